@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -9,15 +11,23 @@ import (
 	"time"
 
 	"github.com/landly/backend/config"
+	"github.com/landly/backend/internal/certs"
 	"github.com/landly/backend/internal/database/postgres"
 	"github.com/landly/backend/internal/handlers"
+	"github.com/landly/backend/internal/jobs"
 	"github.com/landly/backend/internal/logger"
 	"github.com/landly/backend/internal/repositories"
 	"github.com/landly/backend/internal/services"
 	"github.com/landly/backend/internal/storage/ai"
 	"github.com/landly/backend/internal/storage/render"
 	"github.com/landly/backend/internal/storage/s3"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
+
+	// Пользовательские блоки регистрируются в blocks.Default при импорте
+	_ "github.com/landly/backend/internal/blocks/countdown"
+	_ "github.com/landly/backend/internal/blocks/form"
+	_ "github.com/landly/backend/internal/blocks/logos"
 )
 
 func main() {
@@ -65,10 +75,19 @@ func main() {
 	userRepo := repositories.NewUserRepository(qb)
 	projectRepo := repositories.NewProjectRepository(qb)
 	analyticsRepo := repositories.NewAnalyticsRepository(qb)
+	analyticsGoalRepo := repositories.NewAnalyticsGoalRepository(qb)
 	integrationRepo := repositories.NewIntegrationRepository(qb)
 	publishTargetRepo := repositories.NewPublishTargetRepository(qb)
 	sessionRepo := repositories.NewGenerationSessionRepository(qb)
 	messageRepo := repositories.NewGenerationMessageRepository(qb)
+	revisionRepo := repositories.NewSchemaRevisionRepository(qb)
+	deploymentRepo := repositories.NewDeploymentRepository(qb)
+	variantRepo := repositories.NewProjectVariantRepository(qb)
+	jobRepo := repositories.NewJobRepository(qb)
+	purchaseRepo := repositories.NewPurchaseRepository(qb)
+	formSubmissionRepo := repositories.NewFormSubmissionRepository(qb)
+	webhookRepo := repositories.NewWebhookRepository(qb)
+	webhookDeliveryRepo := repositories.NewWebhookDeliveryRepository(qb)
 
 	// S3 клиент
 	s3Client, err := s3.NewClient(s3.Config{
@@ -87,30 +106,116 @@ func main() {
 
 	// AI клиент
 	var aiClient ai.Client
-	if cfg.AI.Provider == "mock" {
+	switch cfg.AI.Provider {
+	case "mock":
 		aiClient = ai.NewMockClient()
 		log.Info("using mock AI client")
-	} else {
+	case "openai":
+		aiClient = ai.NewOpenAIClient(ai.OpenAIConfig{
+			APIKey:      cfg.AI.OpenAI.APIKey,
+			Model:       cfg.AI.OpenAI.Model,
+			BaseURL:     cfg.AI.OpenAI.BaseURL,
+			MaxTokens:   cfg.AI.OpenAI.MaxTokens,
+			Temperature: cfg.AI.OpenAI.Temperature,
+		})
+		log.Info("using OpenAI-compatible AI client",
+			zap.String("model", cfg.AI.OpenAI.Model),
+			zap.String("base_url", cfg.AI.OpenAI.BaseURL),
+		)
+	case "anthropic":
+		aiClient = ai.NewAnthropicClient(ai.AnthropicConfig{
+			APIKey:    cfg.AI.Anthropic.APIKey,
+			Model:     cfg.AI.Anthropic.Model,
+			BaseURL:   cfg.AI.Anthropic.BaseURL,
+			MaxTokens: cfg.AI.Anthropic.MaxTokens,
+		})
+		log.Info("using Anthropic AI client", zap.String("model", cfg.AI.Anthropic.Model))
+	default:
 		log.Fatal("AI provider not implemented", zap.String("provider", cfg.AI.Provider))
 	}
 
 	// Рендерер
 	renderer := render.NewStaticRenderer(cfg.Render.TmpDir)
+	renderer.SetAPIBaseURL(cfg.App.BaseURL)
+	if cfg.Render.ThemesDir != "" {
+		if err := renderer.LoadThemes(cfg.Render.ThemesDir); err != nil {
+			log.Fatal("failed to load themes", zap.Error(err))
+		}
+	}
+
+	// Очередь фоновых задач: генерацию и публикацию выполняет cmd/worker
+	broker, err := jobs.NewBroker(context.Background(), jobs.BrokerConfig{
+		Backend: cfg.Jobs.Backend,
+		Redis: &redis.Options{
+			Addr:     cfg.Database.Redis.Addr,
+			Password: cfg.Database.Redis.Password,
+			DB:       cfg.Database.Redis.DB,
+			PoolSize: cfg.Database.Redis.PoolSize,
+		},
+	}, jobRepo)
+	if err != nil {
+		log.Fatal("failed to create job broker", zap.Error(err))
+	}
+	jobQueue := jobs.NewQueue(jobRepo, broker, cfg.Jobs.MaxAttempts)
 
 	// Сервисы
 	authService := services.NewAuthService(userRepo, cfg.Auth.JWT.Secret, cfg.Auth.JWT.AccessTokenTTL, cfg.Auth.JWT.RefreshTokenTTL)
 	projectService := services.NewProjectService(projectRepo)
 	generateService := services.NewGenerateService(projectRepo, integrationRepo, sessionRepo, messageRepo, aiClient)
-	publishService := services.NewPublishService(projectRepo, publishTargetRepo, userRepo, renderer, s3Client, cfg.App.BaseURL)
+	generateService.SetSchemaRepairAttempts(cfg.AI.RepairAttempts)
+	generateService.SetRevisionRepository(revisionRepo)
+	generateService.SetJobQueue(jobQueue)
+	publishService := services.NewPublishService(projectRepo, publishTargetRepo, deploymentRepo, userRepo, renderer, s3Client, cfg.App.BaseURL)
+	publishService.SetJobQueue(jobQueue)
+	publishService.SetVariantRepository(variantRepo)
 	analyticsService := services.NewAnalyticsService(projectRepo, analyticsRepo)
+	analyticsService.SetIPAnonymization(cfg.Analytics.IPAnonymization)
+	analyticsService.SetGoalRepository(analyticsGoalRepo)
+	experimentService := services.NewExperimentService(projectRepo, variantRepo, analyticsRepo)
+	experimentService.SetGoalRepository(analyticsGoalRepo)
+	experimentService.SetRevisionRepository(revisionRepo)
+	integrationService := services.NewIntegrationService(projectRepo, integrationRepo)
+	renderer.SetPaymentLinks(integrationService)
+	purchaseService := services.NewPurchaseService(projectRepo, integrationRepo, purchaseRepo)
+	formService := services.NewFormService(projectRepo, variantRepo, publishTargetRepo, deploymentRepo, formSubmissionRepo, cfg.Auth.JWT.Secret)
+	webhookService := services.NewWebhookService(projectRepo, webhookRepo, webhookDeliveryRepo, jobQueue)
+	publishService.SetEventEmitter(webhookService)
+	analyticsService.SetEventEmitter(webhookService)
+	purchaseService.SetEventEmitter(webhookService)
+	formService.SetEventEmitter(webhookService)
+	customDomainService := services.NewCustomDomainService(projectRepo, publishTargetRepo, net.DefaultResolver, cfg.App.BaseURL)
+
+	// Сертификаты собственных доменов
+	var certManager *certs.Manager
+	if cfg.Server.ACME.Enabled {
+		certManager = certs.NewManager(repositories.NewCertificateRepository(qb), publishTargetRepo, certs.ManagerConfig{
+			DirectoryURL:  cfg.Server.ACME.DirectoryURL,
+			Email:         cfg.Server.ACME.Email,
+			RenewBefore:   cfg.Server.ACME.RenewBefore,
+			CheckInterval: cfg.Server.ACME.CheckInterval,
+		})
+		customDomainService.SetCertificateIssuer(certManager)
+		log.Info("acme certificates enabled", zap.String("directory", cfg.Server.ACME.DirectoryURL))
+	}
 
 	// HTTP handlers
 	authHandler := handlers.NewAuthHandler(authService)
 	projectHandler := handlers.NewProjectHandler(projectService, publishTargetRepo, cfg.App.BaseURL)
 	generateHandler := handlers.NewGenerateHandler(generateService, publishService, cfg.App.BaseURL)
 	simpleGenerateService := services.NewSimpleGenerateService(projectRepo, aiClient)
+	simpleGenerateService.SetSchemaRepairAttempts(cfg.AI.RepairAttempts)
+	simpleGenerateService.SetRevisionRepository(revisionRepo)
 	simpleGenerateHandler := handlers.NewSimpleGenerateHandler(simpleGenerateService)
 	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
+	schemaRevisionHandler := handlers.NewSchemaRevisionHandler(services.NewSchemaRevisionService(projectRepo, revisionRepo))
+	deploymentHandler := handlers.NewDeploymentHandler(publishService)
+	jobHandler := handlers.NewJobHandler(services.NewJobService(jobRepo, jobQueue))
+	experimentHandler := handlers.NewExperimentHandler(experimentService)
+	domainHandler := handlers.NewDomainHandler(customDomainService, publishService, cfg.App.BaseURL)
+	integrationHandler := handlers.NewIntegrationHandler(integrationService)
+	purchaseHandler := handlers.NewPurchaseHandler(purchaseService)
+	formHandler := handlers.NewFormHandler(formService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
 
 	// Router
 	router := handlers.NewRouter(
@@ -119,6 +224,15 @@ func main() {
 		generateHandler,
 		simpleGenerateHandler,
 		analyticsHandler,
+		schemaRevisionHandler,
+		deploymentHandler,
+		jobHandler,
+		experimentHandler,
+		domainHandler,
+		integrationHandler,
+		purchaseHandler,
+		formHandler,
+		webhookHandler,
 		cfg.Auth.JWT.Secret,
 		cfg.Server.CORS.AllowedOrigins,
 		cfg.Server.CORS.AllowedMethods,
@@ -126,12 +240,16 @@ func main() {
 		logger.GetZapLogger(),
 	)
 
-	engine := router.Setup()
+	handler := router.Handler()
+	if certManager != nil {
+		// Challenge http-01 приходит на собственный домен, поэтому отвечаем до раздачи сайтов
+		handler = certManager.HTTPHandler(handler)
+	}
 
 	// HTTP сервер
 	srv := &http.Server{
 		Addr:         cfg.Server.HTTP.Addr,
-		Handler:      engine,
+		Handler:      handler,
 		ReadTimeout:  cfg.Server.HTTP.ReadTimeout,
 		WriteTimeout: cfg.Server.HTTP.WriteTimeout,
 		IdleTimeout:  60 * time.Second,
@@ -145,6 +263,29 @@ func main() {
 		}
 	}()
 
+	// HTTPS сервер собственных доменов
+	var tlsSrv *http.Server
+	certCtx, stopCerts := context.WithCancel(context.Background())
+	defer stopCerts()
+	if certManager != nil {
+		go certManager.Run(certCtx)
+
+		tlsSrv = &http.Server{
+			Addr:         cfg.Server.ACME.HTTPSAddr,
+			Handler:      handler,
+			TLSConfig:    &tls.Config{GetCertificate: certManager.GetCertificate, MinVersion: tls.VersionTLS12},
+			ReadTimeout:  cfg.Server.HTTP.ReadTimeout,
+			WriteTimeout: cfg.Server.HTTP.WriteTimeout,
+			IdleTimeout:  60 * time.Second,
+		}
+		go func() {
+			logger.WithContext(context.Background()).Info("https server starting", zap.String("addr", cfg.Server.ACME.HTTPSAddr))
+			if err := tlsSrv.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+				logger.WithContext(context.Background()).Fatal("failed to start https server", zap.Error(err))
+			}
+		}()
+	}
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -155,6 +296,12 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	stopCerts()
+	if tlsSrv != nil {
+		if err := tlsSrv.Shutdown(ctx); err != nil {
+			log.Error("https server forced to shutdown", zap.Error(err))
+		}
+	}
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatal("server forced to shutdown", zap.Error(err))
 	}
@@ -1,41 +1,187 @@
 package main
 
 import (
-	"log"
+	"context"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/landly/backend/config"
+	"github.com/landly/backend/internal/database/postgres"
+	"github.com/landly/backend/internal/jobs"
+	"github.com/landly/backend/internal/logger"
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/repositories"
+	"github.com/landly/backend/internal/services"
+	"github.com/landly/backend/internal/storage/ai"
+	"github.com/landly/backend/internal/storage/render"
+	"github.com/landly/backend/internal/storage/s3"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
+
+	// Пользовательские блоки регистрируются в blocks.Default при импорте
+	_ "github.com/landly/backend/internal/blocks/countdown"
+	_ "github.com/landly/backend/internal/blocks/form"
+	_ "github.com/landly/backend/internal/blocks/logos"
 )
 
 func main() {
 	// Логгер
-	logger, err := zap.NewProduction()
-	if err != nil {
-		log.Fatalf("failed to initialize logger: %v", err)
-	}
+	logger.Init()
+	log := logger.Get()
 	defer func() {
-		if err := logger.Sync(); err != nil {
-			log.Printf("failed to sync logger: %v", err)
+		if err := log.Sync(); err != nil {
+			logger.Warn("failed to sync logger", zap.Error(err))
 		}
 	}()
 
 	// Конфигурация
 	cfg, err := config.Load()
 	if err != nil {
-		logger.Fatal("failed to load config", zap.Error(err))
+		log.Fatal("failed to load config", zap.Error(err))
 	}
 
-	logger.Info("starting worker",
+	log.Info("starting worker",
 		zap.String("env", cfg.App.Env),
+		zap.String("version", cfg.App.Version),
 	)
 
-	// TODO: Инициализация Asynq worker для фоновых задач
-	// - GENERATE: AI генерация лендингов
-	// - RENDER: Рендеринг статических сайтов
-	// - PUBLISH: Публикация в S3
+	// База данных (Query Builder)
+	qb, err := postgres.NewConnection(postgres.Config{
+		Host:            cfg.Database.Postgres.Host,
+		Port:            cfg.Database.Postgres.Port,
+		User:            cfg.Database.Postgres.User,
+		Password:        cfg.Database.Postgres.Password,
+		DBName:          cfg.Database.Postgres.DBName,
+		SSLMode:         cfg.Database.Postgres.SSLMode,
+		MaxOpenConns:    cfg.Database.Postgres.MaxOpenConns,
+		MaxIdleConns:    cfg.Database.Postgres.MaxIdleConns,
+		ConnMaxLifetime: cfg.Database.Postgres.ConnMaxLifetime,
+	})
+	if err != nil {
+		log.Fatal("failed to connect to database", zap.Error(err))
+	}
+
+	// Репозитории
+	userRepo := repositories.NewUserRepository(qb)
+	projectRepo := repositories.NewProjectRepository(qb)
+	integrationRepo := repositories.NewIntegrationRepository(qb)
+	publishTargetRepo := repositories.NewPublishTargetRepository(qb)
+	sessionRepo := repositories.NewGenerationSessionRepository(qb)
+	messageRepo := repositories.NewGenerationMessageRepository(qb)
+	revisionRepo := repositories.NewSchemaRevisionRepository(qb)
+	deploymentRepo := repositories.NewDeploymentRepository(qb)
+	variantRepo := repositories.NewProjectVariantRepository(qb)
+	analyticsRepo := repositories.NewAnalyticsRepository(qb)
+	jobRepo := repositories.NewJobRepository(qb)
+	webhookRepo := repositories.NewWebhookRepository(qb)
+	webhookDeliveryRepo := repositories.NewWebhookDeliveryRepository(qb)
+
+	// S3 клиент
+	s3Client, err := s3.NewClient(s3.Config{
+		Endpoint:        cfg.Storage.S3.Endpoint,
+		AccessKeyID:     cfg.Storage.S3.AccessKey,
+		SecretAccessKey: cfg.Storage.S3.SecretKey,
+		UseSSL:          cfg.Storage.S3.UseSSL,
+		BucketName:      cfg.Storage.S3.Bucket,
+		CDNBase:         cfg.Storage.CDN.BaseURL,
+	})
+	if err != nil {
+		log.Fatal("failed to create s3 client", zap.Error(err))
+	}
+
+	// AI клиент
+	var aiClient ai.Client
+	switch cfg.AI.Provider {
+	case "mock":
+		aiClient = ai.NewMockClient()
+	case "openai":
+		aiClient = ai.NewOpenAIClient(ai.OpenAIConfig{
+			APIKey:      cfg.AI.OpenAI.APIKey,
+			Model:       cfg.AI.OpenAI.Model,
+			BaseURL:     cfg.AI.OpenAI.BaseURL,
+			MaxTokens:   cfg.AI.OpenAI.MaxTokens,
+			Temperature: cfg.AI.OpenAI.Temperature,
+		})
+	case "anthropic":
+		aiClient = ai.NewAnthropicClient(ai.AnthropicConfig{
+			APIKey:    cfg.AI.Anthropic.APIKey,
+			Model:     cfg.AI.Anthropic.Model,
+			BaseURL:   cfg.AI.Anthropic.BaseURL,
+			MaxTokens: cfg.AI.Anthropic.MaxTokens,
+		})
+	default:
+		log.Fatal("AI provider not implemented", zap.String("provider", cfg.AI.Provider))
+	}
+
+	// Рендерер
+	renderer := render.NewStaticRenderer(cfg.Render.TmpDir)
+	renderer.SetAPIBaseURL(cfg.App.BaseURL)
+	renderer.SetPaymentLinks(services.NewIntegrationService(projectRepo, integrationRepo))
+	if cfg.Render.ThemesDir != "" {
+		if err := renderer.LoadThemes(cfg.Render.ThemesDir); err != nil {
+			log.Fatal("failed to load themes", zap.Error(err))
+		}
+	}
+
+	// Сервисы
+	generateService := services.NewGenerateService(projectRepo, integrationRepo, sessionRepo, messageRepo, aiClient)
+	generateService.SetSchemaRepairAttempts(cfg.AI.RepairAttempts)
+	generateService.SetRevisionRepository(revisionRepo)
+	publishService := services.NewPublishService(projectRepo, publishTargetRepo, deploymentRepo, userRepo, renderer, s3Client, cfg.App.BaseURL)
+	publishService.SetVariantRepository(variantRepo)
+	analyticsService := services.NewAnalyticsService(projectRepo, analyticsRepo)
+	analyticsService.SetRetention(cfg.Analytics.Retention)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Очередь задач
+	broker, err := jobs.NewBroker(ctx, jobs.BrokerConfig{
+		Backend: cfg.Jobs.Backend,
+		Redis: &redis.Options{
+			Addr:     cfg.Database.Redis.Addr,
+			Password: cfg.Database.Redis.Password,
+			DB:       cfg.Database.Redis.DB,
+			PoolSize: cfg.Database.Redis.PoolSize,
+		},
+	}, jobRepo)
+	if err != nil {
+		log.Fatal("failed to create job broker", zap.Error(err))
+	}
+	queue := jobs.NewQueue(jobRepo, broker, cfg.Jobs.MaxAttempts)
+
+	// Вебхуки: site.published из публикации и доставка событий, поставленных API
+	webhookService := services.NewWebhookService(projectRepo, webhookRepo, webhookDeliveryRepo, queue)
+	publishService.SetEventEmitter(webhookService)
+
+	worker := jobs.NewWorker(queue, jobs.WorkerConfig{
+		Concurrency:  cfg.Jobs.Concurrency,
+		PollInterval: cfg.Jobs.PollInterval,
+		LeaseTimeout: cfg.Jobs.LeaseTimeout,
+	})
+	worker.Handle(domain.JobTypeGenerate, generateService.HandleGenerateJob)
+	worker.Handle(domain.JobTypePublish, publishService.HandlePublishJob)
+	worker.Handle(domain.JobTypeWebhook, webhookService.HandleDeliveryJob)
+
+	log.Info("worker started", zap.Int("concurrency", cfg.Jobs.Concurrency))
+
+	// Агрегаты аналитики для временных рядов
+	go jobs.RunPeriodic(ctx, "analytics_rollup", cfg.Analytics.RollupInterval, func(ctx context.Context) error {
+		return analyticsService.RollupRecent(ctx, time.Now())
+	})
+	// Соли прошлых суток и сырые события старше analytics.retention
+	go jobs.RunPeriodic(ctx, "analytics_retention", time.Hour, func(ctx context.Context) error {
+		return analyticsService.PurgeExpired(ctx, time.Now())
+	})
+
+	// Журнал доставок вебхуков старше services.WebhookDeliveryRetention
+	go jobs.RunPeriodic(ctx, "webhook_deliveries_retention", time.Hour, func(ctx context.Context) error {
+		return webhookService.PurgeDeliveries(ctx, time.Now())
+	})
 
-	logger.Info("worker started (stub implementation)")
+	// Run возвращается после SIGINT/SIGTERM, дождавшись текущих задач
+	worker.Run(ctx)
 
-	// Блокируем выполнение
-	select {}
+	log.Info("worker stopped gracefully")
 }
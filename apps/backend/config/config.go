@@ -19,6 +19,8 @@ type Config struct {
 	Storage       StorageConfig       `mapstructure:"storage"`
 	AI            AIConfig            `mapstructure:"ai"`
 	Render        RenderConfig        `mapstructure:"render"`
+	Jobs          JobsConfig          `mapstructure:"jobs"`
+	Analytics     AnalyticsConfig     `mapstructure:"analytics"`
 	Logging       LoggingConfig       `mapstructure:"logging"`
 	Observability ObservabilityConfig `mapstructure:"observability"`
 }
@@ -33,6 +35,7 @@ type AppConfig struct {
 type ServerConfig struct {
 	HTTP HTTPConfig `mapstructure:"http"`
 	CORS CORSConfig `mapstructure:"cors"`
+	ACME ACMEConfig `mapstructure:"acme"`
 }
 
 type HTTPConfig struct {
@@ -41,6 +44,16 @@ type HTTPConfig struct {
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
 }
 
+// ACMEConfig выпуск сертификатов для собственных доменов и HTTPS-сервер с ними
+type ACMEConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	DirectoryURL  string        `mapstructure:"directory_url"`
+	Email         string        `mapstructure:"email"`
+	HTTPSAddr     string        `mapstructure:"https_addr"`
+	RenewBefore   time.Duration `mapstructure:"renew_before"`
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+}
+
 type CORSConfig struct {
 	AllowedOrigins []string `mapstructure:"allowed_origins"`
 	AllowedMethods []string `mapstructure:"allowed_methods"`
@@ -102,14 +115,16 @@ type CDNConfig struct {
 }
 
 type AIConfig struct {
-	Provider  string          `mapstructure:"provider"`
-	OpenAI    OpenAIConfig    `mapstructure:"openai"`
-	Anthropic AnthropicConfig `mapstructure:"anthropic"`
+	Provider       string          `mapstructure:"provider"`
+	RepairAttempts int             `mapstructure:"repair_attempts"`
+	OpenAI         OpenAIConfig    `mapstructure:"openai"`
+	Anthropic      AnthropicConfig `mapstructure:"anthropic"`
 }
 
 type OpenAIConfig struct {
 	APIKey      string  `mapstructure:"api_key"`
 	Model       string  `mapstructure:"model"`
+	BaseURL     string  `mapstructure:"base_url"`
 	MaxTokens   int     `mapstructure:"max_tokens"`
 	Temperature float64 `mapstructure:"temperature"`
 }
@@ -117,12 +132,28 @@ type OpenAIConfig struct {
 type AnthropicConfig struct {
 	APIKey    string `mapstructure:"api_key"`
 	Model     string `mapstructure:"model"`
+	BaseURL   string `mapstructure:"base_url"`
 	MaxTokens int    `mapstructure:"max_tokens"`
 }
 
 type RenderConfig struct {
 	TmpDir       string        `mapstructure:"tmp_dir"`
 	CleanupAfter time.Duration `mapstructure:"cleanup_after"`
+	ThemesDir    string        `mapstructure:"themes_dir"`
+}
+
+type JobsConfig struct {
+	Backend      string        `mapstructure:"backend"`
+	Concurrency  int           `mapstructure:"concurrency"`
+	MaxAttempts  int           `mapstructure:"max_attempts"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	LeaseTimeout time.Duration `mapstructure:"lease_timeout"`
+}
+
+type AnalyticsConfig struct {
+	RollupInterval  time.Duration `mapstructure:"rollup_interval"`
+	IPAnonymization string        `mapstructure:"ip_anonymization"`
+	Retention       time.Duration `mapstructure:"retention"`
 }
 
 type LoggingConfig struct {
@@ -252,6 +283,51 @@ func validateConfig(cfg *Config) error {
 		cfg.Auth.JWT.RefreshTokenTTL = 7 * 24 * time.Hour
 	}
 
+	if cfg.Analytics.RollupInterval <= 0 {
+		cfg.Analytics.RollupInterval = 5 * time.Minute
+	}
+	if cfg.Analytics.IPAnonymization == "" {
+		cfg.Analytics.IPAnonymization = "full"
+	}
+	switch cfg.Analytics.IPAnonymization {
+	case "full", "truncate", "none":
+	default:
+		return fmt.Errorf("analytics.ip_anonymization must be one of full, truncate, none")
+	}
+	// Агрегаты пересчитываются за последние сутки, события должны дожить до пересчёта
+	if cfg.Analytics.Retention > 0 && cfg.Analytics.Retention < 48*time.Hour {
+		return fmt.Errorf("analytics.retention must be at least 48h or 0 to keep events forever")
+	}
+
+	// Без ключа в конфиге значение 0 молча отключило бы исправление схем
+	if cfg.AI.RepairAttempts <= 0 {
+		cfg.AI.RepairAttempts = 2
+	}
+
+	if cfg.Jobs.Backend == "" {
+		cfg.Jobs.Backend = "auto"
+	}
+	switch cfg.Jobs.Backend {
+	case "auto", "redis", "database":
+	default:
+		return fmt.Errorf("jobs.backend must be one of auto, redis, database")
+	}
+
+	if cfg.Server.ACME.Enabled {
+		if cfg.Server.ACME.DirectoryURL == "" {
+			cfg.Server.ACME.DirectoryURL = "https://acme-v02.api.letsencrypt.org/directory"
+		}
+		if cfg.Server.ACME.HTTPSAddr == "" {
+			cfg.Server.ACME.HTTPSAddr = ":443"
+		}
+		if cfg.Server.ACME.RenewBefore <= 0 {
+			cfg.Server.ACME.RenewBefore = 30 * 24 * time.Hour
+		}
+		if cfg.Server.ACME.CheckInterval <= 0 {
+			cfg.Server.ACME.CheckInterval = 12 * time.Hour
+		}
+	}
+
 	if cfg.Database.Postgres.Host == "" {
 		return fmt.Errorf("database.postgres.host is required")
 	}
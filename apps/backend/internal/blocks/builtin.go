@@ -0,0 +1,162 @@
+package blocks
+
+// Встроенные блоки регистрируются в Default в порядке, в котором они
+// перечисляются в промпте и сообщениях валидатора.
+func init() {
+	for _, def := range builtinDefinitions {
+		MustRegister(def)
+	}
+}
+
+var builtinDefinitions = []Definition{
+	{
+		Type: "hero",
+		Hint: "headline, subheadline, ctaText, ctaUrl, image, navItems[] (названия секций/страниц или {label,href})",
+		PropsSchema: `{
+			"type": "object",
+			"properties": {
+				"headline": {"type": "string"},
+				"subheadline": {"type": "string"},
+				"ctaText": {"type": "string"},
+				"ctaUrl": {"type": "string"},
+				"image": {"type": "string"},
+				"navItems": {"type": "array"}
+			}
+		}`,
+		Example:  `{"headline":"Научитесь программировать за 3 месяца","subheadline":"Практический курс для начинающих","ctaText":"Записаться","ctaUrl":"#pricing"}`,
+		Renderer: RendererFunc(renderHero),
+	},
+	{
+		Type:  "features",
+		Label: "Возможности",
+		Hint:  "title, items[{icon,title,description}]",
+		PropsSchema: `{
+			"type": "object",
+			"properties": {
+				"title": {"type": "string"},
+				"items": {"type": "array", "items": {"type": "object"}}
+			}
+		}`,
+		Example:  `{"title":"Что вы получите","items":[{"icon":"code","title":"Практика","description":"Реальные проекты в портфолио"}]}`,
+		Renderer: RendererFunc(renderFeatures),
+	},
+	{
+		Type:  "pricing",
+		Label: "Цены",
+		Hint:  "title, plans[{name,price,currency,period,featured,features[],url}]",
+		PropsSchema: `{
+			"type": "object",
+			"properties": {
+				"title": {"type": "string"},
+				"plans": {
+					"type": "array",
+					"items": {
+						"type": "object",
+						"properties": {
+							"name": {"type": "string"},
+							"features": {"type": "array", "items": {"type": "string"}}
+						}
+					}
+				}
+			}
+		}`,
+		Example:  `{"title":"Тарифы","plans":[{"name":"Базовый","price":"29990","currency":"₽","period":"курс","features":["Видеоуроки","Сертификат"]}]}`,
+		Renderer: RendererFunc(renderPricing),
+	},
+	{
+		Type:  "testimonials",
+		Label: "Отзывы",
+		Hint:  "title, items[{author,role,text,rating}]",
+		PropsSchema: `{
+			"type": "object",
+			"properties": {
+				"title": {"type": "string"},
+				"items": {"type": "array", "items": {"type": "object"}}
+			}
+		}`,
+		Example:  `{"title":"Отзывы","items":[{"author":"Анна","role":"Дизайнер","text":"Лучший курс","rating":"5"}]}`,
+		Renderer: RendererFunc(renderTestimonials),
+	},
+	{
+		Type:  "faq",
+		Label: "Вопросы",
+		Hint:  "title, items[{question,answer}]",
+		PropsSchema: `{
+			"type": "object",
+			"properties": {
+				"title": {"type": "string"},
+				"items": {"type": "array", "items": {"type": "object"}}
+			}
+		}`,
+		Example:  `{"title":"Вопросы","items":[{"question":"Нужен ли опыт?","answer":"Нет, начинаем с основ"}]}`,
+		Renderer: RendererFunc(renderFAQ),
+	},
+	{
+		Type: "cta",
+		Hint: "title, description, buttonText, buttonUrl",
+		PropsSchema: `{
+			"type": "object",
+			"properties": {
+				"title": {"type": "string"},
+				"description": {"type": "string"},
+				"buttonText": {"type": "string"},
+				"buttonUrl": {"type": "string"}
+			}
+		}`,
+		Example:  `{"title":"Начните сегодня","description":"Первые 7 дней бесплатно","buttonText":"Записаться","buttonUrl":"#pricing"}`,
+		Renderer: RendererFunc(renderCTA),
+	},
+	{
+		Type:  "gallery",
+		Label: "Галерея",
+		Hint:  "title, subtitle, columns(2-4), items[{image,caption,alt}]",
+		PropsSchema: `{
+			"type": "object",
+			"properties": {
+				"title": {"type": "string"},
+				"subtitle": {"type": "string"},
+				"items": {"type": "array", "items": {"type": "object"}}
+			}
+		}`,
+		Example:  `{"title":"Наши работы","columns":3,"items":[{"image":"https://example.com/1.jpg","caption":"Кухня"}]}`,
+		Renderer: RendererFunc(renderGallery),
+	},
+	{
+		Type:  "about",
+		Label: "О нас",
+		Hint:  "title, text (абзацы через \\n), image, stats[{value,label}], team[{name,role,bio,photo}]",
+		PropsSchema: `{
+			"type": "object",
+			"properties": {
+				"title": {"type": "string"},
+				"text": {"type": "string"},
+				"image": {"type": "string"},
+				"stats": {"type": "array", "items": {"type": "object"}},
+				"team": {"type": "array", "items": {"type": "object"}}
+			}
+		}`,
+		Example:  `{"title":"О нас","text":"Мы учим программированию с 2015 года.","stats":[{"value":"10 000+","label":"выпускников"}]}`,
+		Renderer: RendererFunc(renderAbout),
+	},
+	{
+		Type:  "contact",
+		Label: "Контакты",
+		Hint:  "title, description, email, phone, address, hours, mapEmbedUrl|mapQuery, socials[{label,url}]",
+		PropsSchema: `{
+			"type": "object",
+			"properties": {
+				"title": {"type": "string"},
+				"description": {"type": "string"},
+				"email": {"type": "string"},
+				"phone": {"type": "string"},
+				"address": {"type": "string"},
+				"hours": {"type": "string"},
+				"mapEmbedUrl": {"type": "string"},
+				"mapQuery": {"type": "string"},
+				"socials": {"type": "array", "items": {"type": "object"}}
+			}
+		}`,
+		Example:  `{"title":"Контакты","email":"hello@example.com","phone":"+7 (999) 123-45-67","address":"Москва, ул. Тверская, 1"}`,
+		Renderer: RendererFunc(renderContact),
+	},
+}
@@ -0,0 +1,557 @@
+package blocks
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+)
+
+func renderHero(props map[string]interface{}, rc RenderContext) string {
+	headline := html.EscapeString(StringProp(props, "headline", "Заголовок лендинга"))
+	subheadline := html.EscapeString(StringProp(props, "subheadline", ""))
+	ctaText := html.EscapeString(StringProp(props, "ctaText", ""))
+	ctaURL := html.EscapeString(StringProp(props, "ctaUrl", "#"))
+	secondaryText := html.EscapeString(StringProp(props, "secondaryCtaText", "Подробнее"))
+	secondaryURL := html.EscapeString(StringProp(props, "secondaryCtaUrl", "#"))
+	eyebrow := html.EscapeString(StringProp(props, "eyebrow", "Инновационная платформа"))
+	brand := html.EscapeString(StringProp(props, "brand", "Landly"))
+	navActionText := html.EscapeString(StringProp(props, "navActionText", "Войти"))
+	navActionURL := html.EscapeString(StringProp(props, "navActionUrl", "#"))
+	heroImage := html.EscapeString(StringProp(props, "image", ""))
+	imageAlt := html.EscapeString(StringProp(props, "imageAlt", headline))
+
+	navItems := heroNavItems(props["navItems"], rc)
+
+	var sb strings.Builder
+	sb.WriteString(`<section class="landing-section landing-section--hero" data-block="hero"><div class="landing-hero-overlay"></div><div class="landing-container">`)
+
+	sb.WriteString(`<div class="landing-topbar"><span class="landing-brand">`)
+	sb.WriteString(brand)
+	sb.WriteString(`</span><nav class="landing-nav">`)
+	for _, item := range navItems {
+		if item.Active {
+			sb.WriteString(`<a class="is-active" aria-current="page" href="`)
+		} else {
+			sb.WriteString(`<a href="`)
+		}
+		sb.WriteString(html.EscapeString(item.Href))
+		sb.WriteString(`">`)
+		sb.WriteString(html.EscapeString(item.Title))
+		sb.WriteString(`</a>`)
+	}
+	sb.WriteString(`</nav>`)
+	if navActionText != "" {
+		sb.WriteString(`<a class="landing-nav-action" href="`)
+		sb.WriteString(navActionURL)
+		sb.WriteString(`" target="_blank" rel="noopener noreferrer">`)
+		sb.WriteString(navActionText)
+		sb.WriteString(`</a>`)
+	}
+	sb.WriteString(`</div>`)
+
+	sb.WriteString(`<div class="landing-hero-grid"><div class="landing-hero-content">`)
+	if eyebrow != "" {
+		sb.WriteString(`<span class="landing-eyebrow">`)
+		sb.WriteString(eyebrow)
+		sb.WriteString(`</span>`)
+	}
+	sb.WriteString(`<h1>`)
+	sb.WriteString(headline)
+	sb.WriteString(`</h1>`)
+	if subheadline != "" {
+		sb.WriteString(`<p>`)
+		sb.WriteString(subheadline)
+		sb.WriteString(`</p>`)
+	}
+	if ctaText != "" || secondaryText != "" {
+		sb.WriteString(`<div class="landing-actions landing-actions--hero">`)
+		if ctaText != "" {
+			sb.WriteString(`<a class="landing-button landing-button--primary" data-track="cta_click" href="`)
+			sb.WriteString(ctaURL)
+			sb.WriteString(`">`)
+			sb.WriteString(ctaText)
+			sb.WriteString(`</a>`)
+		}
+		if secondaryText != "" {
+			sb.WriteString(`<a class="landing-button landing-button--ghost" data-track="cta_secondary" href="`)
+			sb.WriteString(secondaryURL)
+			sb.WriteString(`">`)
+			sb.WriteString(secondaryText)
+			sb.WriteString(`</a>`)
+		}
+		sb.WriteString(`</div>`)
+	}
+	sb.WriteString(`</div>`)
+
+	if heroImage != "" {
+		sb.WriteString(`<div class="landing-hero-media"><div class="landing-hero-media-card"><img src="`)
+		sb.WriteString(heroImage)
+		sb.WriteString(`" alt="`)
+		sb.WriteString(imageAlt)
+		sb.WriteString(`" /></div></div>`)
+	}
+
+	sb.WriteString(`</div></div></section>`)
+	return sb.String()
+}
+
+// heroNavItems собирает меню hero: navItems — строки (ссылка ищется среди страниц
+// и секций) или объекты {label, href}; без navItems — страницы многостраничного сайта
+func heroNavItems(value interface{}, rc RenderContext) []Link {
+	var links []Link
+	if items, ok := value.([]interface{}); ok {
+		for _, raw := range items {
+			switch item := raw.(type) {
+			case string:
+				links = append(links, Link{Title: item, Href: rc.Href(item)})
+			case map[string]interface{}:
+				label := StringProp(item, "label", "")
+				if label == "" {
+					continue
+				}
+				links = append(links, Link{Title: label, Href: StringProp(item, "href", rc.Href(label))})
+			}
+		}
+	}
+	if len(links) > 0 {
+		return links
+	}
+
+	if len(rc.Pages) > 1 {
+		return rc.Pages
+	}
+
+	for _, label := range []string{"Возможности", "Цены", "Отзывы", "Контакты"} {
+		links = append(links, Link{Title: label, Href: rc.Href(label)})
+	}
+	return links
+}
+
+func renderFeatures(props map[string]interface{}, _ RenderContext) string {
+	title := html.EscapeString(StringProp(props, "title", "Наши преимущества"))
+	items := Slice(props["items"])
+
+	var sb strings.Builder
+	sb.WriteString(`<section class="landing-section landing-section--features" data-block="features"><div class="landing-container">`)
+	sb.WriteString(fmt.Sprintf(`<div class="landing-section-header"><h2 class="landing-section-title">%s</h2></div>`, title))
+	sb.WriteString(`<div class="landing-features__grid">`)
+
+	if len(items) == 0 {
+		sb.WriteString(`<div class="landing-card landing-feature-card"><p class="landing-empty-state">Добавьте преимущества, чтобы показать их здесь</p></div>`)
+	} else {
+		for _, item := range items {
+			icon := html.EscapeString(StringProp(item, "icon", ""))
+			itemTitle := html.EscapeString(StringProp(item, "title", ""))
+			description := html.EscapeString(StringProp(item, "description", ""))
+
+			sb.WriteString(`<div class="landing-card landing-feature-card">`)
+			if icon != "" {
+				sb.WriteString(`<div class="landing-feature-icon"><span>`)
+				sb.WriteString(icon)
+				sb.WriteString(`</span></div>`)
+			}
+			sb.WriteString(fmt.Sprintf(`<h3>%s</h3>`, itemTitle))
+			if description != "" {
+				sb.WriteString(fmt.Sprintf(`<p>%s</p>`, description))
+			}
+			sb.WriteString(`</div>`)
+		}
+	}
+
+	sb.WriteString(`</div></div></section>`)
+	return sb.String()
+}
+
+func renderPricing(props map[string]interface{}, rc RenderContext) string {
+	title := html.EscapeString(StringProp(props, "title", "Тарифы"))
+	plans := Slice(props["plans"])
+	paymentMap, _ := rc.Schema["payment"].(map[string]interface{})
+	defaultButtonText := html.EscapeString(StringProp(paymentMap, "buttonText", "Выбрать тариф"))
+	defaultURL := rc.PaymentURL
+	if defaultURL == "" {
+		defaultURL = StringProp(paymentMap, "url", "")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<section class="landing-section landing-section--pricing" data-block="pricing"><div class="landing-container">`)
+	sb.WriteString(fmt.Sprintf(`<div class="landing-section-header"><h2 class="landing-section-title">%s</h2></div>`, title))
+	sb.WriteString(`<div class="landing-pricing__grid">`)
+
+	if len(plans) == 0 {
+		sb.WriteString(`<div class="landing-card"><div class="landing-empty-state">Добавьте тарифы в описании проекта</div></div>`)
+	} else {
+		for _, plan := range plans {
+			name := html.EscapeString(StringProp(plan, "name", ""))
+			price := html.EscapeString(StringProp(plan, "price", ""))
+			currency := html.EscapeString(StringProp(plan, "currency", ""))
+			period := html.EscapeString(StringProp(plan, "period", ""))
+			features := StringSlice(plan["features"])
+			featured := BoolProp(plan, "featured")
+			buttonText := html.EscapeString(StringProp(plan, "buttonText", defaultButtonText))
+			buttonURL := html.EscapeString(rc.PaymentHref(StringProp(plan, "url", defaultURL)))
+
+			classes := "pricing-card"
+			if featured {
+				classes += " pricing-card--featured"
+			}
+
+			sb.WriteString(fmt.Sprintf(`<div class="%s" data-featured="%t">`, classes, featured))
+			sb.WriteString(fmt.Sprintf(`<div class="pricing-name">%s</div>`, name))
+			sb.WriteString(`<div class="pricing-price">`)
+			sb.WriteString(fmt.Sprintf(`<span class="pricing-price__value">%s</span>`, price))
+			sb.WriteString(`<span class="pricing-price__period">`)
+			sb.WriteString(currency)
+			if period != "" {
+				sb.WriteString(fmt.Sprintf(` / %s`, period))
+			}
+			sb.WriteString(`</span></div>`)
+
+			sb.WriteString(`<ul class="pricing-features">`)
+			for _, feature := range features {
+				sb.WriteString(`<li class="pricing-feature"><span class="pricing-feature-icon">✓</span><span>`)
+				sb.WriteString(html.EscapeString(feature))
+				sb.WriteString(`</span></li>`)
+			}
+			sb.WriteString(`</ul>`)
+
+			sb.WriteString(`<div class="pricing-action">`)
+			if buttonURL != "" {
+				sb.WriteString(fmt.Sprintf(`<a class="landing-button landing-button--secondary" data-track="pay_click" href="%s" target="_blank" rel="noopener">%s</a>`, buttonURL, buttonText))
+			} else {
+				sb.WriteString(fmt.Sprintf(`<button type="button" class="landing-button landing-button--secondary" data-track="pay_click">%s</button>`, buttonText))
+			}
+			sb.WriteString(`</div>`)
+			sb.WriteString(`</div>`)
+		}
+	}
+
+	sb.WriteString(`</div></div></section>`)
+	return sb.String()
+}
+
+func renderTestimonials(props map[string]interface{}, _ RenderContext) string {
+	title := html.EscapeString(StringProp(props, "title", "Отзывы клиентов"))
+	items := Slice(props["items"])
+
+	var sb strings.Builder
+	sb.WriteString(`<section class="landing-section landing-section--testimonials" data-block="testimonials"><div class="landing-container">`)
+	sb.WriteString(fmt.Sprintf(`<div class="landing-section-header"><h2 class="landing-section-title">%s</h2></div>`, title))
+	sb.WriteString(`<div class="landing-testimonials__grid">`)
+
+	if len(items) == 0 {
+		sb.WriteString(`<div class="landing-card landing-testimonial-card"><p class="landing-empty-state">Добавьте отзывы, чтобы повысить доверие</p></div>`)
+	} else {
+		for _, item := range items {
+			text := html.EscapeString(StringProp(item, "text", ""))
+			author := html.EscapeString(StringProp(item, "author", ""))
+			role := html.EscapeString(StringProp(item, "role", ""))
+			rating := html.EscapeString(StringProp(item, "rating", ""))
+
+			sb.WriteString(`<div class="landing-card landing-testimonial-card">`)
+			if text != "" {
+				sb.WriteString(fmt.Sprintf(`<p class="landing-testimonial-quote">“%s”</p>`, text))
+			}
+			sb.WriteString(`<div class="landing-testimonial-author">`)
+			if author != "" {
+				sb.WriteString(fmt.Sprintf(`<strong>%s</strong>`, author))
+			}
+			if role != "" {
+				sb.WriteString(fmt.Sprintf(`<span>%s</span>`, role))
+			}
+			if rating != "" {
+				sb.WriteString(fmt.Sprintf(`<span class="landing-testimonial-rating">⭐ %s</span>`, rating))
+			}
+			sb.WriteString(`</div></div>`)
+		}
+	}
+
+	sb.WriteString(`</div></div></section>`)
+	return sb.String()
+}
+
+func renderFAQ(props map[string]interface{}, _ RenderContext) string {
+	title := html.EscapeString(StringProp(props, "title", "Частые вопросы"))
+	items := Slice(props["items"])
+
+	var sb strings.Builder
+	sb.WriteString(`<section class="landing-section landing-section--faq" data-block="faq"><div class="landing-container">`)
+	sb.WriteString(fmt.Sprintf(`<div class="landing-section-header"><h2 class="landing-section-title">%s</h2></div>`, title))
+	sb.WriteString(`<div class="landing-faq__list">`)
+
+	if len(items) == 0 {
+		sb.WriteString(`<div class="faq-item"><div class="landing-empty-state">Добавьте вопросы и ответы, которые волнуют клиентов</div></div>`)
+	} else {
+		for _, item := range items {
+			question := html.EscapeString(StringProp(item, "question", ""))
+			answer := html.EscapeString(StringProp(item, "answer", ""))
+			sb.WriteString(`<div class="faq-item">`)
+			sb.WriteString(fmt.Sprintf(`<div class="faq-question">%s</div>`, question))
+			if answer != "" {
+				sb.WriteString(fmt.Sprintf(`<div class="faq-answer">%s</div>`, answer))
+			}
+			sb.WriteString(`</div>`)
+		}
+	}
+
+	sb.WriteString(`</div></div></section>`)
+	return sb.String()
+}
+
+func renderCTA(props map[string]interface{}, _ RenderContext) string {
+	title := html.EscapeString(StringProp(props, "title", "Готовы начать?"))
+	description := html.EscapeString(StringProp(props, "description", ""))
+	buttonText := html.EscapeString(StringProp(props, "buttonText", "Связаться"))
+	buttonURL := html.EscapeString(StringProp(props, "buttonUrl", "#"))
+	secondaryText := html.EscapeString(StringProp(props, "secondaryButtonText", ""))
+	secondaryURL := html.EscapeString(StringProp(props, "secondaryButtonUrl", "#"))
+
+	var sb strings.Builder
+	sb.WriteString(`<section class="landing-section landing-section--cta" data-block="cta"><div class="landing-container">`)
+	sb.WriteString(`<div class="landing-section-header">`)
+	sb.WriteString(fmt.Sprintf(`<h2 class="landing-section-title">%s</h2>`, title))
+	if description != "" {
+		sb.WriteString(fmt.Sprintf(`<p>%s</p>`, description))
+	}
+	sb.WriteString(`</div>`)
+	sb.WriteString(`<div class="landing-actions landing-actions--center">`)
+	sb.WriteString(`<a class="landing-button landing-button--primary" data-track="cta_click" href="`)
+	sb.WriteString(buttonURL)
+	sb.WriteString(`">`)
+	sb.WriteString(buttonText)
+	sb.WriteString(`</a>`)
+	if secondaryText != "" {
+		sb.WriteString(`<a class="landing-button landing-button--ghost" data-track="cta_secondary" href="`)
+		sb.WriteString(secondaryURL)
+		sb.WriteString(`">`)
+		sb.WriteString(secondaryText)
+		sb.WriteString(`</a>`)
+	}
+	sb.WriteString(`</div></div></section>`)
+	return sb.String()
+}
+
+func renderGallery(props map[string]interface{}, _ RenderContext) string {
+	title := html.EscapeString(StringProp(props, "title", "Галерея"))
+	subtitle := html.EscapeString(StringProp(props, "subtitle", ""))
+	columns := IntProp(props, "columns", 3)
+	if columns < 2 || columns > 4 {
+		columns = 3
+	}
+	items := Slice(props["items"])
+
+	var sb strings.Builder
+	sb.WriteString(`<section class="landing-section landing-section--gallery" data-block="gallery"><div class="landing-container">`)
+	sb.WriteString(`<div class="landing-section-header">`)
+	sb.WriteString(fmt.Sprintf(`<h2 class="landing-section-title">%s</h2>`, title))
+	if subtitle != "" {
+		sb.WriteString(fmt.Sprintf(`<p class="landing-section-subtitle">%s</p>`, subtitle))
+	}
+	sb.WriteString(`</div>`)
+	sb.WriteString(fmt.Sprintf(`<div class="landing-gallery__grid landing-gallery__grid--%d">`, columns))
+
+	rendered := 0
+	for _, item := range items {
+		image := html.EscapeString(StringProp(item, "image", ""))
+		if image == "" {
+			continue
+		}
+		caption := html.EscapeString(StringProp(item, "caption", ""))
+		alt := html.EscapeString(StringProp(item, "alt", StringProp(item, "caption", "")))
+
+		sb.WriteString(`<figure class="gallery-item"><img src="`)
+		sb.WriteString(image)
+		sb.WriteString(`" alt="`)
+		sb.WriteString(alt)
+		sb.WriteString(`" loading="lazy" />`)
+		if caption != "" {
+			sb.WriteString(fmt.Sprintf(`<figcaption class="gallery-caption">%s</figcaption>`, caption))
+		}
+		sb.WriteString(`</figure>`)
+		rendered++
+	}
+
+	if rendered == 0 {
+		sb.WriteString(`<div class="landing-card"><div class="landing-empty-state">Добавьте изображения, чтобы показать их здесь</div></div>`)
+	}
+
+	sb.WriteString(`</div></div></section>`)
+	return sb.String()
+}
+
+func renderAbout(props map[string]interface{}, _ RenderContext) string {
+	title := html.EscapeString(StringProp(props, "title", "О нас"))
+	text := StringProp(props, "text", "")
+	image := html.EscapeString(StringProp(props, "image", ""))
+	imageAlt := html.EscapeString(StringProp(props, "imageAlt", StringProp(props, "title", "О нас")))
+	stats := Slice(props["stats"])
+	team := Slice(props["team"])
+
+	var sb strings.Builder
+	sb.WriteString(`<section class="landing-section landing-section--about" data-block="about"><div class="landing-container">`)
+	sb.WriteString(`<div class="landing-about__grid`)
+	if image == "" {
+		sb.WriteString(` landing-about__grid--single`)
+	}
+	sb.WriteString(`"><div class="landing-about__content">`)
+	sb.WriteString(fmt.Sprintf(`<h2 class="landing-section-title">%s</h2>`, title))
+	for _, paragraph := range splitParagraphs(text) {
+		sb.WriteString(fmt.Sprintf(`<p>%s</p>`, html.EscapeString(paragraph)))
+	}
+
+	if len(stats) > 0 {
+		sb.WriteString(`<dl class="about-stats">`)
+		for _, stat := range stats {
+			value := html.EscapeString(StringProp(stat, "value", ""))
+			label := html.EscapeString(StringProp(stat, "label", ""))
+			sb.WriteString(fmt.Sprintf(`<div class="about-stat"><dt>%s</dt><dd>%s</dd></div>`, label, value))
+		}
+		sb.WriteString(`</dl>`)
+	}
+	sb.WriteString(`</div>`)
+
+	if image != "" {
+		sb.WriteString(`<div class="landing-about__media"><img src="`)
+		sb.WriteString(image)
+		sb.WriteString(`" alt="`)
+		sb.WriteString(imageAlt)
+		sb.WriteString(`" loading="lazy" /></div>`)
+	}
+	sb.WriteString(`</div>`)
+
+	if len(team) > 0 {
+		sb.WriteString(`<div class="landing-team__grid">`)
+		for _, member := range team {
+			name := html.EscapeString(StringProp(member, "name", ""))
+			role := html.EscapeString(StringProp(member, "role", ""))
+			bio := html.EscapeString(StringProp(member, "bio", ""))
+			photo := html.EscapeString(StringProp(member, "photo", ""))
+
+			sb.WriteString(`<div class="landing-card team-card">`)
+			if photo != "" {
+				sb.WriteString(fmt.Sprintf(`<img class="team-photo" src="%s" alt="%s" loading="lazy" />`, photo, name))
+			}
+			sb.WriteString(fmt.Sprintf(`<h3>%s</h3>`, name))
+			if role != "" {
+				sb.WriteString(fmt.Sprintf(`<span class="team-role">%s</span>`, role))
+			}
+			if bio != "" {
+				sb.WriteString(fmt.Sprintf(`<p>%s</p>`, bio))
+			}
+			sb.WriteString(`</div>`)
+		}
+		sb.WriteString(`</div>`)
+	}
+
+	if text == "" && len(stats) == 0 && len(team) == 0 {
+		sb.WriteString(`<div class="landing-card"><div class="landing-empty-state">Расскажите о себе в описании проекта</div></div>`)
+	}
+
+	sb.WriteString(`</div></section>`)
+	return sb.String()
+}
+
+func renderContact(props map[string]interface{}, _ RenderContext) string {
+	title := html.EscapeString(StringProp(props, "title", "Контакты"))
+	description := html.EscapeString(StringProp(props, "description", ""))
+	email := StringProp(props, "email", "")
+	phone := StringProp(props, "phone", "")
+	address := html.EscapeString(StringProp(props, "address", ""))
+	hours := html.EscapeString(StringProp(props, "hours", ""))
+	socials := Slice(props["socials"])
+	mapURL := contactMapURL(props)
+
+	var sb strings.Builder
+	sb.WriteString(`<section class="landing-section landing-section--contact" data-block="contact"><div class="landing-container">`)
+	sb.WriteString(`<div class="landing-section-header">`)
+	sb.WriteString(fmt.Sprintf(`<h2 class="landing-section-title">%s</h2>`, title))
+	if description != "" {
+		sb.WriteString(fmt.Sprintf(`<p class="landing-section-subtitle">%s</p>`, description))
+	}
+	sb.WriteString(`</div>`)
+
+	sb.WriteString(`<div class="landing-contact__grid`)
+	if mapURL == "" {
+		sb.WriteString(` landing-contact__grid--single`)
+	}
+	sb.WriteString(`"><div class="landing-card contact-card"><ul class="contact-list">`)
+	if email != "" {
+		sb.WriteString(fmt.Sprintf(`<li class="contact-item"><span class="contact-label">Email</span><a href="mailto:%s" data-track="contact_email">%s</a></li>`,
+			html.EscapeString(email), html.EscapeString(email)))
+	}
+	if phone != "" {
+		sb.WriteString(fmt.Sprintf(`<li class="contact-item"><span class="contact-label">Телефон</span><a href="tel:%s" data-track="contact_phone">%s</a></li>`,
+			html.EscapeString(phoneHref(phone)), html.EscapeString(phone)))
+	}
+	if address != "" {
+		sb.WriteString(fmt.Sprintf(`<li class="contact-item"><span class="contact-label">Адрес</span><span>%s</span></li>`, address))
+	}
+	if hours != "" {
+		sb.WriteString(fmt.Sprintf(`<li class="contact-item"><span class="contact-label">Часы работы</span><span>%s</span></li>`, hours))
+	}
+	if email == "" && phone == "" && address == "" && hours == "" {
+		sb.WriteString(`<li class="contact-item"><span class="landing-empty-state">Добавьте контакты в описании проекта</span></li>`)
+	}
+	sb.WriteString(`</ul>`)
+
+	if len(socials) > 0 {
+		sb.WriteString(`<div class="contact-socials">`)
+		for _, social := range socials {
+			label := html.EscapeString(StringProp(social, "label", ""))
+			link := html.EscapeString(StringProp(social, "url", ""))
+			if label == "" || link == "" {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf(`<a class="contact-social" href="%s" target="_blank" rel="noopener noreferrer">%s</a>`, link, label))
+		}
+		sb.WriteString(`</div>`)
+	}
+	sb.WriteString(`</div>`)
+
+	if mapURL != "" {
+		sb.WriteString(fmt.Sprintf(`<div class="contact-map"><iframe src="%s" title="%s" loading="lazy" referrerpolicy="no-referrer-when-downgrade" allowfullscreen></iframe></div>`,
+			html.EscapeString(mapURL), title))
+	}
+
+	sb.WriteString(`</div></div></section>`)
+	return sb.String()
+}
+
+// contactMapURL возвращает адрес карты для iframe: mapEmbedUrl (только https)
+// либо поиск по mapQuery/адресу, если showMap=true
+func contactMapURL(props map[string]interface{}) string {
+	if embedURL := StringProp(props, "mapEmbedUrl", ""); strings.HasPrefix(embedURL, "https://") {
+		return embedURL
+	}
+
+	query := StringProp(props, "mapQuery", "")
+	if query == "" && BoolProp(props, "showMap") {
+		query = StringProp(props, "address", "")
+	}
+	if query == "" {
+		return ""
+	}
+
+	return "https://maps.google.com/maps?output=embed&q=" + url.QueryEscape(query)
+}
+
+// phoneHref оставляет в номере только цифры и ведущий +
+func phoneHref(phone string) string {
+	var sb strings.Builder
+	for i, ch := range phone {
+		if (ch >= '0' && ch <= '9') || (ch == '+' && i == 0) {
+			sb.WriteRune(ch)
+		}
+	}
+	return sb.String()
+}
+
+func splitParagraphs(text string) []string {
+	var paragraphs []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		if trimmed := strings.TrimSpace(paragraph); trimmed != "" {
+			paragraphs = append(paragraphs, trimmed)
+		}
+	}
+	return paragraphs
+}
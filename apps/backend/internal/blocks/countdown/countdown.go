@@ -0,0 +1,130 @@
+// Package countdown блок обратного отсчёта до дедлайна акции.
+// Регистрируется в blocks.Default при импорте пакета.
+package countdown
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/landly/backend/internal/blocks"
+)
+
+// Type тип блока в схеме лендинга
+const Type = "countdown"
+
+func init() {
+	blocks.MustRegister(Definition())
+}
+
+// Definition описание блока для реестра
+func Definition() blocks.Definition {
+	return blocks.Definition{
+		Type:  Type,
+		Label: "Акция",
+		Hint:  "title, deadline (RFC3339), expiredText, ctaText, ctaUrl",
+		PropsSchema: `{
+			"type": "object",
+			"required": ["deadline"],
+			"properties": {
+				"title": {"type": "string"},
+				"deadline": {"type": "string", "pattern": "^\\d{4}-\\d{2}-\\d{2}T\\d{2}:\\d{2}:\\d{2}"},
+				"expiredText": {"type": "string"},
+				"ctaText": {"type": "string"},
+				"ctaUrl": {"type": "string"}
+			}
+		}`,
+		Example:  `{"title":"Скидка 30% до конца недели","deadline":"2030-01-01T00:00:00+03:00","expiredText":"Акция завершена","ctaText":"Успеть купить","ctaUrl":"#pricing"}`,
+		Renderer: blocks.RendererFunc(render),
+		CSS:      css,
+	}
+}
+
+// script обновляет счётчики раз в секунду; без JS остаётся дата дедлайна
+const script = `<script>(function(){var s=document.currentScript.parentNode;var d=Date.parse(s.dataset.deadline);function u(){var l=Math.max(0,d-Date.now());if(l===0){s.classList.add('landing-countdown--expired');return;}var t=Math.floor(l/1000);var v={days:Math.floor(t/86400),hours:Math.floor(t%86400/3600),minutes:Math.floor(t%3600/60),seconds:t%60};Object.keys(v).forEach(function(k){var e=s.querySelector('[data-unit="'+k+'"]');if(e){e.textContent=String(v[k]).padStart(2,'0');}});setTimeout(u,1000);}u();})();</script>`
+
+func render(props map[string]interface{}, _ blocks.RenderContext) string {
+	title := html.EscapeString(blocks.StringProp(props, "title", "До конца акции осталось"))
+	expiredText := html.EscapeString(blocks.StringProp(props, "expiredText", "Акция завершена"))
+	ctaText := html.EscapeString(blocks.StringProp(props, "ctaText", ""))
+	ctaURL := html.EscapeString(blocks.StringProp(props, "ctaUrl", "#"))
+
+	deadline, err := time.Parse(time.RFC3339, blocks.StringProp(props, "deadline", ""))
+	if err != nil {
+		return `<section class="landing-section landing-section--countdown" data-block="countdown"><div class="landing-container"><div class="landing-empty-state">Укажите дату окончания акции</div></div></section>`
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<section class="landing-section landing-section--countdown" data-block="countdown" data-deadline="%s"><div class="landing-container">`, deadline.UTC().Format(time.RFC3339)))
+	sb.WriteString(fmt.Sprintf(`<div class="landing-section-header"><h2 class="landing-section-title">%s</h2></div>`, title))
+	sb.WriteString(`<div class="landing-countdown__timer">`)
+	for _, unit := range []struct{ key, label string }{
+		{"days", "дней"},
+		{"hours", "часов"},
+		{"minutes", "минут"},
+		{"seconds", "секунд"},
+	} {
+		sb.WriteString(fmt.Sprintf(`<div class="landing-countdown__unit"><span class="landing-countdown__value" data-unit="%s">--</span><span class="landing-countdown__label">%s</span></div>`, unit.key, unit.label))
+	}
+	sb.WriteString(`</div>`)
+	sb.WriteString(fmt.Sprintf(`<p class="landing-countdown__expired">%s</p>`, expiredText))
+	if ctaText != "" {
+		sb.WriteString(fmt.Sprintf(`<div class="landing-countdown__actions"><a class="landing-button landing-button--primary" href="%s" data-track="cta_click">%s</a></div>`, ctaURL, ctaText))
+	}
+	sb.WriteString(script)
+	sb.WriteString(`</div></section>`)
+	return sb.String()
+}
+
+const css = `.landing-countdown__timer {
+  display: flex;
+  justify-content: center;
+  gap: 1rem;
+  flex-wrap: wrap;
+}
+
+.landing-countdown__unit {
+  display: flex;
+  flex-direction: column;
+  align-items: center;
+  min-width: 5rem;
+  padding: 1rem;
+  border-radius: 1rem;
+  background: rgba(255, 255, 255, 0.08);
+  box-shadow: 0 10px 30px rgba(15, 23, 42, 0.08);
+}
+
+.landing-countdown__value {
+  font-size: 2.5rem;
+  font-weight: 700;
+  color: var(--landing-primary);
+  font-variant-numeric: tabular-nums;
+}
+
+.landing-countdown__label {
+  font-size: 0.875rem;
+  opacity: 0.7;
+}
+
+.landing-countdown__expired {
+  display: none;
+  text-align: center;
+  font-weight: 600;
+}
+
+.landing-countdown--expired .landing-countdown__timer,
+.landing-countdown--expired .landing-countdown__actions {
+  display: none;
+}
+
+.landing-countdown--expired .landing-countdown__expired {
+  display: block;
+}
+
+.landing-countdown__actions {
+  display: flex;
+  justify-content: center;
+  margin-top: 2rem;
+}
+`
@@ -0,0 +1,38 @@
+package countdown
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/landly/backend/internal/blocks"
+)
+
+func TestRegistered(t *testing.T) {
+	def, ok := blocks.Default.Get(Type)
+	require.True(t, ok)
+	assert.NotEmpty(t, def.CSS)
+}
+
+func TestRender(t *testing.T) {
+	var props map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(Definition().Example), &props))
+
+	html := render(props, blocks.RenderContext{})
+	assert.Contains(t, html, `data-block="countdown"`)
+	assert.Contains(t, html, `data-deadline="2029-12-31T21:00:00Z"`)
+	assert.Contains(t, html, "Скидка 30% до конца недели")
+	assert.Contains(t, html, "Акция завершена")
+	assert.Contains(t, html, `href="#pricing"`)
+	assert.Contains(t, html, `data-unit="seconds"`)
+	assert.Contains(t, html, "<script>")
+}
+
+func TestRender_InvalidDeadline(t *testing.T) {
+	html := render(map[string]interface{}{"title": "<b>Скоро</b>", "deadline": "завтра"}, blocks.RenderContext{})
+	assert.Contains(t, html, "Укажите дату окончания акции")
+	assert.NotContains(t, html, "<script>")
+	assert.NotContains(t, html, "<b>")
+}
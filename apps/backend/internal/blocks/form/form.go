@@ -0,0 +1,325 @@
+// Package form блок формы заявки: поля задаются в схеме, заявки принимает API.
+// Регистрируется в blocks.Default при импорте пакета.
+package form
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/landly/backend/internal/blocks"
+)
+
+// Type тип блока в схеме лендинга
+const Type = "form"
+
+// DefaultName имя формы, если в props не задано name
+const DefaultName = "lead"
+
+// HoneypotField скрытое поле-ловушка: его заполняют только боты
+const HoneypotField = "_hp"
+
+// Типы полей формы
+const (
+	FieldText     = "text"
+	FieldEmail    = "email"
+	FieldTel      = "tel"
+	FieldTextarea = "textarea"
+	FieldSelect   = "select"
+	FieldCheckbox = "checkbox"
+)
+
+var fieldTypes = map[string]bool{
+	FieldText:     true,
+	FieldEmail:    true,
+	FieldTel:      true,
+	FieldTextarea: true,
+	FieldSelect:   true,
+	FieldCheckbox: true,
+}
+
+// fieldNamePattern имена полей: ключи заявки и колонки CSV; "_" зарезервирован под служебные поля
+var fieldNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,63}$`)
+
+func init() {
+	blocks.MustRegister(Definition())
+}
+
+// Definition описание блока для реестра
+func Definition() blocks.Definition {
+	return blocks.Definition{
+		Type:  Type,
+		Label: "Заявка",
+		Hint:  "name (уникальное имя формы, по умолчанию lead), title, description, submitText, successText, fields[{name,label,type(text|email|tel|textarea|select|checkbox),required,placeholder,options[]}]",
+		PropsSchema: `{
+			"type": "object",
+			"properties": {
+				"name": {"type": "string", "pattern": "^[a-zA-Z][a-zA-Z0-9_-]{0,63}$"},
+				"title": {"type": "string"},
+				"description": {"type": "string"},
+				"submitText": {"type": "string"},
+				"successText": {"type": "string"},
+				"fields": {
+					"type": "array",
+					"items": {
+						"type": "object",
+						"required": ["name"],
+						"properties": {
+							"name": {"type": "string", "pattern": "^[a-zA-Z][a-zA-Z0-9_]{0,63}$"},
+							"label": {"type": "string"},
+							"type": {"type": "string", "enum": ["text", "email", "tel", "textarea", "select", "checkbox"]},
+							"required": {"type": "boolean"},
+							"placeholder": {"type": "string"},
+							"options": {"type": "array", "items": {"type": "string"}}
+						}
+					}
+				}
+			}
+		}`,
+		Example:  `{"name":"lead","title":"Оставьте заявку","submitText":"Отправить","successText":"Спасибо! Мы свяжемся с вами","fields":[{"name":"name","label":"Имя","required":true},{"name":"email","label":"Email","type":"email","required":true}]}`,
+		Renderer: blocks.RendererFunc(render),
+		CSS:      css,
+	}
+}
+
+// Field поле формы
+type Field struct {
+	Name        string
+	Label       string
+	Type        string
+	Required    bool
+	Placeholder string
+	Options     []string
+}
+
+// Form форма из props блока
+type Form struct {
+	Name   string
+	Fields []Field
+}
+
+// Parse разбирает props блока. Поля с недопустимым или повторным именем пропускаются,
+// неизвестный тип поля считается text.
+func Parse(props map[string]interface{}) Form {
+	form := Form{Name: blocks.StringProp(props, "name", "")}
+	if form.Name == "" {
+		form.Name = DefaultName
+	}
+
+	seen := make(map[string]bool)
+	for _, item := range blocks.Slice(props["fields"]) {
+		name := blocks.StringProp(item, "name", "")
+		if !fieldNamePattern.MatchString(name) || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		field := Field{
+			Name:        name,
+			Label:       blocks.StringProp(item, "label", name),
+			Type:        blocks.StringProp(item, "type", FieldText),
+			Required:    blocks.BoolProp(item, "required"),
+			Placeholder: blocks.StringProp(item, "placeholder", ""),
+			Options:     blocks.StringSlice(item["options"]),
+		}
+		if !fieldTypes[field.Type] {
+			field.Type = FieldText
+		}
+		if field.Type == FieldSelect && len(field.Options) == 0 {
+			field.Type = FieldText
+		}
+		form.Fields = append(form.Fields, field)
+	}
+	return form
+}
+
+// Find ищет форму с именем name на страницах схемы сайта
+func Find(schema map[string]interface{}, name string) (Form, bool) {
+	for _, form := range All(schema) {
+		if form.Name == name {
+			return form, true
+		}
+	}
+	return Form{}, false
+}
+
+// All возвращает формы схемы в порядке страниц; из форм с одинаковым именем берётся первая
+func All(schema map[string]interface{}) []Form {
+	var forms []Form
+	seen := make(map[string]bool)
+	for _, page := range blocks.Slice(schema["pages"]) {
+		for _, block := range blocks.Slice(page["blocks"]) {
+			if blocks.StringProp(block, "type", "") != Type {
+				continue
+			}
+			props, _ := block["props"].(map[string]interface{})
+			form := Parse(props)
+			if seen[form.Name] {
+				continue
+			}
+			seen[form.Name] = true
+			forms = append(forms, form)
+		}
+	}
+	return forms
+}
+
+// script при загрузке страницы берёт у API подписанный токен с временем выдачи, по которому
+// сервер считает время заполнения формы, и отправляет заявку JSON-ом в text/plain (без CORS preflight, как analytics.js)
+const script = `<script>(function(){var f=document.currentScript.parentNode.querySelector('form');var k='';fetch(f.dataset.token).then(function(r){return r.ok?r.json():{};}).then(function(d){k=d.token||'';}).catch(function(){});var s=f.querySelector('.landing-form__status');f.addEventListener('submit',function(e){e.preventDefault();var v={};Array.prototype.forEach.call(f.elements,function(el){if(!el.name||el.name==='` + HoneypotField + `'){return;}v[el.name]=el.type==='checkbox'?(el.checked?'true':''):el.value;});var b=f.querySelector('button[type=submit]');b.disabled=true;fetch(f.dataset.endpoint,{method:'POST',headers:{'Content-Type':'text/plain'},body:JSON.stringify({form:f.dataset.form,fields:v,path:f.dataset.path,variant:f.dataset.variant,honeypot:f.elements['` + HoneypotField + `'].value,token:k})}).then(function(r){if(!r.ok){throw r;}f.classList.add('landing-form--sent');s.textContent=f.dataset.success;}).catch(function(){b.disabled=false;s.textContent=f.dataset.error;});});})();</script>`
+
+func render(props map[string]interface{}, rc blocks.RenderContext) string {
+	form := Parse(props)
+	title := html.EscapeString(blocks.StringProp(props, "title", "Оставьте заявку"))
+	description := html.EscapeString(blocks.StringProp(props, "description", ""))
+	submitText := html.EscapeString(blocks.StringProp(props, "submitText", "Отправить"))
+	successText := html.EscapeString(blocks.StringProp(props, "successText", "Спасибо! Мы свяжемся с вами"))
+
+	var sb strings.Builder
+	sb.WriteString(`<section class="landing-section landing-section--form" data-block="form"><div class="landing-container">`)
+	sb.WriteString(fmt.Sprintf(`<div class="landing-section-header"><h2 class="landing-section-title">%s</h2>`, title))
+	if description != "" {
+		sb.WriteString(fmt.Sprintf(`<p class="landing-section-subtitle">%s</p>`, description))
+	}
+	sb.WriteString(`</div>`)
+
+	if len(form.Fields) == 0 {
+		sb.WriteString(`<div class="landing-card"><div class="landing-empty-state">Добавьте поля формы в описании проекта</div></div></div></section>`)
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf(`<div class="landing-card landing-form__card"><form class="landing-form" data-form="%s" data-endpoint="%s" data-token="%s" data-path="%s" data-variant="%s" data-success="%s" data-error="%s">`,
+		html.EscapeString(form.Name), html.EscapeString(submitURL(rc.FormEndpoint)), html.EscapeString(tokenURL(rc.FormEndpoint, form.Name)), html.EscapeString(rc.Path), html.EscapeString(rc.Variant),
+		successText, "Не удалось отправить заявку, попробуйте ещё раз"))
+	for _, field := range form.Fields {
+		sb.WriteString(renderField(form.Name, field))
+	}
+	// Ловушка скрыта от людей и вспомогательных технологий, но видна ботам, заполняющим все поля
+	sb.WriteString(fmt.Sprintf(`<div class="landing-form__trap" aria-hidden="true"><input type="text" name="%s" tabindex="-1" autocomplete="off" /></div>`, HoneypotField))
+
+	disabled := ""
+	if rc.FormEndpoint == "" {
+		disabled = " disabled"
+	}
+	sb.WriteString(fmt.Sprintf(`<button type="submit" class="landing-button landing-button--primary" data-track="form_submit"%s>%s</button>`, disabled, submitText))
+	sb.WriteString(`<p class="landing-form__status" role="status"></p>`)
+	sb.WriteString(`</form>`)
+	if rc.FormEndpoint != "" {
+		sb.WriteString(script)
+	}
+	sb.WriteString(`</div></div></section>`)
+	return sb.String()
+}
+
+func submitURL(endpoint string) string {
+	if endpoint == "" {
+		return ""
+	}
+	return endpoint + "/submissions"
+}
+
+func tokenURL(endpoint, formName string) string {
+	if endpoint == "" {
+		return ""
+	}
+	return endpoint + "/token?form=" + url.QueryEscape(formName)
+}
+
+func renderField(formName string, field Field) string {
+	id := html.EscapeString(formName + "-" + field.Name)
+	name := html.EscapeString(field.Name)
+	label := html.EscapeString(field.Label)
+	placeholder := html.EscapeString(field.Placeholder)
+	required := ""
+	if field.Required {
+		required = " required"
+	}
+
+	var sb strings.Builder
+	if field.Type == FieldCheckbox {
+		sb.WriteString(`<div class="landing-form__field landing-form__field--checkbox">`)
+		sb.WriteString(fmt.Sprintf(`<label for="%s"><input type="checkbox" id="%s" name="%s"%s /> %s</label>`, id, id, name, required, label))
+		sb.WriteString(`</div>`)
+		return sb.String()
+	}
+
+	sb.WriteString(`<div class="landing-form__field">`)
+	sb.WriteString(fmt.Sprintf(`<label class="landing-form__label" for="%s">%s</label>`, id, label))
+	switch field.Type {
+	case FieldTextarea:
+		sb.WriteString(fmt.Sprintf(`<textarea class="landing-form__input" id="%s" name="%s" rows="4" placeholder="%s"%s></textarea>`, id, name, placeholder, required))
+	case FieldSelect:
+		sb.WriteString(fmt.Sprintf(`<select class="landing-form__input" id="%s" name="%s"%s>`, id, name, required))
+		sb.WriteString(fmt.Sprintf(`<option value="">%s</option>`, placeholder))
+		for _, option := range field.Options {
+			option = html.EscapeString(option)
+			sb.WriteString(fmt.Sprintf(`<option value="%s">%s</option>`, option, option))
+		}
+		sb.WriteString(`</select>`)
+	default:
+		sb.WriteString(fmt.Sprintf(`<input class="landing-form__input" type="%s" id="%s" name="%s" placeholder="%s"%s />`, field.Type, id, name, placeholder, required))
+	}
+	sb.WriteString(`</div>`)
+	return sb.String()
+}
+
+const css = `.landing-form__card {
+  max-width: 36rem;
+  margin: 0 auto;
+}
+
+.landing-form {
+  display: flex;
+  flex-direction: column;
+  gap: 1rem;
+}
+
+.landing-form__field {
+  display: flex;
+  flex-direction: column;
+  gap: 0.375rem;
+}
+
+.landing-form__label {
+  font-weight: 600;
+  font-size: 0.875rem;
+}
+
+.landing-form__input {
+  padding: 0.75rem 1rem;
+  border: 1px solid var(--landing-border);
+  border-radius: var(--landing-radius-md);
+  font: inherit;
+  color: inherit;
+  background: transparent;
+}
+
+.landing-form__input:focus {
+  outline: 2px solid var(--landing-primary);
+  outline-offset: 1px;
+}
+
+.landing-form__trap {
+  position: absolute;
+  left: -10000px;
+  width: 1px;
+  height: 1px;
+  overflow: hidden;
+}
+
+.landing-form__status:empty {
+  display: none;
+}
+
+.landing-form--sent .landing-form__field,
+.landing-form--sent button {
+  display: none;
+}
+
+.landing-form--sent .landing-form__status {
+  font-weight: 600;
+  text-align: center;
+}
+`
@@ -0,0 +1,110 @@
+package form
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/landly/backend/internal/blocks"
+)
+
+var leadProps = map[string]interface{}{
+	"name":  "callback",
+	"title": "Перезвоним",
+	"fields": []interface{}{
+		map[string]interface{}{"name": "name", "label": "Имя", "required": true},
+		map[string]interface{}{"name": "email", "label": "Email", "type": "email"},
+		map[string]interface{}{"name": "plan", "type": "select", "options": []interface{}{"Базовый", "Про"}},
+		map[string]interface{}{"name": "agree", "label": "Согласен", "type": "checkbox", "required": true},
+		map[string]interface{}{"name": "_hp", "label": "Служебное"},
+		map[string]interface{}{"name": "name", "label": "Дубль"},
+	},
+}
+
+func TestRegistered(t *testing.T) {
+	def, ok := blocks.Default.Get(Type)
+	require.True(t, ok)
+	assert.NotEmpty(t, def.CSS)
+}
+
+func TestParse(t *testing.T) {
+	form := Parse(leadProps)
+	assert.Equal(t, "callback", form.Name)
+	require.Len(t, form.Fields, 4, "reserved and duplicate names are skipped")
+	assert.Equal(t, Field{Name: "name", Label: "Имя", Type: FieldText, Required: true}, form.Fields[0])
+	assert.Equal(t, FieldSelect, form.Fields[2].Type)
+	assert.Equal(t, []string{"Базовый", "Про"}, form.Fields[2].Options)
+
+	assert.Equal(t, DefaultName, Parse(map[string]interface{}{}).Name)
+}
+
+func TestFind(t *testing.T) {
+	schema := map[string]interface{}{"pages": []interface{}{
+		map[string]interface{}{"path": "/", "blocks": []interface{}{
+			map[string]interface{}{"type": "hero", "props": map[string]interface{}{}},
+			map[string]interface{}{"type": Type, "props": leadProps},
+		}},
+		map[string]interface{}{"path": "/contacts", "blocks": []interface{}{
+			map[string]interface{}{"type": Type, "props": map[string]interface{}{"fields": []interface{}{
+				map[string]interface{}{"name": "phone", "type": "tel"},
+			}}},
+		}},
+	}}
+
+	form, ok := Find(schema, DefaultName)
+	require.True(t, ok)
+	assert.Equal(t, "phone", form.Fields[0].Name)
+
+	_, ok = Find(schema, "missing")
+	assert.False(t, ok)
+	assert.Len(t, All(schema), 2)
+}
+
+func TestClean(t *testing.T) {
+	form := Parse(leadProps)
+
+	values, err := form.Clean(map[string]string{"name": " Анна ", "email": "anna@example.com", "plan": "Про", "agree": "on", "extra": "dropped"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"name": "Анна", "email": "anna@example.com", "plan": "Про", "agree": "true"}, values)
+
+	cases := map[string]map[string]string{
+		"missing required": {"agree": "true"},
+		"unchecked box":    {"name": "Анна", "agree": ""},
+		"bad email":        {"name": "Анна", "agree": "true", "email": "Anna <anna@example.com>"},
+		"unknown option":   {"name": "Анна", "agree": "true", "plan": "VIP"},
+	}
+	for name, values := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := form.Clean(values)
+			assert.Error(t, err)
+		})
+	}
+
+	phone := Form{Fields: []Field{{Name: "phone", Type: FieldTel}}}
+	_, err = phone.Clean(map[string]string{"phone": "+7 (900) 123-45-67"})
+	assert.NoError(t, err)
+	_, err = phone.Clean(map[string]string{"phone": "call me"})
+	assert.Error(t, err)
+}
+
+func TestRender(t *testing.T) {
+	html := render(leadProps, blocks.RenderContext{FormEndpoint: "/v1/forms/p1", Path: "/", Variant: "control"})
+
+	assert.Contains(t, html, `data-form="callback" data-endpoint="/v1/forms/p1/submissions" data-token="/v1/forms/p1/token?form=callback" data-path="/" data-variant="control"`)
+	assert.Contains(t, html, `<input class="landing-form__input" type="text" id="callback-name" name="name" placeholder="" required />`)
+	assert.Contains(t, html, `<option value="Про">Про</option>`)
+	assert.Contains(t, html, `<input type="checkbox" id="callback-agree" name="agree" required /> Согласен`)
+	assert.Contains(t, html, `name="_hp" tabindex="-1"`)
+	assert.Contains(t, html, `data-track="form_submit">`)
+	assert.Contains(t, html, "<script>")
+}
+
+func TestRender_WithoutEndpoint(t *testing.T) {
+	html := render(leadProps, blocks.RenderContext{})
+	assert.Contains(t, html, `data-track="form_submit" disabled>`)
+	assert.NotContains(t, html, "<script>")
+
+	empty := render(map[string]interface{}{}, blocks.RenderContext{})
+	assert.Contains(t, empty, "Добавьте поля формы")
+}
@@ -0,0 +1,78 @@
+package form
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxValueLength    = 1000
+	maxTextareaLength = 5000
+)
+
+var telPattern = regexp.MustCompile(`^\+?[0-9\s()\-]{5,32}$`)
+
+// Clean проверяет заявку по полям формы и возвращает значения известных полей.
+// Лишние поля отбрасываются; checkbox приводится к "true" или пустой строке.
+func (f Form) Clean(values map[string]string) (map[string]string, error) {
+	cleaned := make(map[string]string, len(f.Fields))
+	for _, field := range f.Fields {
+		value := strings.TrimSpace(values[field.Name])
+		if field.Type == FieldCheckbox {
+			value = checkboxValue(value)
+		}
+
+		if value == "" {
+			if field.Required {
+				return nil, fmt.Errorf("field %s is required", field.Name)
+			}
+			continue
+		}
+		if err := validateValue(field, value); err != nil {
+			return nil, err
+		}
+		cleaned[field.Name] = value
+	}
+	return cleaned, nil
+}
+
+func validateValue(field Field, value string) error {
+	limit := maxValueLength
+	if field.Type == FieldTextarea {
+		limit = maxTextareaLength
+	}
+	if utf8.RuneCountInString(value) > limit {
+		return fmt.Errorf("field %s is too long", field.Name)
+	}
+
+	switch field.Type {
+	case FieldEmail:
+		addr, err := mail.ParseAddress(value)
+		if err != nil || addr.Address != value {
+			return fmt.Errorf("field %s must be an email address", field.Name)
+		}
+	case FieldTel:
+		if !telPattern.MatchString(value) {
+			return fmt.Errorf("field %s must be a phone number", field.Name)
+		}
+	case FieldSelect:
+		for _, option := range field.Options {
+			if option == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("field %s has an unknown option", field.Name)
+	}
+	return nil
+}
+
+func checkboxValue(value string) string {
+	switch strings.ToLower(value) {
+	case "true", "on", "yes", "1":
+		return "true"
+	}
+	return ""
+}
@@ -0,0 +1,135 @@
+// Package logos блок с логотипами клиентов и партнёров.
+// Регистрируется в blocks.Default при импорте пакета.
+package logos
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/landly/backend/internal/blocks"
+)
+
+// Type тип блока в схеме лендинга
+const Type = "logos"
+
+func init() {
+	blocks.MustRegister(Definition())
+}
+
+// Definition описание блока для реестра
+func Definition() blocks.Definition {
+	return blocks.Definition{
+		Type:  Type,
+		Label: "Клиенты",
+		Hint:  "title, items[{name,image,url}], grayscale(bool)",
+		PropsSchema: `{
+			"type": "object",
+			"properties": {
+				"title": {"type": "string"},
+				"grayscale": {"type": "boolean"},
+				"items": {
+					"type": "array",
+					"items": {
+						"type": "object",
+						"properties": {
+							"name": {"type": "string"},
+							"image": {"type": "string"},
+							"url": {"type": "string"}
+						}
+					}
+				}
+			}
+		}`,
+		Example:  `{"title":"Нам доверяют","grayscale":true,"items":[{"name":"Acme","image":"https://example.com/acme.svg","url":"https://acme.example.com"}]}`,
+		Renderer: blocks.RendererFunc(render),
+		CSS:      css,
+	}
+}
+
+func render(props map[string]interface{}, _ blocks.RenderContext) string {
+	title := html.EscapeString(blocks.StringProp(props, "title", "Нам доверяют"))
+	items := blocks.Slice(props["items"])
+
+	classes := "landing-logos__grid"
+	if blocks.BoolProp(props, "grayscale") {
+		classes += " landing-logos__grid--grayscale"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<section class="landing-section landing-section--logos" data-block="logos"><div class="landing-container">`)
+	sb.WriteString(fmt.Sprintf(`<div class="landing-section-header"><h2 class="landing-section-title">%s</h2></div>`, title))
+	sb.WriteString(fmt.Sprintf(`<div class="%s">`, classes))
+
+	rendered := 0
+	for _, item := range items {
+		name := html.EscapeString(blocks.StringProp(item, "name", ""))
+		image := html.EscapeString(blocks.StringProp(item, "image", ""))
+		link := html.EscapeString(blocks.StringProp(item, "url", ""))
+		if name == "" && image == "" {
+			continue
+		}
+
+		var logo string
+		if image != "" {
+			logo = fmt.Sprintf(`<img src="%s" alt="%s" loading="lazy" />`, image, name)
+		} else {
+			logo = fmt.Sprintf(`<span class="logo-item__name">%s</span>`, name)
+		}
+
+		if link != "" {
+			sb.WriteString(fmt.Sprintf(`<a class="logo-item" href="%s" target="_blank" rel="noopener">%s</a>`, link, logo))
+		} else {
+			sb.WriteString(fmt.Sprintf(`<div class="logo-item">%s</div>`, logo))
+		}
+		rendered++
+	}
+
+	if rendered == 0 {
+		sb.WriteString(`<div class="landing-card"><div class="landing-empty-state">Добавьте логотипы клиентов или партнёров</div></div>`)
+	}
+
+	sb.WriteString(`</div></div></section>`)
+	return sb.String()
+}
+
+const css = `.landing-logos__grid {
+  display: flex;
+  flex-wrap: wrap;
+  justify-content: center;
+  align-items: center;
+  gap: 2rem 3rem;
+}
+
+.logo-item {
+  display: flex;
+  align-items: center;
+  justify-content: center;
+  height: 3rem;
+  color: inherit;
+  text-decoration: none;
+  transition: opacity 0.2s ease, filter 0.2s ease;
+}
+
+.logo-item img {
+  max-height: 100%;
+  max-width: 10rem;
+  object-fit: contain;
+}
+
+.logo-item__name {
+  font-size: 1.25rem;
+  font-weight: 700;
+  opacity: 0.8;
+}
+
+.landing-logos__grid--grayscale .logo-item {
+  filter: grayscale(1);
+  opacity: 0.7;
+}
+
+.landing-logos__grid--grayscale .logo-item:hover {
+  filter: none;
+  opacity: 1;
+}
+`
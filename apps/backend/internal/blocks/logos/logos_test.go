@@ -0,0 +1,41 @@
+package logos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/landly/backend/internal/blocks"
+)
+
+func TestRegistered(t *testing.T) {
+	def, ok := blocks.Default.Get(Type)
+	require.True(t, ok)
+	assert.NotEmpty(t, def.CSS)
+}
+
+func TestRender(t *testing.T) {
+	html := render(map[string]interface{}{
+		"title":     "Партнёры",
+		"grayscale": true,
+		"items": []interface{}{
+			map[string]interface{}{"name": "Acme", "image": "https://example.com/acme.svg", "url": "https://acme.example.com"},
+			map[string]interface{}{"name": "Globex"},
+			map[string]interface{}{"url": "https://empty.example.com"},
+		},
+	}, blocks.RenderContext{})
+
+	assert.Contains(t, html, "Партнёры")
+	assert.Contains(t, html, "landing-logos__grid--grayscale")
+	assert.Contains(t, html, `<a class="logo-item" href="https://acme.example.com" target="_blank" rel="noopener"><img src="https://example.com/acme.svg" alt="Acme" loading="lazy" /></a>`)
+	assert.Contains(t, html, `<div class="logo-item"><span class="logo-item__name">Globex</span></div>`)
+	assert.NotContains(t, html, "empty.example.com")
+}
+
+func TestRender_Empty(t *testing.T) {
+	html := render(map[string]interface{}{}, blocks.RenderContext{})
+	assert.Contains(t, html, "Нам доверяют")
+	assert.Contains(t, html, "Добавьте логотипы")
+	assert.NotContains(t, html, "grayscale")
+}
@@ -0,0 +1,74 @@
+package blocks
+
+import (
+	"strconv"
+	"strings"
+)
+
+// StringProp возвращает строковое свойство блока или значение по умолчанию
+func StringProp(props map[string]interface{}, key, defaultValue string) string {
+	if val, ok := props[key].(string); ok {
+		return val
+	}
+	return defaultValue
+}
+
+// Slice возвращает элементы-объекты массива
+func Slice(value interface{}) []map[string]interface{} {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	result := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// StringSlice возвращает строковые элементы массива
+func StringSlice(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				result = append(result, str)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// IntProp возвращает целое свойство (число или строка с числом)
+func IntProp(props map[string]interface{}, key string, defaultValue int) int {
+	switch val := props[key].(type) {
+	case float64:
+		return int(val)
+	case int:
+		return val
+	case string:
+		if parsed, err := strconv.Atoi(strings.TrimSpace(val)); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// BoolProp возвращает логическое свойство (true или "true")
+func BoolProp(props map[string]interface{}, key string) bool {
+	if val, ok := props[key].(bool); ok {
+		return val
+	}
+	if val, ok := props[key].(string); ok {
+		return strings.EqualFold(val, "true")
+	}
+	return false
+}
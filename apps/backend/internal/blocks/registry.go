@@ -0,0 +1,200 @@
+package blocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Link ссылка навигации по сайту
+type Link struct {
+	Title  string
+	Href   string
+	Active bool
+}
+
+// RenderContext данные сайта, доступные рендереру блока
+type RenderContext struct {
+	// Schema вся JSON-схема лендинга (payment, theme и т.д.)
+	Schema map[string]interface{}
+	// Pages ссылки на страницы сайта относительно текущей страницы
+	Pages []Link
+	// Anchors якоря секций текущей страницы: подпись, заголовок или тип блока в нижнем регистре → id
+	Anchors map[string]string
+	// PaymentURL ссылка платёжной интеграции проекта; важнее payment.url схемы
+	PaymentURL string
+	// ClientReferenceID метка сборки для ссылок Stripe: по ней вебхук об оплате
+	// относит её к проекту и варианту лендинга
+	ClientReferenceID string
+	// FormEndpoint путь приёма заявок проекта от корня сайта; пусто в превью без проекта
+	FormEndpoint string
+	// Path путь текущей страницы, Variant — вариант A/B-теста сборки (пусто вне эксперимента)
+	Path    string
+	Variant string
+}
+
+// Href возвращает ссылку для пункта меню: страницу сайта с таким названием,
+// якорь секции текущей страницы или "#"
+func (rc RenderContext) Href(label string) string {
+	key := strings.ToLower(strings.TrimSpace(label))
+	for _, page := range rc.Pages {
+		if strings.ToLower(page.Title) == key {
+			return page.Href
+		}
+	}
+	if id, ok := rc.Anchors[key]; ok {
+		return "#" + id
+	}
+	return "#"
+}
+
+// PaymentHref добавляет к ссылке Stripe Payment Link параметр client_reference_id.
+// Остальные ссылки и ссылки, где параметр уже задан, возвращаются как есть.
+func (rc RenderContext) PaymentHref(link string) string {
+	if rc.ClientReferenceID == "" || link == "" {
+		return link
+	}
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Scheme != "https" || !isStripeHost(parsed.Hostname()) {
+		return link
+	}
+	query := parsed.Query()
+	if query.Has("client_reference_id") {
+		return link
+	}
+	query.Set("client_reference_id", rc.ClientReferenceID)
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+func isStripeHost(host string) bool {
+	host = strings.ToLower(host)
+	return host == "stripe.com" || strings.HasSuffix(host, ".stripe.com")
+}
+
+// Renderer рендерит свойства блока в HTML-секцию
+type Renderer interface {
+	Render(props map[string]interface{}, rc RenderContext) string
+}
+
+// RendererFunc позволяет использовать функцию как Renderer
+type RendererFunc func(props map[string]interface{}, rc RenderContext) string
+
+// Render вызывает f(props, rc)
+func (f RendererFunc) Render(props map[string]interface{}, rc RenderContext) string {
+	return f(props, rc)
+}
+
+// Definition тип блока лендинга. По нему строятся валидатор схемы,
+// список допустимых блоков в промпте AI и HTML-рендерер.
+type Definition struct {
+	// Type значение поля type блока в схеме
+	Type string
+	// Label название секции в меню сайта (например, «Цены» для pricing)
+	Label string
+	// Hint краткое описание props для модели
+	Hint string
+	// PropsSchema JSON Schema объекта props (по умолчанию {"type":"object"})
+	PropsSchema string
+	// Example пример props, проходящий PropsSchema
+	Example string
+	// Renderer HTML-рендерер блока
+	Renderer Renderer
+	// CSS стили блока, добавляемые к landing.css
+	CSS string
+}
+
+// Registry реестр типов блоков
+type Registry struct {
+	mu    sync.RWMutex
+	defs  map[string]Definition
+	order []string
+}
+
+// NewRegistry создаёт пустой реестр
+func NewRegistry() *Registry {
+	return &Registry{defs: make(map[string]Definition)}
+}
+
+// Default реестр со встроенными блоками; пакеты пользовательских блоков регистрируются в нём из init()
+var Default = NewRegistry()
+
+// Register добавляет тип блока в реестр
+func (r *Registry) Register(def Definition) error {
+	if def.Type == "" {
+		return fmt.Errorf("block type is required")
+	}
+	if def.Renderer == nil {
+		return fmt.Errorf("block %s: renderer is required", def.Type)
+	}
+	if def.PropsSchema == "" {
+		def.PropsSchema = `{"type":"object"}`
+	}
+	if !json.Valid([]byte(def.PropsSchema)) {
+		return fmt.Errorf("block %s: props schema is not valid JSON", def.Type)
+	}
+	if def.Example == "" {
+		def.Example = `{}`
+	}
+	if !json.Valid([]byte(def.Example)) {
+		return fmt.Errorf("block %s: example is not valid JSON", def.Type)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.defs[def.Type]; exists {
+		return fmt.Errorf("block %s is already registered", def.Type)
+	}
+	r.defs[def.Type] = def
+	r.order = append(r.order, def.Type)
+	return nil
+}
+
+// MustRegister регистрирует блок и паникует при ошибке (для вызова из init)
+func (r *Registry) MustRegister(def Definition) {
+	if err := r.Register(def); err != nil {
+		panic(err)
+	}
+}
+
+// Get возвращает описание типа блока
+func (r *Registry) Get(blockType string) (Definition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	def, ok := r.defs[blockType]
+	return def, ok
+}
+
+// Types возвращает типы блоков в порядке регистрации
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]string(nil), r.order...)
+}
+
+// Definitions возвращает описания блоков в порядке регистрации
+func (r *Registry) Definitions() []Definition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	defs := make([]Definition, 0, len(r.order))
+	for _, blockType := range r.order {
+		defs = append(defs, r.defs[blockType])
+	}
+	return defs
+}
+
+// Register добавляет тип блока в реестр по умолчанию
+func Register(def Definition) error {
+	return Default.Register(def)
+}
+
+// MustRegister регистрирует блок в реестре по умолчанию и паникует при ошибке
+func MustRegister(def Definition) {
+	Default.MustRegister(def)
+}
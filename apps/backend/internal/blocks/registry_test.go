@@ -0,0 +1,64 @@
+package blocks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRenderer(props map[string]interface{}, _ RenderContext) string {
+	return "<section>" + StringProp(props, "title", "") + "</section>"
+}
+
+func TestRegistry_Register(t *testing.T) {
+	registry := NewRegistry()
+
+	require.NoError(t, registry.Register(Definition{Type: "banner", Renderer: RendererFunc(testRenderer)}))
+	require.NoError(t, registry.Register(Definition{Type: "quote", Renderer: RendererFunc(testRenderer), Hint: "text"}))
+
+	assert.Equal(t, []string{"banner", "quote"}, registry.Types())
+
+	def, ok := registry.Get("banner")
+	require.True(t, ok)
+	assert.Equal(t, `{"type":"object"}`, def.PropsSchema)
+	assert.Equal(t, `{}`, def.Example)
+	assert.Equal(t, "<section>Hi</section>", def.Renderer.Render(map[string]interface{}{"title": "Hi"}, RenderContext{}))
+
+	_, ok = registry.Get("missing")
+	assert.False(t, ok)
+}
+
+func TestRegistry_Register_Invalid(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(Definition{Type: "banner", Renderer: RendererFunc(testRenderer)}))
+
+	cases := map[string]Definition{
+		"empty type":      {Renderer: RendererFunc(testRenderer)},
+		"no renderer":     {Type: "quote"},
+		"duplicate":       {Type: "banner", Renderer: RendererFunc(testRenderer)},
+		"invalid schema":  {Type: "quote", Renderer: RendererFunc(testRenderer), PropsSchema: `{"type":`},
+		"invalid example": {Type: "quote", Renderer: RendererFunc(testRenderer), Example: `{`},
+	}
+	for name, def := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, registry.Register(def))
+		})
+	}
+
+	assert.Equal(t, []string{"banner"}, registry.Types())
+	assert.Panics(t, func() {
+		registry.MustRegister(Definition{Type: "banner", Renderer: RendererFunc(testRenderer)})
+	})
+}
+
+func TestDefault_Builtins(t *testing.T) {
+	types := Default.Types()
+	require.GreaterOrEqual(t, len(types), 9)
+	assert.Equal(t, []string{"hero", "features", "pricing", "testimonials", "faq", "cta", "gallery", "about", "contact"}, types[:9])
+
+	for _, def := range Default.Definitions() {
+		assert.NotEmpty(t, def.Hint, def.Type)
+		assert.NotEmpty(t, def.Renderer.Render(map[string]interface{}{}, RenderContext{}), def.Type)
+	}
+}
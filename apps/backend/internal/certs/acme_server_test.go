@@ -0,0 +1,451 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testACMEServer локальная замена удостоверяющего центра в духе Pebble: реализует
+// поток RFC 8555 (аккаунт, заказ, авторизация http-01, финализация) в памяти.
+// Challenge проверяется синхронно запросом к validator, подписи JWS не проверяются.
+type testACMEServer struct {
+	*httptest.Server
+
+	// validator отвечает на http-01 вместо настоящего домена
+	validator http.Handler
+	// validity срок действия выпускаемых сертификатов
+	validity time.Duration
+
+	caKey  *ecdsa.PrivateKey
+	caCert *x509.Certificate
+
+	mu       sync.Mutex
+	seq      int
+	accounts map[string]string // URL аккаунта -> отпечаток JWK
+	orders   map[string]*testOrder
+	authzs   map[string]*testAuthz
+	certs    map[string][]byte
+	issued   int
+}
+
+type testOrder struct {
+	account string
+	status  string
+	authzs  []string
+	certURL string
+	domain  string
+}
+
+type testAuthz struct {
+	account string
+	domain  string
+	status  string
+	token   string
+}
+
+type testJWS struct {
+	Protected string `json:"protected"`
+	Payload   string `json:"payload"`
+}
+
+type testJWSHeader struct {
+	KID string          `json:"kid"`
+	JWK json.RawMessage `json:"jwk"`
+}
+
+func newTestACMEServer(t *testing.T) *testACMEServer {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test ACME CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(10 * 365 * 24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &testACMEServer{
+		validator: http.NotFoundHandler(),
+		validity:  90 * 24 * time.Hour,
+		caKey:     caKey,
+		caCert:    caCert,
+		accounts:  make(map[string]string),
+		orders:    make(map[string]*testOrder),
+		authzs:    make(map[string]*testAuthz),
+		certs:     make(map[string][]byte),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testACMEServer) directoryURL() string {
+	return s.URL + "/directory"
+}
+
+func (s *testACMEServer) issuedCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issued
+}
+
+func (s *testACMEServer) accountCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.accounts)
+}
+
+func (s *testACMEServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	w.Header().Set("Replay-Nonce", fmt.Sprintf("nonce-%d", s.seq))
+
+	if r.URL.Path == "/directory" {
+		s.writeJSON(w, http.StatusOK, map[string]any{
+			"newNonce":   s.URL + "/nonce",
+			"newAccount": s.URL + "/account",
+			"newOrder":   s.URL + "/order",
+			"revokeCert": s.URL + "/revoke",
+			"keyChange":  s.URL + "/key-change",
+			"meta":       map[string]any{"termsOfService": s.URL + "/terms"},
+		})
+		return
+	}
+	if r.URL.Path == "/nonce" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		s.writeProblem(w, http.StatusMethodNotAllowed, "malformed", "POST expected")
+		return
+	}
+
+	var jws testJWS
+	if err := json.NewDecoder(r.Body).Decode(&jws); err != nil {
+		s.writeProblem(w, http.StatusBadRequest, "malformed", err.Error())
+		return
+	}
+	var header testJWSHeader
+	if err := decodeSegment(jws.Protected, &header); err != nil {
+		s.writeProblem(w, http.StatusBadRequest, "malformed", err.Error())
+		return
+	}
+
+	if r.URL.Path == "/account" {
+		s.handleAccount(w, header, jws.Payload)
+		return
+	}
+
+	account := header.KID
+	if _, ok := s.accounts[account]; !ok {
+		s.writeProblem(w, http.StatusUnauthorized, "accountDoesNotExist", "unknown account")
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/order":
+		s.handleNewOrder(w, account, jws.Payload)
+	case strings.HasPrefix(r.URL.Path, "/order/"):
+		s.handleOrder(w, r.URL.Path)
+	case strings.HasPrefix(r.URL.Path, "/finalize/"):
+		s.handleFinalize(w, strings.Replace(r.URL.Path, "/finalize/", "/order/", 1), jws.Payload)
+	case strings.HasPrefix(r.URL.Path, "/authz/"):
+		s.handleAuthz(w, r.URL.Path)
+	case strings.HasPrefix(r.URL.Path, "/challenge/"):
+		s.handleChallenge(w, strings.Replace(r.URL.Path, "/challenge/", "/authz/", 1))
+	case strings.HasPrefix(r.URL.Path, "/cert/"):
+		chain, ok := s.certs[r.URL.Path]
+		if !ok {
+			s.writeProblem(w, http.StatusNotFound, "malformed", "unknown certificate")
+			return
+		}
+		w.Header().Set("Content-Type", "application/pem-certificate-chain")
+		_, _ = w.Write(chain)
+	default:
+		s.writeProblem(w, http.StatusNotFound, "malformed", "unknown resource")
+	}
+}
+
+func (s *testACMEServer) handleAccount(w http.ResponseWriter, header testJWSHeader, payload string) {
+	var req struct {
+		OnlyReturnExisting bool `json:"onlyReturnExisting"`
+	}
+	if err := decodeSegment(payload, &req); err != nil {
+		s.writeProblem(w, http.StatusBadRequest, "malformed", err.Error())
+		return
+	}
+
+	thumbprint, err := jwkThumbprint(header.JWK)
+	if err != nil {
+		s.writeProblem(w, http.StatusBadRequest, "badPublicKey", err.Error())
+		return
+	}
+	accountURL := s.URL + "/account/" + thumbprint
+
+	status := http.StatusOK
+	if _, ok := s.accounts[accountURL]; !ok {
+		if req.OnlyReturnExisting {
+			s.writeProblem(w, http.StatusBadRequest, "accountDoesNotExist", "no account for key")
+			return
+		}
+		s.accounts[accountURL] = thumbprint
+		status = http.StatusCreated
+	}
+
+	w.Header().Set("Location", accountURL)
+	s.writeJSON(w, status, map[string]any{"status": "valid", "orders": accountURL + "/orders"})
+}
+
+func (s *testACMEServer) handleNewOrder(w http.ResponseWriter, account, payload string) {
+	var req struct {
+		Identifiers []struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"identifiers"`
+	}
+	if err := decodeSegment(payload, &req); err != nil || len(req.Identifiers) != 1 || req.Identifiers[0].Type != "dns" {
+		s.writeProblem(w, http.StatusBadRequest, "rejectedIdentifier", "exactly one dns identifier expected")
+		return
+	}
+
+	s.seq++
+	orderPath := fmt.Sprintf("/order/%d", s.seq)
+	authzPath := fmt.Sprintf("/authz/%d", s.seq)
+	s.authzs[authzPath] = &testAuthz{
+		account: account,
+		domain:  req.Identifiers[0].Value,
+		status:  "pending",
+		token:   fmt.Sprintf("token-%d", s.seq),
+	}
+	s.orders[orderPath] = &testOrder{
+		account: account,
+		status:  "pending",
+		authzs:  []string{authzPath},
+		domain:  req.Identifiers[0].Value,
+	}
+
+	w.Header().Set("Location", s.URL+orderPath)
+	s.writeJSON(w, http.StatusCreated, s.orderJSON(orderPath))
+}
+
+func (s *testACMEServer) handleOrder(w http.ResponseWriter, orderPath string) {
+	if _, ok := s.orders[orderPath]; !ok {
+		s.writeProblem(w, http.StatusNotFound, "malformed", "unknown order")
+		return
+	}
+	w.Header().Set("Location", s.URL+orderPath)
+	s.writeJSON(w, http.StatusOK, s.orderJSON(orderPath))
+}
+
+func (s *testACMEServer) handleAuthz(w http.ResponseWriter, authzPath string) {
+	authz, ok := s.authzs[authzPath]
+	if !ok {
+		s.writeProblem(w, http.StatusNotFound, "malformed", "unknown authorization")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"identifier": map[string]string{"type": "dns", "value": authz.domain},
+		"status":     authz.status,
+		"challenges": []any{s.challengeJSON(authzPath)},
+	})
+}
+
+// handleChallenge проверяет http-01 сразу: запрашивает у validator ответ на токен
+// так, как удостоверяющий центр запросил бы его у домена
+func (s *testACMEServer) handleChallenge(w http.ResponseWriter, authzPath string) {
+	authz, ok := s.authzs[authzPath]
+	if !ok {
+		s.writeProblem(w, http.StatusNotFound, "malformed", "unknown challenge")
+		return
+	}
+
+	if authz.status == "pending" {
+		req := httptest.NewRequest(http.MethodGet, "http://"+authz.domain+"/.well-known/acme-challenge/"+authz.token, nil)
+		rec := httptest.NewRecorder()
+		s.validator.ServeHTTP(rec, req)
+
+		expected := authz.token + "." + s.accounts[authz.account]
+		if rec.Code == http.StatusOK && strings.TrimSpace(rec.Body.String()) == expected {
+			authz.status = "valid"
+		} else {
+			authz.status = "invalid"
+		}
+	}
+
+	s.writeJSON(w, http.StatusOK, s.challengeJSON(authzPath))
+}
+
+func (s *testACMEServer) handleFinalize(w http.ResponseWriter, orderPath, payload string) {
+	order, ok := s.orders[orderPath]
+	if !ok || s.orderStatus(order) != "ready" {
+		s.writeProblem(w, http.StatusForbidden, "orderNotReady", "order is not ready")
+		return
+	}
+
+	var req struct {
+		CSR string `json:"csr"`
+	}
+	if err := decodeSegment(payload, &req); err != nil {
+		s.writeProblem(w, http.StatusBadRequest, "malformed", err.Error())
+		return
+	}
+	der, err := base64.RawURLEncoding.DecodeString(req.CSR)
+	if err != nil {
+		s.writeProblem(w, http.StatusBadRequest, "badCSR", err.Error())
+		return
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil || csr.CheckSignature() != nil || len(csr.DNSNames) != 1 || csr.DNSNames[0] != order.domain {
+		s.writeProblem(w, http.StatusBadRequest, "badCSR", "CSR does not match the order")
+		return
+	}
+
+	s.seq++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(int64(s.seq)),
+		Subject:      pkix.Name{CommonName: order.domain},
+		DNSNames:     csr.DNSNames,
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(s.validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	leaf, err := x509.CreateCertificate(rand.Reader, template, s.caCert, csr.PublicKey, s.caKey)
+	if err != nil {
+		s.writeProblem(w, http.StatusInternalServerError, "serverInternal", err.Error())
+		return
+	}
+
+	order.certURL = fmt.Sprintf("/cert/%d", s.seq)
+	order.status = "valid"
+	s.certs[order.certURL] = append(
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.caCert.Raw})...,
+	)
+	s.issued++
+
+	w.Header().Set("Location", s.URL+orderPath)
+	s.writeJSON(w, http.StatusOK, s.orderJSON(orderPath))
+}
+
+func (s *testACMEServer) orderStatus(order *testOrder) string {
+	if order.status == "valid" {
+		return order.status
+	}
+	status := "ready"
+	for _, authzPath := range order.authzs {
+		switch s.authzs[authzPath].status {
+		case "invalid":
+			return "invalid"
+		case "pending":
+			status = "pending"
+		}
+	}
+	return status
+}
+
+func (s *testACMEServer) orderJSON(orderPath string) map[string]any {
+	order := s.orders[orderPath]
+	authzURLs := make([]string, 0, len(order.authzs))
+	for _, authzPath := range order.authzs {
+		authzURLs = append(authzURLs, s.URL+authzPath)
+	}
+
+	body := map[string]any{
+		"status":         s.orderStatus(order),
+		"identifiers":    []map[string]string{{"type": "dns", "value": order.domain}},
+		"authorizations": authzURLs,
+		"finalize":       s.URL + strings.Replace(orderPath, "/order/", "/finalize/", 1),
+	}
+	if order.certURL != "" {
+		body["certificate"] = s.URL + order.certURL
+	}
+	return body
+}
+
+func (s *testACMEServer) challengeJSON(authzPath string) map[string]any {
+	authz := s.authzs[authzPath]
+	return map[string]any{
+		"type":   "http-01",
+		"url":    s.URL + strings.Replace(authzPath, "/authz/", "/challenge/", 1),
+		"token":  authz.token,
+		"status": authz.status,
+	}
+}
+
+func (s *testACMEServer) writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (s *testACMEServer) writeProblem(w http.ResponseWriter, status int, problem, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"type":   "urn:ietf:params:acme:error:" + problem,
+		"detail": detail,
+	})
+}
+
+func decodeSegment(segment string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// jwkThumbprint отпечаток ключа EC по RFC 7638: SHA-256 от канонического JSON
+func jwkThumbprint(raw json.RawMessage) (string, error) {
+	var jwk struct {
+		Crv string `json:"crv"`
+		Kty string `json:"kty"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+	if err := json.Unmarshal(raw, &jwk); err != nil {
+		return "", err
+	}
+	if jwk.Kty != "EC" {
+		return "", fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+
+	canonical := fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q,"y":%q}`, jwk.Crv, jwk.Kty, jwk.X, jwk.Y)
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,526 @@
+package certs
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/landly/backend/internal/logger"
+	domain "github.com/landly/backend/internal/models"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/acme"
+)
+
+const (
+	defaultRenewBefore   = 30 * 24 * time.Hour
+	defaultCheckInterval = 12 * time.Hour
+
+	// obtainTimeout сколько ждать удостоверяющий центр при выпуске одного сертификата
+	obtainTimeout = 5 * time.Minute
+	// lookupTimeout чтение сертификата из БД во время TLS-рукопожатия
+	lookupTimeout = 5 * time.Second
+	// cacheTTL сертификат из памяти перечитывается из БД: его мог продлить другой экземпляр
+	cacheTTL = time.Hour
+	// missTTL сколько помнить, что для имени нет сертификата: рукопожатия с произвольным SNI
+	// не должны каждый раз ходить в БД
+	missTTL = time.Minute
+	// maxMisses предел записей о промахах; при переполнении они сбрасываются
+	maxMisses = 10000
+	// issueLeaseTTL аренда выпуска переживает заказ целиком, даже если экземпляр упал посреди него
+	issueLeaseTTL = obtainTimeout + time.Minute
+	// maxChallengeTokenLength токены ACME — base64url около 43 символов
+	maxChallengeTokenLength = 128
+
+	requestQueueSize = 64
+
+	challengePathPrefix = "/.well-known/acme-challenge/"
+)
+
+// ManagerConfig параметры выпуска сертификатов
+type ManagerConfig struct {
+	DirectoryURL string
+	// Email контакт аккаунта для уведомлений удостоверяющего центра
+	Email string
+	// RenewBefore сертификат продлевается, когда до истечения остаётся меньше этого
+	RenewBefore   time.Duration
+	CheckInterval time.Duration
+}
+
+// Manager выпускает по ACME (RFC 8555) сертификаты подтверждённых собственных доменов,
+// продлевает их и отдаёт HTTPS-серверу через GetCertificate. Владение доменом
+// подтверждается challenge http-01, ответы на него хранятся в БД и их отдаёт HTTPHandler
+// любого экземпляра. Выпуск домена защищён арендой в БД, чтобы экземпляры не заказывали его одновременно.
+type Manager struct {
+	certRepo   domain.CertificateRepository
+	targetRepo domain.PublishTargetRepository
+	cfg        ManagerConfig
+	client     *acme.Client
+	requests   chan string
+	// holder идентификатор экземпляра в аренде выпуска
+	holder string
+
+	accountMu sync.Mutex
+
+	mu     sync.RWMutex
+	certs  map[string]*cachedCertificate
+	misses map[string]time.Time
+}
+
+type cachedCertificate struct {
+	cert     *tls.Certificate
+	loadedAt time.Time
+}
+
+// NewManager создаёт менеджер сертификатов
+func NewManager(certRepo domain.CertificateRepository, targetRepo domain.PublishTargetRepository, cfg ManagerConfig) *Manager {
+	if cfg.DirectoryURL == "" {
+		cfg.DirectoryURL = acme.LetsEncryptURL
+	}
+	if cfg.RenewBefore <= 0 {
+		cfg.RenewBefore = defaultRenewBefore
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = defaultCheckInterval
+	}
+
+	return &Manager{
+		certRepo:   certRepo,
+		targetRepo: targetRepo,
+		cfg:        cfg,
+		client:     &acme.Client{DirectoryURL: cfg.DirectoryURL, UserAgent: "landly"},
+		requests:   make(chan string, requestQueueSize),
+		holder:     uuid.NewString(),
+		certs:      make(map[string]*cachedCertificate),
+		misses:     make(map[string]time.Time),
+	}
+}
+
+// Run выпускает запрошенные сертификаты и периодически продлевает истекающие, пока не отменён ctx.
+// Всё выполняется последовательно, чтобы не заказывать один домен дважды.
+func (m *Manager) Run(ctx context.Context) {
+	log := logger.WithContext(ctx)
+
+	ticker := time.NewTicker(m.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	renew := func() {
+		if err := m.RenewDue(ctx); err != nil && ctx.Err() == nil {
+			log.Error("certificate renewal failed", zap.Error(err))
+		}
+	}
+	renew()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case host := <-m.requests:
+			if err := m.Obtain(ctx, host); err != nil && ctx.Err() == nil {
+				log.Error("failed to obtain certificate", zap.String("domain", host), zap.Error(err))
+			}
+		case <-ticker.C:
+			renew()
+		}
+	}
+}
+
+// Request ставит выпуск сертификата домена в очередь Run
+func (m *Manager) Request(host string) {
+	select {
+	case m.requests <- host:
+	default:
+		// Домен подхватит ближайшая проверка продлений
+		logger.WithContext(context.Background()).Warn("certificate request queue is full", zap.String("domain", host))
+	}
+}
+
+// Forget удаляет сертификат отвязанного домена
+func (m *Manager) Forget(ctx context.Context, host string) error {
+	m.mu.Lock()
+	delete(m.certs, host)
+	m.mu.Unlock()
+
+	return m.certRepo.Delete(ctx, host)
+}
+
+// RenewDue выпускает сертификаты подтверждённым доменам, у которых его нет или он скоро истечёт
+func (m *Manager) RenewDue(ctx context.Context) error {
+	targets, err := m.targetRepo.ListVerifiedDomains(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, target := range targets {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		stored, err := m.certRepo.Get(ctx, target.CustomDomain)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			errs = append(errs, err)
+			continue
+		}
+		if stored != nil && time.Now().Add(m.cfg.RenewBefore).Before(stored.NotAfter) {
+			continue
+		}
+
+		if err := m.Obtain(ctx, target.CustomDomain); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target.CustomDomain, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Obtain выпускает сертификат домена и сохраняет его. Пока действует прежний
+// сертификат, неудачное продление не меняет ssl_status: сайт по-прежнему доступен по HTTPS.
+// Если домен уже выпускает другой экземпляр или он только что это сделал, ничего не заказывается.
+func (m *Manager) Obtain(ctx context.Context, host string) error {
+	ctx, cancel := context.WithTimeout(ctx, obtainTimeout)
+	defer cancel()
+
+	leased, err := m.certRepo.AcquireIssueLease(ctx, host, m.holder, issueLeaseTTL)
+	if err != nil {
+		return err
+	}
+	if !leased {
+		logger.WithContext(ctx).Info("certificate is being issued by another instance", zap.String("domain", host))
+		return nil
+	}
+	defer func() {
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), lookupTimeout)
+		defer cancel()
+		if err := m.certRepo.ReleaseIssueLease(releaseCtx, host, m.holder); err != nil {
+			logger.WithContext(ctx).Warn("failed to release certificate issue lease", zap.String("domain", host), zap.Error(err))
+		}
+	}()
+
+	if m.renewedRecently(ctx, host) {
+		return nil
+	}
+
+	hasValid := m.hasValidCertificate(ctx, host)
+	if !hasValid {
+		m.setStatus(ctx, host, domain.SSLStatusPending)
+	}
+
+	cert, err := m.issue(ctx, host)
+	if err == nil {
+		err = m.store(ctx, cert)
+	}
+	if err != nil {
+		if !hasValid {
+			m.setStatus(ctx, host, domain.SSLStatusFailed)
+		}
+		return err
+	}
+
+	m.setStatus(ctx, host, domain.SSLStatusActive)
+	return nil
+}
+
+// GetCertificate отдаёт сертификат по SNI; подходит для tls.Config.GetCertificate.
+// Сертификаты выпускаются заранее, во время рукопожатия ничего не заказывается.
+func (m *Manager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	host := strings.TrimSuffix(strings.ToLower(hello.ServerName), ".")
+	if host == "" {
+		return nil, errors.New("certs: missing server name")
+	}
+
+	m.mu.RLock()
+	cached := m.certs[host]
+	missedAt, missed := m.misses[host]
+	m.mu.RUnlock()
+	if cached != nil && time.Now().Sub(cached.loadedAt) < cacheTTL {
+		return cached.cert, nil
+	}
+	if cached == nil && missed && time.Now().Sub(missedAt) < missTTL {
+		return nil, fmt.Errorf("certs: no certificate for %s", host)
+	}
+
+	parent := hello.Context()
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, lookupTimeout)
+	defer cancel()
+
+	stored, err := m.certRepo.Get(ctx, host)
+	if err != nil {
+		if cached != nil {
+			return cached.cert, nil
+		}
+		if errors.Is(err, domain.ErrNotFound) {
+			m.rememberMiss(host)
+		}
+		return nil, fmt.Errorf("certs: no certificate for %s: %w", host, err)
+	}
+
+	cert, err := m.cache(stored)
+	if err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+// HTTPHandler отвечает на challenge http-01 и передаёт остальные запросы в next.
+// Должен стоять перед раздачей сайтов: удостоверяющий центр проверяет сам собственный домен.
+func (m *Manager) HTTPHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token, ok := strings.CutPrefix(r.URL.Path, challengePathPrefix); ok && token != "" && len(token) <= maxChallengeTokenLength {
+			ctx, cancel := context.WithTimeout(r.Context(), lookupTimeout)
+			response, err := m.certRepo.GetChallenge(ctx, token)
+			cancel()
+			if err == nil {
+				w.Header().Set("Content-Type", "text/plain")
+				_, _ = w.Write([]byte(response))
+				return
+			}
+			if !errors.Is(err, domain.ErrNotFound) {
+				logger.WithContext(r.Context()).Error("failed to load acme challenge", zap.Error(err))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// issue проходит заказ целиком: авторизация домена, CSR с новым ключом, загрузка цепочки
+func (m *Manager) issue(ctx context.Context, host string) (*domain.TLSCertificate, error) {
+	if err := m.ensureAccount(ctx); err != nil {
+		return nil, fmt.Errorf("acme account: %w", err)
+	}
+
+	order, err := m.client.AuthorizeOrder(ctx, acme.DomainIDs(host))
+	if err != nil {
+		return nil, fmt.Errorf("create order: %w", err)
+	}
+	for _, authzURL := range order.AuthzURLs {
+		if err := m.authorize(ctx, authzURL); err != nil {
+			return nil, err
+		}
+	}
+	order, err = m.client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return nil, fmt.Errorf("wait order: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: host},
+		DNSNames: []string{host},
+	}, key)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, _, err := m.client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, fmt.Errorf("finalize order: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return nil, fmt.Errorf("parse certificate: %w", err)
+	}
+	if err := leaf.VerifyHostname(host); err != nil {
+		return nil, err
+	}
+
+	var certPEM strings.Builder
+	for _, der := range chain {
+		if err := pem.Encode(&certPEM, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+			return nil, err
+		}
+	}
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &domain.TLSCertificate{
+		Domain:    host,
+		CertPEM:   certPEM.String(),
+		KeyPEM:    keyPEM,
+		NotAfter:  leaf.NotAfter,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// authorize подтверждает владение доменом через http-01, если авторизация ещё не действует
+func (m *Manager) authorize(ctx context.Context, authzURL string) error {
+	authz, err := m.client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return fmt.Errorf("get authorization: %w", err)
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+
+	var challenge *acme.Challenge
+	for _, candidate := range authz.Challenges {
+		if candidate.Type == "http-01" {
+			challenge = candidate
+			break
+		}
+	}
+	if challenge == nil {
+		return fmt.Errorf("no http-01 challenge for %s", authz.Identifier.Value)
+	}
+
+	response, err := m.client.HTTP01ChallengeResponse(challenge.Token)
+	if err != nil {
+		return err
+	}
+	if err := m.certRepo.SaveChallenge(ctx, challenge.Token, response); err != nil {
+		return fmt.Errorf("save challenge: %w", err)
+	}
+	defer func() {
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), lookupTimeout)
+		defer cancel()
+		if err := m.certRepo.DeleteChallenge(cleanupCtx, challenge.Token); err != nil {
+			logger.WithContext(ctx).Warn("failed to delete acme challenge", zap.Error(err))
+		}
+	}()
+
+	if _, err := m.client.Accept(ctx, challenge); err != nil {
+		return fmt.Errorf("accept challenge: %w", err)
+	}
+	if _, err := m.client.WaitAuthorization(ctx, authz.URI); err != nil {
+		return fmt.Errorf("authorize %s: %w", authz.Identifier.Value, err)
+	}
+	return nil
+}
+
+// ensureAccount загружает ключ аккаунта из БД или регистрирует новый аккаунт
+func (m *Manager) ensureAccount(ctx context.Context) error {
+	m.accountMu.Lock()
+	defer m.accountMu.Unlock()
+
+	if m.client.Key != nil {
+		return nil
+	}
+
+	account, err := m.certRepo.GetACMEAccount(ctx, m.cfg.DirectoryURL)
+	if err == nil {
+		key, err := decodeKey(account.KeyPEM)
+		if err != nil {
+			return err
+		}
+		m.client.Key = key
+		return nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return err
+	}
+
+	m.client.Key = key
+	registration := &acme.Account{}
+	if m.cfg.Email != "" {
+		registration.Contact = []string{"mailto:" + m.cfg.Email}
+	}
+	if _, err := m.client.Register(ctx, registration, acme.AcceptTOS); err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		m.client.Key = nil
+		return err
+	}
+
+	if err := m.certRepo.SaveACMEAccount(ctx, &domain.ACMEAccount{
+		DirectoryURL: m.cfg.DirectoryURL,
+		KeyPEM:       keyPEM,
+		CreatedAt:    time.Now(),
+	}); err != nil {
+		m.client.Key = nil
+		return err
+	}
+	return nil
+}
+
+func (m *Manager) store(ctx context.Context, cert *domain.TLSCertificate) error {
+	if err := m.certRepo.Save(ctx, cert); err != nil {
+		return err
+	}
+	_, err := m.cache(cert)
+	return err
+}
+
+func (m *Manager) cache(stored *domain.TLSCertificate) (*tls.Certificate, error) {
+	cert, err := tls.X509KeyPair([]byte(stored.CertPEM), []byte(stored.KeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("certs: invalid certificate for %s: %w", stored.Domain, err)
+	}
+
+	m.mu.Lock()
+	m.certs[stored.Domain] = &cachedCertificate{cert: &cert, loadedAt: time.Now()}
+	delete(m.misses, stored.Domain)
+	m.mu.Unlock()
+	return &cert, nil
+}
+
+func (m *Manager) rememberMiss(host string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.misses) >= maxMisses {
+		m.misses = make(map[string]time.Time)
+	}
+	m.misses[host] = time.Now()
+}
+
+func (m *Manager) hasValidCertificate(ctx context.Context, host string) bool {
+	stored, err := m.certRepo.Get(ctx, host)
+	return err == nil && time.Now().Before(stored.NotAfter)
+}
+
+// renewedRecently сертификат домена ещё не требует продления: его выпустил другой экземпляр,
+// пока этот ждал в очереди
+func (m *Manager) renewedRecently(ctx context.Context, host string) bool {
+	stored, err := m.certRepo.Get(ctx, host)
+	return err == nil && time.Now().Add(m.cfg.RenewBefore).Before(stored.NotAfter)
+}
+
+func (m *Manager) setStatus(ctx context.Context, host, status string) {
+	if err := m.targetRepo.SetSSLStatus(ctx, host, status); err != nil {
+		logger.WithContext(ctx).Error("failed to update ssl status", zap.String("domain", host), zap.String("status", status), zap.Error(err))
+	}
+}
+
+func encodeKey(key *ecdsa.PrivateKey) (string, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})), nil
+}
+
+func decodeKey(keyPEM string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return nil, errors.New("certs: invalid account key")
+	}
+	return x509.ParseECPrivateKey(block.Bytes)
+}
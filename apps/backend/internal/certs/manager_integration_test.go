@@ -0,0 +1,96 @@
+//go:build integration
+// +build integration
+
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/repositories"
+	testhelpers "github.com/landly/backend/internal/testing"
+)
+
+func TestManager_Integration_RenewDueIssuesForVerifiedDomains(t *testing.T) {
+	ctx := context.Background()
+	qb := testhelpers.SetupTestDB(t)
+	certRepo := repositories.NewCertificateRepository(qb)
+	targetRepo := repositories.NewPublishTargetRepository(qb)
+	ca := newTestACMEServer(t)
+
+	user, _ := testhelpers.CreateTestUser(t, qb, "", "")
+	verifiedAt := time.Now()
+	for i, host := range []string{"shop.example.com", "pending.example.com"} {
+		project := testhelpers.CreateTestProject(t, qb, user.ID, "TLS Project", "SaaS")
+		target := domain.NewPublishTarget(project.ID, "tls-project-"+string(rune('a'+i)))
+		require.NoError(t, targetRepo.Create(ctx, target))
+		target.CustomDomain = host
+		target.DomainVerificationToken = "landly-verification=abc"
+		if host == "shop.example.com" {
+			target.DomainVerifiedAt = &verifiedAt
+		}
+		require.NoError(t, targetRepo.UpdateDomain(ctx, target))
+	}
+
+	manager := NewManager(certRepo, targetRepo, ManagerConfig{DirectoryURL: ca.directoryURL()})
+	ca.validator = manager.HTTPHandler(http.NotFoundHandler())
+
+	require.NoError(t, manager.RenewDue(ctx))
+	assert.Equal(t, 1, ca.issuedCount(), "only verified domains get certificates")
+
+	target, err := targetRepo.GetByCustomDomain(ctx, "shop.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, domain.SSLStatusActive, target.SSLStatus)
+
+	// Новый экземпляр берёт аккаунт и сертификат из БД
+	restarted := NewManager(certRepo, targetRepo, ManagerConfig{DirectoryURL: ca.directoryURL()})
+	cert, err := restarted.GetCertificate(&tls.ClientHelloInfo{ServerName: "shop.example.com"})
+	require.NoError(t, err)
+	assert.NotNil(t, cert)
+
+	require.NoError(t, restarted.RenewDue(ctx))
+	assert.Equal(t, 1, ca.issuedCount(), "fresh certificate is not renewed")
+
+	require.NoError(t, restarted.Forget(ctx, "shop.example.com"))
+	_, err = certRepo.Get(ctx, "shop.example.com")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestCertificateRepository_Integration_IssueLeaseAndChallenges(t *testing.T) {
+	ctx := context.Background()
+	qb := testhelpers.SetupTestDB(t)
+	certRepo := repositories.NewCertificateRepository(qb)
+
+	leased, err := certRepo.AcquireIssueLease(ctx, "shop.example.com", "first", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, leased)
+
+	leased, err = certRepo.AcquireIssueLease(ctx, "shop.example.com", "second", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, leased, "active lease belongs to the first instance")
+
+	require.NoError(t, certRepo.ReleaseIssueLease(ctx, "shop.example.com", "second"))
+	leased, err = certRepo.AcquireIssueLease(ctx, "shop.example.com", "second", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, leased, "only the holder releases the lease")
+
+	require.NoError(t, certRepo.ReleaseIssueLease(ctx, "shop.example.com", "first"))
+	leased, err = certRepo.AcquireIssueLease(ctx, "shop.example.com", "second", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, leased)
+
+	require.NoError(t, certRepo.SaveChallenge(ctx, "token-1", "token-1.thumbprint"))
+	response, err := certRepo.GetChallenge(ctx, "token-1")
+	require.NoError(t, err)
+	assert.Equal(t, "token-1.thumbprint", response)
+	require.NoError(t, certRepo.DeleteChallenge(ctx, "token-1"))
+	_, err = certRepo.GetChallenge(ctx, "token-1")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
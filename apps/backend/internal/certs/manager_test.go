@@ -0,0 +1,360 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/services/mocks"
+)
+
+// memoryCertificateRepository хранилище сертификатов в памяти
+type memoryCertificateRepository struct {
+	mu         sync.Mutex
+	certs      map[string]*domain.TLSCertificate
+	accounts   map[string]*domain.ACMEAccount
+	challenges map[string]string
+	leases     map[string]string
+	lookups    map[string]int
+}
+
+func newMemoryCertificateRepository() *memoryCertificateRepository {
+	return &memoryCertificateRepository{
+		certs:      make(map[string]*domain.TLSCertificate),
+		accounts:   make(map[string]*domain.ACMEAccount),
+		challenges: make(map[string]string),
+		leases:     make(map[string]string),
+		lookups:    make(map[string]int),
+	}
+}
+
+func (r *memoryCertificateRepository) Get(_ context.Context, host string) (*domain.TLSCertificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups[host]++
+	cert, ok := r.certs[host]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cert, nil
+}
+
+func (r *memoryCertificateRepository) Save(_ context.Context, cert *domain.TLSCertificate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.certs[cert.Domain] = cert
+	return nil
+}
+
+func (r *memoryCertificateRepository) Delete(_ context.Context, host string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.certs, host)
+	return nil
+}
+
+func (r *memoryCertificateRepository) GetACMEAccount(_ context.Context, directoryURL string) (*domain.ACMEAccount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	account, ok := r.accounts[directoryURL]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return account, nil
+}
+
+func (r *memoryCertificateRepository) SaveACMEAccount(_ context.Context, account *domain.ACMEAccount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.accounts[account.DirectoryURL] = account
+	return nil
+}
+
+func (r *memoryCertificateRepository) SaveChallenge(_ context.Context, token, response string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.challenges[token] = response
+	return nil
+}
+
+func (r *memoryCertificateRepository) GetChallenge(_ context.Context, token string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	response, ok := r.challenges[token]
+	if !ok {
+		return "", domain.ErrNotFound
+	}
+	return response, nil
+}
+
+func (r *memoryCertificateRepository) DeleteChallenge(_ context.Context, token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.challenges, token)
+	return nil
+}
+
+// AcquireIssueLease без срока: аренду держат, пока её не снимут
+func (r *memoryCertificateRepository) AcquireIssueLease(_ context.Context, host, holder string, _ time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if current, ok := r.leases[host]; ok && current != holder {
+		return false, nil
+	}
+	r.leases[host] = holder
+	return true, nil
+}
+
+func (r *memoryCertificateRepository) ReleaseIssueLease(_ context.Context, host, holder string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.leases[host] == holder {
+		delete(r.leases, host)
+	}
+	return nil
+}
+
+// newTestManager менеджер с общим хранилищем; повторный вызов — второй экземпляр API
+func newTestManager(ca *testACMEServer, certRepo *memoryCertificateRepository, targetRepo *mocks.PublishTargetRepositoryMock) *Manager {
+	manager := NewManager(certRepo, targetRepo, ManagerConfig{
+		DirectoryURL: ca.directoryURL(),
+		Email:        "ops@landly.test",
+	})
+	ca.validator = manager.HTTPHandler(http.NotFoundHandler())
+	return manager
+}
+
+func TestManager_ObtainIssuesAndServesCertificate(t *testing.T) {
+	ctx := context.Background()
+	ca := newTestACMEServer(t)
+	certRepo := newMemoryCertificateRepository()
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	manager := newTestManager(ca, certRepo, targetRepo)
+
+	targetRepo.On("SetSSLStatus", mock.Anything, "shop.example.com", domain.SSLStatusPending).Return(nil).Once()
+	targetRepo.On("SetSSLStatus", mock.Anything, "shop.example.com", domain.SSLStatusActive).Return(nil).Once()
+
+	require.NoError(t, manager.Obtain(ctx, "shop.example.com"))
+	targetRepo.AssertExpectations(t)
+
+	stored, err := certRepo.Get(ctx, "shop.example.com")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(ca.validity), stored.NotAfter, time.Minute)
+
+	cert, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "Shop.Example.com"})
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	assert.NoError(t, leaf.VerifyHostname("shop.example.com"))
+	assert.Len(t, cert.Certificate, 2, "chain includes the issuer")
+
+	_, err = manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "unknown.example.com"})
+	assert.Error(t, err)
+	assert.Empty(t, certRepo.challenges, "challenge responses are removed after authorization")
+	assert.Empty(t, certRepo.leases, "issue lease is released")
+}
+
+func TestManager_ChallengeServedByAnotherInstance(t *testing.T) {
+	ctx := context.Background()
+	ca := newTestACMEServer(t)
+	certRepo := newMemoryCertificateRepository()
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	manager := newTestManager(ca, certRepo, targetRepo)
+	targetRepo.On("SetSSLStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	// Удостоверяющий центр попадает на второй экземпляр за балансировщиком
+	newTestManager(ca, certRepo, targetRepo)
+
+	require.NoError(t, manager.Obtain(ctx, "shop.example.com"))
+	assert.Equal(t, 1, ca.issuedCount())
+}
+
+func TestManager_ObtainSkipsDomainLeasedByAnotherInstance(t *testing.T) {
+	ctx := context.Background()
+	ca := newTestACMEServer(t)
+	certRepo := newMemoryCertificateRepository()
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	manager := newTestManager(ca, certRepo, targetRepo)
+	certRepo.leases["shop.example.com"] = "other-instance"
+
+	require.NoError(t, manager.Obtain(ctx, "shop.example.com"))
+	assert.Equal(t, 0, ca.issuedCount())
+	targetRepo.AssertNotCalled(t, "SetSSLStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestManager_ObtainSkipsFreshCertificate(t *testing.T) {
+	ctx := context.Background()
+	ca := newTestACMEServer(t)
+	certRepo := newMemoryCertificateRepository()
+	manager := newTestManager(ca, certRepo, new(mocks.PublishTargetRepositoryMock))
+	storeSelfSigned(t, certRepo, "shop.example.com", 80*24*time.Hour)
+
+	require.NoError(t, manager.Obtain(ctx, "shop.example.com"))
+	assert.Equal(t, 0, ca.issuedCount())
+}
+
+func TestManager_GetCertificateCachesUnknownNames(t *testing.T) {
+	ca := newTestACMEServer(t)
+	certRepo := newMemoryCertificateRepository()
+	manager := newTestManager(ca, certRepo, new(mocks.PublishTargetRepositoryMock))
+
+	for i := 0; i < 3; i++ {
+		_, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "unknown.example.com"})
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 1, certRepo.lookups["unknown.example.com"])
+}
+
+func TestManager_ReusesStoredAccount(t *testing.T) {
+	ctx := context.Background()
+	ca := newTestACMEServer(t)
+	certRepo := newMemoryCertificateRepository()
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	manager := newTestManager(ca, certRepo, targetRepo)
+	targetRepo.On("SetSSLStatus", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	require.NoError(t, manager.Obtain(ctx, "shop.example.com"))
+
+	// Другой экземпляр берёт ключ аккаунта из БД, а не регистрирует новый
+	second := newTestManager(ca, certRepo, targetRepo)
+	require.NoError(t, second.Obtain(ctx, "www.example.com"))
+
+	assert.Equal(t, 1, ca.accountCount())
+	assert.Equal(t, 2, ca.issuedCount())
+
+	cert, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "www.example.com"})
+	require.NoError(t, err, "certificates are shared through the repository")
+	assert.NotNil(t, cert)
+}
+
+func TestManager_ObtainFailureMarksDomain(t *testing.T) {
+	ctx := context.Background()
+	ca := newTestACMEServer(t)
+	certRepo := newMemoryCertificateRepository()
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	manager := newTestManager(ca, certRepo, targetRepo)
+	// Домен смотрит не на платформу: challenge не отвечает
+	ca.validator = http.NotFoundHandler()
+
+	targetRepo.On("SetSSLStatus", mock.Anything, "shop.example.com", domain.SSLStatusPending).Return(nil).Once()
+	targetRepo.On("SetSSLStatus", mock.Anything, "shop.example.com", domain.SSLStatusFailed).Return(nil).Once()
+
+	assert.Error(t, manager.Obtain(ctx, "shop.example.com"))
+	targetRepo.AssertExpectations(t)
+
+	_, err := certRepo.Get(ctx, "shop.example.com")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestManager_RenewDue(t *testing.T) {
+	ctx := context.Background()
+	ca := newTestACMEServer(t)
+	certRepo := newMemoryCertificateRepository()
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	manager := newTestManager(ca, certRepo, targetRepo)
+
+	expiring := storeSelfSigned(t, certRepo, "old.example.com", 10*24*time.Hour)
+	storeSelfSigned(t, certRepo, "fresh.example.com", 80*24*time.Hour)
+
+	targetRepo.On("ListVerifiedDomains", mock.Anything).Return([]*domain.PublishTarget{
+		{CustomDomain: "fresh.example.com"},
+		{CustomDomain: "new.example.com"},
+		{CustomDomain: "old.example.com"},
+	}, nil)
+	targetRepo.On("SetSSLStatus", mock.Anything, "new.example.com", domain.SSLStatusPending).Return(nil).Once()
+	targetRepo.On("SetSSLStatus", mock.Anything, "new.example.com", domain.SSLStatusActive).Return(nil).Once()
+	// Действующий сертификат не переводит домен в pending на время продления
+	targetRepo.On("SetSSLStatus", mock.Anything, "old.example.com", domain.SSLStatusActive).Return(nil).Once()
+
+	require.NoError(t, manager.RenewDue(ctx))
+	targetRepo.AssertExpectations(t)
+	assert.Equal(t, 2, ca.issuedCount())
+
+	renewed, err := certRepo.Get(ctx, "old.example.com")
+	require.NoError(t, err)
+	assert.True(t, renewed.NotAfter.After(expiring.NotAfter))
+}
+
+func TestManager_HTTPHandler(t *testing.T) {
+	ca := newTestACMEServer(t)
+	certRepo := newMemoryCertificateRepository()
+	manager := newTestManager(ca, certRepo, new(mocks.PublishTargetRepositoryMock))
+	require.NoError(t, certRepo.SaveChallenge(context.Background(), "token-1", "token-1.thumbprint"))
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := manager.HTTPHandler(next)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://shop.example.com/.well-known/acme-challenge/token-1", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "token-1.thumbprint", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://shop.example.com/.well-known/acme-challenge/other", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://shop.example.com/about", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+}
+
+func TestManager_Forget(t *testing.T) {
+	ctx := context.Background()
+	ca := newTestACMEServer(t)
+	certRepo := newMemoryCertificateRepository()
+	manager := newTestManager(ca, certRepo, new(mocks.PublishTargetRepositoryMock))
+	storeSelfSigned(t, certRepo, "shop.example.com", 60*24*time.Hour)
+
+	_, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "shop.example.com"})
+	require.NoError(t, err)
+
+	require.NoError(t, manager.Forget(ctx, "shop.example.com"))
+	_, err = manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "shop.example.com"})
+	assert.Error(t, err)
+}
+
+// storeSelfSigned кладёт в хранилище самоподписанный сертификат с заданным остатком срока
+func storeSelfSigned(t *testing.T, repo *memoryCertificateRepository, host string, remaining time.Duration) *domain.TLSCertificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(remaining),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyPEM, err := encodeKey(key)
+	require.NoError(t, err)
+
+	cert := &domain.TLSCertificate{
+		Domain:   host,
+		CertPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		KeyPEM:   keyPEM,
+		NotAfter: template.NotAfter,
+	}
+	require.NoError(t, repo.Save(context.Background(), cert))
+	return cert
+}
@@ -2,9 +2,14 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
 	"github.com/landly/backend/internal/handlers/dto"
 	"github.com/landly/backend/internal/logger"
@@ -17,8 +22,19 @@ type AnalyticsService interface {
 	GetProjectAnalytics(ctx context.Context, userID, projectID string) (*domain.ProjectAnalytics, error)
 	GetSiteAnalytics(ctx context.Context, userID, targetID string) (*domain.SiteAnalytics, error)
 	TrackEvent(ctx context.Context, req *domain.TrackEventRequest) error
+	TrackEvents(ctx context.Context, reqs []*domain.TrackEventRequest) error
+	GetTimeSeries(ctx context.Context, userID, projectID string, q domain.AnalyticsTimeSeriesQuery) (*domain.AnalyticsTimeSeries, error)
+	GetBreakdown(ctx context.Context, userID, projectID string, q domain.AnalyticsBreakdownQuery) (*domain.AnalyticsBreakdown, error)
+	GetFunnel(ctx context.Context, userID, projectID string, q domain.AnalyticsRangeQuery) (*domain.AnalyticsFunnel, error)
+	ListGoals(ctx context.Context, userID, projectID string) ([]*domain.AnalyticsGoal, error)
+	CreateGoal(ctx context.Context, userID, projectID string, req *domain.AnalyticsGoalRequest) (*domain.AnalyticsGoal, error)
+	UpdateGoal(ctx context.Context, userID, projectID, goalID string, req *domain.AnalyticsGoalRequest) (*domain.AnalyticsGoal, error)
+	DeleteGoal(ctx context.Context, userID, projectID, goalID string) error
 }
 
+// maxTrackBatchBodySize ограничение тела beacon-запроса с публичного сайта
+const maxTrackBatchBodySize = 64 << 10
+
 type AnalyticsHandler struct {
 	analyticsService AnalyticsService
 }
@@ -37,25 +53,98 @@ func NewAnalyticsHandler(analyticsService AnalyticsService) *AnalyticsHandler {
 // @Success 204
 // @Router /v1/analytics/{id}/event [post]
 func (h *AnalyticsHandler) TrackEvent(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+
+	// binding.JSON не требует Content-Type: application/json, как и beacon
 	var req dto.TrackEventRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBindWith(&req, binding.JSON); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	err := h.analyticsService.TrackEvent(c.Request.Context(), &domain.TrackEventRequest{
-		EventType: req.EventType,
-		Path:      req.Path,
-		Referrer:  req.Referrer,
-	})
+	err = h.analyticsService.TrackEvent(c.Request.Context(), trackEventRequest(c, projectID, req))
+	h.respondTracked(c, err)
+}
 
+// TrackEvents godoc
+// @Summary Track a batch of analytics events (navigator.sendBeacon)
+// @Tags analytics
+// @Accept json
+// @Accept plain
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param request body dto.TrackEventsRequest true "Batch of events"
+// @Success 204
+// @Router /v1/analytics/{id}/events [post]
+func (h *AnalyticsHandler) TrackEvents(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+
+	// sendBeacon отправляет text/plain, чтобы обойтись без CORS preflight
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxTrackBatchBodySize)
+	var req dto.TrackEventsRequest
+	if err := c.ShouldBindWith(&req, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reqs := make([]*domain.TrackEventRequest, 0, len(req.Events))
+	for _, event := range req.Events {
+		reqs = append(reqs, trackEventRequest(c, projectID, event))
+	}
+
+	err = h.analyticsService.TrackEvents(c.Request.Context(), reqs)
+	h.respondTracked(c, err)
+}
+
+// respondTracked отвечает на трекинг: ошибки валидации возвращаются клиенту,
+// сбои хранилища только логируются — сайт не должен от них зависеть
+func (h *AnalyticsHandler) respondTracked(c *gin.Context, err error) {
+	if err != nil {
+		status, body := domainErrorBody(err)
+		if status < http.StatusInternalServerError {
+			c.JSON(status, body)
+			return
+		}
 		logger.WithContext(c.Request.Context()).Warn("failed to track analytics event", zap.Error(err))
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
+// trackEventRequest дополняет событие данными HTTP-запроса: User-Agent, IP и,
+// если скрипт не передал страницу, её адрес из заголовка Referer
+func trackEventRequest(c *gin.Context, projectID uuid.UUID, req dto.TrackEventRequest) *domain.TrackEventRequest {
+	pageURL := req.URL
+	if pageURL == "" {
+		pageURL = c.Request.Referer()
+	}
+	path := req.Path
+	if path == "" {
+		if page, err := url.Parse(pageURL); err == nil {
+			path = page.Path
+		}
+	}
+
+	return &domain.TrackEventRequest{
+		ProjectID: projectID,
+		EventType: req.EventType,
+		Path:      path,
+		Referrer:  req.Referrer,
+		URL:       pageURL,
+		Variant:   req.Variant,
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
+
 // GetStats godoc
 // @Summary Get analytics stats
 // @Tags analytics
@@ -65,15 +154,8 @@ func (h *AnalyticsHandler) TrackEvent(c *gin.Context) {
 // @Router /v1/analytics/{id}/stats [get]
 // @Security BearerAuth
 func (h *AnalyticsHandler) GetStats(c *gin.Context) {
-	userID, ok := GetUserID(c)
+	userID, projectID, ok := projectParams(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-		return
-	}
-
-	projectID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
 		return
 	}
 
@@ -91,3 +173,359 @@ func (h *AnalyticsHandler) GetStats(c *gin.Context) {
 		UniqueVisitors: int64(stats.UniqueVisitors),
 	})
 }
+
+// GetTimeSeries godoc
+// @Summary Get analytics time series compared with the previous period
+// @Tags analytics
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param from query string false "Start, YYYY-MM-DD or RFC3339 (default: 30 days / 24 hours ago)"
+// @Param to query string false "End, YYYY-MM-DD (inclusive day) or RFC3339 (default: now)"
+// @Param granularity query string false "hour or day (default: day)"
+// @Success 200 {object} dto.AnalyticsTimeSeriesResponse
+// @Router /v1/analytics/{id}/timeseries [get]
+// @Security BearerAuth
+func (h *AnalyticsHandler) GetTimeSeries(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	series, err := h.analyticsService.GetTimeSeries(c.Request.Context(), userID.String(), projectID.String(), domain.AnalyticsTimeSeriesQuery{
+		Granularity: domain.AnalyticsGranularity(c.Query("granularity")),
+		From:        from,
+		To:          to,
+	})
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.AnalyticsTimeSeriesResponse{
+		ProjectID:   projectID,
+		Granularity: string(series.Granularity),
+		Current:     toAnalyticsPeriodResponse(series.Current),
+		Previous:    toAnalyticsPeriodResponse(series.Previous),
+		Change: dto.AnalyticsChangeResponse{
+			PageViews:      series.Change.PageViews,
+			UniqueVisitors: series.Change.UniqueVisitors,
+			CTAClicks:      series.Change.CTAClicks,
+			PayClicks:      series.Change.PayClicks,
+		},
+	})
+}
+
+// GetBreakdown godoc
+// @Summary Get top values of an analytics dimension (UTM tags, referrer, browser, OS, device)
+// @Tags analytics
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param dimension path string true "utm_source, utm_medium, utm_campaign, utm_term, utm_content, referrer, browser, os or device"
+// @Param from query string false "Start, YYYY-MM-DD or RFC3339 (default: 30 days ago)"
+// @Param to query string false "End, YYYY-MM-DD (inclusive day) or RFC3339 (default: now)"
+// @Param limit query int false "Number of values (default: 10, max: 100)"
+// @Success 200 {object} dto.AnalyticsBreakdownResponse
+// @Router /v1/analytics/{id}/breakdown/{dimension} [get]
+// @Security BearerAuth
+func (h *AnalyticsHandler) GetBreakdown(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	var limit int
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	breakdown, err := h.analyticsService.GetBreakdown(c.Request.Context(), userID.String(), projectID.String(), domain.AnalyticsBreakdownQuery{
+		Dimension: domain.AnalyticsDimension(c.Param("dimension")),
+		From:      from,
+		To:        to,
+		Limit:     limit,
+	})
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	items := make([]dto.AnalyticsBreakdownItemResponse, 0, len(breakdown.Items))
+	for _, item := range breakdown.Items {
+		items = append(items, dto.AnalyticsBreakdownItemResponse{
+			Value:          item.Value,
+			PageViews:      int64(item.PageViews),
+			UniqueVisitors: int64(item.UniqueVisitors),
+			CTAClicks:      int64(item.CTAClicks),
+			PayClicks:      int64(item.PayClicks),
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.AnalyticsBreakdownResponse{
+		ProjectID: projectID,
+		Dimension: string(breakdown.Dimension),
+		From:      breakdown.From,
+		To:        breakdown.To,
+		Items:     items,
+	})
+}
+
+// GetFunnel godoc
+// @Summary Get the pageview → CTA → pay funnel and conversions of project goals
+// @Tags analytics
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param from query string false "Start, YYYY-MM-DD or RFC3339 (default: 30 days ago)"
+// @Param to query string false "End, YYYY-MM-DD (inclusive day) or RFC3339 (default: now)"
+// @Success 200 {object} dto.AnalyticsFunnelResponse
+// @Router /v1/analytics/{id}/funnel [get]
+// @Security BearerAuth
+func (h *AnalyticsHandler) GetFunnel(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	funnel, err := h.analyticsService.GetFunnel(c.Request.Context(), userID.String(), projectID.String(), domain.AnalyticsRangeQuery{
+		From: from,
+		To:   to,
+	})
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	response := dto.AnalyticsFunnelResponse{
+		ProjectID: projectID,
+		From:      funnel.From,
+		To:        funnel.To,
+		Steps:     make([]dto.AnalyticsFunnelStepResponse, 0, len(funnel.Steps)),
+		Goals:     make([]dto.AnalyticsGoalConversionResponse, 0, len(funnel.Goals)),
+	}
+	for _, step := range funnel.Steps {
+		response.Steps = append(response.Steps, dto.AnalyticsFunnelStepResponse{
+			Name:               step.Name,
+			EventType:          step.EventType,
+			Path:               step.Path,
+			Visitors:           int64(step.Visitors),
+			ConversionRate:     step.ConversionRate,
+			StepConversionRate: step.StepConversionRate,
+			DropOff:            int64(step.DropOff),
+			DropOffRate:        step.DropOffRate,
+		})
+	}
+	for _, conversion := range funnel.Goals {
+		response.Goals = append(response.Goals, dto.AnalyticsGoalConversionResponse{
+			Goal:           toAnalyticsGoalResponse(conversion.Goal),
+			Visitors:       int64(conversion.Visitors),
+			ConversionRate: conversion.ConversionRate,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListGoals godoc
+// @Summary List project goals
+// @Tags analytics
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {array} dto.AnalyticsGoalResponse
+// @Router /v1/analytics/{id}/goals [get]
+// @Security BearerAuth
+func (h *AnalyticsHandler) ListGoals(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	goals, err := h.analyticsService.ListGoals(c.Request.Context(), userID.String(), projectID.String())
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	response := make([]dto.AnalyticsGoalResponse, 0, len(goals))
+	for _, goal := range goals {
+		response = append(response, toAnalyticsGoalResponse(goal))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateGoal godoc
+// @Summary Create project goal
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param request body dto.AnalyticsGoalRequest true "Goal"
+// @Success 201 {object} dto.AnalyticsGoalResponse
+// @Router /v1/analytics/{id}/goals [post]
+// @Security BearerAuth
+func (h *AnalyticsHandler) CreateGoal(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AnalyticsGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, err := h.analyticsService.CreateGoal(c.Request.Context(), userID.String(), projectID.String(), &domain.AnalyticsGoalRequest{
+		Name:      req.Name,
+		EventType: req.EventType,
+		Path:      req.Path,
+	})
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, toAnalyticsGoalResponse(goal))
+}
+
+// UpdateGoal godoc
+// @Summary Update project goal
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param goalId path string true "Goal ID"
+// @Param request body dto.AnalyticsGoalRequest true "Goal"
+// @Success 200 {object} dto.AnalyticsGoalResponse
+// @Router /v1/analytics/{id}/goals/{goalId} [put]
+// @Security BearerAuth
+func (h *AnalyticsHandler) UpdateGoal(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AnalyticsGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, err := h.analyticsService.UpdateGoal(c.Request.Context(), userID.String(), projectID.String(), c.Param("goalId"), &domain.AnalyticsGoalRequest{
+		Name:      req.Name,
+		EventType: req.EventType,
+		Path:      req.Path,
+	})
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toAnalyticsGoalResponse(goal))
+}
+
+// DeleteGoal godoc
+// @Summary Delete project goal
+// @Tags analytics
+// @Param id path string true "Project ID"
+// @Param goalId path string true "Goal ID"
+// @Success 204
+// @Router /v1/analytics/{id}/goals/{goalId} [delete]
+// @Security BearerAuth
+func (h *AnalyticsHandler) DeleteGoal(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	err := h.analyticsService.DeleteGoal(c.Request.Context(), userID.String(), projectID.String(), c.Param("goalId"))
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func toAnalyticsGoalResponse(goal *domain.AnalyticsGoal) dto.AnalyticsGoalResponse {
+	return dto.AnalyticsGoalResponse{
+		ID:        goal.ID,
+		ProjectID: goal.ProjectID,
+		Name:      goal.Name,
+		EventType: goal.EventType,
+		Path:      goal.Path,
+		CreatedAt: goal.CreatedAt,
+		UpdatedAt: goal.UpdatedAt,
+	}
+}
+
+// parseAnalyticsRange разбирает query-параметры from и to; при ошибке отвечает 400 и возвращает false
+func parseAnalyticsRange(c *gin.Context) (time.Time, time.Time, bool) {
+	from, _, err := parseAnalyticsTime(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from: " + err.Error()})
+		return time.Time{}, time.Time{}, false
+	}
+	to, dateOnly, err := parseAnalyticsTime(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to: " + err.Error()})
+		return time.Time{}, time.Time{}, false
+	}
+	// Дата в to включается целиком
+	if dateOnly {
+		to = to.AddDate(0, 0, 1)
+	}
+	return from, to, true
+}
+
+// parseAnalyticsTime разбирает YYYY-MM-DD (UTC) или RFC3339; пустая строка — нулевое время
+func parseAnalyticsTime(value string) (time.Time, bool, error) {
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("expected YYYY-MM-DD or RFC3339")
+	}
+	return t, false, nil
+}
+
+func toAnalyticsPeriodResponse(period domain.AnalyticsPeriod) dto.AnalyticsPeriodResponse {
+	points := make([]dto.AnalyticsPointResponse, 0, len(period.Points))
+	for _, point := range period.Points {
+		points = append(points, dto.AnalyticsPointResponse{
+			Bucket:         point.Bucket,
+			PageViews:      int64(point.PageViews),
+			UniqueVisitors: int64(point.UniqueVisitors),
+			CTAClicks:      int64(point.CTAClicks),
+			PayClicks:      int64(point.PayClicks),
+		})
+	}
+
+	return dto.AnalyticsPeriodResponse{
+		From:   period.From,
+		To:     period.To,
+		Points: points,
+		Totals: dto.AnalyticsTotalsResponse{
+			PageViews:      int64(period.Totals.TotalPageViews),
+			UniqueVisitors: int64(period.Totals.UniqueVisitors),
+			CTAClicks:      int64(period.Totals.CTAClicks),
+			PayClicks:      int64(period.Totals.PayClicks),
+		},
+	}
+}
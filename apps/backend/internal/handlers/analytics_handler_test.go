@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/landly/backend/internal/handlers/dto"
+	"github.com/landly/backend/internal/handlers/mocks"
+	domain "github.com/landly/backend/internal/models"
+)
+
+func newTrackContext(projectID, body string) (*gin.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/analytics/"+projectID+"/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/plain;charset=UTF-8")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (iPhone)")
+	req.Header.Set("Referer", "https://shop.example.com/pricing")
+	req.RemoteAddr = "203.0.113.7:51234"
+	w := httptest.NewRecorder()
+
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: projectID}}
+
+	return ctx, w
+}
+
+func TestAnalyticsHandler_TrackEvents_CapturesRequestData(t *testing.T) {
+	service := new(mocks.AnalyticsServiceMock)
+	handler := NewAnalyticsHandler(service)
+	projectID := uuid.New()
+
+	service.On("TrackEvents", mock.Anything, mock.MatchedBy(func(reqs []*domain.TrackEventRequest) bool {
+		if len(reqs) != 2 {
+			return false
+		}
+		for _, req := range reqs {
+			if req.ProjectID != projectID || req.UserAgent != "Mozilla/5.0 (iPhone)" || req.IPAddress != "203.0.113.7" {
+				return false
+			}
+		}
+		// Без path и url в событии страница берётся из Referer
+		return reqs[0].Path == "/" && reqs[0].URL == "https://shop.example.com/?utm_source=ads" &&
+			reqs[1].Path == "/pricing" && reqs[1].URL == "https://shop.example.com/pricing" &&
+			reqs[0].Referrer == "https://google.com/"
+	})).Return(nil).Once()
+
+	ctx, _ := newTrackContext(projectID.String(), `{"events":[
+		{"event_type":"pageview","path":"/","url":"https://shop.example.com/?utm_source=ads","referrer":"https://google.com/"},
+		{"event_type":"cta_click"}
+	]}`)
+	handler.TrackEvents(ctx)
+
+	assert.Equal(t, http.StatusNoContent, ctx.Writer.Status())
+	service.AssertExpectations(t)
+}
+
+func TestAnalyticsHandler_TrackEvents_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		projectID string
+		body      string
+		err       error
+		status    int
+	}{
+		{name: "invalid project", projectID: "nope", body: `{"events":[]}`, status: http.StatusBadRequest},
+		{name: "malformed body", projectID: uuid.NewString(), body: `{"events":`, status: http.StatusBadRequest},
+		{name: "validation error", projectID: uuid.NewString(), body: `{"events":[{"event_type":"hack"}]}`, err: domain.ErrBadRequest.WithMessage("unknown event type"), status: http.StatusBadRequest},
+		{name: "storage error is hidden", projectID: uuid.NewString(), body: `{"events":[{"event_type":"pageview"}]}`, err: domain.ErrInternal.WithError(errors.New("db down")), status: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := new(mocks.AnalyticsServiceMock)
+			handler := NewAnalyticsHandler(service)
+			service.On("TrackEvents", mock.Anything, mock.Anything).Return(tt.err).Maybe()
+
+			ctx, _ := newTrackContext(tt.projectID, tt.body)
+			handler.TrackEvents(ctx)
+
+			assert.Equal(t, tt.status, ctx.Writer.Status())
+		})
+	}
+}
+
+func TestAnalyticsHandler_GetTimeSeries_ParsesRange(t *testing.T) {
+	service := new(mocks.AnalyticsServiceMock)
+	handler := NewAnalyticsHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	service.On("GetTimeSeries", mock.Anything, userID.String(), projectID.String(), domain.AnalyticsTimeSeriesQuery{
+		Granularity: domain.AnalyticsGranularityDay,
+		From:        from,
+		// to=2026-03-07 включает весь день
+		To: time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC),
+	}).Return(&domain.AnalyticsTimeSeries{
+		Granularity: domain.AnalyticsGranularityDay,
+		Current:     domain.AnalyticsPeriod{From: from, Points: []domain.AnalyticsRollup{{Bucket: from, PageViews: 3}}},
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/analytics/"+projectID.String()+"/timeseries?from=2026-03-01&to=2026-03-07&granularity=day", nil)
+	w := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}}
+	ctx.Set("user_id", userID)
+
+	handler.GetTimeSeries(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.AnalyticsTimeSeriesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Current.Points, 1)
+	assert.Equal(t, int64(3), response.Current.Points[0].PageViews)
+	service.AssertExpectations(t)
+}
+
+func TestAnalyticsHandler_GetTimeSeries_InvalidDate(t *testing.T) {
+	handler := NewAnalyticsHandler(new(mocks.AnalyticsServiceMock))
+	projectID := uuid.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/analytics/"+projectID.String()+"/timeseries?from=yesterday", nil)
+	w := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}}
+	ctx.Set("user_id", uuid.New())
+
+	handler.GetTimeSeries(ctx)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAnalyticsHandler_GetBreakdown(t *testing.T) {
+	service := new(mocks.AnalyticsServiceMock)
+	handler := NewAnalyticsHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)
+	service.On("GetBreakdown", mock.Anything, userID.String(), projectID.String(), domain.AnalyticsBreakdownQuery{
+		Dimension: domain.AnalyticsDimensionUTMCampaign,
+		From:      from,
+		To:        to,
+		Limit:     5,
+	}).Return(&domain.AnalyticsBreakdown{
+		Dimension: domain.AnalyticsDimensionUTMCampaign,
+		From:      from,
+		To:        to,
+		Items:     []*domain.AnalyticsBreakdownItem{{Value: "spring", PageViews: 12, PayClicks: 3}},
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/analytics/"+projectID.String()+"/breakdown/utm_campaign?from=2026-03-01&to=2026-03-07&limit=5", nil)
+	w := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}, {Key: "dimension", Value: "utm_campaign"}}
+	ctx.Set("user_id", userID)
+
+	handler.GetBreakdown(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.AnalyticsBreakdownResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "utm_campaign", response.Dimension)
+	require.Len(t, response.Items, 1)
+	assert.Equal(t, "spring", response.Items[0].Value)
+	assert.Equal(t, int64(3), response.Items[0].PayClicks)
+	service.AssertExpectations(t)
+}
+
+func TestAnalyticsHandler_GetBreakdown_InvalidLimit(t *testing.T) {
+	handler := NewAnalyticsHandler(new(mocks.AnalyticsServiceMock))
+	projectID := uuid.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/analytics/"+projectID.String()+"/breakdown/os?limit=many", nil)
+	w := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}, {Key: "dimension", Value: "os"}}
+	ctx.Set("user_id", uuid.New())
+
+	handler.GetBreakdown(ctx)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAnalyticsHandler_CreateGoal(t *testing.T) {
+	service := new(mocks.AnalyticsServiceMock)
+	handler := NewAnalyticsHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	goal := domain.NewAnalyticsGoal(projectID, "Lead", domain.AnalyticsEventFormSubmit, "/contact")
+	service.On("CreateGoal", mock.Anything, userID.String(), projectID.String(), &domain.AnalyticsGoalRequest{
+		Name:      "Lead",
+		EventType: domain.AnalyticsEventFormSubmit,
+		Path:      "/contact",
+	}).Return(goal, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/analytics/"+projectID.String()+"/goals",
+		strings.NewReader(`{"name":"Lead","event_type":"form_submit","path":"/contact"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}}
+	ctx.Set("user_id", userID)
+
+	handler.CreateGoal(ctx)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response dto.AnalyticsGoalResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, goal.ID, response.ID)
+	assert.Equal(t, "/contact", response.Path)
+	service.AssertExpectations(t)
+}
+
+func TestAnalyticsHandler_GetFunnel(t *testing.T) {
+	service := new(mocks.AnalyticsServiceMock)
+	handler := NewAnalyticsHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	rate := 25.0
+	service.On("GetFunnel", mock.Anything, userID.String(), projectID.String(), domain.AnalyticsRangeQuery{}).Return(&domain.AnalyticsFunnel{
+		Steps: []domain.AnalyticsFunnelStepResult{
+			{AnalyticsFunnelStep: domain.AnalyticsFunnelStep{Name: "Pageview", EventType: domain.AnalyticsEventPageview}, Visitors: 4},
+			{AnalyticsFunnelStep: domain.AnalyticsFunnelStep{Name: "CTA click", EventType: domain.AnalyticsEventCTAClick}, Visitors: 1, StepConversionRate: &rate, DropOff: 3},
+		},
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/analytics/"+projectID.String()+"/funnel", nil)
+	w := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}}
+	ctx.Set("user_id", userID)
+
+	handler.GetFunnel(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.AnalyticsFunnelResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Steps, 2)
+	assert.Equal(t, int64(3), response.Steps[1].DropOff)
+	assert.Equal(t, 25.0, *response.Steps[1].StepConversionRate)
+	assert.NotNil(t, response.Goals)
+	service.AssertExpectations(t)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/landly/backend/internal/handlers/dto"
+	domain "github.com/landly/backend/internal/models"
+)
+
+// DeploymentService интерфейс сервиса версий опубликованного сайта
+type DeploymentService interface {
+	ListDeployments(ctx context.Context, userID, projectID string, limit, offset int) ([]*domain.Deployment, *uuid.UUID, error)
+	RollbackDeployment(ctx context.Context, userID, projectID, deploymentID string) (*domain.Deployment, error)
+}
+
+type DeploymentHandler struct {
+	deploymentService DeploymentService
+}
+
+func NewDeploymentHandler(deploymentService DeploymentService) *DeploymentHandler {
+	return &DeploymentHandler{
+		deploymentService: deploymentService,
+	}
+}
+
+// ListDeployments godoc
+// @Summary List published site deployments
+// @Tags deployments
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} dto.DeploymentsListResponse
+// @Router /v1/projects/{id}/deployments [get]
+// @Security BearerAuth
+func (h *DeploymentHandler) ListDeployments(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	deployments, activeID, err := h.deploymentService.ListDeployments(c.Request.Context(), userID.String(), projectID.String(), limit, offset)
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	response := dto.DeploymentsListResponse{Deployments: make([]dto.DeploymentResponse, 0, len(deployments))}
+	for _, deployment := range deployments {
+		response.Deployments = append(response.Deployments, toDeploymentResponse(deployment, activeID != nil && *activeID == deployment.ID))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RollbackDeployment godoc
+// @Summary Serve the site from an earlier deployment
+// @Tags deployments
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param deploymentId path string true "Deployment ID"
+// @Success 200 {object} dto.DeploymentResponse
+// @Router /v1/projects/{id}/deployments/{deploymentId}/rollback [post]
+// @Security BearerAuth
+func (h *DeploymentHandler) RollbackDeployment(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	deployment, err := h.deploymentService.RollbackDeployment(c.Request.Context(), userID.String(), projectID.String(), c.Param("deploymentId"))
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toDeploymentResponse(deployment, true))
+}
+
+func toDeploymentResponse(deployment *domain.Deployment, active bool) dto.DeploymentResponse {
+	response := dto.DeploymentResponse{
+		ID:         deployment.ID,
+		Version:    deployment.Version,
+		Subdomain:  deployment.Subdomain,
+		Status:     deployment.Status,
+		Active:     active,
+		AuthorID:   deployment.AuthorID,
+		CreatedAt:  deployment.CreatedAt,
+		FinishedAt: deployment.FinishedAt,
+	}
+	for _, variant := range deployment.Variants {
+		response.Variants = append(response.Variants, dto.DeploymentVariantResponse{Key: variant.Key, Weight: variant.Weight})
+	}
+	return response
+}
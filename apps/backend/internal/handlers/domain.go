@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/landly/backend/internal/handlers/dto"
+	"github.com/landly/backend/internal/logger"
+	domain "github.com/landly/backend/internal/models"
+	"go.uber.org/zap"
+)
+
+// CustomDomainService интерфейс сервиса собственных доменов
+type CustomDomainService interface {
+	GetDomain(ctx context.Context, userID, projectID string) (*domain.PublishTarget, error)
+	AttachDomain(ctx context.Context, userID, projectID, host string) (*domain.PublishTarget, error)
+	VerifyDomain(ctx context.Context, userID, projectID string) (*domain.PublishTarget, error)
+	DetachDomain(ctx context.Context, userID, projectID string) error
+	ResolveHost(ctx context.Context, host string) (string, error)
+}
+
+type DomainHandler struct {
+	domainService  CustomDomainService
+	publishService PublishService
+	// platformHosts хосты самой платформы: запросы к ним идут в обычные маршруты
+	platformHosts map[string]struct{}
+}
+
+func NewDomainHandler(domainService CustomDomainService, publishService PublishService, publicBaseURL string) *DomainHandler {
+	platformHosts := map[string]struct{}{"localhost": {}}
+	if base, err := url.Parse(publicBaseURL); err == nil && base.Hostname() != "" {
+		platformHosts[strings.ToLower(base.Hostname())] = struct{}{}
+	}
+
+	return &DomainHandler{
+		domainService:  domainService,
+		publishService: publishService,
+		platformHosts:  platformHosts,
+	}
+}
+
+// GetDomain godoc
+// @Summary Get the custom domain of the project
+// @Tags domains
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} dto.CustomDomainResponse
+// @Router /v1/projects/{id}/domain [get]
+// @Security BearerAuth
+func (h *DomainHandler) GetDomain(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	target, err := h.domainService.GetDomain(c.Request.Context(), userID.String(), projectID.String())
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toCustomDomainResponse(target))
+}
+
+// AttachDomain godoc
+// @Summary Attach a custom domain to the published project
+// @Description The site is served on the domain after the TXT record from the response is verified.
+// @Tags domains
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param request body dto.CustomDomainRequest true "Domain"
+// @Success 200 {object} dto.CustomDomainResponse
+// @Router /v1/projects/{id}/domain [put]
+// @Security BearerAuth
+func (h *DomainHandler) AttachDomain(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CustomDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	target, err := h.domainService.AttachDomain(c.Request.Context(), userID.String(), projectID.String(), req.Domain)
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toCustomDomainResponse(target))
+}
+
+// VerifyDomain godoc
+// @Summary Check the TXT record and confirm ownership of the custom domain
+// @Tags domains
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} dto.CustomDomainResponse
+// @Router /v1/projects/{id}/domain/verify [post]
+// @Security BearerAuth
+func (h *DomainHandler) VerifyDomain(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	target, err := h.domainService.VerifyDomain(c.Request.Context(), userID.String(), projectID.String())
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toCustomDomainResponse(target))
+}
+
+// DetachDomain godoc
+// @Summary Detach the custom domain from the project
+// @Tags domains
+// @Param id path string true "Project ID"
+// @Success 204
+// @Router /v1/projects/{id}/domain [delete]
+// @Security BearerAuth
+func (h *DomainHandler) DetachDomain(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	err := h.domainService.DetachDomain(c.Request.Context(), userID.String(), projectID.String())
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// customDomainSlugKey ключ контекста запроса с поддоменом сайта собственного домена
+type customDomainSlugKey struct{}
+
+// HostRouting ставит перед обработчиком платформы раздачу сайтов с собственных доменов:
+// запрос к подтверждённому домену получает сайт от корня домена. Хосты платформы, API
+// и неизвестные домены уходят в next. Работает до gin, потому что роутер платформы
+// перенаправил бы /about/ на /:slug раньше любого middleware.
+func (h *DomainHandler) HostRouting(next http.Handler) http.Handler {
+	sites := gin.New()
+	sites.Use(gin.Recovery())
+	sites.Use(logger.TraceMiddleware())
+	sites.Use(logger.LoggingMiddleware())
+	sites.Use(RequestIDMiddleware())
+	sites.NoRoute(h.serveCustomDomain)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		host := requestHost(r)
+		if !h.customHost(host) || strings.HasPrefix(r.URL.Path, "/v1/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		slug, err := h.domainService.ResolveHost(r.Context(), host)
+		if err != nil {
+			if !errors.Is(err, domain.ErrNotFound) {
+				logger.WithContext(r.Context()).Error("failed to resolve custom domain", zap.String("host", host), zap.Error(err))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		sites.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), customDomainSlugKey{}, slug)))
+	})
+}
+
+func (h *DomainHandler) serveCustomDomain(c *gin.Context) {
+	slug, _ := c.Request.Context().Value(customDomainSlugKey{}).(string)
+	if slug == "" {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	servePublishedSite(c, h.publishService, slug, c.Request.URL.Path, "/")
+}
+
+// customHost может ли хост быть собственным доменом: не платформа, не IP и не имя сервиса без точки
+func (h *DomainHandler) customHost(host string) bool {
+	if host == "" || !strings.Contains(host, ".") || net.ParseIP(host) != nil {
+		return false
+	}
+	_, platform := h.platformHosts[host]
+	return !platform
+}
+
+// requestHost хост запроса без порта в нижнем регистре
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+func toCustomDomainResponse(target *domain.PublishTarget) dto.CustomDomainResponse {
+	return dto.CustomDomainResponse{
+		ProjectID:  target.ProjectID,
+		Domain:     target.CustomDomain,
+		Verified:   target.DomainVerified(),
+		VerifiedAt: target.DomainVerifiedAt,
+		SSLStatus:  target.SSLStatus,
+		Verification: dto.DomainVerificationRecord{
+			Type:  "TXT",
+			Name:  target.DomainVerificationRecord(),
+			Value: target.DomainVerificationToken,
+		},
+	}
+}
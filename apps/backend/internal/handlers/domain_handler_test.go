@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/landly/backend/internal/handlers/dto"
+	"github.com/landly/backend/internal/handlers/mocks"
+	domain "github.com/landly/backend/internal/models"
+)
+
+func TestDomainHandler_AttachDomain(t *testing.T) {
+	service := new(mocks.CustomDomainServiceMock)
+	handler := NewDomainHandler(service, nil, "https://landly.test")
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	target := domain.NewPublishTarget(projectID, "shop-1234abcd")
+	target.CustomDomain = "shop.example.com"
+	target.DomainVerificationToken = "landly-verification=abc"
+	service.On("AttachDomain", mock.Anything, userID.String(), projectID.String(), "shop.example.com").Return(target, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/projects/"+projectID.String()+"/domain", strings.NewReader(`{"domain":"shop.example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}}
+	ctx.Set("user_id", userID)
+	handler.AttachDomain(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.CustomDomainResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Verified)
+	assert.Equal(t, domain.SSLStatusNone, response.SSLStatus)
+	assert.Equal(t, dto.DomainVerificationRecord{Type: "TXT", Name: "_landly-verification.shop.example.com", Value: "landly-verification=abc"}, response.Verification)
+	service.AssertExpectations(t)
+}
+
+func newHostRoutingEngine(handler *DomainHandler) http.Handler {
+	engine := gin.New()
+	engine.GET("/v1/ping", func(c *gin.Context) { c.String(http.StatusOK, "api") })
+	engine.GET("/:slug", func(c *gin.Context) { c.String(http.StatusOK, "platform") })
+	return handler.HostRouting(engine)
+}
+
+func TestDomainHandler_HostRouting_ServesSiteAtDomainRoot(t *testing.T) {
+	service := new(mocks.CustomDomainServiceMock)
+	publishService := new(mocks.PublishServiceMock)
+	engine := newHostRoutingEngine(NewDomainHandler(service, publishService, "https://landly.test"))
+
+	service.On("ResolveHost", mock.Anything, "shop.example.com").Return("shop-1234abcd", nil)
+	publishService.On("ServePublished", mock.Anything, "shop-1234abcd", "about/", "").Return(&domain.PublishedAsset{
+		Body:        io.NopCloser(strings.NewReader("<html>About</html>")),
+		ContentType: "text/html",
+		Variant:     "b",
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/about/", nil)
+	req.Host = "Shop.Example.com:443"
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "<html>About</html>", w.Body.String())
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "/", cookies[0].Path)
+
+	// Без завершающего слэша относительные ссылки страницы сломаются
+	req = httptest.NewRequest(http.MethodGet, "/about", nil)
+	req.Host = "shop.example.com"
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/about/", w.Header().Get("Location"))
+	publishService.AssertExpectations(t)
+}
+
+func TestDomainHandler_HostRouting_PassesThrough(t *testing.T) {
+	service := new(mocks.CustomDomainServiceMock)
+	engine := newHostRoutingEngine(NewDomainHandler(service, nil, "https://landly.test"))
+	service.On("ResolveHost", mock.Anything, "unknown.example.com").Return("", domain.ErrNotFound)
+	service.On("ResolveHost", mock.Anything, "shop.example.com").Return("shop-1234abcd", nil)
+
+	cases := []struct {
+		host, path, body string
+	}{
+		{"landly.test", "/demo", "platform"},
+		{"localhost:8080", "/demo", "platform"},
+		{"10.0.0.5:8080", "/demo", "platform"},
+		{"backend:8080", "/demo", "platform"},
+		{"unknown.example.com", "/demo", "platform"},
+		{"shop.example.com", "/v1/ping", "api"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.host+tc.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Host = tc.host
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tc.body, w.Body.String())
+		})
+	}
+	service.AssertNumberOfCalls(t, "ResolveHost", 1)
+}
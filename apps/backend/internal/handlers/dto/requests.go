@@ -1,5 +1,7 @@
 package dto
 
+import "encoding/json"
+
 // Auth requests
 type SignUpRequest struct {
 	Email    string `json:"email" binding:"required,email"`
@@ -33,12 +35,93 @@ type GenerateRequest struct {
 }
 
 type ChatMessageRequest struct {
-	Content string `json:"content" binding:"required"`
+	Content string           `json:"content" binding:"required"`
+	Target  *ChatBlockTarget `json:"target,omitempty"`
+}
+
+// ChatBlockTarget блок, который нужно перегенерировать вместо всей схемы
+type ChatBlockTarget struct {
+	PagePath   string `json:"page_path"`
+	BlockIndex *int   `json:"block_index"`
+	BlockType  string `json:"block_type"`
+}
+
+// UpdateSchemaRequest ручная правка схемы лендинга
+type UpdateSchemaRequest struct {
+	Schema json.RawMessage `json:"schema" binding:"required"`
 }
 
 // Analytics requests
 type TrackEventRequest struct {
 	EventType string `json:"event_type" binding:"required"`
-	Path      string `json:"path" binding:"required"`
+	Path      string `json:"path"`
 	Referrer  string `json:"referrer"`
+	URL       string `json:"url"`
+	Variant   string `json:"variant"`
+}
+
+// AnalyticsGoalRequest цель проекта; path ограничивает цель страницей
+type AnalyticsGoalRequest struct {
+	Name      string `json:"name" binding:"required"`
+	EventType string `json:"event_type" binding:"required"`
+	Path      string `json:"path"`
+}
+
+// ProjectVariantRequest вариант A/B-теста; weight — доля трафика в процентах.
+// Без schema новый вариант копирует основную схему, а изменяемый сохраняет свою.
+type ProjectVariantRequest struct {
+	Name   string          `json:"name" binding:"required"`
+	Schema json.RawMessage `json:"schema"`
+	Weight int             `json:"weight"`
+}
+
+// CustomDomainRequest собственный домен проекта, например shop.example.com
+type CustomDomainRequest struct {
+	Domain string `json:"domain" binding:"required"`
+}
+
+// CreateIntegrationRequest платёжная интеграция: type — stripe или paypal, payment_url — https-ссылка на оплату,
+// webhook_secret — секрет подписи вебхуков Stripe (whsec_...), необязателен
+type CreateIntegrationRequest struct {
+	Type          string `json:"type" binding:"required"`
+	PaymentURL    string `json:"payment_url" binding:"required"`
+	WebhookSecret string `json:"webhook_secret"`
+}
+
+// UpdateIntegrationRequest новая платёжная ссылка или секрет вебхуков; пустые поля не меняются
+type UpdateIntegrationRequest struct {
+	PaymentURL    string `json:"payment_url"`
+	WebhookSecret string `json:"webhook_secret"`
+}
+
+// SubmitFormRequest заявка из формы лендинга: honeypot и token заполняет скрипт блока form
+type SubmitFormRequest struct {
+	Form     string            `json:"form" binding:"required"`
+	Fields   map[string]string `json:"fields"`
+	Path     string            `json:"path"`
+	Variant  string            `json:"variant"`
+	Honeypot string            `json:"honeypot"`
+	Token    string            `json:"token"`
+}
+
+// TrackEventsRequest пачка событий из navigator.sendBeacon
+type TrackEventsRequest struct {
+	Events []TrackEventRequest `json:"events" binding:"required,dive"`
+}
+
+// CreateWebhookRequest подписка на события проекта: url — адрес подписчика, events — типы событий
+// (site.published, lead.submitted, purchase.completed, goal.reached)
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required"`
+	Active *bool    `json:"active"`
+}
+
+// UpdateWebhookRequest изменение подписки; незаданные поля не меняются,
+// rotate_secret выдаёт новый секрет подписи
+type UpdateWebhookRequest struct {
+	URL          string   `json:"url"`
+	Events       []string `json:"events"`
+	Active       *bool    `json:"active"`
+	RotateSecret bool     `json:"rotate_secret"`
 }
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -50,20 +51,35 @@ type ProjectsListResponse struct {
 }
 
 type GenerationSessionResponse struct {
-	ID        uuid.UUID `json:"id"`
-	ProjectID uuid.UUID `json:"project_id"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uuid.UUID  `json:"id"`
+	ProjectID uuid.UUID  `json:"project_id"`
+	Status    string     `json:"status"`
+	JobID     *uuid.UUID `json:"job_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
 }
 
 type ChatSessionResponse struct {
-	ID          uuid.UUID  `json:"id"`
-	ProjectID   uuid.UUID  `json:"project_id"`
-	Status      string     `json:"status"`
-	SchemaJSON  string     `json:"schema_json"`
-	CompletedAt *time.Time `json:"completed_at,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID          uuid.UUID       `json:"id"`
+	ProjectID   uuid.UUID       `json:"project_id"`
+	Status      string          `json:"status"`
+	SchemaJSON  string          `json:"schema_json"`
+	Errors      json.RawMessage `json:"errors,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// ChatStreamTokenResponse фрагмент ответа модели (SSE-событие token)
+type ChatStreamTokenResponse struct {
+	Delta   string `json:"delta"`
+	Attempt int    `json:"attempt"`
+}
+
+// ChatStreamBlockResponse блок, разобранный из потока (SSE-событие block_parsed)
+type ChatStreamBlockResponse struct {
+	Index   int             `json:"index"`
+	Attempt int             `json:"attempt"`
+	Block   json.RawMessage `json:"block"`
 }
 
 type ChatMessageResponse struct {
@@ -85,9 +101,39 @@ type PreviewResponse struct {
 }
 
 type PublishResponse struct {
-	Subdomain   string `json:"subdomain"`
-	PublicURL   string `json:"public_url"`
-	PublishedAt string `json:"published_at"`
+	Subdomain   string     `json:"subdomain"`
+	PublicURL   string     `json:"public_url"`
+	PublishedAt string     `json:"published_at"`
+	JobID       *uuid.UUID `json:"job_id,omitempty"`
+}
+
+// Schema revision responses
+type SchemaRevisionResponse struct {
+	ID           uuid.UUID       `json:"id"`
+	Version      int             `json:"version"`
+	Source       string          `json:"source"`
+	AuthorID     *uuid.UUID      `json:"author_id,omitempty"`
+	MessageID    *uuid.UUID      `json:"message_id,omitempty"`
+	RestoredFrom *uuid.UUID      `json:"restored_from,omitempty"`
+	Schema       json.RawMessage `json:"schema,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+type SchemaRevisionsListResponse struct {
+	Revisions []SchemaRevisionResponse `json:"revisions"`
+}
+
+type SchemaDiffResponse struct {
+	From    uuid.UUID          `json:"from"`
+	To      uuid.UUID          `json:"to"`
+	Changes []SchemaDiffChange `json:"changes"`
+}
+
+type SchemaDiffChange struct {
+	Op       string      `json:"op"`
+	Path     string      `json:"path"`
+	OldValue interface{} `json:"old_value,omitempty"`
+	NewValue interface{} `json:"new_value,omitempty"`
 }
 
 // Analytics responses
@@ -98,3 +144,285 @@ type AnalyticsStatsResponse struct {
 	TotalPayClicks int64     `json:"total_pay_clicks"`
 	UniqueVisitors int64     `json:"unique_visitors"`
 }
+
+type AnalyticsPointResponse struct {
+	Bucket         time.Time `json:"bucket"`
+	PageViews      int64     `json:"pageviews"`
+	UniqueVisitors int64     `json:"unique_visitors"`
+	CTAClicks      int64     `json:"cta_clicks"`
+	PayClicks      int64     `json:"pay_clicks"`
+}
+
+type AnalyticsTotalsResponse struct {
+	PageViews      int64 `json:"pageviews"`
+	UniqueVisitors int64 `json:"unique_visitors"`
+	CTAClicks      int64 `json:"cta_clicks"`
+	PayClicks      int64 `json:"pay_clicks"`
+}
+
+type AnalyticsPeriodResponse struct {
+	From   time.Time                `json:"from"`
+	To     time.Time                `json:"to"`
+	Points []AnalyticsPointResponse `json:"points"`
+	Totals AnalyticsTotalsResponse  `json:"totals"`
+}
+
+// AnalyticsChangeResponse изменение итогов в процентах; null, если в предыдущем периоде был 0
+type AnalyticsChangeResponse struct {
+	PageViews      *float64 `json:"pageviews"`
+	UniqueVisitors *float64 `json:"unique_visitors"`
+	CTAClicks      *float64 `json:"cta_clicks"`
+	PayClicks      *float64 `json:"pay_clicks"`
+}
+
+type AnalyticsTimeSeriesResponse struct {
+	ProjectID   uuid.UUID               `json:"project_id"`
+	Granularity string                  `json:"granularity"`
+	Current     AnalyticsPeriodResponse `json:"current"`
+	Previous    AnalyticsPeriodResponse `json:"previous"`
+	Change      AnalyticsChangeResponse `json:"change"`
+}
+
+type AnalyticsBreakdownItemResponse struct {
+	Value          string `json:"value"`
+	PageViews      int64  `json:"pageviews"`
+	UniqueVisitors int64  `json:"unique_visitors"`
+	CTAClicks      int64  `json:"cta_clicks"`
+	PayClicks      int64  `json:"pay_clicks"`
+}
+
+type AnalyticsBreakdownResponse struct {
+	ProjectID uuid.UUID                        `json:"project_id"`
+	Dimension string                           `json:"dimension"`
+	From      time.Time                        `json:"from"`
+	To        time.Time                        `json:"to"`
+	Items     []AnalyticsBreakdownItemResponse `json:"items"`
+}
+
+type AnalyticsGoalResponse struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	Name      string    `json:"name"`
+	EventType string    `json:"event_type"`
+	Path      string    `json:"path,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AnalyticsFunnelStepResponse проценты null, если база для них равна нулю
+type AnalyticsFunnelStepResponse struct {
+	Name               string   `json:"name"`
+	EventType          string   `json:"event_type"`
+	Path               string   `json:"path,omitempty"`
+	Visitors           int64    `json:"visitors"`
+	ConversionRate     *float64 `json:"conversion_rate"`
+	StepConversionRate *float64 `json:"step_conversion_rate"`
+	DropOff            int64    `json:"drop_off"`
+	DropOffRate        *float64 `json:"drop_off_rate"`
+}
+
+type AnalyticsGoalConversionResponse struct {
+	Goal           AnalyticsGoalResponse `json:"goal"`
+	Visitors       int64                 `json:"visitors"`
+	ConversionRate *float64              `json:"conversion_rate"`
+}
+
+type AnalyticsFunnelResponse struct {
+	ProjectID uuid.UUID                         `json:"project_id"`
+	From      time.Time                         `json:"from"`
+	To        time.Time                         `json:"to"`
+	Steps     []AnalyticsFunnelStepResponse     `json:"steps"`
+	Goals     []AnalyticsGoalConversionResponse `json:"goals"`
+}
+
+// Experiment responses
+type ProjectVariantResponse struct {
+	ID        uuid.UUID       `json:"id"`
+	ProjectID uuid.UUID       `json:"project_id"`
+	Name      string          `json:"name"`
+	Weight    int             `json:"weight"`
+	Schema    json.RawMessage `json:"schema,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type ProjectVariantsListResponse struct {
+	// ControlWeight доля трафика основной схемы
+	ControlWeight int                      `json:"control_weight"`
+	Variants      []ProjectVariantResponse `json:"variants"`
+}
+
+// VariantResultResponse uplift и p_value null, пока сравнивать с контролем не с чем
+type VariantResultResponse struct {
+	Key            string   `json:"key"`
+	Name           string   `json:"name"`
+	Weight         int      `json:"weight"`
+	Visitors       int      `json:"visitors"`
+	Conversions    int      `json:"conversions"`
+	ConversionRate *float64 `json:"conversion_rate"`
+	Uplift         *float64 `json:"uplift"`
+	PValue         *float64 `json:"p_value"`
+	Significant    bool     `json:"significant"`
+}
+
+type ExperimentResultsResponse struct {
+	ProjectID uuid.UUID               `json:"project_id"`
+	From      time.Time               `json:"from"`
+	To        time.Time               `json:"to"`
+	Goal      ExperimentGoalResponse  `json:"goal"`
+	Variants  []VariantResultResponse `json:"variants"`
+	Winner    string                  `json:"winner,omitempty"`
+}
+
+// ExperimentGoalResponse событие, которое считается конверсией
+type ExperimentGoalResponse struct {
+	Name      string `json:"name"`
+	EventType string `json:"event_type"`
+	Path      string `json:"path,omitempty"`
+}
+
+type PromoteVariantResponse struct {
+	ProjectID uuid.UUID       `json:"project_id"`
+	Promoted  string          `json:"promoted"`
+	Schema    json.RawMessage `json:"schema,omitempty"`
+}
+
+// Deployment responses
+type DeploymentResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Version    int        `json:"version"`
+	Subdomain  string     `json:"subdomain"`
+	Status     string     `json:"status"`
+	Active     bool       `json:"active"`
+	AuthorID   *uuid.UUID `json:"author_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// Variants варианты A/B-теста, опубликованные в этом деплое
+	Variants []DeploymentVariantResponse `json:"variants,omitempty"`
+}
+
+type DeploymentVariantResponse struct {
+	Key    string `json:"key"`
+	Weight int    `json:"weight"`
+}
+
+type DeploymentsListResponse struct {
+	Deployments []DeploymentResponse `json:"deployments"`
+}
+
+// Custom domain responses
+type CustomDomainResponse struct {
+	ProjectID  uuid.UUID  `json:"project_id"`
+	Domain     string     `json:"domain"`
+	Verified   bool       `json:"verified"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	SSLStatus  string     `json:"ssl_status"`
+	// Verification TXT-запись, которую владелец домена добавляет в DNS
+	Verification DomainVerificationRecord `json:"verification"`
+}
+
+type DomainVerificationRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Integration responses
+type IntegrationResponse struct {
+	ID         uuid.UUID `json:"id"`
+	ProjectID  uuid.UUID `json:"project_id"`
+	Type       string    `json:"type"`
+	PaymentURL string    `json:"payment_url"`
+	// WebhookConfigured задан секрет вебхуков; сам секрет не возвращается
+	WebhookConfigured bool      `json:"webhook_configured"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type IntegrationsListResponse struct {
+	Integrations []IntegrationResponse `json:"integrations"`
+}
+
+// Form submission responses
+type FormSubmissionResponse struct {
+	ID        uuid.UUID         `json:"id"`
+	Form      string            `json:"form"`
+	Fields    map[string]string `json:"fields"`
+	Path      string            `json:"path"`
+	Variant   string            `json:"variant,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+type FormSubmissionsListResponse struct {
+	Submissions []FormSubmissionResponse `json:"submissions"`
+}
+
+type FormTokenResponse struct {
+	Token string `json:"token"`
+}
+
+// Purchase responses
+type PurchaseResponse struct {
+	ID         uuid.UUID `json:"id"`
+	Provider   string    `json:"provider"`
+	ExternalID string    `json:"external_id"`
+	Amount     int64     `json:"amount"`
+	Currency   string    `json:"currency"`
+	Variant    string    `json:"variant,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type PurchasesListResponse struct {
+	Purchases []PurchaseResponse `json:"purchases"`
+}
+
+// Job responses
+type JobResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Type        string     `json:"type"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	LastError   string     `json:"last_error,omitempty"`
+	ProjectID   *uuid.UUID `json:"project_id,omitempty"`
+	RunAt       time.Time  `json:"run_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// Webhook responses
+type WebhookResponse struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	URL       string    `json:"url"`
+	// Secret ключ HMAC-подписи заголовка X-Landly-Signature; отдаётся только при создании и смене секрета
+	Secret string `json:"secret,omitempty"`
+	// SecretHint последние символы секрета, чтобы отличить его от других
+	SecretHint string    `json:"secret_hint"`
+	Events     []string  `json:"events"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type WebhooksListResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID         uuid.UUID `json:"id"`
+	EventID    uuid.UUID `json:"event_id"`
+	Event      string    `json:"event"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	Payload    string    `json:"payload"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookDeliveriesListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/landly/backend/internal/handlers/dto"
+	domain "github.com/landly/backend/internal/models"
+)
+
+// ExperimentService интерфейс сервиса A/B-тестов
+type ExperimentService interface {
+	ListVariants(ctx context.Context, userID, projectID string) ([]*domain.ProjectVariant, error)
+	CreateVariant(ctx context.Context, userID, projectID string, req *domain.ProjectVariantRequest) (*domain.ProjectVariant, error)
+	UpdateVariant(ctx context.Context, userID, projectID, variantID string, req *domain.ProjectVariantRequest) (*domain.ProjectVariant, error)
+	DeleteVariant(ctx context.Context, userID, projectID, variantID string) error
+	PromoteVariant(ctx context.Context, userID, projectID, variantID string) (*domain.Project, error)
+	GetResults(ctx context.Context, userID, projectID string, query *domain.ExperimentResultsQuery) (*domain.ExperimentResults, error)
+}
+
+type ExperimentHandler struct {
+	experimentService ExperimentService
+}
+
+func NewExperimentHandler(experimentService ExperimentService) *ExperimentHandler {
+	return &ExperimentHandler{
+		experimentService: experimentService,
+	}
+}
+
+// ListVariants godoc
+// @Summary List A/B test variants of the project
+// @Tags experiments
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} dto.ProjectVariantsListResponse
+// @Router /v1/projects/{id}/variants [get]
+// @Security BearerAuth
+func (h *ExperimentHandler) ListVariants(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	variants, err := h.experimentService.ListVariants(c.Request.Context(), userID.String(), projectID.String())
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	response := dto.ProjectVariantsListResponse{
+		ControlWeight: 100,
+		Variants:      make([]dto.ProjectVariantResponse, 0, len(variants)),
+	}
+	for _, variant := range variants {
+		response.ControlWeight -= variant.Weight
+		response.Variants = append(response.Variants, toProjectVariantResponse(variant, false))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateVariant godoc
+// @Summary Add an A/B test variant
+// @Tags experiments
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param request body dto.ProjectVariantRequest true "Variant"
+// @Success 201 {object} dto.ProjectVariantResponse
+// @Router /v1/projects/{id}/variants [post]
+// @Security BearerAuth
+func (h *ExperimentHandler) CreateVariant(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ProjectVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	variant, err := h.experimentService.CreateVariant(c.Request.Context(), userID.String(), projectID.String(), toProjectVariantRequest(req))
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, toProjectVariantResponse(variant, true))
+}
+
+// UpdateVariant godoc
+// @Summary Update an A/B test variant
+// @Tags experiments
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param variantId path string true "Variant ID"
+// @Param request body dto.ProjectVariantRequest true "Variant"
+// @Success 200 {object} dto.ProjectVariantResponse
+// @Router /v1/projects/{id}/variants/{variantId} [put]
+// @Security BearerAuth
+func (h *ExperimentHandler) UpdateVariant(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ProjectVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	variant, err := h.experimentService.UpdateVariant(c.Request.Context(), userID.String(), projectID.String(), c.Param("variantId"), toProjectVariantRequest(req))
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toProjectVariantResponse(variant, true))
+}
+
+// DeleteVariant godoc
+// @Summary Delete an A/B test variant
+// @Tags experiments
+// @Param id path string true "Project ID"
+// @Param variantId path string true "Variant ID"
+// @Success 204
+// @Router /v1/projects/{id}/variants/{variantId} [delete]
+// @Security BearerAuth
+func (h *ExperimentHandler) DeleteVariant(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	err := h.experimentService.DeleteVariant(c.Request.Context(), userID.String(), projectID.String(), c.Param("variantId"))
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// PromoteVariant godoc
+// @Summary Make the variant the main schema and finish the experiment
+// @Description variantId "control" keeps the main schema. Visitors see the result after the next publish.
+// @Tags experiments
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param variantId path string true "Variant ID or control"
+// @Success 200 {object} dto.PromoteVariantResponse
+// @Router /v1/projects/{id}/variants/{variantId}/promote [post]
+// @Security BearerAuth
+func (h *ExperimentHandler) PromoteVariant(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	variantID := c.Param("variantId")
+	project, err := h.experimentService.PromoteVariant(c.Request.Context(), userID.String(), projectID.String(), variantID)
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	response := dto.PromoteVariantResponse{ProjectID: project.ID, Promoted: variantID}
+	if json.Valid([]byte(project.SchemaJSON)) {
+		response.Schema = json.RawMessage(project.SchemaJSON)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetResults godoc
+// @Summary Get conversion of A/B test variants with significance against control
+// @Tags experiments
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param from query string false "Start, YYYY-MM-DD or RFC3339 (default: 30 days ago)"
+// @Param to query string false "End, YYYY-MM-DD (inclusive day) or RFC3339 (default: now)"
+// @Param goal_id query string false "Project goal (default: pay_click)"
+// @Success 200 {object} dto.ExperimentResultsResponse
+// @Router /v1/projects/{id}/experiment/results [get]
+// @Security BearerAuth
+func (h *ExperimentHandler) GetResults(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	query := &domain.ExperimentResultsQuery{From: from, To: to}
+	if raw := c.Query("goal_id"); raw != "" {
+		goalID, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid goal_id"})
+			return
+		}
+		query.GoalID = &goalID
+	}
+
+	results, err := h.experimentService.GetResults(c.Request.Context(), userID.String(), projectID.String(), query)
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	response := dto.ExperimentResultsResponse{
+		ProjectID: projectID,
+		From:      results.From,
+		To:        results.To,
+		Goal: dto.ExperimentGoalResponse{
+			Name:      results.Goal.Name,
+			EventType: results.Goal.EventType,
+			Path:      results.Goal.Path,
+		},
+		Variants: make([]dto.VariantResultResponse, 0, len(results.Variants)),
+		Winner:   results.Winner,
+	}
+	for _, variant := range results.Variants {
+		response.Variants = append(response.Variants, dto.VariantResultResponse{
+			Key:            variant.Key,
+			Name:           variant.Name,
+			Weight:         variant.Weight,
+			Visitors:       variant.Visitors,
+			Conversions:    variant.Conversions,
+			ConversionRate: variant.ConversionRate,
+			Uplift:         variant.Uplift,
+			PValue:         variant.PValue,
+			Significant:    variant.Significant,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func toProjectVariantRequest(req dto.ProjectVariantRequest) *domain.ProjectVariantRequest {
+	schemaJSON := ""
+	if len(req.Schema) > 0 && string(req.Schema) != "null" {
+		schemaJSON = string(req.Schema)
+	}
+	return &domain.ProjectVariantRequest{
+		Name:       req.Name,
+		SchemaJSON: schemaJSON,
+		Weight:     req.Weight,
+	}
+}
+
+func toProjectVariantResponse(variant *domain.ProjectVariant, withSchema bool) dto.ProjectVariantResponse {
+	response := dto.ProjectVariantResponse{
+		ID:        variant.ID,
+		ProjectID: variant.ProjectID,
+		Name:      variant.Name,
+		Weight:    variant.Weight,
+		CreatedAt: variant.CreatedAt,
+		UpdatedAt: variant.UpdatedAt,
+	}
+	if withSchema && json.Valid([]byte(variant.SchemaJSON)) {
+		response.Schema = json.RawMessage(variant.SchemaJSON)
+	}
+	return response
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/landly/backend/internal/handlers/dto"
+	"github.com/landly/backend/internal/handlers/mocks"
+	domain "github.com/landly/backend/internal/models"
+)
+
+func newExperimentContext(method, target, body string, userID uuid.UUID, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = req
+	ctx.Params = params
+	ctx.Set("user_id", userID)
+	return ctx, w
+}
+
+func TestExperimentHandler_CreateVariant(t *testing.T) {
+	service := new(mocks.ExperimentServiceMock)
+	handler := NewExperimentHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	schemaJSON := `{"version":"1.0","pages":[]}`
+	variant := domain.NewProjectVariant(projectID, "Short hero", schemaJSON, 30)
+	service.On("CreateVariant", mock.Anything, userID.String(), projectID.String(), &domain.ProjectVariantRequest{
+		Name:       "Short hero",
+		SchemaJSON: schemaJSON,
+		Weight:     30,
+	}).Return(variant, nil).Once()
+
+	ctx, w := newExperimentContext(http.MethodPost, "/v1/projects/"+projectID.String()+"/variants",
+		`{"name":"Short hero","weight":30,"schema":`+schemaJSON+`}`, userID, gin.Params{{Key: "id", Value: projectID.String()}})
+	handler.CreateVariant(ctx)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response dto.ProjectVariantResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, variant.ID, response.ID)
+	assert.JSONEq(t, schemaJSON, string(response.Schema))
+	service.AssertExpectations(t)
+}
+
+func TestExperimentHandler_ListVariants_ControlWeight(t *testing.T) {
+	service := new(mocks.ExperimentServiceMock)
+	handler := NewExperimentHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	service.On("ListVariants", mock.Anything, userID.String(), projectID.String()).Return([]*domain.ProjectVariant{
+		domain.NewProjectVariant(projectID, "B", "{}", 30),
+		domain.NewProjectVariant(projectID, "C", "{}", 20),
+	}, nil).Once()
+
+	ctx, w := newExperimentContext(http.MethodGet, "/v1/projects/"+projectID.String()+"/variants", "", userID, gin.Params{{Key: "id", Value: projectID.String()}})
+	handler.ListVariants(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.ProjectVariantsListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 50, response.ControlWeight)
+	require.Len(t, response.Variants, 2)
+	assert.Empty(t, response.Variants[0].Schema)
+}
+
+func TestExperimentHandler_GetResults(t *testing.T) {
+	service := new(mocks.ExperimentServiceMock)
+	handler := NewExperimentHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+	goalID := uuid.New()
+
+	rate, pValue := 8.0, 0.0065
+	service.On("GetResults", mock.Anything, userID.String(), projectID.String(), &domain.ExperimentResultsQuery{GoalID: &goalID}).Return(&domain.ExperimentResults{
+		Goal: domain.AnalyticsFunnelStep{Name: "Lead", EventType: domain.AnalyticsEventFormSubmit},
+		Variants: []domain.VariantResult{
+			{Key: "b", Name: "B", Visitors: 1000, Conversions: 80, ConversionRate: &rate, PValue: &pValue, Significant: true},
+		},
+		Winner: "b",
+	}, nil).Once()
+
+	ctx, w := newExperimentContext(http.MethodGet, "/v1/projects/"+projectID.String()+"/experiment/results?goal_id="+goalID.String(), "",
+		userID, gin.Params{{Key: "id", Value: projectID.String()}})
+	handler.GetResults(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.ExperimentResultsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "b", response.Winner)
+	assert.Equal(t, domain.AnalyticsEventFormSubmit, response.Goal.EventType)
+	require.Len(t, response.Variants, 1)
+	assert.True(t, response.Variants[0].Significant)
+	service.AssertExpectations(t)
+}
+
+func TestExperimentHandler_GetResults_InvalidGoal(t *testing.T) {
+	service := new(mocks.ExperimentServiceMock)
+	handler := NewExperimentHandler(service)
+	projectID := uuid.New()
+
+	ctx, w := newExperimentContext(http.MethodGet, "/v1/projects/"+projectID.String()+"/experiment/results?goal_id=lead", "",
+		uuid.New(), gin.Params{{Key: "id", Value: projectID.String()}})
+	handler.GetResults(ctx)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	service.AssertNotCalled(t, "GetResults", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
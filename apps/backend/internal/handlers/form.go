@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/landly/backend/internal/handlers/dto"
+	domain "github.com/landly/backend/internal/models"
+)
+
+const (
+	// maxFormSubmissionBodySize предел тела заявки
+	maxFormSubmissionBodySize = 64 << 10
+	// formSubmissionsPerMinute предел заявок с одного IP в минуту
+	formSubmissionsPerMinute = 10
+)
+
+// FormService интерфейс сервиса заявок
+type FormService interface {
+	IssueFormToken(projectID uuid.UUID, formName string) string
+	SubmitForm(ctx context.Context, req *domain.FormSubmissionRequest) error
+	ListSubmissions(ctx context.Context, userID, projectID string, query domain.FormSubmissionsQuery) ([]*domain.FormSubmission, error)
+	ExportSubmissions(ctx context.Context, userID, projectID, formName string, w io.Writer) error
+}
+
+type FormHandler struct {
+	formService FormService
+}
+
+func NewFormHandler(formService FormService) *FormHandler {
+	return &FormHandler{
+		formService: formService,
+	}
+}
+
+// IssueFormToken godoc
+// @Summary Issue a token for a lead form of a published site
+// @Description The form script requests the token on page load and sends it with the submission, so the server can tell how long the form was filled in.
+// @Tags forms
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param form query string true "Form name"
+// @Success 200 {object} dto.FormTokenResponse
+// @Router /v1/forms/{id}/token [get]
+func (h *FormHandler) IssueFormToken(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+
+	formName := c.Query("form")
+	if formName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "form is required"})
+		return
+	}
+
+	// Токен несёт время выдачи, поэтому ответ не должен попасть в кэш
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, dto.FormTokenResponse{Token: h.formService.IssueFormToken(projectID, formName)})
+}
+
+// SubmitForm godoc
+// @Summary Submit a lead form from a published site
+// @Description Fields are validated against the form block of the project schema. Spam is discarded silently.
+// @Tags forms
+// @Accept json
+// @Accept plain
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param request body dto.SubmitFormRequest true "Submission"
+// @Success 204
+// @Router /v1/forms/{id}/submissions [post]
+func (h *FormHandler) SubmitForm(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+
+	// Скрипт формы отправляет text/plain, чтобы обойтись без CORS preflight
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxFormSubmissionBodySize)
+	var req dto.SubmitFormRequest
+	if err := c.ShouldBindWith(&req, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.formService.SubmitForm(c.Request.Context(), &domain.FormSubmissionRequest{
+		ProjectID: projectID,
+		Form:      req.Form,
+		Fields:    req.Fields,
+		Path:      req.Path,
+		Variant:   req.Variant,
+		Honeypot:  req.Honeypot,
+		Token:     req.Token,
+	})
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListSubmissions godoc
+// @Summary List lead form submissions of the project
+// @Tags forms
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param form query string false "Form name"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} dto.FormSubmissionsListResponse
+// @Router /v1/projects/{id}/submissions [get]
+// @Security BearerAuth
+func (h *FormHandler) ListSubmissions(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	submissions, err := h.formService.ListSubmissions(c.Request.Context(), userID.String(), projectID.String(), domain.FormSubmissionsQuery{
+		Form:   c.Query("form"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	response := dto.FormSubmissionsListResponse{Submissions: make([]dto.FormSubmissionResponse, 0, len(submissions))}
+	for _, submission := range submissions {
+		// Повреждённые данные не прячут заявку: видны дата, форма и страница
+		fields, _ := submission.Fields()
+		response.Submissions = append(response.Submissions, dto.FormSubmissionResponse{
+			ID:        submission.ID,
+			Form:      submission.FormName,
+			Fields:    fields,
+			Path:      submission.Path,
+			Variant:   submission.Variant,
+			CreatedAt: submission.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ExportSubmissions godoc
+// @Summary Export lead form submissions as CSV
+// @Tags forms
+// @Produce text/csv
+// @Param id path string true "Project ID"
+// @Param form query string false "Form name"
+// @Success 200 {file} file
+// @Router /v1/projects/{id}/submissions/export [get]
+// @Security BearerAuth
+func (h *FormHandler) ExportSubmissions(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	// CSV собирается в буфер, чтобы ошибка не оборвала уже начатый ответ
+	var buf bytes.Buffer
+	err := h.formService.ExportSubmissions(c.Request.Context(), userID.String(), projectID.String(), c.Query("form"), &buf)
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="submissions-`+projectID.String()+`.csv"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/landly/backend/internal/handlers/dto"
+	"github.com/landly/backend/internal/handlers/mocks"
+	domain "github.com/landly/backend/internal/models"
+)
+
+func TestFormHandler_SubmitForm(t *testing.T) {
+	service := new(mocks.FormServiceMock)
+	handler := NewFormHandler(service)
+	projectID := uuid.New()
+
+	service.On("SubmitForm", mock.Anything, mock.MatchedBy(func(req *domain.FormSubmissionRequest) bool {
+		return req.ProjectID == projectID && req.Form == "lead" && req.Fields["email"] == "anna@example.com" &&
+			req.Path == "/" && req.Token == "1700000000.abc"
+	})).Return(nil).Once()
+	service.On("SubmitForm", mock.Anything, mock.MatchedBy(func(req *domain.FormSubmissionRequest) bool {
+		return req.Form == "missing"
+	})).Return(domain.ErrNotFound.WithMessage("form not found")).Once()
+
+	send := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/v1/forms/"+projectID.String()+"/submissions", strings.NewReader(body))
+		// Скрипт формы шлёт text/plain
+		req.Header.Set("Content-Type", "text/plain")
+		w := httptest.NewRecorder()
+		ctx := gin.CreateTestContextOnly(w, gin.New())
+		ctx.Request = req
+		ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}}
+		handler.SubmitForm(ctx)
+		return ctx.Writer.Status()
+	}
+
+	assert.Equal(t, http.StatusNoContent, send(`{"form":"lead","fields":{"email":"anna@example.com"},"path":"/","token":"1700000000.abc"}`))
+	assert.Equal(t, http.StatusNotFound, send(`{"form":"missing","fields":{}}`))
+	assert.Equal(t, http.StatusBadRequest, send(`not json`))
+	service.AssertExpectations(t)
+}
+
+func TestFormHandler_IssueFormToken(t *testing.T) {
+	service := new(mocks.FormServiceMock)
+	handler := NewFormHandler(service)
+	projectID := uuid.New()
+
+	service.On("IssueFormToken", projectID, "lead").Return("1700000000.abc").Once()
+
+	issue := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		ctx := gin.CreateTestContextOnly(w, gin.New())
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/v1/forms/"+projectID.String()+"/token"+query, nil)
+		ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}}
+		handler.IssueFormToken(ctx)
+		return w
+	}
+
+	w := issue("?form=lead")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var response dto.FormTokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "1700000000.abc", response.Token)
+
+	assert.Equal(t, http.StatusBadRequest, issue("").Code)
+	service.AssertExpectations(t)
+}
+
+func TestFormHandler_ListSubmissions(t *testing.T) {
+	service := new(mocks.FormServiceMock)
+	handler := NewFormHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	submission, err := domain.NewFormSubmission(projectID, "lead", map[string]string{"name": "Анна"}, "/", "")
+	require.NoError(t, err)
+	service.On("ListSubmissions", mock.Anything, userID.String(), projectID.String(), domain.FormSubmissionsQuery{Form: "lead", Limit: 10, Offset: 20}).
+		Return([]*domain.FormSubmission{submission}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/projects/"+projectID.String()+"/submissions?form=lead&limit=10&offset=20", nil)
+	w := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}}
+	ctx.Set("user_id", userID)
+	handler.ListSubmissions(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.FormSubmissionsListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Submissions, 1)
+	assert.Equal(t, map[string]string{"name": "Анна"}, response.Submissions[0].Fields)
+	service.AssertExpectations(t)
+}
+
+func TestFormHandler_ExportSubmissions(t *testing.T) {
+	service := new(mocks.FormServiceMock)
+	handler := NewFormHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	service.On("ExportSubmissions", mock.Anything, userID.String(), projectID.String(), "", mock.Anything).
+		Run(func(args mock.Arguments) {
+			_, _ = io.WriteString(args.Get(4).(io.Writer), "created_at,form,path,variant\n")
+		}).Return(nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/projects/"+projectID.String()+"/submissions/export", nil)
+	w := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}}
+	ctx.Set("user_id", userID)
+	handler.ExportSubmissions(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+	assert.Equal(t, "created_at,form,path,variant\n", w.Body.String())
+	service.AssertExpectations(t)
+}
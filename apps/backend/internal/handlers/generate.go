@@ -2,16 +2,21 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"path"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/landly/backend/internal/handlers/dto"
 	"github.com/landly/backend/internal/logger"
 	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/schema"
 	"go.uber.org/zap"
 )
 
@@ -22,7 +27,8 @@ type GenerateService interface {
 	GetGenerationResult(ctx context.Context, userID, sessionID string) (*domain.GenerationResult, error)
 	GetPreview(ctx context.Context, userID, projectID uuid.UUID) (map[string]interface{}, error)
 	GetChatHistory(ctx context.Context, userID, projectID string) (*domain.GenerationSession, []*domain.GenerationMessage, error)
-	SendChatMessage(ctx context.Context, userID, projectID, content string) (*domain.GenerationSession, []*domain.GenerationMessage, error)
+	SendChatMessage(ctx context.Context, userID, projectID, content string, target *domain.BlockTarget) (*domain.GenerationSession, []*domain.GenerationMessage, error)
+	StreamChatMessage(ctx context.Context, userID, projectID, content string, target *domain.BlockTarget, emit func(domain.ChatStreamEvent)) (*domain.GenerationSession, []*domain.GenerationMessage, error)
 }
 
 // PublishService интерфейс для сервиса публикации
@@ -31,7 +37,7 @@ type PublishService interface {
 	GetPublishStatus(ctx context.Context, userID, targetID string) (*domain.PublishTarget, error)
 	GetPublishedURL(ctx context.Context, userID, targetID string) (string, error)
 	UnpublishProject(ctx context.Context, userID, projectID uuid.UUID) error
-	ServePublished(ctx context.Context, subdomain, assetPath string) (io.ReadCloser, string, error)
+	ServePublished(ctx context.Context, subdomain, assetPath, variant string) (*domain.PublishedAsset, error)
 }
 
 type GenerateHandler struct {
@@ -81,8 +87,7 @@ func (h *GenerateHandler) Generate(c *gin.Context) {
 		Prompt:     req.Prompt,
 		PaymentURL: req.PaymentURL,
 	})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if respondWithDomainError(c, err) {
 		return
 	}
 
@@ -90,6 +95,7 @@ func (h *GenerateHandler) Generate(c *gin.Context) {
 		ID:        session.ID,
 		ProjectID: session.ProjectID,
 		Status:    string(session.Status),
+		JobID:     session.JobID,
 		CreatedAt: session.CreatedAt,
 	})
 }
@@ -185,7 +191,7 @@ func (h *GenerateHandler) SendChat(c *gin.Context) {
 		return
 	}
 
-	session, messages, err := h.generateService.SendChatMessage(c.Request.Context(), userID.String(), projectID.String(), req.Content)
+	session, messages, err := h.generateService.SendChatMessage(c.Request.Context(), userID.String(), projectID.String(), req.Content, toBlockTarget(req.Target))
 	if respondWithDomainError(c, err) {
 		return
 	}
@@ -196,6 +202,64 @@ func (h *GenerateHandler) SendChat(c *gin.Context) {
 	})
 }
 
+// StreamChat godoc
+// @Summary Send chat message and stream generation progress
+// @Description Server-Sent Events: message_accepted, token, block_parsed, schema_saved, assistant_message, затем done либо error
+// @Tags generate
+// @Accept json
+// @Produce text/event-stream
+// @Param id path string true "Project ID"
+// @Param request body dto.ChatMessageRequest true "Chat message"
+// @Success 200 {object} dto.ChatHistoryResponse "payload of the final done event"
+// @Router /v1/projects/{id}/chat/stream [post]
+// @Security BearerAuth
+func (h *GenerateHandler) StreamChat(c *gin.Context) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+
+	var req dto.ChatMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	// Генерация может длиться дольше server.http.write_timeout
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Status(http.StatusOK)
+
+	send := func(event string, payload interface{}) {
+		c.SSEvent(event, payload)
+		c.Writer.Flush()
+	}
+
+	session, messages, err := h.generateService.StreamChatMessage(c.Request.Context(), userID.String(), projectID.String(), req.Content, toBlockTarget(req.Target), func(event domain.ChatStreamEvent) {
+		send(event.Type, toChatStreamPayload(event))
+	})
+	if err != nil {
+		_, body := domainErrorBody(err)
+		send("error", body)
+		return
+	}
+
+	send("done", dto.ChatHistoryResponse{
+		Session:  toChatSessionResponse(session),
+		Messages: toChatMessagesResponse(messages),
+	})
+}
+
 // Publish godoc
 // @Summary Publish landing page
 // @Tags generate
@@ -242,6 +306,7 @@ func (h *GenerateHandler) Publish(c *gin.Context) {
 		Subdomain:   result.Subdomain,
 		PublicURL:   publicURL,
 		PublishedAt: publishedAt,
+		JobID:       result.JobID,
 	})
 }
 
@@ -273,17 +338,39 @@ func (h *GenerateHandler) Unpublish(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// Cookie с вариантом A/B-теста закрепляет вариант за посетителем
+const (
+	variantCookieName   = "landly_variant"
+	variantCookieMaxAge = 30 * 24 * 60 * 60
+)
+
 // ServePublished обрабатывает запросы на опубликованный лендинг
 func (h *GenerateHandler) ServePublished(c *gin.Context) {
 	slug := c.Param("slug")
-	asset := strings.TrimPrefix(c.Param("path"), "/")
-
 	if slug == "" {
 		c.Status(http.StatusNotFound)
 		return
 	}
 
-	reader, contentType, err := h.publishService.ServePublished(c.Request.Context(), slug, asset)
+	servePublishedSite(c, h.publishService, slug, c.Param("path"), "/sites/"+slug+"/")
+}
+
+// servePublishedSite отдаёт ресурс сайта. sitePath — адрес корня сайта, на нём живёт cookie варианта.
+func servePublishedSite(c *gin.Context, publishService PublishService, slug, assetPath, sitePath string) {
+	asset := strings.TrimPrefix(assetPath, "/")
+
+	// Относительные ссылки страниц (styles.css, ../about/) работают только от «каталога»
+	if !strings.HasSuffix(c.Request.URL.Path, "/") && path.Ext(asset) == "" {
+		target := c.Request.URL.Path + "/"
+		if c.Request.URL.RawQuery != "" {
+			target += "?" + c.Request.URL.RawQuery
+		}
+		c.Redirect(http.StatusMovedPermanently, target)
+		return
+	}
+
+	currentVariant, _ := c.Cookie(variantCookieName)
+	published, err := publishService.ServePublished(c.Request.Context(), slug, asset, currentVariant)
 	if err != nil {
 		if domainErr, ok := err.(*domain.Error); ok {
 			c.String(domainErr.HTTPStatus(), domainErr.Message)
@@ -292,13 +379,22 @@ func (h *GenerateHandler) ServePublished(c *gin.Context) {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
 	}
-	defer reader.Close()
+	defer published.Body.Close()
 
-	if contentType != "" {
-		c.Header("Content-Type", contentType)
+	if published.ContentType != "" {
+		c.Header("Content-Type", published.ContentType)
+	}
+	if published.Variant != "" {
+		// Разные посетители получают разные сборки по одному адресу: общий кэш их смешает
+		c.Header("Cache-Control", "private")
+		if published.Variant != currentVariant {
+			c.SetSameSite(http.SameSiteLaxMode)
+			c.SetCookie(variantCookieName, published.Variant, variantCookieMaxAge, sitePath, "", c.Request.TLS != nil, true)
+		}
 	}
 
-	if _, err := io.Copy(c.Writer, reader); err != nil {
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, published.Body); err != nil {
 		if errHandler := c.Error(err); errHandler != nil {
 			logger.WithContext(c.Request.Context()).Error("failed to write published asset", zap.Error(errHandler))
 		}
@@ -312,7 +408,7 @@ func (h *GenerateHandler) ServePublishedLegacy(c *gin.Context) {
 		return
 	}
 
-	reader, contentType, err := h.publishService.ServePublished(c.Request.Context(), slug, "")
+	published, err := h.publishService.ServePublished(c.Request.Context(), slug, "", "")
 	if err != nil {
 		if domainErr, ok := err.(*domain.Error); ok {
 			c.String(domainErr.HTTPStatus(), domainErr.Message)
@@ -321,17 +417,10 @@ func (h *GenerateHandler) ServePublishedLegacy(c *gin.Context) {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
 	}
-	defer reader.Close()
-
-	if contentType != "" {
-		c.Header("Content-Type", contentType)
-	}
+	published.Body.Close()
 
-	if _, err := io.Copy(c.Writer, reader); err != nil {
-		if errHandler := c.Error(err); errHandler != nil {
-			logger.WithContext(c.Request.Context()).Error("failed to write legacy published asset", zap.Error(errHandler))
-		}
-	}
+	// Страницы ссылаются на ресурсы относительно /sites/<slug>/, поэтому отдаём сайт оттуда
+	c.Redirect(http.StatusMovedPermanently, fmt.Sprintf("/sites/%s/", slug))
 }
 
 func isReservedSlug(slug string) bool {
@@ -352,13 +441,23 @@ func respondWithDomainError(c *gin.Context, err error) bool {
 		return false
 	}
 
-	if domainErr, ok := err.(*domain.Error); ok {
-		c.JSON(domainErr.HTTPStatus(), gin.H{"error": domainErr.Message})
-	} else {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	c.JSON(domainErrorBody(err))
+	return true
+}
+
+// domainErrorBody возвращает HTTP-статус и тело ответа для ошибки
+func domainErrorBody(err error) (int, gin.H) {
+	domainErr, ok := err.(*domain.Error)
+	if !ok {
+		return http.StatusInternalServerError, gin.H{"error": err.Error()}
 	}
 
-	return true
+	body := gin.H{"error": domainErr.Message}
+	var validationErrs schema.ValidationErrors
+	if errors.As(domainErr, &validationErrs) {
+		body["details"] = validationErrs
+	}
+	return domainErr.HTTPStatus(), body
 }
 
 func toChatSessionResponse(session *domain.GenerationSession) dto.ChatSessionResponse {
@@ -371,6 +470,9 @@ func toChatSessionResponse(session *domain.GenerationSession) dto.ChatSessionRes
 	response.ProjectID = session.ProjectID
 	response.Status = session.Status
 	response.SchemaJSON = session.SchemaJSON
+	if session.ErrorJSON != "" {
+		response.Errors = json.RawMessage(session.ErrorJSON)
+	}
 	response.CompletedAt = session.CompletedAt
 	response.CreatedAt = session.CreatedAt
 	response.UpdatedAt = session.UpdatedAt
@@ -378,6 +480,32 @@ func toChatSessionResponse(session *domain.GenerationSession) dto.ChatSessionRes
 	return response
 }
 
+func toBlockTarget(target *dto.ChatBlockTarget) *domain.BlockTarget {
+	if target == nil {
+		return nil
+	}
+	return &domain.BlockTarget{
+		PagePath:   target.PagePath,
+		BlockIndex: target.BlockIndex,
+		BlockType:  target.BlockType,
+	}
+}
+
+func toChatStreamPayload(event domain.ChatStreamEvent) interface{} {
+	switch event.Type {
+	case domain.ChatEventMessageAccepted, domain.ChatEventAssistantMessage:
+		return toChatMessagesResponse([]*domain.GenerationMessage{event.Message})[0]
+	case domain.ChatEventToken:
+		return dto.ChatStreamTokenResponse{Delta: event.Delta, Attempt: event.Attempt}
+	case domain.ChatEventBlockParsed:
+		return dto.ChatStreamBlockResponse{Index: event.BlockIndex, Attempt: event.Attempt, Block: event.Block}
+	case domain.ChatEventSchemaSaved:
+		return toChatSessionResponse(event.Session)
+	default:
+		return gin.H{}
+	}
+}
+
 func toChatMessagesResponse(messages []*domain.GenerationMessage) []dto.ChatMessageResponse {
 	if len(messages) == 0 {
 		return []dto.ChatMessageResponse{}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/landly/backend/internal/handlers/dto"
+	"github.com/landly/backend/internal/handlers/mocks"
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/schema"
+)
+
+func newStreamChatContext(t *testing.T, userID, projectID uuid.UUID, content string) (*gin.Context, *httptest.ResponseRecorder) {
+	t.Helper()
+
+	body, _ := json.Marshal(dto.ChatMessageRequest{Content: content})
+	req := httptest.NewRequest(http.MethodPost, "/v1/projects/"+projectID.String()+"/chat/stream", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}}
+	ctx.Set("user_id", userID)
+
+	return ctx, w
+}
+
+// sseEventNames возвращает имена событий из тела text/event-stream по порядку
+func sseEventNames(body string) []string {
+	var names []string
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "event:") {
+			names = append(names, strings.TrimSpace(strings.TrimPrefix(line, "event:")))
+		}
+	}
+	return names
+}
+
+func TestGenerateHandler_StreamChat_StreamsProgressEvents(t *testing.T) {
+	service := new(mocks.GenerateServiceMock)
+	handler := NewGenerateHandler(service, nil, "http://localhost")
+
+	userID := uuid.New()
+	projectID := uuid.New()
+	session := &domain.GenerationSession{ID: uuid.New(), ProjectID: projectID, Status: domain.GenerationStatusCompleted}
+	userMessage := &domain.GenerationMessage{ID: uuid.New(), Role: domain.MessageRoleUser, Content: "Добавь FAQ"}
+	assistantMessage := &domain.GenerationMessage{ID: uuid.New(), Role: domain.MessageRoleAssistant, Content: "Готово"}
+
+	service.On("StreamChatMessage", mock.Anything, userID.String(), projectID.String(), "Добавь FAQ", (*domain.BlockTarget)(nil)).Return(
+		session,
+		[]*domain.GenerationMessage{userMessage, assistantMessage},
+		nil,
+		[]domain.ChatStreamEvent{
+			{Type: domain.ChatEventMessageAccepted, Message: userMessage},
+			{Type: domain.ChatEventToken, Delta: `{"pages":[`},
+			{Type: domain.ChatEventBlockParsed, BlockIndex: 0, Block: json.RawMessage(`{"type":"faq","order":0,"props":{}}`)},
+			{Type: domain.ChatEventSchemaSaved, Session: session},
+			{Type: domain.ChatEventAssistantMessage, Message: assistantMessage},
+		},
+	)
+
+	ctx, w := newStreamChatContext(t, userID, projectID, "Добавь FAQ")
+	handler.StreamChat(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, []string{
+		domain.ChatEventMessageAccepted,
+		domain.ChatEventToken,
+		domain.ChatEventBlockParsed,
+		domain.ChatEventSchemaSaved,
+		domain.ChatEventAssistantMessage,
+		"done",
+	}, sseEventNames(w.Body.String()))
+	assert.Contains(t, w.Body.String(), `"block":{"type":"faq","order":0,"props":{}}`)
+	service.AssertExpectations(t)
+}
+
+func TestGenerateHandler_StreamChat_ReportsValidationErrors(t *testing.T) {
+	service := new(mocks.GenerateServiceMock)
+	handler := NewGenerateHandler(service, nil, "http://localhost")
+
+	userID := uuid.New()
+	projectID := uuid.New()
+	validationErrs := schema.ValidationErrors{{Path: "$.version", Message: "is required"}}
+
+	service.On("StreamChatMessage", mock.Anything, userID.String(), projectID.String(), "Сломай схему", (*domain.BlockTarget)(nil)).Return(
+		nil, nil, domain.ErrSchemaInvalid.WithError(validationErrs), []domain.ChatStreamEvent(nil),
+	)
+
+	ctx, w := newStreamChatContext(t, userID, projectID, "Сломай схему")
+	handler.StreamChat(ctx)
+
+	require.Equal(t, []string{"error"}, sseEventNames(w.Body.String()))
+	assert.Contains(t, w.Body.String(), `"details":[{"path":"$.version","message":"is required"}]`)
+}
+
+func TestGenerateHandler_Generate_MapsDomainErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "missing project", err: domain.ErrNotFound.WithMessage("project not found"), status: http.StatusNotFound},
+		{name: "foreign project", err: domain.ErrForbidden.WithMessage("access denied"), status: http.StatusForbidden},
+		{name: "invalid schema", err: domain.ErrSchemaInvalid, status: http.StatusUnprocessableEntity},
+		{name: "internal", err: domain.ErrInternal, status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := new(mocks.GenerateServiceMock)
+			handler := NewGenerateHandler(service, nil, "http://localhost")
+
+			userID := uuid.New()
+			projectID := uuid.New()
+			service.On("GenerateSite", mock.Anything, userID.String(), projectID.String(), mock.AnythingOfType("*domain.GenerateRequest")).
+				Return(nil, tt.err).Once()
+
+			body, _ := json.Marshal(dto.GenerateRequest{Prompt: "Лендинг"})
+			req := httptest.NewRequest(http.MethodPost, "/v1/projects/"+projectID.String()+"/generate", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			ctx := gin.CreateTestContextOnly(w, gin.New())
+			ctx.Request = req
+			ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}}
+			ctx.Set("user_id", userID)
+
+			handler.Generate(ctx)
+
+			assert.Equal(t, tt.status, w.Code)
+			service.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGenerateHandler_ServePublished_RedirectsPagesToTrailingSlash(t *testing.T) {
+	handler := NewGenerateHandler(new(mocks.GenerateServiceMock), nil, "http://localhost")
+
+	cases := map[string]string{
+		"/sites/demo":              "/sites/demo/",
+		"/sites/demo/about":        "/sites/demo/about/",
+		"/sites/demo/docs/api?x=1": "/sites/demo/docs/api/?x=1",
+	}
+	for requestPath, location := range cases {
+		t.Run(requestPath, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx := gin.CreateTestContextOnly(w, gin.New())
+			ctx.Request = httptest.NewRequest(http.MethodGet, requestPath, nil)
+			asset := strings.TrimPrefix(strings.SplitN(requestPath, "?", 2)[0], "/sites/demo")
+			ctx.Params = gin.Params{{Key: "slug", Value: "demo"}, {Key: "path", Value: asset}}
+
+			handler.ServePublished(ctx)
+
+			assert.Equal(t, http.StatusMovedPermanently, w.Code)
+			assert.Equal(t, location, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestGenerateHandler_ServePublished_SetsVariantCookie(t *testing.T) {
+	publishService := new(mocks.PublishServiceMock)
+	handler := NewGenerateHandler(new(mocks.GenerateServiceMock), publishService, "http://localhost")
+
+	publishService.On("ServePublished", mock.Anything, "demo", "", "").Return(&domain.PublishedAsset{
+		Body:        io.NopCloser(strings.NewReader("<html>B</html>")),
+		ContentType: "text/html",
+		Variant:     "b",
+	}, nil).Once()
+	publishService.On("ServePublished", mock.Anything, "demo", "styles.css", "b").Return(&domain.PublishedAsset{
+		Body:    io.NopCloser(strings.NewReader("body{}")),
+		Variant: "b",
+	}, nil).Once()
+
+	w := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/sites/demo/", nil)
+	ctx.Params = gin.Params{{Key: "slug", Value: "demo"}, {Key: "path", Value: "/"}}
+	handler.ServePublished(ctx)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "<html>B</html>", w.Body.String())
+	assert.Equal(t, "private", w.Header().Get("Cache-Control"))
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, variantCookieName, cookies[0].Name)
+	assert.Equal(t, "b", cookies[0].Value)
+	assert.Equal(t, "/sites/demo/", cookies[0].Path)
+	assert.True(t, cookies[0].HttpOnly)
+
+	// Посетитель с тем же вариантом cookie повторно не получает
+	w = httptest.NewRecorder()
+	ctx = gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/sites/demo/styles.css", nil)
+	ctx.Request.AddCookie(&http.Cookie{Name: variantCookieName, Value: "b"})
+	ctx.Params = gin.Params{{Key: "slug", Value: "demo"}, {Key: "path", Value: "/styles.css"}}
+	handler.ServePublished(ctx)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Result().Cookies())
+	publishService.AssertExpectations(t)
+}
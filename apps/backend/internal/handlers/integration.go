@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/landly/backend/internal/handlers/dto"
+	domain "github.com/landly/backend/internal/models"
+)
+
+// IntegrationService интерфейс сервиса платёжных интеграций
+type IntegrationService interface {
+	ListIntegrations(ctx context.Context, userID, projectID string) ([]*domain.Integration, error)
+	GetIntegration(ctx context.Context, userID, projectID, integrationID string) (*domain.Integration, error)
+	CreateIntegration(ctx context.Context, userID, projectID string, req *domain.IntegrationRequest) (*domain.Integration, error)
+	UpdateIntegration(ctx context.Context, userID, projectID, integrationID string, req *domain.IntegrationRequest) (*domain.Integration, error)
+	DeleteIntegration(ctx context.Context, userID, projectID, integrationID string) error
+}
+
+type IntegrationHandler struct {
+	integrationService IntegrationService
+}
+
+func NewIntegrationHandler(integrationService IntegrationService) *IntegrationHandler {
+	return &IntegrationHandler{
+		integrationService: integrationService,
+	}
+}
+
+// ListIntegrations godoc
+// @Summary List payment integrations of the project
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} dto.IntegrationsListResponse
+// @Router /v1/projects/{id}/integrations [get]
+// @Security BearerAuth
+func (h *IntegrationHandler) ListIntegrations(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	integrations, err := h.integrationService.ListIntegrations(c.Request.Context(), userID.String(), projectID.String())
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	response := dto.IntegrationsListResponse{Integrations: make([]dto.IntegrationResponse, 0, len(integrations))}
+	for _, integration := range integrations {
+		response.Integrations = append(response.Integrations, toIntegrationResponse(integration))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetIntegration godoc
+// @Summary Get a payment integration
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param integrationId path string true "Integration ID"
+// @Success 200 {object} dto.IntegrationResponse
+// @Router /v1/projects/{id}/integrations/{integrationId} [get]
+// @Security BearerAuth
+func (h *IntegrationHandler) GetIntegration(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	integration, err := h.integrationService.GetIntegration(c.Request.Context(), userID.String(), projectID.String(), c.Param("integrationId"))
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toIntegrationResponse(integration))
+}
+
+// CreateIntegration godoc
+// @Summary Connect a Stripe or PayPal payment link
+// @Description Pricing plans without their own URL link to it after the next publish.
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param request body dto.CreateIntegrationRequest true "Integration"
+// @Success 201 {object} dto.IntegrationResponse
+// @Router /v1/projects/{id}/integrations [post]
+// @Security BearerAuth
+func (h *IntegrationHandler) CreateIntegration(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateIntegrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	integration, err := h.integrationService.CreateIntegration(c.Request.Context(), userID.String(), projectID.String(), &domain.IntegrationRequest{
+		Type:          req.Type,
+		PaymentURL:    req.PaymentURL,
+		WebhookSecret: req.WebhookSecret,
+	})
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, toIntegrationResponse(integration))
+}
+
+// UpdateIntegration godoc
+// @Summary Change the payment link or webhook secret of an integration
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param integrationId path string true "Integration ID"
+// @Param request body dto.UpdateIntegrationRequest true "Integration"
+// @Success 200 {object} dto.IntegrationResponse
+// @Router /v1/projects/{id}/integrations/{integrationId} [put]
+// @Security BearerAuth
+func (h *IntegrationHandler) UpdateIntegration(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateIntegrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	integration, err := h.integrationService.UpdateIntegration(c.Request.Context(), userID.String(), projectID.String(), c.Param("integrationId"), &domain.IntegrationRequest{
+		PaymentURL:    req.PaymentURL,
+		WebhookSecret: req.WebhookSecret,
+	})
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toIntegrationResponse(integration))
+}
+
+// DeleteIntegration godoc
+// @Summary Disconnect a payment integration
+// @Tags integrations
+// @Param id path string true "Project ID"
+// @Param integrationId path string true "Integration ID"
+// @Success 204
+// @Router /v1/projects/{id}/integrations/{integrationId} [delete]
+// @Security BearerAuth
+func (h *IntegrationHandler) DeleteIntegration(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	err := h.integrationService.DeleteIntegration(c.Request.Context(), userID.String(), projectID.String(), c.Param("integrationId"))
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func toIntegrationResponse(integration *domain.Integration) dto.IntegrationResponse {
+	// Битый config не прячет интеграцию из списка: её можно исправить или удалить
+	config, _ := integration.ParseConfig()
+	return dto.IntegrationResponse{
+		ID:                integration.ID,
+		ProjectID:         integration.ProjectID,
+		Type:              integration.Type,
+		PaymentURL:        config.PaymentURL,
+		WebhookConfigured: config.WebhookSecret != "",
+		CreatedAt:         integration.CreatedAt,
+		UpdatedAt:         integration.UpdatedAt,
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/landly/backend/internal/handlers/dto"
+	"github.com/landly/backend/internal/handlers/mocks"
+	domain "github.com/landly/backend/internal/models"
+)
+
+func TestIntegrationHandler_CreateIntegration(t *testing.T) {
+	service := new(mocks.IntegrationServiceMock)
+	handler := NewIntegrationHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	integration := domain.NewIntegration(projectID, domain.IntegrationTypeStripe, `{"payment_url":"https://buy.stripe.com/shop"}`)
+	service.On("CreateIntegration", mock.Anything, userID.String(), projectID.String(), &domain.IntegrationRequest{
+		Type:       domain.IntegrationTypeStripe,
+		PaymentURL: "https://buy.stripe.com/shop",
+	}).Return(integration, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/projects/"+projectID.String()+"/integrations", strings.NewReader(`{"type":"stripe","payment_url":"https://buy.stripe.com/shop"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}}
+	ctx.Set("user_id", userID)
+	handler.CreateIntegration(ctx)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response dto.IntegrationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, integration.ID, response.ID)
+	assert.Equal(t, domain.IntegrationTypeStripe, response.Type)
+	assert.Equal(t, "https://buy.stripe.com/shop", response.PaymentURL)
+	service.AssertExpectations(t)
+}
+
+func TestIntegrationHandler_CreateIntegration_Duplicate(t *testing.T) {
+	service := new(mocks.IntegrationServiceMock)
+	handler := NewIntegrationHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	service.On("CreateIntegration", mock.Anything, userID.String(), projectID.String(), mock.Anything).
+		Return(nil, domain.ErrAlreadyExists.WithMessage("integration of this type already exists")).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/projects/"+projectID.String()+"/integrations", strings.NewReader(`{"type":"paypal","payment_url":"https://paypal.me/shop"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}}
+	ctx.Set("user_id", userID)
+	handler.CreateIntegration(ctx)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	service.AssertExpectations(t)
+}
+
+func TestIntegrationHandler_ListIntegrations(t *testing.T) {
+	service := new(mocks.IntegrationServiceMock)
+	handler := NewIntegrationHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	service.On("ListIntegrations", mock.Anything, userID.String(), projectID.String()).Return([]*domain.Integration{
+		domain.NewIntegration(projectID, domain.IntegrationTypePayPal, `{"payment_url":"https://paypal.me/shop"}`),
+	}, nil).Once()
+
+	w := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/v1/projects/"+projectID.String()+"/integrations", nil)
+	ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}}
+	ctx.Set("user_id", userID)
+	handler.ListIntegrations(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.IntegrationsListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Integrations, 1)
+	assert.Equal(t, "https://paypal.me/shop", response.Integrations[0].PaymentURL)
+}
+
+func TestIntegrationHandler_UpdateIntegration_HidesWebhookSecret(t *testing.T) {
+	service := new(mocks.IntegrationServiceMock)
+	handler := NewIntegrationHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	integration := domain.NewIntegration(projectID, domain.IntegrationTypeStripe, `{"payment_url":"https://buy.stripe.com/shop","webhook_secret":"whsec_123"}`)
+	service.On("UpdateIntegration", mock.Anything, userID.String(), projectID.String(), integration.ID.String(), &domain.IntegrationRequest{
+		WebhookSecret: "whsec_123",
+	}).Return(integration, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/projects/"+projectID.String()+"/integrations/"+integration.ID.String(), strings.NewReader(`{"webhook_secret":"whsec_123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}, {Key: "integrationId", Value: integration.ID.String()}}
+	ctx.Set("user_id", userID)
+	handler.UpdateIntegration(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "whsec_123")
+	var response dto.IntegrationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.WebhookConfigured)
+	service.AssertExpectations(t)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/landly/backend/internal/handlers/dto"
+	domain "github.com/landly/backend/internal/models"
+)
+
+// JobService интерфейс сервиса фоновых задач
+type JobService interface {
+	GetJob(ctx context.Context, userID, jobID string) (*domain.Job, error)
+	RetryJob(ctx context.Context, userID, jobID string) (*domain.Job, error)
+}
+
+type JobHandler struct {
+	jobService JobService
+}
+
+func NewJobHandler(jobService JobService) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+	}
+}
+
+// GetJob godoc
+// @Summary Get background job status
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} dto.JobResponse
+// @Router /v1/jobs/{id} [get]
+// @Security BearerAuth
+func (h *JobHandler) GetJob(c *gin.Context) {
+	userID, jobID, ok := h.jobParams(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.jobService.GetJob(c.Request.Context(), userID.String(), jobID.String())
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toJobResponse(job))
+}
+
+// RetryJob godoc
+// @Summary Requeue a job that exhausted its attempts
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} dto.JobResponse
+// @Router /v1/jobs/{id}/retry [post]
+// @Security BearerAuth
+func (h *JobHandler) RetryJob(c *gin.Context) {
+	userID, jobID, ok := h.jobParams(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.jobService.RetryJob(c.Request.Context(), userID.String(), jobID.String())
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toJobResponse(job))
+}
+
+func (h *JobHandler) jobParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, jobID, true
+}
+
+func toJobResponse(job *domain.Job) dto.JobResponse {
+	return dto.JobResponse{
+		ID:          job.ID,
+		Type:        job.Type,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		LastError:   job.LastError,
+		ProjectID:   job.ProjectID,
+		RunAt:       job.RunAt,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+		FinishedAt:  job.FinishedAt,
+	}
+}
@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -144,6 +146,53 @@ func defaultIfEmpty(values, fallback []string) []string {
 	return values
 }
 
+// RateLimitMiddleware ограничивает число запросов с одного IP: не больше limit за окно window.
+// Счётчики хранятся в памяти процесса, так что на нескольких инстансах предел действует на каждый.
+func RateLimitMiddleware(limit int, window time.Duration) gin.HandlerFunc {
+	type bucket struct {
+		start time.Time
+		count int
+	}
+	var (
+		mu      sync.Mutex
+		buckets = make(map[string]*bucket)
+		sweep   time.Time
+	)
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		ip := c.ClientIP()
+
+		mu.Lock()
+		// Истёкшие окна убираем не чаще раза за окно, чтобы карта не росла от разовых адресов
+		if now.Sub(sweep) >= window {
+			for key, b := range buckets {
+				if now.Sub(b.start) >= window {
+					delete(buckets, key)
+				}
+			}
+			sweep = now
+		}
+		b, ok := buckets[ip]
+		if !ok || now.Sub(b.start) >= window {
+			b = &bucket{start: now}
+			buckets[ip] = b
+		}
+		b.count++
+		allowed := b.count <= limit
+		retryAfter := b.start.Add(window).Sub(now)
+		mu.Unlock()
+
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many requests"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 // RequestIDMiddleware добавляет request ID для трейсинга
 func RequestIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -166,3 +215,20 @@ func GetUserID(c *gin.Context) (uuid.UUID, bool) {
 	id, ok := userID.(uuid.UUID)
 	return id, ok
 }
+
+// projectParams извлекает user_id и ID проекта из пути; при ошибке ответ уже отправлен
+func projectParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, projectID, true
+}
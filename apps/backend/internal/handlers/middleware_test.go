@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	engine := gin.New()
+	engine.POST("/submit", RateLimitMiddleware(2, time.Minute), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	send := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/submit", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusNoContent, send("203.0.113.1:1000").Code)
+	assert.Equal(t, http.StatusNoContent, send("203.0.113.1:1001").Code)
+	limited := send("203.0.113.1:1002")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.NotEmpty(t, limited.Header().Get("Retry-After"))
+
+	// Предел считается для каждого адреса отдельно
+	assert.Equal(t, http.StatusNoContent, send("203.0.113.2:1000").Code)
+}
@@ -0,0 +1,76 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type AnalyticsServiceMock struct {
+	mock.Mock
+}
+
+func (m *AnalyticsServiceMock) GetProjectAnalytics(ctx context.Context, userID, projectID string) (*domain.ProjectAnalytics, error) {
+	args := m.Called(ctx, userID, projectID)
+	analytics, _ := args.Get(0).(*domain.ProjectAnalytics)
+	return analytics, args.Error(1)
+}
+
+func (m *AnalyticsServiceMock) GetSiteAnalytics(ctx context.Context, userID, targetID string) (*domain.SiteAnalytics, error) {
+	args := m.Called(ctx, userID, targetID)
+	analytics, _ := args.Get(0).(*domain.SiteAnalytics)
+	return analytics, args.Error(1)
+}
+
+func (m *AnalyticsServiceMock) TrackEvent(ctx context.Context, req *domain.TrackEventRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *AnalyticsServiceMock) TrackEvents(ctx context.Context, reqs []*domain.TrackEventRequest) error {
+	args := m.Called(ctx, reqs)
+	return args.Error(0)
+}
+
+func (m *AnalyticsServiceMock) GetTimeSeries(ctx context.Context, userID, projectID string, q domain.AnalyticsTimeSeriesQuery) (*domain.AnalyticsTimeSeries, error) {
+	args := m.Called(ctx, userID, projectID, q)
+	series, _ := args.Get(0).(*domain.AnalyticsTimeSeries)
+	return series, args.Error(1)
+}
+
+func (m *AnalyticsServiceMock) GetBreakdown(ctx context.Context, userID, projectID string, q domain.AnalyticsBreakdownQuery) (*domain.AnalyticsBreakdown, error) {
+	args := m.Called(ctx, userID, projectID, q)
+	breakdown, _ := args.Get(0).(*domain.AnalyticsBreakdown)
+	return breakdown, args.Error(1)
+}
+
+func (m *AnalyticsServiceMock) GetFunnel(ctx context.Context, userID, projectID string, q domain.AnalyticsRangeQuery) (*domain.AnalyticsFunnel, error) {
+	args := m.Called(ctx, userID, projectID, q)
+	funnel, _ := args.Get(0).(*domain.AnalyticsFunnel)
+	return funnel, args.Error(1)
+}
+
+func (m *AnalyticsServiceMock) ListGoals(ctx context.Context, userID, projectID string) ([]*domain.AnalyticsGoal, error) {
+	args := m.Called(ctx, userID, projectID)
+	goals, _ := args.Get(0).([]*domain.AnalyticsGoal)
+	return goals, args.Error(1)
+}
+
+func (m *AnalyticsServiceMock) CreateGoal(ctx context.Context, userID, projectID string, req *domain.AnalyticsGoalRequest) (*domain.AnalyticsGoal, error) {
+	args := m.Called(ctx, userID, projectID, req)
+	goal, _ := args.Get(0).(*domain.AnalyticsGoal)
+	return goal, args.Error(1)
+}
+
+func (m *AnalyticsServiceMock) UpdateGoal(ctx context.Context, userID, projectID, goalID string, req *domain.AnalyticsGoalRequest) (*domain.AnalyticsGoal, error) {
+	args := m.Called(ctx, userID, projectID, goalID, req)
+	goal, _ := args.Get(0).(*domain.AnalyticsGoal)
+	return goal, args.Error(1)
+}
+
+func (m *AnalyticsServiceMock) DeleteGoal(ctx context.Context, userID, projectID, goalID string) error {
+	args := m.Called(ctx, userID, projectID, goalID)
+	return args.Error(0)
+}
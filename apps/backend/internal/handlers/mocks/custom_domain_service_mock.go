@@ -0,0 +1,41 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type CustomDomainServiceMock struct {
+	mock.Mock
+}
+
+func (m *CustomDomainServiceMock) GetDomain(ctx context.Context, userID, projectID string) (*domain.PublishTarget, error) {
+	args := m.Called(ctx, userID, projectID)
+	target, _ := args.Get(0).(*domain.PublishTarget)
+	return target, args.Error(1)
+}
+
+func (m *CustomDomainServiceMock) AttachDomain(ctx context.Context, userID, projectID, host string) (*domain.PublishTarget, error) {
+	args := m.Called(ctx, userID, projectID, host)
+	target, _ := args.Get(0).(*domain.PublishTarget)
+	return target, args.Error(1)
+}
+
+func (m *CustomDomainServiceMock) VerifyDomain(ctx context.Context, userID, projectID string) (*domain.PublishTarget, error) {
+	args := m.Called(ctx, userID, projectID)
+	target, _ := args.Get(0).(*domain.PublishTarget)
+	return target, args.Error(1)
+}
+
+func (m *CustomDomainServiceMock) DetachDomain(ctx context.Context, userID, projectID string) error {
+	args := m.Called(ctx, userID, projectID)
+	return args.Error(0)
+}
+
+func (m *CustomDomainServiceMock) ResolveHost(ctx context.Context, host string) (string, error) {
+	args := m.Called(ctx, host)
+	return args.String(0), args.Error(1)
+}
@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type ExperimentServiceMock struct {
+	mock.Mock
+}
+
+func (m *ExperimentServiceMock) ListVariants(ctx context.Context, userID, projectID string) ([]*domain.ProjectVariant, error) {
+	args := m.Called(ctx, userID, projectID)
+	variants, _ := args.Get(0).([]*domain.ProjectVariant)
+	return variants, args.Error(1)
+}
+
+func (m *ExperimentServiceMock) CreateVariant(ctx context.Context, userID, projectID string, req *domain.ProjectVariantRequest) (*domain.ProjectVariant, error) {
+	args := m.Called(ctx, userID, projectID, req)
+	variant, _ := args.Get(0).(*domain.ProjectVariant)
+	return variant, args.Error(1)
+}
+
+func (m *ExperimentServiceMock) UpdateVariant(ctx context.Context, userID, projectID, variantID string, req *domain.ProjectVariantRequest) (*domain.ProjectVariant, error) {
+	args := m.Called(ctx, userID, projectID, variantID, req)
+	variant, _ := args.Get(0).(*domain.ProjectVariant)
+	return variant, args.Error(1)
+}
+
+func (m *ExperimentServiceMock) DeleteVariant(ctx context.Context, userID, projectID, variantID string) error {
+	args := m.Called(ctx, userID, projectID, variantID)
+	return args.Error(0)
+}
+
+func (m *ExperimentServiceMock) PromoteVariant(ctx context.Context, userID, projectID, variantID string) (*domain.Project, error) {
+	args := m.Called(ctx, userID, projectID, variantID)
+	project, _ := args.Get(0).(*domain.Project)
+	return project, args.Error(1)
+}
+
+func (m *ExperimentServiceMock) GetResults(ctx context.Context, userID, projectID string, query *domain.ExperimentResultsQuery) (*domain.ExperimentResults, error) {
+	args := m.Called(ctx, userID, projectID, query)
+	results, _ := args.Get(0).(*domain.ExperimentResults)
+	return results, args.Error(1)
+}
@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"context"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type FormServiceMock struct {
+	mock.Mock
+}
+
+func (m *FormServiceMock) IssueFormToken(projectID uuid.UUID, formName string) string {
+	args := m.Called(projectID, formName)
+	return args.String(0)
+}
+
+func (m *FormServiceMock) SubmitForm(ctx context.Context, req *domain.FormSubmissionRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *FormServiceMock) ListSubmissions(ctx context.Context, userID, projectID string, query domain.FormSubmissionsQuery) ([]*domain.FormSubmission, error) {
+	args := m.Called(ctx, userID, projectID, query)
+	submissions, _ := args.Get(0).([]*domain.FormSubmission)
+	return submissions, args.Error(1)
+}
+
+func (m *FormServiceMock) ExportSubmissions(ctx context.Context, userID, projectID, formName string, w io.Writer) error {
+	args := m.Called(ctx, userID, projectID, formName, w)
+	return args.Error(0)
+}
@@ -0,0 +1,64 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type GenerateServiceMock struct {
+	mock.Mock
+}
+
+func (m *GenerateServiceMock) GenerateSite(ctx context.Context, userID, projectID string, req *domain.GenerateRequest) (*domain.GenerationSession, error) {
+	args := m.Called(ctx, userID, projectID, req)
+	session, _ := args.Get(0).(*domain.GenerationSession)
+	return session, args.Error(1)
+}
+
+func (m *GenerateServiceMock) GetGenerationStatus(ctx context.Context, userID, sessionID string) (*domain.GenerationSession, error) {
+	args := m.Called(ctx, userID, sessionID)
+	session, _ := args.Get(0).(*domain.GenerationSession)
+	return session, args.Error(1)
+}
+
+func (m *GenerateServiceMock) GetGenerationResult(ctx context.Context, userID, sessionID string) (*domain.GenerationResult, error) {
+	args := m.Called(ctx, userID, sessionID)
+	result, _ := args.Get(0).(*domain.GenerationResult)
+	return result, args.Error(1)
+}
+
+func (m *GenerateServiceMock) GetPreview(ctx context.Context, userID, projectID uuid.UUID) (map[string]interface{}, error) {
+	args := m.Called(ctx, userID, projectID)
+	preview, _ := args.Get(0).(map[string]interface{})
+	return preview, args.Error(1)
+}
+
+func (m *GenerateServiceMock) GetChatHistory(ctx context.Context, userID, projectID string) (*domain.GenerationSession, []*domain.GenerationMessage, error) {
+	args := m.Called(ctx, userID, projectID)
+	session, _ := args.Get(0).(*domain.GenerationSession)
+	messages, _ := args.Get(1).([]*domain.GenerationMessage)
+	return session, messages, args.Error(2)
+}
+
+func (m *GenerateServiceMock) SendChatMessage(ctx context.Context, userID, projectID, content string, target *domain.BlockTarget) (*domain.GenerationSession, []*domain.GenerationMessage, error) {
+	args := m.Called(ctx, userID, projectID, content, target)
+	session, _ := args.Get(0).(*domain.GenerationSession)
+	messages, _ := args.Get(1).([]*domain.GenerationMessage)
+	return session, messages, args.Error(2)
+}
+
+// StreamChatMessage передаёт в emit события из четвёртого возвращаемого значения ([]domain.ChatStreamEvent)
+func (m *GenerateServiceMock) StreamChatMessage(ctx context.Context, userID, projectID, content string, target *domain.BlockTarget, emit func(domain.ChatStreamEvent)) (*domain.GenerationSession, []*domain.GenerationMessage, error) {
+	args := m.Called(ctx, userID, projectID, content, target)
+	events, _ := args.Get(3).([]domain.ChatStreamEvent)
+	for _, event := range events {
+		emit(event)
+	}
+	session, _ := args.Get(0).(*domain.GenerationSession)
+	messages, _ := args.Get(1).([]*domain.GenerationMessage)
+	return session, messages, args.Error(2)
+}
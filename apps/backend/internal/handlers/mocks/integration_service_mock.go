@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type IntegrationServiceMock struct {
+	mock.Mock
+}
+
+func (m *IntegrationServiceMock) ListIntegrations(ctx context.Context, userID, projectID string) ([]*domain.Integration, error) {
+	args := m.Called(ctx, userID, projectID)
+	integrations, _ := args.Get(0).([]*domain.Integration)
+	return integrations, args.Error(1)
+}
+
+func (m *IntegrationServiceMock) GetIntegration(ctx context.Context, userID, projectID, integrationID string) (*domain.Integration, error) {
+	args := m.Called(ctx, userID, projectID, integrationID)
+	integration, _ := args.Get(0).(*domain.Integration)
+	return integration, args.Error(1)
+}
+
+func (m *IntegrationServiceMock) CreateIntegration(ctx context.Context, userID, projectID string, req *domain.IntegrationRequest) (*domain.Integration, error) {
+	args := m.Called(ctx, userID, projectID, req)
+	integration, _ := args.Get(0).(*domain.Integration)
+	return integration, args.Error(1)
+}
+
+func (m *IntegrationServiceMock) UpdateIntegration(ctx context.Context, userID, projectID, integrationID string, req *domain.IntegrationRequest) (*domain.Integration, error) {
+	args := m.Called(ctx, userID, projectID, integrationID, req)
+	integration, _ := args.Get(0).(*domain.Integration)
+	return integration, args.Error(1)
+}
+
+func (m *IntegrationServiceMock) DeleteIntegration(ctx context.Context, userID, projectID, integrationID string) error {
+	args := m.Called(ctx, userID, projectID, integrationID)
+	return args.Error(0)
+}
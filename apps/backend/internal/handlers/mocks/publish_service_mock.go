@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type PublishServiceMock struct {
+	mock.Mock
+}
+
+func (m *PublishServiceMock) PublishSite(ctx context.Context, userID, projectID string, req *domain.PublishRequest) (*domain.PublishTarget, error) {
+	args := m.Called(ctx, userID, projectID, req)
+	target, _ := args.Get(0).(*domain.PublishTarget)
+	return target, args.Error(1)
+}
+
+func (m *PublishServiceMock) GetPublishStatus(ctx context.Context, userID, targetID string) (*domain.PublishTarget, error) {
+	args := m.Called(ctx, userID, targetID)
+	target, _ := args.Get(0).(*domain.PublishTarget)
+	return target, args.Error(1)
+}
+
+func (m *PublishServiceMock) GetPublishedURL(ctx context.Context, userID, targetID string) (string, error) {
+	args := m.Called(ctx, userID, targetID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *PublishServiceMock) UnpublishProject(ctx context.Context, userID, projectID uuid.UUID) error {
+	args := m.Called(ctx, userID, projectID)
+	return args.Error(0)
+}
+
+func (m *PublishServiceMock) ServePublished(ctx context.Context, subdomain, assetPath, variant string) (*domain.PublishedAsset, error) {
+	args := m.Called(ctx, subdomain, assetPath, variant)
+	asset, _ := args.Get(0).(*domain.PublishedAsset)
+	return asset, args.Error(1)
+}
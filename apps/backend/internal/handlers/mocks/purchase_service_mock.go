@@ -0,0 +1,24 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type PurchaseServiceMock struct {
+	mock.Mock
+}
+
+func (m *PurchaseServiceMock) HandleStripeWebhook(ctx context.Context, projectID string, payload []byte, signature string) error {
+	args := m.Called(ctx, projectID, payload, signature)
+	return args.Error(0)
+}
+
+func (m *PurchaseServiceMock) ListPurchases(ctx context.Context, userID, projectID string) ([]*domain.Purchase, error) {
+	args := m.Called(ctx, userID, projectID)
+	purchases, _ := args.Get(0).([]*domain.Purchase)
+	return purchases, args.Error(1)
+}
@@ -0,0 +1,54 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type WebhookServiceMock struct {
+	mock.Mock
+}
+
+func (m *WebhookServiceMock) ListWebhooks(ctx context.Context, userID, projectID string) ([]*domain.Webhook, error) {
+	args := m.Called(ctx, userID, projectID)
+	webhooks, _ := args.Get(0).([]*domain.Webhook)
+	return webhooks, args.Error(1)
+}
+
+func (m *WebhookServiceMock) GetWebhook(ctx context.Context, userID, projectID, webhookID string) (*domain.Webhook, error) {
+	args := m.Called(ctx, userID, projectID, webhookID)
+	webhook, _ := args.Get(0).(*domain.Webhook)
+	return webhook, args.Error(1)
+}
+
+func (m *WebhookServiceMock) CreateWebhook(ctx context.Context, userID, projectID string, req *domain.WebhookRequest) (*domain.Webhook, error) {
+	args := m.Called(ctx, userID, projectID, req)
+	webhook, _ := args.Get(0).(*domain.Webhook)
+	return webhook, args.Error(1)
+}
+
+func (m *WebhookServiceMock) UpdateWebhook(ctx context.Context, userID, projectID, webhookID string, req *domain.WebhookRequest) (*domain.Webhook, error) {
+	args := m.Called(ctx, userID, projectID, webhookID, req)
+	webhook, _ := args.Get(0).(*domain.Webhook)
+	return webhook, args.Error(1)
+}
+
+func (m *WebhookServiceMock) DeleteWebhook(ctx context.Context, userID, projectID, webhookID string) error {
+	args := m.Called(ctx, userID, projectID, webhookID)
+	return args.Error(0)
+}
+
+func (m *WebhookServiceMock) ListDeliveries(ctx context.Context, userID, projectID, webhookID string, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, userID, projectID, webhookID, limit, offset)
+	deliveries, _ := args.Get(0).([]*domain.WebhookDelivery)
+	return deliveries, args.Error(1)
+}
+
+func (m *WebhookServiceMock) SendTestEvent(ctx context.Context, userID, projectID, webhookID string) (*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, userID, projectID, webhookID)
+	delivery, _ := args.Get(0).(*domain.WebhookDelivery)
+	return delivery, args.Error(1)
+}
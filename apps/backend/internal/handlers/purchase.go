@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/landly/backend/internal/handlers/dto"
+	domain "github.com/landly/backend/internal/models"
+)
+
+// maxStripeWebhookBodySize предел тела вебхука; события Checkout намного меньше
+const maxStripeWebhookBodySize = 1 << 20
+
+// PurchaseService интерфейс сервиса оплат
+type PurchaseService interface {
+	HandleStripeWebhook(ctx context.Context, projectID string, payload []byte, signature string) error
+	ListPurchases(ctx context.Context, userID, projectID string) ([]*domain.Purchase, error)
+}
+
+type PurchaseHandler struct {
+	purchaseService PurchaseService
+}
+
+func NewPurchaseHandler(purchaseService PurchaseService) *PurchaseHandler {
+	return &PurchaseHandler{
+		purchaseService: purchaseService,
+	}
+}
+
+// StripeWebhook godoc
+// @Summary Receive a Stripe webhook for the project
+// @Description Verifies Stripe-Signature with the webhook secret of the project's stripe integration
+// @Description and records completed Checkout sessions as purchases.
+// @Tags purchases
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param Stripe-Signature header string true "Stripe signature"
+// @Success 200
+// @Router /v1/webhooks/stripe/{id} [post]
+func (h *PurchaseHandler) StripeWebhook(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return
+	}
+
+	// Подпись считается по сырому телу, поэтому оно читается без разбора JSON
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxStripeWebhookBodySize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	err = h.purchaseService.HandleStripeWebhook(c.Request.Context(), projectID.String(), payload, c.GetHeader("Stripe-Signature"))
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": true})
+}
+
+// ListPurchases godoc
+// @Summary List recent purchases of the project
+// @Tags purchases
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} dto.PurchasesListResponse
+// @Router /v1/projects/{id}/purchases [get]
+// @Security BearerAuth
+func (h *PurchaseHandler) ListPurchases(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	purchases, err := h.purchaseService.ListPurchases(c.Request.Context(), userID.String(), projectID.String())
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	response := dto.PurchasesListResponse{Purchases: make([]dto.PurchaseResponse, 0, len(purchases))}
+	for _, purchase := range purchases {
+		response.Purchases = append(response.Purchases, dto.PurchaseResponse{
+			ID:         purchase.ID,
+			Provider:   purchase.Provider,
+			ExternalID: purchase.ExternalID,
+			Amount:     purchase.Amount,
+			Currency:   purchase.Currency,
+			Variant:    purchase.Variant,
+			CreatedAt:  purchase.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/landly/backend/internal/handlers/dto"
+	"github.com/landly/backend/internal/handlers/mocks"
+	domain "github.com/landly/backend/internal/models"
+)
+
+func TestPurchaseHandler_StripeWebhook(t *testing.T) {
+	service := new(mocks.PurchaseServiceMock)
+	handler := NewPurchaseHandler(service)
+	projectID := uuid.New()
+	body := `{"id":"evt_1","type":"checkout.session.completed"}`
+
+	service.On("HandleStripeWebhook", mock.Anything, projectID.String(), []byte(body), "t=1,v1=abc").Return(nil).Once()
+	service.On("HandleStripeWebhook", mock.Anything, projectID.String(), []byte(body), "t=1,v1=bad").
+		Return(domain.ErrBadRequest.WithMessage("signature mismatch")).Once()
+
+	send := func(signature string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/webhooks/stripe/"+projectID.String(), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Stripe-Signature", signature)
+		w := httptest.NewRecorder()
+		ctx := gin.CreateTestContextOnly(w, gin.New())
+		ctx.Request = req
+		ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}}
+		handler.StripeWebhook(ctx)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, send("t=1,v1=abc").Code)
+	assert.Equal(t, http.StatusBadRequest, send("t=1,v1=bad").Code)
+	service.AssertExpectations(t)
+}
+
+func TestPurchaseHandler_ListPurchases(t *testing.T) {
+	service := new(mocks.PurchaseServiceMock)
+	handler := NewPurchaseHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	purchase := domain.NewPurchase(projectID, domain.PurchaseProviderStripe, "cs_test_1", 4900, "USD", domain.VariantControl)
+	service.On("ListPurchases", mock.Anything, userID.String(), projectID.String()).Return([]*domain.Purchase{purchase}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/projects/"+projectID.String()+"/purchases", nil)
+	w := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = req
+	ctx.Params = gin.Params{{Key: "id", Value: projectID.String()}}
+	ctx.Set("user_id", userID)
+	handler.ListPurchases(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.PurchasesListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Purchases, 1)
+	assert.Equal(t, int64(4900), response.Purchases[0].Amount)
+	assert.Equal(t, domain.VariantControl, response.Purchases[0].Variant)
+	service.AssertExpectations(t)
+}
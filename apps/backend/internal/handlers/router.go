@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/landly/backend/internal/logger"
@@ -15,6 +16,15 @@ type Router struct {
 	generateHandler       *GenerateHandler
 	simpleGenerateHandler *SimpleGenerateHandler
 	analyticsHandler      *AnalyticsHandler
+	schemaRevisionHandler *SchemaRevisionHandler
+	deploymentHandler     *DeploymentHandler
+	jobHandler            *JobHandler
+	experimentHandler     *ExperimentHandler
+	domainHandler         *DomainHandler
+	integrationHandler    *IntegrationHandler
+	purchaseHandler       *PurchaseHandler
+	formHandler           *FormHandler
+	webhookHandler        *WebhookHandler
 	jwtSecret             string
 	allowedOrigins        []string
 	allowedMethods        []string
@@ -28,6 +38,15 @@ func NewRouter(
 	generateHandler *GenerateHandler,
 	simpleGenerateHandler *SimpleGenerateHandler,
 	analyticsHandler *AnalyticsHandler,
+	schemaRevisionHandler *SchemaRevisionHandler,
+	deploymentHandler *DeploymentHandler,
+	jobHandler *JobHandler,
+	experimentHandler *ExperimentHandler,
+	domainHandler *DomainHandler,
+	integrationHandler *IntegrationHandler,
+	purchaseHandler *PurchaseHandler,
+	formHandler *FormHandler,
+	webhookHandler *WebhookHandler,
 	jwtSecret string,
 	allowedOrigins []string,
 	allowedMethods []string,
@@ -41,6 +60,15 @@ func NewRouter(
 		generateHandler:       generateHandler,
 		simpleGenerateHandler: simpleGenerateHandler,
 		analyticsHandler:      analyticsHandler,
+		schemaRevisionHandler: schemaRevisionHandler,
+		deploymentHandler:     deploymentHandler,
+		jobHandler:            jobHandler,
+		experimentHandler:     experimentHandler,
+		domainHandler:         domainHandler,
+		integrationHandler:    integrationHandler,
+		purchaseHandler:       purchaseHandler,
+		formHandler:           formHandler,
+		webhookHandler:        webhookHandler,
 		jwtSecret:             jwtSecret,
 		allowedOrigins:        allowedOrigins,
 		allowedMethods:        allowedMethods,
@@ -93,8 +121,79 @@ func (r *Router) Setup() *gin.Engine {
 			projects.GET("/:id/preview", r.generateHandler.GetPreview)
 			projects.GET("/:id/chat", r.generateHandler.GetChat)
 			projects.POST("/:id/chat", r.generateHandler.SendChat)
+			projects.POST("/:id/chat/stream", r.generateHandler.StreamChat)
 			projects.POST("/:id/publish", r.generateHandler.Publish)
 			projects.DELETE("/:id/publish", r.generateHandler.Unpublish)
+
+			// Schema history
+			projects.PUT("/:id/schema", r.schemaRevisionHandler.UpdateSchema)
+			projects.POST("/:id/schema/undo", r.schemaRevisionHandler.UndoSchema)
+			projects.GET("/:id/revisions", r.schemaRevisionHandler.ListRevisions)
+			projects.GET("/:id/revisions/diff", r.schemaRevisionHandler.DiffRevisions)
+			projects.GET("/:id/revisions/:revisionId", r.schemaRevisionHandler.GetRevision)
+			projects.POST("/:id/revisions/:revisionId/restore", r.schemaRevisionHandler.RestoreRevision)
+
+			// Deployments
+			projects.GET("/:id/deployments", r.deploymentHandler.ListDeployments)
+			projects.POST("/:id/deployments/:deploymentId/rollback", r.deploymentHandler.RollbackDeployment)
+
+			// A/B-тесты
+			projects.GET("/:id/variants", r.experimentHandler.ListVariants)
+			projects.POST("/:id/variants", r.experimentHandler.CreateVariant)
+			projects.PUT("/:id/variants/:variantId", r.experimentHandler.UpdateVariant)
+			projects.DELETE("/:id/variants/:variantId", r.experimentHandler.DeleteVariant)
+			projects.POST("/:id/variants/:variantId/promote", r.experimentHandler.PromoteVariant)
+			projects.GET("/:id/experiment/results", r.experimentHandler.GetResults)
+
+			// Собственный домен
+			projects.GET("/:id/domain", r.domainHandler.GetDomain)
+			projects.PUT("/:id/domain", r.domainHandler.AttachDomain)
+			projects.POST("/:id/domain/verify", r.domainHandler.VerifyDomain)
+			projects.DELETE("/:id/domain", r.domainHandler.DetachDomain)
+
+			// Платёжные интеграции
+			projects.GET("/:id/integrations", r.integrationHandler.ListIntegrations)
+			projects.POST("/:id/integrations", r.integrationHandler.CreateIntegration)
+			projects.GET("/:id/integrations/:integrationId", r.integrationHandler.GetIntegration)
+			projects.PUT("/:id/integrations/:integrationId", r.integrationHandler.UpdateIntegration)
+			projects.DELETE("/:id/integrations/:integrationId", r.integrationHandler.DeleteIntegration)
+
+			// Оплаты из вебхуков платёжных систем
+			projects.GET("/:id/purchases", r.purchaseHandler.ListPurchases)
+
+			// Заявки из форм
+			projects.GET("/:id/submissions", r.formHandler.ListSubmissions)
+			projects.GET("/:id/submissions/export", r.formHandler.ExportSubmissions)
+
+			// Исходящие вебхуки о событиях проекта
+			projects.GET("/:id/webhooks", r.webhookHandler.ListWebhooks)
+			projects.POST("/:id/webhooks", r.webhookHandler.CreateWebhook)
+			projects.GET("/:id/webhooks/:webhookId", r.webhookHandler.GetWebhook)
+			projects.PUT("/:id/webhooks/:webhookId", r.webhookHandler.UpdateWebhook)
+			projects.DELETE("/:id/webhooks/:webhookId", r.webhookHandler.DeleteWebhook)
+			projects.GET("/:id/webhooks/:webhookId/deliveries", r.webhookHandler.ListDeliveries)
+			projects.POST("/:id/webhooks/:webhookId/test", r.webhookHandler.SendTestEvent)
+		}
+
+		// Приём заявок с опубликованных сайтов (публичный)
+		forms := v1.Group("/forms")
+		{
+			forms.GET("/:id/token", r.formHandler.IssueFormToken)
+			forms.POST("/:id/submissions", RateLimitMiddleware(formSubmissionsPerMinute, time.Minute), r.formHandler.SubmitForm)
+		}
+
+		// Вебхуки платёжных систем (публичные, проверяются подписью)
+		webhooks := v1.Group("/webhooks")
+		{
+			webhooks.POST("/stripe/:id", r.purchaseHandler.StripeWebhook)
+		}
+
+		// Background jobs
+		jobs := v1.Group("/jobs")
+		jobs.Use(AuthMiddleware(r.jwtSecret))
+		{
+			jobs.GET("/:id", r.jobHandler.GetJob)
+			jobs.POST("/:id/retry", r.jobHandler.RetryJob)
 		}
 
 		// Analytics
@@ -102,15 +201,30 @@ func (r *Router) Setup() *gin.Engine {
 		{
 			// Публичный эндпойнт для трекинга (с опубликованных сайтов)
 			analytics.POST("/:id/event", r.analyticsHandler.TrackEvent)
+			analytics.POST("/:id/events", r.analyticsHandler.TrackEvents)
 
 			// Приватный эндпойнт для получения статистики
 			analytics.GET("/:id/stats", AuthMiddleware(r.jwtSecret), r.analyticsHandler.GetStats)
+			analytics.GET("/:id/timeseries", AuthMiddleware(r.jwtSecret), r.analyticsHandler.GetTimeSeries)
+			analytics.GET("/:id/breakdown/:dimension", AuthMiddleware(r.jwtSecret), r.analyticsHandler.GetBreakdown)
+			analytics.GET("/:id/funnel", AuthMiddleware(r.jwtSecret), r.analyticsHandler.GetFunnel)
+
+			// Цели проекта для воронки
+			analytics.GET("/:id/goals", AuthMiddleware(r.jwtSecret), r.analyticsHandler.ListGoals)
+			analytics.POST("/:id/goals", AuthMiddleware(r.jwtSecret), r.analyticsHandler.CreateGoal)
+			analytics.PUT("/:id/goals/:goalId", AuthMiddleware(r.jwtSecret), r.analyticsHandler.UpdateGoal)
+			analytics.DELETE("/:id/goals/:goalId", AuthMiddleware(r.jwtSecret), r.analyticsHandler.DeleteGoal)
 		}
 	}
 
 	return r.engine
 }
 
+// Handler собирает маршруты и ставит перед ними раздачу сайтов с собственных доменов
+func (r *Router) Handler() http.Handler {
+	return r.domainHandler.HostRouting(r.Setup())
+}
+
 func (r *Router) healthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status": "ok",
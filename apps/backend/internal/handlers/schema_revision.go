@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/landly/backend/internal/handlers/dto"
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/schema"
+)
+
+// SchemaRevisionService интерфейс сервиса истории схемы
+type SchemaRevisionService interface {
+	ListRevisions(ctx context.Context, userID, projectID string, limit, offset int) ([]*domain.SchemaRevision, error)
+	GetRevision(ctx context.Context, userID, projectID, revisionID string) (*domain.SchemaRevision, error)
+	DiffRevisions(ctx context.Context, userID, projectID, fromID, toID string) ([]schema.Change, error)
+	RestoreRevision(ctx context.Context, userID, projectID, revisionID string) (*domain.SchemaRevision, error)
+	UndoSchema(ctx context.Context, userID, projectID string) (*domain.SchemaRevision, error)
+	UpdateSchema(ctx context.Context, userID, projectID, schemaJSON string) (*domain.SchemaRevision, error)
+}
+
+type SchemaRevisionHandler struct {
+	revisionService SchemaRevisionService
+}
+
+func NewSchemaRevisionHandler(revisionService SchemaRevisionService) *SchemaRevisionHandler {
+	return &SchemaRevisionHandler{
+		revisionService: revisionService,
+	}
+}
+
+// ListRevisions godoc
+// @Summary List schema revisions
+// @Tags revisions
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} dto.SchemaRevisionsListResponse
+// @Router /v1/projects/{id}/revisions [get]
+// @Security BearerAuth
+func (h *SchemaRevisionHandler) ListRevisions(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	revisions, err := h.revisionService.ListRevisions(c.Request.Context(), userID.String(), projectID.String(), limit, offset)
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	response := dto.SchemaRevisionsListResponse{Revisions: make([]dto.SchemaRevisionResponse, 0, len(revisions))}
+	for _, revision := range revisions {
+		response.Revisions = append(response.Revisions, toSchemaRevisionResponse(revision, false))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRevision godoc
+// @Summary Get schema revision with its schema
+// @Tags revisions
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param revisionId path string true "Revision ID"
+// @Success 200 {object} dto.SchemaRevisionResponse
+// @Router /v1/projects/{id}/revisions/{revisionId} [get]
+// @Security BearerAuth
+func (h *SchemaRevisionHandler) GetRevision(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	revision, err := h.revisionService.GetRevision(c.Request.Context(), userID.String(), projectID.String(), c.Param("revisionId"))
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toSchemaRevisionResponse(revision, true))
+}
+
+// DiffRevisions godoc
+// @Summary Structural JSON diff between two schema revisions
+// @Tags revisions
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param from query string true "Base revision ID"
+// @Param to query string true "Target revision ID"
+// @Success 200 {object} dto.SchemaDiffResponse
+// @Router /v1/projects/{id}/revisions/diff [get]
+// @Security BearerAuth
+func (h *SchemaRevisionHandler) DiffRevisions(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	fromID, err := uuid.Parse(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from revision id"})
+		return
+	}
+
+	toID, err := uuid.Parse(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to revision id"})
+		return
+	}
+
+	changes, err := h.revisionService.DiffRevisions(c.Request.Context(), userID.String(), projectID.String(), fromID.String(), toID.String())
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	response := dto.SchemaDiffResponse{From: fromID, To: toID, Changes: make([]dto.SchemaDiffChange, 0, len(changes))}
+	for _, change := range changes {
+		response.Changes = append(response.Changes, dto.SchemaDiffChange{
+			Op:       change.Op,
+			Path:     change.Path,
+			OldValue: change.OldValue,
+			NewValue: change.NewValue,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RestoreRevision godoc
+// @Summary Restore schema revision as the current schema
+// @Tags revisions
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param revisionId path string true "Revision ID"
+// @Success 200 {object} dto.SchemaRevisionResponse
+// @Router /v1/projects/{id}/revisions/{revisionId}/restore [post]
+// @Security BearerAuth
+func (h *SchemaRevisionHandler) RestoreRevision(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	revision, err := h.revisionService.RestoreRevision(c.Request.Context(), userID.String(), projectID.String(), c.Param("revisionId"))
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toSchemaRevisionResponse(revision, true))
+}
+
+// UndoSchema godoc
+// @Summary Undo the last schema change
+// @Description Repeated calls keep stepping further back through the history.
+// @Tags revisions
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} dto.SchemaRevisionResponse
+// @Router /v1/projects/{id}/schema/undo [post]
+// @Security BearerAuth
+func (h *SchemaRevisionHandler) UndoSchema(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	revision, err := h.revisionService.UndoSchema(c.Request.Context(), userID.String(), projectID.String())
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toSchemaRevisionResponse(revision, true))
+}
+
+// UpdateSchema godoc
+// @Summary Replace project schema with a manually edited one
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param request body dto.UpdateSchemaRequest true "Edited schema"
+// @Success 200 {object} dto.SchemaRevisionResponse
+// @Failure 422 {object} map[string]interface{} "schema validation errors in details"
+// @Router /v1/projects/{id}/schema [put]
+// @Security BearerAuth
+func (h *SchemaRevisionHandler) UpdateSchema(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateSchemaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revision, err := h.revisionService.UpdateSchema(c.Request.Context(), userID.String(), projectID.String(), string(req.Schema))
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toSchemaRevisionResponse(revision, true))
+}
+
+func toSchemaRevisionResponse(revision *domain.SchemaRevision, withSchema bool) dto.SchemaRevisionResponse {
+	response := dto.SchemaRevisionResponse{
+		ID:           revision.ID,
+		Version:      revision.Version,
+		Source:       revision.Source,
+		AuthorID:     revision.AuthorID,
+		MessageID:    revision.MessageID,
+		RestoredFrom: revision.RestoredFrom,
+		CreatedAt:    revision.CreatedAt,
+	}
+	if withSchema && json.Valid([]byte(revision.SchemaJSON)) {
+		response.Schema = json.RawMessage(revision.SchemaJSON)
+	}
+	return response
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/landly/backend/internal/handlers/dto"
+	domain "github.com/landly/backend/internal/models"
+)
+
+// WebhookService интерфейс сервиса исходящих вебхуков
+type WebhookService interface {
+	ListWebhooks(ctx context.Context, userID, projectID string) ([]*domain.Webhook, error)
+	GetWebhook(ctx context.Context, userID, projectID, webhookID string) (*domain.Webhook, error)
+	CreateWebhook(ctx context.Context, userID, projectID string, req *domain.WebhookRequest) (*domain.Webhook, error)
+	UpdateWebhook(ctx context.Context, userID, projectID, webhookID string, req *domain.WebhookRequest) (*domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID, projectID, webhookID string) error
+	ListDeliveries(ctx context.Context, userID, projectID, webhookID string, limit, offset int) ([]*domain.WebhookDelivery, error)
+	SendTestEvent(ctx context.Context, userID, projectID, webhookID string) (*domain.WebhookDelivery, error)
+}
+
+type WebhookHandler struct {
+	webhookService WebhookService
+}
+
+func NewWebhookHandler(webhookService WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// ListWebhooks godoc
+// @Summary List webhook subscriptions of the project
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} dto.WebhooksListResponse
+// @Router /v1/projects/{id}/webhooks [get]
+// @Security BearerAuth
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	webhooks, err := h.webhookService.ListWebhooks(c.Request.Context(), userID.String(), projectID.String())
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	response := dto.WebhooksListResponse{Webhooks: make([]dto.WebhookResponse, 0, len(webhooks))}
+	for _, webhook := range webhooks {
+		response.Webhooks = append(response.Webhooks, toWebhookResponse(webhook, false))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetWebhook godoc
+// @Summary Get a webhook subscription
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param webhookId path string true "Webhook ID"
+// @Success 200 {object} dto.WebhookResponse
+// @Router /v1/projects/{id}/webhooks/{webhookId} [get]
+// @Security BearerAuth
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.webhookService.GetWebhook(c.Request.Context(), userID.String(), projectID.String(), c.Param("webhookId"))
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toWebhookResponse(webhook, false))
+}
+
+// CreateWebhook godoc
+// @Summary Subscribe a URL to project events
+// @Description Events are POSTed as JSON signed with the subscription secret in X-Landly-Signature. The secret is returned only here and when it is rotated.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param request body dto.CreateWebhookRequest true "Webhook"
+// @Success 201 {object} dto.WebhookResponse
+// @Router /v1/projects/{id}/webhooks [post]
+// @Security BearerAuth
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(c.Request.Context(), userID.String(), projectID.String(), &domain.WebhookRequest{
+		URL:    req.URL,
+		Events: req.Events,
+		Active: req.Active,
+	})
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, toWebhookResponse(webhook, true))
+}
+
+// UpdateWebhook godoc
+// @Summary Change the URL, events or state of a webhook, or rotate its secret
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param webhookId path string true "Webhook ID"
+// @Param request body dto.UpdateWebhookRequest true "Webhook"
+// @Success 200 {object} dto.WebhookResponse
+// @Router /v1/projects/{id}/webhooks/{webhookId} [put]
+// @Security BearerAuth
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := h.webhookService.UpdateWebhook(c.Request.Context(), userID.String(), projectID.String(), c.Param("webhookId"), &domain.WebhookRequest{
+		URL:          req.URL,
+		Events:       req.Events,
+		Active:       req.Active,
+		RotateSecret: req.RotateSecret,
+	})
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toWebhookResponse(webhook, req.RotateSecret))
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook subscription
+// @Tags webhooks
+// @Param id path string true "Project ID"
+// @Param webhookId path string true "Webhook ID"
+// @Success 204
+// @Router /v1/projects/{id}/webhooks/{webhookId} [delete]
+// @Security BearerAuth
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	err := h.webhookService.DeleteWebhook(c.Request.Context(), userID.String(), projectID.String(), c.Param("webhookId"))
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries godoc
+// @Summary List delivery attempts of a webhook
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param webhookId path string true "Webhook ID"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} dto.WebhookDeliveriesListResponse
+// @Router /v1/projects/{id}/webhooks/{webhookId}/deliveries [get]
+// @Security BearerAuth
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	deliveries, err := h.webhookService.ListDeliveries(c.Request.Context(), userID.String(), projectID.String(), c.Param("webhookId"), limit, offset)
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	response := dto.WebhookDeliveriesListResponse{Deliveries: make([]dto.WebhookDeliveryResponse, 0, len(deliveries))}
+	for _, delivery := range deliveries {
+		response.Deliveries = append(response.Deliveries, toWebhookDeliveryResponse(delivery))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SendTestEvent godoc
+// @Summary Send a webhook.test event to the webhook
+// @Description Delivers once, without retries, and returns the recorded attempt.
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param webhookId path string true "Webhook ID"
+// @Success 200 {object} dto.WebhookDeliveryResponse
+// @Router /v1/projects/{id}/webhooks/{webhookId}/test [post]
+// @Security BearerAuth
+func (h *WebhookHandler) SendTestEvent(c *gin.Context) {
+	userID, projectID, ok := projectParams(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.SendTestEvent(c.Request.Context(), userID.String(), projectID.String(), c.Param("webhookId"))
+	if respondWithDomainError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, toWebhookDeliveryResponse(delivery))
+}
+
+// toWebhookResponse отдаёт секрет целиком только сразу после его выдачи; в остальных ответах — подсказка
+func toWebhookResponse(webhook *domain.Webhook, revealSecret bool) dto.WebhookResponse {
+	response := dto.WebhookResponse{
+		ID:         webhook.ID,
+		ProjectID:  webhook.ProjectID,
+		URL:        webhook.URL,
+		SecretHint: webhookSecretHint(webhook.Secret),
+		Events:     webhook.Events,
+		Active:     webhook.Active,
+		CreatedAt:  webhook.CreatedAt,
+		UpdatedAt:  webhook.UpdatedAt,
+	}
+	if revealSecret {
+		response.Secret = webhook.Secret
+	}
+	return response
+}
+
+// webhookSecretHint маскирует секрет, оставляя последние 4 символа: whsec_…a1b2
+func webhookSecretHint(secret string) string {
+	const visible = 4
+	if len(secret) <= visible {
+		return "…"
+	}
+	prefix := ""
+	if strings.HasPrefix(secret, "whsec_") {
+		prefix = "whsec_"
+	}
+	return prefix + "…" + secret[len(secret)-visible:]
+}
+
+func toWebhookDeliveryResponse(delivery *domain.WebhookDelivery) dto.WebhookDeliveryResponse {
+	return dto.WebhookDeliveryResponse{
+		ID:         delivery.ID,
+		EventID:    delivery.EventID,
+		Event:      delivery.Event,
+		Attempt:    delivery.Attempt,
+		StatusCode: delivery.StatusCode,
+		Success:    delivery.Success,
+		Error:      delivery.Error,
+		DurationMs: delivery.DurationMs,
+		Payload:    delivery.Payload,
+		CreatedAt:  delivery.CreatedAt,
+	}
+}
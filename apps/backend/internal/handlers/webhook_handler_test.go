@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/landly/backend/internal/handlers/dto"
+	"github.com/landly/backend/internal/handlers/mocks"
+	domain "github.com/landly/backend/internal/models"
+)
+
+func newWebhookTestContext(method, target, body string, userID uuid.UUID, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(w, gin.New())
+	ctx.Request = req
+	ctx.Params = params
+	ctx.Set("user_id", userID)
+	return ctx, w
+}
+
+func TestWebhookHandler_CreateWebhook(t *testing.T) {
+	service := new(mocks.WebhookServiceMock)
+	handler := NewWebhookHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+	params := gin.Params{{Key: "id", Value: projectID.String()}}
+
+	webhook := domain.NewWebhook(projectID, "https://crm.example.com/hook", "whsec_test", []string{domain.WebhookEventLeadSubmitted})
+	service.On("CreateWebhook", mock.Anything, userID.String(), projectID.String(), mock.MatchedBy(func(req *domain.WebhookRequest) bool {
+		return req.URL == "https://crm.example.com/hook" && len(req.Events) == 1 && req.Active == nil
+	})).Return(webhook, nil).Once()
+
+	ctx, w := newWebhookTestContext(http.MethodPost, "/v1/projects/"+projectID.String()+"/webhooks",
+		`{"url":"https://crm.example.com/hook","events":["lead.submitted"]}`, userID, params)
+	handler.CreateWebhook(ctx)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response dto.WebhookResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, webhook.ID, response.ID)
+	assert.Equal(t, "whsec_test", response.Secret)
+	assert.Equal(t, []string{domain.WebhookEventLeadSubmitted}, response.Events)
+
+	ctx, w = newWebhookTestContext(http.MethodPost, "/v1/projects/"+projectID.String()+"/webhooks", `{"url":"https://crm.example.com/hook"}`, userID, params)
+	handler.CreateWebhook(ctx)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	service.AssertExpectations(t)
+}
+
+func TestWebhookHandler_ListWebhooks_HidesSecrets(t *testing.T) {
+	service := new(mocks.WebhookServiceMock)
+	handler := NewWebhookHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	webhook := domain.NewWebhook(projectID, "https://crm.example.com/hook", "whsec_0123456789abcdef", []string{domain.WebhookEventLeadSubmitted})
+	service.On("ListWebhooks", mock.Anything, userID.String(), projectID.String()).Return([]*domain.Webhook{webhook}, nil).Once()
+
+	ctx, w := newWebhookTestContext(http.MethodGet, "/", "", userID, gin.Params{{Key: "id", Value: projectID.String()}})
+	handler.ListWebhooks(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), webhook.Secret)
+	var response dto.WebhooksListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Webhooks, 1)
+	assert.Empty(t, response.Webhooks[0].Secret)
+	assert.Equal(t, "whsec_…cdef", response.Webhooks[0].SecretHint)
+	service.AssertExpectations(t)
+}
+
+func TestWebhookHandler_UpdateWebhook(t *testing.T) {
+	service := new(mocks.WebhookServiceMock)
+	handler := NewWebhookHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+	webhook := domain.NewWebhook(projectID, "https://crm.example.com/hook", "whsec_new", []string{domain.WebhookEventLeadSubmitted})
+	params := gin.Params{{Key: "id", Value: projectID.String()}, {Key: "webhookId", Value: webhook.ID.String()}}
+
+	service.On("UpdateWebhook", mock.Anything, userID.String(), projectID.String(), webhook.ID.String(), mock.MatchedBy(func(req *domain.WebhookRequest) bool {
+		return req.RotateSecret && req.Active != nil && !*req.Active
+	})).Return(webhook, nil).Once()
+
+	service.On("UpdateWebhook", mock.Anything, userID.String(), projectID.String(), webhook.ID.String(), mock.MatchedBy(func(req *domain.WebhookRequest) bool {
+		return !req.RotateSecret
+	})).Return(webhook, nil).Once()
+
+	ctx, w := newWebhookTestContext(http.MethodPut, "/", `{"active":false,"rotate_secret":true}`, userID, params)
+	handler.UpdateWebhook(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.WebhookResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "whsec_new", response.Secret, "a rotated secret is shown once")
+
+	// Без смены секрета он не возвращается
+	ctx, w = newWebhookTestContext(http.MethodPut, "/", `{"active":true}`, userID, params)
+	handler.UpdateWebhook(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "whsec_new")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "whsec_…_new", response.SecretHint)
+	service.AssertExpectations(t)
+}
+
+func TestWebhookHandler_DeleteWebhook(t *testing.T) {
+	service := new(mocks.WebhookServiceMock)
+	handler := NewWebhookHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+	webhookID := uuid.New()
+	params := gin.Params{{Key: "id", Value: projectID.String()}, {Key: "webhookId", Value: webhookID.String()}}
+
+	service.On("DeleteWebhook", mock.Anything, userID.String(), projectID.String(), webhookID.String()).Return(nil).Once()
+	ctx, _ := newWebhookTestContext(http.MethodDelete, "/", "", userID, params)
+	handler.DeleteWebhook(ctx)
+	assert.Equal(t, http.StatusNoContent, ctx.Writer.Status())
+
+	service.On("DeleteWebhook", mock.Anything, userID.String(), projectID.String(), "missing").Return(domain.ErrNotFound.WithMessage("webhook not found")).Once()
+	ctx, w := newWebhookTestContext(http.MethodDelete, "/", "", userID, gin.Params{{Key: "id", Value: projectID.String()}, {Key: "webhookId", Value: "missing"}})
+	handler.DeleteWebhook(ctx)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	ctx, w = newWebhookTestContext(http.MethodDelete, "/", "", userID, gin.Params{{Key: "id", Value: "not-a-uuid"}})
+	handler.DeleteWebhook(ctx)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	service.AssertExpectations(t)
+}
+
+func TestWebhookHandler_ListDeliveries(t *testing.T) {
+	service := new(mocks.WebhookServiceMock)
+	handler := NewWebhookHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+	webhookID := uuid.New()
+	params := gin.Params{{Key: "id", Value: projectID.String()}, {Key: "webhookId", Value: webhookID.String()}}
+
+	delivery := &domain.WebhookDelivery{
+		ID:         uuid.New(),
+		WebhookID:  webhookID,
+		EventID:    uuid.New(),
+		Event:      domain.WebhookEventSitePublished,
+		Attempt:    3,
+		StatusCode: http.StatusServiceUnavailable,
+		Error:      "unexpected status 503: maintenance",
+	}
+	service.On("ListDeliveries", mock.Anything, userID.String(), projectID.String(), webhookID.String(), 20, 40).
+		Return([]*domain.WebhookDelivery{delivery}, nil).Once()
+
+	ctx, w := newWebhookTestContext(http.MethodGet, "/deliveries?limit=20&offset=40", "", userID, params)
+	handler.ListDeliveries(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.WebhookDeliveriesListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Deliveries, 1)
+	assert.Equal(t, 3, response.Deliveries[0].Attempt)
+	assert.False(t, response.Deliveries[0].Success)
+	assert.Equal(t, "unexpected status 503: maintenance", response.Deliveries[0].Error)
+	service.AssertExpectations(t)
+}
+
+func TestWebhookHandler_SendTestEvent(t *testing.T) {
+	service := new(mocks.WebhookServiceMock)
+	handler := NewWebhookHandler(service)
+	userID := uuid.New()
+	projectID := uuid.New()
+	webhookID := uuid.New()
+	params := gin.Params{{Key: "id", Value: projectID.String()}, {Key: "webhookId", Value: webhookID.String()}}
+
+	delivery := &domain.WebhookDelivery{ID: uuid.New(), WebhookID: webhookID, Event: domain.WebhookEventTest, Attempt: 1, StatusCode: http.StatusOK, Success: true}
+	service.On("SendTestEvent", mock.Anything, userID.String(), projectID.String(), webhookID.String()).Return(delivery, nil).Once()
+
+	ctx, w := newWebhookTestContext(http.MethodPost, "/test", "", userID, params)
+	handler.SendTestEvent(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.WebhookDeliveryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Success)
+	assert.Equal(t, domain.WebhookEventTest, response.Event)
+	service.AssertExpectations(t)
+}
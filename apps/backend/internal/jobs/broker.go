@@ -0,0 +1,151 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/landly/backend/internal/logger"
+	domain "github.com/landly/backend/internal/models"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Варианты jobs.backend в конфигурации
+const (
+	BackendAuto     = "auto"
+	BackendRedis    = "redis"
+	BackendDatabase = "database"
+)
+
+// BrokerConfig выбор брокера: auto — Redis, а если он не настроен или не отвечает, таблица jobs
+type BrokerConfig struct {
+	Backend string
+	Redis   *redis.Options
+}
+
+// NewBroker создаёт брокер по конфигурации
+func NewBroker(ctx context.Context, cfg BrokerConfig, repo domain.JobRepository) (Broker, error) {
+	log := logger.WithContext(ctx)
+
+	switch cfg.Backend {
+	case BackendDatabase:
+		log.Info("job queue uses database broker")
+		return NewDatabaseBroker(repo), nil
+	case BackendRedis, BackendAuto, "":
+	default:
+		return nil, fmt.Errorf("unknown job queue backend: %s", cfg.Backend)
+	}
+
+	if cfg.Redis == nil || cfg.Redis.Addr == "" {
+		if cfg.Backend == BackendRedis {
+			return nil, errors.New("job queue backend redis requires database.redis.addr")
+		}
+		log.Info("redis is not configured, job queue uses database broker")
+		return NewDatabaseBroker(repo), nil
+	}
+
+	client := redis.NewClient(cfg.Redis)
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := client.Ping(pingCtx).Err(); err != nil {
+		client.Close()
+		if cfg.Backend == BackendRedis {
+			return nil, fmt.Errorf("failed to connect to redis: %w", err)
+		}
+		log.Warn("redis is unavailable, job queue uses database broker", zap.Error(err))
+		return NewDatabaseBroker(repo), nil
+	}
+
+	log.Info("job queue uses redis broker", zap.String("addr", cfg.Redis.Addr))
+	return NewRedisBroker(client, DefaultRedisKey), nil
+}
+
+// DefaultRedisKey ключ sorted set с ID задач; score — время запуска в миллисекундах
+const DefaultRedisKey = "landly:jobs"
+
+// RedisBroker брокер на sorted set Redis: отложенные задачи (backoff) просто имеют score в будущем
+type RedisBroker struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisBroker создаёт брокер на Redis
+func NewRedisBroker(client *redis.Client, key string) *RedisBroker {
+	if key == "" {
+		key = DefaultRedisKey
+	}
+	return &RedisBroker{client: client, key: key}
+}
+
+// Push добавляет задачу; повторный Push той же задачи только обновляет время запуска
+func (b *RedisBroker) Push(ctx context.Context, job *domain.Job) error {
+	return b.client.ZAdd(ctx, b.key, redis.Z{
+		Score:  float64(job.RunAt.UnixMilli()),
+		Member: job.ID.String(),
+	}).Err()
+}
+
+// Pop забирает самую раннюю готовую задачу. ZREM гарантирует, что её получит только один воркер.
+func (b *RedisBroker) Pop(ctx context.Context) (uuid.UUID, bool, error) {
+	ids, err := b.client.ZRangeByScore(ctx, b.key, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(time.Now().UnixMilli(), 10),
+		Count: 1,
+	}).Result()
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+	if len(ids) == 0 {
+		return uuid.Nil, false, nil
+	}
+
+	removed, err := b.client.ZRem(ctx, b.key, ids[0]).Result()
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+	if removed == 0 {
+		return uuid.Nil, false, nil
+	}
+
+	id, err := uuid.Parse(ids[0])
+	if err != nil {
+		return uuid.Nil, false, nil
+	}
+	return id, true, nil
+}
+
+// DatabaseBroker брокер на таблице jobs, когда Redis не настроен или недоступен
+type DatabaseBroker struct {
+	repo domain.JobRepository
+}
+
+// NewDatabaseBroker создаёт брокер на таблице jobs
+func NewDatabaseBroker(repo domain.JobRepository) *DatabaseBroker {
+	return &DatabaseBroker{repo: repo}
+}
+
+// Push ничего не делает: задача уже лежит в таблице со своим run_at
+func (b *DatabaseBroker) Push(ctx context.Context, job *domain.Job) error {
+	return nil
+}
+
+// Pop возвращает самую раннюю готовую задачу; эксклюзивность обеспечивает JobRepository.Claim
+func (b *DatabaseBroker) Pop(ctx context.Context) (uuid.UUID, bool, error) {
+	id, err := b.repo.NextQueued(ctx, time.Now())
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return uuid.Nil, false, nil
+		}
+		return uuid.Nil, false, err
+	}
+	return id, true, nil
+}
+
+var (
+	_ Broker = (*RedisBroker)(nil)
+	_ Broker = (*DatabaseBroker)(nil)
+)
@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/landly/backend/internal/logger"
+	"go.uber.org/zap"
+)
+
+// RunPeriodic выполняет task сразу и затем каждые interval, пока не отменён ctx.
+// Ошибки только логируются: следующий запуск повторит работу.
+func RunPeriodic(ctx context.Context, name string, interval time.Duration, task func(ctx context.Context) error) {
+	log := logger.WithContext(ctx).With(zap.String("task", name))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := task(ctx); err != nil && ctx.Err() == nil {
+			log.Error("periodic task failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
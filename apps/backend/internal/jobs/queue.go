@@ -0,0 +1,99 @@
+// Package jobs очередь фоновых задач (генерация, публикация, доставка вебхуков) и воркер для их выполнения.
+//
+// Состояние задач хранится в таблице jobs. Брокер только раздаёт воркерам ID
+// готовых задач: Redis, если он настроен, или сама таблица как запасной вариант.
+// Потерянные брокером задачи возвращаются в очередь при восстановлении (см. Worker).
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/landly/backend/internal/logger"
+	domain "github.com/landly/backend/internal/models"
+	"go.uber.org/zap"
+)
+
+// DefaultMaxAttempts число попыток выполнения задачи по умолчанию
+const DefaultMaxAttempts = 5
+
+// Broker доставляет воркерам ID задач, готовых к выполнению
+type Broker interface {
+	// Push делает задачу доступной воркерам начиная с job.RunAt
+	Push(ctx context.Context, job *domain.Job) error
+	// Pop забирает ID следующей готовой задачи; false — очередь пуста
+	Pop(ctx context.Context) (uuid.UUID, bool, error)
+}
+
+// Queue ставит задачи в очередь и хранит их состояние
+type Queue struct {
+	repo        domain.JobRepository
+	broker      Broker
+	maxAttempts int
+}
+
+// NewQueue создаёт очередь задач
+func NewQueue(repo domain.JobRepository, broker Broker, maxAttempts int) *Queue {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	return &Queue{
+		repo:        repo,
+		broker:      broker,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Enqueue сохраняет задачу и передаёт её брокеру.
+// Если брокер недоступен, задача остаётся в таблице и будет возвращена в очередь воркером.
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload interface{}, userID, projectID uuid.UUID) (*domain.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	job := domain.NewJob(jobType, string(data), &userID, &projectID, q.maxAttempts)
+	if err := q.repo.Create(ctx, job); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	if err := q.broker.Push(ctx, job); err != nil {
+		logger.WithContext(ctx).Warn("failed to push job to broker, it will be recovered later",
+			zap.String("job_id", job.ID.String()),
+			zap.String("type", jobType),
+			zap.Error(err),
+		)
+	}
+
+	return job, nil
+}
+
+// Requeue возвращает задачу из dead letter в очередь с новым набором попыток
+func (q *Queue) Requeue(ctx context.Context, job *domain.Job) error {
+	now := time.Now()
+	job.Status = domain.JobStatusQueued
+	job.Attempts = 0
+	job.LastError = ""
+	job.RunAt = now
+	job.UpdatedAt = now
+	job.FinishedAt = nil
+
+	requeued, err := q.repo.Requeue(ctx, job)
+	if err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+	if !requeued {
+		return domain.ErrBadRequest.WithMessage("only dead jobs can be retried")
+	}
+
+	if err := q.broker.Push(ctx, job); err != nil {
+		logger.WithContext(ctx).Warn("failed to push job to broker, it will be recovered later",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+//go:build integration
+// +build integration
+
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/repositories"
+	testhelpers "github.com/landly/backend/internal/testing"
+)
+
+func TestQueue_Integration_DatabaseBroker(t *testing.T) {
+	ctx := context.Background()
+	qb := testhelpers.SetupTestDB(t)
+	repo := repositories.NewJobRepository(qb)
+
+	user, _ := testhelpers.CreateTestUser(t, qb, "", "")
+	project := testhelpers.CreateTestProject(t, qb, user.ID, "Jobs Project", "SaaS")
+
+	queue := NewQueue(repo, NewDatabaseBroker(repo), 2)
+	worker := NewWorker(queue, WorkerConfig{})
+
+	attempts := 0
+	worker.Handle(domain.JobTypePublish, func(ctx context.Context, job *domain.Job) error {
+		attempts++
+		return nil
+	})
+
+	job, err := queue.Enqueue(ctx, domain.JobTypePublish, map[string]string{"target_id": "t"}, user.ID, project.ID)
+	require.NoError(t, err)
+
+	processed, err := worker.processNext(ctx)
+	require.NoError(t, err)
+	assert.True(t, processed)
+	assert.Equal(t, 1, attempts)
+
+	stored, err := repo.GetByID(ctx, job.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobStatusSucceeded, stored.Status)
+	assert.Equal(t, 1, stored.Attempts)
+
+	// Завершённую задачу повторно забрать нельзя
+	claimed, err := repo.Claim(ctx, job.ID)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	// Итог устаревшей попытки не перезаписывает завершённую задачу
+	stored.Status = domain.JobStatusQueued
+	saved, err := repo.Update(ctx, stored)
+	require.NoError(t, err)
+	assert.False(t, saved)
+}
+
+func TestRedisBroker_Integration_DelaysJobsUntilRunAt(t *testing.T) {
+	ctx := context.Background()
+	client := testhelpers.SetupTestRedis(t)
+	broker := NewRedisBroker(client, "landly:jobs:test")
+
+	ready := domain.NewJob(domain.JobTypeGenerate, "{}", nil, nil, 1)
+	delayed := domain.NewJob(domain.JobTypeGenerate, "{}", nil, nil, 1)
+	delayed.RunAt = time.Now().Add(time.Hour)
+
+	require.NoError(t, broker.Push(ctx, delayed))
+	require.NoError(t, broker.Push(ctx, ready))
+
+	id, ok, err := broker.Pop(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, ready.ID, id)
+
+	_, ok, err = broker.Pop(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,341 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/landly/backend/internal/logger"
+	domain "github.com/landly/backend/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultConcurrency  = 2
+	defaultPollInterval = time.Second
+	defaultLeaseTimeout = 15 * time.Minute
+
+	// recoverInterval как часто воркер ищет зависшие и потерянные брокером задачи
+	recoverInterval  = time.Minute
+	recoverBatchSize = 100
+
+	backoffBase = 10 * time.Second
+	backoffMax  = 10 * time.Minute
+)
+
+// Handler выполняет задачу одного типа. Ошибка с 4xx-кодом domain.Error или
+// обёрнутая в Permanent не повторяется — задача сразу уходит в dead letter.
+type Handler func(ctx context.Context, job *domain.Job) error
+
+// WorkerConfig параметры воркера
+type WorkerConfig struct {
+	Concurrency  int
+	PollInterval time.Duration
+	// LeaseTimeout задача в running дольше этого времени считается брошенной и возвращается в очередь
+	LeaseTimeout time.Duration
+	// HeartbeatInterval как часто воркер продлевает аренду выполняемой задачи (по умолчанию LeaseTimeout/3)
+	HeartbeatInterval time.Duration
+}
+
+// Worker забирает задачи из брокера и выполняет их с повторами и backoff
+type Worker struct {
+	repo     domain.JobRepository
+	broker   Broker
+	handlers map[string]Handler
+	cfg      WorkerConfig
+	backoff  func(attempt int) time.Duration
+}
+
+// NewWorker создаёт воркер для очереди
+func NewWorker(queue *Queue, cfg WorkerConfig) *Worker {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaultConcurrency
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.LeaseTimeout <= 0 {
+		cfg.LeaseTimeout = defaultLeaseTimeout
+	}
+	if cfg.HeartbeatInterval <= 0 {
+		cfg.HeartbeatInterval = cfg.LeaseTimeout / 3
+	}
+	return &Worker{
+		repo:     queue.repo,
+		broker:   queue.broker,
+		handlers: make(map[string]Handler),
+		cfg:      cfg,
+		backoff:  Backoff,
+	}
+}
+
+// Handle регистрирует обработчик задач типа jobType
+func (w *Worker) Handle(jobType string, handler Handler) {
+	w.handlers[jobType] = handler
+}
+
+// Run обрабатывает задачи, пока не отменён ctx
+func (w *Worker) Run(ctx context.Context) {
+	w.recoverStuck(ctx)
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(recoverInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.recoverStuck(ctx)
+			}
+		}
+	}()
+
+	wg.Wait()
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		processed, err := w.processNext(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.WithContext(ctx).Error("failed to fetch job", zap.Error(err))
+		}
+		if processed {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.cfg.PollInterval):
+		}
+	}
+}
+
+// processNext выполняет одну задачу; false — готовых задач нет
+func (w *Worker) processNext(ctx context.Context) (bool, error) {
+	id, ok, err := w.broker.Pop(ctx)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	claimed, err := w.repo.Claim(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if !claimed {
+		// Задачу уже забрал другой воркер или она снята с очереди
+		return true, nil
+	}
+
+	job, err := w.repo.GetByID(ctx, id.String())
+	if err != nil {
+		return true, err
+	}
+
+	w.execute(ctx, job)
+	return true, nil
+}
+
+func (w *Worker) execute(ctx context.Context, job *domain.Job) {
+	log := logger.WithContext(ctx).With(
+		zap.String("job_id", job.ID.String()),
+		zap.String("type", job.Type),
+		zap.Int("attempt", job.Attempts),
+	)
+
+	var err error
+	if handler, ok := w.handlers[job.Type]; ok {
+		err = w.run(ctx, handler, job)
+	} else {
+		err = Permanent(fmt.Errorf("no handler for job type %q", job.Type))
+	}
+
+	now := time.Now()
+	job.UpdatedAt = now
+	switch {
+	case err == nil:
+		job.Status = domain.JobStatusSucceeded
+		job.LastError = ""
+		job.FinishedAt = &now
+		log.Info("job completed")
+	case IsPermanent(err) || job.LastAttempt():
+		job.Status = domain.JobStatusDead
+		job.LastError = err.Error()
+		job.FinishedAt = &now
+		log.Error("job moved to dead letter", zap.Error(err))
+	default:
+		delay := w.backoff(job.Attempts)
+		job.Status = domain.JobStatusQueued
+		job.LastError = err.Error()
+		job.RunAt = now.Add(delay)
+		log.Warn("job failed, will retry", zap.Duration("retry_in", delay), zap.Error(err))
+	}
+
+	// Итог сохраняется и при остановке воркера, иначе задача зависнет в running до LeaseTimeout
+	saveCtx := context.WithoutCancel(ctx)
+	saved, err := w.repo.Update(saveCtx, job)
+	if err != nil {
+		log.Error("failed to save job state", zap.Error(err))
+		return
+	}
+	if !saved {
+		// Аренда потеряна: задачу уже выполняет другой воркер, его итог не перезаписываем
+		log.Warn("job lease lost, result discarded")
+		return
+	}
+	if job.Status == domain.JobStatusQueued {
+		if err := w.broker.Push(saveCtx, job); err != nil {
+			log.Warn("failed to push job to broker, it will be recovered later", zap.Error(err))
+		}
+	}
+}
+
+func (w *Worker) run(ctx context.Context, handler Handler, job *domain.Job) (err error) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	var heartbeat sync.WaitGroup
+	heartbeat.Add(1)
+	go func() {
+		defer heartbeat.Done()
+		w.heartbeat(jobCtx, job, cancel)
+	}()
+	defer func() {
+		cancel()
+		heartbeat.Wait()
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(jobCtx, job)
+}
+
+// heartbeat продлевает аренду задачи, пока работает обработчик, чтобы долгую генерацию
+// или публикацию не забрал другой воркер. Если задачу уже забрали заново, обработчик отменяется.
+func (w *Worker) heartbeat(ctx context.Context, job *domain.Job, cancel context.CancelFunc) {
+	ticker := time.NewTicker(w.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			owned, err := w.repo.Touch(ctx, job.ID, job.Attempts)
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.WithContext(ctx).Warn("failed to extend job lease", zap.String("job_id", job.ID.String()), zap.Error(err))
+				}
+				continue
+			}
+			if !owned {
+				logger.WithContext(ctx).Warn("job lease lost, cancelling handler", zap.String("job_id", job.ID.String()))
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// recoverStuck возвращает в очередь задачи брошенных воркеров и задачи, потерянные брокером;
+// брошенные на последней попытке переводятся в dead letter
+func (w *Worker) recoverStuck(ctx context.Context) {
+	log := logger.WithContext(ctx)
+
+	stuck, err := w.repo.ListStuck(ctx, time.Now().Add(-w.cfg.LeaseTimeout), recoverBatchSize)
+	if err != nil {
+		log.Error("failed to list stuck jobs", zap.Error(err))
+		return
+	}
+
+	for _, job := range stuck {
+		if job.Status == domain.JobStatusRunning {
+			// Задача, ронявшая или вешавшая воркер на последней попытке, уходит в dead letter, а не по кругу
+			now := time.Now()
+			job.UpdatedAt = now
+			if job.LastAttempt() {
+				job.Status = domain.JobStatusDead
+				job.LastError = "job lease expired on the last attempt"
+				job.FinishedAt = &now
+			} else {
+				job.Status = domain.JobStatusQueued
+				job.RunAt = now
+			}
+			saved, err := w.repo.Update(ctx, job)
+			if err != nil {
+				log.Error("failed to recover stuck job", zap.String("job_id", job.ID.String()), zap.Error(err))
+				continue
+			}
+			if !saved {
+				// Воркер успел продлить аренду или завершить задачу
+				continue
+			}
+			if job.Status == domain.JobStatusDead {
+				log.Error("stuck job moved to dead letter", zap.String("job_id", job.ID.String()), zap.String("type", job.Type))
+				continue
+			}
+			log.Warn("requeued stuck job", zap.String("job_id", job.ID.String()), zap.String("type", job.Type))
+		}
+		if err := w.broker.Push(ctx, job); err != nil {
+			log.Error("failed to push recovered job", zap.String("job_id", job.ID.String()), zap.Error(err))
+		}
+	}
+}
+
+// Backoff задержка перед повторной попыткой: 10s, 20s, 40s, ... не больше 10 минут
+func Backoff(attempt int) time.Duration {
+	delay := backoffBase
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= backoffMax {
+			return backoffMax
+		}
+	}
+	return delay
+}
+
+type permanentError struct {
+	err error
+}
+
+// Permanent помечает ошибку как неповторяемую
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// IsPermanent ошибки клиента (нет доступа, не найдено, невалидные данные) повтор не исправит
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return true
+	}
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		status := domainErr.HTTPStatus()
+		return status >= 400 && status < 500
+	}
+	return false
+}
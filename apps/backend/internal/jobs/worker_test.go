@@ -0,0 +1,404 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+// memoryRepository таблица jobs в памяти
+type memoryRepository struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]domain.Job
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{jobs: make(map[uuid.UUID]domain.Job)}
+}
+
+func (r *memoryRepository) Create(ctx context.Context, job *domain.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *memoryRepository) GetByID(ctx context.Context, id string) (*domain.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[uuid.MustParse(id)]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &job, nil
+}
+
+func (r *memoryRepository) Update(ctx context.Context, job *domain.Job) (bool, error) {
+	return r.replace(job, domain.JobStatusRunning)
+}
+
+func (r *memoryRepository) Requeue(ctx context.Context, job *domain.Job) (bool, error) {
+	return r.replace(job, domain.JobStatusDead)
+}
+
+// replace перезаписывает задачу, если она в статусе from (и для running — в той же попытке)
+func (r *memoryRepository) replace(job *domain.Job, from string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.jobs[job.ID]
+	if !ok || current.Status != from || (from == domain.JobStatusRunning && current.Attempts != job.Attempts) {
+		return false, nil
+	}
+	r.jobs[job.ID] = *job
+	return true, nil
+}
+
+func (r *memoryRepository) Claim(ctx context.Context, id uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok || job.Status != domain.JobStatusQueued {
+		return false, nil
+	}
+	job.Status = domain.JobStatusRunning
+	job.Attempts++
+	job.UpdatedAt = time.Now()
+	r.jobs[id] = job
+	return true, nil
+}
+
+func (r *memoryRepository) Touch(ctx context.Context, id uuid.UUID, attempt int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok || job.Status != domain.JobStatusRunning || job.Attempts != attempt {
+		return false, nil
+	}
+	job.UpdatedAt = time.Now()
+	r.jobs[id] = job
+	return true, nil
+}
+
+func (r *memoryRepository) NextQueued(ctx context.Context, now time.Time) (uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var next *domain.Job
+	for _, job := range r.jobs {
+		job := job
+		if job.Status == domain.JobStatusQueued && !job.RunAt.After(now) && (next == nil || job.RunAt.Before(next.RunAt)) {
+			next = &job
+		}
+	}
+	if next == nil {
+		return uuid.Nil, domain.ErrNotFound
+	}
+	return next.ID, nil
+}
+
+func (r *memoryRepository) ListStuck(ctx context.Context, before time.Time, limit int) ([]*domain.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var stuck []*domain.Job
+	for _, job := range r.jobs {
+		job := job
+		if (job.Status == domain.JobStatusRunning && job.UpdatedAt.Before(before)) ||
+			(job.Status == domain.JobStatusQueued && job.RunAt.Before(before)) {
+			stuck = append(stuck, &job)
+		}
+	}
+	return stuck, nil
+}
+
+func (r *memoryRepository) get(t *testing.T, id uuid.UUID) domain.Job {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	require.True(t, ok)
+	return job
+}
+
+// recordingBroker брокер на таблице, запоминающий Push
+type recordingBroker struct {
+	*DatabaseBroker
+	pushed []uuid.UUID
+}
+
+func (b *recordingBroker) Push(ctx context.Context, job *domain.Job) error {
+	b.pushed = append(b.pushed, job.ID)
+	return nil
+}
+
+func newTestWorker(t *testing.T) (*Worker, *Queue, *memoryRepository, *recordingBroker) {
+	t.Helper()
+	repo := newMemoryRepository()
+	broker := &recordingBroker{DatabaseBroker: NewDatabaseBroker(repo)}
+	queue := NewQueue(repo, broker, 3)
+	worker := NewWorker(queue, WorkerConfig{LeaseTimeout: time.Minute})
+	return worker, queue, repo, broker
+}
+
+func enqueue(t *testing.T, queue *Queue, jobType string) *domain.Job {
+	t.Helper()
+	job, err := queue.Enqueue(context.Background(), jobType, map[string]string{"key": "value"}, uuid.New(), uuid.New())
+	require.NoError(t, err)
+	return job
+}
+
+func TestWorker_ProcessNext_Succeeds(t *testing.T) {
+	ctx := context.Background()
+	worker, queue, repo, _ := newTestWorker(t)
+
+	var payload string
+	worker.Handle("test", func(ctx context.Context, job *domain.Job) error {
+		payload = job.Payload
+		return nil
+	})
+	job := enqueue(t, queue, "test")
+
+	processed, err := worker.processNext(ctx)
+	require.NoError(t, err)
+	assert.True(t, processed)
+	assert.JSONEq(t, `{"key":"value"}`, payload)
+
+	stored := repo.get(t, job.ID)
+	assert.Equal(t, domain.JobStatusSucceeded, stored.Status)
+	assert.Equal(t, 1, stored.Attempts)
+	assert.NotNil(t, stored.FinishedAt)
+}
+
+func TestWorker_ProcessNext_RetriesWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	worker, queue, repo, broker := newTestWorker(t)
+
+	worker.Handle("test", func(ctx context.Context, job *domain.Job) error {
+		return errors.New("s3 timeout")
+	})
+	job := enqueue(t, queue, "test")
+
+	before := time.Now()
+	_, err := worker.processNext(ctx)
+	require.NoError(t, err)
+
+	stored := repo.get(t, job.ID)
+	assert.Equal(t, domain.JobStatusQueued, stored.Status)
+	assert.Equal(t, "s3 timeout", stored.LastError)
+	assert.True(t, !stored.RunAt.Before(before.Add(Backoff(1))))
+	assert.Equal(t, []uuid.UUID{job.ID, job.ID}, broker.pushed)
+
+	// До истечения backoff задача воркеру не выдаётся
+	processed, err := worker.processNext(ctx)
+	require.NoError(t, err)
+	assert.False(t, processed)
+}
+
+func TestWorker_ProcessNext_DeadLetter(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		handler  Handler
+	}{
+		{
+			name:     "attempts exhausted",
+			attempts: 2,
+			handler:  func(context.Context, *domain.Job) error { return errors.New("boom") },
+		},
+		{
+			name:    "permanent error",
+			handler: func(context.Context, *domain.Job) error { return Permanent(errors.New("bad payload")) },
+		},
+		{
+			name:    "client error",
+			handler: func(context.Context, *domain.Job) error { return domain.ErrForbidden },
+		},
+		{
+			name:     "panic on last attempt",
+			attempts: 2,
+			handler:  func(context.Context, *domain.Job) error { panic("nil map") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			worker, queue, repo, _ := newTestWorker(t)
+			worker.Handle("test", tt.handler)
+
+			job := enqueue(t, queue, "test")
+			job.Attempts = tt.attempts
+			require.NoError(t, repo.Create(ctx, job))
+
+			_, err := worker.processNext(ctx)
+			require.NoError(t, err)
+
+			stored := repo.get(t, job.ID)
+			assert.Equal(t, domain.JobStatusDead, stored.Status)
+			assert.NotEmpty(t, stored.LastError)
+			assert.NotNil(t, stored.FinishedAt)
+		})
+	}
+}
+
+func TestWorker_ProcessNext_UnknownTypeIsDead(t *testing.T) {
+	ctx := context.Background()
+	worker, queue, repo, _ := newTestWorker(t)
+	job := enqueue(t, queue, "unknown")
+
+	_, err := worker.processNext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobStatusDead, repo.get(t, job.ID).Status)
+}
+
+func TestWorker_RecoverStuck(t *testing.T) {
+	ctx := context.Background()
+	worker, queue, repo, broker := newTestWorker(t)
+
+	job := enqueue(t, queue, "test")
+	job.Status = domain.JobStatusRunning
+	job.UpdatedAt = time.Now().Add(-time.Hour)
+	require.NoError(t, repo.Create(ctx, job))
+	broker.pushed = nil
+
+	worker.recoverStuck(ctx)
+
+	assert.Equal(t, domain.JobStatusQueued, repo.get(t, job.ID).Status)
+	assert.Equal(t, []uuid.UUID{job.ID}, broker.pushed)
+}
+
+func TestWorker_RecoverStuck_LastAttemptIsDead(t *testing.T) {
+	ctx := context.Background()
+	worker, queue, repo, broker := newTestWorker(t)
+
+	job := enqueue(t, queue, "test")
+	job.Status = domain.JobStatusRunning
+	job.Attempts = job.MaxAttempts
+	job.UpdatedAt = time.Now().Add(-time.Hour)
+	require.NoError(t, repo.Create(ctx, job))
+	broker.pushed = nil
+
+	worker.recoverStuck(ctx)
+
+	stored := repo.get(t, job.ID)
+	assert.Equal(t, domain.JobStatusDead, stored.Status)
+	assert.NotEmpty(t, stored.LastError)
+	assert.NotNil(t, stored.FinishedAt)
+	assert.Empty(t, broker.pushed)
+}
+
+func TestWorker_HeartbeatExtendsLease(t *testing.T) {
+	ctx := context.Background()
+	worker, queue, repo, _ := newTestWorker(t)
+	worker.cfg.LeaseTimeout = 40 * time.Millisecond
+	worker.cfg.HeartbeatInterval = 5 * time.Millisecond
+
+	var stuck []*domain.Job
+	worker.Handle("test", func(ctx context.Context, job *domain.Job) error {
+		time.Sleep(100 * time.Millisecond)
+		var err error
+		stuck, err = repo.ListStuck(ctx, time.Now().Add(-worker.cfg.LeaseTimeout), recoverBatchSize)
+		return err
+	})
+	job := enqueue(t, queue, "test")
+
+	_, err := worker.processNext(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, stuck, "running job must not look abandoned while the handler is alive")
+	assert.Equal(t, domain.JobStatusSucceeded, repo.get(t, job.ID).Status)
+}
+
+func TestWorker_HeartbeatCancelsHandlerWhenLeaseLost(t *testing.T) {
+	ctx := context.Background()
+	worker, queue, repo, _ := newTestWorker(t)
+	worker.cfg.HeartbeatInterval = 5 * time.Millisecond
+
+	var handlerErr error
+	worker.Handle("test", func(ctx context.Context, job *domain.Job) error {
+		// Другой воркер вернул задачу в очередь и забрал её заново
+		reclaimed := repo.get(t, job.ID)
+		reclaimed.Attempts++
+		require.NoError(t, repo.Create(ctx, &reclaimed))
+
+		select {
+		case <-ctx.Done():
+			handlerErr = ctx.Err()
+		case <-time.After(time.Second):
+			handlerErr = errors.New("handler was not cancelled")
+		}
+		return handlerErr
+	})
+	enqueue(t, queue, "test")
+
+	_, err := worker.processNext(ctx)
+	require.NoError(t, err)
+	assert.ErrorIs(t, handlerErr, context.Canceled)
+}
+
+func TestWorker_Execute_DiscardsResultAfterLeaseLost(t *testing.T) {
+	ctx := context.Background()
+	worker, queue, repo, broker := newTestWorker(t)
+
+	worker.Handle("test", func(ctx context.Context, job *domain.Job) error {
+		// Пока обработчик работал, задачу вернули в очередь и забрал другой воркер
+		reclaimed := repo.get(t, job.ID)
+		reclaimed.Attempts++
+		require.NoError(t, repo.Create(ctx, &reclaimed))
+		return errors.New("s3 timeout")
+	})
+	job := enqueue(t, queue, "test")
+	broker.pushed = nil
+
+	_, err := worker.processNext(ctx)
+	require.NoError(t, err)
+
+	stored := repo.get(t, job.ID)
+	assert.Equal(t, domain.JobStatusRunning, stored.Status, "the other worker keeps the job")
+	assert.Equal(t, 2, stored.Attempts)
+	assert.Empty(t, stored.LastError)
+	assert.Empty(t, broker.pushed)
+}
+
+func TestQueue_Requeue(t *testing.T) {
+	ctx := context.Background()
+	_, queue, repo, _ := newTestWorker(t)
+
+	job := enqueue(t, queue, "test")
+	job.Status = domain.JobStatusDead
+	job.Attempts = 3
+	job.LastError = "boom"
+	require.NoError(t, repo.Create(ctx, job))
+
+	require.NoError(t, queue.Requeue(ctx, job))
+
+	stored := repo.get(t, job.ID)
+	assert.Equal(t, domain.JobStatusQueued, stored.Status)
+	assert.Zero(t, stored.Attempts)
+	assert.Empty(t, stored.LastError)
+	assert.Nil(t, stored.FinishedAt)
+
+	err := queue.Requeue(ctx, job)
+	assert.ErrorIs(t, err, domain.ErrBadRequest, "a job that is no longer dead is not requeued twice")
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, Backoff(1))
+	assert.Equal(t, 20*time.Second, Backoff(2))
+	assert.Equal(t, 80*time.Second, Backoff(4))
+	assert.Equal(t, backoffMax, Backoff(20))
+}
+
+func TestNewBroker_FallsBackToDatabase(t *testing.T) {
+	broker, err := NewBroker(context.Background(), BrokerConfig{Backend: BackendAuto}, newMemoryRepository())
+	require.NoError(t, err)
+	assert.IsType(t, &DatabaseBroker{}, broker)
+
+	_, err = NewBroker(context.Background(), BrokerConfig{Backend: BackendRedis}, newMemoryRepository())
+	assert.Error(t, err)
+}
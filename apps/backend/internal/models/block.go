@@ -21,6 +21,21 @@ const (
 	BlockTypeContact      BlockType = "contact"
 )
 
+// BlockTarget адресует блок схемы для точечной перегенерации: либо PagePath + BlockIndex,
+// либо BlockType (первый блок этого типа на странице PagePath или на любой странице)
+type BlockTarget struct {
+	PagePath   string
+	BlockIndex *int
+	BlockType  string
+}
+
+// BlockRef ссылка на блок внутри JSON-схемы лендинга
+type BlockRef struct {
+	PagePath   string `json:"page_path"`
+	BlockIndex int    `json:"block_index"`
+	BlockType  string `json:"block_type"`
+}
+
 // Block представляет блок (секцию) страницы
 type Block struct {
 	ID        uuid.UUID `db:"id" json:"id"`
@@ -0,0 +1,31 @@
+package domain
+
+import "encoding/json"
+
+// Типы событий потоковой генерации в чате
+const (
+	ChatEventMessageAccepted  = "message_accepted"
+	ChatEventToken            = "token"
+	ChatEventBlockParsed      = "block_parsed"
+	ChatEventSchemaSaved      = "schema_saved"
+	ChatEventAssistantMessage = "assistant_message"
+)
+
+// ChatStreamEvent событие потоковой генерации в чате.
+// Заполняются только поля, относящиеся к типу события.
+type ChatStreamEvent struct {
+	Type string
+
+	// Message — для message_accepted и assistant_message
+	Message *GenerationMessage
+	// Session — для schema_saved
+	Session *GenerationSession
+
+	// Delta и Attempt — для token; Attempt растёт при запросе исправления схемы
+	Delta   string
+	Attempt int
+
+	// BlockIndex и Block — для block_parsed (порядковый номер блока в ответе модели)
+	BlockIndex int
+	Block      json.RawMessage
+}
@@ -1,6 +1,10 @@
 package domain
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -35,9 +39,12 @@ type GenerationSession struct {
 	Model       string     `db:"model" json:"model"`
 	Status      string     `db:"status" json:"status"`
 	SchemaJSON  string     `db:"schema_json" json:"schema_json"`
+	ErrorJSON   string     `db:"error_json" json:"error_json,omitempty"`
 	CompletedAt *time.Time `db:"completed_at" json:"completed_at"`
 	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
 	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+	// JobID задача, в которой выполняется генерация (не хранится в БД)
+	JobID *uuid.UUID `db:"-" json:"job_id,omitempty"`
 }
 
 // GenerationMessage представляет сообщение в рамках сессии генерации
@@ -51,15 +58,122 @@ type GenerationMessage struct {
 	CreatedAt  time.Time `db:"created_at" json:"created_at"`
 }
 
+// SchemaRevision снимок схемы проекта после очередного изменения
+type SchemaRevision struct {
+	ID           uuid.UUID  `db:"id" json:"id"`
+	ProjectID    uuid.UUID  `db:"project_id" json:"project_id"`
+	Version      int        `db:"version" json:"version"`
+	SchemaJSON   string     `db:"schema_json" json:"schema_json"`
+	Source       string     `db:"source" json:"source"`
+	AuthorID     *uuid.UUID `db:"author_id" json:"author_id"`
+	MessageID    *uuid.UUID `db:"message_id" json:"message_id"`
+	RestoredFrom *uuid.UUID `db:"restored_from" json:"restored_from"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+}
+
 // PublishTarget представляет цель публикации
 type PublishTarget struct {
-	ID              uuid.UUID  `db:"id" json:"id"`
-	ProjectID       uuid.UUID  `db:"project_id" json:"project_id"`
-	Subdomain       string     `db:"subdomain" json:"subdomain"`
-	Status          string     `db:"status" json:"status"`
-	LastPublishedAt *time.Time `db:"last_published_at" json:"last_published_at"`
-	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
-	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`
+	ID                 uuid.UUID  `db:"id" json:"id"`
+	ProjectID          uuid.UUID  `db:"project_id" json:"project_id"`
+	Subdomain          string     `db:"subdomain" json:"subdomain"`
+	Status             string     `db:"status" json:"status"`
+	LastPublishedAt    *time.Time `db:"last_published_at" json:"last_published_at"`
+	ActiveDeploymentID *uuid.UUID `db:"active_deployment_id" json:"active_deployment_id"`
+	CreatedAt          time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time  `db:"updated_at" json:"updated_at"`
+	// CustomDomain собственный домен проекта; сайт отдаётся на нём только после подтверждения владения
+	CustomDomain            string     `db:"custom_domain" json:"custom_domain,omitempty"`
+	DomainVerificationToken string     `db:"domain_verification_token" json:"-"`
+	DomainVerifiedAt        *time.Time `db:"domain_verified_at" json:"domain_verified_at,omitempty"`
+	SSLStatus               string     `db:"ssl_status" json:"ssl_status"`
+	// JobID задача, в которой выполняется публикация (не хранится в БД)
+	JobID *uuid.UUID `db:"-" json:"job_id,omitempty"`
+}
+
+// DomainVerificationRecordPrefix поддомен TXT-записи, подтверждающей владение доменом
+const DomainVerificationRecordPrefix = "_landly-verification."
+
+// DomainVerified подтверждено ли владение собственным доменом
+func (t *PublishTarget) DomainVerified() bool {
+	return t.CustomDomain != "" && t.DomainVerifiedAt != nil
+}
+
+// DomainVerificationRecord имя TXT-записи, в которую владелец домена кладёт токен
+func (t *PublishTarget) DomainVerificationRecord() string {
+	return DomainVerificationRecordPrefix + t.CustomDomain
+}
+
+// TLSCertificate сертификат собственного домена, выпущенный по ACME: PEM-цепочка и ключ
+type TLSCertificate struct {
+	Domain    string    `db:"domain" json:"domain"`
+	CertPEM   string    `db:"cert_pem" json:"-"`
+	KeyPEM    string    `db:"key_pem" json:"-"`
+	NotAfter  time.Time `db:"not_after" json:"not_after"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// ACMEAccount ключ аккаунта в удостоверяющем центре; аккаунт определяется ключом
+type ACMEAccount struct {
+	DirectoryURL string    `db:"directory_url" json:"directory_url"`
+	KeyPEM       string    `db:"key_pem" json:"-"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
+
+// Deployment версия опубликованного сайта в неизменяемом префиксе хранилища
+type Deployment struct {
+	ID         uuid.UUID  `db:"id" json:"id"`
+	ProjectID  uuid.UUID  `db:"project_id" json:"project_id"`
+	Version    int        `db:"version" json:"version"`
+	Subdomain  string     `db:"subdomain" json:"subdomain"`
+	Status     string     `db:"status" json:"status"`
+	AuthorID   *uuid.UUID `db:"author_id" json:"author_id"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	FinishedAt *time.Time `db:"finished_at" json:"finished_at"`
+	// Variants варианты A/B-теста на момент публикации; пусто — эксперимента нет
+	Variants []DeploymentVariant `db:"variants" json:"variants,omitempty"`
+	// SchemaJSON основная схема на момент публикации; пусто у деплоев, опубликованных до снимков схемы
+	SchemaJSON string `db:"schema_json" json:"-"`
+}
+
+// DeploymentVariant вариант в снимке деплоя: ключ и доля трафика в процентах.
+// SchemaJSON схема варианта на момент публикации; у контроля пусто — он собран из основной схемы.
+type DeploymentVariant struct {
+	Key        string `json:"key"`
+	Weight     int    `json:"weight"`
+	SchemaJSON string `json:"schema_json,omitempty"`
+}
+
+// VariantStoragePrefix префикс сборки варианта внутри деплоя
+func (d *Deployment) VariantStoragePrefix(key string) string {
+	return d.StoragePrefix() + "/variants/" + key
+}
+
+// StoragePrefix префикс файлов деплоя в хранилище: sites/<subdomain>/v<version>
+func (d *Deployment) StoragePrefix() string {
+	return fmt.Sprintf("sites/%s/v%d", d.Subdomain, d.Version)
+}
+
+// Job фоновая задача: генерация, публикация или доставка вебхука, выполняемая воркером
+type Job struct {
+	ID          uuid.UUID  `db:"id" json:"id"`
+	Type        string     `db:"type" json:"type"`
+	Payload     string     `db:"payload" json:"payload"`
+	Status      string     `db:"status" json:"status"`
+	Attempts    int        `db:"attempts" json:"attempts"`
+	MaxAttempts int        `db:"max_attempts" json:"max_attempts"`
+	LastError   string     `db:"last_error" json:"last_error,omitempty"`
+	UserID      *uuid.UUID `db:"user_id" json:"user_id"`
+	ProjectID   *uuid.UUID `db:"project_id" json:"project_id"`
+	RunAt       time.Time  `db:"run_at" json:"run_at"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+	FinishedAt  *time.Time `db:"finished_at" json:"finished_at"`
+}
+
+// LastAttempt текущая попытка последняя: при ошибке задача уйдёт в dead letter, а не на повтор
+func (j *Job) LastAttempt() bool {
+	return j.Attempts >= j.MaxAttempts
 }
 
 // AnalyticsEvent представляет событие аналитики
@@ -71,6 +185,21 @@ type AnalyticsEvent struct {
 	Referrer  string    `db:"referrer" json:"referrer"`
 	UserAgent string    `db:"user_agent" json:"user_agent"`
 	IPAddress string    `db:"ip_address" json:"ip_address"`
+	// VisitorHash хэш IP + User-Agent + проект с солью суток, по нему считаются уникальные посетители
+	VisitorHash string `db:"visitor_hash" json:"-"`
+	// UTM-метки из адреса страницы
+	UTMSource   string `db:"utm_source" json:"utm_source"`
+	UTMMedium   string `db:"utm_medium" json:"utm_medium"`
+	UTMCampaign string `db:"utm_campaign" json:"utm_campaign"`
+	UTMTerm     string `db:"utm_term" json:"utm_term"`
+	UTMContent  string `db:"utm_content" json:"utm_content"`
+	// ReferrerDomain домен источника перехода; пусто — прямой заход или переход внутри сайта
+	ReferrerDomain string `db:"referrer_domain" json:"referrer_domain"`
+	Browser        string `db:"browser" json:"browser"`
+	OS             string `db:"os" json:"os"`
+	DeviceType     string `db:"device_type" json:"device_type"`
+	// Variant вариант A/B-теста, который видел посетитель
+	Variant   string    `db:"variant" json:"variant,omitempty"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 }
 
@@ -84,6 +213,140 @@ type Integration struct {
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
 }
 
+// IntegrationConfig настройки интеграции, хранятся JSON в integrations.config
+type IntegrationConfig struct {
+	// PaymentURL платёжная ссылка: Stripe Payment Link или PayPal
+	PaymentURL string `json:"payment_url,omitempty"`
+	// WebhookSecret секрет подписи вебхуков Stripe (whsec_...)
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+}
+
+// ParseConfig разбирает настройки интеграции; пустой config — настройки по умолчанию
+func (i *Integration) ParseConfig() (IntegrationConfig, error) {
+	var config IntegrationConfig
+	if strings.TrimSpace(i.Config) == "" {
+		return config, nil
+	}
+	if err := json.Unmarshal([]byte(i.Config), &config); err != nil {
+		return config, fmt.Errorf("invalid integration config: %w", err)
+	}
+	return config, nil
+}
+
+// SetConfig сохраняет настройки в Config
+func (i *Integration) SetConfig(config IntegrationConfig) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	i.Config = string(data)
+	return nil
+}
+
+// Purchase оплата, подтверждённая платёжной системой.
+// Amount в минимальных единицах валюты (центы, копейки); Variant пусто вне эксперимента.
+type Purchase struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	ProjectID  uuid.UUID `db:"project_id" json:"project_id"`
+	Provider   string    `db:"provider" json:"provider"`
+	ExternalID string    `db:"external_id" json:"external_id"`
+	Amount     int64     `db:"amount" json:"amount"`
+	Currency   string    `db:"currency" json:"currency"`
+	Variant    string    `db:"variant" json:"variant,omitempty"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+// FormSubmission заявка из формы лендинга; Data — JSON значений полей
+type FormSubmission struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	ProjectID uuid.UUID `db:"project_id" json:"project_id"`
+	FormName  string    `db:"form_name" json:"form_name"`
+	Data      string    `db:"data" json:"data"`
+	Path      string    `db:"path" json:"path"`
+	Variant   string    `db:"variant" json:"variant,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Fields разбирает значения полей заявки
+func (s *FormSubmission) Fields() (map[string]string, error) {
+	fields := make(map[string]string)
+	if err := json.Unmarshal([]byte(s.Data), &fields); err != nil {
+		return nil, fmt.Errorf("invalid form submission data: %w", err)
+	}
+	return fields, nil
+}
+
+// Webhook подписка проекта на события: JSON события отправляется POST-запросом на URL,
+// подписанный секретом подписки
+type Webhook struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	ProjectID uuid.UUID `db:"project_id" json:"project_id"`
+	URL       string    `db:"url" json:"url"`
+	Secret    string    `db:"secret" json:"-"`
+	Events    []string  `db:"events" json:"events"`
+	Active    bool      `db:"active" json:"active"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Subscribed проверяет, что подписка активна и получает события типа event
+func (w *Webhook) Subscribed(event string) bool {
+	if !w.Active {
+		return false
+	}
+	for _, subscribed := range w.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery попытка доставки события подписчику. Повторы одного события
+// имеют общий EventID и растущий Attempt.
+type WebhookDelivery struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	WebhookID  uuid.UUID `db:"webhook_id" json:"webhook_id"`
+	ProjectID  uuid.UUID `db:"project_id" json:"project_id"`
+	EventID    uuid.UUID `db:"event_id" json:"event_id"`
+	Event      string    `db:"event" json:"event"`
+	Payload    string    `db:"payload" json:"payload"`
+	Attempt    int       `db:"attempt" json:"attempt"`
+	StatusCode int       `db:"status_code" json:"status_code"`
+	Error      string    `db:"error" json:"error,omitempty"`
+	DurationMs int64     `db:"duration_ms" json:"duration_ms"`
+	Success    bool      `db:"success" json:"success"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+// WebhookEvent событие, отправляемое подписчикам; тело запроса — его JSON
+type WebhookEvent struct {
+	ID        uuid.UUID   `json:"id"`
+	Type      string      `json:"type"`
+	ProjectID uuid.UUID   `json:"project_id"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// ClientReferenceID метка, которую рендерер добавляет к ссылке на оплату:
+// по ней оплата из вебхука относится к проекту и варианту лендинга
+func ClientReferenceID(projectID uuid.UUID, variant string) string {
+	if variant == "" {
+		return projectID.String()
+	}
+	return projectID.String() + "_" + variant
+}
+
+// ParseClientReferenceID разбирает метку ClientReferenceID
+func ParseClientReferenceID(ref string) (uuid.UUID, string, bool) {
+	rawID, variant, _ := strings.Cut(ref, "_")
+	projectID, err := uuid.Parse(rawID)
+	if err != nil {
+		return uuid.Nil, "", false
+	}
+	return projectID, variant, true
+}
+
 // Константы статусов
 const (
 	ProjectStatusDraft     = "draft"
@@ -98,17 +361,113 @@ const (
 	PublishStatusPublished = "published"
 	PublishStatusFailed    = "failed"
 
+	SSLStatusNone    = "none"
+	SSLStatusPending = "pending"
+	SSLStatusActive  = "active"
+	SSLStatusFailed  = "failed"
+
+	DeploymentStatusPending   = "pending"
+	DeploymentStatusSucceeded = "succeeded"
+	DeploymentStatusFailed    = "failed"
+
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusDead      = "dead"
+
+	JobTypeGenerate = "generate"
+	JobTypePublish  = "publish"
+	JobTypeWebhook  = "webhook"
+
+	AnalyticsEventPageview     = "pageview"
+	AnalyticsEventCTAClick     = "cta_click"
+	AnalyticsEventCTASecondary = "cta_secondary"
+	AnalyticsEventPayClick     = "pay_click"
+	AnalyticsEventContactEmail = "contact_email"
+	AnalyticsEventContactPhone = "contact_phone"
+	AnalyticsEventFormSubmit   = "form_submit"
+
+	DeviceTypeDesktop = "desktop"
+	DeviceTypeMobile  = "mobile"
+	DeviceTypeTablet  = "tablet"
+	DeviceTypeBot     = "bot"
+
 	MessageRoleUser      = "user"
 	MessageRoleAssistant = "assistant"
 	MessageRoleSystem    = "system"
 
 	IntegrationTypeStripe = "stripe"
 	IntegrationTypePayPal = "paypal"
+
+	PurchaseProviderStripe = "stripe"
+
+	WebhookEventSitePublished     = "site.published"
+	WebhookEventLeadSubmitted     = "lead.submitted"
+	WebhookEventPurchaseCompleted = "purchase.completed"
+	WebhookEventGoalReached       = "goal.reached"
+	// WebhookEventTest отправляется по запросу владельца; подписка на него не нужна
+	WebhookEventTest = "webhook.test"
+
+	RevisionSourceInitial        = "initial"
+	RevisionSourceGenerate       = "generate"
+	RevisionSourceGenerateSimple = "generate_simple"
+	RevisionSourceChat           = "chat"
+	RevisionSourceManual         = "manual"
+	RevisionSourceRestore        = "restore"
+	RevisionSourceUndo           = "undo"
+	RevisionSourceExperiment     = "experiment"
 )
 
 // IntegrationType тип интеграции
 type IntegrationType string
 
+// PaymentIntegrationTypes платёжные интеграции в порядке выбора ссылки для сайта
+var PaymentIntegrationTypes = []IntegrationType{IntegrationTypeStripe, IntegrationTypePayPal}
+
+// IsValidIntegrationType проверяет, что тип интеграции поддерживается
+func IsValidIntegrationType(integrationType string) bool {
+	for _, known := range PaymentIntegrationTypes {
+		if string(known) == integrationType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEventTypes события проекта, на которые можно подписать вебхук
+var WebhookEventTypes = []string{
+	WebhookEventSitePublished,
+	WebhookEventLeadSubmitted,
+	WebhookEventPurchaseCompleted,
+	WebhookEventGoalReached,
+}
+
+// IsValidWebhookEvent проверяет, что на событие можно подписаться
+func IsValidWebhookEvent(event string) bool {
+	for _, known := range WebhookEventTypes {
+		if known == event {
+			return true
+		}
+	}
+	return false
+}
+
+// analyticsEventTypes события, которые принимает трекинг (data-track блоков и pageview)
+var analyticsEventTypes = map[string]bool{
+	AnalyticsEventPageview:     true,
+	AnalyticsEventCTAClick:     true,
+	AnalyticsEventCTASecondary: true,
+	AnalyticsEventPayClick:     true,
+	AnalyticsEventContactEmail: true,
+	AnalyticsEventContactPhone: true,
+	AnalyticsEventFormSubmit:   true,
+}
+
+// IsValidAnalyticsEventType проверяет, что тип события известен трекингу
+func IsValidAnalyticsEventType(eventType string) bool {
+	return analyticsEventTypes[eventType]
+}
+
 // AnalyticsStats статистика аналитики
 type AnalyticsStats struct {
 	TotalPageViews int `json:"total_page_views"`
@@ -117,6 +476,203 @@ type AnalyticsStats struct {
 	PayClicks      int `json:"pay_clicks"`
 }
 
+// AnalyticsGranularity шаг временного ряда аналитики
+type AnalyticsGranularity string
+
+const (
+	AnalyticsGranularityHour AnalyticsGranularity = "hour"
+	AnalyticsGranularityDay  AnalyticsGranularity = "day"
+)
+
+// Duration длительность одного интервала
+func (g AnalyticsGranularity) Duration() time.Duration {
+	if g == AnalyticsGranularityHour {
+		return time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// Truncate начало интервала, в который попадает t (UTC)
+func (g AnalyticsGranularity) Truncate(t time.Time) time.Time {
+	return t.UTC().Truncate(g.Duration())
+}
+
+// AnalyticsRollup агрегат событий проекта за час или сутки
+type AnalyticsRollup struct {
+	ProjectID      uuid.UUID `db:"project_id" json:"-"`
+	Bucket         time.Time `db:"bucket" json:"bucket"`
+	PageViews      int       `db:"pageviews" json:"pageviews"`
+	UniqueVisitors int       `db:"unique_visitors" json:"unique_visitors"`
+	CTAClicks      int       `db:"cta_clicks" json:"cta_clicks"`
+	PayClicks      int       `db:"pay_clicks" json:"pay_clicks"`
+}
+
+// AnalyticsPeriod временной ряд за период [From, To) и итоги по нему.
+// UniqueVisitors в итогах — сумма по интервалам, посетитель нескольких интервалов учитывается в каждом.
+type AnalyticsPeriod struct {
+	From   time.Time         `json:"from"`
+	To     time.Time         `json:"to"`
+	Points []AnalyticsRollup `json:"points"`
+	Totals AnalyticsStats    `json:"totals"`
+}
+
+// AnalyticsChange изменение итогов относительно предыдущего периода в процентах.
+// nil — в предыдущем периоде было 0 и процент не определён.
+type AnalyticsChange struct {
+	PageViews      *float64 `json:"pageviews"`
+	UniqueVisitors *float64 `json:"unique_visitors"`
+	CTAClicks      *float64 `json:"cta_clicks"`
+	PayClicks      *float64 `json:"pay_clicks"`
+}
+
+// AnalyticsTimeSeries временной ряд и такой же по длине предыдущий период для сравнения
+type AnalyticsTimeSeries struct {
+	Granularity AnalyticsGranularity `json:"granularity"`
+	Current     AnalyticsPeriod      `json:"current"`
+	Previous    AnalyticsPeriod      `json:"previous"`
+	Change      AnalyticsChange      `json:"change"`
+}
+
+// AnalyticsDimension разрез, по которому группируются события
+type AnalyticsDimension string
+
+const (
+	AnalyticsDimensionUTMSource   AnalyticsDimension = "utm_source"
+	AnalyticsDimensionUTMMedium   AnalyticsDimension = "utm_medium"
+	AnalyticsDimensionUTMCampaign AnalyticsDimension = "utm_campaign"
+	AnalyticsDimensionUTMTerm     AnalyticsDimension = "utm_term"
+	AnalyticsDimensionUTMContent  AnalyticsDimension = "utm_content"
+	AnalyticsDimensionReferrer    AnalyticsDimension = "referrer"
+	AnalyticsDimensionBrowser     AnalyticsDimension = "browser"
+	AnalyticsDimensionOS          AnalyticsDimension = "os"
+	AnalyticsDimensionDevice      AnalyticsDimension = "device"
+)
+
+// AnalyticsBreakdownItem показатели событий с одним значением разреза
+type AnalyticsBreakdownItem struct {
+	Value          string `db:"value" json:"value"`
+	PageViews      int    `db:"pageviews" json:"pageviews"`
+	UniqueVisitors int    `db:"unique_visitors" json:"unique_visitors"`
+	CTAClicks      int    `db:"cta_clicks" json:"cta_clicks"`
+	PayClicks      int    `db:"pay_clicks" json:"pay_clicks"`
+}
+
+// AnalyticsBreakdown топ значений разреза за период [From, To)
+type AnalyticsBreakdown struct {
+	Dimension AnalyticsDimension        `json:"dimension"`
+	From      time.Time                 `json:"from"`
+	To        time.Time                 `json:"to"`
+	Items     []*AnalyticsBreakdownItem `json:"items"`
+}
+
+// AnalyticsGoal цель проекта: событие, которое считается конверсией.
+// Path ограничивает цель страницей, например pageview на /thank-you.
+type AnalyticsGoal struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	ProjectID uuid.UUID `db:"project_id" json:"project_id"`
+	Name      string    `db:"name" json:"name"`
+	EventType string    `db:"event_type" json:"event_type"`
+	Path      string    `db:"path" json:"path,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// AnalyticsFunnelStep шаг воронки; пустой Path — событие на любой странице
+type AnalyticsFunnelStep struct {
+	Name      string `json:"name"`
+	EventType string `json:"event_type"`
+	Path      string `json:"path,omitempty"`
+}
+
+// AnalyticsFunnelStepResult посетители, дошедшие до шага, и потери относительно предыдущего.
+// Проценты nil, если база для них равна нулю.
+type AnalyticsFunnelStepResult struct {
+	AnalyticsFunnelStep
+	Visitors int `json:"visitors"`
+	// ConversionRate доля от посетителей первого шага
+	ConversionRate *float64 `json:"conversion_rate"`
+	// StepConversionRate доля от посетителей предыдущего шага
+	StepConversionRate *float64 `json:"step_conversion_rate"`
+	DropOff            int      `json:"drop_off"`
+	DropOffRate        *float64 `json:"drop_off_rate"`
+}
+
+// AnalyticsGoalConversion посетители, достигшие цели, и их доля от всех посетителей
+type AnalyticsGoalConversion struct {
+	Goal           *AnalyticsGoal `json:"goal"`
+	Visitors       int            `json:"visitors"`
+	ConversionRate *float64       `json:"conversion_rate"`
+}
+
+// AnalyticsFunnel воронка за период [From, To) и конверсии целей проекта.
+// Посетитель определяется хэшем суток, поэтому путь, растянутый на несколько дней, не склеивается.
+type AnalyticsFunnel struct {
+	From  time.Time                   `json:"from"`
+	To    time.Time                   `json:"to"`
+	Steps []AnalyticsFunnelStepResult `json:"steps"`
+	Goals []AnalyticsGoalConversion   `json:"goals"`
+}
+
+// VariantControl ключ контрольного варианта — основной схемы проекта
+const VariantControl = "control"
+
+// ProjectVariant вариант схемы проекта для A/B-теста.
+// Weight — доля трафика в процентах; остаток достаётся основной схеме.
+type ProjectVariant struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	ProjectID  uuid.UUID `db:"project_id" json:"project_id"`
+	Name       string    `db:"name" json:"name"`
+	SchemaJSON string    `db:"schema_json" json:"schema_json"`
+	Weight     int       `db:"weight" json:"weight"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Key ключ варианта в cookie, пути сборки и событиях аналитики
+func (v *ProjectVariant) Key() string {
+	return v.ID.String()
+}
+
+// PublishedAsset файл опубликованного сайта и вариант, из сборки которого он отдан
+type PublishedAsset struct {
+	Body        io.ReadCloser
+	ContentType string
+	// Variant пусто, если у деплоя нет эксперимента
+	Variant string
+}
+
+// VariantConversion посетители варианта и сколько из них достигли цели
+type VariantConversion struct {
+	Variant     string `db:"variant" json:"variant"`
+	Visitors    int    `db:"visitors" json:"visitors"`
+	Conversions int    `db:"conversions" json:"conversions"`
+}
+
+// VariantResult итог варианта в эксперименте.
+// Uplift и PValue считаются относительно контрольного варианта; nil — данных для сравнения нет.
+type VariantResult struct {
+	Key            string   `json:"key"`
+	Name           string   `json:"name"`
+	Weight         int      `json:"weight"`
+	Visitors       int      `json:"visitors"`
+	Conversions    int      `json:"conversions"`
+	ConversionRate *float64 `json:"conversion_rate"`
+	Uplift         *float64 `json:"uplift"`
+	PValue         *float64 `json:"p_value"`
+	// Significant разница с контролем значима на уровне 95%
+	Significant bool `json:"significant"`
+}
+
+// ExperimentResults конверсия вариантов в цель за период [From, To)
+type ExperimentResults struct {
+	From     time.Time           `json:"from"`
+	To       time.Time           `json:"to"`
+	Goal     AnalyticsFunnelStep `json:"goal"`
+	Variants []VariantResult     `json:"variants"`
+	// Winner ключ значимо лучшего варианта; пусто — победителя пока нет
+	Winner string `json:"winner,omitempty"`
+}
+
 // ProjectAnalytics аналитика проекта
 type ProjectAnalytics struct {
 	TotalPageViews int `json:"total_page_views"`
@@ -178,6 +734,19 @@ func NewGenerationSession(projectID uuid.UUID, prompt, model string) *Generation
 	}
 }
 
+// NewSchemaRevision создаёт снимок схемы; номер версии назначает репозиторий
+func NewSchemaRevision(projectID uuid.UUID, schemaJSON, source string, authorID, messageID *uuid.UUID) *SchemaRevision {
+	return &SchemaRevision{
+		ID:         uuid.New(),
+		ProjectID:  projectID,
+		SchemaJSON: schemaJSON,
+		Source:     source,
+		AuthorID:   authorID,
+		MessageID:  messageID,
+		CreatedAt:  time.Now(),
+	}
+}
+
 // NewPublishTarget создаёт новую цель публикации
 func NewPublishTarget(projectID uuid.UUID, subdomain string) *PublishTarget {
 	return &PublishTarget{
@@ -185,11 +754,41 @@ func NewPublishTarget(projectID uuid.UUID, subdomain string) *PublishTarget {
 		ProjectID: projectID,
 		Subdomain: subdomain,
 		Status:    PublishStatusDraft,
+		SSLStatus: SSLStatusNone,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 }
 
+// NewDeployment создаёт деплой в статусе pending; номер версии назначает репозиторий
+func NewDeployment(projectID uuid.UUID, subdomain string, authorID *uuid.UUID) *Deployment {
+	return &Deployment{
+		ID:        uuid.New(),
+		ProjectID: projectID,
+		Subdomain: subdomain,
+		Status:    DeploymentStatusPending,
+		AuthorID:  authorID,
+		CreatedAt: time.Now(),
+	}
+}
+
+// NewJob создаёт задачу, готовую к выполнению сразу
+func NewJob(jobType, payload string, userID, projectID *uuid.UUID, maxAttempts int) *Job {
+	now := time.Now()
+	return &Job{
+		ID:          uuid.New(),
+		Type:        jobType,
+		Payload:     payload,
+		Status:      JobStatusQueued,
+		MaxAttempts: maxAttempts,
+		UserID:      userID,
+		ProjectID:   projectID,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
 // NewAnalyticsEvent создаёт новое событие аналитики
 func NewAnalyticsEvent(projectID uuid.UUID, eventType, path, referrer, userAgent, ipAddress string) *AnalyticsEvent {
 	return &AnalyticsEvent{
@@ -204,6 +803,34 @@ func NewAnalyticsEvent(projectID uuid.UUID, eventType, path, referrer, userAgent
 	}
 }
 
+// NewAnalyticsGoal создаёт новую цель проекта
+func NewAnalyticsGoal(projectID uuid.UUID, name, eventType, path string) *AnalyticsGoal {
+	now := time.Now()
+	return &AnalyticsGoal{
+		ID:        uuid.New(),
+		ProjectID: projectID,
+		Name:      name,
+		EventType: eventType,
+		Path:      path,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// NewProjectVariant создаёт новый вариант схемы проекта
+func NewProjectVariant(projectID uuid.UUID, name, schemaJSON string, weight int) *ProjectVariant {
+	now := time.Now()
+	return &ProjectVariant{
+		ID:         uuid.New(),
+		ProjectID:  projectID,
+		Name:       name,
+		SchemaJSON: schemaJSON,
+		Weight:     weight,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
 // NewIntegration создаёт новую интеграцию
 func NewIntegration(projectID uuid.UUID, integrationType IntegrationType, config string) *Integration {
 	return &Integration{
@@ -215,3 +842,60 @@ func NewIntegration(projectID uuid.UUID, integrationType IntegrationType, config
 		UpdatedAt: time.Now(),
 	}
 }
+
+// NewPurchase создаёт запись об оплате
+func NewPurchase(projectID uuid.UUID, provider, externalID string, amount int64, currency, variant string) *Purchase {
+	return &Purchase{
+		ID:         uuid.New(),
+		ProjectID:  projectID,
+		Provider:   provider,
+		ExternalID: externalID,
+		Amount:     amount,
+		Currency:   currency,
+		Variant:    variant,
+		CreatedAt:  time.Now(),
+	}
+}
+
+// NewFormSubmission создаёт заявку из значений полей формы
+func NewFormSubmission(projectID uuid.UUID, formName string, fields map[string]string, path, variant string) (*FormSubmission, error) {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return &FormSubmission{
+		ID:        uuid.New(),
+		ProjectID: projectID,
+		FormName:  formName,
+		Data:      string(data),
+		Path:      path,
+		Variant:   variant,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// NewWebhook создаёт активную подписку
+func NewWebhook(projectID uuid.UUID, url, secret string, events []string) *Webhook {
+	now := time.Now()
+	return &Webhook{
+		ID:        uuid.New(),
+		ProjectID: projectID,
+		URL:       url,
+		Secret:    secret,
+		Events:    events,
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// NewWebhookEvent создаёт событие проекта
+func NewWebhookEvent(projectID uuid.UUID, eventType string, data interface{}) *WebhookEvent {
+	return &WebhookEvent{
+		ID:        uuid.New(),
+		Type:      eventType,
+		ProjectID: projectID,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+}
@@ -20,6 +20,13 @@ func (e *Error) Unwrap() error {
 	return e.Err
 }
 
+// Is сравнивает доменные ошибки по коду, чтобы errors.Is(err, ErrNotFound)
+// срабатывал и для копий, созданных через WithMessage/WithError
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
 func (e *Error) HTTPStatus() int {
 	switch e.Code {
 	case "NOT_FOUND":
@@ -28,6 +35,8 @@ func (e *Error) HTTPStatus() int {
 		return 409
 	case "INVALID_INPUT", "BAD_REQUEST":
 		return 400
+	case "SCHEMA_INVALID":
+		return 422
 	case "UNAUTHORIZED":
 		return 401
 	case "FORBIDDEN":
@@ -102,6 +111,11 @@ var (
 		Message: "AI generation failed",
 	}
 
+	ErrSchemaInvalid = &Error{
+		Code:    "SCHEMA_INVALID",
+		Message: "generated schema does not match page schema",
+	}
+
 	ErrRenderFailed = &Error{
 		Code:    "RENDER_FAILED",
 		Message: "rendering failed",
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -23,6 +24,7 @@ type ProjectRepository interface {
 	Update(ctx context.Context, project *Project) error
 	Delete(ctx context.Context, id string) error
 	UpdateSchema(ctx context.Context, projectID string, schemaJSON string) error
+	UpdateStatus(ctx context.Context, projectID string, status string) error
 }
 
 // PageRepository интерфейс репозитория страниц
@@ -61,10 +63,38 @@ type PublishTargetRepository interface {
 	GetByID(ctx context.Context, id string) (*PublishTarget, error)
 	GetByProjectID(ctx context.Context, projectID string) (*PublishTarget, error)
 	GetBySubdomain(ctx context.Context, subdomain string) (*PublishTarget, error)
+	GetByCustomDomain(ctx context.Context, host string) (*PublishTarget, error)
 	Update(ctx context.Context, target *PublishTarget) error
+	UpdateDomain(ctx context.Context, target *PublishTarget) error
+	ClaimVerifiedDomain(ctx context.Context, target *PublishTarget) error
+	ListVerifiedDomains(ctx context.Context) ([]*PublishTarget, error)
+	SetSSLStatus(ctx context.Context, host, status string) error
+	SetActiveDeployment(ctx context.Context, targetID, deploymentID uuid.UUID) error
 	Delete(ctx context.Context, id string) error
 }
 
+// CertificateRepository интерфейс хранилища TLS-сертификатов и ACME-аккаунтов
+type CertificateRepository interface {
+	Get(ctx context.Context, host string) (*TLSCertificate, error)
+	Save(ctx context.Context, cert *TLSCertificate) error
+	Delete(ctx context.Context, host string) error
+	GetACMEAccount(ctx context.Context, directoryURL string) (*ACMEAccount, error)
+	SaveACMEAccount(ctx context.Context, account *ACMEAccount) error
+	SaveChallenge(ctx context.Context, token, response string) error
+	GetChallenge(ctx context.Context, token string) (string, error)
+	DeleteChallenge(ctx context.Context, token string) error
+	AcquireIssueLease(ctx context.Context, host, holder string, ttl time.Duration) (bool, error)
+	ReleaseIssueLease(ctx context.Context, host, holder string) error
+}
+
+// DeploymentRepository интерфейс репозитория деплоев
+type DeploymentRepository interface {
+	Create(ctx context.Context, deployment *Deployment) error
+	GetByID(ctx context.Context, id string) (*Deployment, error)
+	ListByProject(ctx context.Context, projectID string, limit, offset int) ([]*Deployment, error)
+	Update(ctx context.Context, deployment *Deployment) error
+}
+
 // GenerationSessionRepository интерфейс репозитория сессий генерации
 type GenerationSessionRepository interface {
 	Create(ctx context.Context, session *GenerationSession) error
@@ -81,9 +111,89 @@ type GenerationMessageRepository interface {
 	DeleteBySession(ctx context.Context, sessionID string) error
 }
 
+// SchemaRevisionRepository интерфейс репозитория ревизий схемы
+type SchemaRevisionRepository interface {
+	Create(ctx context.Context, revision *SchemaRevision) error
+	GetByID(ctx context.Context, id string) (*SchemaRevision, error)
+	ListByProject(ctx context.Context, projectID string, limit, offset int) ([]*SchemaRevision, error)
+	GetPrevious(ctx context.Context, projectID string, version int) (*SchemaRevision, error)
+}
+
+// JobRepository интерфейс репозитория фоновых задач
+type JobRepository interface {
+	Create(ctx context.Context, job *Job) error
+	GetByID(ctx context.Context, id string) (*Job, error)
+	Update(ctx context.Context, job *Job) (bool, error)
+	Requeue(ctx context.Context, job *Job) (bool, error)
+	Claim(ctx context.Context, id uuid.UUID) (bool, error)
+	Touch(ctx context.Context, id uuid.UUID, attempt int) (bool, error)
+	NextQueued(ctx context.Context, now time.Time) (uuid.UUID, error)
+	ListStuck(ctx context.Context, before time.Time, limit int) ([]*Job, error)
+}
+
 // AnalyticsRepository интерфейс репозитория аналитики
 type AnalyticsRepository interface {
 	TrackEvent(ctx context.Context, event *AnalyticsEvent) error
+	TrackEvents(ctx context.Context, events []*AnalyticsEvent) error
 	GetStats(ctx context.Context, projectID uuid.UUID) (*AnalyticsStats, error)
 	GetEvents(ctx context.Context, projectID uuid.UUID, limit, offset int) ([]*AnalyticsEvent, error)
+	RollupEvents(ctx context.Context, granularity AnalyticsGranularity, from, to time.Time) error
+	GetRollupWatermark(ctx context.Context, granularity AnalyticsGranularity) (time.Time, error)
+	SetRollupWatermark(ctx context.Context, granularity AnalyticsGranularity, to time.Time) error
+	GetRollups(ctx context.Context, projectID uuid.UUID, granularity AnalyticsGranularity, from, to time.Time) ([]*AnalyticsRollup, error)
+	GetOrCreateSalt(ctx context.Context, day time.Time, candidate []byte) ([]byte, error)
+	DeleteSaltsBefore(ctx context.Context, day time.Time) error
+	DeleteEventsBefore(ctx context.Context, before time.Time) (int64, error)
+	GetBreakdown(ctx context.Context, projectID uuid.UUID, dimension AnalyticsDimension, from, to time.Time, limit int) ([]*AnalyticsBreakdownItem, error)
+	GetFunnel(ctx context.Context, projectID uuid.UUID, steps, goals []AnalyticsFunnelStep, from, to time.Time) ([]int, []int, error)
+	GetVariantConversions(ctx context.Context, projectID uuid.UUID, goal AnalyticsFunnelStep, from, to time.Time) ([]*VariantConversion, error)
+}
+
+// AnalyticsGoalRepository интерфейс репозитория целей аналитики
+type AnalyticsGoalRepository interface {
+	Create(ctx context.Context, goal *AnalyticsGoal) error
+	GetByID(ctx context.Context, id string) (*AnalyticsGoal, error)
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]*AnalyticsGoal, error)
+	Update(ctx context.Context, goal *AnalyticsGoal) error
+	Delete(ctx context.Context, id string) error
+	MarkReached(ctx context.Context, goalID uuid.UUID, visitorHash string, day time.Time) (bool, error)
+	DeleteReachedBefore(ctx context.Context, day time.Time) error
+}
+
+// ProjectVariantRepository интерфейс репозитория вариантов A/B-теста
+type ProjectVariantRepository interface {
+	Create(ctx context.Context, variant *ProjectVariant) error
+	GetByID(ctx context.Context, id string) (*ProjectVariant, error)
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]*ProjectVariant, error)
+	Update(ctx context.Context, variant *ProjectVariant) error
+	Delete(ctx context.Context, id string) error
+	DeleteByProject(ctx context.Context, projectID uuid.UUID) error
+}
+
+// PurchaseRepository интерфейс репозитория оплат
+type PurchaseRepository interface {
+	Create(ctx context.Context, purchase *Purchase) error
+	ListByProject(ctx context.Context, projectID uuid.UUID, limit int) ([]*Purchase, error)
+}
+
+// FormSubmissionRepository интерфейс репозитория заявок
+type FormSubmissionRepository interface {
+	Create(ctx context.Context, submission *FormSubmission) error
+	ListByProject(ctx context.Context, projectID uuid.UUID, query FormSubmissionsQuery) ([]*FormSubmission, error)
+}
+
+// WebhookRepository интерфейс репозитория подписок на события проекта
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *Webhook) error
+	GetByID(ctx context.Context, id string) (*Webhook, error)
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]*Webhook, error)
+	Update(ctx context.Context, webhook *Webhook) error
+	Delete(ctx context.Context, id string) error
+}
+
+// WebhookDeliveryRepository интерфейс журнала доставок вебхуков
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *WebhookDelivery) error
+	ListByWebhook(ctx context.Context, webhookID uuid.UUID, limit, offset int) ([]*WebhookDelivery, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
 }
@@ -2,6 +2,8 @@ package domain
 
 import (
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // Auth requests and responses
@@ -46,7 +48,94 @@ type PublishRequest struct {
 
 // Analytics requests and responses
 type TrackEventRequest struct {
-	EventType string `json:"event_type" binding:"required"`
-	Path      string `json:"path" binding:"required"`
-	Referrer  string `json:"referrer"`
+	ProjectID uuid.UUID `json:"-"`
+	EventType string    `json:"event_type" binding:"required"`
+	Path      string    `json:"path"`
+	Referrer  string    `json:"referrer"`
+	// URL полный адрес страницы: из него берутся UTM-метки
+	URL string `json:"url"`
+	// Variant вариант A/B-теста, вшитый в analytics.js сборки
+	Variant string `json:"variant"`
+	// Заполняются обработчиком из HTTP-запроса
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+// AnalyticsTimeSeriesQuery параметры временного ряда; нулевые From/To заменяются значениями по умолчанию
+type AnalyticsTimeSeriesQuery struct {
+	Granularity AnalyticsGranularity
+	From        time.Time
+	To          time.Time
+}
+
+// AnalyticsBreakdownQuery параметры разбивки; нулевые значения заменяются значениями по умолчанию
+type AnalyticsBreakdownQuery struct {
+	Dimension AnalyticsDimension
+	From      time.Time
+	To        time.Time
+	Limit     int
+}
+
+// AnalyticsGoalRequest создание или изменение цели проекта
+type AnalyticsGoalRequest struct {
+	Name      string `json:"name"`
+	EventType string `json:"event_type"`
+	Path      string `json:"path"`
+}
+
+// ProjectVariantRequest создание или изменение варианта A/B-теста.
+// Пустая схема при создании — копия основной схемы, при изменении — схема не меняется.
+type ProjectVariantRequest struct {
+	Name       string `json:"name"`
+	SchemaJSON string `json:"schema_json"`
+	Weight     int    `json:"weight"`
+}
+
+// ExperimentResultsQuery период и цель отчёта по эксперименту; без GoalID целью считается pay_click
+type ExperimentResultsQuery struct {
+	From   time.Time
+	To     time.Time
+	GoalID *uuid.UUID
+}
+
+// AnalyticsRangeQuery период отчёта; нулевые From/To заменяются значениями по умолчанию
+type AnalyticsRangeQuery struct {
+	From time.Time
+	To   time.Time
+}
+
+// IntegrationRequest создание или изменение интеграции; тип после создания не меняется.
+// При изменении пустые поля оставляют текущие значения.
+type IntegrationRequest struct {
+	Type          string `json:"type"`
+	PaymentURL    string `json:"payment_url"`
+	WebhookSecret string `json:"webhook_secret"`
+}
+
+// FormSubmissionRequest заявка с опубликованного сайта.
+// Honeypot — скрытое поле, которое заполняют только боты; Token — подписанное сервером время выдачи формы.
+type FormSubmissionRequest struct {
+	ProjectID uuid.UUID
+	Form      string
+	Fields    map[string]string
+	Path      string
+	Variant   string
+	Honeypot  string
+	Token     string
+}
+
+// FormSubmissionsQuery выборка заявок; пустой Form — заявки всех форм
+type FormSubmissionsQuery struct {
+	Form   string
+	Limit  int
+	Offset int
+}
+
+// WebhookRequest подписка на события проекта. При обновлении пустой URL и nil-поля
+// не меняются, RotateSecret выдаёт новый секрет подписи.
+type WebhookRequest struct {
+	URL          string
+	Events       []string
+	Active       *bool
+	RotateSecret bool
 }
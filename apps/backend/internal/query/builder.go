@@ -1,6 +1,7 @@
 package query
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -114,3 +115,47 @@ func (b *Builder) QueryRow(query squirrel.Sqlizer) *sql.Row {
 
 	return b.db.QueryRow(sql, args...)
 }
+
+// Tx транзакция, в которой выполняются запросы билдера
+type Tx struct {
+	tx *sql.Tx
+}
+
+// InTransaction выполняет fn в транзакции: фиксирует её, если fn вернула nil, иначе откатывает
+func (b *Builder) InTransaction(ctx context.Context, fn func(tx *Tx) error) error {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(&Tx{tx: tx}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Execute выполняет запрос в транзакции
+func (t *Tx) Execute(query squirrel.Sqlizer) (sql.Result, error) {
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	return t.tx.Exec(sql, args...)
+}
+
+// QueryRow выполняет SELECT запрос для одной строки в транзакции
+func (t *Tx) QueryRow(query squirrel.Sqlizer) *sql.Row {
+	sql, args, err := query.ToSql()
+	if err != nil {
+		// Возвращаем row с ошибкой
+		return t.tx.QueryRow("SELECT 1 WHERE 1=0")
+	}
+
+	return t.tx.QueryRow(sql, args...)
+}
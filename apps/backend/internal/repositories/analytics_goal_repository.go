@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/query"
+)
+
+// AnalyticsGoalRepository интерфейс репозитория целей аналитики
+type AnalyticsGoalRepository interface {
+	Create(ctx context.Context, goal *domain.AnalyticsGoal) error
+	GetByID(ctx context.Context, id string) (*domain.AnalyticsGoal, error)
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]*domain.AnalyticsGoal, error)
+	Update(ctx context.Context, goal *domain.AnalyticsGoal) error
+	Delete(ctx context.Context, id string) error
+	MarkReached(ctx context.Context, goalID uuid.UUID, visitorHash string, day time.Time) (bool, error)
+	DeleteReachedBefore(ctx context.Context, day time.Time) error
+}
+
+type analyticsGoalRepository struct {
+	qb *query.Builder
+}
+
+// NewAnalyticsGoalRepository создаёт репозиторий целей аналитики
+func NewAnalyticsGoalRepository(qb *query.Builder) AnalyticsGoalRepository {
+	return &analyticsGoalRepository{qb: qb}
+}
+
+var analyticsGoalColumns = []string{"id", "project_id", "name", "event_type", "COALESCE(path, '')", "created_at", "updated_at"}
+
+// Create сохраняет цель
+func (r *analyticsGoalRepository) Create(ctx context.Context, goal *domain.AnalyticsGoal) error {
+	query := r.qb.Insert("analytics_goals").
+		Columns("id", "project_id", "name", "event_type", "path", "created_at", "updated_at").
+		Values(goal.ID, goal.ProjectID, goal.Name, goal.EventType, goal.Path, goal.CreatedAt, goal.UpdatedAt)
+
+	_, err := r.qb.Execute(query)
+	return err
+}
+
+// GetByID получает цель по ID
+func (r *analyticsGoalRepository) GetByID(ctx context.Context, id string) (*domain.AnalyticsGoal, error) {
+	goalID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrBadRequest.WithMessage("invalid goal ID format")
+	}
+
+	query := r.qb.Select(analyticsGoalColumns...).
+		From("analytics_goals").
+		Where(squirrel.Eq{"id": goalID})
+
+	goal, err := scanAnalyticsGoal(r.qb.QueryRow(query))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound.WithMessage("goal not found")
+		}
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return goal, nil
+}
+
+// ListByProject возвращает цели проекта в порядке создания
+func (r *analyticsGoalRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]*domain.AnalyticsGoal, error) {
+	query := r.qb.Select(analyticsGoalColumns...).
+		From("analytics_goals").
+		Where(squirrel.Eq{"project_id": projectID}).
+		OrderBy("created_at ASC")
+
+	rows, err := r.qb.Query(query)
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	defer rows.Close()
+
+	var goals []*domain.AnalyticsGoal
+	for rows.Next() {
+		goal, err := scanAnalyticsGoal(rows)
+		if err != nil {
+			return nil, domain.ErrInternal.WithError(err)
+		}
+		goals = append(goals, goal)
+	}
+
+	return goals, rows.Err()
+}
+
+// Update обновляет цель
+func (r *analyticsGoalRepository) Update(ctx context.Context, goal *domain.AnalyticsGoal) error {
+	query := r.qb.Update("analytics_goals").
+		Set("name", goal.Name).
+		Set("event_type", goal.EventType).
+		Set("path", goal.Path).
+		Set("updated_at", goal.UpdatedAt).
+		Where(squirrel.Eq{"id": goal.ID})
+
+	_, err := r.qb.Execute(query)
+	return err
+}
+
+// Delete удаляет цель
+func (r *analyticsGoalRepository) Delete(ctx context.Context, id string) error {
+	goalID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrBadRequest.WithMessage("invalid goal ID format")
+	}
+
+	query := r.qb.Delete("analytics_goals").
+		Where(squirrel.Eq{"id": goalID})
+
+	_, err = r.qb.Execute(query)
+	return err
+}
+
+// MarkReached отмечает достижение цели посетителем за сутки day.
+// Возвращает false, если отметка уже была: событие goal.reached за эти сутки уже отправлено.
+func (r *analyticsGoalRepository) MarkReached(ctx context.Context, goalID uuid.UUID, visitorHash string, day time.Time) (bool, error) {
+	query := r.qb.Insert("analytics_goal_reached").
+		Columns("goal_id", "visitor_hash", "day").
+		Values(goalID, visitorHash, day.UTC().Format(time.DateOnly)).
+		Suffix("ON CONFLICT (goal_id, visitor_hash, day) DO NOTHING")
+
+	result, err := r.qb.Execute(query)
+	if err != nil {
+		return false, domain.ErrInternal.WithError(err)
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, domain.ErrInternal.WithError(err)
+	}
+	return inserted > 0, nil
+}
+
+// DeleteReachedBefore удаляет отметки достижения целей за сутки раньше day
+func (r *analyticsGoalRepository) DeleteReachedBefore(ctx context.Context, day time.Time) error {
+	query := r.qb.Delete("analytics_goal_reached").
+		Where(squirrel.Lt{"day": day.UTC().Format(time.DateOnly)})
+
+	if _, err := r.qb.Execute(query); err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+	return nil
+}
+
+func scanAnalyticsGoal(row rowScanner) (*domain.AnalyticsGoal, error) {
+	var goal domain.AnalyticsGoal
+	err := row.Scan(&goal.ID, &goal.ProjectID, &goal.Name, &goal.EventType, &goal.Path, &goal.CreatedAt, &goal.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+// Ensure interface compliance at compile time
+var _ AnalyticsGoalRepository = (*analyticsGoalRepository)(nil)
@@ -2,6 +2,9 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
@@ -12,8 +15,19 @@ import (
 // AnalyticsRepository интерфейс репозитория аналитики
 type AnalyticsRepository interface {
 	TrackEvent(ctx context.Context, event *domain.AnalyticsEvent) error
+	TrackEvents(ctx context.Context, events []*domain.AnalyticsEvent) error
 	GetStats(ctx context.Context, projectID uuid.UUID) (*domain.AnalyticsStats, error)
 	GetEvents(ctx context.Context, projectID uuid.UUID, limit, offset int) ([]*domain.AnalyticsEvent, error)
+	RollupEvents(ctx context.Context, granularity domain.AnalyticsGranularity, from, to time.Time) error
+	GetRollupWatermark(ctx context.Context, granularity domain.AnalyticsGranularity) (time.Time, error)
+	SetRollupWatermark(ctx context.Context, granularity domain.AnalyticsGranularity, to time.Time) error
+	GetRollups(ctx context.Context, projectID uuid.UUID, granularity domain.AnalyticsGranularity, from, to time.Time) ([]*domain.AnalyticsRollup, error)
+	GetOrCreateSalt(ctx context.Context, day time.Time, candidate []byte) ([]byte, error)
+	DeleteSaltsBefore(ctx context.Context, day time.Time) error
+	DeleteEventsBefore(ctx context.Context, before time.Time) (int64, error)
+	GetBreakdown(ctx context.Context, projectID uuid.UUID, dimension domain.AnalyticsDimension, from, to time.Time, limit int) ([]*domain.AnalyticsBreakdownItem, error)
+	GetFunnel(ctx context.Context, projectID uuid.UUID, steps, goals []domain.AnalyticsFunnelStep, from, to time.Time) ([]int, []int, error)
+	GetVariantConversions(ctx context.Context, projectID uuid.UUID, goal domain.AnalyticsFunnelStep, from, to time.Time) ([]*domain.VariantConversion, error)
 }
 
 // analyticsRepository реализация репозитория аналитики
@@ -26,11 +40,41 @@ func NewAnalyticsRepository(qb *query.Builder) AnalyticsRepository {
 	return &analyticsRepository{qb: qb}
 }
 
+var analyticsEventColumns = []string{
+	"id", "project_id", "event_type", "path", "referrer", "user_agent", "ip_address", "visitor_hash",
+	"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content",
+	"referrer_domain", "browser", "os", "device_type", "variant", "created_at",
+}
+
+func analyticsEventValues(event *domain.AnalyticsEvent) []interface{} {
+	return []interface{}{
+		event.ID, event.ProjectID, event.EventType, event.Path, event.Referrer, event.UserAgent, event.IPAddress, event.VisitorHash,
+		event.UTMSource, event.UTMMedium, event.UTMCampaign, event.UTMTerm, event.UTMContent,
+		event.ReferrerDomain, event.Browser, event.OS, event.DeviceType, event.Variant, event.CreatedAt,
+	}
+}
+
 // TrackEvent отслеживает событие
 func (r *analyticsRepository) TrackEvent(ctx context.Context, event *domain.AnalyticsEvent) error {
 	query := r.qb.Insert("analytics_events").
-		Columns("id", "project_id", "event_type", "path", "referrer", "user_agent", "ip_address", "created_at").
-		Values(event.ID, event.ProjectID, event.EventType, event.Path, event.Referrer, event.UserAgent, event.IPAddress, event.CreatedAt)
+		Columns(analyticsEventColumns...).
+		Values(analyticsEventValues(event)...)
+
+	_, err := r.qb.Execute(query)
+	return err
+}
+
+// TrackEvents сохраняет пачку событий одним INSERT
+func (r *analyticsRepository) TrackEvents(ctx context.Context, events []*domain.AnalyticsEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	query := r.qb.Insert("analytics_events").
+		Columns(analyticsEventColumns...)
+	for _, event := range events {
+		query = query.Values(analyticsEventValues(event)...)
+	}
 
 	_, err := r.qb.Execute(query)
 	return err
@@ -53,7 +97,7 @@ func (r *analyticsRepository) GetStats(ctx context.Context, projectID uuid.UUID)
 		sql = `
 			SELECT 
 				COUNT(CASE WHEN event_type = 'pageview' THEN 1 END) as total_page_views,
-				COUNT(DISTINCT CASE WHEN event_type = 'pageview' THEN ip_address END) as unique_visitors,
+				COUNT(DISTINCT CASE WHEN event_type = 'pageview' THEN COALESCE(visitor_hash, ip_address) END) as unique_visitors,
 				COUNT(CASE WHEN event_type = 'cta_click' THEN 1 END) as cta_clicks,
 				COUNT(CASE WHEN event_type = 'pay_click' THEN 1 END) as pay_clicks
 			FROM analytics_events 
@@ -63,7 +107,7 @@ func (r *analyticsRepository) GetStats(ctx context.Context, projectID uuid.UUID)
 		sql = `
 			SELECT 
 				COUNT(CASE WHEN event_type = 'pageview' THEN 1 END) as total_page_views,
-				COUNT(DISTINCT CASE WHEN event_type = 'pageview' THEN ip_address END) as unique_visitors,
+				COUNT(DISTINCT CASE WHEN event_type = 'pageview' THEN COALESCE(visitor_hash, ip_address) END) as unique_visitors,
 				COUNT(CASE WHEN event_type = 'cta_click' THEN 1 END) as cta_clicks,
 				COUNT(CASE WHEN event_type = 'pay_click' THEN 1 END) as pay_clicks
 			FROM analytics_events 
@@ -73,7 +117,7 @@ func (r *analyticsRepository) GetStats(ctx context.Context, projectID uuid.UUID)
 		sql = `
 			SELECT 
 				COUNT(CASE WHEN event_type = 'pageview' THEN 1 END) as total_page_views,
-				COUNT(DISTINCT CASE WHEN event_type = 'pageview' THEN ip_address END) as unique_visitors,
+				COUNT(DISTINCT CASE WHEN event_type = 'pageview' THEN COALESCE(visitor_hash, ip_address) END) as unique_visitors,
 				COUNT(CASE WHEN event_type = 'cta_click' THEN 1 END) as cta_clicks,
 				COUNT(CASE WHEN event_type = 'pay_click' THEN 1 END) as pay_clicks
 			FROM analytics_events 
@@ -97,7 +141,14 @@ func (r *analyticsRepository) GetStats(ctx context.Context, projectID uuid.UUID)
 
 // GetEvents получает события аналитики
 func (r *analyticsRepository) GetEvents(ctx context.Context, projectID uuid.UUID, limit, offset int) ([]*domain.AnalyticsEvent, error) {
-	query := r.qb.Select("id", "project_id", "event_type", "path", "referrer", "user_agent", "ip_address", "created_at").
+	// Старые события без хэша и разрезов, а новые без сохранённого IP
+	query := r.qb.Select(
+		"id", "project_id", "event_type", "path",
+		"COALESCE(referrer, '')", "COALESCE(user_agent, '')", "COALESCE(ip_address, '')", "COALESCE(visitor_hash, '')",
+		"COALESCE(utm_source, '')", "COALESCE(utm_medium, '')", "COALESCE(utm_campaign, '')", "COALESCE(utm_term, '')", "COALESCE(utm_content, '')",
+		"COALESCE(referrer_domain, '')", "COALESCE(browser, '')", "COALESCE(os, '')", "COALESCE(device_type, '')",
+		"COALESCE(variant, '')", "created_at",
+	).
 		From("analytics_events").
 		Where(squirrel.Eq{"project_id": projectID}).
 		OrderBy("created_at DESC").
@@ -113,7 +164,13 @@ func (r *analyticsRepository) GetEvents(ctx context.Context, projectID uuid.UUID
 	var events []*domain.AnalyticsEvent
 	for rows.Next() {
 		var event domain.AnalyticsEvent
-		err := rows.Scan(&event.ID, &event.ProjectID, &event.EventType, &event.Path, &event.Referrer, &event.UserAgent, &event.IPAddress, &event.CreatedAt)
+		err := rows.Scan(
+			&event.ID, &event.ProjectID, &event.EventType, &event.Path,
+			&event.Referrer, &event.UserAgent, &event.IPAddress, &event.VisitorHash,
+			&event.UTMSource, &event.UTMMedium, &event.UTMCampaign, &event.UTMTerm, &event.UTMContent,
+			&event.ReferrerDomain, &event.Browser, &event.OS, &event.DeviceType,
+			&event.Variant, &event.CreatedAt,
+		)
 		if err != nil {
 			return nil, domain.ErrInternal.WithError(err)
 		}
@@ -122,3 +179,349 @@ func (r *analyticsRepository) GetEvents(ctx context.Context, projectID uuid.UUID
 
 	return events, nil
 }
+
+// rollupTable таблица агрегатов для шага; имя таблицы не берётся из ввода пользователя
+func rollupTable(granularity domain.AnalyticsGranularity) (string, error) {
+	switch granularity {
+	case domain.AnalyticsGranularityHour:
+		return "analytics_rollups_hourly", nil
+	case domain.AnalyticsGranularityDay:
+		return "analytics_rollups_daily", nil
+	default:
+		return "", domain.ErrBadRequest.WithMessage(fmt.Sprintf("unknown granularity %q", granularity))
+	}
+}
+
+// RollupEvents пересчитывает агрегаты за [from, to). from должен совпадать с началом интервала,
+// иначе первый интервал перезапишется неполными данными.
+func (r *analyticsRepository) RollupEvents(ctx context.Context, granularity domain.AnalyticsGranularity, from, to time.Time) error {
+	table, err := rollupTable(granularity)
+	if err != nil {
+		return err
+	}
+	if r.qb.GetDialect() != query.PostgreSQL {
+		return domain.ErrInternal.WithMessage("analytics rollups require PostgreSQL")
+	}
+
+	// granularity подставляется как литерал: date_trunc в GROUP BY должен совпадать с SELECT
+	sql := fmt.Sprintf(`
+		INSERT INTO %[1]s (project_id, bucket, pageviews, unique_visitors, cta_clicks, pay_clicks, updated_at)
+		SELECT
+			project_id,
+			date_trunc('%[2]s', created_at),
+			COUNT(*) FILTER (WHERE event_type = 'pageview'),
+			COUNT(DISTINCT COALESCE(visitor_hash, ip_address)) FILTER (WHERE event_type = 'pageview'),
+			COUNT(*) FILTER (WHERE event_type = 'cta_click'),
+			COUNT(*) FILTER (WHERE event_type = 'pay_click'),
+			NOW()
+		FROM analytics_events
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY project_id, date_trunc('%[2]s', created_at)
+		ON CONFLICT (project_id, bucket) DO UPDATE SET
+			pageviews = EXCLUDED.pageviews,
+			unique_visitors = EXCLUDED.unique_visitors,
+			cta_clicks = EXCLUDED.cta_clicks,
+			pay_clicks = EXCLUDED.pay_clicks,
+			updated_at = EXCLUDED.updated_at
+	`, table, granularity)
+
+	if _, err := r.qb.GetDB().ExecContext(ctx, sql, from.UTC(), to.UTC()); err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+
+	return nil
+}
+
+// GetRollupWatermark возвращает момент, до которого агрегаты уже пересчитаны; нулевое время — пересчёта ещё не было
+func (r *analyticsRepository) GetRollupWatermark(ctx context.Context, granularity domain.AnalyticsGranularity) (time.Time, error) {
+	query := r.qb.Select("rolled_up_to").
+		From("analytics_rollup_watermarks").
+		Where(squirrel.Eq{"granularity": string(granularity)})
+
+	var watermark time.Time
+	if err := r.qb.QueryRow(query).Scan(&watermark); err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, nil
+		}
+		return time.Time{}, domain.ErrInternal.WithError(err)
+	}
+
+	return watermark.UTC(), nil
+}
+
+// SetRollupWatermark запоминает, что агрегаты пересчитаны до to
+func (r *analyticsRepository) SetRollupWatermark(ctx context.Context, granularity domain.AnalyticsGranularity, to time.Time) error {
+	query := r.qb.Insert("analytics_rollup_watermarks").
+		Columns("granularity", "rolled_up_to", "updated_at").
+		Values(string(granularity), to.UTC(), time.Now().UTC()).
+		Suffix("ON CONFLICT (granularity) DO UPDATE SET rolled_up_to = EXCLUDED.rolled_up_to, updated_at = EXCLUDED.updated_at")
+
+	if _, err := r.qb.Execute(query); err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+	return nil
+}
+
+// GetRollups возвращает агрегаты проекта за [from, to) по возрастанию времени
+func (r *analyticsRepository) GetRollups(ctx context.Context, projectID uuid.UUID, granularity domain.AnalyticsGranularity, from, to time.Time) ([]*domain.AnalyticsRollup, error) {
+	table, err := rollupTable(granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	query := r.qb.Select("project_id", "bucket", "pageviews", "unique_visitors", "cta_clicks", "pay_clicks").
+		From(table).
+		Where(squirrel.Eq{"project_id": projectID}).
+		Where(squirrel.GtOrEq{"bucket": from.UTC()}).
+		Where(squirrel.Lt{"bucket": to.UTC()}).
+		OrderBy("bucket ASC")
+
+	rows, err := r.qb.Query(query)
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	defer rows.Close()
+
+	var rollups []*domain.AnalyticsRollup
+	for rows.Next() {
+		var rollup domain.AnalyticsRollup
+		if err := rows.Scan(&rollup.ProjectID, &rollup.Bucket, &rollup.PageViews, &rollup.UniqueVisitors, &rollup.CTAClicks, &rollup.PayClicks); err != nil {
+			return nil, domain.ErrInternal.WithError(err)
+		}
+		rollups = append(rollups, &rollup)
+	}
+
+	return rollups, rows.Err()
+}
+
+// GetOrCreateSalt возвращает соль суток day. Если её ещё нет, сохраняет candidate;
+// при гонке нескольких инстансов все получают соль, записанную первым.
+func (r *analyticsRepository) GetOrCreateSalt(ctx context.Context, day time.Time, candidate []byte) ([]byte, error) {
+	date := day.UTC().Format(time.DateOnly)
+
+	insert := r.qb.Insert("analytics_salts").
+		Columns("day", "salt", "created_at").
+		Values(date, candidate, time.Now()).
+		Suffix("ON CONFLICT (day) DO NOTHING")
+	if _, err := r.qb.Execute(insert); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	query := r.qb.Select("salt").
+		From("analytics_salts").
+		Where(squirrel.Eq{"day": date})
+
+	var salt []byte
+	if err := r.qb.QueryRow(query).Scan(&salt); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return salt, nil
+}
+
+// DeleteSaltsBefore удаляет соли суток раньше day
+func (r *analyticsRepository) DeleteSaltsBefore(ctx context.Context, day time.Time) error {
+	query := r.qb.Delete("analytics_salts").
+		Where(squirrel.Lt{"day": day.UTC().Format(time.DateOnly)})
+
+	if _, err := r.qb.Execute(query); err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+	return nil
+}
+
+// DeleteEventsBefore удаляет сырые события старше before; агрегаты остаются
+func (r *analyticsRepository) DeleteEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := r.qb.Delete("analytics_events").
+		Where(squirrel.Lt{"created_at": before.UTC()})
+
+	result, err := r.qb.Execute(query)
+	if err != nil {
+		return 0, domain.ErrInternal.WithError(err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, domain.ErrInternal.WithError(err)
+	}
+	return deleted, nil
+}
+
+// breakdownColumn колонка событий для разреза; имя колонки не берётся из ввода пользователя
+func breakdownColumn(dimension domain.AnalyticsDimension) (string, error) {
+	switch dimension {
+	case domain.AnalyticsDimensionUTMSource:
+		return "utm_source", nil
+	case domain.AnalyticsDimensionUTMMedium:
+		return "utm_medium", nil
+	case domain.AnalyticsDimensionUTMCampaign:
+		return "utm_campaign", nil
+	case domain.AnalyticsDimensionUTMTerm:
+		return "utm_term", nil
+	case domain.AnalyticsDimensionUTMContent:
+		return "utm_content", nil
+	case domain.AnalyticsDimensionReferrer:
+		return "referrer_domain", nil
+	case domain.AnalyticsDimensionBrowser:
+		return "browser", nil
+	case domain.AnalyticsDimensionOS:
+		return "os", nil
+	case domain.AnalyticsDimensionDevice:
+		return "device_type", nil
+	default:
+		return "", domain.ErrBadRequest.WithMessage(fmt.Sprintf("unknown dimension %q", dimension))
+	}
+}
+
+// GetBreakdown возвращает limit самых частых значений разреза за [from, to) по числу просмотров.
+// NULL и пустая строка попадают в одну группу с пустым значением.
+func (r *analyticsRepository) GetBreakdown(ctx context.Context, projectID uuid.UUID, dimension domain.AnalyticsDimension, from, to time.Time, limit int) ([]*domain.AnalyticsBreakdownItem, error) {
+	column, err := breakdownColumn(dimension)
+	if err != nil {
+		return nil, err
+	}
+	value := fmt.Sprintf("COALESCE(%s, '')", column)
+
+	query := r.qb.Select(
+		value+" AS value",
+		"COUNT(CASE WHEN event_type = 'pageview' THEN 1 END) AS pageviews",
+		"COUNT(DISTINCT CASE WHEN event_type = 'pageview' THEN COALESCE(visitor_hash, ip_address) END) AS unique_visitors",
+		"COUNT(CASE WHEN event_type = 'cta_click' THEN 1 END) AS cta_clicks",
+		"COUNT(CASE WHEN event_type = 'pay_click' THEN 1 END) AS pay_clicks",
+	).
+		From("analytics_events").
+		Where(squirrel.Eq{"project_id": projectID}).
+		Where(squirrel.GtOrEq{"created_at": from.UTC()}).
+		Where(squirrel.Lt{"created_at": to.UTC()}).
+		GroupBy(value).
+		OrderBy("pageviews DESC", "pay_clicks DESC", "value ASC").
+		Limit(uint64(limit))
+
+	rows, err := r.qb.Query(query)
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	defer rows.Close()
+
+	var items []*domain.AnalyticsBreakdownItem
+	for rows.Next() {
+		var item domain.AnalyticsBreakdownItem
+		if err := rows.Scan(&item.Value, &item.PageViews, &item.UniqueVisitors, &item.CTAClicks, &item.PayClicks); err != nil {
+			return nil, domain.ErrInternal.WithError(err)
+		}
+		items = append(items, &item)
+	}
+
+	return items, rows.Err()
+}
+
+// GetFunnel считает посетителей, прошедших шаги воронки по порядку за [from, to),
+// и посетителей, достигших каждой из целей после первого шага, — всё одним запросом.
+// Для каждого посетителя берётся первое событие каждого шага; шаг засчитан, если оно
+// не раньше первого события предыдущего шага.
+func (r *analyticsRepository) GetFunnel(ctx context.Context, projectID uuid.UUID, steps, goals []domain.AnalyticsFunnelStep, from, to time.Time) ([]int, []int, error) {
+	if len(steps) == 0 {
+		return nil, nil, nil
+	}
+
+	visitor := "COALESCE(visitor_hash, ip_address)"
+	visitors := squirrel.Select(visitor + " AS visitor")
+	for i, step := range steps {
+		visitors = visitors.Column(funnelStepFirstEvent(step, fmt.Sprintf("step_%d", i)))
+	}
+	for i, goal := range goals {
+		visitors = visitors.Column(funnelStepFirstEvent(goal, fmt.Sprintf("goal_%d", i)))
+	}
+	visitors = visitors.
+		From("analytics_events").
+		Where(squirrel.Eq{"project_id": projectID}).
+		Where(squirrel.GtOrEq{"created_at": from.UTC()}).
+		Where(squirrel.Lt{"created_at": to.UTC()}).
+		Where(fmt.Sprintf("COALESCE(%s, '') <> ''", visitor)).
+		GroupBy(visitor)
+
+	query := r.qb.Select().FromSelect(visitors, "visitors")
+	reached := "step_0 IS NOT NULL"
+	for i := range steps {
+		if i > 0 {
+			reached += fmt.Sprintf(" AND step_%d >= step_%d", i, i-1)
+		}
+		query = query.Column(fmt.Sprintf("COUNT(CASE WHEN %s THEN 1 END)", reached))
+	}
+	for i := range goals {
+		query = query.Column(fmt.Sprintf("COUNT(CASE WHEN step_0 IS NOT NULL AND goal_%d >= step_0 THEN 1 END)", i))
+	}
+
+	counts := make([]int, len(steps))
+	goalCounts := make([]int, len(goals))
+	dest := make([]interface{}, 0, len(steps)+len(goals))
+	for i := range counts {
+		dest = append(dest, &counts[i])
+	}
+	for i := range goalCounts {
+		dest = append(dest, &goalCounts[i])
+	}
+	if err := r.qb.QueryRow(query).Scan(dest...); err != nil {
+		return nil, nil, domain.ErrInternal.WithError(err)
+	}
+
+	return counts, goalCounts, nil
+}
+
+// funnelStepFirstEvent колонка с первым событием посетителя, подходящим под шаг
+func funnelStepFirstEvent(step domain.AnalyticsFunnelStep, alias string) squirrel.Sqlizer {
+	condition, args := "event_type = ?", []interface{}{step.EventType}
+	if step.Path != "" {
+		condition += " AND path = ?"
+		args = append(args, step.Path)
+	}
+	return squirrel.Expr(fmt.Sprintf("MIN(CASE WHEN %s THEN created_at END) AS %s", condition, alias), args...)
+}
+
+// GetVariantConversions считает по вариантам A/B-теста посетителей с pageview за [from, to)
+// и сколько из них достигли цели. События без варианта не учитываются.
+func (r *analyticsRepository) GetVariantConversions(ctx context.Context, projectID uuid.UUID, goal domain.AnalyticsFunnelStep, from, to time.Time) ([]*domain.VariantConversion, error) {
+	condition, args := "event_type = ?", []interface{}{goal.EventType}
+	if goal.Path != "" {
+		condition += " AND path = ?"
+		args = append(args, goal.Path)
+	}
+
+	visitor := "COALESCE(visitor_hash, ip_address)"
+	visitors := squirrel.Select("variant").
+		Column(squirrel.Expr("MAX(CASE WHEN event_type = ? THEN 1 ELSE 0 END) AS viewed", domain.AnalyticsEventPageview)).
+		Column(squirrel.Expr(fmt.Sprintf("MAX(CASE WHEN %s THEN 1 ELSE 0 END) AS converted", condition), args...)).
+		From("analytics_events").
+		Where(squirrel.Eq{"project_id": projectID}).
+		Where(squirrel.GtOrEq{"created_at": from.UTC()}).
+		Where(squirrel.Lt{"created_at": to.UTC()}).
+		Where("COALESCE(variant, '') <> ''").
+		Where(fmt.Sprintf("COALESCE(%s, '') <> ''", visitor)).
+		GroupBy("variant", visitor)
+
+	query := r.qb.Select(
+		"variant",
+		"SUM(viewed) AS visitors",
+		"SUM(CASE WHEN viewed = 1 AND converted = 1 THEN 1 ELSE 0 END) AS conversions",
+	).
+		FromSelect(visitors, "visitors").
+		GroupBy("variant").
+		OrderBy("variant ASC")
+
+	rows, err := r.qb.Query(query)
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	defer rows.Close()
+
+	var result []*domain.VariantConversion
+	for rows.Next() {
+		var item domain.VariantConversion
+		if err := rows.Scan(&item.Variant, &item.Visitors, &item.Conversions); err != nil {
+			return nil, domain.ErrInternal.WithError(err)
+		}
+		result = append(result, &item)
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,176 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/query"
+)
+
+// CertificateRepository интерфейс хранилища TLS-сертификатов и ACME-аккаунтов
+type CertificateRepository interface {
+	Get(ctx context.Context, host string) (*domain.TLSCertificate, error)
+	Save(ctx context.Context, cert *domain.TLSCertificate) error
+	Delete(ctx context.Context, host string) error
+	GetACMEAccount(ctx context.Context, directoryURL string) (*domain.ACMEAccount, error)
+	SaveACMEAccount(ctx context.Context, account *domain.ACMEAccount) error
+	SaveChallenge(ctx context.Context, token, response string) error
+	GetChallenge(ctx context.Context, token string) (string, error)
+	DeleteChallenge(ctx context.Context, token string) error
+	AcquireIssueLease(ctx context.Context, host, holder string, ttl time.Duration) (bool, error)
+	ReleaseIssueLease(ctx context.Context, host, holder string) error
+}
+
+type certificateRepository struct {
+	qb *query.Builder
+}
+
+// NewCertificateRepository создаёт хранилище сертификатов
+func NewCertificateRepository(qb *query.Builder) CertificateRepository {
+	return &certificateRepository{qb: qb}
+}
+
+// Get получает сертификат домена
+func (r *certificateRepository) Get(ctx context.Context, host string) (*domain.TLSCertificate, error) {
+	query := r.qb.Select("domain", "cert_pem", "key_pem", "not_after", "created_at", "updated_at").
+		From("tls_certificates").
+		Where(squirrel.Eq{"domain": host})
+
+	var cert domain.TLSCertificate
+	err := r.qb.QueryRow(query).Scan(&cert.Domain, &cert.CertPEM, &cert.KeyPEM, &cert.NotAfter, &cert.CreatedAt, &cert.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound.WithMessage("certificate not found")
+		}
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return &cert, nil
+}
+
+// Save сохраняет сертификат; продлённый заменяет прежний
+func (r *certificateRepository) Save(ctx context.Context, cert *domain.TLSCertificate) error {
+	query := r.qb.Insert("tls_certificates").
+		Columns("domain", "cert_pem", "key_pem", "not_after", "created_at", "updated_at").
+		Values(cert.Domain, cert.CertPEM, cert.KeyPEM, cert.NotAfter, cert.CreatedAt, cert.UpdatedAt).
+		Suffix(`ON CONFLICT (domain) DO UPDATE SET
+			cert_pem = EXCLUDED.cert_pem,
+			key_pem = EXCLUDED.key_pem,
+			not_after = EXCLUDED.not_after,
+			updated_at = EXCLUDED.updated_at`)
+
+	_, err := r.qb.Execute(query)
+	return err
+}
+
+// Delete удаляет сертификат домена
+func (r *certificateRepository) Delete(ctx context.Context, host string) error {
+	query := r.qb.Delete("tls_certificates").
+		Where(squirrel.Eq{"domain": host})
+
+	_, err := r.qb.Execute(query)
+	return err
+}
+
+// GetACMEAccount получает аккаунт удостоверяющего центра
+func (r *certificateRepository) GetACMEAccount(ctx context.Context, directoryURL string) (*domain.ACMEAccount, error) {
+	query := r.qb.Select("directory_url", "key_pem", "created_at").
+		From("acme_accounts").
+		Where(squirrel.Eq{"directory_url": directoryURL})
+
+	var account domain.ACMEAccount
+	err := r.qb.QueryRow(query).Scan(&account.DirectoryURL, &account.KeyPEM, &account.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound.WithMessage("acme account not found")
+		}
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return &account, nil
+}
+
+// SaveACMEAccount сохраняет ключ аккаунта
+func (r *certificateRepository) SaveACMEAccount(ctx context.Context, account *domain.ACMEAccount) error {
+	query := r.qb.Insert("acme_accounts").
+		Columns("directory_url", "key_pem", "created_at").
+		Values(account.DirectoryURL, account.KeyPEM, account.CreatedAt).
+		Suffix("ON CONFLICT (directory_url) DO UPDATE SET key_pem = EXCLUDED.key_pem")
+
+	_, err := r.qb.Execute(query)
+	return err
+}
+
+// SaveChallenge сохраняет ответ на challenge http-01
+func (r *certificateRepository) SaveChallenge(ctx context.Context, token, response string) error {
+	query := r.qb.Insert("acme_challenges").
+		Columns("token", "response", "created_at").
+		Values(token, response, time.Now()).
+		Suffix("ON CONFLICT (token) DO UPDATE SET response = EXCLUDED.response")
+
+	_, err := r.qb.Execute(query)
+	return err
+}
+
+// GetChallenge получает ответ на challenge http-01 по токену
+func (r *certificateRepository) GetChallenge(ctx context.Context, token string) (string, error) {
+	query := r.qb.Select("response").
+		From("acme_challenges").
+		Where(squirrel.Eq{"token": token})
+
+	var response string
+	if err := r.qb.QueryRow(query).Scan(&response); err != nil {
+		if err == sql.ErrNoRows {
+			return "", domain.ErrNotFound.WithMessage("challenge not found")
+		}
+		return "", domain.ErrInternal.WithError(err)
+	}
+
+	return response, nil
+}
+
+// DeleteChallenge удаляет ответ на пройденный challenge
+func (r *certificateRepository) DeleteChallenge(ctx context.Context, token string) error {
+	query := r.qb.Delete("acme_challenges").
+		Where(squirrel.Eq{"token": token})
+
+	_, err := r.qb.Execute(query)
+	return err
+}
+
+// AcquireIssueLease берёт аренду выпуска сертификата домена на ttl.
+// false — домен сейчас выпускает другой экземпляр; истёкшая аренда перехватывается.
+func (r *certificateRepository) AcquireIssueLease(ctx context.Context, host, holder string, ttl time.Duration) (bool, error) {
+	query := r.qb.Insert("certificate_issue_leases").
+		Columns("domain", "holder", "expires_at").
+		Values(host, holder, squirrel.Expr("NOW() + ? * INTERVAL '1 second'", ttl.Seconds())).
+		Suffix(`ON CONFLICT (domain) DO UPDATE SET
+			holder = EXCLUDED.holder,
+			expires_at = EXCLUDED.expires_at
+			WHERE certificate_issue_leases.expires_at < NOW() OR certificate_issue_leases.holder = EXCLUDED.holder
+			RETURNING domain`)
+
+	var leased string
+	if err := r.qb.QueryRow(query).Scan(&leased); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, domain.ErrInternal.WithError(err)
+	}
+
+	return true, nil
+}
+
+// ReleaseIssueLease снимает аренду, если она всё ещё принадлежит holder
+func (r *certificateRepository) ReleaseIssueLease(ctx context.Context, host, holder string) error {
+	query := r.qb.Delete("certificate_issue_leases").
+		Where(squirrel.Eq{"domain": host, "holder": holder})
+
+	_, err := r.qb.Execute(query)
+	return err
+}
+
+var _ CertificateRepository = (*certificateRepository)(nil)
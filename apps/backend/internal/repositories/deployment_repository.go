@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/query"
+)
+
+// DeploymentRepository интерфейс репозитория деплоев
+type DeploymentRepository interface {
+	Create(ctx context.Context, deployment *domain.Deployment) error
+	GetByID(ctx context.Context, id string) (*domain.Deployment, error)
+	ListByProject(ctx context.Context, projectID string, limit, offset int) ([]*domain.Deployment, error)
+	Update(ctx context.Context, deployment *domain.Deployment) error
+}
+
+type deploymentRepository struct {
+	qb *query.Builder
+}
+
+// NewDeploymentRepository создаёт репозиторий деплоев
+func NewDeploymentRepository(qb *query.Builder) DeploymentRepository {
+	return &deploymentRepository{qb: qb}
+}
+
+var deploymentColumns = []string{"id", "project_id", "version", "subdomain", "status", "author_id", "created_at", "finished_at", "variants", "schema_json"}
+
+// Create сохраняет деплой, назначая ему следующий номер версии проекта
+func (r *deploymentRepository) Create(ctx context.Context, deployment *domain.Deployment) error {
+	variants := ""
+	if len(deployment.Variants) > 0 {
+		data, err := json.Marshal(deployment.Variants)
+		if err != nil {
+			return domain.ErrInternal.WithError(err)
+		}
+		variants = string(data)
+	}
+
+	return r.qb.InTransaction(ctx, func(tx *query.Tx) error {
+		version, err := nextProjectVersion(r.qb, tx, "deployments", deployment.ProjectID)
+		if err != nil {
+			return err
+		}
+		deployment.Version = version
+
+		query := r.qb.Insert("deployments").
+			Columns(deploymentColumns...).
+			Values(deployment.ID, deployment.ProjectID, deployment.Version, deployment.Subdomain, deployment.Status, deployment.AuthorID, deployment.CreatedAt, deployment.FinishedAt, variants, deployment.SchemaJSON)
+
+		_, err = tx.Execute(query)
+		return err
+	})
+}
+
+// GetByID получает деплой по ID
+func (r *deploymentRepository) GetByID(ctx context.Context, id string) (*domain.Deployment, error) {
+	deploymentID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrBadRequest.WithMessage("invalid deployment ID format")
+	}
+
+	query := r.qb.Select(deploymentColumns...).
+		From("deployments").
+		Where(squirrel.Eq{"id": deploymentID})
+
+	deployment, err := scanDeployment(r.qb.QueryRow(query))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound.WithMessage("deployment not found")
+		}
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return deployment, nil
+}
+
+// ListByProject возвращает деплои проекта, начиная с последнего
+func (r *deploymentRepository) ListByProject(ctx context.Context, projectID string, limit, offset int) ([]*domain.Deployment, error) {
+	projectUUID, err := uuid.Parse(projectID)
+	if err != nil {
+		return nil, domain.ErrBadRequest.WithMessage("invalid project ID format")
+	}
+
+	query := r.qb.Select(deploymentColumns...).
+		From("deployments").
+		Where(squirrel.Eq{"project_id": projectUUID}).
+		OrderBy("version DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+
+	rows, err := r.qb.Query(query)
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	defer rows.Close()
+
+	var deployments []*domain.Deployment
+	for rows.Next() {
+		deployment, err := scanDeployment(rows)
+		if err != nil {
+			return nil, domain.ErrInternal.WithError(err)
+		}
+		deployments = append(deployments, deployment)
+	}
+
+	return deployments, rows.Err()
+}
+
+// Update обновляет статус деплоя
+func (r *deploymentRepository) Update(ctx context.Context, deployment *domain.Deployment) error {
+	query := r.qb.Update("deployments").
+		Set("status", deployment.Status).
+		Set("finished_at", deployment.FinishedAt).
+		Where(squirrel.Eq{"id": deployment.ID})
+
+	_, err := r.qb.Execute(query)
+	return err
+}
+
+func scanDeployment(row rowScanner) (*domain.Deployment, error) {
+	var deployment domain.Deployment
+	var variants string
+	err := row.Scan(&deployment.ID, &deployment.ProjectID, &deployment.Version, &deployment.Subdomain, &deployment.Status,
+		&deployment.AuthorID, &deployment.CreatedAt, &deployment.FinishedAt, &variants, &deployment.SchemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	if variants != "" {
+		if err := json.Unmarshal([]byte(variants), &deployment.Variants); err != nil {
+			return nil, err
+		}
+	}
+	return &deployment, nil
+}
+
+// Ensure interface compliance at compile time
+var _ DeploymentRepository = (*deploymentRepository)(nil)
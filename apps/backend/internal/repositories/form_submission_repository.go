@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/query"
+)
+
+// FormSubmissionRepository интерфейс репозитория заявок
+type FormSubmissionRepository interface {
+	Create(ctx context.Context, submission *domain.FormSubmission) error
+	ListByProject(ctx context.Context, projectID uuid.UUID, query domain.FormSubmissionsQuery) ([]*domain.FormSubmission, error)
+}
+
+type formSubmissionRepository struct {
+	qb *query.Builder
+}
+
+// NewFormSubmissionRepository создаёт репозиторий заявок
+func NewFormSubmissionRepository(qb *query.Builder) FormSubmissionRepository {
+	return &formSubmissionRepository{qb: qb}
+}
+
+// Create сохраняет заявку
+func (r *formSubmissionRepository) Create(ctx context.Context, submission *domain.FormSubmission) error {
+	query := r.qb.Insert("form_submissions").
+		Columns("id", "project_id", "form_name", "data", "path", "variant", "created_at").
+		Values(submission.ID, submission.ProjectID, submission.FormName, submission.Data, submission.Path, submission.Variant, submission.CreatedAt)
+
+	if _, err := r.qb.Execute(query); err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+	return nil
+}
+
+// ListByProject возвращает заявки проекта, новые первыми
+func (r *formSubmissionRepository) ListByProject(ctx context.Context, projectID uuid.UUID, q domain.FormSubmissionsQuery) ([]*domain.FormSubmission, error) {
+	query := r.qb.Select("id", "project_id", "form_name", "data", "path", "variant", "created_at").
+		From("form_submissions").
+		Where(squirrel.Eq{"project_id": projectID}).
+		OrderBy("created_at DESC", "id").
+		Limit(uint64(q.Limit)).
+		Offset(uint64(q.Offset))
+	if q.Form != "" {
+		query = query.Where(squirrel.Eq{"form_name": q.Form})
+	}
+
+	rows, err := r.qb.Query(query)
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	defer rows.Close()
+
+	var submissions []*domain.FormSubmission
+	for rows.Next() {
+		var submission domain.FormSubmission
+		err := rows.Scan(&submission.ID, &submission.ProjectID, &submission.FormName, &submission.Data,
+			&submission.Path, &submission.Variant, &submission.CreatedAt)
+		if err != nil {
+			return nil, domain.ErrInternal.WithError(err)
+		}
+		submissions = append(submissions, &submission)
+	}
+
+	return submissions, rows.Err()
+}
+
+// Ensure interface compliance at compile time
+var _ FormSubmissionRepository = (*formSubmissionRepository)(nil)
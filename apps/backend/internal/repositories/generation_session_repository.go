@@ -32,8 +32,8 @@ func NewGenerationSessionRepository(qb *query.Builder) GenerationSessionReposito
 // Create создает сессию генерации
 func (r *generationSessionRepository) Create(ctx context.Context, session *domain.GenerationSession) error {
 	query := r.qb.Insert("generation_sessions").
-		Columns("id", "project_id", "prompt", "model", "status", "schema_json", "completed_at", "created_at", "updated_at").
-		Values(session.ID, session.ProjectID, session.Prompt, session.Model, session.Status, session.SchemaJSON, session.CompletedAt, session.CreatedAt, session.UpdatedAt)
+		Columns("id", "project_id", "prompt", "model", "status", "schema_json", "error_json", "completed_at", "created_at", "updated_at").
+		Values(session.ID, session.ProjectID, session.Prompt, session.Model, session.Status, session.SchemaJSON, session.ErrorJSON, session.CompletedAt, session.CreatedAt, session.UpdatedAt)
 
 	_, err := r.qb.Execute(query)
 	return err
@@ -46,14 +46,14 @@ func (r *generationSessionRepository) GetByID(ctx context.Context, id string) (*
 		return nil, domain.ErrBadRequest.WithMessage("invalid session ID format")
 	}
 
-	query := r.qb.Select("id", "project_id", "prompt", "model", "status", "schema_json", "completed_at", "created_at", "updated_at").
+	query := r.qb.Select("id", "project_id", "prompt", "model", "status", "schema_json", "error_json", "completed_at", "created_at", "updated_at").
 		From("generation_sessions").
 		Where(squirrel.Eq{"id": sessionID})
 
 	row := r.qb.QueryRow(query)
 
 	var session domain.GenerationSession
-	err = row.Scan(&session.ID, &session.ProjectID, &session.Prompt, &session.Model, &session.Status, &session.SchemaJSON, &session.CompletedAt, &session.CreatedAt, &session.UpdatedAt)
+	err = row.Scan(&session.ID, &session.ProjectID, &session.Prompt, &session.Model, &session.Status, &session.SchemaJSON, &session.ErrorJSON, &session.CompletedAt, &session.CreatedAt, &session.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrNotFound.WithMessage("session not found")
@@ -71,7 +71,7 @@ func (r *generationSessionRepository) GetByProjectID(ctx context.Context, projec
 		return nil, domain.ErrBadRequest.WithMessage("invalid project ID format")
 	}
 
-	query := r.qb.Select("id", "project_id", "prompt", "model", "status", "schema_json", "completed_at", "created_at", "updated_at").
+	query := r.qb.Select("id", "project_id", "prompt", "model", "status", "schema_json", "error_json", "completed_at", "created_at", "updated_at").
 		From("generation_sessions").
 		Where(squirrel.Eq{"project_id": projectUUID}).
 		OrderBy("created_at DESC")
@@ -85,7 +85,7 @@ func (r *generationSessionRepository) GetByProjectID(ctx context.Context, projec
 	var sessions []*domain.GenerationSession
 	for rows.Next() {
 		var session domain.GenerationSession
-		err := rows.Scan(&session.ID, &session.ProjectID, &session.Prompt, &session.Model, &session.Status, &session.SchemaJSON, &session.CompletedAt, &session.CreatedAt, &session.UpdatedAt)
+		err := rows.Scan(&session.ID, &session.ProjectID, &session.Prompt, &session.Model, &session.Status, &session.SchemaJSON, &session.ErrorJSON, &session.CompletedAt, &session.CreatedAt, &session.UpdatedAt)
 		if err != nil {
 			return nil, domain.ErrInternal.WithError(err)
 		}
@@ -101,6 +101,7 @@ func (r *generationSessionRepository) Update(ctx context.Context, session *domai
 		Set("prompt", session.Prompt).
 		Set("status", session.Status).
 		Set("schema_json", session.SchemaJSON).
+		Set("error_json", session.ErrorJSON).
 		Set("completed_at", session.CompletedAt).
 		Set("updated_at", session.UpdatedAt).
 		Where(squirrel.Eq{"id": session.ID})
@@ -0,0 +1,219 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/query"
+)
+
+// JobRepository интерфейс репозитория фоновых задач
+type JobRepository interface {
+	Create(ctx context.Context, job *domain.Job) error
+	GetByID(ctx context.Context, id string) (*domain.Job, error)
+	Update(ctx context.Context, job *domain.Job) (bool, error)
+	Requeue(ctx context.Context, job *domain.Job) (bool, error)
+	Claim(ctx context.Context, id uuid.UUID) (bool, error)
+	Touch(ctx context.Context, id uuid.UUID, attempt int) (bool, error)
+	NextQueued(ctx context.Context, now time.Time) (uuid.UUID, error)
+	ListStuck(ctx context.Context, before time.Time, limit int) ([]*domain.Job, error)
+}
+
+type jobRepository struct {
+	qb *query.Builder
+}
+
+// NewJobRepository создаёт репозиторий фоновых задач
+func NewJobRepository(qb *query.Builder) JobRepository {
+	return &jobRepository{qb: qb}
+}
+
+var jobColumns = []string{"id", "type", "payload", "status", "attempts", "max_attempts", "last_error", "user_id", "project_id", "run_at", "created_at", "updated_at", "finished_at"}
+
+// Create сохраняет задачу
+func (r *jobRepository) Create(ctx context.Context, job *domain.Job) error {
+	query := r.qb.Insert("jobs").
+		Columns(jobColumns...).
+		Values(job.ID, job.Type, job.Payload, job.Status, job.Attempts, job.MaxAttempts, job.LastError, job.UserID, job.ProjectID, job.RunAt, job.CreatedAt, job.UpdatedAt, job.FinishedAt)
+
+	_, err := r.qb.Execute(query)
+	return err
+}
+
+// GetByID получает задачу по ID
+func (r *jobRepository) GetByID(ctx context.Context, id string) (*domain.Job, error) {
+	jobID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrBadRequest.WithMessage("invalid job ID format")
+	}
+
+	query := r.qb.Select(jobColumns...).
+		From("jobs").
+		Where(squirrel.Eq{"id": jobID})
+
+	job, err := scanJob(r.qb.QueryRow(query))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound.WithMessage("job not found")
+		}
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return job, nil
+}
+
+// Update сохраняет итог попытки выполняемой задачи. Возвращает false, если задача
+// уже не в running или её забрали заново (attempts не совпадает) — итог устарел
+func (r *jobRepository) Update(ctx context.Context, job *domain.Job) (bool, error) {
+	query := r.qb.Update("jobs").
+		Set("status", job.Status).
+		Set("last_error", job.LastError).
+		Set("run_at", job.RunAt).
+		Set("updated_at", job.UpdatedAt).
+		Set("finished_at", job.FinishedAt).
+		Where(squirrel.Eq{"id": job.ID, "status": domain.JobStatusRunning, "attempts": job.Attempts})
+
+	result, err := r.qb.Execute(query)
+	if err != nil {
+		return false, domain.ErrInternal.WithError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, domain.ErrInternal.WithError(err)
+	}
+
+	return affected == 1, nil
+}
+
+// Requeue возвращает задачу из dead letter в очередь. Возвращает false, если задача уже не в dead
+func (r *jobRepository) Requeue(ctx context.Context, job *domain.Job) (bool, error) {
+	query := r.qb.Update("jobs").
+		Set("status", job.Status).
+		Set("attempts", job.Attempts).
+		Set("last_error", job.LastError).
+		Set("run_at", job.RunAt).
+		Set("updated_at", job.UpdatedAt).
+		Set("finished_at", job.FinishedAt).
+		Where(squirrel.Eq{"id": job.ID, "status": domain.JobStatusDead})
+
+	result, err := r.qb.Execute(query)
+	if err != nil {
+		return false, domain.ErrInternal.WithError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, domain.ErrInternal.WithError(err)
+	}
+
+	return affected == 1, nil
+}
+
+// Claim переводит задачу из queued в running и увеличивает счётчик попыток.
+// Возвращает false, если задачу уже забрал другой воркер.
+func (r *jobRepository) Claim(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := r.qb.Update("jobs").
+		Set("status", domain.JobStatusRunning).
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": id, "status": domain.JobStatusQueued})
+
+	result, err := r.qb.Execute(query)
+	if err != nil {
+		return false, domain.ErrInternal.WithError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, domain.ErrInternal.WithError(err)
+	}
+
+	return affected == 1, nil
+}
+
+// Touch продлевает аренду выполняемой задачи. Возвращает false, если задача уже
+// не в running или её забрали заново (attempt не совпадает)
+func (r *jobRepository) Touch(ctx context.Context, id uuid.UUID, attempt int) (bool, error) {
+	query := r.qb.Update("jobs").
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": id, "status": domain.JobStatusRunning, "attempts": attempt})
+
+	result, err := r.qb.Execute(query)
+	if err != nil {
+		return false, domain.ErrInternal.WithError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, domain.ErrInternal.WithError(err)
+	}
+
+	return affected == 1, nil
+}
+
+// NextQueued возвращает ID самой ранней задачи, готовой к выполнению
+func (r *jobRepository) NextQueued(ctx context.Context, now time.Time) (uuid.UUID, error) {
+	query := r.qb.Select("id").
+		From("jobs").
+		Where(squirrel.Eq{"status": domain.JobStatusQueued}).
+		Where(squirrel.LtOrEq{"run_at": now}).
+		OrderBy("run_at ASC").
+		Limit(1)
+
+	var id uuid.UUID
+	if err := r.qb.QueryRow(query).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, domain.ErrNotFound.WithMessage("no queued jobs")
+		}
+		return uuid.Nil, domain.ErrInternal.WithError(err)
+	}
+
+	return id, nil
+}
+
+// ListStuck возвращает задачи, зависшие в running или не забранные из очереди до before
+func (r *jobRepository) ListStuck(ctx context.Context, before time.Time, limit int) ([]*domain.Job, error) {
+	query := r.qb.Select(jobColumns...).
+		From("jobs").
+		Where(squirrel.Or{
+			squirrel.And{squirrel.Eq{"status": domain.JobStatusRunning}, squirrel.Lt{"updated_at": before}},
+			squirrel.And{squirrel.Eq{"status": domain.JobStatusQueued}, squirrel.Lt{"run_at": before}},
+		}).
+		OrderBy("run_at ASC").
+		Limit(uint64(limit))
+
+	rows, err := r.qb.Query(query)
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	defer rows.Close()
+
+	var jobs []*domain.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, domain.ErrInternal.WithError(err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+func scanJob(row rowScanner) (*domain.Job, error) {
+	var job domain.Job
+	err := row.Scan(&job.ID, &job.Type, &job.Payload, &job.Status, &job.Attempts, &job.MaxAttempts, &job.LastError,
+		&job.UserID, &job.ProjectID, &job.RunAt, &job.CreatedAt, &job.UpdatedAt, &job.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Ensure interface compliance at compile time
+var _ JobRepository = (*jobRepository)(nil)
@@ -19,6 +19,7 @@ type ProjectRepository interface {
 	Update(ctx context.Context, project *domain.Project) error
 	Delete(ctx context.Context, id string) error
 	UpdateSchema(ctx context.Context, projectID string, schemaJSON string) error
+	UpdateStatus(ctx context.Context, projectID string, status string) error
 }
 
 // projectRepository реализация репозитория проектов
@@ -146,3 +147,19 @@ func (r *projectRepository) UpdateSchema(ctx context.Context, projectID string,
 	_, err = r.qb.Execute(query)
 	return err
 }
+
+// UpdateStatus обновляет только статус проекта, не трогая схему
+func (r *projectRepository) UpdateStatus(ctx context.Context, projectID string, status string) error {
+	projectUUID, err := uuid.Parse(projectID)
+	if err != nil {
+		return domain.ErrBadRequest.WithMessage("invalid project ID format")
+	}
+
+	query := r.qb.Update("projects").
+		Set("status", status).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": projectUUID})
+
+	_, err = r.qb.Execute(query)
+	return err
+}
@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/query"
+)
+
+// ProjectVariantRepository интерфейс репозитория вариантов A/B-теста
+type ProjectVariantRepository interface {
+	Create(ctx context.Context, variant *domain.ProjectVariant) error
+	GetByID(ctx context.Context, id string) (*domain.ProjectVariant, error)
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]*domain.ProjectVariant, error)
+	Update(ctx context.Context, variant *domain.ProjectVariant) error
+	Delete(ctx context.Context, id string) error
+	DeleteByProject(ctx context.Context, projectID uuid.UUID) error
+}
+
+type projectVariantRepository struct {
+	qb *query.Builder
+}
+
+// NewProjectVariantRepository создаёт репозиторий вариантов A/B-теста
+func NewProjectVariantRepository(qb *query.Builder) ProjectVariantRepository {
+	return &projectVariantRepository{qb: qb}
+}
+
+var projectVariantColumns = []string{"id", "project_id", "name", "schema_json", "weight", "created_at", "updated_at"}
+
+// Create сохраняет вариант
+func (r *projectVariantRepository) Create(ctx context.Context, variant *domain.ProjectVariant) error {
+	query := r.qb.Insert("project_variants").
+		Columns(projectVariantColumns...).
+		Values(variant.ID, variant.ProjectID, variant.Name, variant.SchemaJSON, variant.Weight, variant.CreatedAt, variant.UpdatedAt)
+
+	_, err := r.qb.Execute(query)
+	return err
+}
+
+// GetByID получает вариант по ID
+func (r *projectVariantRepository) GetByID(ctx context.Context, id string) (*domain.ProjectVariant, error) {
+	variantID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrBadRequest.WithMessage("invalid variant ID format")
+	}
+
+	query := r.qb.Select(projectVariantColumns...).
+		From("project_variants").
+		Where(squirrel.Eq{"id": variantID})
+
+	variant, err := scanProjectVariant(r.qb.QueryRow(query))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound.WithMessage("variant not found")
+		}
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return variant, nil
+}
+
+// ListByProject возвращает варианты проекта в порядке создания
+func (r *projectVariantRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]*domain.ProjectVariant, error) {
+	query := r.qb.Select(projectVariantColumns...).
+		From("project_variants").
+		Where(squirrel.Eq{"project_id": projectID}).
+		OrderBy("created_at ASC")
+
+	rows, err := r.qb.Query(query)
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	defer rows.Close()
+
+	var variants []*domain.ProjectVariant
+	for rows.Next() {
+		variant, err := scanProjectVariant(rows)
+		if err != nil {
+			return nil, domain.ErrInternal.WithError(err)
+		}
+		variants = append(variants, variant)
+	}
+
+	return variants, rows.Err()
+}
+
+// Update обновляет вариант
+func (r *projectVariantRepository) Update(ctx context.Context, variant *domain.ProjectVariant) error {
+	query := r.qb.Update("project_variants").
+		Set("name", variant.Name).
+		Set("schema_json", variant.SchemaJSON).
+		Set("weight", variant.Weight).
+		Set("updated_at", variant.UpdatedAt).
+		Where(squirrel.Eq{"id": variant.ID})
+
+	_, err := r.qb.Execute(query)
+	return err
+}
+
+// Delete удаляет вариант
+func (r *projectVariantRepository) Delete(ctx context.Context, id string) error {
+	variantID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrBadRequest.WithMessage("invalid variant ID format")
+	}
+
+	query := r.qb.Delete("project_variants").
+		Where(squirrel.Eq{"id": variantID})
+
+	_, err = r.qb.Execute(query)
+	return err
+}
+
+// DeleteByProject удаляет все варианты проекта
+func (r *projectVariantRepository) DeleteByProject(ctx context.Context, projectID uuid.UUID) error {
+	query := r.qb.Delete("project_variants").
+		Where(squirrel.Eq{"project_id": projectID})
+
+	_, err := r.qb.Execute(query)
+	return err
+}
+
+func scanProjectVariant(row rowScanner) (*domain.ProjectVariant, error) {
+	var variant domain.ProjectVariant
+	err := row.Scan(&variant.ID, &variant.ProjectID, &variant.Name, &variant.SchemaJSON, &variant.Weight, &variant.CreatedAt, &variant.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// Ensure interface compliance at compile time
+var _ ProjectVariantRepository = (*projectVariantRepository)(nil)
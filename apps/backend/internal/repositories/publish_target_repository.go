@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
@@ -16,7 +17,13 @@ type PublishTargetRepository interface {
 	GetByID(ctx context.Context, id string) (*domain.PublishTarget, error)
 	GetByProjectID(ctx context.Context, projectID string) (*domain.PublishTarget, error)
 	GetBySubdomain(ctx context.Context, subdomain string) (*domain.PublishTarget, error)
+	GetByCustomDomain(ctx context.Context, host string) (*domain.PublishTarget, error)
 	Update(ctx context.Context, target *domain.PublishTarget) error
+	UpdateDomain(ctx context.Context, target *domain.PublishTarget) error
+	ClaimVerifiedDomain(ctx context.Context, target *domain.PublishTarget) error
+	ListVerifiedDomains(ctx context.Context) ([]*domain.PublishTarget, error)
+	SetSSLStatus(ctx context.Context, host, status string) error
+	SetActiveDeployment(ctx context.Context, targetID, deploymentID uuid.UUID) error
 	Delete(ctx context.Context, id string) error
 }
 
@@ -30,11 +37,18 @@ func NewPublishTargetRepository(qb *query.Builder) PublishTargetRepository {
 	return &publishTargetRepository{qb: qb}
 }
 
+var publishTargetColumns = []string{"id", "project_id", "subdomain", "status", "last_published_at", "active_deployment_id", "created_at", "updated_at"}
+
+// publishTargetSelectColumns колонки чтения: к колонкам вставки добавлены поля собственного домена,
+// которые меняет только UpdateDomain
+var publishTargetSelectColumns = append(publishTargetColumns,
+	"COALESCE(custom_domain, '')", "COALESCE(domain_verification_token, '')", "domain_verified_at", "ssl_status")
+
 // Create создает цель публикации
 func (r *publishTargetRepository) Create(ctx context.Context, target *domain.PublishTarget) error {
 	query := r.qb.Insert("publish_targets").
-		Columns("id", "project_id", "subdomain", "status", "last_published_at", "created_at", "updated_at").
-		Values(target.ID, target.ProjectID, target.Subdomain, target.Status, target.LastPublishedAt, target.CreatedAt, target.UpdatedAt)
+		Columns(publishTargetColumns...).
+		Values(target.ID, target.ProjectID, target.Subdomain, target.Status, target.LastPublishedAt, target.ActiveDeploymentID, target.CreatedAt, target.UpdatedAt)
 
 	_, err := r.qb.Execute(query)
 	return err
@@ -47,22 +61,11 @@ func (r *publishTargetRepository) GetByID(ctx context.Context, id string) (*doma
 		return nil, domain.ErrBadRequest.WithMessage("invalid target ID format")
 	}
 
-	query := r.qb.Select("id", "project_id", "subdomain", "status", "last_published_at", "created_at", "updated_at").
+	query := r.qb.Select(publishTargetSelectColumns...).
 		From("publish_targets").
 		Where(squirrel.Eq{"id": targetID})
 
-	row := r.qb.QueryRow(query)
-
-	var target domain.PublishTarget
-	err = row.Scan(&target.ID, &target.ProjectID, &target.Subdomain, &target.Status, &target.LastPublishedAt, &target.CreatedAt, &target.UpdatedAt)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, domain.ErrNotFound.WithMessage("target not found")
-		}
-		return nil, domain.ErrInternal.WithError(err)
-	}
-
-	return &target, nil
+	return scanPublishTarget(r.qb.QueryRow(query))
 }
 
 // GetByProjectID получает цель по ID проекта
@@ -72,36 +75,40 @@ func (r *publishTargetRepository) GetByProjectID(ctx context.Context, projectID
 		return nil, domain.ErrBadRequest.WithMessage("invalid project ID format")
 	}
 
-	query := r.qb.Select("id", "project_id", "subdomain", "status", "last_published_at", "created_at", "updated_at").
+	query := r.qb.Select(publishTargetSelectColumns...).
 		From("publish_targets").
 		Where(squirrel.Eq{"project_id": projectUUID}).
 		OrderBy("updated_at DESC").
 		Limit(1)
 
-	row := r.qb.QueryRow(query)
-
-	var target domain.PublishTarget
-	err = row.Scan(&target.ID, &target.ProjectID, &target.Subdomain, &target.Status, &target.LastPublishedAt, &target.CreatedAt, &target.UpdatedAt)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, domain.ErrNotFound.WithMessage("target not found")
-		}
-		return nil, domain.ErrInternal.WithError(err)
-	}
-
-	return &target, nil
+	return scanPublishTarget(r.qb.QueryRow(query))
 }
 
 // GetBySubdomain получает цель по поддомену
 func (r *publishTargetRepository) GetBySubdomain(ctx context.Context, subdomain string) (*domain.PublishTarget, error) {
-	query := r.qb.Select("id", "project_id", "subdomain", "status", "last_published_at", "created_at", "updated_at").
+	query := r.qb.Select(publishTargetSelectColumns...).
 		From("publish_targets").
 		Where(squirrel.Eq{"subdomain": subdomain})
 
-	row := r.qb.QueryRow(query)
+	return scanPublishTarget(r.qb.QueryRow(query))
+}
+
+// GetByCustomDomain получает цель по собственному домену. Неподтверждённых заявок на домен
+// может быть несколько, поэтому первой идёт цель, подтвердившая его.
+func (r *publishTargetRepository) GetByCustomDomain(ctx context.Context, host string) (*domain.PublishTarget, error) {
+	query := r.qb.Select(publishTargetSelectColumns...).
+		From("publish_targets").
+		Where(squirrel.Eq{"custom_domain": host}).
+		OrderBy("domain_verified_at IS NULL", "updated_at DESC").
+		Limit(1)
+
+	return scanPublishTarget(r.qb.QueryRow(query))
+}
 
+func scanPublishTarget(row rowScanner) (*domain.PublishTarget, error) {
 	var target domain.PublishTarget
-	err := row.Scan(&target.ID, &target.ProjectID, &target.Subdomain, &target.Status, &target.LastPublishedAt, &target.CreatedAt, &target.UpdatedAt)
+	err := row.Scan(&target.ID, &target.ProjectID, &target.Subdomain, &target.Status, &target.LastPublishedAt, &target.ActiveDeploymentID, &target.CreatedAt, &target.UpdatedAt,
+		&target.CustomDomain, &target.DomainVerificationToken, &target.DomainVerifiedAt, &target.SSLStatus)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrNotFound.WithMessage("target not found")
@@ -125,6 +132,127 @@ func (r *publishTargetRepository) Update(ctx context.Context, target *domain.Pub
 	return err
 }
 
+// UpdateDomain сохраняет собственный домен цели, его подтверждение и статус сертификата.
+// Пустой домен записывается как NULL, чтобы не нарушать уникальность.
+func (r *publishTargetRepository) UpdateDomain(ctx context.Context, target *domain.PublishTarget) error {
+	var customDomain, token interface{}
+	if target.CustomDomain != "" {
+		customDomain = target.CustomDomain
+	}
+	if target.DomainVerificationToken != "" {
+		token = target.DomainVerificationToken
+	}
+
+	query := r.qb.Update("publish_targets").
+		Set("custom_domain", customDomain).
+		Set("domain_verification_token", token).
+		Set("domain_verified_at", target.DomainVerifiedAt).
+		Set("ssl_status", target.SSLStatus).
+		Set("updated_at", target.UpdatedAt).
+		Where(squirrel.Eq{"id": target.ID})
+
+	_, err := r.qb.Execute(query)
+	return err
+}
+
+// ClaimVerifiedDomain сохраняет подтверждение домена и снимает неподтверждённые заявки
+// других проектов на тот же домен. Если домен уже подтвердил другой проект, возвращает ErrAlreadyExists.
+func (r *publishTargetRepository) ClaimVerifiedDomain(ctx context.Context, target *domain.PublishTarget) error {
+	return r.qb.InTransaction(ctx, func(tx *query.Tx) error {
+		ownerQuery := r.qb.Select("id").
+			From("publish_targets").
+			Where(squirrel.Eq{"custom_domain": target.CustomDomain}).
+			Where(squirrel.NotEq{"id": target.ID}).
+			Where(squirrel.NotEq{"domain_verified_at": nil}).
+			Suffix("FOR UPDATE")
+
+		var ownerID uuid.UUID
+		err := tx.QueryRow(ownerQuery).Scan(&ownerID)
+		if err == nil {
+			return domain.ErrAlreadyExists.WithMessage("domain is attached to another project")
+		}
+		if err != sql.ErrNoRows {
+			return domain.ErrInternal.WithError(err)
+		}
+
+		releaseQuery := r.qb.Update("publish_targets").
+			Set("custom_domain", nil).
+			Set("domain_verification_token", nil).
+			Set("ssl_status", domain.SSLStatusNone).
+			Set("updated_at", target.UpdatedAt).
+			Where(squirrel.Eq{"custom_domain": target.CustomDomain, "domain_verified_at": nil}).
+			Where(squirrel.NotEq{"id": target.ID})
+		if _, err := tx.Execute(releaseQuery); err != nil {
+			return domain.ErrInternal.WithError(err)
+		}
+
+		claimQuery := r.qb.Update("publish_targets").
+			Set("domain_verified_at", target.DomainVerifiedAt).
+			Set("ssl_status", target.SSLStatus).
+			Set("updated_at", target.UpdatedAt).
+			Where(squirrel.Eq{"id": target.ID, "custom_domain": target.CustomDomain})
+		if _, err := tx.Execute(claimQuery); err != nil {
+			return domain.ErrInternal.WithError(err)
+		}
+		return nil
+	})
+}
+
+// ListVerifiedDomains возвращает цели с подтверждённым собственным доменом
+func (r *publishTargetRepository) ListVerifiedDomains(ctx context.Context) ([]*domain.PublishTarget, error) {
+	query := r.qb.Select(publishTargetSelectColumns...).
+		From("publish_targets").
+		Where(squirrel.And{
+			squirrel.NotEq{"custom_domain": nil},
+			squirrel.NotEq{"domain_verified_at": nil},
+		}).
+		OrderBy("custom_domain")
+
+	rows, err := r.qb.Query(query)
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	defer rows.Close()
+
+	var targets []*domain.PublishTarget
+	for rows.Next() {
+		target, err := scanPublishTarget(rows)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return targets, nil
+}
+
+// SetSSLStatus обновляет статус сертификата домена. Условие по домену, а не по ID цели:
+// если домен успели отвязать, пока выпускался сертификат, статус не запишется.
+func (r *publishTargetRepository) SetSSLStatus(ctx context.Context, host, status string) error {
+	query := r.qb.Update("publish_targets").
+		Set("ssl_status", status).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"custom_domain": host})
+
+	_, err := r.qb.Execute(query)
+	return err
+}
+
+// SetActiveDeployment переключает цель на указанный деплой.
+// Update указатель не трогает, чтобы перезапись цели не откатила переключение.
+func (r *publishTargetRepository) SetActiveDeployment(ctx context.Context, targetID, deploymentID uuid.UUID) error {
+	query := r.qb.Update("publish_targets").
+		Set("active_deployment_id", deploymentID).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": targetID})
+
+	_, err := r.qb.Execute(query)
+	return err
+}
+
 // Delete удаляет цель
 func (r *publishTargetRepository) Delete(ctx context.Context, id string) error {
 	targetID, err := uuid.Parse(id)
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/query"
+)
+
+// PurchaseRepository интерфейс репозитория оплат
+type PurchaseRepository interface {
+	Create(ctx context.Context, purchase *domain.Purchase) error
+	ListByProject(ctx context.Context, projectID uuid.UUID, limit int) ([]*domain.Purchase, error)
+}
+
+type purchaseRepository struct {
+	qb *query.Builder
+}
+
+// NewPurchaseRepository создаёт репозиторий оплат
+func NewPurchaseRepository(qb *query.Builder) PurchaseRepository {
+	return &purchaseRepository{qb: qb}
+}
+
+// Create сохраняет оплату. Оплата, уже записанная по тому же событию
+// платёжной системы, не дублируется: возвращается ErrAlreadyExists.
+func (r *purchaseRepository) Create(ctx context.Context, purchase *domain.Purchase) error {
+	query := r.qb.Insert("purchases").
+		Columns("id", "project_id", "provider", "external_id", "amount", "currency", "variant", "created_at").
+		Values(purchase.ID, purchase.ProjectID, purchase.Provider, purchase.ExternalID, purchase.Amount, purchase.Currency, purchase.Variant, purchase.CreatedAt).
+		Suffix("ON CONFLICT (provider, external_id) DO NOTHING")
+
+	result, err := r.qb.Execute(query)
+	if err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+	if affected == 0 {
+		return domain.ErrAlreadyExists.WithMessage("purchase already recorded")
+	}
+
+	return nil
+}
+
+// ListByProject возвращает последние оплаты проекта, новые первыми
+func (r *purchaseRepository) ListByProject(ctx context.Context, projectID uuid.UUID, limit int) ([]*domain.Purchase, error) {
+	query := r.qb.Select("id", "project_id", "provider", "external_id", "amount", "currency", "variant", "created_at").
+		From("purchases").
+		Where(squirrel.Eq{"project_id": projectID}).
+		OrderBy("created_at DESC").
+		Limit(uint64(limit))
+
+	rows, err := r.qb.Query(query)
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	defer rows.Close()
+
+	var purchases []*domain.Purchase
+	for rows.Next() {
+		var purchase domain.Purchase
+		err := rows.Scan(&purchase.ID, &purchase.ProjectID, &purchase.Provider, &purchase.ExternalID,
+			&purchase.Amount, &purchase.Currency, &purchase.Variant, &purchase.CreatedAt)
+		if err != nil {
+			return nil, domain.ErrInternal.WithError(err)
+		}
+		purchases = append(purchases, &purchase)
+	}
+
+	return purchases, rows.Err()
+}
+
+// Ensure interface compliance at compile time
+var _ PurchaseRepository = (*purchaseRepository)(nil)
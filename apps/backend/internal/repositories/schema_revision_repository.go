@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/query"
+)
+
+// SchemaRevisionRepository интерфейс репозитория ревизий схемы
+type SchemaRevisionRepository interface {
+	Create(ctx context.Context, revision *domain.SchemaRevision) error
+	GetByID(ctx context.Context, id string) (*domain.SchemaRevision, error)
+	ListByProject(ctx context.Context, projectID string, limit, offset int) ([]*domain.SchemaRevision, error)
+	GetPrevious(ctx context.Context, projectID string, version int) (*domain.SchemaRevision, error)
+}
+
+type schemaRevisionRepository struct {
+	qb *query.Builder
+}
+
+// NewSchemaRevisionRepository создаёт репозиторий ревизий схемы
+func NewSchemaRevisionRepository(qb *query.Builder) SchemaRevisionRepository {
+	return &schemaRevisionRepository{qb: qb}
+}
+
+var schemaRevisionColumns = []string{"id", "project_id", "version", "schema_json", "source", "author_id", "message_id", "restored_from", "created_at"}
+
+// Create сохраняет ревизию, назначая ей следующий номер версии проекта
+func (r *schemaRevisionRepository) Create(ctx context.Context, revision *domain.SchemaRevision) error {
+	return r.qb.InTransaction(ctx, func(tx *query.Tx) error {
+		version, err := nextProjectVersion(r.qb, tx, "schema_revisions", revision.ProjectID)
+		if err != nil {
+			return err
+		}
+		revision.Version = version
+
+		query := r.qb.Insert("schema_revisions").
+			Columns(schemaRevisionColumns...).
+			Values(revision.ID, revision.ProjectID, revision.Version, revision.SchemaJSON, revision.Source, revision.AuthorID, revision.MessageID, revision.RestoredFrom, revision.CreatedAt)
+
+		_, err = tx.Execute(query)
+		return err
+	})
+}
+
+// GetByID получает ревизию по ID
+func (r *schemaRevisionRepository) GetByID(ctx context.Context, id string) (*domain.SchemaRevision, error) {
+	revisionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrBadRequest.WithMessage("invalid revision ID format")
+	}
+
+	query := r.qb.Select(schemaRevisionColumns...).
+		From("schema_revisions").
+		Where(squirrel.Eq{"id": revisionID})
+
+	revision, err := scanSchemaRevision(r.qb.QueryRow(query))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound.WithMessage("revision not found")
+		}
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return revision, nil
+}
+
+// ListByProject возвращает ревизии проекта, начиная с последней
+func (r *schemaRevisionRepository) ListByProject(ctx context.Context, projectID string, limit, offset int) ([]*domain.SchemaRevision, error) {
+	projectUUID, err := uuid.Parse(projectID)
+	if err != nil {
+		return nil, domain.ErrBadRequest.WithMessage("invalid project ID format")
+	}
+
+	query := r.qb.Select(schemaRevisionColumns...).
+		From("schema_revisions").
+		Where(squirrel.Eq{"project_id": projectUUID}).
+		OrderBy("version DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+
+	rows, err := r.qb.Query(query)
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	defer rows.Close()
+
+	var revisions []*domain.SchemaRevision
+	for rows.Next() {
+		revision, err := scanSchemaRevision(rows)
+		if err != nil {
+			return nil, domain.ErrInternal.WithError(err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+// GetPrevious возвращает ближайшую ревизию проекта с номером меньше version
+func (r *schemaRevisionRepository) GetPrevious(ctx context.Context, projectID string, version int) (*domain.SchemaRevision, error) {
+	projectUUID, err := uuid.Parse(projectID)
+	if err != nil {
+		return nil, domain.ErrBadRequest.WithMessage("invalid project ID format")
+	}
+
+	query := r.qb.Select(schemaRevisionColumns...).
+		From("schema_revisions").
+		Where(squirrel.Eq{"project_id": projectUUID}).
+		Where(squirrel.Lt{"version": version}).
+		OrderBy("version DESC").
+		Limit(1)
+
+	revision, err := scanSchemaRevision(r.qb.QueryRow(query))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound.WithMessage("revision not found")
+		}
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return revision, nil
+}
+
+// nextProjectVersion блокирует строку проекта до конца транзакции и возвращает следующий номер версии в table.
+// Без блокировки параллельные записи получают одинаковый MAX(version)+1 и падают на UNIQUE(project_id, version).
+func nextProjectVersion(qb *query.Builder, tx *query.Tx, table string, projectID uuid.UUID) (int, error) {
+	lockQuery := qb.Select("id").
+		From("projects").
+		Where(squirrel.Eq{"id": projectID}).
+		Suffix("FOR UPDATE")
+
+	var lockedID uuid.UUID
+	if err := tx.QueryRow(lockQuery).Scan(&lockedID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, domain.ErrNotFound.WithMessage("project not found")
+		}
+		return 0, domain.ErrInternal.WithError(err)
+	}
+
+	versionQuery := qb.Select("COALESCE(MAX(version), 0)").
+		From(table).
+		Where(squirrel.Eq{"project_id": projectID})
+
+	var lastVersion int
+	if err := tx.QueryRow(versionQuery).Scan(&lastVersion); err != nil {
+		return 0, domain.ErrInternal.WithError(err)
+	}
+
+	return lastVersion + 1, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSchemaRevision(row rowScanner) (*domain.SchemaRevision, error) {
+	var revision domain.SchemaRevision
+	err := row.Scan(&revision.ID, &revision.ProjectID, &revision.Version, &revision.SchemaJSON, &revision.Source,
+		&revision.AuthorID, &revision.MessageID, &revision.RestoredFrom, &revision.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// Ensure interface compliance at compile time
+var _ SchemaRevisionRepository = (*schemaRevisionRepository)(nil)
@@ -0,0 +1,228 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/query"
+)
+
+// WebhookRepository интерфейс репозитория подписок на события проекта
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *domain.Webhook) error
+	GetByID(ctx context.Context, id string) (*domain.Webhook, error)
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]*domain.Webhook, error)
+	Update(ctx context.Context, webhook *domain.Webhook) error
+	Delete(ctx context.Context, id string) error
+}
+
+// WebhookDeliveryRepository интерфейс журнала доставок вебхуков
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *domain.WebhookDelivery) error
+	ListByWebhook(ctx context.Context, webhookID uuid.UUID, limit, offset int) ([]*domain.WebhookDelivery, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type webhookRepository struct {
+	qb *query.Builder
+}
+
+// NewWebhookRepository создаёт репозиторий подписок
+func NewWebhookRepository(qb *query.Builder) WebhookRepository {
+	return &webhookRepository{qb: qb}
+}
+
+var webhookColumns = []string{"id", "project_id", "url", "secret", "events", "active", "created_at", "updated_at"}
+
+// Create сохраняет подписку
+func (r *webhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+
+	query := r.qb.Insert("webhooks").
+		Columns(webhookColumns...).
+		Values(webhook.ID, webhook.ProjectID, webhook.URL, webhook.Secret, string(events), webhook.Active, webhook.CreatedAt, webhook.UpdatedAt)
+
+	if _, err := r.qb.Execute(query); err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+	return nil
+}
+
+// GetByID получает подписку по ID
+func (r *webhookRepository) GetByID(ctx context.Context, id string) (*domain.Webhook, error) {
+	webhookID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrBadRequest.WithMessage("invalid webhook ID format")
+	}
+
+	query := r.qb.Select(webhookColumns...).
+		From("webhooks").
+		Where(squirrel.Eq{"id": webhookID})
+
+	webhook, err := scanWebhook(r.qb.QueryRow(query))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound.WithMessage("webhook not found")
+		}
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return webhook, nil
+}
+
+// ListByProject возвращает подписки проекта в порядке создания
+func (r *webhookRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]*domain.Webhook, error) {
+	query := r.qb.Select(webhookColumns...).
+		From("webhooks").
+		Where(squirrel.Eq{"project_id": projectID}).
+		OrderBy("created_at ASC")
+
+	rows, err := r.qb.Query(query)
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	defer rows.Close()
+
+	var webhooks []*domain.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, domain.ErrInternal.WithError(err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// Update обновляет подписку
+func (r *webhookRepository) Update(ctx context.Context, webhook *domain.Webhook) error {
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+
+	query := r.qb.Update("webhooks").
+		Set("url", webhook.URL).
+		Set("secret", webhook.Secret).
+		Set("events", string(events)).
+		Set("active", webhook.Active).
+		Set("updated_at", webhook.UpdatedAt).
+		Where(squirrel.Eq{"id": webhook.ID})
+
+	if _, err := r.qb.Execute(query); err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+	return nil
+}
+
+// Delete удаляет подписку вместе с журналом доставок
+func (r *webhookRepository) Delete(ctx context.Context, id string) error {
+	webhookID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrBadRequest.WithMessage("invalid webhook ID format")
+	}
+
+	query := r.qb.Delete("webhooks").
+		Where(squirrel.Eq{"id": webhookID})
+
+	if _, err := r.qb.Execute(query); err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+	return nil
+}
+
+func scanWebhook(row rowScanner) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	var events string
+	err := row.Scan(&webhook.ID, &webhook.ProjectID, &webhook.URL, &webhook.Secret, &events, &webhook.Active, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(events), &webhook.Events); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+type webhookDeliveryRepository struct {
+	qb *query.Builder
+}
+
+// NewWebhookDeliveryRepository создаёт репозиторий журнала доставок
+func NewWebhookDeliveryRepository(qb *query.Builder) WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{qb: qb}
+}
+
+var webhookDeliveryColumns = []string{"id", "webhook_id", "project_id", "event_id", "event", "payload", "attempt", "status_code", "error", "duration_ms", "success", "created_at"}
+
+// Create записывает попытку доставки
+func (r *webhookDeliveryRepository) Create(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	query := r.qb.Insert("webhook_deliveries").
+		Columns(webhookDeliveryColumns...).
+		Values(delivery.ID, delivery.WebhookID, delivery.ProjectID, delivery.EventID, delivery.Event, delivery.Payload,
+			delivery.Attempt, delivery.StatusCode, delivery.Error, delivery.DurationMs, delivery.Success, delivery.CreatedAt)
+
+	if _, err := r.qb.Execute(query); err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+	return nil
+}
+
+// ListByWebhook возвращает попытки доставки подписки, новые первыми
+func (r *webhookDeliveryRepository) ListByWebhook(ctx context.Context, webhookID uuid.UUID, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	query := r.qb.Select(webhookDeliveryColumns...).
+		From("webhook_deliveries").
+		Where(squirrel.Eq{"webhook_id": webhookID}).
+		OrderBy("created_at DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+
+	rows, err := r.qb.Query(query)
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		var delivery domain.WebhookDelivery
+		err := rows.Scan(&delivery.ID, &delivery.WebhookID, &delivery.ProjectID, &delivery.EventID, &delivery.Event, &delivery.Payload,
+			&delivery.Attempt, &delivery.StatusCode, &delivery.Error, &delivery.DurationMs, &delivery.Success, &delivery.CreatedAt)
+		if err != nil {
+			return nil, domain.ErrInternal.WithError(err)
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// DeleteBefore удаляет записи журнала старше before и возвращает их число
+func (r *webhookDeliveryRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := r.qb.Delete("webhook_deliveries").
+		Where(squirrel.Lt{"created_at": before.UTC()})
+
+	result, err := r.qb.Execute(query)
+	if err != nil {
+		return 0, domain.ErrInternal.WithError(err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, domain.ErrInternal.WithError(err)
+	}
+	return deleted, nil
+}
+
+// Ensure interface compliance at compile time
+var _ WebhookRepository = (*webhookRepository)(nil)
+var _ WebhookDeliveryRepository = (*webhookDeliveryRepository)(nil)
@@ -0,0 +1,99 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Операции структурного сравнения
+const (
+	DiffOpAdd     = "add"
+	DiffOpRemove  = "remove"
+	DiffOpReplace = "replace"
+)
+
+// Change одно расхождение между двумя JSON-документами
+type Change struct {
+	Op       string      `json:"op"`
+	Path     string      `json:"path"`
+	OldValue interface{} `json:"old_value,omitempty"`
+	NewValue interface{} `json:"new_value,omitempty"`
+}
+
+// Diff сравнивает документы структурно: объекты — по ключам, массивы — по индексам.
+// Пути записываются так же, как в ValidationError ($.pages[0].blocks[1].props).
+func Diff(oldJSON, newJSON string) ([]Change, error) {
+	var oldDoc, newDoc interface{}
+	if err := json.Unmarshal([]byte(oldJSON), &oldDoc); err != nil {
+		return nil, fmt.Errorf("invalid old document: %w", err)
+	}
+	if err := json.Unmarshal([]byte(newJSON), &newDoc); err != nil {
+		return nil, fmt.Errorf("invalid new document: %w", err)
+	}
+
+	changes := []Change{}
+	diffValues("$", oldDoc, newDoc, &changes)
+	return changes, nil
+}
+
+func diffValues(path string, oldValue, newValue interface{}, changes *[]Change) {
+	switch oldTyped := oldValue.(type) {
+	case map[string]interface{}:
+		if newTyped, ok := newValue.(map[string]interface{}); ok {
+			diffObjects(path, oldTyped, newTyped, changes)
+			return
+		}
+	case []interface{}:
+		if newTyped, ok := newValue.([]interface{}); ok {
+			diffArrays(path, oldTyped, newTyped, changes)
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(oldValue, newValue) {
+		*changes = append(*changes, Change{Op: DiffOpReplace, Path: path, OldValue: oldValue, NewValue: newValue})
+	}
+}
+
+func diffObjects(path string, oldObj, newObj map[string]interface{}, changes *[]Change) {
+	keys := make([]string, 0, len(oldObj)+len(newObj))
+	for key := range oldObj {
+		keys = append(keys, key)
+	}
+	for key := range newObj {
+		if _, ok := oldObj[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		childPath := path + "." + key
+		oldChild, inOld := oldObj[key]
+		newChild, inNew := newObj[key]
+		switch {
+		case !inNew:
+			*changes = append(*changes, Change{Op: DiffOpRemove, Path: childPath, OldValue: oldChild})
+		case !inOld:
+			*changes = append(*changes, Change{Op: DiffOpAdd, Path: childPath, NewValue: newChild})
+		default:
+			diffValues(childPath, oldChild, newChild, changes)
+		}
+	}
+}
+
+func diffArrays(path string, oldArr, newArr []interface{}, changes *[]Change) {
+	for i := 0; i < len(oldArr) || i < len(newArr); i++ {
+		childPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(newArr):
+			*changes = append(*changes, Change{Op: DiffOpRemove, Path: childPath, OldValue: oldArr[i]})
+		case i >= len(oldArr):
+			*changes = append(*changes, Change{Op: DiffOpAdd, Path: childPath, NewValue: newArr[i]})
+		default:
+			diffValues(childPath, oldArr[i], newArr[i], changes)
+		}
+	}
+}
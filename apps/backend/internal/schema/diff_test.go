@@ -0,0 +1,35 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	oldDoc := `{"version":"1.0","theme":{"name":"light"},"pages":[{"path":"/","blocks":[{"type":"hero","props":{"headline":"A"}},{"type":"faq","props":{}}]}]}`
+	newDoc := `{"version":"1.0","pages":[{"path":"/","blocks":[{"type":"hero","props":{"headline":"B","cta":"Go"}}]}],"seo":{"title":"T"}}`
+
+	changes, err := Diff(oldDoc, newDoc)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Change{
+		{Op: DiffOpAdd, Path: "$.pages[0].blocks[0].props.cta", NewValue: "Go"},
+		{Op: DiffOpReplace, Path: "$.pages[0].blocks[0].props.headline", OldValue: "A", NewValue: "B"},
+		{Op: DiffOpRemove, Path: "$.pages[0].blocks[1]", OldValue: map[string]interface{}{"type": "faq", "props": map[string]interface{}{}}},
+		{Op: DiffOpAdd, Path: "$.seo", NewValue: map[string]interface{}{"title": "T"}},
+		{Op: DiffOpRemove, Path: "$.theme", OldValue: map[string]interface{}{"name": "light"}},
+	}, changes)
+}
+
+func TestDiff_Identical(t *testing.T) {
+	changes, err := Diff(`{"a":[1,2]}`, `{ "a": [1, 2] }`)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestDiff_InvalidJSON(t *testing.T) {
+	_, err := Diff(`{`, `{}`)
+	assert.Error(t, err)
+}
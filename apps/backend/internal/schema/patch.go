@@ -0,0 +1,114 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ReplaceValue заменяет значение по пути (ключи объектов — string, индексы массивов — int)
+// и возвращает документ, в котором все байты вне заменённого значения остаются нетронутыми.
+// Если документ отформатирован с отступами, новое значение форматируется под его уровень вложенности.
+func ReplaceValue(doc string, value json.RawMessage, path ...interface{}) (string, error) {
+	if !json.Valid(value) {
+		return "", fmt.Errorf("replacement value is not valid JSON")
+	}
+
+	start, end, err := locate([]byte(doc), 0, path)
+	if err != nil {
+		return "", err
+	}
+
+	formatted, err := formatLike(doc, start, value)
+	if err != nil {
+		return "", err
+	}
+
+	return doc[:start] + formatted + doc[end:], nil
+}
+
+// ExtractValue возвращает исходные байты значения по пути
+func ExtractValue(doc string, path ...interface{}) (json.RawMessage, error) {
+	start, end, err := locate([]byte(doc), 0, path)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(doc[start:end]), nil
+}
+
+// locate возвращает границы значения по пути внутри data (смещения относительно base)
+func locate(data []byte, base int, path []interface{}) (int, int, error) {
+	trimmed := bytes.TrimSpace(data)
+	offset := bytes.Index(data, trimmed)
+	if len(path) == 0 {
+		return base + offset, base + offset + len(trimmed), nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read JSON: %w", err)
+	}
+
+	switch step := path[0].(type) {
+	case string:
+		if tok != json.Delim('{') {
+			return 0, 0, fmt.Errorf("expected object at %q", step)
+		}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return 0, 0, fmt.Errorf("failed to read JSON: %w", err)
+			}
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return 0, 0, fmt.Errorf("failed to read JSON: %w", err)
+			}
+			if keyTok == step {
+				end := int(dec.InputOffset())
+				return locate(raw, base+end-len(raw), path[1:])
+			}
+		}
+		return 0, 0, fmt.Errorf("key %q not found", step)
+	case int:
+		if tok != json.Delim('[') {
+			return 0, 0, fmt.Errorf("expected array at index %d", step)
+		}
+		for i := 0; dec.More(); i++ {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return 0, 0, fmt.Errorf("failed to read JSON: %w", err)
+			}
+			if i == step {
+				end := int(dec.InputOffset())
+				return locate(raw, base+end-len(raw), path[1:])
+			}
+		}
+		return 0, 0, fmt.Errorf("index %d out of range", step)
+	default:
+		return 0, 0, fmt.Errorf("unsupported path element %v", step)
+	}
+}
+
+// formatLike форматирует значение компактно либо с отступом строки, в которой оно начинается
+func formatLike(doc string, start int, value json.RawMessage) (string, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, value); err != nil {
+		return "", err
+	}
+
+	lineStart := strings.LastIndexByte(doc[:start], '\n')
+	if lineStart < 0 {
+		return compact.String(), nil
+	}
+
+	line := doc[lineStart+1 : start]
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, compact.Bytes(), indent, "  "); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,79 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceValue_KeepsRestOfDocumentIntact(t *testing.T) {
+	doc := `{
+  "version": "1.0",
+  "pages": [
+    {
+      "path": "/",
+      "blocks": [
+        {
+          "type": "hero",
+          "props": {"headline": "Старый"},
+          "order": 0
+        },
+        {
+          "order": 1,
+          "props": {
+            "title": "Тарифы"
+          },
+          "type": "pricing"
+        }
+      ]
+    }
+  ]
+}`
+
+	updated, err := ReplaceValue(doc, json.RawMessage(`{"title":"Новые тарифы","plans":[]}`), "pages", 0, "blocks", 1, "props")
+	require.NoError(t, err)
+
+	assert.Equal(t, `{
+  "version": "1.0",
+  "pages": [
+    {
+      "path": "/",
+      "blocks": [
+        {
+          "type": "hero",
+          "props": {"headline": "Старый"},
+          "order": 0
+        },
+        {
+          "order": 1,
+          "props": {
+            "title": "Новые тарифы",
+            "plans": []
+          },
+          "type": "pricing"
+        }
+      ]
+    }
+  ]
+}`, updated)
+}
+
+func TestReplaceValue_CompactDocument(t *testing.T) {
+	doc := `{"pages":[{"blocks":[{"type":"cta","props":{"title":"A"}}]}],"theme":{}}`
+
+	updated, err := ReplaceValue(doc, json.RawMessage(`{ "title": "B" }`), "pages", 0, "blocks", 0, "props")
+	require.NoError(t, err)
+	assert.Equal(t, `{"pages":[{"blocks":[{"type":"cta","props":{"title":"B"}}]}],"theme":{}}`, updated)
+}
+
+func TestReplaceValue_PathNotFound(t *testing.T) {
+	doc := `{"pages":[{"blocks":[]}]}`
+
+	_, err := ReplaceValue(doc, json.RawMessage(`{}`), "pages", 0, "blocks", 3, "props")
+	assert.EqualError(t, err, "index 3 out of range")
+
+	_, err = ReplaceValue(doc, json.RawMessage(`{}`), "pages", 0, "title")
+	assert.EqualError(t, err, `key "title" not found`)
+}
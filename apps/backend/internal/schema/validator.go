@@ -0,0 +1,303 @@
+package schema
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/landly/backend/internal/blocks"
+)
+
+// pageSchemaJSON копия docs/schemas/page_schema.json (синхронность проверяется тестом)
+//
+//go:embed page_schema.json
+var pageSchemaJSON []byte
+
+// ValidationError описывает одно нарушение схемы
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors список нарушений схемы
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	parts := make([]string, len(e))
+	for i, ve := range e {
+		parts[i] = fmt.Sprintf("%s: %s", ve.Path, ve.Message)
+	}
+	return "schema validation failed: " + strings.Join(parts, "; ")
+}
+
+// JSON сериализует список ошибок для хранения в БД
+func (e ValidationErrors) JSON() string {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "[]"
+	}
+	return string(data)
+}
+
+// node узел JSON Schema (поддерживается подмножество draft-07, используемое page_schema.json)
+type node struct {
+	Type       string           `json:"type"`
+	Required   []string         `json:"required"`
+	Properties map[string]*node `json:"properties"`
+	Items      *node            `json:"items"`
+	Enum       []interface{}    `json:"enum"`
+	Const      interface{}      `json:"const"`
+	Pattern    string           `json:"pattern"`
+	Format     string           `json:"format"`
+
+	pattern *regexp.Regexp
+}
+
+// Validator проверяет JSON-схемы лендингов на соответствие page_schema.json
+// и схемам props блоков из реестра
+type Validator struct {
+	root  *node
+	props map[string]*node
+}
+
+// NewValidator создаёт валидатор на основе встроенной page_schema.json и blocks.Default
+func NewValidator() (*Validator, error) {
+	return NewValidatorWithRegistry(blocks.Default)
+}
+
+// NewValidatorWithRegistry создаёт валидатор, допускающий типы блоков из registry
+func NewValidatorWithRegistry(registry *blocks.Registry) (*Validator, error) {
+	var root node
+	if err := json.Unmarshal(pageSchemaJSON, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse page schema: %w", err)
+	}
+
+	blockNode := root.lookup("pages", "items", "blocks", "items")
+	if blockNode == nil || blockNode.Properties["type"] == nil {
+		return nil, fmt.Errorf("page schema has no pages[].blocks[].type")
+	}
+
+	props := make(map[string]*node)
+	var types []interface{}
+	for _, def := range registry.Definitions() {
+		types = append(types, def.Type)
+
+		var propsNode node
+		if err := json.Unmarshal([]byte(def.PropsSchema), &propsNode); err != nil {
+			return nil, fmt.Errorf("failed to parse props schema of block %s: %w", def.Type, err)
+		}
+		if err := propsNode.compile(); err != nil {
+			return nil, fmt.Errorf("block %s: %w", def.Type, err)
+		}
+		props[def.Type] = &propsNode
+	}
+	blockNode.Properties["type"].Enum = types
+
+	if err := root.compile(); err != nil {
+		return nil, err
+	}
+	return &Validator{root: &root, props: props}, nil
+}
+
+// MustNewValidator создаёт валидатор и паникует при ошибке во встроенной схеме
+func MustNewValidator() *Validator {
+	v, err := NewValidator()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Validate проверяет схему лендинга и возвращает список нарушений (nil, если схема корректна)
+func (v *Validator) Validate(schemaJSON string) ValidationErrors {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(schemaJSON), &doc); err != nil {
+		return ValidationErrors{{Path: "$", Message: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+
+	var errs ValidationErrors
+	v.root.validate("$", doc, &errs)
+	v.validateBlockProps(doc, &errs)
+	return errs
+}
+
+// validateBlockProps проверяет props каждого блока по схеме его типа
+func (v *Validator) validateBlockProps(doc interface{}, errs *ValidationErrors) {
+	root, _ := doc.(map[string]interface{})
+	pages, _ := root["pages"].([]interface{})
+	for i, rawPage := range pages {
+		page, _ := rawPage.(map[string]interface{})
+		pageBlocks, _ := page["blocks"].([]interface{})
+		for j, rawBlock := range pageBlocks {
+			block, _ := rawBlock.(map[string]interface{})
+			blockType, _ := block["type"].(string)
+			props, ok := block["props"].(map[string]interface{})
+			propsNode, known := v.props[blockType]
+			if !ok || !known {
+				// Неизвестный тип и не-объект props уже отмечены основной схемой
+				continue
+			}
+			propsNode.validate(fmt.Sprintf("$.pages[%d].blocks[%d].props", i, j), props, errs)
+		}
+	}
+}
+
+// lookup спускается по цепочке properties/items (ключ "items" означает элемент массива)
+func (n *node) lookup(keys ...string) *node {
+	current := n
+	for _, key := range keys {
+		if current == nil {
+			return nil
+		}
+		if key == "items" && current.Items != nil {
+			current = current.Items
+			continue
+		}
+		current = current.Properties[key]
+	}
+	return current
+}
+
+func (n *node) compile() error {
+	if n.Pattern != "" {
+		re, err := regexp.Compile(n.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", n.Pattern, err)
+		}
+		n.pattern = re
+	}
+	for _, child := range n.Properties {
+		if err := child.compile(); err != nil {
+			return err
+		}
+	}
+	if n.Items != nil {
+		return n.Items.compile()
+	}
+	return nil
+}
+
+func (n *node) validate(path string, value interface{}, errs *ValidationErrors) {
+	if n.Type != "" && !matchesType(n.Type, value) {
+		*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf("expected %s, got %s", n.Type, typeName(value))})
+		return
+	}
+
+	if n.Const != nil && !reflect.DeepEqual(n.Const, value) {
+		*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf("must be %v", n.Const)})
+	}
+
+	if len(n.Enum) > 0 && !containsValue(n.Enum, value) {
+		*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf("must be one of %s", formatEnum(n.Enum))})
+	}
+
+	if str, ok := value.(string); ok {
+		if n.pattern != nil && !n.pattern.MatchString(str) {
+			*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf("must match pattern %s", n.Pattern)})
+		}
+		if n.Format == "uri" && !isURI(str) {
+			*errs = append(*errs, ValidationError{Path: path, Message: "must be an absolute URI"})
+		}
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for _, key := range n.Required {
+			if _, ok := typed[key]; !ok {
+				*errs = append(*errs, ValidationError{Path: joinPath(path, key), Message: "is required"})
+			}
+		}
+		keys := make([]string, 0, len(n.Properties))
+		for key := range n.Properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if child, ok := typed[key]; ok {
+				n.Properties[key].validate(joinPath(path, key), child, errs)
+			}
+		}
+	case []interface{}:
+		if n.Items != nil {
+			for i, item := range typed {
+				n.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+			}
+		}
+	}
+}
+
+func matchesType(expected string, value interface{}) bool {
+	switch expected {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		num, ok := value.(float64)
+		return ok && num == math.Trunc(num)
+	default:
+		return true
+	}
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatEnum(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprintf("%v", v)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func isURI(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func joinPath(base, key string) string {
+	return base + "." + key
+}
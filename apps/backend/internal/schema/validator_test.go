@@ -0,0 +1,142 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/landly/backend/internal/blocks"
+	_ "github.com/landly/backend/internal/blocks/countdown"
+	_ "github.com/landly/backend/internal/blocks/form"
+	_ "github.com/landly/backend/internal/blocks/logos"
+)
+
+func TestEmbeddedSchemaMatchesDocs(t *testing.T) {
+	docs, err := os.ReadFile(filepath.Join("..", "..", "..", "..", "docs", "schemas", "page_schema.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, string(docs), string(pageSchemaJSON), "internal/schema/page_schema.json must be kept in sync with docs/schemas/page_schema.json")
+}
+
+func TestValidator_Validate_ValidSchema(t *testing.T) {
+	v := MustNewValidator()
+
+	errs := v.Validate(`{
+		"version": "1.0",
+		"pages": [{
+			"path": "/",
+			"title": "Главная",
+			"blocks": [{"type": "hero", "order": 0, "props": {"headline": "Привет"}}]
+		}],
+		"theme": {"palette": {"primary": "#3B82F6"}, "font": "inter", "borderRadius": "md"},
+		"payment": {"url": "https://pay.example.com"}
+	}`)
+
+	assert.Empty(t, errs)
+}
+
+func TestValidator_Validate_CollectsAllErrors(t *testing.T) {
+	v := MustNewValidator()
+
+	errs := v.Validate(`{
+		"version": "2.0",
+		"pages": [{
+			"path": "about",
+			"blocks": [
+				{"type": "carousel", "order": 1.5, "props": {}},
+				{"type": "hero", "order": 0, "props": "oops"}
+			]
+		}],
+		"theme": {"palette": {"primary": "blue"}},
+		"payment": {"url": "not a url"}
+	}`)
+
+	assert.ElementsMatch(t, ValidationErrors{
+		{Path: "$.version", Message: "must be 1.0"},
+		{Path: "$.pages[0].title", Message: "is required"},
+		{Path: "$.pages[0].path", Message: "must match pattern ^/.*"},
+		{Path: "$.pages[0].blocks[0].type", Message: "must be one of [hero, features, pricing, testimonials, faq, cta, gallery, about, contact, countdown, form, logos]"},
+		{Path: "$.pages[0].blocks[0].order", Message: "expected integer, got number"},
+		{Path: "$.pages[0].blocks[1].props", Message: "expected object, got string"},
+		{Path: "$.theme.palette.primary", Message: "must match pattern ^#[0-9A-Fa-f]{6}$"},
+		{Path: "$.payment.url", Message: "must be an absolute URI"},
+	}, errs)
+}
+
+func TestValidator_Validate_BlockProps(t *testing.T) {
+	v := MustNewValidator()
+
+	errs := v.Validate(`{
+		"version": "1.0",
+		"pages": [{
+			"path": "/",
+			"title": "Главная",
+			"blocks": [
+				{"type": "hero", "order": 0, "props": {"headline": 42}},
+				{"type": "pricing", "order": 1, "props": {"plans": [{"name": "Базовый", "features": "всё"}]}},
+				{"type": "countdown", "order": 2, "props": {"title": "Скидка"}}
+			]
+		}]
+	}`)
+
+	assert.ElementsMatch(t, ValidationErrors{
+		{Path: "$.pages[0].blocks[0].props.headline", Message: "expected string, got number"},
+		{Path: "$.pages[0].blocks[1].props.plans[0].features", Message: "expected array, got string"},
+		{Path: "$.pages[0].blocks[2].props.deadline", Message: "is required"},
+	}, errs)
+}
+
+func TestValidator_Validate_RegisteredExamples(t *testing.T) {
+	v := MustNewValidator()
+
+	for i, def := range blocks.Default.Definitions() {
+		t.Run(def.Type, func(t *testing.T) {
+			doc := fmt.Sprintf(`{"version":"1.0","pages":[{"path":"/","title":"Пример","blocks":[{"type":%q,"order":%d,"props":%s}]}]}`, def.Type, i, def.Example)
+			assert.Empty(t, v.Validate(doc))
+		})
+	}
+}
+
+func TestNewValidatorWithRegistry(t *testing.T) {
+	registry := blocks.NewRegistry()
+	require.NoError(t, registry.Register(blocks.Definition{
+		Type:        "banner",
+		PropsSchema: `{"type":"object","required":["text"]}`,
+		Renderer: blocks.RendererFunc(func(map[string]interface{}, blocks.RenderContext) string {
+			return ""
+		}),
+	}))
+
+	v, err := NewValidatorWithRegistry(registry)
+	require.NoError(t, err)
+
+	errs := v.Validate(`{"version":"1.0","pages":[{"path":"/","title":"T","blocks":[
+		{"type":"banner","order":0,"props":{}},
+		{"type":"hero","order":1,"props":{}}
+	]}]}`)
+
+	assert.ElementsMatch(t, ValidationErrors{
+		{Path: "$.pages[0].blocks[0].props.text", Message: "is required"},
+		{Path: "$.pages[0].blocks[1].type", Message: "must be one of [banner]"},
+	}, errs)
+}
+
+func TestValidator_Validate_InvalidJSON(t *testing.T) {
+	v := MustNewValidator()
+
+	errs := v.Validate(`{"pages": [`)
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, "$", errs[0].Path)
+	assert.Contains(t, errs[0].Message, "invalid JSON")
+}
+
+func TestValidationErrors_JSON(t *testing.T) {
+	errs := ValidationErrors{{Path: "$.pages", Message: "is required"}}
+
+	assert.JSONEq(t, `[{"path":"$.pages","message":"is required"}]`, errs.JSON())
+	assert.Equal(t, "schema validation failed: $.pages: is required", errs.Error())
+}
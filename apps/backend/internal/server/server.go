@@ -2,25 +2,45 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"sync"
 
-	"github.com/gin-gonic/gin"
 	"github.com/landly/backend/config"
+	"github.com/landly/backend/internal/certs"
 	"github.com/landly/backend/internal/database/postgres"
 	"github.com/landly/backend/internal/handlers"
+	"github.com/landly/backend/internal/jobs"
 	"github.com/landly/backend/internal/repositories"
 	"github.com/landly/backend/internal/services"
 	"github.com/landly/backend/internal/storage/ai"
 	"github.com/landly/backend/internal/storage/render"
 	"github.com/landly/backend/internal/storage/s3"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
+
+	// Пользовательские блоки регистрируются в blocks.Default при импорте
+	_ "github.com/landly/backend/internal/blocks/countdown"
+	_ "github.com/landly/backend/internal/blocks/form"
+	_ "github.com/landly/backend/internal/blocks/logos"
 )
 
 // Server представляет HTTP сервер приложения
 type Server struct {
-	engine *gin.Engine
-	config *config.Config
-	logger *zap.Logger
+	handler     http.Handler
+	certManager *certs.Manager
+	config      *config.Config
+	logger      *zap.Logger
+
+	httpServer *http.Server
+	tlsServer  *http.Server
+
+	mu sync.Mutex
+	// stopCerts останавливает выпуск и продление сертификатов
+	stopCerts context.CancelFunc
 }
 
 // NewServer создает новый сервер с инициализированными зависимостями
@@ -45,10 +65,19 @@ func NewServer(cfg *config.Config, logger *zap.Logger) (*Server, error) {
 	userRepo := repositories.NewUserRepository(qb)
 	projectRepo := repositories.NewProjectRepository(qb)
 	analyticsRepo := repositories.NewAnalyticsRepository(qb)
+	analyticsGoalRepo := repositories.NewAnalyticsGoalRepository(qb)
 	integrationRepo := repositories.NewIntegrationRepository(qb)
 	publishTargetRepo := repositories.NewPublishTargetRepository(qb)
 	sessionRepo := repositories.NewGenerationSessionRepository(qb)
 	messageRepo := repositories.NewGenerationMessageRepository(qb)
+	revisionRepo := repositories.NewSchemaRevisionRepository(qb)
+	deploymentRepo := repositories.NewDeploymentRepository(qb)
+	variantRepo := repositories.NewProjectVariantRepository(qb)
+	jobRepo := repositories.NewJobRepository(qb)
+	purchaseRepo := repositories.NewPurchaseRepository(qb)
+	formSubmissionRepo := repositories.NewFormSubmissionRepository(qb)
+	webhookRepo := repositories.NewWebhookRepository(qb)
+	webhookDeliveryRepo := repositories.NewWebhookDeliveryRepository(qb)
 
 	// S3 клиент
 	s3Client, err := s3.NewClient(s3.Config{
@@ -65,22 +94,92 @@ func NewServer(cfg *config.Config, logger *zap.Logger) (*Server, error) {
 
 	// AI клиент
 	var aiClient services.AIClient
-	if cfg.AI.Provider == "mock" {
+	switch cfg.AI.Provider {
+	case "mock":
 		aiClient = ai.NewMockClient()
-	} else {
+	case "openai":
+		aiClient = ai.NewOpenAIClient(ai.OpenAIConfig{
+			APIKey:      cfg.AI.OpenAI.APIKey,
+			Model:       cfg.AI.OpenAI.Model,
+			BaseURL:     cfg.AI.OpenAI.BaseURL,
+			MaxTokens:   cfg.AI.OpenAI.MaxTokens,
+			Temperature: cfg.AI.OpenAI.Temperature,
+		})
+	case "anthropic":
+		aiClient = ai.NewAnthropicClient(ai.AnthropicConfig{
+			APIKey:    cfg.AI.Anthropic.APIKey,
+			Model:     cfg.AI.Anthropic.Model,
+			BaseURL:   cfg.AI.Anthropic.BaseURL,
+			MaxTokens: cfg.AI.Anthropic.MaxTokens,
+		})
+	default:
 		return nil, fmt.Errorf("AI provider not implemented: %s", cfg.AI.Provider)
 	}
 
 	// Renderer
 	renderer := render.NewStaticRenderer(cfg.Render.TmpDir)
+	renderer.SetAPIBaseURL(cfg.App.BaseURL)
+	if cfg.Render.ThemesDir != "" {
+		if err := renderer.LoadThemes(cfg.Render.ThemesDir); err != nil {
+			return nil, fmt.Errorf("failed to load themes: %w", err)
+		}
+	}
+
+	// Очередь фоновых задач: генерацию и публикацию выполняет cmd/worker
+	broker, err := jobs.NewBroker(context.Background(), jobs.BrokerConfig{
+		Backend: cfg.Jobs.Backend,
+		Redis: &redis.Options{
+			Addr:     cfg.Database.Redis.Addr,
+			Password: cfg.Database.Redis.Password,
+			DB:       cfg.Database.Redis.DB,
+			PoolSize: cfg.Database.Redis.PoolSize,
+		},
+	}, jobRepo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job broker: %w", err)
+	}
+	jobQueue := jobs.NewQueue(jobRepo, broker, cfg.Jobs.MaxAttempts)
 
 	// Services
 	authService := services.NewAuthService(userRepo, cfg.Auth.JWT.Secret, cfg.Auth.JWT.AccessTokenTTL, cfg.Auth.JWT.RefreshTokenTTL)
 	projectService := services.NewProjectService(projectRepo)
 	generateService := services.NewGenerateService(projectRepo, integrationRepo, sessionRepo, messageRepo, aiClient)
-	publishService := services.NewPublishService(projectRepo, publishTargetRepo, userRepo, renderer, s3Client, cfg.App.BaseURL)
+	generateService.SetSchemaRepairAttempts(cfg.AI.RepairAttempts)
+	generateService.SetRevisionRepository(revisionRepo)
+	generateService.SetJobQueue(jobQueue)
+	publishService := services.NewPublishService(projectRepo, publishTargetRepo, deploymentRepo, userRepo, renderer, s3Client, cfg.App.BaseURL)
+	publishService.SetJobQueue(jobQueue)
+	publishService.SetVariantRepository(variantRepo)
 	simpleGenerateService := services.NewSimpleGenerateService(projectRepo, aiClient)
+	simpleGenerateService.SetSchemaRepairAttempts(cfg.AI.RepairAttempts)
+	simpleGenerateService.SetRevisionRepository(revisionRepo)
 	analyticsService := services.NewAnalyticsService(projectRepo, analyticsRepo)
+	analyticsService.SetIPAnonymization(cfg.Analytics.IPAnonymization)
+	analyticsService.SetGoalRepository(analyticsGoalRepo)
+	experimentService := services.NewExperimentService(projectRepo, variantRepo, analyticsRepo)
+	experimentService.SetGoalRepository(analyticsGoalRepo)
+	experimentService.SetRevisionRepository(revisionRepo)
+	integrationService := services.NewIntegrationService(projectRepo, integrationRepo)
+	renderer.SetPaymentLinks(integrationService)
+	purchaseService := services.NewPurchaseService(projectRepo, integrationRepo, purchaseRepo)
+	formService := services.NewFormService(projectRepo, variantRepo, publishTargetRepo, deploymentRepo, formSubmissionRepo, cfg.Auth.JWT.Secret)
+	webhookService := services.NewWebhookService(projectRepo, webhookRepo, webhookDeliveryRepo, jobQueue)
+	publishService.SetEventEmitter(webhookService)
+	analyticsService.SetEventEmitter(webhookService)
+	purchaseService.SetEventEmitter(webhookService)
+	formService.SetEventEmitter(webhookService)
+	customDomainService := services.NewCustomDomainService(projectRepo, publishTargetRepo, net.DefaultResolver, cfg.App.BaseURL)
+
+	var certManager *certs.Manager
+	if cfg.Server.ACME.Enabled {
+		certManager = certs.NewManager(repositories.NewCertificateRepository(qb), publishTargetRepo, certs.ManagerConfig{
+			DirectoryURL:  cfg.Server.ACME.DirectoryURL,
+			Email:         cfg.Server.ACME.Email,
+			RenewBefore:   cfg.Server.ACME.RenewBefore,
+			CheckInterval: cfg.Server.ACME.CheckInterval,
+		})
+		customDomainService.SetCertificateIssuer(certManager)
+	}
 
 	// HTTP handlers
 	authHandler := handlers.NewAuthHandler(authService)
@@ -88,6 +187,15 @@ func NewServer(cfg *config.Config, logger *zap.Logger) (*Server, error) {
 	generateHandler := handlers.NewGenerateHandler(generateService, publishService, cfg.App.BaseURL)
 	simpleGenerateHandler := handlers.NewSimpleGenerateHandler(simpleGenerateService)
 	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
+	schemaRevisionHandler := handlers.NewSchemaRevisionHandler(services.NewSchemaRevisionService(projectRepo, revisionRepo))
+	deploymentHandler := handlers.NewDeploymentHandler(publishService)
+	jobHandler := handlers.NewJobHandler(services.NewJobService(jobRepo, jobQueue))
+	experimentHandler := handlers.NewExperimentHandler(experimentService)
+	domainHandler := handlers.NewDomainHandler(customDomainService, publishService, cfg.App.BaseURL)
+	integrationHandler := handlers.NewIntegrationHandler(integrationService)
+	purchaseHandler := handlers.NewPurchaseHandler(purchaseService)
+	formHandler := handlers.NewFormHandler(formService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
 
 	// Router
 	router := handlers.NewRouter(
@@ -96,6 +204,15 @@ func NewServer(cfg *config.Config, logger *zap.Logger) (*Server, error) {
 		generateHandler,
 		simpleGenerateHandler,
 		analyticsHandler,
+		schemaRevisionHandler,
+		deploymentHandler,
+		jobHandler,
+		experimentHandler,
+		domainHandler,
+		integrationHandler,
+		purchaseHandler,
+		formHandler,
+		webhookHandler,
 		cfg.Auth.JWT.Secret,
 		cfg.Server.CORS.AllowedOrigins,
 		cfg.Server.CORS.AllowedMethods,
@@ -103,26 +220,70 @@ func NewServer(cfg *config.Config, logger *zap.Logger) (*Server, error) {
 		logger,
 	)
 
-	engine := router.Setup()
+	handler := router.Handler()
+	if certManager != nil {
+		handler = certManager.HTTPHandler(handler)
+	}
 
-	return &Server{
-		engine: engine,
-		config: cfg,
-		logger: logger,
-	}, nil
+	srv := &Server{
+		handler:     handler,
+		certManager: certManager,
+		config:      cfg,
+		logger:      logger,
+		httpServer:  &http.Server{Addr: cfg.Server.HTTP.Addr, Handler: handler},
+	}
+	if certManager != nil {
+		srv.tlsServer = &http.Server{
+			Addr:      cfg.Server.ACME.HTTPSAddr,
+			Handler:   handler,
+			TLSConfig: &tls.Config{GetCertificate: certManager.GetCertificate, MinVersion: tls.VersionTLS12},
+		}
+	}
+
+	return srv, nil
 }
 
-// Start запускает HTTP сервер
-func (s *Server) Start() error {
-	addr := s.config.Server.HTTP.Addr
-	s.logger.Info("starting HTTP server", zap.String("addr", addr))
+// Start запускает HTTP сервер; ctx ограничивает жизнь фонового выпуска сертификатов.
+// Возвращает http.ErrServerClosed после Shutdown.
+func (s *Server) Start(ctx context.Context) error {
+	s.logger.Info("starting HTTP server", zap.String("addr", s.httpServer.Addr))
+
+	if s.certManager != nil {
+		certCtx, stopCerts := context.WithCancel(ctx)
+		s.mu.Lock()
+		s.stopCerts = stopCerts
+		s.mu.Unlock()
+		go s.certManager.Run(certCtx)
+
+		go func() {
+			s.logger.Info("starting HTTPS server", zap.String("addr", s.tlsServer.Addr))
+			if err := s.tlsServer.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+				s.logger.Error("https server stopped", zap.Error(err))
+			}
+		}()
+	}
 
-	return s.engine.Run(addr)
+	return s.httpServer.ListenAndServe()
 }
 
-// Shutdown корректно останавливает сервер
+// Shutdown корректно останавливает HTTP и HTTPS серверы и выпуск сертификатов
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info("shutting down HTTP server")
-	// Здесь можно добавить graceful shutdown логику
-	return nil
+
+	s.mu.Lock()
+	if s.stopCerts != nil {
+		s.stopCerts()
+	}
+	s.mu.Unlock()
+
+	var errs []error
+	if s.tlsServer != nil {
+		if err := s.tlsServer.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("https server: %w", err))
+		}
+	}
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("http server: %w", err))
+	}
+	return errors.Join(errs...)
 }
@@ -2,6 +2,10 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	domain "github.com/landly/backend/internal/models"
@@ -9,8 +13,14 @@ import (
 
 // AnalyticsService сервис для аналитики
 type AnalyticsService struct {
-	projectRepo   domain.ProjectRepository
-	analyticsRepo domain.AnalyticsRepository
+	projectRepo     domain.ProjectRepository
+	analyticsRepo   domain.AnalyticsRepository
+	goalRepo        domain.AnalyticsGoalRepository
+	goals           projectCache[[]*domain.AnalyticsGoal]
+	events          EventEmitter
+	ipAnonymization string
+	retention       time.Duration
+	salts           saltCache
 }
 
 // NewAnalyticsService создаёт новый analytics service
@@ -19,29 +29,113 @@ func NewAnalyticsService(
 	analyticsRepo domain.AnalyticsRepository,
 ) *AnalyticsService {
 	return &AnalyticsService{
-		projectRepo:   projectRepo,
-		analyticsRepo: analyticsRepo,
+		projectRepo:     projectRepo,
+		analyticsRepo:   analyticsRepo,
+		ipAnonymization: IPAnonymizationFull,
 	}
 }
 
-// TrackEvent отслеживает событие (новый интерфейс)
+const (
+	// MaxTrackBatchSize сколько событий принимает один beacon-запрос
+	MaxTrackBatchSize = 50
+
+	maxTrackPathLength      = 255
+	maxTrackReferrerLength  = 2048
+	maxTrackUserAgentLength = 512
+	maxTrackVariantLength   = 64
+)
+
+// TrackEvent сохраняет событие с опубликованного сайта
 func (s *AnalyticsService) TrackEvent(ctx context.Context, req *domain.TrackEventRequest) error {
-	// Создаем событие
-	event := &domain.AnalyticsEvent{
-		ID:        uuid.New(),
-		EventType: req.EventType,
-		Path:      req.Path,
-		Referrer:  req.Referrer,
+	event, err := newTrackedEvent(req)
+	if err != nil {
+		return err
+	}
+	if err := s.protectVisitor(ctx, event); err != nil {
+		return err
 	}
 
 	if err := s.analyticsRepo.TrackEvent(ctx, event); err != nil {
-		// Не критично, можем игнорировать ошибки трекинга
-		return nil
+		return domain.ErrInternal.WithError(err)
 	}
 
+	s.emitGoalsReached(ctx, []*domain.AnalyticsEvent{event})
 	return nil
 }
 
+// TrackEvents сохраняет пачку событий из beacon. Невалидное событие отклоняет всю пачку.
+func (s *AnalyticsService) TrackEvents(ctx context.Context, reqs []*domain.TrackEventRequest) error {
+	if len(reqs) == 0 {
+		return domain.ErrBadRequest.WithMessage("events are required")
+	}
+	if len(reqs) > MaxTrackBatchSize {
+		return domain.ErrBadRequest.WithMessage(fmt.Sprintf("at most %d events per batch", MaxTrackBatchSize))
+	}
+
+	events := make([]*domain.AnalyticsEvent, 0, len(reqs))
+	for _, req := range reqs {
+		event, err := newTrackedEvent(req)
+		if err != nil {
+			return err
+		}
+		if err := s.protectVisitor(ctx, event); err != nil {
+			return err
+		}
+		events = append(events, event)
+	}
+
+	if err := s.analyticsRepo.TrackEvents(ctx, events); err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+
+	s.emitGoalsReached(ctx, events)
+	return nil
+}
+
+// newTrackedEvent проверяет запрос трекинга и приводит поля к размерам колонок
+func newTrackedEvent(req *domain.TrackEventRequest) (*domain.AnalyticsEvent, error) {
+	if req.ProjectID == uuid.Nil {
+		return nil, domain.ErrBadRequest.WithMessage("project ID is required")
+	}
+	if !domain.IsValidAnalyticsEventType(req.EventType) {
+		return nil, domain.ErrBadRequest.WithMessage(fmt.Sprintf("unknown event type %q", req.EventType))
+	}
+
+	path := req.Path
+	if path == "" {
+		path = "/"
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, domain.ErrBadRequest.WithMessage("path must start with /")
+	}
+	// UTM-метки хранятся в своих колонках, в path остаётся только путь
+	path, query, _ := strings.Cut(path, "?")
+	path, _, _ = strings.Cut(path, "#")
+	query, _, _ = strings.Cut(query, "#")
+
+	event := domain.NewAnalyticsEvent(
+		req.ProjectID,
+		req.EventType,
+		truncateRunes(path, maxTrackPathLength),
+		truncateRunes(req.Referrer, maxTrackReferrerLength),
+		truncateRunes(req.UserAgent, maxTrackUserAgentLength),
+		req.IPAddress,
+	)
+	describeVisit(event, req.URL, query)
+	event.Variant = truncateRunes(strings.TrimSpace(req.Variant), maxTrackVariantLength)
+
+	return event, nil
+}
+
+// truncateRunes обрезает строку до limit символов (VARCHAR считает символы, а не байты)
+func truncateRunes(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit])
+}
+
 // GetProjectAnalytics получает аналитику проекта
 func (s *AnalyticsService) GetProjectAnalytics(ctx context.Context, userID, projectID string) (*domain.ProjectAnalytics, error) {
 	projectUUID, err := uuid.Parse(projectID)
@@ -136,3 +230,235 @@ func (s *AnalyticsService) GetProjectEvents(ctx context.Context, userID, project
 
 	return events, nil
 }
+
+const (
+	defaultDailyRange  = 30 * 24 * time.Hour
+	defaultHourlyRange = 24 * time.Hour
+	maxDailyRange      = 366 * 24 * time.Hour
+	maxHourlyRange     = 31 * 24 * time.Hour
+)
+
+// GetTimeSeries возвращает ряд из агрегатов за период и такой же по длине предыдущий период.
+// Данные за последние минуты появляются после очередного прохода агрегатора.
+func (s *AnalyticsService) GetTimeSeries(ctx context.Context, userID, projectID string, q domain.AnalyticsTimeSeriesQuery) (*domain.AnalyticsTimeSeries, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	granularity, from, to, err := normalizeTimeSeriesQuery(q, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	previousFrom := from.Add(-to.Sub(from))
+
+	// Оба периода одним запросом: они идут подряд
+	rollups, err := s.analyticsRepo.GetRollups(ctx, project.ID, granularity, previousFrom, to)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &domain.AnalyticsTimeSeries{
+		Granularity: granularity,
+		Current:     buildAnalyticsPeriod(rollups, granularity, from, to),
+		Previous:    buildAnalyticsPeriod(rollups, granularity, previousFrom, from),
+	}
+	series.Change = domain.AnalyticsChange{
+		PageViews:      percentChange(series.Previous.Totals.TotalPageViews, series.Current.Totals.TotalPageViews),
+		UniqueVisitors: percentChange(series.Previous.Totals.UniqueVisitors, series.Current.Totals.UniqueVisitors),
+		CTAClicks:      percentChange(series.Previous.Totals.CTAClicks, series.Current.Totals.CTAClicks),
+		PayClicks:      percentChange(series.Previous.Totals.PayClicks, series.Current.Totals.PayClicks),
+	}
+
+	return series, nil
+}
+
+// normalizeTimeSeriesQuery выравнивает границы по интервалам и подставляет значения по умолчанию:
+// по суткам — последние 30 дней, по часам — последние 24 часа, включая текущий интервал
+func normalizeTimeSeriesQuery(q domain.AnalyticsTimeSeriesQuery, now time.Time) (domain.AnalyticsGranularity, time.Time, time.Time, error) {
+	granularity := q.Granularity
+	if granularity == "" {
+		granularity = domain.AnalyticsGranularityDay
+	}
+
+	var defaultRange, maxRange time.Duration
+	switch granularity {
+	case domain.AnalyticsGranularityDay:
+		defaultRange, maxRange = defaultDailyRange, maxDailyRange
+	case domain.AnalyticsGranularityHour:
+		defaultRange, maxRange = defaultHourlyRange, maxHourlyRange
+	default:
+		return "", time.Time{}, time.Time{}, domain.ErrBadRequest.WithMessage("granularity must be hour or day")
+	}
+
+	to := q.To
+	if to.IsZero() {
+		to = now
+	}
+	// Неполный последний интервал включается целиком
+	if aligned := granularity.Truncate(to); !aligned.Equal(to) {
+		to = aligned.Add(granularity.Duration())
+	}
+
+	from := q.From
+	if from.IsZero() {
+		from = to.Add(-defaultRange)
+	}
+	from = granularity.Truncate(from)
+
+	if !from.Before(to) {
+		return "", time.Time{}, time.Time{}, domain.ErrBadRequest.WithMessage("from must be before to")
+	}
+	if to.Sub(from) > maxRange {
+		return "", time.Time{}, time.Time{}, domain.ErrBadRequest.WithMessage(fmt.Sprintf("range is too long for %s granularity", granularity))
+	}
+
+	return granularity, from, to, nil
+}
+
+// buildAnalyticsPeriod раскладывает агрегаты по интервалам [from, to), заполняя пропуски нулями
+func buildAnalyticsPeriod(rollups []*domain.AnalyticsRollup, granularity domain.AnalyticsGranularity, from, to time.Time) domain.AnalyticsPeriod {
+	byBucket := make(map[time.Time]*domain.AnalyticsRollup, len(rollups))
+	for _, rollup := range rollups {
+		byBucket[rollup.Bucket.UTC()] = rollup
+	}
+
+	period := domain.AnalyticsPeriod{From: from, To: to}
+	for bucket := from; bucket.Before(to); bucket = bucket.Add(granularity.Duration()) {
+		point := domain.AnalyticsRollup{Bucket: bucket}
+		if rollup, ok := byBucket[bucket]; ok {
+			point.ProjectID = rollup.ProjectID
+			point.PageViews = rollup.PageViews
+			point.UniqueVisitors = rollup.UniqueVisitors
+			point.CTAClicks = rollup.CTAClicks
+			point.PayClicks = rollup.PayClicks
+		}
+
+		period.Points = append(period.Points, point)
+		period.Totals.TotalPageViews += point.PageViews
+		period.Totals.UniqueVisitors += point.UniqueVisitors
+		period.Totals.CTAClicks += point.CTAClicks
+		period.Totals.PayClicks += point.PayClicks
+	}
+
+	return period
+}
+
+func percentChange(previous, current int) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := math.Round(float64(current-previous)/float64(previous)*1000) / 10
+	return &change
+}
+
+// rollupGranularities интервалы, по которым воркер строит агрегаты
+var rollupGranularities = []domain.AnalyticsGranularity{domain.AnalyticsGranularityHour, domain.AnalyticsGranularityDay}
+
+// RollupRecent пересчитывает агрегаты текущего и предыдущего интервалов: события приходят
+// с задержкой (beacon при уходе со страницы), поэтому закрытый интервал ещё может измениться.
+// Если воркер простаивал дольше, пересчёт начинается с интервала, на котором он остановился.
+func (s *AnalyticsService) RollupRecent(ctx context.Context, now time.Time) error {
+	for _, granularity := range rollupGranularities {
+		from := granularity.Truncate(now).Add(-granularity.Duration())
+
+		watermark, err := s.analyticsRepo.GetRollupWatermark(ctx, granularity)
+		if err != nil {
+			return err
+		}
+		if !watermark.IsZero() && watermark.Before(from) {
+			from = granularity.Truncate(watermark)
+		}
+
+		if err := s.analyticsRepo.RollupEvents(ctx, granularity, from, now); err != nil {
+			return err
+		}
+		if err := s.analyticsRepo.SetRollupWatermark(ctx, granularity, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	defaultBreakdownRange = 30 * 24 * time.Hour
+	maxBreakdownRange     = 366 * 24 * time.Hour
+	defaultBreakdownLimit = 10
+	maxBreakdownLimit     = 100
+)
+
+// GetBreakdown возвращает самые частые значения разреза (UTM-метки, источник, браузер, ОС, устройство)
+// по сырым событиям за период; по умолчанию — последние 30 дней
+func (s *AnalyticsService) GetBreakdown(ctx context.Context, userID, projectID string, q domain.AnalyticsBreakdownQuery) (*domain.AnalyticsBreakdown, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, limit, err := normalizeBreakdownQuery(q, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.analyticsRepo.GetBreakdown(ctx, project.ID, q.Dimension, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if item.Value == "" {
+			item.Value = emptyBreakdownValue(q.Dimension)
+		}
+	}
+	if items == nil {
+		items = []*domain.AnalyticsBreakdownItem{}
+	}
+
+	return &domain.AnalyticsBreakdown{
+		Dimension: q.Dimension,
+		From:      from,
+		To:        to,
+		Items:     items,
+	}, nil
+}
+
+func normalizeBreakdownQuery(q domain.AnalyticsBreakdownQuery, now time.Time) (time.Time, time.Time, int, error) {
+	from, to, err := normalizeAnalyticsRange(q.From, q.To, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, 0, err
+	}
+
+	limit := q.Limit
+	switch {
+	case limit == 0:
+		limit = defaultBreakdownLimit
+	case limit < 0 || limit > maxBreakdownLimit:
+		return time.Time{}, time.Time{}, 0, domain.ErrBadRequest.WithMessage(fmt.Sprintf("limit must be between 1 and %d", maxBreakdownLimit))
+	}
+
+	return from, to, limit, nil
+}
+
+// normalizeAnalyticsRange период отчётов по сырым событиям: по умолчанию последние 30 дней до now
+func normalizeAnalyticsRange(from, to, now time.Time) (time.Time, time.Time, error) {
+	if to.IsZero() {
+		to = now
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultBreakdownRange)
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, domain.ErrBadRequest.WithMessage("from must be before to")
+	}
+	if to.Sub(from) > maxBreakdownRange {
+		return time.Time{}, time.Time{}, domain.ErrBadRequest.WithMessage("range is too long")
+	}
+	return from.UTC(), to.UTC(), nil
+}
+
+// emptyBreakdownValue подпись для событий без значения разреза
+func emptyBreakdownValue(dimension domain.AnalyticsDimension) string {
+	if dimension == domain.AnalyticsDimensionReferrer {
+		return "(direct)"
+	}
+	return "(none)"
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/landly/backend/internal/logger"
+	domain "github.com/landly/backend/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	maxGoalsPerProject = 20
+	maxGoalNameLength  = 100
+)
+
+var errGoalsNotConfigured = domain.ErrInternal.WithMessage("analytics goals are not configured")
+
+// defaultFunnelSteps путь посетителя лендинга: просмотр → CTA → оплата
+var defaultFunnelSteps = []domain.AnalyticsFunnelStep{
+	{Name: "Pageview", EventType: domain.AnalyticsEventPageview},
+	{Name: "CTA click", EventType: domain.AnalyticsEventCTAClick},
+	{Name: "Pay click", EventType: domain.AnalyticsEventPayClick},
+}
+
+// SetGoalRepository подключает хранилище целей проекта
+func (s *AnalyticsService) SetGoalRepository(goalRepo domain.AnalyticsGoalRepository) {
+	s.goalRepo = goalRepo
+}
+
+// SetEventEmitter отправляет событие goal.reached подписчикам вебхуков, когда событие
+// трекинга совпадает с целью проекта
+func (s *AnalyticsService) SetEventEmitter(events EventEmitter) {
+	s.events = events
+}
+
+// ListGoals возвращает цели проекта
+func (s *AnalyticsService) ListGoals(ctx context.Context, userID, projectID string) ([]*domain.AnalyticsGoal, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return s.projectGoals(ctx, project.ID)
+}
+
+// CreateGoal добавляет цель проекта
+func (s *AnalyticsService) CreateGoal(ctx context.Context, userID, projectID string, req *domain.AnalyticsGoalRequest) (*domain.AnalyticsGoal, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.goalRepo == nil {
+		return nil, errGoalsNotConfigured
+	}
+
+	name, path, err := validateGoalRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	goals, err := s.projectGoals(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(goals) >= maxGoalsPerProject {
+		return nil, domain.ErrBadRequest.WithMessage(fmt.Sprintf("a project can have at most %d goals", maxGoalsPerProject))
+	}
+
+	goal := domain.NewAnalyticsGoal(project.ID, name, req.EventType, path)
+	if err := s.goalRepo.Create(ctx, goal); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	s.goals.forget(project.ID)
+
+	return goal, nil
+}
+
+// UpdateGoal изменяет цель проекта
+func (s *AnalyticsService) UpdateGoal(ctx context.Context, userID, projectID, goalID string, req *domain.AnalyticsGoalRequest) (*domain.AnalyticsGoal, error) {
+	goal, err := s.projectGoal(ctx, userID, projectID, goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, path, err := validateGoalRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	goal.Name = name
+	goal.EventType = req.EventType
+	goal.Path = path
+	goal.UpdatedAt = time.Now()
+	if err := s.goalRepo.Update(ctx, goal); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	s.goals.forget(goal.ProjectID)
+
+	return goal, nil
+}
+
+// DeleteGoal удаляет цель проекта
+func (s *AnalyticsService) DeleteGoal(ctx context.Context, userID, projectID, goalID string) error {
+	goal, err := s.projectGoal(ctx, userID, projectID, goalID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.goalRepo.Delete(ctx, goal.ID.String()); err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+	s.goals.forget(goal.ProjectID)
+	return nil
+}
+
+// GetFunnel строит воронку просмотр → CTA → оплата по хэшам посетителей и конверсии целей проекта
+func (s *AnalyticsService) GetFunnel(ctx context.Context, userID, projectID string, q domain.AnalyticsRangeQuery) (*domain.AnalyticsFunnel, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, err := normalizeAnalyticsRange(q.From, q.To, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	goals, err := s.projectGoals(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	goalSteps := make([]domain.AnalyticsFunnelStep, len(goals))
+	for i, goal := range goals {
+		goalSteps[i] = domain.AnalyticsFunnelStep{Name: goal.Name, EventType: goal.EventType, Path: goal.Path}
+	}
+
+	counts, goalCounts, err := s.analyticsRepo.GetFunnel(ctx, project.ID, defaultFunnelSteps, goalSteps, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	funnel := &domain.AnalyticsFunnel{
+		From:  from,
+		To:    to,
+		Steps: buildFunnelSteps(defaultFunnelSteps, counts),
+		Goals: []domain.AnalyticsGoalConversion{},
+	}
+
+	// Конверсия цели — доля посетителей, дошедших до неё после просмотра любой страницы
+	visitors := funnel.Steps[0].Visitors
+	for i, goal := range goals {
+		funnel.Goals = append(funnel.Goals, domain.AnalyticsGoalConversion{
+			Goal:           goal,
+			Visitors:       goalCounts[i],
+			ConversionRate: ratePercent(goalCounts[i], visitors),
+		})
+	}
+
+	return funnel, nil
+}
+
+// buildFunnelSteps считает конверсию и потери каждого шага
+func buildFunnelSteps(steps []domain.AnalyticsFunnelStep, counts []int) []domain.AnalyticsFunnelStepResult {
+	results := make([]domain.AnalyticsFunnelStepResult, len(steps))
+	for i, step := range steps {
+		results[i] = domain.AnalyticsFunnelStepResult{AnalyticsFunnelStep: step, Visitors: counts[i]}
+		results[i].ConversionRate = ratePercent(counts[i], counts[0])
+		if i > 0 {
+			previous := counts[i-1]
+			results[i].StepConversionRate = ratePercent(counts[i], previous)
+			results[i].DropOff = previous - counts[i]
+			results[i].DropOffRate = ratePercent(results[i].DropOff, previous)
+		}
+	}
+	return results
+}
+
+// ratePercent доля part от base в процентах с одним знаком; nil, если base равна нулю
+func ratePercent(part, base int) *float64 {
+	if base == 0 {
+		return nil
+	}
+	rate := math.Round(float64(part)/float64(base)*1000) / 10
+	return &rate
+}
+
+func validateGoalRequest(req *domain.AnalyticsGoalRequest) (string, string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return "", "", domain.ErrBadRequest.WithMessage("goal name is required")
+	}
+	if len([]rune(name)) > maxGoalNameLength {
+		return "", "", domain.ErrBadRequest.WithMessage(fmt.Sprintf("goal name must be at most %d characters", maxGoalNameLength))
+	}
+	if !domain.IsValidAnalyticsEventType(req.EventType) {
+		return "", "", domain.ErrBadRequest.WithMessage(fmt.Sprintf("unknown event type %q", req.EventType))
+	}
+
+	path := strings.TrimSpace(req.Path)
+	if path != "" && !strings.HasPrefix(path, "/") {
+		return "", "", domain.ErrBadRequest.WithMessage("path must start with /")
+	}
+	if len([]rune(path)) > maxTrackPathLength {
+		return "", "", domain.ErrBadRequest.WithMessage(fmt.Sprintf("path must be at most %d characters", maxTrackPathLength))
+	}
+
+	return name, path, nil
+}
+
+// emitGoalsReached отправляет goal.reached для событий, совпавших с целями проекта.
+// Цель совпадает по типу события и пути; пустой путь цели — любая страница. Боты не учитываются.
+// Событие уходит не чаще раза в сутки на посетителя и цель; без подписчиков цели не проверяются.
+func (s *AnalyticsService) emitGoalsReached(ctx context.Context, events []*domain.AnalyticsEvent) {
+	if s.events == nil || s.goalRepo == nil {
+		return
+	}
+
+	goalsByProject := make(map[uuid.UUID][]*domain.AnalyticsGoal)
+	for _, event := range events {
+		if event.DeviceType == domain.DeviceTypeBot {
+			continue
+		}
+
+		goals, ok := goalsByProject[event.ProjectID]
+		if !ok {
+			if s.events.Subscribed(ctx, event.ProjectID, domain.WebhookEventGoalReached) {
+				goals = s.cachedGoals(ctx, event.ProjectID)
+			}
+			goalsByProject[event.ProjectID] = goals
+		}
+
+		for _, goal := range goals {
+			if goal.EventType != event.EventType || (goal.Path != "" && goal.Path != event.Path) {
+				continue
+			}
+			first, err := s.goalRepo.MarkReached(ctx, goal.ID, event.VisitorHash, event.CreatedAt)
+			if err != nil {
+				logger.WithContext(ctx).Error("failed to mark goal as reached",
+					zap.String("goal_id", goal.ID.String()),
+					zap.Error(err))
+				continue
+			}
+			if !first {
+				continue
+			}
+			s.events.Emit(ctx, event.ProjectID, domain.WebhookEventGoalReached, goalReachedData{
+				GoalID:    goal.ID,
+				Goal:      goal.Name,
+				EventType: event.EventType,
+				Path:      event.Path,
+				Variant:   event.Variant,
+			})
+		}
+	}
+}
+
+// cachedGoals цели проекта для публичного трекинга; ошибка чтения логируется и не мешает сохранить событие
+func (s *AnalyticsService) cachedGoals(ctx context.Context, projectID uuid.UUID) []*domain.AnalyticsGoal {
+	now := time.Now()
+	if goals, ok := s.goals.get(projectID, now); ok {
+		return goals
+	}
+
+	goals, err := s.goalRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		logger.WithContext(ctx).Error("failed to list goals for webhook events",
+			zap.String("project_id", projectID.String()),
+			zap.Error(err))
+		return nil
+	}
+	s.goals.set(projectID, goals, now)
+	return goals
+}
+
+// projectGoals цели проекта; без хранилища целей — пустой список
+func (s *AnalyticsService) projectGoals(ctx context.Context, projectID uuid.UUID) ([]*domain.AnalyticsGoal, error) {
+	if s.goalRepo == nil {
+		return []*domain.AnalyticsGoal{}, nil
+	}
+
+	goals, err := s.goalRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	if goals == nil {
+		goals = []*domain.AnalyticsGoal{}
+	}
+	return goals, nil
+}
+
+// projectGoal получает цель, проверяя доступ к проекту и принадлежность цели ему
+func (s *AnalyticsService) projectGoal(ctx context.Context, userID, projectID, goalID string) (*domain.AnalyticsGoal, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if s.goalRepo == nil {
+		return nil, errGoalsNotConfigured
+	}
+
+	goal, err := s.goalRepo.GetByID(ctx, goalID)
+	if err != nil {
+		return nil, err
+	}
+	if goal.ProjectID != project.ID {
+		return nil, domain.ErrNotFound.WithMessage("goal not found")
+	}
+
+	return goal, nil
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/services/mocks"
+)
+
+func TestAnalyticsService_GetFunnel(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+	goalRepo := new(mocks.AnalyticsGoalRepositoryMock)
+	svc := NewAnalyticsService(projectRepo, analyticsRepo)
+	svc.SetGoalRepository(goalRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New()}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil).Once()
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	goal := domain.NewAnalyticsGoal(project.ID, "Lead", domain.AnalyticsEventFormSubmit, "/contact")
+	goalRepo.On("ListByProject", ctx, project.ID).Return([]*domain.AnalyticsGoal{goal}, nil).Once()
+	analyticsRepo.On("GetFunnel", ctx, project.ID, defaultFunnelSteps, []domain.AnalyticsFunnelStep{
+		{Name: "Lead", EventType: domain.AnalyticsEventFormSubmit, Path: "/contact"},
+	}, from, to).Return([]int{200, 50, 10}, []int{30}, nil).Once()
+
+	funnel, err := svc.GetFunnel(ctx, project.UserID.String(), project.ID.String(), domain.AnalyticsRangeQuery{From: from, To: to})
+	require.NoError(t, err)
+
+	require.Len(t, funnel.Steps, 3)
+	assert.Equal(t, 100.0, *funnel.Steps[0].ConversionRate)
+	assert.Nil(t, funnel.Steps[0].StepConversionRate)
+
+	cta := funnel.Steps[1]
+	assert.Equal(t, 50, cta.Visitors)
+	assert.Equal(t, 25.0, *cta.ConversionRate)
+	assert.Equal(t, 25.0, *cta.StepConversionRate)
+	assert.Equal(t, 150, cta.DropOff)
+	assert.Equal(t, 75.0, *cta.DropOffRate)
+
+	pay := funnel.Steps[2]
+	assert.Equal(t, 5.0, *pay.ConversionRate)
+	assert.Equal(t, 20.0, *pay.StepConversionRate)
+	assert.Equal(t, 40, pay.DropOff)
+
+	require.Len(t, funnel.Goals, 1)
+	assert.Equal(t, goal, funnel.Goals[0].Goal)
+	assert.Equal(t, 30, funnel.Goals[0].Visitors)
+	assert.Equal(t, 15.0, *funnel.Goals[0].ConversionRate)
+
+	analyticsRepo.AssertExpectations(t)
+}
+
+func TestBuildFunnelSteps_NoVisitors(t *testing.T) {
+	steps := buildFunnelSteps(defaultFunnelSteps, []int{0, 0, 0})
+
+	for _, step := range steps {
+		assert.Zero(t, step.Visitors)
+		assert.Nil(t, step.ConversionRate)
+		assert.Nil(t, step.DropOffRate)
+	}
+}
+
+func TestAnalyticsService_CreateGoal(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	goalRepo := new(mocks.AnalyticsGoalRepositoryMock)
+	svc := NewAnalyticsService(projectRepo, new(mocks.AnalyticsRepositoryMock))
+	svc.SetGoalRepository(goalRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New()}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	t.Run("creates goal", func(t *testing.T) {
+		goalRepo.On("ListByProject", ctx, project.ID).Return(nil, nil).Once()
+		goalRepo.On("Create", ctx, mock.MatchedBy(func(goal *domain.AnalyticsGoal) bool {
+			return goal.ProjectID == project.ID && goal.Name == "Purchase" && goal.EventType == domain.AnalyticsEventPayClick
+		})).Return(nil).Once()
+
+		goal, err := svc.CreateGoal(ctx, project.UserID.String(), project.ID.String(), &domain.AnalyticsGoalRequest{
+			Name:      "  Purchase ",
+			EventType: domain.AnalyticsEventPayClick,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Purchase", goal.Name)
+	})
+
+	t.Run("limits goals per project", func(t *testing.T) {
+		goals := make([]*domain.AnalyticsGoal, maxGoalsPerProject)
+		goalRepo.On("ListByProject", ctx, project.ID).Return(goals, nil).Once()
+
+		_, err := svc.CreateGoal(ctx, project.UserID.String(), project.ID.String(), &domain.AnalyticsGoalRequest{
+			Name:      "One more",
+			EventType: domain.AnalyticsEventCTAClick,
+		})
+		assert.ErrorIs(t, err, domain.ErrBadRequest)
+	})
+
+	invalid := []*domain.AnalyticsGoalRequest{
+		{Name: " ", EventType: domain.AnalyticsEventPayClick},
+		{Name: "Signup", EventType: "signup"},
+		{Name: "Thanks", EventType: domain.AnalyticsEventPageview, Path: "thank-you"},
+	}
+	for _, req := range invalid {
+		_, err := svc.CreateGoal(ctx, project.UserID.String(), project.ID.String(), req)
+		assert.ErrorIs(t, err, domain.ErrBadRequest)
+	}
+
+	goalRepo.AssertExpectations(t)
+}
+
+func TestAnalyticsService_UpdateGoal_OtherProject(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	goalRepo := new(mocks.AnalyticsGoalRepositoryMock)
+	svc := NewAnalyticsService(projectRepo, new(mocks.AnalyticsRepositoryMock))
+	svc.SetGoalRepository(goalRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New()}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil).Once()
+
+	// Цель чужого проекта не должна меняться через свой проект
+	goal := domain.NewAnalyticsGoal(uuid.New(), "Purchase", domain.AnalyticsEventPayClick, "")
+	goalRepo.On("GetByID", ctx, goal.ID.String()).Return(goal, nil).Once()
+
+	_, err := svc.UpdateGoal(ctx, project.UserID.String(), project.ID.String(), goal.ID.String(), &domain.AnalyticsGoalRequest{
+		Name:      "Renamed",
+		EventType: domain.AnalyticsEventPayClick,
+	})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	goalRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestAnalyticsService_TrackEvent_EmitsGoalReached(t *testing.T) {
+	ctx := context.Background()
+	analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+	goalRepo := new(mocks.AnalyticsGoalRepositoryMock)
+	events := new(mocks.EventEmitterMock)
+	svc := NewAnalyticsService(new(mocks.ProjectRepositoryMock), analyticsRepo)
+	svc.SetGoalRepository(goalRepo)
+	svc.SetEventEmitter(events)
+
+	projectID := uuid.New()
+	pricingClick := &domain.AnalyticsGoal{ID: uuid.New(), ProjectID: projectID, Name: "Pricing click", EventType: domain.AnalyticsEventPayClick, Path: "/pricing"}
+	anyClick := &domain.AnalyticsGoal{ID: uuid.New(), ProjectID: projectID, Name: "Any click", EventType: domain.AnalyticsEventPayClick}
+	// Цели читаются один раз и дальше берутся из кэша
+	goalRepo.On("ListByProject", ctx, projectID).Return([]*domain.AnalyticsGoal{pricingClick, anyClick}, nil).Once()
+	goalRepo.On("MarkReached", ctx, pricingClick.ID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+	goalRepo.On("MarkReached", ctx, anyClick.ID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+	goalRepo.On("MarkReached", ctx, anyClick.ID, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(false, nil).Once()
+	analyticsRepo.On("GetOrCreateSalt", ctx, mock.AnythingOfType("time.Time"), mock.Anything).Return([]byte("salt"), nil)
+	analyticsRepo.On("TrackEvent", ctx, mock.AnythingOfType("*domain.AnalyticsEvent")).Return(nil)
+
+	events.On("Subscribed", ctx, projectID, domain.WebhookEventGoalReached).Return(true)
+	events.On("Emit", ctx, projectID, domain.WebhookEventGoalReached, mock.MatchedBy(func(data goalReachedData) bool {
+		return data.GoalID == pricingClick.ID && data.Path == "/pricing"
+	})).Once()
+	events.On("Emit", ctx, projectID, domain.WebhookEventGoalReached, mock.MatchedBy(func(data goalReachedData) bool {
+		return data.GoalID == anyClick.ID
+	})).Once()
+
+	// Второй клик того же посетителя за сутки цель «Any click» повторно не отправляет
+	for _, path := range []string{"/pricing", "/"} {
+		require.NoError(t, svc.TrackEvent(ctx, &domain.TrackEventRequest{
+			ProjectID: projectID,
+			EventType: domain.AnalyticsEventPayClick,
+			Path:      path,
+			UserAgent: "Mozilla/5.0",
+		}))
+	}
+	// Переходы ботов не считаются достижением цели
+	require.NoError(t, svc.TrackEvent(ctx, &domain.TrackEventRequest{
+		ProjectID: projectID,
+		EventType: domain.AnalyticsEventPayClick,
+		Path:      "/pricing",
+		UserAgent: "Googlebot/2.1 (+http://www.google.com/bot.html)",
+	}))
+	goalRepo.AssertExpectations(t)
+	events.AssertExpectations(t)
+	events.AssertNumberOfCalls(t, "Emit", 2)
+}
+
+func TestAnalyticsService_TrackEvent_SkipsGoalsWithoutSubscribers(t *testing.T) {
+	ctx := context.Background()
+	analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+	goalRepo := new(mocks.AnalyticsGoalRepositoryMock)
+	events := new(mocks.EventEmitterMock)
+	svc := NewAnalyticsService(new(mocks.ProjectRepositoryMock), analyticsRepo)
+	svc.SetGoalRepository(goalRepo)
+	svc.SetEventEmitter(events)
+
+	projectID := uuid.New()
+	analyticsRepo.On("GetOrCreateSalt", ctx, mock.AnythingOfType("time.Time"), mock.Anything).Return([]byte("salt"), nil)
+	analyticsRepo.On("TrackEvent", ctx, mock.AnythingOfType("*domain.AnalyticsEvent")).Return(nil)
+	events.On("Subscribed", ctx, projectID, domain.WebhookEventGoalReached).Return(false).Once()
+
+	require.NoError(t, svc.TrackEvent(ctx, &domain.TrackEventRequest{
+		ProjectID: projectID,
+		EventType: domain.AnalyticsEventPayClick,
+		Path:      "/pricing",
+		UserAgent: "Mozilla/5.0",
+	}))
+	events.AssertExpectations(t)
+	goalRepo.AssertNotCalled(t, "ListByProject", mock.Anything, mock.Anything)
+	events.AssertNotCalled(t, "Emit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
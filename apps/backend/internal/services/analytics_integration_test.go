@@ -0,0 +1,161 @@
+//go:build integration
+// +build integration
+
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/repositories"
+	testhelpers "github.com/landly/backend/internal/testing"
+)
+
+func TestAnalyticsService_Integration_RollupsAndTimeSeries(t *testing.T) {
+	ctx := context.Background()
+	qb := testhelpers.SetupTestDB(t)
+	projectRepo := repositories.NewProjectRepository(qb)
+	analyticsRepo := repositories.NewAnalyticsRepository(qb)
+	svc := NewAnalyticsService(projectRepo, analyticsRepo)
+
+	user, _ := testhelpers.CreateTestUser(t, qb, "", "")
+	project := testhelpers.CreateTestProject(t, qb, user.ID, "Analytics Project", "SaaS")
+
+	now := time.Now().UTC()
+	events := []*domain.TrackEventRequest{
+		{ProjectID: project.ID, EventType: domain.AnalyticsEventPageview, Path: "/", IPAddress: "203.0.113.1"},
+		{ProjectID: project.ID, EventType: domain.AnalyticsEventPageview, Path: "/", IPAddress: "203.0.113.1"},
+		{ProjectID: project.ID, EventType: domain.AnalyticsEventPageview, Path: "/", IPAddress: "203.0.113.2"},
+		{ProjectID: project.ID, EventType: domain.AnalyticsEventPayClick, Path: "/", IPAddress: "203.0.113.2"},
+	}
+	require.NoError(t, svc.TrackEvents(ctx, events))
+
+	require.NoError(t, svc.RollupRecent(ctx, now.Add(time.Minute)))
+	// Повторный проход перезаписывает агрегаты, а не удваивает их
+	require.NoError(t, svc.RollupRecent(ctx, now.Add(time.Minute)))
+
+	series, err := svc.GetTimeSeries(ctx, user.ID.String(), project.ID.String(), domain.AnalyticsTimeSeriesQuery{
+		Granularity: domain.AnalyticsGranularityHour,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, series.Current.Totals.TotalPageViews)
+	assert.Equal(t, 2, series.Current.Totals.UniqueVisitors)
+	assert.Equal(t, 1, series.Current.Totals.PayClicks)
+	assert.Zero(t, series.Previous.Totals.TotalPageViews)
+}
+
+func TestAnalyticsService_Integration_Breakdown(t *testing.T) {
+	ctx := context.Background()
+	qb := testhelpers.SetupTestDB(t)
+	projectRepo := repositories.NewProjectRepository(qb)
+	analyticsRepo := repositories.NewAnalyticsRepository(qb)
+	svc := NewAnalyticsService(projectRepo, analyticsRepo)
+
+	user, _ := testhelpers.CreateTestUser(t, qb, "", "")
+	project := testhelpers.CreateTestProject(t, qb, user.ID, "Campaigns Project", "SaaS")
+
+	campaignURL := "https://shop.example.com/?utm_source=newsletter&utm_campaign=spring"
+	events := []*domain.TrackEventRequest{
+		{ProjectID: project.ID, EventType: domain.AnalyticsEventPageview, URL: campaignURL, IPAddress: "203.0.113.1"},
+		{ProjectID: project.ID, EventType: domain.AnalyticsEventPayClick, URL: campaignURL, IPAddress: "203.0.113.1"},
+		{ProjectID: project.ID, EventType: domain.AnalyticsEventPageview, URL: campaignURL, IPAddress: "203.0.113.2"},
+		{ProjectID: project.ID, EventType: domain.AnalyticsEventPageview, URL: "https://shop.example.com/", IPAddress: "203.0.113.3"},
+	}
+	require.NoError(t, svc.TrackEvents(ctx, events))
+
+	breakdown, err := svc.GetBreakdown(ctx, user.ID.String(), project.ID.String(), domain.AnalyticsBreakdownQuery{
+		Dimension: domain.AnalyticsDimensionUTMCampaign,
+		To:        time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, breakdown.Items, 2)
+	assert.Equal(t, "spring", breakdown.Items[0].Value)
+	assert.Equal(t, 2, breakdown.Items[0].PageViews)
+	assert.Equal(t, 2, breakdown.Items[0].UniqueVisitors)
+	assert.Equal(t, 1, breakdown.Items[0].PayClicks)
+	assert.Equal(t, "(none)", breakdown.Items[1].Value)
+	assert.Equal(t, 1, breakdown.Items[1].PageViews)
+}
+
+func TestAnalyticsService_Integration_FunnelAndGoals(t *testing.T) {
+	ctx := context.Background()
+	qb := testhelpers.SetupTestDB(t)
+	projectRepo := repositories.NewProjectRepository(qb)
+	analyticsRepo := repositories.NewAnalyticsRepository(qb)
+	svc := NewAnalyticsService(projectRepo, analyticsRepo)
+	svc.SetGoalRepository(repositories.NewAnalyticsGoalRepository(qb))
+
+	user, _ := testhelpers.CreateTestUser(t, qb, "", "")
+	project := testhelpers.CreateTestProject(t, qb, user.ID, "Funnel Project", "SaaS")
+
+	_, err := svc.CreateGoal(ctx, user.ID.String(), project.ID.String(), &domain.AnalyticsGoalRequest{
+		Name:      "Thank you page",
+		EventType: domain.AnalyticsEventPageview,
+		Path:      "/thank-you",
+	})
+	require.NoError(t, err)
+
+	// Каждый посетитель — свой IP; события одного посетителя идут по порядку
+	track := func(ip string, eventTypes ...string) {
+		for _, eventType := range eventTypes {
+			path := "/"
+			if eventType == "thanks" {
+				eventType, path = domain.AnalyticsEventPageview, "/thank-you"
+			}
+			require.NoError(t, svc.TrackEvent(ctx, &domain.TrackEventRequest{ProjectID: project.ID, EventType: eventType, Path: path, IPAddress: ip}))
+		}
+	}
+	track("203.0.113.1", domain.AnalyticsEventPageview)
+	track("203.0.113.2", domain.AnalyticsEventPageview, domain.AnalyticsEventCTAClick)
+	track("203.0.113.3", domain.AnalyticsEventPageview, domain.AnalyticsEventCTAClick, domain.AnalyticsEventPayClick, "thanks")
+	track("203.0.113.4", domain.AnalyticsEventPageview, domain.AnalyticsEventPayClick)
+
+	funnel, err := svc.GetFunnel(ctx, user.ID.String(), project.ID.String(), domain.AnalyticsRangeQuery{To: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+
+	require.Len(t, funnel.Steps, 3)
+	assert.Equal(t, 4, funnel.Steps[0].Visitors)
+	assert.Equal(t, 2, funnel.Steps[1].Visitors)
+	// Оплата без CTA не засчитывается в воронку
+	assert.Equal(t, 1, funnel.Steps[2].Visitors)
+
+	require.Len(t, funnel.Goals, 1)
+	assert.Equal(t, 1, funnel.Goals[0].Visitors)
+	assert.Equal(t, 25.0, *funnel.Goals[0].ConversionRate)
+}
+
+func TestAnalyticsService_Integration_GoalReachedOncePerVisitorAndDay(t *testing.T) {
+	ctx := context.Background()
+	qb := testhelpers.SetupTestDB(t)
+	goalRepo := repositories.NewAnalyticsGoalRepository(qb)
+
+	user, _ := testhelpers.CreateTestUser(t, qb, "", "")
+	project := testhelpers.CreateTestProject(t, qb, user.ID, "Goals Project", "SaaS")
+	goal := domain.NewAnalyticsGoal(project.ID, "Thank you page", domain.AnalyticsEventPageview, "/thank-you")
+	require.NoError(t, goalRepo.Create(ctx, goal))
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	first, err := goalRepo.MarkReached(ctx, goal.ID, "visitor-a", today.Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, first)
+
+	again, err := goalRepo.MarkReached(ctx, goal.ID, "visitor-a", today.Add(5*time.Hour))
+	require.NoError(t, err)
+	assert.False(t, again, "same visitor on the same day")
+
+	yesterday, err := goalRepo.MarkReached(ctx, goal.ID, "visitor-a", today.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.True(t, yesterday)
+
+	require.NoError(t, goalRepo.DeleteReachedBefore(ctx, today))
+	again, err = goalRepo.MarkReached(ctx, goal.ID, "visitor-a", today.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.True(t, again, "marks of past days are purged")
+}
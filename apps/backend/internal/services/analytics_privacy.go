@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/landly/backend/internal/logger"
+	domain "github.com/landly/backend/internal/models"
+	"go.uber.org/zap"
+)
+
+// Режимы analytics.ip_anonymization: что остаётся в ip_address после подсчёта хэша посетителя
+const (
+	IPAnonymizationFull     = "full"     // IP не сохраняется
+	IPAnonymizationTruncate = "truncate" // IPv4 без последнего октета, IPv6 — только /48
+	IPAnonymizationNone     = "none"     // IP сохраняется как есть
+)
+
+const visitorSaltSize = 32
+
+// saltCache соль текущих суток; общая для инстансов соль хранится в analytics_salts
+type saltCache struct {
+	mu    sync.Mutex
+	day   time.Time
+	value []byte
+}
+
+// SetIPAnonymization задаёт режим хранения IP (по умолчанию IPAnonymizationFull)
+func (s *AnalyticsService) SetIPAnonymization(mode string) {
+	s.ipAnonymization = mode
+}
+
+// SetRetention задаёт срок хранения сырых событий; 0 — хранить бессрочно
+func (s *AnalyticsService) SetRetention(retention time.Duration) {
+	s.retention = retention
+}
+
+// protectVisitor заменяет IP хэшем посетителя: HMAC-SHA256(соль суток, проект + IP + User-Agent).
+// Соль меняется раз в сутки, поэтому один и тот же посетитель не связывается между днями.
+func (s *AnalyticsService) protectVisitor(ctx context.Context, event *domain.AnalyticsEvent) error {
+	salt, err := s.dailySalt(ctx, event.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(event.ProjectID.String()))
+	mac.Write([]byte{0})
+	mac.Write([]byte(event.IPAddress))
+	mac.Write([]byte{0})
+	mac.Write([]byte(event.UserAgent))
+	event.VisitorHash = hex.EncodeToString(mac.Sum(nil))
+
+	event.IPAddress = anonymizeIP(event.IPAddress, s.ipAnonymization)
+	return nil
+}
+
+func (s *AnalyticsService) dailySalt(ctx context.Context, now time.Time) ([]byte, error) {
+	day := now.UTC().Truncate(24 * time.Hour)
+
+	s.salts.mu.Lock()
+	defer s.salts.mu.Unlock()
+
+	if s.salts.value != nil && s.salts.day.Equal(day) {
+		return s.salts.value, nil
+	}
+
+	candidate := make([]byte, visitorSaltSize)
+	if _, err := rand.Read(candidate); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	salt, err := s.analyticsRepo.GetOrCreateSalt(ctx, day, candidate)
+	if err != nil {
+		return nil, err
+	}
+
+	s.salts.day = day
+	s.salts.value = salt
+	return salt, nil
+}
+
+// anonymizeIP применяет режим хранения к IP; нераспознанный адрес в режиме truncate не сохраняется
+func anonymizeIP(ip, mode string) string {
+	switch mode {
+	case IPAnonymizationNone:
+		return ip
+	case IPAnonymizationTruncate:
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			return ""
+		}
+		if v4 := parsed.To4(); v4 != nil {
+			return v4.Mask(net.CIDRMask(24, 32)).String()
+		}
+		return parsed.Mask(net.CIDRMask(48, 128)).String()
+	default:
+		return ""
+	}
+}
+
+// PurgeExpired удаляет соли прошлых суток и сырые события старше срока хранения.
+// Агрегаты остаются, поэтому срок хранения не должен быть короче окна пересчёта RollupRecent;
+// события, ещё не попавшие в агрегаты (воркер простаивал), не удаляются.
+func (s *AnalyticsService) PurgeExpired(ctx context.Context, now time.Time) error {
+	today := now.UTC().Truncate(24 * time.Hour)
+	if err := s.analyticsRepo.DeleteSaltsBefore(ctx, today); err != nil {
+		return err
+	}
+	// Хэши посетителей прошлых суток больше не совпадут, их отметки целей не нужны
+	if s.goalRepo != nil {
+		if err := s.goalRepo.DeleteReachedBefore(ctx, today); err != nil {
+			return err
+		}
+	}
+
+	if s.retention <= 0 {
+		return nil
+	}
+
+	before := now.Add(-s.retention)
+	for _, granularity := range rollupGranularities {
+		watermark, err := s.analyticsRepo.GetRollupWatermark(ctx, granularity)
+		if err != nil {
+			return err
+		}
+		if watermark.IsZero() {
+			logger.WithContext(ctx).Info("analytics events are kept until the first rollup")
+			return nil
+		}
+		if pending := granularity.Truncate(watermark); pending.Before(before) {
+			before = pending
+		}
+	}
+
+	deleted, err := s.analyticsRepo.DeleteEventsBefore(ctx, before)
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		logger.WithContext(ctx).Info("purged expired analytics events", zap.Int64("deleted", deleted))
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/services/mocks"
+)
+
+func TestAnalyticsService_ProtectVisitor_HashRotatesDaily(t *testing.T) {
+	ctx := context.Background()
+	analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+	svc := NewAnalyticsService(new(mocks.ProjectRepositoryMock), analyticsRepo)
+
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	nextDay := day.AddDate(0, 0, 1)
+	analyticsRepo.On("GetOrCreateSalt", ctx, day, mock.Anything).Return([]byte("salt-1"), nil).Once()
+	analyticsRepo.On("GetOrCreateSalt", ctx, nextDay, mock.Anything).Return([]byte("salt-2"), nil).Once()
+
+	projectID := uuid.New()
+	visit := func(at time.Time, ip, ua string, project uuid.UUID) string {
+		event := domain.NewAnalyticsEvent(project, domain.AnalyticsEventPageview, "/", "", ua, ip)
+		event.CreatedAt = at
+		require.NoError(t, svc.protectVisitor(ctx, event))
+		assert.Empty(t, event.IPAddress)
+		return event.VisitorHash
+	}
+
+	morning := visit(day.Add(9*time.Hour), "203.0.113.7", "Firefox", projectID)
+	// Соль суток кэшируется: второй запрос в тот же день в репозиторий не идёт
+	assert.Equal(t, morning, visit(day.Add(18*time.Hour), "203.0.113.7", "Firefox", projectID))
+	assert.NotEqual(t, morning, visit(day.Add(18*time.Hour), "203.0.113.7", "Safari", projectID))
+	assert.NotEqual(t, morning, visit(day.Add(18*time.Hour), "203.0.113.7", "Firefox", uuid.New()))
+	assert.NotEqual(t, morning, visit(nextDay.Add(time.Hour), "203.0.113.7", "Firefox", projectID))
+
+	analyticsRepo.AssertExpectations(t)
+}
+
+func TestAnonymizeIP(t *testing.T) {
+	tests := []struct {
+		ip, mode, want string
+	}{
+		{"203.0.113.7", IPAnonymizationFull, ""},
+		{"203.0.113.7", IPAnonymizationNone, "203.0.113.7"},
+		{"203.0.113.7", IPAnonymizationTruncate, "203.0.113.0"},
+		{"2001:db8:85a3:8d3:1319:8a2e:370:7348", IPAnonymizationTruncate, "2001:db8:85a3::"},
+		{"not-an-ip", IPAnonymizationTruncate, ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, anonymizeIP(tt.ip, tt.mode), "%s %s", tt.mode, tt.ip)
+	}
+}
+
+func TestAnalyticsService_PurgeExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC)
+
+	t.Run("deletes events older than retention", func(t *testing.T) {
+		analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+		svc := NewAnalyticsService(new(mocks.ProjectRepositoryMock), analyticsRepo)
+		svc.SetRetention(90 * 24 * time.Hour)
+
+		analyticsRepo.On("DeleteSaltsBefore", ctx, time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)).Return(nil).Once()
+		analyticsRepo.On("GetRollupWatermark", ctx, mock.Anything).Return(now.Add(-5*time.Minute), nil).Twice()
+		analyticsRepo.On("DeleteEventsBefore", ctx, now.AddDate(0, 0, -90)).Return(int64(12), nil).Once()
+
+		require.NoError(t, svc.PurgeExpired(ctx, now))
+		analyticsRepo.AssertExpectations(t)
+	})
+
+	t.Run("keeps events that are not rolled up yet", func(t *testing.T) {
+		analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+		svc := NewAnalyticsService(new(mocks.ProjectRepositoryMock), analyticsRepo)
+		svc.SetRetention(90 * 24 * time.Hour)
+
+		stale := now.AddDate(0, 0, -100).Add(30 * time.Minute)
+		analyticsRepo.On("DeleteSaltsBefore", ctx, mock.Anything).Return(nil).Once()
+		analyticsRepo.On("GetRollupWatermark", ctx, domain.AnalyticsGranularityHour).Return(now.Add(-5*time.Minute), nil).Once()
+		analyticsRepo.On("GetRollupWatermark", ctx, domain.AnalyticsGranularityDay).Return(stale, nil).Once()
+		analyticsRepo.On("DeleteEventsBefore", ctx, domain.AnalyticsGranularityDay.Truncate(stale)).Return(int64(3), nil).Once()
+
+		require.NoError(t, svc.PurgeExpired(ctx, now))
+		analyticsRepo.AssertExpectations(t)
+	})
+
+	t.Run("keeps events before the first rollup", func(t *testing.T) {
+		analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+		svc := NewAnalyticsService(new(mocks.ProjectRepositoryMock), analyticsRepo)
+		svc.SetRetention(90 * 24 * time.Hour)
+
+		analyticsRepo.On("DeleteSaltsBefore", ctx, mock.Anything).Return(nil).Once()
+		analyticsRepo.On("GetRollupWatermark", ctx, domain.AnalyticsGranularityHour).Return(time.Time{}, nil).Once()
+
+		require.NoError(t, svc.PurgeExpired(ctx, now))
+		analyticsRepo.AssertNotCalled(t, "DeleteEventsBefore", mock.Anything, mock.Anything)
+	})
+
+	t.Run("deletes goal marks of past days", func(t *testing.T) {
+		analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+		goalRepo := new(mocks.AnalyticsGoalRepositoryMock)
+		svc := NewAnalyticsService(new(mocks.ProjectRepositoryMock), analyticsRepo)
+		svc.SetGoalRepository(goalRepo)
+
+		today := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+		analyticsRepo.On("DeleteSaltsBefore", ctx, today).Return(nil).Once()
+		goalRepo.On("DeleteReachedBefore", ctx, today).Return(nil).Once()
+
+		require.NoError(t, svc.PurgeExpired(ctx, now))
+		goalRepo.AssertExpectations(t)
+	})
+
+	t.Run("keeps events without retention", func(t *testing.T) {
+		analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+		svc := NewAnalyticsService(new(mocks.ProjectRepositoryMock), analyticsRepo)
+
+		analyticsRepo.On("DeleteSaltsBefore", ctx, mock.Anything).Return(nil).Once()
+
+		require.NoError(t, svc.PurgeExpired(ctx, now))
+		analyticsRepo.AssertNotCalled(t, "DeleteEventsBefore", mock.Anything, mock.Anything)
+	})
+}
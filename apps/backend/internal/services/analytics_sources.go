@@ -0,0 +1,158 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+const maxTrackUTMLength = 255
+
+// referrerAliases домены, под которыми один источник приходит с разных хостов
+var referrerAliases = map[string]string{
+	"t.co":           "twitter.com",
+	"x.com":          "twitter.com",
+	"away.vk.com":    "vk.com",
+	"vk.ru":          "vk.com",
+	"ya.ru":          "yandex.ru",
+	"out.reddit.com": "reddit.com",
+}
+
+// referrerSubdomains служебные поддомены, которые не отличают источник
+var referrerSubdomains = []string{"www.", "m.", "mobile.", "l.", "lm."}
+
+// describeVisit заполняет UTM-метки, домен источника и устройство события.
+// UTM-метки берутся из адреса страницы, а если его нет — из query пути.
+func describeVisit(event *domain.AnalyticsEvent, pageURL, pathQuery string) {
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		page = &url.URL{}
+	}
+
+	params := page.Query()
+	if len(params) == 0 && pathQuery != "" {
+		// ParseQuery возвращает разобранные пары и при ошибке в одной из них
+		params, _ = url.ParseQuery(pathQuery)
+	}
+	utm := func(key string) string {
+		return truncateRunes(strings.TrimSpace(params.Get(key)), maxTrackUTMLength)
+	}
+	event.UTMSource = utm("utm_source")
+	event.UTMMedium = utm("utm_medium")
+	event.UTMCampaign = utm("utm_campaign")
+	event.UTMTerm = utm("utm_term")
+	event.UTMContent = utm("utm_content")
+
+	event.ReferrerDomain = referrerDomain(event.Referrer, page.Hostname())
+	event.Browser, event.OS, event.DeviceType = classifyUserAgent(event.UserAgent)
+}
+
+// referrerDomain приводит referrer к домену источника: google.de и www.google.com — google.com.
+// Переход со страницы того же сайта не считается источником.
+func referrerDomain(referrer, pageHost string) string {
+	ref, err := url.Parse(strings.TrimSpace(referrer))
+	if err != nil || ref.Hostname() == "" {
+		return ""
+	}
+
+	host := normalizeHost(ref.Hostname())
+	if pageHost != "" && host == normalizeHost(pageHost) {
+		return ""
+	}
+	return truncateRunes(host, maxTrackUTMLength)
+}
+
+func normalizeHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, prefix := range referrerSubdomains {
+		if trimmed := strings.TrimPrefix(host, prefix); trimmed != host && strings.Contains(trimmed, ".") {
+			host = trimmed
+			break
+		}
+	}
+
+	if alias, ok := referrerAliases[host]; ok {
+		return alias
+	}
+	// Региональные домены поисковиков: google.de, google.co.uk, yandex.kz
+	switch {
+	case strings.HasPrefix(host, "google."):
+		return "google.com"
+	case strings.HasPrefix(host, "yandex."):
+		return "yandex.ru"
+	}
+	return host
+}
+
+// userAgentRule правило распознавания по подстроке User-Agent; порядок важен,
+// потому что Edge и Opera тоже пишут Chrome, а Chrome — Safari
+type userAgentRule struct {
+	token string
+	name  string
+}
+
+var browserRules = []userAgentRule{
+	{"YaBrowser", "Yandex Browser"},
+	{"Edg", "Edge"},
+	{"OPR/", "Opera"},
+	{"Opera", "Opera"},
+	{"SamsungBrowser", "Samsung Internet"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS", "Firefox"},
+	{"CriOS", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+}
+
+var osRules = []userAgentRule{
+	{"Windows", "Windows"},
+	{"iPhone", "iOS"},
+	{"iPad", "iOS"},
+	{"iPod", "iOS"},
+	{"Android", "Android"},
+	{"Mac OS X", "macOS"},
+	{"Macintosh", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+var botTokens = []string{"bot", "crawler", "spider", "headless", "lighthouse", "curl/", "wget/"}
+
+// classifyUserAgent определяет браузер, ОС и тип устройства. Пустой User-Agent — пустые значения.
+func classifyUserAgent(userAgent string) (browser, os, device string) {
+	if userAgent == "" {
+		return "", "", ""
+	}
+
+	browser = matchUserAgent(userAgent, browserRules)
+	os = matchUserAgent(userAgent, osRules)
+
+	lower := strings.ToLower(userAgent)
+	for _, token := range botTokens {
+		if strings.Contains(lower, token) {
+			return "Bot", os, domain.DeviceTypeBot
+		}
+	}
+
+	switch {
+	case strings.Contains(userAgent, "iPad"), strings.Contains(userAgent, "Tablet"),
+		strings.Contains(userAgent, "Android") && !strings.Contains(userAgent, "Mobile"):
+		device = domain.DeviceTypeTablet
+	case strings.Contains(userAgent, "Mobi"), strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPod"):
+		device = domain.DeviceTypeMobile
+	default:
+		device = domain.DeviceTypeDesktop
+	}
+
+	return browser, os, device
+}
+
+func matchUserAgent(userAgent string, rules []userAgentRule) string {
+	for _, rule := range rules {
+		if strings.Contains(userAgent, rule.token) {
+			return rule.name
+		}
+	}
+	return "Other"
+}
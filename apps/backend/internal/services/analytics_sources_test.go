@@ -0,0 +1,84 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+func TestDescribeVisit_UTMFromPageURL(t *testing.T) {
+	event := domain.NewAnalyticsEvent(uuid.New(), domain.AnalyticsEventPayClick, "/", "https://www.google.de/search?q=landing", "", "")
+
+	describeVisit(event, "https://shop.example.com/?utm_source=newsletter&utm_medium=email&utm_campaign=spring%20sale&utm_term=+shoes+&utm_content=hero", "")
+
+	assert.Equal(t, "newsletter", event.UTMSource)
+	assert.Equal(t, "email", event.UTMMedium)
+	assert.Equal(t, "spring sale", event.UTMCampaign)
+	assert.Equal(t, "shoes", event.UTMTerm)
+	assert.Equal(t, "hero", event.UTMContent)
+	assert.Equal(t, "google.com", event.ReferrerDomain)
+}
+
+func TestDescribeVisit_UTMFromPathQuery(t *testing.T) {
+	event := domain.NewAnalyticsEvent(uuid.New(), domain.AnalyticsEventPageview, "/", "", "", "")
+
+	describeVisit(event, "", "utm_source=vk&utm_campaign=launch")
+
+	assert.Equal(t, "vk", event.UTMSource)
+	assert.Equal(t, "launch", event.UTMCampaign)
+	assert.Empty(t, event.UTMMedium)
+	assert.Empty(t, event.ReferrerDomain)
+}
+
+func TestReferrerDomain(t *testing.T) {
+	tests := []struct {
+		referrer, pageHost, want string
+	}{
+		{"", "shop.example.com", ""},
+		{"not a url", "", ""},
+		{"https://www.Example.org/blog/post", "", "example.org"},
+		{"https://m.facebook.com/", "", "facebook.com"},
+		{"https://l.facebook.com/l.php?u=x", "", "facebook.com"},
+		{"https://t.co/abc", "", "twitter.com"},
+		{"https://yandex.kz/search/?text=x", "", "yandex.ru"},
+		{"https://www.google.co.uk/", "", "google.com"},
+		{"android-app://com.google.android.gm/", "", "com.google.android.gm"},
+		// Переход внутри сайта
+		{"https://shop.example.com/pricing", "www.shop.example.com", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, referrerDomain(tt.referrer, tt.pageHost), tt.referrer)
+	}
+}
+
+func TestClassifyUserAgent(t *testing.T) {
+	tests := []struct {
+		name, userAgent, browser, os, device string
+	}{
+		{"empty", "", "", "", ""},
+		{"chrome windows", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36", "Chrome", "Windows", domain.DeviceTypeDesktop},
+		{"edge", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.51", "Edge", "Windows", domain.DeviceTypeDesktop},
+		{"yandex", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/122.0.0.0 YaBrowser/24.4.0.0 Safari/537.36", "Yandex Browser", "macOS", domain.DeviceTypeDesktop},
+		{"safari iphone", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1", "Safari", "iOS", domain.DeviceTypeMobile},
+		{"chrome ios", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/124.0.6367.88 Mobile/15E148 Safari/604.1", "Chrome", "iOS", domain.DeviceTypeMobile},
+		{"ipad", "Mozilla/5.0 (iPad; CPU OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1", "Safari", "iOS", domain.DeviceTypeTablet},
+		{"android phone", "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36", "Chrome", "Android", domain.DeviceTypeMobile},
+		{"android tablet", "Mozilla/5.0 (Linux; Android 13; SM-X200) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36", "Chrome", "Android", domain.DeviceTypeTablet},
+		{"firefox linux", "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0", "Firefox", "Linux", domain.DeviceTypeDesktop},
+		{"googlebot", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", "Bot", "Other", domain.DeviceTypeBot},
+		{"unknown", "SomeClient/1.0", "Other", "Other", domain.DeviceTypeDesktop},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			browser, os, device := classifyUserAgent(tt.userAgent)
+			assert.Equal(t, tt.browser, browser)
+			assert.Equal(t, tt.os, os)
+			assert.Equal(t, tt.device, device)
+		})
+	}
+}
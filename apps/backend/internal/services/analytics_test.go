@@ -0,0 +1,340 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/services/mocks"
+)
+
+func TestAnalyticsService_TrackEvent_StoresProjectAndClientData(t *testing.T) {
+	ctx := context.Background()
+	analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+	svc := NewAnalyticsService(new(mocks.ProjectRepositoryMock), analyticsRepo)
+
+	projectID := uuid.New()
+	analyticsRepo.On("GetOrCreateSalt", ctx, mock.AnythingOfType("time.Time"), mock.Anything).Return([]byte("salt"), nil).Once()
+	analyticsRepo.On("TrackEvent", ctx, mock.MatchedBy(func(event *domain.AnalyticsEvent) bool {
+		return event.ProjectID == projectID &&
+			event.EventType == domain.AnalyticsEventPayClick &&
+			event.Path == "/pricing" &&
+			event.UserAgent == "Mozilla/5.0" &&
+			event.IPAddress == "" &&
+			len(event.VisitorHash) == 64 &&
+			!event.CreatedAt.IsZero()
+	})).Return(nil).Once()
+
+	err := svc.TrackEvent(ctx, &domain.TrackEventRequest{
+		ProjectID: projectID,
+		EventType: domain.AnalyticsEventPayClick,
+		Path:      "/pricing",
+		UserAgent: "Mozilla/5.0",
+		IPAddress: "203.0.113.7",
+	})
+	require.NoError(t, err)
+	analyticsRepo.AssertExpectations(t)
+}
+
+func TestAnalyticsService_TrackEvent_Validation(t *testing.T) {
+	projectID := uuid.New()
+	tests := []struct {
+		name string
+		req  domain.TrackEventRequest
+	}{
+		{name: "zero project", req: domain.TrackEventRequest{EventType: domain.AnalyticsEventPageview, Path: "/"}},
+		{name: "unknown event type", req: domain.TrackEventRequest{ProjectID: projectID, EventType: "drop_table", Path: "/"}},
+		{name: "relative path", req: domain.TrackEventRequest{ProjectID: projectID, EventType: domain.AnalyticsEventPageview, Path: "pricing"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+			svc := NewAnalyticsService(new(mocks.ProjectRepositoryMock), analyticsRepo)
+
+			err := svc.TrackEvent(context.Background(), &tt.req)
+			assert.ErrorIs(t, err, domain.ErrBadRequest)
+			analyticsRepo.AssertNotCalled(t, "TrackEvent", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAnalyticsService_TrackEvents(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+
+	t.Run("stores batch and truncates long fields", func(t *testing.T) {
+		analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+		svc := NewAnalyticsService(new(mocks.ProjectRepositoryMock), analyticsRepo)
+
+		analyticsRepo.On("GetOrCreateSalt", ctx, mock.AnythingOfType("time.Time"), mock.Anything).Return([]byte("salt"), nil).Once()
+		analyticsRepo.On("TrackEvents", ctx, mock.MatchedBy(func(events []*domain.AnalyticsEvent) bool {
+			return len(events) == 2 && len([]rune(events[1].Path)) == maxTrackPathLength && events[0].Path == "/"
+		})).Return(nil).Once()
+
+		err := svc.TrackEvents(ctx, []*domain.TrackEventRequest{
+			{ProjectID: projectID, EventType: domain.AnalyticsEventPageview},
+			{ProjectID: projectID, EventType: domain.AnalyticsEventCTAClick, Path: "/" + strings.Repeat("я", 400)},
+		})
+		require.NoError(t, err)
+		analyticsRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects oversized batch", func(t *testing.T) {
+		analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+		svc := NewAnalyticsService(new(mocks.ProjectRepositoryMock), analyticsRepo)
+
+		reqs := make([]*domain.TrackEventRequest, MaxTrackBatchSize+1)
+		for i := range reqs {
+			reqs[i] = &domain.TrackEventRequest{ProjectID: projectID, EventType: domain.AnalyticsEventPageview}
+		}
+
+		err := svc.TrackEvents(ctx, reqs)
+		assert.ErrorIs(t, err, domain.ErrBadRequest)
+		analyticsRepo.AssertNotCalled(t, "TrackEvents", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAnalyticsService_GetTimeSeries_ComparesWithPreviousPeriod(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+	svc := NewAnalyticsService(projectRepo, analyticsRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New()}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil).Once()
+
+	from := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 3)
+	previousFrom := from.AddDate(0, 0, -3)
+	analyticsRepo.On("GetRollups", ctx, project.ID, domain.AnalyticsGranularityDay, previousFrom, to).Return([]*domain.AnalyticsRollup{
+		{Bucket: previousFrom.AddDate(0, 0, 1), PageViews: 10, UniqueVisitors: 4, PayClicks: 1},
+		{Bucket: from, PageViews: 8, UniqueVisitors: 3, CTAClicks: 2},
+		{Bucket: from.AddDate(0, 0, 2), PageViews: 7, UniqueVisitors: 3, PayClicks: 1},
+	}, nil).Once()
+
+	series, err := svc.GetTimeSeries(ctx, project.UserID.String(), project.ID.String(), domain.AnalyticsTimeSeriesQuery{
+		Granularity: domain.AnalyticsGranularityDay,
+		From:        from,
+		To:          to,
+	})
+	require.NoError(t, err)
+
+	// Пропущенный день заполняется нулями
+	require.Len(t, series.Current.Points, 3)
+	assert.Equal(t, from.AddDate(0, 0, 1), series.Current.Points[1].Bucket)
+	assert.Zero(t, series.Current.Points[1].PageViews)
+	assert.Equal(t, 15, series.Current.Totals.TotalPageViews)
+
+	require.Len(t, series.Previous.Points, 3)
+	assert.Equal(t, previousFrom, series.Previous.From)
+	assert.Equal(t, 10, series.Previous.Totals.TotalPageViews)
+
+	require.NotNil(t, series.Change.PageViews)
+	assert.Equal(t, 50.0, *series.Change.PageViews)
+	assert.Equal(t, 50.0, *series.Change.UniqueVisitors)
+	assert.Equal(t, 0.0, *series.Change.PayClicks)
+	assert.Nil(t, series.Change.CTAClicks)
+}
+
+func TestAnalyticsService_GetTimeSeries_ForeignProject(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+	svc := NewAnalyticsService(projectRepo, analyticsRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New()}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil).Once()
+
+	_, err := svc.GetTimeSeries(ctx, uuid.NewString(), project.ID.String(), domain.AnalyticsTimeSeriesQuery{})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	analyticsRepo.AssertNotCalled(t, "GetRollups", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNormalizeTimeSeriesQuery(t *testing.T) {
+	now := time.Date(2026, 3, 10, 14, 25, 0, 0, time.UTC)
+
+	t.Run("defaults include current day", func(t *testing.T) {
+		granularity, from, to, err := normalizeTimeSeriesQuery(domain.AnalyticsTimeSeriesQuery{}, now)
+		require.NoError(t, err)
+		assert.Equal(t, domain.AnalyticsGranularityDay, granularity)
+		assert.Equal(t, time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC), to)
+		assert.Equal(t, to.AddDate(0, 0, -30), from)
+	})
+
+	t.Run("hourly aligns to hours", func(t *testing.T) {
+		_, from, to, err := normalizeTimeSeriesQuery(domain.AnalyticsTimeSeriesQuery{Granularity: domain.AnalyticsGranularityHour}, now)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC), to)
+		assert.Equal(t, 24*time.Hour, to.Sub(from))
+	})
+
+	invalid := []domain.AnalyticsTimeSeriesQuery{
+		{Granularity: "week"},
+		{From: now, To: now.Add(-48 * time.Hour)},
+		{Granularity: domain.AnalyticsGranularityHour, From: now.AddDate(0, -2, 0), To: now},
+	}
+	for _, q := range invalid {
+		_, _, _, err := normalizeTimeSeriesQuery(q, now)
+		assert.ErrorIs(t, err, domain.ErrBadRequest)
+	}
+}
+
+func TestAnalyticsService_RollupRecent(t *testing.T) {
+	ctx := context.Background()
+	analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+	svc := NewAnalyticsService(new(mocks.ProjectRepositoryMock), analyticsRepo)
+
+	now := time.Date(2026, 3, 10, 14, 25, 0, 0, time.UTC)
+	analyticsRepo.On("GetRollupWatermark", ctx, domain.AnalyticsGranularityHour).Return(time.Date(2026, 3, 10, 14, 20, 0, 0, time.UTC), nil).Once()
+	analyticsRepo.On("GetRollupWatermark", ctx, domain.AnalyticsGranularityDay).Return(time.Time{}, nil).Once()
+	analyticsRepo.On("RollupEvents", ctx, domain.AnalyticsGranularityHour, time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC), now).Return(nil).Once()
+	analyticsRepo.On("RollupEvents", ctx, domain.AnalyticsGranularityDay, time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), now).Return(nil).Once()
+	analyticsRepo.On("SetRollupWatermark", ctx, domain.AnalyticsGranularityHour, now).Return(nil).Once()
+	analyticsRepo.On("SetRollupWatermark", ctx, domain.AnalyticsGranularityDay, now).Return(nil).Once()
+
+	require.NoError(t, svc.RollupRecent(ctx, now))
+	analyticsRepo.AssertExpectations(t)
+}
+
+func TestAnalyticsService_RollupRecent_CatchesUpFromWatermark(t *testing.T) {
+	ctx := context.Background()
+	analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+	svc := NewAnalyticsService(new(mocks.ProjectRepositoryMock), analyticsRepo)
+
+	// воркер стоял трое суток
+	now := time.Date(2026, 3, 10, 14, 25, 0, 0, time.UTC)
+	watermark := time.Date(2026, 3, 7, 9, 40, 0, 0, time.UTC)
+	analyticsRepo.On("GetRollupWatermark", ctx, mock.Anything).Return(watermark, nil).Twice()
+	analyticsRepo.On("RollupEvents", ctx, domain.AnalyticsGranularityHour, time.Date(2026, 3, 7, 9, 0, 0, 0, time.UTC), now).Return(nil).Once()
+	analyticsRepo.On("RollupEvents", ctx, domain.AnalyticsGranularityDay, time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC), now).Return(nil).Once()
+	analyticsRepo.On("SetRollupWatermark", ctx, mock.Anything, now).Return(nil).Twice()
+
+	require.NoError(t, svc.RollupRecent(ctx, now))
+	analyticsRepo.AssertExpectations(t)
+}
+
+func TestAnalyticsService_RollupRecent_KeepsWatermarkOnError(t *testing.T) {
+	ctx := context.Background()
+	analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+	svc := NewAnalyticsService(new(mocks.ProjectRepositoryMock), analyticsRepo)
+
+	now := time.Date(2026, 3, 10, 14, 25, 0, 0, time.UTC)
+	analyticsRepo.On("GetRollupWatermark", ctx, domain.AnalyticsGranularityHour).Return(time.Time{}, nil).Once()
+	analyticsRepo.On("RollupEvents", ctx, domain.AnalyticsGranularityHour, mock.Anything, now).Return(domain.ErrInternal).Once()
+
+	err := svc.RollupRecent(ctx, now)
+	assert.ErrorIs(t, err, domain.ErrInternal)
+	analyticsRepo.AssertNotCalled(t, "SetRollupWatermark", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAnalyticsService_TrackEvent_SplitsUTMFromPath(t *testing.T) {
+	ctx := context.Background()
+	analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+	svc := NewAnalyticsService(new(mocks.ProjectRepositoryMock), analyticsRepo)
+
+	analyticsRepo.On("GetOrCreateSalt", ctx, mock.AnythingOfType("time.Time"), mock.Anything).Return([]byte("salt"), nil).Once()
+	analyticsRepo.On("TrackEvent", ctx, mock.MatchedBy(func(event *domain.AnalyticsEvent) bool {
+		return event.Path == "/pricing" &&
+			event.UTMSource == "google" &&
+			event.UTMCampaign == "brand" &&
+			event.ReferrerDomain == "google.com" &&
+			event.Browser == "Firefox" &&
+			event.DeviceType == domain.DeviceTypeDesktop
+	})).Return(nil).Once()
+
+	err := svc.TrackEvent(ctx, &domain.TrackEventRequest{
+		ProjectID: uuid.New(),
+		EventType: domain.AnalyticsEventPageview,
+		Path:      "/pricing?utm_source=google&utm_campaign=brand#plans",
+		Referrer:  "https://www.google.com/",
+		UserAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+	})
+	require.NoError(t, err)
+	analyticsRepo.AssertExpectations(t)
+}
+
+func TestAnalyticsService_TrackEvent_TagsVariant(t *testing.T) {
+	ctx := context.Background()
+	analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+	svc := NewAnalyticsService(new(mocks.ProjectRepositoryMock), analyticsRepo)
+
+	analyticsRepo.On("GetOrCreateSalt", ctx, mock.AnythingOfType("time.Time"), mock.Anything).Return([]byte("salt"), nil).Once()
+	analyticsRepo.On("TrackEvent", ctx, mock.MatchedBy(func(event *domain.AnalyticsEvent) bool {
+		return event.Variant == domain.VariantControl
+	})).Return(nil).Once()
+
+	err := svc.TrackEvent(ctx, &domain.TrackEventRequest{
+		ProjectID: uuid.New(),
+		EventType: domain.AnalyticsEventPageview,
+		Variant:   " control ",
+	})
+	require.NoError(t, err)
+	analyticsRepo.AssertExpectations(t)
+}
+
+func TestAnalyticsService_GetBreakdown(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+	svc := NewAnalyticsService(projectRepo, analyticsRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New()}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	t.Run("labels empty values", func(t *testing.T) {
+		from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+		to := from.AddDate(0, 0, 7)
+		analyticsRepo.On("GetBreakdown", ctx, project.ID, domain.AnalyticsDimensionReferrer, from, to, 5).Return([]*domain.AnalyticsBreakdownItem{
+			{Value: "google.com", PageViews: 10, PayClicks: 2},
+			{Value: "", PageViews: 4},
+		}, nil).Once()
+
+		breakdown, err := svc.GetBreakdown(ctx, project.UserID.String(), project.ID.String(), domain.AnalyticsBreakdownQuery{
+			Dimension: domain.AnalyticsDimensionReferrer,
+			From:      from,
+			To:        to,
+			Limit:     5,
+		})
+		require.NoError(t, err)
+		require.Len(t, breakdown.Items, 2)
+		assert.Equal(t, "google.com", breakdown.Items[0].Value)
+		assert.Equal(t, "(direct)", breakdown.Items[1].Value)
+	})
+
+	t.Run("defaults to last 30 days", func(t *testing.T) {
+		analyticsRepo.On("GetBreakdown", ctx, project.ID, domain.AnalyticsDimensionUTMCampaign, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 10).
+			Return(nil, nil).Once()
+
+		breakdown, err := svc.GetBreakdown(ctx, project.UserID.String(), project.ID.String(), domain.AnalyticsBreakdownQuery{
+			Dimension: domain.AnalyticsDimensionUTMCampaign,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 30*24*time.Hour, breakdown.To.Sub(breakdown.From))
+		assert.NotNil(t, breakdown.Items)
+	})
+
+	t.Run("rejects foreign project", func(t *testing.T) {
+		_, err := svc.GetBreakdown(ctx, uuid.NewString(), project.ID.String(), domain.AnalyticsBreakdownQuery{Dimension: domain.AnalyticsDimensionOS})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	invalid := []domain.AnalyticsBreakdownQuery{
+		{Dimension: domain.AnalyticsDimensionBrowser, Limit: 500},
+		{Dimension: domain.AnalyticsDimensionBrowser, From: time.Now(), To: time.Now().Add(-time.Hour)},
+		{Dimension: domain.AnalyticsDimensionBrowser, From: time.Now().AddDate(-2, 0, 0)},
+	}
+	for _, q := range invalid {
+		_, err := svc.GetBreakdown(ctx, project.UserID.String(), project.ID.String(), q)
+		assert.ErrorIs(t, err, domain.ErrBadRequest)
+	}
+
+	analyticsRepo.AssertExpectations(t)
+}
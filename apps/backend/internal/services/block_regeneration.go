@@ -0,0 +1,157 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+// blockEdit блок схемы, выбранный для точечной перегенерации
+type blockEdit struct {
+	ref       domain.BlockRef
+	pageIndex int
+	props     json.RawMessage
+}
+
+// path путь к props блока внутри схемы для schema.ReplaceValue
+func (e *blockEdit) path() []interface{} {
+	return []interface{}{"pages", e.pageIndex, "blocks", e.ref.BlockIndex, "props"}
+}
+
+type schemaBlocksView struct {
+	Pages []struct {
+		Path   string `json:"path"`
+		Blocks []struct {
+			Type  string          `json:"type"`
+			Props json.RawMessage `json:"props"`
+		} `json:"blocks"`
+	} `json:"pages"`
+}
+
+// resolveBlockTarget находит блок схемы, на который указывает target
+func resolveBlockTarget(schemaJSON string, target *domain.BlockTarget) (*blockEdit, error) {
+	if strings.TrimSpace(schemaJSON) == "" {
+		return nil, fmt.Errorf("project has no schema yet")
+	}
+
+	var view schemaBlocksView
+	if err := json.Unmarshal([]byte(schemaJSON), &view); err != nil {
+		return nil, fmt.Errorf("invalid project schema: %w", err)
+	}
+
+	for pageIndex, page := range view.Pages {
+		if target.PagePath != "" && page.Path != target.PagePath {
+			continue
+		}
+
+		for blockIndex, block := range page.Blocks {
+			if target.BlockIndex != nil && blockIndex != *target.BlockIndex {
+				continue
+			}
+			if target.BlockType != "" && block.Type != target.BlockType {
+				continue
+			}
+
+			props := block.Props
+			if len(props) == 0 || string(props) == "null" {
+				props = json.RawMessage("{}")
+			}
+
+			return &blockEdit{
+				ref:       domain.BlockRef{PagePath: page.Path, BlockIndex: blockIndex, BlockType: block.Type},
+				pageIndex: pageIndex,
+				props:     props,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("target block not found")
+}
+
+// blockTypeAliases слова, по которым в сообщении узнаётся тип блока
+var blockTypeAliases = map[domain.BlockType][]string{
+	domain.BlockTypeHero:         {"hero", "первый экран", "шапк"},
+	domain.BlockTypeFeatures:     {"features", "преимуществ"},
+	domain.BlockTypePricing:      {"pricing", "тариф", "цены", "стоимост"},
+	domain.BlockTypeTestimonials: {"testimonials", "отзыв"},
+	domain.BlockTypeFAQ:          {"faq", "вопрос"},
+	domain.BlockTypeCTA:          {"cta", "призыв"},
+	domain.BlockTypeGallery:      {"gallery", "галере"},
+	domain.BlockTypeAbout:        {"about", "о нас"},
+	domain.BlockTypeContact:      {"contact", "контакт"},
+}
+
+var blockWordPattern = regexp.MustCompile(`(?i)(^|[^\p{L}])(блок|block|секци|section)`)
+
+// detectBlockTarget распознаёт в сообщении просьбу изменить конкретный блок
+// ("перепиши блок с тарифами", "rewrite the pricing block"). Срабатывает, только если
+// упомянуто слово «блок» и ровно один тип блока.
+func detectBlockTarget(content string) *domain.BlockTarget {
+	lower := strings.ToLower(content)
+	if !blockWordPattern.MatchString(lower) {
+		return nil
+	}
+
+	var found []domain.BlockType
+	for blockType, aliases := range blockTypeAliases {
+		for _, alias := range aliases {
+			if containsWordPrefix(lower, alias) {
+				found = append(found, blockType)
+				break
+			}
+		}
+	}
+
+	if len(found) != 1 {
+		return nil
+	}
+	return &domain.BlockTarget{BlockType: string(found[0])}
+}
+
+// containsWordPrefix проверяет, что alias встречается в тексте с начала слова
+func containsWordPrefix(text, alias string) bool {
+	pattern := regexp.MustCompile(`(^|[^\p{L}])` + regexp.QuoteMeta(alias))
+	return pattern.MatchString(text)
+}
+
+// diffBlocks возвращает блоки новой схемы, которые появились или изменились относительно старой
+func diffBlocks(oldSchemaJSON, newSchemaJSON string) []domain.BlockRef {
+	var oldView, newView schemaBlocksView
+	_ = json.Unmarshal([]byte(oldSchemaJSON), &oldView)
+	if err := json.Unmarshal([]byte(newSchemaJSON), &newView); err != nil {
+		return nil
+	}
+
+	oldBlocks := make(map[string]map[int]string)
+	for _, page := range oldView.Pages {
+		blocks := make(map[int]string, len(page.Blocks))
+		for i, block := range page.Blocks {
+			blocks[i] = block.Type + ":" + compactJSON(block.Props)
+		}
+		oldBlocks[page.Path] = blocks
+	}
+
+	changed := []domain.BlockRef{}
+	for _, page := range newView.Pages {
+		for i, block := range page.Blocks {
+			if previous, ok := oldBlocks[page.Path][i]; ok && previous == block.Type+":"+compactJSON(block.Props) {
+				continue
+			}
+			changed = append(changed, domain.BlockRef{PagePath: page.Path, BlockIndex: i, BlockType: block.Type})
+		}
+	}
+
+	return changed
+}
+
+func compactJSON(raw json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
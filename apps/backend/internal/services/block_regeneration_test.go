@@ -0,0 +1,39 @@
+package services
+
+import (
+	"testing"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectBlockTarget(t *testing.T) {
+	cases := []struct {
+		content string
+		want    *domain.BlockTarget
+	}{
+		{"Перепиши блок с тарифами", &domain.BlockTarget{BlockType: "pricing"}},
+		{"rewrite the pricing block", &domain.BlockTarget{BlockType: "pricing"}},
+		{"Сделай секцию отзывов живее", &domain.BlockTarget{BlockType: "testimonials"}},
+		{"Добавь отзывы", nil},
+		{"Поменяй блоки hero и faq местами", nil},
+		{"Сделай блок ярче", nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.content, func(t *testing.T) {
+			assert.Equal(t, tc.want, detectBlockTarget(tc.content))
+		})
+	}
+}
+
+func TestDiffBlocks(t *testing.T) {
+	oldSchema := `{"pages":[{"path":"/","blocks":[{"type":"hero","props":{"headline":"A"}},{"type":"faq","props":{}}]}]}`
+	newSchema := `{"pages":[{"path":"/","blocks":[{"type":"hero","props":{"headline": "A"}},{"type":"faq","props":{"items":[]}},{"type":"cta","props":{}}]},{"path":"/about","blocks":[{"type":"about","props":{}}]}]}`
+
+	assert.Equal(t, []domain.BlockRef{
+		{PagePath: "/", BlockIndex: 1, BlockType: "faq"},
+		{PagePath: "/", BlockIndex: 2, BlockType: "cta"},
+		{PagePath: "/about", BlockIndex: 0, BlockType: "about"},
+	}, diffBlocks(oldSchema, newSchema))
+}
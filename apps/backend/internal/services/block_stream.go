@@ -0,0 +1,88 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// blockStreamParser выделяет завершённые объекты из массивов "blocks" по мере поступления JSON
+type blockStreamParser struct {
+	buf strings.Builder
+
+	inString   bool
+	escaped    bool
+	stringFrom int
+	lastString string
+	lastKey    string
+
+	depth       int
+	blocksDepth int
+	objectStart int
+}
+
+func newBlockStreamParser() *blockStreamParser {
+	return &blockStreamParser{blocksDepth: -1, objectStart: -1}
+}
+
+// Feed дописывает фрагмент и возвращает блоки, завершённые в нём
+func (p *blockStreamParser) Feed(chunk string) []json.RawMessage {
+	from := p.buf.Len()
+	p.buf.WriteString(chunk)
+	data := p.buf.String()
+
+	var blocks []json.RawMessage
+	for i := from; i < len(data); i++ {
+		ch := data[i]
+
+		if p.inString {
+			switch {
+			case p.escaped:
+				p.escaped = false
+			case ch == '\\':
+				p.escaped = true
+			case ch == '"':
+				p.inString = false
+				p.lastString = data[p.stringFrom:i]
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			p.inString = true
+			p.stringFrom = i + 1
+		case ':':
+			p.lastKey = p.lastString
+		case ',':
+			p.lastKey = ""
+		case '{':
+			p.depth++
+			if p.blocksDepth >= 0 && p.depth == p.blocksDepth+1 {
+				p.objectStart = i
+			}
+			p.lastKey = ""
+		case '[':
+			p.depth++
+			if p.blocksDepth < 0 && p.lastKey == "blocks" {
+				p.blocksDepth = p.depth
+			}
+			p.lastKey = ""
+		case '}':
+			if p.blocksDepth >= 0 && p.depth == p.blocksDepth+1 && p.objectStart >= 0 {
+				raw := data[p.objectStart : i+1]
+				if json.Valid([]byte(raw)) {
+					blocks = append(blocks, json.RawMessage(raw))
+				}
+				p.objectStart = -1
+			}
+			p.depth--
+		case ']':
+			if p.depth == p.blocksDepth {
+				p.blocksDepth = -1
+			}
+			p.depth--
+		}
+	}
+
+	return blocks
+}
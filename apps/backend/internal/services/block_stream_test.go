@@ -0,0 +1,37 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockStreamParser_EmitsCompletedBlocksAcrossChunks(t *testing.T) {
+	schemaJSON := `{"version":"1.0","pages":[` +
+		`{"path":"/","title":"Главная","blocks":[` +
+		`{"type":"hero","order":0,"props":{"headline":"Скобки } и ] в \"строке\""}},` +
+		`{"type":"faq","order":1,"props":{"items":[{"question":"?","answer":"!"}],"blocks":[{"nested":true}]}}]},` +
+		`{"path":"/about","title":"О нас","blocks":[{"type":"about","order":0,"props":{}}]}]}`
+
+	parser := newBlockStreamParser()
+	var blocks []json.RawMessage
+	for i := 0; i < len(schemaJSON); i += 7 {
+		end := i + 7
+		if end > len(schemaJSON) {
+			end = len(schemaJSON)
+		}
+		blocks = append(blocks, parser.Feed(schemaJSON[i:end])...)
+	}
+
+	require.Len(t, blocks, 3)
+
+	var types []string
+	for _, raw := range blocks {
+		var block map[string]interface{}
+		require.NoError(t, json.Unmarshal(raw, &block))
+		types = append(types, block["type"].(string))
+	}
+	assert.Equal(t, []string{"hero", "faq", "about"}, types)
+}
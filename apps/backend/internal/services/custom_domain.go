@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/landly/backend/internal/logger"
+	domain "github.com/landly/backend/internal/models"
+	"go.uber.org/zap"
+)
+
+const maxCustomDomainLength = 253
+
+// TXTResolver источник TXT-записей; net.DefaultResolver подходит, в тестах подменяется заглушкой
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// CertificateIssuer выпускает TLS-сертификаты подтверждённых доменов
+type CertificateIssuer interface {
+	// Request ставит выпуск в очередь, не дожидаясь удостоверяющего центра
+	Request(host string)
+	Forget(ctx context.Context, host string) error
+}
+
+// CustomDomainService собственные домены проектов: привязка, подтверждение владения через
+// TXT-запись и поиск сайта по Host запроса
+type CustomDomainService struct {
+	projectRepo       domain.ProjectRepository
+	publishTargetRepo domain.PublishTargetRepository
+	resolver          TXTResolver
+	certIssuer        CertificateIssuer
+	// platformHost хост платформы: его и его поддомены привязать нельзя
+	platformHost string
+}
+
+// NewCustomDomainService создаёт сервис собственных доменов
+func NewCustomDomainService(projectRepo domain.ProjectRepository, publishTargetRepo domain.PublishTargetRepository, resolver TXTResolver, publicBase string) *CustomDomainService {
+	platformHost := ""
+	if base, err := url.Parse(strings.TrimSpace(publicBase)); err == nil {
+		platformHost = strings.ToLower(base.Hostname())
+	}
+
+	return &CustomDomainService{
+		projectRepo:       projectRepo,
+		publishTargetRepo: publishTargetRepo,
+		resolver:          resolver,
+		platformHost:      platformHost,
+	}
+}
+
+// SetCertificateIssuer включает выпуск сертификатов для подтверждённых доменов
+func (s *CustomDomainService) SetCertificateIssuer(issuer CertificateIssuer) {
+	s.certIssuer = issuer
+}
+
+// GetDomain возвращает цель публикации с привязанным доменом
+func (s *CustomDomainService) GetDomain(ctx context.Context, userID, projectID string) (*domain.PublishTarget, error) {
+	target, err := s.projectTarget(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if target.CustomDomain == "" {
+		return nil, domain.ErrNotFound.WithMessage("custom domain not attached")
+	}
+	return target, nil
+}
+
+// AttachDomain привязывает домен к опубликованному проекту и выдаёт токен для TXT-записи.
+// Повторная привязка того же домена сохраняет токен и подтверждение.
+func (s *CustomDomainService) AttachDomain(ctx context.Context, userID, projectID, host string) (*domain.PublishTarget, error) {
+	target, err := s.projectTarget(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized, err := s.normalizeDomain(host)
+	if err != nil {
+		return nil, err
+	}
+	if target.CustomDomain == normalized {
+		return target, nil
+	}
+
+	// Неподтверждённые заявки не блокируют домен: иначе его мог бы занять любой, кто назовёт его первым
+	owner, err := s.publishTargetRepo.GetByCustomDomain(ctx, normalized)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if owner != nil && owner.ID != target.ID && owner.DomainVerified() {
+		return nil, domain.ErrAlreadyExists.WithMessage("domain is attached to another project")
+	}
+
+	token, err := newDomainVerificationToken()
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	previous := target.CustomDomain
+	target.CustomDomain = normalized
+	target.DomainVerificationToken = token
+	target.DomainVerifiedAt = nil
+	target.SSLStatus = domain.SSLStatusNone
+	target.UpdatedAt = time.Now()
+	if err := s.publishTargetRepo.UpdateDomain(ctx, target); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	s.forgetCertificate(ctx, previous)
+
+	return target, nil
+}
+
+// VerifyDomain ищет токен в TXT-записи _landly-verification.<domain> и отмечает домен подтверждённым.
+// Неподтверждённые заявки других проектов на этот домен снимаются.
+func (s *CustomDomainService) VerifyDomain(ctx context.Context, userID, projectID string) (*domain.PublishTarget, error) {
+	target, err := s.GetDomain(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if target.DomainVerified() {
+		return target, nil
+	}
+
+	recordName := target.DomainVerificationRecord()
+	records, err := s.resolver.LookupTXT(ctx, recordName)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, domain.ErrBadRequest.WithMessage(fmt.Sprintf("TXT record %s not found", recordName))
+		}
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	found := false
+	for _, record := range records {
+		if strings.TrimSpace(record) == target.DomainVerificationToken {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, domain.ErrBadRequest.WithMessage(fmt.Sprintf("TXT record %s does not contain the verification token", recordName))
+	}
+
+	now := time.Now()
+	target.DomainVerifiedAt = &now
+	target.UpdatedAt = now
+	if s.certIssuer != nil {
+		target.SSLStatus = domain.SSLStatusPending
+	}
+	if err := s.publishTargetRepo.ClaimVerifiedDomain(ctx, target); err != nil {
+		return nil, err
+	}
+	if s.certIssuer != nil {
+		s.certIssuer.Request(target.CustomDomain)
+	}
+
+	return target, nil
+}
+
+// DetachDomain отвязывает домен от проекта
+func (s *CustomDomainService) DetachDomain(ctx context.Context, userID, projectID string) error {
+	target, err := s.GetDomain(ctx, userID, projectID)
+	if err != nil {
+		return err
+	}
+
+	host := target.CustomDomain
+	target.CustomDomain = ""
+	target.DomainVerificationToken = ""
+	target.DomainVerifiedAt = nil
+	target.SSLStatus = domain.SSLStatusNone
+	target.UpdatedAt = time.Now()
+	if err := s.publishTargetRepo.UpdateDomain(ctx, target); err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+	s.forgetCertificate(ctx, host)
+
+	return nil
+}
+
+// ResolveHost возвращает поддомен сайта, привязанного к хосту запроса.
+// Неподтверждённый домен не отдаёт сайт: до проверки DNS им мог назваться кто угодно.
+func (s *CustomDomainService) ResolveHost(ctx context.Context, host string) (string, error) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" {
+		return "", domain.ErrNotFound
+	}
+
+	target, err := s.publishTargetRepo.GetByCustomDomain(ctx, host)
+	if err != nil {
+		return "", err
+	}
+	if !target.DomainVerified() {
+		return "", domain.ErrNotFound.WithMessage("custom domain not verified")
+	}
+
+	return target.Subdomain, nil
+}
+
+// forgetCertificate удаляет сертификат отвязанного домена. Домен уже отвязан,
+// поэтому ошибка только пишется в лог: сертификат без домена никому не отдаётся.
+func (s *CustomDomainService) forgetCertificate(ctx context.Context, host string) {
+	if s.certIssuer == nil || host == "" {
+		return
+	}
+	if err := s.certIssuer.Forget(ctx, host); err != nil {
+		logger.WithContext(ctx).Error("failed to delete certificate", zap.String("domain", host), zap.Error(err))
+	}
+}
+
+func (s *CustomDomainService) projectTarget(ctx context.Context, userID, projectID string) (*domain.PublishTarget, error) {
+	if _, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID); err != nil {
+		return nil, err
+	}
+
+	target, err := s.publishTargetRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrBadRequest.WithMessage("project is not published yet")
+		}
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// normalizeDomain приводит ввод пользователя (https://Shop.Example.com/) к имени хоста
+// и проверяет, что это доменное имя не из зоны платформы
+func (s *CustomDomainService) normalizeDomain(input string) (string, error) {
+	host := strings.ToLower(strings.TrimSpace(input))
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.IndexAny(host, "/?#"); i >= 0 {
+		host = host[:i]
+	}
+	host = strings.TrimSuffix(host, ".")
+
+	if host == "" || len(host) > maxCustomDomainLength || net.ParseIP(host) != nil {
+		return "", domain.ErrInvalidInput.WithMessage("invalid domain")
+	}
+
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return "", domain.ErrInvalidInput.WithMessage("domain must contain at least two labels")
+	}
+	for _, label := range labels {
+		if !validDomainLabel(label) {
+			return "", domain.ErrInvalidInput.WithMessage("invalid domain")
+		}
+	}
+
+	if s.platformHost != "" && (host == s.platformHost || strings.HasSuffix(host, "."+s.platformHost)) {
+		return "", domain.ErrInvalidInput.WithMessage("domain belongs to the platform")
+	}
+
+	return host, nil
+}
+
+func validDomainLabel(label string) bool {
+	if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for _, r := range label {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func newDomainVerificationToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "landly-verification=" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,91 @@
+//go:build integration
+// +build integration
+
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/repositories"
+	testhelpers "github.com/landly/backend/internal/testing"
+)
+
+func TestCustomDomainService_Integration_AttachVerifyResolve(t *testing.T) {
+	ctx := context.Background()
+	qb := testhelpers.SetupTestDB(t)
+	projectRepo := repositories.NewProjectRepository(qb)
+	targetRepo := repositories.NewPublishTargetRepository(qb)
+	resolver := stubTXTResolver{}
+	svc := NewCustomDomainService(projectRepo, targetRepo, resolver, "https://landly.test")
+
+	user, _ := testhelpers.CreateTestUser(t, qb, "", "")
+	project := testhelpers.CreateTestProject(t, qb, user.ID, "Domain Project", "SaaS")
+	target := domain.NewPublishTarget(project.ID, "domain-project-1234abcd")
+	require.NoError(t, targetRepo.Create(ctx, target))
+
+	attached, err := svc.AttachDomain(ctx, user.ID.String(), project.ID.String(), "Shop.Example.com")
+	require.NoError(t, err)
+
+	_, err = svc.ResolveHost(ctx, "shop.example.com")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	resolver[attached.DomainVerificationRecord()] = []string{attached.DomainVerificationToken}
+	_, err = svc.VerifyDomain(ctx, user.ID.String(), project.ID.String())
+	require.NoError(t, err)
+
+	subdomain, err := svc.ResolveHost(ctx, "shop.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, target.Subdomain, subdomain)
+
+	// Публикация перезаписывает цель через Update и не должна терять домен
+	stored, err := targetRepo.GetByProjectID(ctx, project.ID.String())
+	require.NoError(t, err)
+	stored.Status = domain.PublishStatusPublished
+	require.NoError(t, targetRepo.Update(ctx, stored))
+	stored, err = targetRepo.GetByProjectID(ctx, project.ID.String())
+	require.NoError(t, err)
+	assert.True(t, stored.DomainVerified())
+	assert.Equal(t, domain.SSLStatusNone, stored.SSLStatus)
+
+	require.NoError(t, svc.DetachDomain(ctx, user.ID.String(), project.ID.String()))
+	_, err = targetRepo.GetByCustomDomain(ctx, "shop.example.com")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestCustomDomainService_Integration_VerificationTakesDomainFromSquatter(t *testing.T) {
+	ctx := context.Background()
+	qb := testhelpers.SetupTestDB(t)
+	projectRepo := repositories.NewProjectRepository(qb)
+	targetRepo := repositories.NewPublishTargetRepository(qb)
+	resolver := stubTXTResolver{}
+	svc := NewCustomDomainService(projectRepo, targetRepo, resolver, "https://landly.test")
+
+	squatter, _ := testhelpers.CreateTestUser(t, qb, "squatter@example.com", "")
+	squatterProject := testhelpers.CreateTestProject(t, qb, squatter.ID, "Squatter", "SaaS")
+	require.NoError(t, targetRepo.Create(ctx, domain.NewPublishTarget(squatterProject.ID, "squatter-1234abcd")))
+
+	owner, _ := testhelpers.CreateTestUser(t, qb, "owner@example.com", "")
+	ownerProject := testhelpers.CreateTestProject(t, qb, owner.ID, "Owner", "SaaS")
+	require.NoError(t, targetRepo.Create(ctx, domain.NewPublishTarget(ownerProject.ID, "owner-1234abcd")))
+
+	// Неподтверждённая заявка не мешает настоящему владельцу привязать домен
+	_, err := svc.AttachDomain(ctx, squatter.ID.String(), squatterProject.ID.String(), "shop.example.com")
+	require.NoError(t, err)
+	attached, err := svc.AttachDomain(ctx, owner.ID.String(), ownerProject.ID.String(), "shop.example.com")
+	require.NoError(t, err)
+
+	resolver[attached.DomainVerificationRecord()] = []string{attached.DomainVerificationToken}
+	_, err = svc.VerifyDomain(ctx, owner.ID.String(), ownerProject.ID.String())
+	require.NoError(t, err)
+
+	_, err = svc.GetDomain(ctx, squatter.ID.String(), squatterProject.ID.String())
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	_, err = svc.AttachDomain(ctx, squatter.ID.String(), squatterProject.ID.String(), "shop.example.com")
+	assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+}
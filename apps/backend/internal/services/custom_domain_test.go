@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/services/mocks"
+)
+
+// stubTXTResolver отвечает TXT-записями из карты; отсутствующее имя — NXDOMAIN
+type stubTXTResolver map[string][]string
+
+func (r stubTXTResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	records, ok := r[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestCustomDomainService_AttachDomain_NormalizesAndIssuesToken(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	svc := NewCustomDomainService(projectRepo, targetRepo, stubTXTResolver{}, "https://landly.test")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	published := domain.NewPublishTarget(project.ID, "shop-1234abcd")
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(published, nil)
+
+	targetRepo.On("GetByCustomDomain", ctx, "shop.example.com").Return(nil, domain.ErrNotFound).Once()
+	targetRepo.On("UpdateDomain", ctx, published).Return(nil).Once()
+
+	target, err := svc.AttachDomain(ctx, project.UserID.String(), project.ID.String(), " https://Shop.Example.com./pricing ")
+	require.NoError(t, err)
+	assert.Equal(t, "shop.example.com", target.CustomDomain)
+	assert.Equal(t, "_landly-verification.shop.example.com", target.DomainVerificationRecord())
+	assert.NotEmpty(t, target.DomainVerificationToken)
+	assert.False(t, target.DomainVerified())
+	targetRepo.AssertExpectations(t)
+}
+
+func TestCustomDomainService_AttachDomain_Rejected(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	svc := NewCustomDomainService(projectRepo, targetRepo, stubTXTResolver{}, "https://landly.test")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	published := domain.NewPublishTarget(project.ID, "shop-1234abcd")
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(published, nil)
+
+	verifiedAt := time.Now()
+	other := domain.NewPublishTarget(uuid.New(), "other-1234abcd")
+	other.CustomDomain = "taken.example.com"
+	other.DomainVerifiedAt = &verifiedAt
+	targetRepo.On("GetByCustomDomain", ctx, "taken.example.com").Return(other, nil)
+
+	cases := map[string]struct {
+		host string
+		err  error
+	}{
+		"single label":       {"localhost", domain.ErrInvalidInput},
+		"ip address":         {"203.0.113.10", domain.ErrInvalidInput},
+		"bad characters":     {"shop_1.example.com", domain.ErrInvalidInput},
+		"platform host":      {"landly.test", domain.ErrInvalidInput},
+		"platform subdomain": {"shop.landly.test", domain.ErrInvalidInput},
+		"another project":    {"taken.example.com", domain.ErrAlreadyExists},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.AttachDomain(ctx, project.UserID.String(), project.ID.String(), tc.host)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+	targetRepo.AssertNotCalled(t, "UpdateDomain", mock.Anything, mock.Anything)
+}
+
+func TestCustomDomainService_AttachDomain_UnverifiedClaimDoesNotBlock(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	svc := NewCustomDomainService(projectRepo, targetRepo, stubTXTResolver{}, "https://landly.test")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	published := domain.NewPublishTarget(project.ID, "shop-1234abcd")
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(published, nil)
+
+	squatter := domain.NewPublishTarget(uuid.New(), "other-1234abcd")
+	squatter.CustomDomain = "shop.example.com"
+	targetRepo.On("GetByCustomDomain", ctx, "shop.example.com").Return(squatter, nil).Once()
+	targetRepo.On("UpdateDomain", ctx, published).Return(nil).Once()
+
+	target, err := svc.AttachDomain(ctx, project.UserID.String(), project.ID.String(), "shop.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "shop.example.com", target.CustomDomain)
+	targetRepo.AssertExpectations(t)
+}
+
+func TestCustomDomainService_VerifyDomain(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	resolver := stubTXTResolver{}
+	svc := NewCustomDomainService(projectRepo, targetRepo, resolver, "https://landly.test")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	published := domain.NewPublishTarget(project.ID, "shop-1234abcd")
+	published.CustomDomain = "shop.example.com"
+	published.DomainVerificationToken = "landly-verification=abc"
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(published, nil)
+
+	_, err := svc.VerifyDomain(ctx, project.UserID.String(), project.ID.String())
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+
+	resolver["_landly-verification.shop.example.com"] = []string{"v=spf1 -all", "landly-verification=other"}
+	_, err = svc.VerifyDomain(ctx, project.UserID.String(), project.ID.String())
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+	targetRepo.AssertNotCalled(t, "ClaimVerifiedDomain", mock.Anything, mock.Anything)
+
+	resolver["_landly-verification.shop.example.com"] = []string{"landly-verification=abc"}
+	targetRepo.On("ClaimVerifiedDomain", ctx, published).Return(nil).Once()
+	target, err := svc.VerifyDomain(ctx, project.UserID.String(), project.ID.String())
+	require.NoError(t, err)
+	assert.True(t, target.DomainVerified())
+	targetRepo.AssertExpectations(t)
+}
+
+func TestCustomDomainService_VerifyDomain_VerifiedByAnotherProject(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	resolver := stubTXTResolver{}
+	svc := NewCustomDomainService(projectRepo, targetRepo, resolver, "https://landly.test")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	published := domain.NewPublishTarget(project.ID, "shop-1234abcd")
+	published.CustomDomain = "shop.example.com"
+	published.DomainVerificationToken = "landly-verification=abc"
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(published, nil)
+	resolver["_landly-verification.shop.example.com"] = []string{"landly-verification=abc"}
+
+	targetRepo.On("ClaimVerifiedDomain", ctx, published).Return(domain.ErrAlreadyExists).Once()
+
+	_, err := svc.VerifyDomain(ctx, project.UserID.String(), project.ID.String())
+	assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+}
+
+func TestCustomDomainService_ResolveHost_RequiresVerification(t *testing.T) {
+	ctx := context.Background()
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	svc := NewCustomDomainService(new(mocks.ProjectRepositoryMock), targetRepo, stubTXTResolver{}, "https://landly.test")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	published := domain.NewPublishTarget(project.ID, "shop-1234abcd")
+	published.CustomDomain = "shop.example.com"
+	targetRepo.On("GetByCustomDomain", ctx, "shop.example.com").Return(published, nil)
+
+	_, err := svc.ResolveHost(ctx, "Shop.Example.com")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	verifiedAt := time.Now()
+	published.DomainVerifiedAt = &verifiedAt
+	subdomain, err := svc.ResolveHost(ctx, "Shop.Example.com")
+	require.NoError(t, err)
+	assert.Equal(t, published.Subdomain, subdomain)
+}
+
+func TestCustomDomainService_DetachDomain(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	svc := NewCustomDomainService(projectRepo, targetRepo, stubTXTResolver{}, "https://landly.test")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	published := domain.NewPublishTarget(project.ID, "shop-1234abcd")
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(published, nil)
+
+	verifiedAt := time.Now()
+	published.CustomDomain = "shop.example.com"
+	published.DomainVerificationToken = "landly-verification=abc"
+	published.DomainVerifiedAt = &verifiedAt
+
+	targetRepo.On("UpdateDomain", ctx, mock.MatchedBy(func(target *domain.PublishTarget) bool {
+		return target.CustomDomain == "" && target.DomainVerificationToken == "" && target.DomainVerifiedAt == nil
+	})).Return(nil).Once()
+
+	require.NoError(t, svc.DetachDomain(ctx, project.UserID.String(), project.ID.String()))
+	targetRepo.AssertExpectations(t)
+}
+
+// recordingIssuer запоминает запросы на выпуск и удаление сертификатов
+type recordingIssuer struct {
+	requested []string
+	forgotten []string
+}
+
+func (i *recordingIssuer) Request(host string) {
+	i.requested = append(i.requested, host)
+}
+
+func (i *recordingIssuer) Forget(_ context.Context, host string) error {
+	i.forgotten = append(i.forgotten, host)
+	return nil
+}
+
+func TestCustomDomainService_CertificateIssuer(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	resolver := stubTXTResolver{}
+	svc := NewCustomDomainService(projectRepo, targetRepo, resolver, "https://landly.test")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	published := domain.NewPublishTarget(project.ID, "shop-1234abcd")
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(published, nil)
+
+	issuer := &recordingIssuer{}
+	svc.SetCertificateIssuer(issuer)
+	published.CustomDomain = "shop.example.com"
+	published.DomainVerificationToken = "landly-verification=abc"
+	resolver["_landly-verification.shop.example.com"] = []string{"landly-verification=abc"}
+	targetRepo.On("ClaimVerifiedDomain", ctx, published).Return(nil).Once()
+	targetRepo.On("UpdateDomain", ctx, published).Return(nil)
+
+	target, err := svc.VerifyDomain(ctx, project.UserID.String(), project.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, domain.SSLStatusPending, target.SSLStatus)
+	assert.Equal(t, []string{"shop.example.com"}, issuer.requested)
+
+	targetRepo.On("GetByCustomDomain", ctx, "www.example.com").Return(nil, domain.ErrNotFound).Once()
+	_, err = svc.AttachDomain(ctx, project.UserID.String(), project.ID.String(), "www.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shop.example.com"}, issuer.forgotten)
+
+	require.NoError(t, svc.DetachDomain(ctx, project.UserID.String(), project.ID.String()))
+	assert.Equal(t, []string{"shop.example.com", "www.example.com"}, issuer.forgotten)
+	assert.Equal(t, domain.SSLStatusNone, published.SSLStatus)
+}
@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/schema"
+)
+
+const (
+	maxVariantsPerProject = 5
+	maxVariantNameLength  = 100
+
+	// significanceLevel порог p-value, ниже которого разница с контролем считается значимой (95%)
+	significanceLevel = 0.05
+)
+
+// defaultExperimentGoal цель эксперимента, если не выбрана цель проекта
+var defaultExperimentGoal = domain.AnalyticsFunnelStep{Name: "Pay click", EventType: domain.AnalyticsEventPayClick}
+
+// ExperimentService A/B-тесты лендинга: варианты схемы, их результаты и выбор победителя.
+// Изменения вариантов попадают на сайт со следующей публикацией.
+type ExperimentService struct {
+	projectRepo   domain.ProjectRepository
+	variantRepo   domain.ProjectVariantRepository
+	analyticsRepo domain.AnalyticsRepository
+	goalRepo      domain.AnalyticsGoalRepository
+	revisionRepo  domain.SchemaRevisionRepository
+	validator     *schema.Validator
+}
+
+// NewExperimentService создаёт сервис A/B-тестов
+func NewExperimentService(projectRepo domain.ProjectRepository, variantRepo domain.ProjectVariantRepository, analyticsRepo domain.AnalyticsRepository) *ExperimentService {
+	return &ExperimentService{
+		projectRepo:   projectRepo,
+		variantRepo:   variantRepo,
+		analyticsRepo: analyticsRepo,
+		validator:     schema.MustNewValidator(),
+	}
+}
+
+// SetGoalRepository позволяет считать результаты по целям проекта
+func (s *ExperimentService) SetGoalRepository(goalRepo domain.AnalyticsGoalRepository) {
+	s.goalRepo = goalRepo
+}
+
+// SetRevisionRepository включает запись ревизии при выборе победителя
+func (s *ExperimentService) SetRevisionRepository(repo domain.SchemaRevisionRepository) {
+	s.revisionRepo = repo
+}
+
+// ListVariants возвращает варианты проекта
+func (s *ExperimentService) ListVariants(ctx context.Context, userID, projectID string) ([]*domain.ProjectVariant, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return s.variantRepo.ListByProject(ctx, project.ID)
+}
+
+// CreateVariant добавляет вариант; без схемы вариант начинается с копии основной схемы
+func (s *ExperimentService) CreateVariant(ctx context.Context, userID, projectID string, req *domain.ProjectVariantRequest) (*domain.ProjectVariant, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := s.variantRepo.ListByProject(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(variants) >= maxVariantsPerProject {
+		return nil, domain.ErrBadRequest.WithMessage(fmt.Sprintf("a project can have at most %d variants", maxVariantsPerProject))
+	}
+
+	name, err := s.validateVariantRequest(req, variants, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaJSON := req.SchemaJSON
+	if strings.TrimSpace(schemaJSON) == "" {
+		if project.SchemaJSON == "" {
+			return nil, domain.ErrBadRequest.WithMessage("project schema is empty")
+		}
+		schemaJSON = project.SchemaJSON
+	}
+
+	variant := domain.NewProjectVariant(project.ID, name, schemaJSON, req.Weight)
+	if err := s.variantRepo.Create(ctx, variant); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return variant, nil
+}
+
+// UpdateVariant изменяет название, вес и, если передана, схему варианта
+func (s *ExperimentService) UpdateVariant(ctx context.Context, userID, projectID, variantID string, req *domain.ProjectVariantRequest) (*domain.ProjectVariant, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	variant, err := s.projectVariant(ctx, project, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := s.variantRepo.ListByProject(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := s.validateVariantRequest(req, variants, variant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	variant.Name = name
+	variant.Weight = req.Weight
+	if strings.TrimSpace(req.SchemaJSON) != "" {
+		variant.SchemaJSON = req.SchemaJSON
+	}
+	variant.UpdatedAt = time.Now()
+	if err := s.variantRepo.Update(ctx, variant); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return variant, nil
+}
+
+// DeleteVariant удаляет вариант
+func (s *ExperimentService) DeleteVariant(ctx context.Context, userID, projectID, variantID string) error {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return err
+	}
+
+	variant, err := s.projectVariant(ctx, project, variantID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.variantRepo.Delete(ctx, variant.ID.String()); err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+	return nil
+}
+
+// PromoteVariant делает схему варианта основной и завершает эксперимент, удаляя все варианты.
+// variantID "control" оставляет основную схему. На сайте изменения появятся после публикации.
+func (s *ExperimentService) PromoteVariant(ctx context.Context, userID, projectID, variantID string) (*domain.Project, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if variantID != domain.VariantControl {
+		variant, err := s.projectVariant(ctx, project, variantID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.projectRepo.UpdateSchema(ctx, project.ID.String(), variant.SchemaJSON); err != nil {
+			return nil, domain.ErrInternal.WithError(err)
+		}
+		project.SchemaJSON = variant.SchemaJSON
+		recordSchemaRevision(ctx, s.revisionRepo, project.ID, variant.SchemaJSON, domain.RevisionSourceExperiment, &project.UserID, nil)
+	}
+
+	if err := s.variantRepo.DeleteByProject(ctx, project.ID); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return project, nil
+}
+
+// GetResults считает конверсию вариантов в цель за период и значимость отличия от контроля
+func (s *ExperimentService) GetResults(ctx context.Context, userID, projectID string, query *domain.ExperimentResultsQuery) (*domain.ExperimentResults, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, err := normalizeAnalyticsRange(query.From, query.To, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	goal, err := s.experimentGoal(ctx, project, query.GoalID)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := s.variantRepo.ListByProject(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	conversions, err := s.analyticsRepo.GetVariantConversions(ctx, project.ID, goal, from, to)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]*domain.VariantConversion, len(conversions))
+	for _, conversion := range conversions {
+		byKey[conversion.Variant] = conversion
+	}
+
+	controlWeight := 100
+	for _, variant := range variants {
+		controlWeight -= variant.Weight
+	}
+
+	results := make([]domain.VariantResult, 0, len(variants)+1)
+	results = append(results, variantResult(domain.VariantControl, "Control", controlWeight, byKey[domain.VariantControl]))
+	for _, variant := range variants {
+		results = append(results, variantResult(variant.Key(), variant.Name, variant.Weight, byKey[variant.Key()]))
+	}
+
+	control := results[0]
+	winner := ""
+	var bestRate float64
+	for i := 1; i < len(results); i++ {
+		compareWithControl(&results[i], control)
+		result := results[i]
+		if result.Significant && *result.Uplift > 0 && *result.ConversionRate > bestRate {
+			winner, bestRate = result.Key, *result.ConversionRate
+		}
+	}
+
+	return &domain.ExperimentResults{
+		From:     from,
+		To:       to,
+		Goal:     goal,
+		Variants: results,
+		Winner:   winner,
+	}, nil
+}
+
+// experimentGoal шаг, который считается конверсией: цель проекта или клик по оплате
+func (s *ExperimentService) experimentGoal(ctx context.Context, project *domain.Project, goalID *uuid.UUID) (domain.AnalyticsFunnelStep, error) {
+	if goalID == nil {
+		return defaultExperimentGoal, nil
+	}
+	if s.goalRepo == nil {
+		return domain.AnalyticsFunnelStep{}, errGoalsNotConfigured
+	}
+
+	goal, err := s.goalRepo.GetByID(ctx, goalID.String())
+	if err != nil {
+		return domain.AnalyticsFunnelStep{}, err
+	}
+	if goal.ProjectID != project.ID {
+		return domain.AnalyticsFunnelStep{}, domain.ErrNotFound.WithMessage("goal not found")
+	}
+
+	return domain.AnalyticsFunnelStep{Name: goal.Name, EventType: goal.EventType, Path: goal.Path}, nil
+}
+
+func variantResult(key, name string, weight int, conversion *domain.VariantConversion) domain.VariantResult {
+	result := domain.VariantResult{Key: key, Name: name, Weight: weight}
+	if conversion != nil {
+		result.Visitors = conversion.Visitors
+		result.Conversions = conversion.Conversions
+	}
+	result.ConversionRate = ratePercent(result.Conversions, result.Visitors)
+	return result
+}
+
+// compareWithControl заполняет прирост конверсии и p-value двустороннего z-теста для двух долей
+func compareWithControl(result *domain.VariantResult, control domain.VariantResult) {
+	if result.Visitors == 0 || control.Visitors == 0 {
+		return
+	}
+
+	variantRate := float64(result.Conversions) / float64(result.Visitors)
+	controlRate := float64(control.Conversions) / float64(control.Visitors)
+	if controlRate > 0 {
+		uplift := math.Round((variantRate-controlRate)/controlRate*1000) / 10
+		result.Uplift = &uplift
+	}
+
+	pooled := float64(result.Conversions+control.Conversions) / float64(result.Visitors+control.Visitors)
+	stdErr := math.Sqrt(pooled * (1 - pooled) * (1/float64(result.Visitors) + 1/float64(control.Visitors)))
+	if stdErr == 0 {
+		return
+	}
+
+	z := (variantRate - controlRate) / stdErr
+	pValue := math.Erfc(math.Abs(z) / math.Sqrt2)
+	pValue = math.Round(pValue*10000) / 10000
+	result.PValue = &pValue
+	result.Significant = pValue < significanceLevel
+}
+
+// validateVariantRequest проверяет запрос; вес варианта вместе с остальными не должен превышать 100%
+func (s *ExperimentService) validateVariantRequest(req *domain.ProjectVariantRequest, variants []*domain.ProjectVariant, selfID uuid.UUID) (string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return "", domain.ErrBadRequest.WithMessage("variant name is required")
+	}
+	if len([]rune(name)) > maxVariantNameLength {
+		return "", domain.ErrBadRequest.WithMessage(fmt.Sprintf("variant name must be at most %d characters", maxVariantNameLength))
+	}
+
+	if req.Weight < 0 || req.Weight > 100 {
+		return "", domain.ErrBadRequest.WithMessage("weight must be between 0 and 100")
+	}
+	total := req.Weight
+	for _, variant := range variants {
+		if variant.ID != selfID {
+			total += variant.Weight
+		}
+	}
+	if total > 100 {
+		return "", domain.ErrBadRequest.WithMessage(fmt.Sprintf("total weight of variants is %d%%, must be at most 100%%", total))
+	}
+
+	if strings.TrimSpace(req.SchemaJSON) != "" {
+		if validationErrs := s.validator.Validate(req.SchemaJSON); len(validationErrs) > 0 {
+			return "", domain.ErrSchemaInvalid.WithError(validationErrs)
+		}
+	}
+
+	return name, nil
+}
+
+func (s *ExperimentService) projectVariant(ctx context.Context, project *domain.Project, variantID string) (*domain.ProjectVariant, error) {
+	variant, err := s.variantRepo.GetByID(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if variant.ProjectID != project.ID {
+		return nil, domain.ErrNotFound.WithMessage("variant not found")
+	}
+	return variant, nil
+}
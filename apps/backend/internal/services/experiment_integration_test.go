@@ -0,0 +1,65 @@
+//go:build integration
+// +build integration
+
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/repositories"
+	testhelpers "github.com/landly/backend/internal/testing"
+)
+
+func TestExperimentService_Integration_ResultsAndPromote(t *testing.T) {
+	ctx := context.Background()
+	qb := testhelpers.SetupTestDB(t)
+	projectRepo := repositories.NewProjectRepository(qb)
+	analyticsRepo := repositories.NewAnalyticsRepository(qb)
+	analytics := NewAnalyticsService(projectRepo, analyticsRepo)
+	svc := NewExperimentService(projectRepo, repositories.NewProjectVariantRepository(qb), analyticsRepo)
+	svc.SetRevisionRepository(repositories.NewSchemaRevisionRepository(qb))
+
+	user, _ := testhelpers.CreateTestUser(t, qb, "", "")
+	project := testhelpers.CreateTestProject(t, qb, user.ID, "Experiment Project", "SaaS")
+	require.NoError(t, projectRepo.UpdateSchema(ctx, project.ID.String(), revisionTestSchema))
+
+	variant, err := svc.CreateVariant(ctx, user.ID.String(), project.ID.String(), &domain.ProjectVariantRequest{Name: "B", Weight: 50})
+	require.NoError(t, err)
+	assert.Equal(t, revisionTestSchema, variant.SchemaJSON)
+
+	track := func(ip, variantKey string, eventTypes ...string) {
+		for _, eventType := range eventTypes {
+			require.NoError(t, analytics.TrackEvent(ctx, &domain.TrackEventRequest{
+				ProjectID: project.ID, EventType: eventType, Path: "/", IPAddress: ip, Variant: variantKey,
+			}))
+		}
+	}
+	track("203.0.113.1", domain.VariantControl, domain.AnalyticsEventPageview)
+	track("203.0.113.2", domain.VariantControl, domain.AnalyticsEventPageview, domain.AnalyticsEventPayClick)
+	track("203.0.113.3", variant.Key(), domain.AnalyticsEventPageview, domain.AnalyticsEventPayClick, domain.AnalyticsEventPayClick)
+	track("203.0.113.4", variant.Key(), domain.AnalyticsEventPageview, domain.AnalyticsEventPayClick)
+	// Без варианта и без просмотра страницы в результаты не попадают
+	track("203.0.113.5", "", domain.AnalyticsEventPageview, domain.AnalyticsEventPayClick)
+	track("203.0.113.6", variant.Key(), domain.AnalyticsEventPayClick)
+
+	results, err := svc.GetResults(ctx, user.ID.String(), project.ID.String(), &domain.ExperimentResultsQuery{To: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	require.Len(t, results.Variants, 2)
+	assert.Equal(t, 2, results.Variants[0].Visitors)
+	assert.Equal(t, 1, results.Variants[0].Conversions)
+	assert.Equal(t, 2, results.Variants[1].Visitors)
+	assert.Equal(t, 2, results.Variants[1].Conversions)
+
+	_, err = svc.PromoteVariant(ctx, user.ID.String(), project.ID.String(), variant.ID.String())
+	require.NoError(t, err)
+
+	variants, err := svc.ListVariants(ctx, user.ID.String(), project.ID.String())
+	require.NoError(t, err)
+	assert.Empty(t, variants)
+}
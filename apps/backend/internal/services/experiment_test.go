@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/services/mocks"
+)
+
+func TestExperimentService_CreateVariant_CopiesMainSchema(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	variantRepo := new(mocks.ProjectVariantRepositoryMock)
+	svc := NewExperimentService(projectRepo, variantRepo, new(mocks.AnalyticsRepositoryMock))
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), SchemaJSON: revisionTestSchema}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	variantRepo.On("ListByProject", ctx, project.ID).Return([]*domain.ProjectVariant{}, nil).Once()
+	variantRepo.On("Create", ctx, mock.AnythingOfType("*domain.ProjectVariant")).Return(nil).Once()
+
+	variant, err := svc.CreateVariant(ctx, project.UserID.String(), project.ID.String(), &domain.ProjectVariantRequest{Name: " Short hero ", Weight: 50})
+	require.NoError(t, err)
+	assert.Equal(t, "Short hero", variant.Name)
+	assert.Equal(t, 50, variant.Weight)
+	assert.Equal(t, project.SchemaJSON, variant.SchemaJSON)
+	variantRepo.AssertExpectations(t)
+}
+
+func TestExperimentService_CreateVariant_Validation(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	variantRepo := new(mocks.ProjectVariantRepositoryMock)
+	svc := NewExperimentService(projectRepo, variantRepo, new(mocks.AnalyticsRepositoryMock))
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), SchemaJSON: revisionTestSchema}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	existing := domain.NewProjectVariant(project.ID, "B", revisionTestSchema, 60)
+	variantRepo.On("ListByProject", ctx, project.ID).Return([]*domain.ProjectVariant{existing}, nil)
+
+	cases := map[string]*domain.ProjectVariantRequest{
+		"empty name":      {Name: " ", Weight: 10},
+		"negative weight": {Name: "C", Weight: -1},
+		"total over 100":  {Name: "C", Weight: 41},
+		"invalid schema":  {Name: "C", Weight: 10, SchemaJSON: `{"pages":[]}`},
+	}
+	for name, req := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.CreateVariant(ctx, project.UserID.String(), project.ID.String(), req)
+			require.Error(t, err)
+		})
+	}
+	variantRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestExperimentService_UpdateVariant_ExcludesOwnWeight(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	variantRepo := new(mocks.ProjectVariantRepositoryMock)
+	svc := NewExperimentService(projectRepo, variantRepo, new(mocks.AnalyticsRepositoryMock))
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), SchemaJSON: revisionTestSchema}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	variant := domain.NewProjectVariant(project.ID, "B", revisionTestSchema, 60)
+	other := domain.NewProjectVariant(project.ID, "C", revisionTestSchema, 20)
+	variantRepo.On("GetByID", ctx, variant.ID.String()).Return(variant, nil).Once()
+	variantRepo.On("ListByProject", ctx, project.ID).Return([]*domain.ProjectVariant{variant, other}, nil).Once()
+	variantRepo.On("Update", ctx, variant).Return(nil).Once()
+
+	updated, err := svc.UpdateVariant(ctx, project.UserID.String(), project.ID.String(), variant.ID.String(), &domain.ProjectVariantRequest{Name: "B2", Weight: 80})
+	require.NoError(t, err)
+	assert.Equal(t, 80, updated.Weight)
+	assert.Equal(t, revisionTestSchema, updated.SchemaJSON)
+}
+
+func TestExperimentService_UpdateVariant_OtherProject(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	variantRepo := new(mocks.ProjectVariantRepositoryMock)
+	svc := NewExperimentService(projectRepo, variantRepo, new(mocks.AnalyticsRepositoryMock))
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), SchemaJSON: revisionTestSchema}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	variant := domain.NewProjectVariant(uuid.New(), "B", revisionTestSchema, 10)
+	variantRepo.On("GetByID", ctx, variant.ID.String()).Return(variant, nil).Once()
+
+	_, err := svc.UpdateVariant(ctx, project.UserID.String(), project.ID.String(), variant.ID.String(), &domain.ProjectVariantRequest{Name: "B", Weight: 10})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestExperimentService_GetResults(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	variantRepo := new(mocks.ProjectVariantRepositoryMock)
+	analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+	svc := NewExperimentService(projectRepo, variantRepo, analyticsRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), SchemaJSON: revisionTestSchema}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	variant := domain.NewProjectVariant(project.ID, "Short hero", revisionTestSchema, 50)
+	variantRepo.On("ListByProject", ctx, project.ID).Return([]*domain.ProjectVariant{variant}, nil).Once()
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 14)
+	analyticsRepo.On("GetVariantConversions", ctx, project.ID, defaultExperimentGoal, from, to).Return([]*domain.VariantConversion{
+		{Variant: domain.VariantControl, Visitors: 1000, Conversions: 50},
+		{Variant: variant.Key(), Visitors: 1000, Conversions: 80},
+	}, nil).Once()
+
+	results, err := svc.GetResults(ctx, project.UserID.String(), project.ID.String(), &domain.ExperimentResultsQuery{From: from, To: to})
+	require.NoError(t, err)
+	require.Len(t, results.Variants, 2)
+
+	control := results.Variants[0]
+	assert.Equal(t, domain.VariantControl, control.Key)
+	assert.Equal(t, 50, control.Weight)
+	assert.Equal(t, 5.0, *control.ConversionRate)
+	assert.Nil(t, control.PValue)
+
+	b := results.Variants[1]
+	assert.Equal(t, 8.0, *b.ConversionRate)
+	assert.Equal(t, 60.0, *b.Uplift)
+	// z ≈ 2.72 для 5% против 8% на 1000 посетителей
+	assert.InDelta(t, 0.0065, *b.PValue, 0.0005)
+	assert.True(t, b.Significant)
+	assert.Equal(t, variant.Key(), results.Winner)
+}
+
+func TestExperimentService_GetResults_NotSignificant(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	variantRepo := new(mocks.ProjectVariantRepositoryMock)
+	analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+	svc := NewExperimentService(projectRepo, variantRepo, analyticsRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), SchemaJSON: revisionTestSchema}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	variant := domain.NewProjectVariant(project.ID, "B", revisionTestSchema, 50)
+	variantRepo.On("ListByProject", ctx, project.ID).Return([]*domain.ProjectVariant{variant}, nil).Once()
+	analyticsRepo.On("GetVariantConversions", ctx, project.ID, defaultExperimentGoal, mock.Anything, mock.Anything).Return([]*domain.VariantConversion{
+		{Variant: domain.VariantControl, Visitors: 100, Conversions: 5},
+		{Variant: variant.Key(), Visitors: 100, Conversions: 7},
+	}, nil).Once()
+
+	results, err := svc.GetResults(ctx, project.UserID.String(), project.ID.String(), &domain.ExperimentResultsQuery{})
+	require.NoError(t, err)
+
+	assert.False(t, results.Variants[1].Significant)
+	assert.Greater(t, *results.Variants[1].PValue, significanceLevel)
+	assert.Empty(t, results.Winner)
+}
+
+func TestExperimentService_GetResults_GoalOfOtherProject(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	analyticsRepo := new(mocks.AnalyticsRepositoryMock)
+	svc := NewExperimentService(projectRepo, new(mocks.ProjectVariantRepositoryMock), analyticsRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), SchemaJSON: revisionTestSchema}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	goalRepo := new(mocks.AnalyticsGoalRepositoryMock)
+	svc.SetGoalRepository(goalRepo)
+
+	goal := domain.NewAnalyticsGoal(uuid.New(), "Lead", domain.AnalyticsEventFormSubmit, "")
+	goalRepo.On("GetByID", ctx, goal.ID.String()).Return(goal, nil).Once()
+
+	_, err := svc.GetResults(ctx, project.UserID.String(), project.ID.String(), &domain.ExperimentResultsQuery{GoalID: &goal.ID})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	analyticsRepo.AssertNotCalled(t, "GetVariantConversions", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestExperimentService_PromoteVariant(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	variantRepo := new(mocks.ProjectVariantRepositoryMock)
+	revisionRepo := new(mocks.SchemaRevisionRepositoryMock)
+	svc := NewExperimentService(projectRepo, variantRepo, new(mocks.AnalyticsRepositoryMock))
+	svc.SetRevisionRepository(revisionRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), SchemaJSON: revisionTestSchema}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	winnerSchema := `{"version":"1.0","pages":[{"path":"/","title":"Home","blocks":[{"type":"hero","order":0,"props":{"headline":"B"}}]}]}`
+	variant := domain.NewProjectVariant(project.ID, "B", winnerSchema, 50)
+	variantRepo.On("GetByID", ctx, variant.ID.String()).Return(variant, nil).Once()
+	projectRepo.On("UpdateSchema", ctx, project.ID.String(), winnerSchema).Return(nil).Once()
+	revisionRepo.On("Create", ctx, mock.MatchedBy(func(revision *domain.SchemaRevision) bool {
+		return revision.Source == domain.RevisionSourceExperiment && revision.SchemaJSON == winnerSchema
+	})).Return(nil).Once()
+	variantRepo.On("DeleteByProject", ctx, project.ID).Return(nil).Once()
+
+	promoted, err := svc.PromoteVariant(ctx, project.UserID.String(), project.ID.String(), variant.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, winnerSchema, promoted.SchemaJSON)
+
+	projectRepo.AssertExpectations(t)
+	revisionRepo.AssertExpectations(t)
+	variantRepo.AssertExpectations(t)
+}
+
+func TestExperimentService_PromoteControl_KeepsSchema(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	variantRepo := new(mocks.ProjectVariantRepositoryMock)
+	revisionRepo := new(mocks.SchemaRevisionRepositoryMock)
+	svc := NewExperimentService(projectRepo, variantRepo, new(mocks.AnalyticsRepositoryMock))
+	svc.SetRevisionRepository(revisionRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), SchemaJSON: revisionTestSchema}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	variantRepo.On("DeleteByProject", ctx, project.ID).Return(nil).Once()
+
+	_, err := svc.PromoteVariant(ctx, project.UserID.String(), project.ID.String(), domain.VariantControl)
+	require.NoError(t, err)
+
+	projectRepo.AssertNotCalled(t, "UpdateSchema", mock.Anything, mock.Anything, mock.Anything)
+	revisionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,346 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/landly/backend/internal/blocks/form"
+	"github.com/landly/backend/internal/logger"
+	domain "github.com/landly/backend/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// minFormFillTime люди не заполняют форму быстрее; боты отправляют её сразу после загрузки
+	minFormFillTime = 3 * time.Second
+	// maxFormTokenAge после этого токен формы не принимается: вкладку пора перезагрузить
+	maxFormTokenAge = 24 * time.Hour
+
+	defaultSubmissionsLimit = 50
+	maxSubmissionsLimit     = 200
+	// maxExportSubmissions предел строк CSV-выгрузки
+	maxExportSubmissions = 10000
+
+	maxSubmissionPathLength = 512
+)
+
+// FormService заявки из блоков form опубликованных сайтов.
+// Заявка проверяется по формам опубликованного деплоя: основной схемы и вариантов A/B-теста.
+// Время заполнения формы считается по подписанному tokenSecret токену с временем выдачи.
+type FormService struct {
+	projectRepo       domain.ProjectRepository
+	variantRepo       domain.ProjectVariantRepository
+	publishTargetRepo domain.PublishTargetRepository
+	deploymentRepo    domain.DeploymentRepository
+	submissionRepo    domain.FormSubmissionRepository
+	tokenSecret       []byte
+	events            EventEmitter
+}
+
+// NewFormService создаёт сервис заявок
+func NewFormService(
+	projectRepo domain.ProjectRepository,
+	variantRepo domain.ProjectVariantRepository,
+	publishTargetRepo domain.PublishTargetRepository,
+	deploymentRepo domain.DeploymentRepository,
+	submissionRepo domain.FormSubmissionRepository,
+	tokenSecret string,
+) *FormService {
+	return &FormService{
+		projectRepo:       projectRepo,
+		variantRepo:       variantRepo,
+		publishTargetRepo: publishTargetRepo,
+		deploymentRepo:    deploymentRepo,
+		submissionRepo:    submissionRepo,
+		tokenSecret:       []byte(tokenSecret),
+	}
+}
+
+// SetEventEmitter отправляет событие lead.submitted подписчикам вебхуков проекта
+func (s *FormService) SetEventEmitter(events EventEmitter) {
+	s.events = events
+}
+
+// IssueFormToken выдаёт токен формы: время выдачи и подпись проекта, формы и времени.
+// Скрипт формы запрашивает его при загрузке страницы и отправляет вместе с заявкой.
+func (s *FormService) IssueFormToken(projectID uuid.UUID, formName string) string {
+	issuedAt := strconv.FormatInt(time.Now().Unix(), 10)
+	return issuedAt + "." + s.signFormToken(projectID, formName, issuedAt)
+}
+
+// SubmitForm сохраняет заявку. Заявки, похожие на спам, отбрасываются без ошибки,
+// чтобы бот не узнал о фильтре.
+func (s *FormService) SubmitForm(ctx context.Context, req *domain.FormSubmissionRequest) error {
+	if reason := s.spamReason(req); reason != "" {
+		logger.WithContext(ctx).Info("form submission discarded",
+			zap.String("project_id", req.ProjectID.String()),
+			zap.String("form", req.Form),
+			zap.String("reason", reason))
+		return nil
+	}
+
+	project, err := s.projectRepo.GetByID(ctx, req.ProjectID.String())
+	if err != nil {
+		return domain.ErrNotFound.WithMessage("project not found")
+	}
+
+	forms, variants, err := s.publishedForms(ctx, project)
+	if err != nil {
+		return err
+	}
+	definition, ok := findForm(forms, req.Form)
+	if !ok {
+		return domain.ErrNotFound.WithMessage("form not found")
+	}
+
+	fields, err := definition.Clean(req.Fields)
+	if err != nil {
+		return domain.ErrInvalidInput.WithMessage(err.Error())
+	}
+
+	path := req.Path
+	if !strings.HasPrefix(path, "/") || len(path) > maxSubmissionPathLength {
+		path = ""
+	}
+	// Вариант сохраняется, только если он есть в опубликованном эксперименте
+	variant := ""
+	if variants[req.Variant] {
+		variant = req.Variant
+	}
+
+	submission, err := domain.NewFormSubmission(project.ID, definition.Name, fields, path, variant)
+	if err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+	if err := s.submissionRepo.Create(ctx, submission); err != nil {
+		return err
+	}
+
+	if s.events != nil {
+		s.events.Emit(ctx, project.ID, domain.WebhookEventLeadSubmitted, leadSubmittedData{
+			SubmissionID: submission.ID,
+			Form:         submission.FormName,
+			Fields:       fields,
+			Path:         submission.Path,
+			Variant:      submission.Variant,
+		})
+	}
+	return nil
+}
+
+// ListSubmissions возвращает заявки проекта, новые первыми
+func (s *FormService) ListSubmissions(ctx context.Context, userID, projectID string, query domain.FormSubmissionsQuery) ([]*domain.FormSubmission, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = defaultSubmissionsLimit
+	}
+	if query.Limit > maxSubmissionsLimit {
+		query.Limit = maxSubmissionsLimit
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	return s.submissionRepo.ListByProject(ctx, project.ID, query)
+}
+
+// ExportSubmissions пишет заявки в CSV: дата, форма, страница, вариант и поля.
+// Поля идут в порядке форм схемы, поля удалённых из схемы форм — в конце по алфавиту.
+func (s *FormService) ExportSubmissions(ctx context.Context, userID, projectID, formName string, w io.Writer) error {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return err
+	}
+
+	submissions, err := s.submissionRepo.ListByProject(ctx, project.ID, domain.FormSubmissionsQuery{Form: formName, Limit: maxExportSubmissions})
+	if err != nil {
+		return err
+	}
+	forms, err := s.projectForms(ctx, project)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]map[string]string, len(submissions))
+	var columns []string
+	known := make(map[string]bool)
+	for _, definition := range forms {
+		if formName != "" && definition.Name != formName {
+			continue
+		}
+		for _, field := range definition.Fields {
+			if !known[field.Name] {
+				known[field.Name] = true
+				columns = append(columns, field.Name)
+			}
+		}
+	}
+	var extra []string
+	for i, submission := range submissions {
+		fields, err := submission.Fields()
+		if err != nil {
+			return domain.ErrInternal.WithError(err)
+		}
+		rows[i] = fields
+		for name := range fields {
+			if !known[name] {
+				known[name] = true
+				extra = append(extra, name)
+			}
+		}
+	}
+	sort.Strings(extra)
+	columns = append(columns, extra...)
+
+	writer := csv.NewWriter(w)
+	header := append([]string{"created_at", "form", "path", "variant"}, columns...)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for i, submission := range submissions {
+		record := []string{
+			submission.CreatedAt.UTC().Format(time.RFC3339),
+			csvSafe(submission.FormName),
+			csvSafe(submission.Path),
+			csvSafe(submission.Variant),
+		}
+		for _, column := range columns {
+			record = append(record, csvSafe(rows[i][column]))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// publishedForms формы и ключи вариантов активного деплоя — того, что видят посетители.
+// Деплои без снимка схемы, опубликованные до его появления, проверяются по черновику проекта.
+func (s *FormService) publishedForms(ctx context.Context, project *domain.Project) ([]form.Form, map[string]bool, error) {
+	target, err := s.publishTargetRepo.GetByProjectID(ctx, project.ID.String())
+	if err != nil || target.ActiveDeploymentID == nil {
+		return nil, nil, domain.ErrNotFound.WithMessage("project is not published")
+	}
+	deployment, err := s.deploymentRepo.GetByID(ctx, target.ActiveDeploymentID.String())
+	if err != nil {
+		return nil, nil, domain.ErrNotFound.WithMessage("project is not published")
+	}
+
+	variants := make(map[string]bool, len(deployment.Variants))
+	for _, variant := range deployment.Variants {
+		variants[variant.Key] = true
+	}
+	if deployment.SchemaJSON == "" {
+		forms, err := s.projectForms(ctx, project)
+		return forms, variants, err
+	}
+
+	schemas := []string{deployment.SchemaJSON}
+	for _, variant := range deployment.Variants {
+		if variant.SchemaJSON != "" {
+			schemas = append(schemas, variant.SchemaJSON)
+		}
+	}
+	return schemaForms(schemas), variants, nil
+}
+
+// projectForms формы черновика: основной схемы и вариантов A/B-теста; первой идёт основная схема
+func (s *FormService) projectForms(ctx context.Context, project *domain.Project) ([]form.Form, error) {
+	schemas := []string{project.SchemaJSON}
+	variants, err := s.variantRepo.ListByProject(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, variant := range variants {
+		schemas = append(schemas, variant.SchemaJSON)
+	}
+	return schemaForms(schemas), nil
+}
+
+// schemaForms формы из блоков form схем; повреждённые схемы пропускаются
+func schemaForms(schemas []string) []form.Form {
+	var forms []form.Form
+	for _, schemaJSON := range schemas {
+		var schema map[string]interface{}
+		if err := json.Unmarshal([]byte(schemaJSON), &schema); err != nil {
+			continue
+		}
+		forms = append(forms, form.All(schema)...)
+	}
+	return forms
+}
+
+func findForm(forms []form.Form, name string) (form.Form, bool) {
+	for _, definition := range forms {
+		if definition.Name == name {
+			return definition, true
+		}
+	}
+	return form.Form{}, false
+}
+
+// spamReason признак бота: заполненная ловушка, чужой или поддельный токен,
+// отправка быстрее minFormFillTime после выдачи токена или по устаревшему токену
+func (s *FormService) spamReason(req *domain.FormSubmissionRequest) string {
+	if strings.TrimSpace(req.Honeypot) != "" {
+		return "honeypot"
+	}
+	issuedAt, ok := s.formTokenIssuedAt(req.ProjectID, req.Form, req.Token)
+	if !ok {
+		return "invalid token"
+	}
+	age := time.Since(issuedAt)
+	if age < minFormFillTime {
+		return "too fast"
+	}
+	if age > maxFormTokenAge {
+		return "token expired"
+	}
+	return ""
+}
+
+// formTokenIssuedAt проверяет подпись токена и возвращает время его выдачи
+func (s *FormService) formTokenIssuedAt(projectID uuid.UUID, formName, token string) (time.Time, bool) {
+	issuedAt, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return time.Time{}, false
+	}
+	expected := s.signFormToken(projectID, formName, issuedAt)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return time.Time{}, false
+	}
+	unix, err := strconv.ParseInt(issuedAt, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(unix, 0), true
+}
+
+func (s *FormService) signFormToken(projectID uuid.UUID, formName, issuedAt string) string {
+	mac := hmac.New(sha256.New, s.tokenSecret)
+	mac.Write([]byte("form-token\n" + projectID.String() + "\n" + formName + "\n" + issuedAt))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// csvSafe защищает от выполнения формул при открытии выгрузки в Excel и аналогах
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
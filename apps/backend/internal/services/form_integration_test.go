@@ -0,0 +1,64 @@
+//go:build integration
+// +build integration
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/repositories"
+	testhelpers "github.com/landly/backend/internal/testing"
+)
+
+func TestFormService_Integration_SubmitAndExport(t *testing.T) {
+	ctx := context.Background()
+	qb := testhelpers.SetupTestDB(t)
+	projectRepo := repositories.NewProjectRepository(qb)
+	targetRepo := repositories.NewPublishTargetRepository(qb)
+	deploymentRepo := repositories.NewDeploymentRepository(qb)
+	forms := NewFormService(projectRepo, repositories.NewProjectVariantRepository(qb), targetRepo, deploymentRepo,
+		repositories.NewFormSubmissionRepository(qb), "form-token-secret")
+
+	user, _ := testhelpers.CreateTestUser(t, qb, "", "")
+	project := testhelpers.CreateTestProject(t, qb, user.ID, "Leads Project", "SaaS")
+	userID, projectID := user.ID.String(), project.ID.String()
+
+	// Форма есть только в опубликованной схеме, черновик проекта пуст
+	target := domain.NewPublishTarget(project.ID, "leads-project-1234abcd")
+	require.NoError(t, targetRepo.Create(ctx, target))
+	deployment := domain.NewDeployment(project.ID, target.Subdomain, &user.ID)
+	deployment.SchemaJSON = formTestSchema
+	require.NoError(t, deploymentRepo.Create(ctx, deployment))
+	require.NoError(t, targetRepo.SetActiveDeployment(ctx, target.ID, deployment.ID))
+
+	for _, name := range []string{"Анна", "Борис"} {
+		require.NoError(t, forms.SubmitForm(ctx, &domain.FormSubmissionRequest{
+			ProjectID: project.ID,
+			Form:      "lead",
+			Fields:    map[string]string{"name": name, "email": "lead@example.com"},
+			Path:      "/",
+			Token:     formTokenIssuedAgo(forms, project.ID, "lead", 10*time.Second),
+		}))
+	}
+
+	list, err := forms.ListSubmissions(ctx, userID, projectID, domain.FormSubmissionsQuery{Form: "lead", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	fields, err := list[0].Fields()
+	require.NoError(t, err)
+	assert.Equal(t, "Борис", fields["name"], "newest submission first")
+
+	var buf bytes.Buffer
+	require.NoError(t, forms.ExportSubmissions(ctx, userID, projectID, "", &buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "created_at,form,path,variant,name,email", lines[0])
+}
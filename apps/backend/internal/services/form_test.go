@@ -0,0 +1,302 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/services/mocks"
+)
+
+const formTestSchema = `{"pages":[{"path":"/","blocks":[{"type":"form","props":{"fields":[
+	{"name":"name","required":true},
+	{"name":"email","type":"email","required":true}
+]}}]}]}`
+
+const formTestVariantSchema = `{"pages":[{"path":"/","blocks":[{"type":"form","props":{"name":"callback","fields":[
+	{"name":"phone","type":"tel","required":true}
+]}}]}]}`
+
+// formTokenIssuedAgo токен формы, выданный age назад
+func formTokenIssuedAgo(s *FormService, projectID uuid.UUID, formName string, age time.Duration) string {
+	issuedAt := strconv.FormatInt(time.Now().Add(-age).Unix(), 10)
+	return issuedAt + "." + s.signFormToken(projectID, formName, issuedAt)
+}
+
+func TestFormService_SubmitForm_StoresCleanedFields(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	deploymentRepo := new(mocks.DeploymentRepositoryMock)
+	submissionRepo := new(mocks.FormSubmissionRepositoryMock)
+	svc := NewFormService(projectRepo, nil, targetRepo, deploymentRepo, submissionRepo, "form-token-secret")
+
+	// В черновике формы уже нет: заявка проверяется по опубликованной схеме
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), SchemaJSON: `{"pages":[]}`}
+	deploymentID := uuid.New()
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil).Once()
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(&domain.PublishTarget{ActiveDeploymentID: &deploymentID}, nil).Once()
+	deploymentRepo.On("GetByID", ctx, deploymentID.String()).Return(&domain.Deployment{
+		ID:         deploymentID,
+		SchemaJSON: formTestSchema,
+		Variants: []domain.DeploymentVariant{
+			{Key: domain.VariantControl, Weight: 70},
+			{Key: "b", Weight: 30, SchemaJSON: formTestVariantSchema},
+		},
+	}, nil).Once()
+	submissionRepo.On("Create", ctx, mock.MatchedBy(func(s *domain.FormSubmission) bool {
+		fields, err := s.Fields()
+		return err == nil && s.ProjectID == project.ID && s.FormName == "lead" && s.Path == "/" && s.Variant == "control" &&
+			fields["name"] == "Анна" && fields["email"] == "anna@example.com" && len(fields) == 2
+	})).Return(nil).Once()
+
+	err := svc.SubmitForm(ctx, &domain.FormSubmissionRequest{
+		ProjectID: project.ID,
+		Form:      "lead",
+		Fields:    map[string]string{"name": " Анна ", "email": "anna@example.com", "admin": "true"},
+		Path:      "/",
+		Variant:   "control",
+		Token:     formTokenIssuedAgo(svc, project.ID, "lead", 12*time.Second),
+	})
+	require.NoError(t, err)
+	submissionRepo.AssertExpectations(t)
+}
+
+func TestFormService_SubmitForm_VariantForm(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	deploymentRepo := new(mocks.DeploymentRepositoryMock)
+	submissionRepo := new(mocks.FormSubmissionRepositoryMock)
+	svc := NewFormService(projectRepo, nil, targetRepo, deploymentRepo, submissionRepo, "form-token-secret")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), SchemaJSON: formTestSchema}
+	deploymentID := uuid.New()
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil).Once()
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(&domain.PublishTarget{ActiveDeploymentID: &deploymentID}, nil).Once()
+	deploymentRepo.On("GetByID", ctx, deploymentID.String()).Return(&domain.Deployment{
+		ID:         deploymentID,
+		SchemaJSON: formTestSchema,
+		Variants:   []domain.DeploymentVariant{{Key: "b", Weight: 100, SchemaJSON: formTestVariantSchema}},
+	}, nil).Once()
+	submissionRepo.On("Create", ctx, mock.MatchedBy(func(s *domain.FormSubmission) bool {
+		return s.FormName == "callback" && s.Path == "" && s.Variant == ""
+	})).Return(nil).Once()
+
+	// Неизвестный вариант и путь не со слеша не сохраняются
+	err := svc.SubmitForm(ctx, &domain.FormSubmissionRequest{
+		ProjectID: project.ID,
+		Form:      "callback",
+		Fields:    map[string]string{"phone": "+7 900 123-45-67"},
+		Path:      "javascript:alert(1)",
+		Variant:   "=HYPERLINK(\"http://x\")",
+		Token:     formTokenIssuedAgo(svc, project.ID, "callback", 5*time.Second),
+	})
+	require.NoError(t, err)
+	submissionRepo.AssertExpectations(t)
+}
+
+func TestFormService_SubmitForm_LegacyDeploymentUsesDraft(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	variantRepo := new(mocks.ProjectVariantRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	deploymentRepo := new(mocks.DeploymentRepositoryMock)
+	submissionRepo := new(mocks.FormSubmissionRepositoryMock)
+	svc := NewFormService(projectRepo, variantRepo, targetRepo, deploymentRepo, submissionRepo, "form-token-secret")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), SchemaJSON: formTestSchema}
+	deploymentID := uuid.New()
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil).Once()
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(&domain.PublishTarget{ActiveDeploymentID: &deploymentID}, nil).Once()
+	deploymentRepo.On("GetByID", ctx, deploymentID.String()).Return(&domain.Deployment{ID: deploymentID}, nil).Once()
+	variantRepo.On("ListByProject", ctx, project.ID).
+		Return([]*domain.ProjectVariant{{ID: uuid.New(), ProjectID: project.ID, SchemaJSON: formTestVariantSchema}}, nil).Once()
+	submissionRepo.On("Create", ctx, mock.MatchedBy(func(s *domain.FormSubmission) bool {
+		return s.FormName == "callback" && s.Variant == ""
+	})).Return(nil).Once()
+
+	err := svc.SubmitForm(ctx, &domain.FormSubmissionRequest{
+		ProjectID: project.ID,
+		Form:      "callback",
+		Fields:    map[string]string{"phone": "+7 900 123-45-67"},
+		Variant:   "control",
+		Token:     formTokenIssuedAgo(svc, project.ID, "callback", 5*time.Second),
+	})
+	require.NoError(t, err)
+	submissionRepo.AssertExpectations(t)
+	variantRepo.AssertExpectations(t)
+}
+
+func TestFormService_SubmitForm_NotPublished(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	submissionRepo := new(mocks.FormSubmissionRepositoryMock)
+	svc := NewFormService(projectRepo, nil, targetRepo, nil, submissionRepo, "form-token-secret")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), SchemaJSON: formTestSchema}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil).Once()
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(&domain.PublishTarget{}, nil).Once()
+
+	err := svc.SubmitForm(ctx, &domain.FormSubmissionRequest{
+		ProjectID: project.ID,
+		Form:      "lead",
+		Fields:    map[string]string{"name": "Анна", "email": "anna@example.com"},
+		Token:     formTokenIssuedAgo(svc, project.ID, "lead", 10*time.Second),
+	})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	submissionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestFormService_SubmitForm_DiscardsSpam(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	submissionRepo := new(mocks.FormSubmissionRepositoryMock)
+	svc := NewFormService(projectRepo, nil, nil, nil, submissionRepo, "form-token-secret")
+
+	projectID := uuid.New()
+	fields := map[string]string{"name": "Bot", "email": "bot@example.com"}
+	valid := formTokenIssuedAgo(svc, projectID, "lead", 10*time.Second)
+
+	cases := map[string]*domain.FormSubmissionRequest{
+		"honeypot":      {ProjectID: projectID, Form: "lead", Fields: fields, Honeypot: "http://spam.example", Token: valid},
+		"no token":      {ProjectID: projectID, Form: "lead", Fields: fields},
+		"forged token":  {ProjectID: projectID, Form: "lead", Fields: fields, Token: "1700000000.deadbeef"},
+		"other form":    {ProjectID: projectID, Form: "callback", Fields: fields, Token: valid},
+		"other project": {ProjectID: uuid.New(), Form: "lead", Fields: fields, Token: valid},
+		"too fast":      {ProjectID: projectID, Form: "lead", Fields: fields, Token: svc.IssueFormToken(projectID, "lead")},
+		"expired":       {ProjectID: projectID, Form: "lead", Fields: fields, Token: formTokenIssuedAgo(svc, projectID, "lead", 25*time.Hour)},
+	}
+	for name, req := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, svc.SubmitForm(ctx, req))
+		})
+	}
+	projectRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	submissionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestFormService_SubmitForm_Rejects(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	deploymentRepo := new(mocks.DeploymentRepositoryMock)
+	submissionRepo := new(mocks.FormSubmissionRepositoryMock)
+	svc := NewFormService(projectRepo, nil, targetRepo, deploymentRepo, submissionRepo, "form-token-secret")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), SchemaJSON: formTestSchema}
+	deploymentID := uuid.New()
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(&domain.PublishTarget{ActiveDeploymentID: &deploymentID}, nil)
+	deploymentRepo.On("GetByID", ctx, deploymentID.String()).Return(&domain.Deployment{ID: deploymentID, SchemaJSON: formTestSchema}, nil)
+
+	err := svc.SubmitForm(ctx, &domain.FormSubmissionRequest{
+		ProjectID: project.ID, Form: "lead", Fields: map[string]string{"name": "Анна", "email": "not-an-email"},
+		Token: formTokenIssuedAgo(svc, project.ID, "lead", 10*time.Second),
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+	// Форма из черновика варианта не опубликована
+	err = svc.SubmitForm(ctx, &domain.FormSubmissionRequest{
+		ProjectID: project.ID, Form: "callback", Fields: map[string]string{"phone": "+7 900 123-45-67"},
+		Token: formTokenIssuedAgo(svc, project.ID, "callback", 10*time.Second),
+	})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	submissionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestFormService_ListSubmissions(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	submissionRepo := new(mocks.FormSubmissionRepositoryMock)
+	svc := NewFormService(projectRepo, nil, nil, nil, submissionRepo, "form-token-secret")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New()}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+	submissionRepo.On("ListByProject", ctx, project.ID, domain.FormSubmissionsQuery{Form: "lead", Limit: maxSubmissionsLimit}).
+		Return([]*domain.FormSubmission{}, nil).Once()
+
+	_, err := svc.ListSubmissions(ctx, project.UserID.String(), project.ID.String(), domain.FormSubmissionsQuery{Form: "lead", Limit: 1000, Offset: -5})
+	require.NoError(t, err)
+	submissionRepo.AssertExpectations(t)
+
+	_, err = svc.ListSubmissions(ctx, uuid.NewString(), project.ID.String(), domain.FormSubmissionsQuery{})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
+
+func TestFormService_ExportSubmissions(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	variantRepo := new(mocks.ProjectVariantRepositoryMock)
+	submissionRepo := new(mocks.FormSubmissionRepositoryMock)
+	svc := NewFormService(projectRepo, variantRepo, nil, nil, submissionRepo, "form-token-secret")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), SchemaJSON: formTestSchema}
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil).Once()
+	variantRepo.On("ListByProject", ctx, project.ID).Return([]*domain.ProjectVariant{}, nil).Once()
+
+	submissions := []*domain.FormSubmission{
+		{FormName: "lead", Data: `{"email":"anna@example.com","name":"=HYPERLINK(\"http://x\")"}`, Path: "/", Variant: "control", CreatedAt: createdAt},
+		{FormName: "lead", Data: `{"name":"Борис","company":"ООО Ромашка"}`, Path: "/pricing", Variant: "@cmd", CreatedAt: createdAt},
+	}
+	submissionRepo.On("ListByProject", ctx, project.ID, domain.FormSubmissionsQuery{Form: "lead", Limit: maxExportSubmissions}).
+		Return(submissions, nil).Once()
+
+	var buf bytes.Buffer
+	require.NoError(t, svc.ExportSubmissions(ctx, project.UserID.String(), project.ID.String(), "lead", &buf))
+
+	expected := "created_at,form,path,variant,name,email,company\n" +
+		"2026-03-01T12:00:00Z,lead,/,control,\"'=HYPERLINK(\"\"http://x\"\")\",anna@example.com,\n" +
+		"2026-03-01T12:00:00Z,lead,/pricing,'@cmd,Борис,,ООО Ромашка\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestFormService_SubmitForm_EmitsLeadSubmitted(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	deploymentRepo := new(mocks.DeploymentRepositoryMock)
+	submissionRepo := new(mocks.FormSubmissionRepositoryMock)
+	events := new(mocks.EventEmitterMock)
+	svc := NewFormService(projectRepo, nil, targetRepo, deploymentRepo, submissionRepo, "form-token-secret")
+	svc.SetEventEmitter(events)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), SchemaJSON: formTestSchema}
+	deploymentID := uuid.New()
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil).Once()
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(&domain.PublishTarget{ActiveDeploymentID: &deploymentID}, nil).Once()
+	deploymentRepo.On("GetByID", ctx, deploymentID.String()).Return(&domain.Deployment{ID: deploymentID, SchemaJSON: formTestSchema}, nil).Once()
+	submissionRepo.On("Create", ctx, mock.AnythingOfType("*domain.FormSubmission")).Return(nil).Once()
+	events.On("Emit", ctx, project.ID, domain.WebhookEventLeadSubmitted, mock.MatchedBy(func(data leadSubmittedData) bool {
+		return data.Form == "lead" && data.Fields["email"] == "anna@example.com" && data.SubmissionID != uuid.Nil
+	})).Once()
+
+	err := svc.SubmitForm(ctx, &domain.FormSubmissionRequest{
+		ProjectID: project.ID,
+		Form:      "lead",
+		Fields:    map[string]string{"name": "Анна", "email": "anna@example.com"},
+		Token:     formTokenIssuedAgo(svc, project.ID, "lead", 12*time.Second),
+	})
+	require.NoError(t, err)
+	events.AssertExpectations(t)
+
+	// Спам не доходит до подписчиков
+	require.NoError(t, svc.SubmitForm(ctx, &domain.FormSubmissionRequest{
+		ProjectID: project.ID,
+		Form:      "lead",
+		Fields:    map[string]string{"name": "Bot", "email": "bot@example.com"},
+		Honeypot:  "http://spam.example",
+		Token:     formTokenIssuedAgo(svc, project.ID, "lead", 12*time.Second),
+	}))
+	events.AssertNumberOfCalls(t, "Emit", 1)
+}
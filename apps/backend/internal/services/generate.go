@@ -8,14 +8,23 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/landly/backend/internal/jobs"
 	"github.com/landly/backend/internal/logger"
 	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/schema"
 	"go.uber.org/zap"
 )
 
 // AIClient интерфейс для AI-генерации
 type AIClient interface {
 	GenerateLandingSchema(ctx context.Context, prompt, paymentURL string) (string, error)
+	GenerateBlockProps(ctx context.Context, blockType, propsJSON, instruction string) (string, error)
+}
+
+// StreamingAIClient AI клиент с потоковой генерацией (опционально реализуется AIClient)
+type StreamingAIClient interface {
+	AIClient
+	StreamLandingSchema(ctx context.Context, prompt, paymentURL string, onChunk func(chunk string)) (string, error)
 }
 
 // GenerateService сервис для генерации лендингов
@@ -25,6 +34,9 @@ type GenerateService struct {
 	sessionRepo     domain.GenerationSessionRepository
 	messageRepo     domain.GenerationMessageRepository
 	aiClient        AIClient
+	schemaGenerator *schemaGenerator
+	revisionRepo    domain.SchemaRevisionRepository
+	jobQueue        JobQueue
 }
 
 // NewGenerateService создаёт новый generate service
@@ -41,10 +53,34 @@ func NewGenerateService(
 		sessionRepo:     sessionRepo,
 		messageRepo:     messageRepo,
 		aiClient:        aiClient,
+		schemaGenerator: newSchemaGenerator(aiClient),
 	}
 }
 
-// GenerateSite генерирует лендинг (новый интерфейс)
+// SetSchemaRepairAttempts задаёт число попыток исправления схемы, не прошедшей валидацию
+func (s *GenerateService) SetSchemaRepairAttempts(attempts int) {
+	s.schemaGenerator.repairAttempts = attempts
+}
+
+// SetRevisionRepository включает запись ревизий схемы после каждой генерации
+func (s *GenerateService) SetRevisionRepository(revisionRepo domain.SchemaRevisionRepository) {
+	s.revisionRepo = revisionRepo
+}
+
+// SetJobQueue переносит генерацию из запроса в фоновую задачу воркера
+func (s *GenerateService) SetJobQueue(queue JobQueue) {
+	s.jobQueue = queue
+}
+
+// generateJobPayload данные задачи генерации
+type generateJobPayload struct {
+	SessionID  uuid.UUID `json:"session_id"`
+	Prompt     string    `json:"prompt"`
+	PaymentURL string    `json:"payment_url"`
+}
+
+// GenerateSite генерирует лендинг (новый интерфейс).
+// С очередью задач возвращает сессию в статусе pending, генерацию выполняет воркер.
 func (s *GenerateService) GenerateSite(ctx context.Context, userID, projectID string, req *domain.GenerateRequest) (*domain.GenerationSession, error) {
 	userUUID, err := uuid.Parse(userID)
 	if err != nil {
@@ -62,50 +98,116 @@ func (s *GenerateService) GenerateSite(ctx context.Context, userID, projectID st
 		return nil, domain.ErrInternal.WithError(err)
 	}
 
-	defer func() {
-		if err := s.sessionRepo.Update(context.Background(), session); err != nil {
-			log := logger.WithContext(ctx).With(
-				zap.String("project_id", projectUUID.String()),
-				zap.String("user_id", userUUID.String()),
-			)
-			log.Error("failed to update session status", zap.Error(err))
-		}
-	}()
+	if s.jobQueue != nil {
+		return s.enqueueGeneration(ctx, session, userUUID, req)
+	}
+
+	// Без очереди генерация доводится до конца, даже если клиент закрыл соединение
+	return session, s.runGeneration(context.WithoutCancel(ctx), session, userUUID, req.Prompt, req.PaymentURL, true)
+}
+
+func (s *GenerateService) enqueueGeneration(ctx context.Context, session *domain.GenerationSession, userID uuid.UUID, req *domain.GenerateRequest) (*domain.GenerationSession, error) {
+	if _, err := ensureProjectOwnership(ctx, s.projectRepo, userID.String(), session.ProjectID.String()); err != nil {
+		session.Status = domain.GenerationStatusFailed
+		session.CompletedAt = ptrTime(time.Now())
+		s.saveSession(ctx, session)
+		return nil, err
+	}
+
+	job, err := s.jobQueue.Enqueue(ctx, domain.JobTypeGenerate, generateJobPayload{
+		SessionID:  session.ID,
+		Prompt:     req.Prompt,
+		PaymentURL: req.PaymentURL,
+	}, userID, session.ProjectID)
+	if err != nil {
+		session.Status = domain.GenerationStatusFailed
+		session.CompletedAt = ptrTime(time.Now())
+		s.saveSession(ctx, session)
+		return nil, err
+	}
+
+	session.JobID = &job.ID
+	return session, nil
+}
+
+// HandleGenerateJob выполняет задачу генерации в воркере
+func (s *GenerateService) HandleGenerateJob(ctx context.Context, job *domain.Job) error {
+	var payload generateJobPayload
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		return domain.ErrBadRequest.WithMessage("invalid generate job payload")
+	}
+	if job.UserID == nil {
+		return domain.ErrBadRequest.WithMessage("generate job without user")
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, payload.SessionID.String())
+	if err != nil {
+		return err
+	}
+	if session.Status == domain.GenerationStatusCompleted {
+		return nil
+	}
+
+	session.Status = domain.GenerationStatusPending
+	return s.runGeneration(ctx, session, *job.UserID, payload.Prompt, payload.PaymentURL, job.LastAttempt())
+}
+
+// runGeneration генерирует схему и записывает итог в сессию. Если final == false, временная ошибка
+// оставляет сессию в ожидании: воркер повторит задачу, и статус failed означал бы ложный финал.
+func (s *GenerateService) runGeneration(ctx context.Context, session *domain.GenerationSession, userID uuid.UUID, prompt, paymentURL string, final bool) error {
+	projectID := session.ProjectID
+	defer s.saveSession(ctx, session)
 
-	// Запускаем генерацию синхронно
 	log := logger.WithContext(ctx).With(
-		zap.String("project_id", projectUUID.String()),
-		zap.String("user_id", userUUID.String()),
+		zap.String("project_id", projectID.String()),
+		zap.String("user_id", userID.String()),
 	)
 
 	log.Info("starting landing page generation",
-		zap.String("prompt", req.Prompt),
-		zap.String("payment_url", req.PaymentURL),
+		zap.String("prompt", prompt),
+		zap.String("payment_url", paymentURL),
 	)
 
-	updatedProject, err := s.GenerateLanding(context.Background(), userUUID, projectUUID, req.Prompt, req.PaymentURL)
+	updatedProject, err := s.GenerateLanding(ctx, userID, projectID, prompt, paymentURL)
 	if err != nil {
+		if !final && !jobs.IsPermanent(err) {
+			log.Warn("generation failed, job will be retried", zap.Error(err))
+			session.Status = domain.GenerationStatusPending
+			return domain.ErrInternal.WithError(err)
+		}
+
 		log.Error("generation failed", zap.Error(err))
 		session.Status = domain.GenerationStatusFailed
 		session.CompletedAt = ptrTime(time.Now())
-		return session, domain.ErrInternal.WithError(err)
+		if validationErrs, ok := schemaValidationErrors(err); ok {
+			session.ErrorJSON = validationErrs.JSON()
+			return err
+		}
+		if jobs.IsPermanent(err) {
+			return err
+		}
+		return domain.ErrInternal.WithError(err)
 	}
 
 	log.Info("generation completed successfully")
 	session.Status = domain.GenerationStatusCompleted
+	session.ErrorJSON = ""
 	session.CompletedAt = ptrTime(time.Now())
 	if updatedProject != nil {
 		session.SchemaJSON = updatedProject.SchemaJSON
 	}
 
-	// Обновляем сессию
-	if updateErr := s.sessionRepo.Update(context.Background(), session); updateErr != nil {
-		log.Error("failed to update session", zap.Error(updateErr))
-	} else {
-		log.Info("session updated in database")
-	}
+	return nil
+}
 
-	return session, nil
+func (s *GenerateService) saveSession(ctx context.Context, session *domain.GenerationSession) {
+	if err := s.sessionRepo.Update(context.Background(), session); err != nil {
+		logger.WithContext(ctx).Error("failed to update session status",
+			zap.String("session_id", session.ID.String()),
+			zap.String("project_id", session.ProjectID.String()),
+			zap.Error(err),
+		)
+	}
 }
 
 // GetGenerationStatus получает статус генерации
@@ -187,11 +289,16 @@ func (s *GenerateService) GenerateLanding(ctx context.Context, userID, projectID
 	)
 
 	log.Info("calling AI client for schema generation")
-	schemaJSON, err := s.aiClient.GenerateLandingSchema(ctx, prompt, paymentURL)
+	schemaJSON, err := s.schemaGenerator.generate(ctx, prompt, paymentURL, nil)
 	if err != nil {
 		log.Error("AI generation failed", zap.Error(err))
 		// Обновляем статус сессии на ошибку
 		session.Status = domain.GenerationStatusFailed
+		session.CompletedAt = ptrTime(time.Now())
+		if validationErrs, ok := schemaValidationErrors(err); ok {
+			session.ErrorJSON = validationErrs.JSON()
+			return nil, err
+		}
 		return nil, domain.ErrInternal.WithMessage("AI generation failed")
 	}
 
@@ -207,9 +314,11 @@ func (s *GenerateService) GenerateLanding(ctx context.Context, userID, projectID
 		return nil, domain.ErrInternal.WithError(err)
 	}
 	log.Info("schema saved to project successfully")
+	recordSchemaRevision(ctx, s.revisionRepo, projectID, schemaJSON, domain.RevisionSourceGenerate, &userID, nil)
 
 	session.Status = domain.GenerationStatusCompleted
 	session.SchemaJSON = schemaJSON
+	session.ErrorJSON = ""
 	session.CompletedAt = ptrTime(time.Now())
 
 	// Получаем обновлённый проект
@@ -246,7 +355,7 @@ func (s *GenerateService) GetPreview(ctx context.Context, userID, projectID uuid
 
 // GetChatHistory возвращает текущую сессию и историю сообщений для проекта
 func (s *GenerateService) GetChatHistory(ctx context.Context, userID, projectID string) (*domain.GenerationSession, []*domain.GenerationMessage, error) {
-	project, err := s.ensureProjectOwnership(ctx, userID, projectID)
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -264,18 +373,54 @@ func (s *GenerateService) GetChatHistory(ctx context.Context, userID, projectID
 	return session, messages, nil
 }
 
-// SendChatMessage обрабатывает новое сообщение пользователя и возвращает обновлённую историю
-func (s *GenerateService) SendChatMessage(ctx context.Context, userID, projectID, content string) (*domain.GenerationSession, []*domain.GenerationMessage, error) {
+// SendChatMessage обрабатывает новое сообщение пользователя и возвращает обновлённую историю.
+// Если задан target (или в сообщении явно упомянут один блок), перегенерируется только этот блок.
+func (s *GenerateService) SendChatMessage(ctx context.Context, userID, projectID, content string, target *domain.BlockTarget) (*domain.GenerationSession, []*domain.GenerationMessage, error) {
+	return s.sendChatMessage(ctx, userID, projectID, content, target, nil)
+}
+
+// StreamChatMessage обрабатывает сообщение как SendChatMessage, сообщая о ходе генерации через emit
+func (s *GenerateService) StreamChatMessage(ctx context.Context, userID, projectID, content string, target *domain.BlockTarget, emit func(domain.ChatStreamEvent)) (*domain.GenerationSession, []*domain.GenerationMessage, error) {
+	return s.sendChatMessage(ctx, userID, projectID, content, target, emit)
+}
+
+// chatMessageMetadata метаданные ответа ассистента в чате
+type chatMessageMetadata struct {
+	SchemaUpdated bool              `json:"schema_updated"`
+	SchemaLength  int               `json:"schema_length"`
+	ChangedBlocks []domain.BlockRef `json:"changed_blocks"`
+}
+
+func (s *GenerateService) sendChatMessage(ctx context.Context, userID, projectID, content string, target *domain.BlockTarget, emit func(domain.ChatStreamEvent)) (*domain.GenerationSession, []*domain.GenerationMessage, error) {
+	// Без подписчика генерируем схему обычным (непотоковым) запросом
+	var onChunk func(attempt int, chunk string)
+	if emit == nil {
+		emit = func(domain.ChatStreamEvent) {}
+	} else {
+		onChunk = s.chunkEmitter(emit)
+	}
+
 	trimmed := strings.TrimSpace(content)
 	if trimmed == "" {
 		return nil, nil, domain.ErrBadRequest.WithMessage("message content is required")
 	}
 
-	project, err := s.ensureProjectOwnership(ctx, userID, projectID)
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	var edit *blockEdit
+	if target != nil {
+		edit, err = resolveBlockTarget(project.SchemaJSON, target)
+		if err != nil {
+			return nil, nil, domain.ErrBadRequest.WithMessage(err.Error())
+		}
+	} else if detected := detectBlockTarget(trimmed); detected != nil {
+		// Блок не найден — перегенерируем схему целиком
+		edit, _ = resolveBlockTarget(project.SchemaJSON, detected)
+	}
+
 	session, err := s.ensureSessionForProject(ctx, project)
 	if err != nil {
 		return nil, nil, err
@@ -295,28 +440,47 @@ func (s *GenerateService) SendChatMessage(ctx context.Context, userID, projectID
 	if err := s.messageRepo.Create(ctx, userMessage); err != nil {
 		return nil, nil, err
 	}
+	emit(domain.ChatStreamEvent{Type: domain.ChatEventMessageAccepted, Message: userMessage})
 
 	messages, err := s.messageRepo.ListBySession(ctx, session.ID.String())
 	if err != nil {
 		return nil, nil, err
 	}
 
-	prompt := s.buildChatPrompt(session, messages)
-
 	log := logger.WithContext(ctx).With(
 		zap.String("project_id", project.ID.String()),
 		zap.String("user_id", project.UserID.String()),
 	)
 
-	log.Info("generating landing schema via chat", zap.String("prompt_snippet", truncateForLog(prompt)))
-
-	schemaJSON, err := s.aiClient.GenerateLandingSchema(ctx, prompt, "")
+	var schemaJSON string
+	var changedBlocks []domain.BlockRef
+	if edit != nil {
+		log.Info("regenerating single block via chat",
+			zap.String("page_path", edit.ref.PagePath),
+			zap.Int("block_index", edit.ref.BlockIndex),
+			zap.String("block_type", edit.ref.BlockType),
+		)
+		schemaJSON, err = s.regenerateBlock(ctx, project.SchemaJSON, edit, trimmed, emit)
+		changedBlocks = []domain.BlockRef{edit.ref}
+	} else {
+		prompt := s.buildChatPrompt(session, messages)
+		log.Info("generating landing schema via chat", zap.String("prompt_snippet", truncateForLog(prompt)))
+		schemaJSON, err = s.schemaGenerator.generate(ctx, prompt, "", onChunk)
+		changedBlocks = diffBlocks(project.SchemaJSON, schemaJSON)
+	}
 	if err != nil {
 		log.Error("chat generation failed", zap.Error(err))
 		session.Status = domain.GenerationStatusFailed
 		session.UpdatedAt = now
 		session.CompletedAt = ptrTime(now)
+		validationErrs, isValidationErr := schemaValidationErrors(err)
+		if isValidationErr {
+			session.ErrorJSON = validationErrs.JSON()
+		}
 		_ = s.sessionRepo.Update(ctx, session)
+		if isValidationErr {
+			return nil, nil, err
+		}
 		return nil, nil, domain.ErrInternal.WithError(err)
 	}
 
@@ -324,6 +488,7 @@ func (s *GenerateService) SendChatMessage(ctx context.Context, userID, projectID
 		log.Error("failed to persist generated schema", zap.Error(err))
 		return nil, nil, err
 	}
+	recordSchemaRevision(ctx, s.revisionRepo, project.ID, schemaJSON, domain.RevisionSourceChat, &project.UserID, &userMessage.ID)
 
 	project.SchemaJSON = schemaJSON
 	project.Status = domain.ProjectStatusGenerated
@@ -332,20 +497,33 @@ func (s *GenerateService) SendChatMessage(ctx context.Context, userID, projectID
 	session.Prompt = trimmed
 	session.Status = domain.GenerationStatusCompleted
 	session.SchemaJSON = schemaJSON
+	session.ErrorJSON = ""
 	session.CompletedAt = ptrTime(now)
 	session.UpdatedAt = now
 	if err := s.sessionRepo.Update(ctx, session); err != nil {
 		log.Error("failed to update generation session", zap.Error(err))
 		return nil, nil, err
 	}
+	emit(domain.ChatStreamEvent{Type: domain.ChatEventSchemaSaved, Session: session})
 
 	assistantContent := fmt.Sprintf("Готово! Я обновил лендинг согласно запросу: \"%s\". Посмотри предпросмотр справа.", truncateUserContent(trimmed))
+	if edit != nil {
+		assistantContent = fmt.Sprintf("Готово! Я обновил блок %s на странице %s согласно запросу: \"%s\". Остальные блоки не менялись.", edit.ref.BlockType, edit.ref.PagePath, truncateUserContent(trimmed))
+	}
+	metadata, err := json.Marshal(chatMessageMetadata{
+		SchemaUpdated: true,
+		SchemaLength:  len(schemaJSON),
+		ChangedBlocks: changedBlocks,
+	})
+	if err != nil {
+		return nil, nil, domain.ErrInternal.WithError(err)
+	}
 	assistantMessage := &domain.GenerationMessage{
 		ID:         uuid.New(),
 		SessionID:  session.ID,
 		Role:       domain.MessageRoleAssistant,
 		Content:    assistantContent,
-		Metadata:   fmt.Sprintf("{\"schema_updated\":true,\"schema_length\":%d}", len(schemaJSON)),
+		Metadata:   string(metadata),
 		TokensUsed: 0,
 		CreatedAt:  time.Now(),
 	}
@@ -354,6 +532,7 @@ func (s *GenerateService) SendChatMessage(ctx context.Context, userID, projectID
 		log.Error("failed to save assistant message", zap.Error(err))
 		return nil, nil, err
 	}
+	emit(domain.ChatStreamEvent{Type: domain.ChatEventAssistantMessage, Message: assistantMessage})
 
 	messages = append(messages, assistantMessage)
 
@@ -364,17 +543,41 @@ func (s *GenerateService) SendChatMessage(ctx context.Context, userID, projectID
 	return session, messages, nil
 }
 
-func (s *GenerateService) ensureProjectOwnership(ctx context.Context, userID, projectID string) (*domain.Project, error) {
-	project, err := s.projectRepo.GetByID(ctx, projectID)
+// regenerateBlock отправляет AI только props выбранного блока и подставляет ответ в исходную схему,
+// не трогая остальные байты; невалидный результат не сохраняется
+func (s *GenerateService) regenerateBlock(ctx context.Context, schemaJSON string, edit *blockEdit, instruction string, emit func(domain.ChatStreamEvent)) (string, error) {
+	updated, err := s.schemaGenerator.generateBlock(ctx, schemaJSON, edit, instruction)
 	if err != nil {
-		return nil, domain.ErrNotFound.WithMessage("project not found")
+		return "", err
 	}
 
-	if project.UserID.String() != userID {
-		return nil, domain.ErrForbidden.WithMessage("access denied")
+	if block, err := schema.ExtractValue(updated, "pages", edit.pageIndex, "blocks", edit.ref.BlockIndex); err == nil {
+		emit(domain.ChatStreamEvent{Type: domain.ChatEventBlockParsed, BlockIndex: edit.ref.BlockIndex, Block: block})
 	}
 
-	return project, nil
+	return updated, nil
+}
+
+// chunkEmitter превращает фрагменты ответа модели в события token и block_parsed
+func (s *GenerateService) chunkEmitter(emit func(domain.ChatStreamEvent)) func(attempt int, chunk string) {
+	parser := newBlockStreamParser()
+	currentAttempt := 0
+	blockIndex := 0
+
+	return func(attempt int, chunk string) {
+		if attempt != currentAttempt {
+			// Исправленная схема приходит целиком заново
+			parser = newBlockStreamParser()
+			currentAttempt = attempt
+			blockIndex = 0
+		}
+
+		emit(domain.ChatStreamEvent{Type: domain.ChatEventToken, Delta: chunk, Attempt: attempt})
+		for _, block := range parser.Feed(chunk) {
+			emit(domain.ChatStreamEvent{Type: domain.ChatEventBlockParsed, BlockIndex: blockIndex, Block: block, Attempt: attempt})
+			blockIndex++
+		}
+	}
 }
 
 func (s *GenerateService) ensureSessionForProject(ctx context.Context, project *domain.Project) (*domain.GenerationSession, error) {
@@ -385,7 +588,8 @@ func (s *GenerateService) ensureSessionForProject(ctx context.Context, project *
 
 	if len(sessions) > 0 {
 		session := sessions[0]
-		if session.SchemaJSON == "" {
+		// Схема проекта могла измениться вне чата (ручная правка, восстановление ревизии)
+		if session.SchemaJSON == "" || project.SchemaJSON != "" {
 			session.SchemaJSON = project.SchemaJSON
 		}
 		return session, nil
@@ -126,3 +126,7 @@ type failingAIClient struct{}
 func (failingAIClient) GenerateLandingSchema(ctx context.Context, prompt, paymentURL string) (string, error) {
 	return "", errors.New("ai generation failed")
 }
+
+func (failingAIClient) GenerateBlockProps(ctx context.Context, blockType, propsJSON, instruction string) (string, error) {
+	return "", errors.New("ai generation failed")
+}
@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -11,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/schema"
 	"github.com/landly/backend/internal/services/mocks"
 )
 
@@ -33,7 +36,7 @@ func TestGenerateService_GenerateLanding_Success(t *testing.T) {
 		require.Equal(t, "Prompt", session.Prompt)
 		return true
 	})).Return(nil).Once()
-	generatedSchema := `{"pages":[{"path":"/","title":"Home","blocks":[]}]} `
+	generatedSchema := `{"version":"1.0","pages":[{"path":"/","title":"Home","blocks":[]}]} `
 	aiClient.On("GenerateLandingSchema", ctx, "Prompt", "https://pay").Return(generatedSchema, nil).Once()
 	projectRepo.On("UpdateSchema", ctx, projectID.String(), generatedSchema).Return(nil).Once()
 	projectRepo.On("GetByID", ctx, projectID.String()).Return(&domain.Project{ID: projectID, UserID: userID, SchemaJSON: generatedSchema}, nil).Once()
@@ -82,6 +85,89 @@ func TestGenerateService_GenerateLanding_AIError(t *testing.T) {
 	aiClient.AssertExpectations(t)
 }
 
+func TestGenerateService_GenerateLanding_RepairsInvalidSchema(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	userID := uuid.New()
+
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	sessionRepo := new(mocks.GenerationSessionRepositoryMock)
+	messageRepo := new(mocks.GenerationMessageRepositoryMock)
+	aiClient := new(mocks.AIClientMock)
+
+	svc := NewGenerateService(projectRepo, nil, sessionRepo, messageRepo, aiClient)
+
+	invalidSchema := `{"version":"1.0","pages":[{"path":"home","title":"Home","blocks":[]}]}`
+	repairedSchema := `{"version":"1.0","pages":[{"path":"/","title":"Home","blocks":[]}]}`
+
+	projectRepo.On("GetByID", ctx, projectID.String()).Return(&domain.Project{ID: projectID, UserID: userID}, nil).Once()
+	sessionRepo.On("Create", ctx, mock.AnythingOfType("*domain.GenerationSession")).Return(nil).Once()
+	aiClient.On("GenerateLandingSchema", ctx, "Prompt", "https://pay").Return(invalidSchema, nil).Once()
+	aiClient.On("GenerateLandingSchema", ctx, mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, "$.pages[0].path: must match pattern ^/.*") &&
+			strings.Contains(prompt, invalidSchema) &&
+			strings.Contains(prompt, "Prompt")
+	}), "https://pay").Return(repairedSchema, nil).Once()
+	projectRepo.On("UpdateSchema", ctx, projectID.String(), repairedSchema).Return(nil).Once()
+	projectRepo.On("GetByID", ctx, projectID.String()).Return(&domain.Project{ID: projectID, UserID: userID, SchemaJSON: repairedSchema}, nil).Once()
+	sessionRepo.On("Update", mock.Anything, mock.MatchedBy(func(session *domain.GenerationSession) bool {
+		return session.Status == domain.GenerationStatusCompleted && session.ErrorJSON == ""
+	})).Return(nil).Once()
+
+	updated, err := svc.GenerateLanding(ctx, userID, projectID, "Prompt", "https://pay")
+	require.NoError(t, err)
+	assert.Equal(t, repairedSchema, updated.SchemaJSON)
+
+	projectRepo.AssertExpectations(t)
+	sessionRepo.AssertExpectations(t)
+	aiClient.AssertExpectations(t)
+}
+
+func TestGenerateService_GenerateLanding_RepairAttemptsExhausted(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	userID := uuid.New()
+
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	sessionRepo := new(mocks.GenerationSessionRepositoryMock)
+	messageRepo := new(mocks.GenerationMessageRepositoryMock)
+	aiClient := new(mocks.AIClientMock)
+
+	svc := NewGenerateService(projectRepo, nil, sessionRepo, messageRepo, aiClient)
+	svc.SetSchemaRepairAttempts(1)
+
+	invalidSchema := `{"pages":[{"path":"/","title":"Home","blocks":[{"type":"carousel","order":0,"props":{}}]}]}`
+
+	projectRepo.On("GetByID", ctx, projectID.String()).Return(&domain.Project{ID: projectID, UserID: userID}, nil).Once()
+	sessionRepo.On("Create", ctx, mock.AnythingOfType("*domain.GenerationSession")).Return(nil).Once()
+	aiClient.On("GenerateLandingSchema", ctx, mock.Anything, "https://pay").Return(invalidSchema, nil).Twice()
+
+	var failed *domain.GenerationSession
+	sessionRepo.On("Update", mock.Anything, mock.MatchedBy(func(session *domain.GenerationSession) bool {
+		failed = session
+		return session.Status == domain.GenerationStatusFailed
+	})).Return(nil).Once()
+
+	updated, err := svc.GenerateLanding(ctx, userID, projectID, "Prompt", "https://pay")
+	assert.Nil(t, updated)
+
+	var domainErr *domain.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domain.ErrSchemaInvalid.Code, domainErr.Code)
+
+	require.NotNil(t, failed)
+	var validationErrs schema.ValidationErrors
+	require.NoError(t, json.Unmarshal([]byte(failed.ErrorJSON), &validationErrs))
+	assert.ElementsMatch(t, schema.ValidationErrors{
+		{Path: "$.version", Message: "is required"},
+		{Path: "$.pages[0].blocks[0].type", Message: "must be one of [hero, features, pricing, testimonials, faq, cta, gallery, about, contact, form]"},
+	}, validationErrs)
+
+	projectRepo.AssertNotCalled(t, "UpdateSchema", mock.Anything, mock.Anything, mock.Anything)
+	sessionRepo.AssertExpectations(t)
+	aiClient.AssertExpectations(t)
+}
+
 func TestGenerateService_GenerateSite_InvalidUser(t *testing.T) {
 	ctx := context.Background()
 	projectRepo := new(mocks.ProjectRepositoryMock)
@@ -101,3 +187,355 @@ func TestGenerateService_GenerateSite_InvalidUser(t *testing.T) {
 	assert.Nil(t, session)
 	assert.Error(t, err)
 }
+
+func TestGenerateService_StreamChatMessage_EmitsProgressEvents(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	userID := uuid.New()
+
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	sessionRepo := new(mocks.GenerationSessionRepositoryMock)
+	messageRepo := new(mocks.GenerationMessageRepositoryMock)
+	aiClient := new(mocks.StreamingAIClientMock)
+
+	svc := NewGenerateService(projectRepo, nil, sessionRepo, messageRepo, aiClient)
+
+	session := &domain.GenerationSession{ID: uuid.New(), ProjectID: projectID, SchemaJSON: "{}"}
+	chunks := []string{
+		`{"version":"1.0","pages":[{"path":"/","title":"Home","blocks":[{"type":"hero",`,
+		`"order":0,"props":{}},{"type":"cta","order":1,`,
+		`"props":{}}]}]}`,
+	}
+	finalSchema := strings.Join(chunks, "")
+
+	projectRepo.On("GetByID", ctx, projectID.String()).Return(&domain.Project{ID: projectID, UserID: userID}, nil).Once()
+	sessionRepo.On("GetByProjectID", ctx, projectID.String()).Return([]*domain.GenerationSession{session}, nil).Once()
+	messageRepo.On("Create", ctx, mock.MatchedBy(func(msg *domain.GenerationMessage) bool {
+		return msg.Role == domain.MessageRoleUser
+	})).Return(nil).Once()
+	messageRepo.On("ListBySession", ctx, session.ID.String()).Return([]*domain.GenerationMessage{{Role: domain.MessageRoleUser, Content: "Добавь CTA"}}, nil).Once()
+	aiClient.On("StreamLandingSchema", ctx, mock.AnythingOfType("string"), "").Return(chunks, nil).Once()
+	projectRepo.On("UpdateSchema", ctx, projectID.String(), finalSchema).Return(nil).Once()
+	sessionRepo.On("Update", ctx, session).Return(nil).Once()
+	messageRepo.On("Create", ctx, mock.MatchedBy(func(msg *domain.GenerationMessage) bool {
+		return msg.Role == domain.MessageRoleAssistant
+	})).Return(nil).Once()
+
+	var events []domain.ChatStreamEvent
+	_, messages, err := svc.StreamChatMessage(ctx, userID.String(), projectID.String(), "Добавь CTA", nil, func(event domain.ChatStreamEvent) {
+		events = append(events, event)
+	})
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+
+	var types []string
+	var blockTypes []string
+	for _, event := range events {
+		types = append(types, event.Type)
+		if event.Type == domain.ChatEventBlockParsed {
+			var block map[string]interface{}
+			require.NoError(t, json.Unmarshal(event.Block, &block))
+			blockTypes = append(blockTypes, block["type"].(string))
+		}
+	}
+	assert.Equal(t, []string{
+		domain.ChatEventMessageAccepted,
+		domain.ChatEventToken,
+		domain.ChatEventToken,
+		domain.ChatEventBlockParsed,
+		domain.ChatEventToken,
+		domain.ChatEventBlockParsed,
+		domain.ChatEventSchemaSaved,
+		domain.ChatEventAssistantMessage,
+	}, types)
+	assert.Equal(t, []string{"hero", "cta"}, blockTypes)
+
+	aiClient.AssertNotCalled(t, "GenerateLandingSchema", mock.Anything, mock.Anything, mock.Anything)
+	projectRepo.AssertExpectations(t)
+	sessionRepo.AssertExpectations(t)
+	messageRepo.AssertExpectations(t)
+	aiClient.AssertExpectations(t)
+}
+
+func TestGenerateService_SendChatMessage_RegeneratesTargetBlock(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	userID := uuid.New()
+
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	sessionRepo := new(mocks.GenerationSessionRepositoryMock)
+	messageRepo := new(mocks.GenerationMessageRepositoryMock)
+	aiClient := new(mocks.AIClientMock)
+
+	revisionRepo := new(mocks.SchemaRevisionRepositoryMock)
+
+	svc := NewGenerateService(projectRepo, nil, sessionRepo, messageRepo, aiClient)
+	svc.SetRevisionRepository(revisionRepo)
+
+	original := `{
+  "version": "1.0",
+  "pages": [
+    {
+      "path": "/",
+      "title": "Home",
+      "blocks": [
+        {"type": "hero", "order": 0, "props": {"headline": "Старый"}},
+        {
+          "type": "pricing",
+          "order": 1,
+          "props": {"title": "Тарифы", "plans": []}
+        }
+      ]
+    }
+  ]
+}`
+	expected := `{
+  "version": "1.0",
+  "pages": [
+    {
+      "path": "/",
+      "title": "Home",
+      "blocks": [
+        {"type": "hero", "order": 0, "props": {"headline": "Старый"}},
+        {
+          "type": "pricing",
+          "order": 1,
+          "props": {
+            "title": "Новые тарифы",
+            "plans": []
+          }
+        }
+      ]
+    }
+  ]
+}`
+	session := &domain.GenerationSession{ID: uuid.New(), ProjectID: projectID, SchemaJSON: original}
+
+	projectRepo.On("GetByID", ctx, projectID.String()).Return(&domain.Project{ID: projectID, UserID: userID, SchemaJSON: original}, nil).Once()
+	sessionRepo.On("GetByProjectID", ctx, projectID.String()).Return([]*domain.GenerationSession{session}, nil).Once()
+	messageRepo.On("ListBySession", ctx, session.ID.String()).Return([]*domain.GenerationMessage{}, nil).Once()
+	aiClient.On("GenerateBlockProps", ctx, "pricing", `{"title": "Тарифы", "plans": []}`, "Перепиши блок с тарифами").
+		Return(`{"title":"Новые тарифы","plans":[]}`, nil).Once()
+	var userMessageID uuid.UUID
+	messageRepo.On("Create", ctx, mock.MatchedBy(func(msg *domain.GenerationMessage) bool {
+		return msg.Role == domain.MessageRoleUser
+	})).Run(func(args mock.Arguments) {
+		userMessageID = args.Get(1).(*domain.GenerationMessage).ID
+	}).Return(nil).Once()
+	projectRepo.On("UpdateSchema", ctx, projectID.String(), expected).Return(nil).Once()
+	revisionRepo.On("Create", ctx, mock.MatchedBy(func(revision *domain.SchemaRevision) bool {
+		return revision.Source == domain.RevisionSourceChat &&
+			revision.SchemaJSON == expected &&
+			revision.MessageID != nil && *revision.MessageID == userMessageID
+	})).Return(nil).Once()
+	sessionRepo.On("Update", ctx, session).Return(nil).Once()
+
+	var assistant *domain.GenerationMessage
+	messageRepo.On("Create", ctx, mock.MatchedBy(func(msg *domain.GenerationMessage) bool {
+		return msg.Role == domain.MessageRoleAssistant
+	})).Run(func(args mock.Arguments) {
+		assistant = args.Get(1).(*domain.GenerationMessage)
+	}).Return(nil).Once()
+
+	_, _, err := svc.SendChatMessage(ctx, userID.String(), projectID.String(), "Перепиши блок с тарифами", nil)
+	require.NoError(t, err)
+	assert.Equal(t, expected, session.SchemaJSON)
+
+	require.NotNil(t, assistant)
+	var metadata struct {
+		ChangedBlocks []domain.BlockRef `json:"changed_blocks"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(assistant.Metadata), &metadata))
+	assert.Equal(t, []domain.BlockRef{{PagePath: "/", BlockIndex: 1, BlockType: "pricing"}}, metadata.ChangedBlocks)
+
+	aiClient.AssertNotCalled(t, "GenerateLandingSchema", mock.Anything, mock.Anything, mock.Anything)
+	projectRepo.AssertExpectations(t)
+	messageRepo.AssertExpectations(t)
+	revisionRepo.AssertExpectations(t)
+	aiClient.AssertExpectations(t)
+}
+
+func TestGenerateService_SendChatMessage_RepairsInvalidBlockProps(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	userID := uuid.New()
+
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	sessionRepo := new(mocks.GenerationSessionRepositoryMock)
+	messageRepo := new(mocks.GenerationMessageRepositoryMock)
+	aiClient := new(mocks.AIClientMock)
+
+	svc := NewGenerateService(projectRepo, nil, sessionRepo, messageRepo, aiClient)
+
+	original := `{"version":"1.0","pages":[{"path":"/","title":"Home","blocks":[{"type":"pricing","order":0,"props":{"title":"Тарифы","plans":[]}}]}]}`
+	expected := `{"version":"1.0","pages":[{"path":"/","title":"Home","blocks":[{"type":"pricing","order":0,"props":{"title":"Тарифы","plans":[{"name":"Pro"}]}}]}]}`
+	session := &domain.GenerationSession{ID: uuid.New(), ProjectID: projectID, SchemaJSON: original}
+
+	projectRepo.On("GetByID", ctx, projectID.String()).Return(&domain.Project{ID: projectID, UserID: userID, SchemaJSON: original}, nil).Once()
+	sessionRepo.On("GetByProjectID", ctx, projectID.String()).Return([]*domain.GenerationSession{session}, nil).Once()
+	messageRepo.On("ListBySession", ctx, session.ID.String()).Return([]*domain.GenerationMessage{}, nil).Once()
+	messageRepo.On("Create", ctx, mock.AnythingOfType("*domain.GenerationMessage")).Return(nil).Twice()
+	aiClient.On("GenerateBlockProps", ctx, "pricing", `{"title":"Тарифы","plans":[]}`, "Добавь тариф Pro в блок с тарифами").
+		Return(`{"title":"Тарифы","plans":"Pro"}`, nil).Once()
+	aiClient.On("GenerateBlockProps", ctx, "pricing", `{"title":"Тарифы","plans":[]}`, mock.MatchedBy(func(instruction string) bool {
+		return strings.Contains(instruction, "$.pages[0].blocks[0].props.plans: expected array, got string")
+	})).Return(`{"title":"Тарифы","plans":[{"name":"Pro"}]}`, nil).Once()
+	projectRepo.On("UpdateSchema", ctx, projectID.String(), expected).Return(nil).Once()
+	sessionRepo.On("Update", ctx, session).Return(nil).Once()
+
+	_, _, err := svc.SendChatMessage(ctx, userID.String(), projectID.String(), "Добавь тариф Pro в блок с тарифами", nil)
+	require.NoError(t, err)
+
+	projectRepo.AssertExpectations(t)
+	aiClient.AssertExpectations(t)
+}
+
+func TestGenerateService_SendChatMessage_InvalidBlockPropsNotSaved(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	userID := uuid.New()
+
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	sessionRepo := new(mocks.GenerationSessionRepositoryMock)
+	messageRepo := new(mocks.GenerationMessageRepositoryMock)
+	aiClient := new(mocks.AIClientMock)
+
+	svc := NewGenerateService(projectRepo, nil, sessionRepo, messageRepo, aiClient)
+	svc.SetSchemaRepairAttempts(1)
+
+	original := `{"version":"1.0","pages":[{"path":"/","title":"Home","blocks":[{"type":"pricing","order":0,"props":{"title":"Тарифы","plans":[]}}]}]}`
+	session := &domain.GenerationSession{ID: uuid.New(), ProjectID: projectID, SchemaJSON: original}
+
+	projectRepo.On("GetByID", ctx, projectID.String()).Return(&domain.Project{ID: projectID, UserID: userID, SchemaJSON: original}, nil).Once()
+	sessionRepo.On("GetByProjectID", ctx, projectID.String()).Return([]*domain.GenerationSession{session}, nil).Once()
+	messageRepo.On("ListBySession", ctx, session.ID.String()).Return([]*domain.GenerationMessage{}, nil).Once()
+	messageRepo.On("Create", ctx, mock.AnythingOfType("*domain.GenerationMessage")).Return(nil).Once()
+	aiClient.On("GenerateBlockProps", ctx, "pricing", mock.Anything, mock.Anything).Return(`{"title":["Тарифы"]}`, nil).Twice()
+	sessionRepo.On("Update", ctx, mock.MatchedBy(func(s *domain.GenerationSession) bool {
+		return s.Status == domain.GenerationStatusFailed && strings.Contains(s.ErrorJSON, "$.pages[0].blocks[0].props.title")
+	})).Return(nil).Once()
+
+	_, _, err := svc.SendChatMessage(ctx, userID.String(), projectID.String(), "Перепиши блок с тарифами", nil)
+
+	var domainErr *domain.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domain.ErrSchemaInvalid.Code, domainErr.Code)
+	projectRepo.AssertNotCalled(t, "UpdateSchema", mock.Anything, mock.Anything, mock.Anything)
+	sessionRepo.AssertExpectations(t)
+	aiClient.AssertExpectations(t)
+}
+
+func TestGenerateService_SendChatMessage_UnknownTargetBlock(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	userID := uuid.New()
+
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	sessionRepo := new(mocks.GenerationSessionRepositoryMock)
+	messageRepo := new(mocks.GenerationMessageRepositoryMock)
+	aiClient := new(mocks.AIClientMock)
+
+	svc := NewGenerateService(projectRepo, nil, sessionRepo, messageRepo, aiClient)
+
+	schemaJSON := `{"version":"1.0","pages":[{"path":"/","title":"Home","blocks":[{"type":"hero","order":0,"props":{}}]}]}`
+	projectRepo.On("GetByID", ctx, projectID.String()).Return(&domain.Project{ID: projectID, UserID: userID, SchemaJSON: schemaJSON}, nil).Once()
+
+	index := 3
+	_, _, err := svc.SendChatMessage(ctx, userID.String(), projectID.String(), "Сделай короче", &domain.BlockTarget{PagePath: "/", BlockIndex: &index})
+
+	var domainErr *domain.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domain.ErrBadRequest.Code, domainErr.Code)
+	messageRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	aiClient.AssertNotCalled(t, "GenerateBlockProps", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGenerateService_GenerateSite_EnqueuesJob(t *testing.T) {
+	ctx := context.Background()
+	projectID := uuid.New()
+	userID := uuid.New()
+
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	sessionRepo := new(mocks.GenerationSessionRepositoryMock)
+	messageRepo := new(mocks.GenerationMessageRepositoryMock)
+	aiClient := new(mocks.AIClientMock)
+	queue := new(mocks.JobQueueMock)
+
+	svc := NewGenerateService(projectRepo, nil, sessionRepo, messageRepo, aiClient)
+	svc.SetJobQueue(queue)
+
+	projectRepo.On("GetByID", ctx, projectID.String()).Return(&domain.Project{ID: projectID, UserID: userID}, nil).Once()
+	sessionRepo.On("Create", ctx, mock.AnythingOfType("*domain.GenerationSession")).Return(nil).Once()
+	job := &domain.Job{ID: uuid.New(), Type: domain.JobTypeGenerate}
+	queue.On("Enqueue", ctx, domain.JobTypeGenerate, mock.MatchedBy(func(payload generateJobPayload) bool {
+		return payload.Prompt == "Prompt" && payload.PaymentURL == "https://pay"
+	}), userID, projectID).Return(job, nil).Once()
+
+	session, err := svc.GenerateSite(ctx, userID.String(), projectID.String(), &domain.GenerateRequest{Prompt: "Prompt", PaymentURL: "https://pay"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.GenerationStatusPending, session.Status)
+	require.NotNil(t, session.JobID)
+	assert.Equal(t, job.ID, *session.JobID)
+
+	aiClient.AssertNotCalled(t, "GenerateLandingSchema", mock.Anything, mock.Anything, mock.Anything)
+	queue.AssertExpectations(t)
+}
+
+func TestGenerateService_HandleGenerateJob_SkipsCompletedSession(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	sessionRepo := new(mocks.GenerationSessionRepositoryMock)
+	messageRepo := new(mocks.GenerationMessageRepositoryMock)
+	aiClient := new(mocks.AIClientMock)
+
+	svc := NewGenerateService(projectRepo, nil, sessionRepo, messageRepo, aiClient)
+
+	session := &domain.GenerationSession{ID: uuid.New(), ProjectID: uuid.New(), Status: domain.GenerationStatusCompleted}
+	sessionRepo.On("GetByID", ctx, session.ID.String()).Return(session, nil).Once()
+
+	payload, err := json.Marshal(generateJobPayload{SessionID: session.ID, Prompt: "Prompt"})
+	require.NoError(t, err)
+
+	err = svc.HandleGenerateJob(ctx, &domain.Job{Type: domain.JobTypeGenerate, Payload: string(payload), UserID: &userID})
+	require.NoError(t, err)
+
+	aiClient.AssertNotCalled(t, "GenerateLandingSchema", mock.Anything, mock.Anything, mock.Anything)
+	sessionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestGenerateService_HandleGenerateJob_FailsSessionOnlyOnLastAttempt(t *testing.T) {
+	// Генерация идёт в контексте задачи: воркер отменяет его при потере аренды и остановке
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	userID := uuid.New()
+	projectID := uuid.New()
+
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	sessionRepo := new(mocks.GenerationSessionRepositoryMock)
+	messageRepo := new(mocks.GenerationMessageRepositoryMock)
+	aiClient := new(mocks.AIClientMock)
+
+	svc := NewGenerateService(projectRepo, nil, sessionRepo, messageRepo, aiClient)
+
+	session := &domain.GenerationSession{ID: uuid.New(), ProjectID: projectID, Status: domain.GenerationStatusPending}
+	projectRepo.On("GetByID", mock.Anything, projectID.String()).Return(&domain.Project{ID: projectID, UserID: userID}, nil)
+	sessionRepo.On("GetByID", ctx, session.ID.String()).Return(session, nil)
+	sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.GenerationSession")).Return(nil)
+	sessionRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.GenerationSession")).Return(nil)
+	aiClient.On("GenerateLandingSchema", ctx, "Prompt", "").Return("", errors.New("ai down"))
+
+	payload, err := json.Marshal(generateJobPayload{SessionID: session.ID, Prompt: "Prompt"})
+	require.NoError(t, err)
+	job := &domain.Job{Type: domain.JobTypeGenerate, Payload: string(payload), UserID: &userID, Attempts: 1, MaxAttempts: 3}
+
+	require.Error(t, svc.HandleGenerateJob(ctx, job))
+	assert.Equal(t, domain.GenerationStatusPending, session.Status, "worker will retry the job")
+	assert.Nil(t, session.CompletedAt)
+
+	job.Attempts = 3
+	require.Error(t, svc.HandleGenerateJob(ctx, job))
+	assert.Equal(t, domain.GenerationStatusFailed, session.Status)
+	assert.NotNil(t, session.CompletedAt)
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	domain "github.com/landly/backend/internal/models"
+)
+
+const (
+	maxPaymentURLLength    = 2048
+	maxWebhookSecretLength = 255
+
+	stripeWebhookSecretPrefix = "whsec_"
+)
+
+// IntegrationService платёжные интеграции проекта (Stripe, PayPal).
+// Ссылка интеграции подставляется в тарифы сайта при следующей публикации.
+type IntegrationService struct {
+	projectRepo     domain.ProjectRepository
+	integrationRepo domain.IntegrationRepository
+}
+
+// NewIntegrationService создаёт сервис интеграций
+func NewIntegrationService(projectRepo domain.ProjectRepository, integrationRepo domain.IntegrationRepository) *IntegrationService {
+	return &IntegrationService{
+		projectRepo:     projectRepo,
+		integrationRepo: integrationRepo,
+	}
+}
+
+// ListIntegrations возвращает интеграции проекта
+func (s *IntegrationService) ListIntegrations(ctx context.Context, userID, projectID string) ([]*domain.Integration, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return s.integrationRepo.GetByProjectID(ctx, project.ID.String())
+}
+
+// GetIntegration возвращает интеграцию проекта
+func (s *IntegrationService) GetIntegration(ctx context.Context, userID, projectID, integrationID string) (*domain.Integration, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return s.projectIntegration(ctx, project, integrationID)
+}
+
+// CreateIntegration подключает интеграцию; у проекта одна интеграция каждого типа
+func (s *IntegrationService) CreateIntegration(ctx context.Context, userID, projectID string, req *domain.IntegrationRequest) (*domain.Integration, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !domain.IsValidIntegrationType(req.Type) {
+		return nil, domain.ErrInvalidInput.WithMessage("unsupported integration type")
+	}
+	paymentURL, err := normalizePaymentURL(req.PaymentURL)
+	if err != nil {
+		return nil, err
+	}
+	webhookSecret, err := normalizeWebhookSecret(req.Type, req.WebhookSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.integrationRepo.GetByProjectIDAndType(ctx, project.ID.String(), domain.IntegrationType(req.Type))
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, domain.ErrAlreadyExists.WithMessage("integration of this type already exists")
+	}
+
+	integration := domain.NewIntegration(project.ID, domain.IntegrationType(req.Type), "")
+	if err := integration.SetConfig(domain.IntegrationConfig{PaymentURL: paymentURL, WebhookSecret: webhookSecret}); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	if err := s.integrationRepo.Create(ctx, integration); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return integration, nil
+}
+
+// UpdateIntegration меняет платёжную ссылку и секрет вебхуков; незаданные в запросе
+// и неизвестные сервису настройки интеграции сохраняются
+func (s *IntegrationService) UpdateIntegration(ctx context.Context, userID, projectID, integrationID string, req *domain.IntegrationRequest) (*domain.Integration, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	integration, err := s.projectIntegration(ctx, project, integrationID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Type != "" && req.Type != integration.Type {
+		return nil, domain.ErrBadRequest.WithMessage("integration type cannot be changed")
+	}
+
+	if strings.TrimSpace(req.PaymentURL) == "" && strings.TrimSpace(req.WebhookSecret) == "" {
+		return nil, domain.ErrInvalidInput.WithMessage("payment_url or webhook_secret is required")
+	}
+
+	config, err := integration.ParseConfig()
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	if strings.TrimSpace(req.PaymentURL) != "" {
+		if config.PaymentURL, err = normalizePaymentURL(req.PaymentURL); err != nil {
+			return nil, err
+		}
+	}
+	if strings.TrimSpace(req.WebhookSecret) != "" {
+		if config.WebhookSecret, err = normalizeWebhookSecret(integration.Type, req.WebhookSecret); err != nil {
+			return nil, err
+		}
+	}
+	if err := integration.SetConfig(config); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	integration.UpdatedAt = time.Now()
+	if err := s.integrationRepo.Update(ctx, integration); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return integration, nil
+}
+
+// DeleteIntegration отключает интеграцию
+func (s *IntegrationService) DeleteIntegration(ctx context.Context, userID, projectID, integrationID string) error {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return err
+	}
+
+	integration, err := s.projectIntegration(ctx, project, integrationID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.integrationRepo.Delete(ctx, integration.ID.String()); err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+	return nil
+}
+
+// PaymentURL платёжная ссылка проекта для рендерера: первая настроенная
+// в порядке domain.PaymentIntegrationTypes, пустая строка — ссылки нет
+func (s *IntegrationService) PaymentURL(ctx context.Context, projectID uuid.UUID) (string, error) {
+	integrations, err := s.integrationRepo.GetByProjectID(ctx, projectID.String())
+	if err != nil {
+		return "", err
+	}
+
+	links := make(map[string]string, len(integrations))
+	for _, integration := range integrations {
+		config, err := integration.ParseConfig()
+		if err != nil {
+			continue
+		}
+		links[integration.Type] = config.PaymentURL
+	}
+	for _, integrationType := range domain.PaymentIntegrationTypes {
+		if link := links[string(integrationType)]; link != "" {
+			return link, nil
+		}
+	}
+	return "", nil
+}
+
+func (s *IntegrationService) projectIntegration(ctx context.Context, project *domain.Project, integrationID string) (*domain.Integration, error) {
+	integration, err := s.integrationRepo.GetByID(ctx, integrationID)
+	if err != nil {
+		return nil, err
+	}
+	if integration.ProjectID != project.ID {
+		return nil, domain.ErrNotFound.WithMessage("integration not found")
+	}
+	return integration, nil
+}
+
+// normalizePaymentURL принимает только абсолютные https-ссылки: они попадают в href на сайте
+func normalizePaymentURL(raw string) (string, error) {
+	link := strings.TrimSpace(raw)
+	if link == "" {
+		return "", domain.ErrInvalidInput.WithMessage("payment_url is required")
+	}
+	if len(link) > maxPaymentURLLength {
+		return "", domain.ErrInvalidInput.WithMessage("payment_url is too long")
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" || parsed.User != nil {
+		return "", domain.ErrInvalidInput.WithMessage("payment_url must be an absolute https URL")
+	}
+	return parsed.String(), nil
+}
+
+// normalizeWebhookSecret проверяет секрет подписи вебхуков: он есть только у Stripe
+// и выдаётся в дашборде в виде whsec_...; пустой секрет — вебхуки не настроены
+func normalizeWebhookSecret(integrationType, raw string) (string, error) {
+	secret := strings.TrimSpace(raw)
+	if secret == "" {
+		return "", nil
+	}
+	if integrationType != domain.IntegrationTypeStripe {
+		return "", domain.ErrInvalidInput.WithMessage("webhook_secret is supported only for stripe")
+	}
+	if !strings.HasPrefix(secret, stripeWebhookSecretPrefix) || len(secret) <= len(stripeWebhookSecretPrefix) {
+		return "", domain.ErrInvalidInput.WithMessage("webhook_secret must start with " + stripeWebhookSecretPrefix)
+	}
+	if len(secret) > maxWebhookSecretLength {
+		return "", domain.ErrInvalidInput.WithMessage("webhook_secret is too long")
+	}
+	return secret, nil
+}
@@ -0,0 +1,50 @@
+//go:build integration
+// +build integration
+
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/repositories"
+	testhelpers "github.com/landly/backend/internal/testing"
+)
+
+func TestIntegrationService_Integration_CRUDAndPaymentURL(t *testing.T) {
+	ctx := context.Background()
+	qb := testhelpers.SetupTestDB(t)
+	svc := NewIntegrationService(repositories.NewProjectRepository(qb), repositories.NewIntegrationRepository(qb))
+
+	user, _ := testhelpers.CreateTestUser(t, qb, "", "")
+	project := testhelpers.CreateTestProject(t, qb, user.ID, "Payments Project", "SaaS")
+	userID, projectID := user.ID.String(), project.ID.String()
+
+	paypal, err := svc.CreateIntegration(ctx, userID, projectID, &domain.IntegrationRequest{Type: domain.IntegrationTypePayPal, PaymentURL: "https://paypal.me/shop"})
+	require.NoError(t, err)
+	_, err = svc.CreateIntegration(ctx, userID, projectID, &domain.IntegrationRequest{Type: domain.IntegrationTypePayPal, PaymentURL: "https://paypal.me/other"})
+	assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+
+	link, err := svc.PaymentURL(ctx, project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://paypal.me/shop", link)
+
+	stripe, err := svc.CreateIntegration(ctx, userID, projectID, &domain.IntegrationRequest{Type: domain.IntegrationTypeStripe, PaymentURL: "https://buy.stripe.com/old"})
+	require.NoError(t, err)
+	_, err = svc.UpdateIntegration(ctx, userID, projectID, stripe.ID.String(), &domain.IntegrationRequest{PaymentURL: "https://buy.stripe.com/new"})
+	require.NoError(t, err)
+
+	link, err = svc.PaymentURL(ctx, project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://buy.stripe.com/new", link)
+
+	require.NoError(t, svc.DeleteIntegration(ctx, userID, projectID, stripe.ID.String()))
+	integrations, err := svc.ListIntegrations(ctx, userID, projectID)
+	require.NoError(t, err)
+	require.Len(t, integrations, 1)
+	assert.Equal(t, paypal.ID, integrations[0].ID)
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/services/mocks"
+)
+
+func TestIntegrationService_CreateIntegration(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	integrationRepo := new(mocks.IntegrationRepositoryMock)
+	svc := NewIntegrationService(projectRepo, integrationRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	integrationRepo.On("GetByProjectIDAndType", ctx, project.ID.String(), domain.IntegrationType(domain.IntegrationTypeStripe)).Return(nil, domain.ErrNotFound).Once()
+	integrationRepo.On("Create", ctx, mock.AnythingOfType("*domain.Integration")).Return(nil).Once()
+
+	integration, err := svc.CreateIntegration(ctx, project.UserID.String(), project.ID.String(), &domain.IntegrationRequest{
+		Type:       domain.IntegrationTypeStripe,
+		PaymentURL: " https://buy.stripe.com/test_123 ",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, project.ID, integration.ProjectID)
+	assert.JSONEq(t, `{"payment_url":"https://buy.stripe.com/test_123"}`, integration.Config)
+	integrationRepo.AssertExpectations(t)
+}
+
+func TestIntegrationService_CreateIntegration_Rejected(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	integrationRepo := new(mocks.IntegrationRepositoryMock)
+	svc := NewIntegrationService(projectRepo, integrationRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	existing := domain.NewIntegration(project.ID, domain.IntegrationTypePayPal, `{"payment_url":"https://paypal.me/shop"}`)
+	integrationRepo.On("GetByProjectIDAndType", ctx, project.ID.String(), domain.IntegrationType(domain.IntegrationTypePayPal)).Return(existing, nil)
+
+	cases := map[string]struct {
+		req domain.IntegrationRequest
+		err error
+	}{
+		"unknown type":     {domain.IntegrationRequest{Type: "bitcoin", PaymentURL: "https://example.com/pay"}, domain.ErrInvalidInput},
+		"missing url":      {domain.IntegrationRequest{Type: domain.IntegrationTypeStripe}, domain.ErrInvalidInput},
+		"plain http":       {domain.IntegrationRequest{Type: domain.IntegrationTypeStripe, PaymentURL: "http://buy.stripe.com/x"}, domain.ErrInvalidInput},
+		"javascript url":   {domain.IntegrationRequest{Type: domain.IntegrationTypeStripe, PaymentURL: "javascript:alert(1)"}, domain.ErrInvalidInput},
+		"duplicate type":   {domain.IntegrationRequest{Type: domain.IntegrationTypePayPal, PaymentURL: "https://paypal.me/other"}, domain.ErrAlreadyExists},
+		"relative address": {domain.IntegrationRequest{Type: domain.IntegrationTypeStripe, PaymentURL: "/pay"}, domain.ErrInvalidInput},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.CreateIntegration(ctx, project.UserID.String(), project.ID.String(), &tc.req)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+	integrationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestIntegrationService_UpdateIntegration_KeepsOtherSettings(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	integrationRepo := new(mocks.IntegrationRepositoryMock)
+	svc := NewIntegrationService(projectRepo, integrationRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	integration := domain.NewIntegration(project.ID, domain.IntegrationTypeStripe, `{"payment_url":"https://buy.stripe.com/old","extra":"kept"}`)
+	integrationRepo.On("GetByID", ctx, integration.ID.String()).Return(integration, nil)
+	integrationRepo.On("Update", ctx, integration).Return(nil).Once()
+
+	_, err := svc.UpdateIntegration(ctx, project.UserID.String(), project.ID.String(), integration.ID.String(), &domain.IntegrationRequest{
+		Type:       domain.IntegrationTypePayPal,
+		PaymentURL: "https://buy.stripe.com/new",
+	})
+	assert.ErrorIs(t, err, domain.ErrBadRequest)
+
+	updated, err := svc.UpdateIntegration(ctx, project.UserID.String(), project.ID.String(), integration.ID.String(), &domain.IntegrationRequest{
+		PaymentURL: "https://buy.stripe.com/new",
+	})
+	require.NoError(t, err)
+	config, err := updated.ParseConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://buy.stripe.com/new", config.PaymentURL)
+	integrationRepo.AssertExpectations(t)
+}
+
+func TestIntegrationService_WebhookSecret(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	integrationRepo := new(mocks.IntegrationRepositoryMock)
+	svc := NewIntegrationService(projectRepo, integrationRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	integrationRepo.On("GetByProjectIDAndType", ctx, project.ID.String(), mock.Anything).Return(nil, domain.ErrNotFound)
+	integrationRepo.On("Create", ctx, mock.AnythingOfType("*domain.Integration")).Return(nil).Once()
+
+	_, err := svc.CreateIntegration(ctx, project.UserID.String(), project.ID.String(), &domain.IntegrationRequest{
+		Type: domain.IntegrationTypePayPal, PaymentURL: "https://paypal.me/shop", WebhookSecret: "whsec_test_secret",
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidInput, "paypal has no signed webhooks")
+	_, err = svc.CreateIntegration(ctx, project.UserID.String(), project.ID.String(), &domain.IntegrationRequest{
+		Type: domain.IntegrationTypeStripe, PaymentURL: "https://buy.stripe.com/shop", WebhookSecret: "sk_live_oops",
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidInput, "only signing secrets are accepted")
+
+	integration, err := svc.CreateIntegration(ctx, project.UserID.String(), project.ID.String(), &domain.IntegrationRequest{
+		Type: domain.IntegrationTypeStripe, PaymentURL: "https://buy.stripe.com/shop", WebhookSecret: "whsec_test_secret",
+	})
+	require.NoError(t, err)
+
+	// Смена секрета не трогает ссылку, пустой запрос отклоняется
+	integrationRepo.On("GetByID", ctx, integration.ID.String()).Return(integration, nil)
+	integrationRepo.On("Update", ctx, integration).Return(nil).Once()
+	_, err = svc.UpdateIntegration(ctx, project.UserID.String(), project.ID.String(), integration.ID.String(), &domain.IntegrationRequest{})
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+	updated, err := svc.UpdateIntegration(ctx, project.UserID.String(), project.ID.String(), integration.ID.String(), &domain.IntegrationRequest{
+		WebhookSecret: "whsec_rotated",
+	})
+	require.NoError(t, err)
+	config, err := updated.ParseConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://buy.stripe.com/shop", config.PaymentURL)
+	assert.Equal(t, "whsec_rotated", config.WebhookSecret)
+	integrationRepo.AssertExpectations(t)
+}
+
+func TestIntegrationService_ForeignIntegration(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	integrationRepo := new(mocks.IntegrationRepositoryMock)
+	svc := NewIntegrationService(projectRepo, integrationRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	foreign := domain.NewIntegration(uuid.New(), domain.IntegrationTypeStripe, `{}`)
+	integrationRepo.On("GetByID", ctx, foreign.ID.String()).Return(foreign, nil)
+
+	err := svc.DeleteIntegration(ctx, project.UserID.String(), project.ID.String(), foreign.ID.String())
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	_, err = svc.ListIntegrations(ctx, uuid.NewString(), project.ID.String())
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	integrationRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestIntegrationService_PaymentURL_PrefersStripe(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	integrationRepo := new(mocks.IntegrationRepositoryMock)
+	svc := NewIntegrationService(projectRepo, integrationRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	integrationRepo.On("GetByProjectID", ctx, project.ID.String()).Return([]*domain.Integration{
+		domain.NewIntegration(project.ID, domain.IntegrationTypePayPal, `{"payment_url":"https://paypal.me/shop"}`),
+		domain.NewIntegration(project.ID, domain.IntegrationTypeStripe, `{"payment_url":"https://buy.stripe.com/shop"}`),
+	}, nil).Once()
+	link, err := svc.PaymentURL(ctx, project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://buy.stripe.com/shop", link)
+
+	integrationRepo.On("GetByProjectID", ctx, project.ID.String()).Return([]*domain.Integration{
+		domain.NewIntegration(project.ID, domain.IntegrationTypeStripe, `{}`),
+		domain.NewIntegration(project.ID, domain.IntegrationTypePayPal, `{"payment_url":"https://paypal.me/shop"}`),
+	}, nil).Once()
+	link, err = svc.PaymentURL(ctx, project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://paypal.me/shop", link)
+}
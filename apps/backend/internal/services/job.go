@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	domain "github.com/landly/backend/internal/models"
+)
+
+// JobQueue очередь фоновых задач, выполняемых воркером
+type JobQueue interface {
+	Enqueue(ctx context.Context, jobType string, payload interface{}, userID, projectID uuid.UUID) (*domain.Job, error)
+	Requeue(ctx context.Context, job *domain.Job) error
+}
+
+// JobService статус фоновых задач и повтор задач из dead letter
+type JobService struct {
+	jobRepo domain.JobRepository
+	queue   JobQueue
+}
+
+// NewJobService создаёт сервис фоновых задач
+func NewJobService(jobRepo domain.JobRepository, queue JobQueue) *JobService {
+	return &JobService{
+		jobRepo: jobRepo,
+		queue:   queue,
+	}
+}
+
+// GetJob возвращает задачу пользователя
+func (s *JobService) GetJob(ctx context.Context, userID, jobID string) (*domain.Job, error) {
+	job, err := s.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Чужие задачи неотличимы от несуществующих
+	if job.UserID == nil || job.UserID.String() != userID {
+		return nil, domain.ErrNotFound.WithMessage("job not found")
+	}
+
+	return job, nil
+}
+
+// RetryJob возвращает в очередь задачу, исчерпавшую попытки
+func (s *JobService) RetryJob(ctx context.Context, userID, jobID string) (*domain.Job, error) {
+	job, err := s.GetJob(ctx, userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status != domain.JobStatusDead {
+		return nil, domain.ErrBadRequest.WithMessage("only dead jobs can be retried")
+	}
+
+	if err := s.queue.Requeue(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/services/mocks"
+)
+
+func TestJobService_GetJob_HidesForeignJobs(t *testing.T) {
+	ctx := context.Background()
+	jobRepo := new(mocks.JobRepositoryMock)
+	svc := NewJobService(jobRepo, new(mocks.JobQueueMock))
+
+	owner := uuid.New()
+	job := &domain.Job{ID: uuid.New(), UserID: &owner, Status: domain.JobStatusRunning}
+	jobRepo.On("GetByID", ctx, job.ID.String()).Return(job, nil)
+
+	found, err := svc.GetJob(ctx, owner.String(), job.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, found.ID)
+
+	_, err = svc.GetJob(ctx, uuid.New().String(), job.ID.String())
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestJobService_RetryJob(t *testing.T) {
+	ctx := context.Background()
+	owner := uuid.New()
+
+	t.Run("dead job is requeued", func(t *testing.T) {
+		jobRepo := new(mocks.JobRepositoryMock)
+		queue := new(mocks.JobQueueMock)
+		svc := NewJobService(jobRepo, queue)
+
+		job := &domain.Job{ID: uuid.New(), UserID: &owner, Status: domain.JobStatusDead}
+		jobRepo.On("GetByID", ctx, job.ID.String()).Return(job, nil).Once()
+		queue.On("Requeue", ctx, job).Return(nil).Once()
+
+		_, err := svc.RetryJob(ctx, owner.String(), job.ID.String())
+		require.NoError(t, err)
+		queue.AssertExpectations(t)
+	})
+
+	t.Run("running job is rejected", func(t *testing.T) {
+		jobRepo := new(mocks.JobRepositoryMock)
+		queue := new(mocks.JobQueueMock)
+		svc := NewJobService(jobRepo, queue)
+
+		job := &domain.Job{ID: uuid.New(), UserID: &owner, Status: domain.JobStatusRunning}
+		jobRepo.On("GetByID", ctx, job.ID.String()).Return(job, nil).Once()
+
+		_, err := svc.RetryJob(ctx, owner.String(), job.ID.String())
+		assert.ErrorIs(t, err, domain.ErrBadRequest)
+		queue.AssertNotCalled(t, "Requeue", mock.Anything, mock.Anything)
+	})
+}
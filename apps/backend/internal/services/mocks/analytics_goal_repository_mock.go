@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type AnalyticsGoalRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *AnalyticsGoalRepositoryMock) Create(ctx context.Context, goal *domain.AnalyticsGoal) error {
+	args := m.Called(ctx, goal)
+	return args.Error(0)
+}
+
+func (m *AnalyticsGoalRepositoryMock) GetByID(ctx context.Context, id string) (*domain.AnalyticsGoal, error) {
+	args := m.Called(ctx, id)
+	if goal, ok := args.Get(0).(*domain.AnalyticsGoal); ok {
+		return goal, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AnalyticsGoalRepositoryMock) ListByProject(ctx context.Context, projectID uuid.UUID) ([]*domain.AnalyticsGoal, error) {
+	args := m.Called(ctx, projectID)
+	if goals, ok := args.Get(0).([]*domain.AnalyticsGoal); ok {
+		return goals, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AnalyticsGoalRepositoryMock) Update(ctx context.Context, goal *domain.AnalyticsGoal) error {
+	args := m.Called(ctx, goal)
+	return args.Error(0)
+}
+
+func (m *AnalyticsGoalRepositoryMock) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *AnalyticsGoalRepositoryMock) MarkReached(ctx context.Context, goalID uuid.UUID, visitorHash string, day time.Time) (bool, error) {
+	args := m.Called(ctx, goalID, visitorHash, day)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *AnalyticsGoalRepositoryMock) DeleteReachedBefore(ctx context.Context, day time.Time) error {
+	args := m.Called(ctx, day)
+	return args.Error(0)
+}
@@ -0,0 +1,105 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type AnalyticsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *AnalyticsRepositoryMock) TrackEvent(ctx context.Context, event *domain.AnalyticsEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *AnalyticsRepositoryMock) TrackEvents(ctx context.Context, events []*domain.AnalyticsEvent) error {
+	args := m.Called(ctx, events)
+	return args.Error(0)
+}
+
+func (m *AnalyticsRepositoryMock) GetStats(ctx context.Context, projectID uuid.UUID) (*domain.AnalyticsStats, error) {
+	args := m.Called(ctx, projectID)
+	if stats, ok := args.Get(0).(*domain.AnalyticsStats); ok {
+		return stats, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AnalyticsRepositoryMock) GetEvents(ctx context.Context, projectID uuid.UUID, limit, offset int) ([]*domain.AnalyticsEvent, error) {
+	args := m.Called(ctx, projectID, limit, offset)
+	if events, ok := args.Get(0).([]*domain.AnalyticsEvent); ok {
+		return events, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AnalyticsRepositoryMock) RollupEvents(ctx context.Context, granularity domain.AnalyticsGranularity, from, to time.Time) error {
+	args := m.Called(ctx, granularity, from, to)
+	return args.Error(0)
+}
+
+func (m *AnalyticsRepositoryMock) GetRollups(ctx context.Context, projectID uuid.UUID, granularity domain.AnalyticsGranularity, from, to time.Time) ([]*domain.AnalyticsRollup, error) {
+	args := m.Called(ctx, projectID, granularity, from, to)
+	if rollups, ok := args.Get(0).([]*domain.AnalyticsRollup); ok {
+		return rollups, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AnalyticsRepositoryMock) GetOrCreateSalt(ctx context.Context, day time.Time, candidate []byte) ([]byte, error) {
+	args := m.Called(ctx, day, candidate)
+	if salt, ok := args.Get(0).([]byte); ok {
+		return salt, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AnalyticsRepositoryMock) DeleteSaltsBefore(ctx context.Context, day time.Time) error {
+	args := m.Called(ctx, day)
+	return args.Error(0)
+}
+
+func (m *AnalyticsRepositoryMock) GetRollupWatermark(ctx context.Context, granularity domain.AnalyticsGranularity) (time.Time, error) {
+	args := m.Called(ctx, granularity)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *AnalyticsRepositoryMock) SetRollupWatermark(ctx context.Context, granularity domain.AnalyticsGranularity, to time.Time) error {
+	args := m.Called(ctx, granularity, to)
+	return args.Error(0)
+}
+
+func (m *AnalyticsRepositoryMock) DeleteEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *AnalyticsRepositoryMock) GetBreakdown(ctx context.Context, projectID uuid.UUID, dimension domain.AnalyticsDimension, from, to time.Time, limit int) ([]*domain.AnalyticsBreakdownItem, error) {
+	args := m.Called(ctx, projectID, dimension, from, to, limit)
+	if items, ok := args.Get(0).([]*domain.AnalyticsBreakdownItem); ok {
+		return items, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AnalyticsRepositoryMock) GetFunnel(ctx context.Context, projectID uuid.UUID, steps, goals []domain.AnalyticsFunnelStep, from, to time.Time) ([]int, []int, error) {
+	args := m.Called(ctx, projectID, steps, goals, from, to)
+	counts, _ := args.Get(0).([]int)
+	goalCounts, _ := args.Get(1).([]int)
+	return counts, goalCounts, args.Error(2)
+}
+
+func (m *AnalyticsRepositoryMock) GetVariantConversions(ctx context.Context, projectID uuid.UUID, goal domain.AnalyticsFunnelStep, from, to time.Time) ([]*domain.VariantConversion, error) {
+	args := m.Called(ctx, projectID, goal, from, to)
+	if conversions, ok := args.Get(0).([]*domain.VariantConversion); ok {
+		return conversions, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
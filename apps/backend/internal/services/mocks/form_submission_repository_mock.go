@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type FormSubmissionRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FormSubmissionRepositoryMock) Create(ctx context.Context, submission *domain.FormSubmission) error {
+	args := m.Called(ctx, submission)
+	return args.Error(0)
+}
+
+func (m *FormSubmissionRepositoryMock) ListByProject(ctx context.Context, projectID uuid.UUID, query domain.FormSubmissionsQuery) ([]*domain.FormSubmission, error) {
+	args := m.Called(ctx, projectID, query)
+	if submissions, ok := args.Get(0).([]*domain.FormSubmission); ok {
+		return submissions, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"strings"
 
 	"github.com/stretchr/testify/mock"
 
@@ -48,6 +49,11 @@ func (m *ProjectRepositoryMock) UpdateSchema(ctx context.Context, projectID stri
 	return args.Error(0)
 }
 
+func (m *ProjectRepositoryMock) UpdateStatus(ctx context.Context, projectID string, status string) error {
+	args := m.Called(ctx, projectID, status)
+	return args.Error(0)
+}
+
 type GenerationSessionRepositoryMock struct {
 	mock.Mock
 }
@@ -156,3 +162,23 @@ func (m *AIClientMock) GenerateLandingSchema(ctx context.Context, prompt, paymen
 	args := m.Called(ctx, prompt, paymentURL)
 	return args.String(0), args.Error(1)
 }
+
+func (m *AIClientMock) GenerateBlockProps(ctx context.Context, blockType, propsJSON, instruction string) (string, error) {
+	args := m.Called(ctx, blockType, propsJSON, instruction)
+	return args.String(0), args.Error(1)
+}
+
+// StreamingAIClientMock AI клиент с потоковой генерацией: StreamLandingSchema отдаёт
+// фрагменты из первого возвращаемого значения ([]string) и возвращает их конкатенацию
+type StreamingAIClientMock struct {
+	AIClientMock
+}
+
+func (m *StreamingAIClientMock) StreamLandingSchema(ctx context.Context, prompt, paymentURL string, onChunk func(chunk string)) (string, error) {
+	args := m.Called(ctx, prompt, paymentURL)
+	chunks, _ := args.Get(0).([]string)
+	for _, chunk := range chunks {
+		onChunk(chunk)
+	}
+	return strings.Join(chunks, ""), args.Error(1)
+}
@@ -0,0 +1,78 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type JobRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *JobRepositoryMock) Create(ctx context.Context, job *domain.Job) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *JobRepositoryMock) GetByID(ctx context.Context, id string) (*domain.Job, error) {
+	args := m.Called(ctx, id)
+	if job, ok := args.Get(0).(*domain.Job); ok {
+		return job, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *JobRepositoryMock) Update(ctx context.Context, job *domain.Job) (bool, error) {
+	args := m.Called(ctx, job)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *JobRepositoryMock) Requeue(ctx context.Context, job *domain.Job) (bool, error) {
+	args := m.Called(ctx, job)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *JobRepositoryMock) Claim(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *JobRepositoryMock) Touch(ctx context.Context, id uuid.UUID, attempt int) (bool, error) {
+	args := m.Called(ctx, id, attempt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *JobRepositoryMock) NextQueued(ctx context.Context, now time.Time) (uuid.UUID, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *JobRepositoryMock) ListStuck(ctx context.Context, before time.Time, limit int) ([]*domain.Job, error) {
+	args := m.Called(ctx, before, limit)
+	if jobs, ok := args.Get(0).([]*domain.Job); ok {
+		return jobs, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type JobQueueMock struct {
+	mock.Mock
+}
+
+func (m *JobQueueMock) Enqueue(ctx context.Context, jobType string, payload interface{}, userID, projectID uuid.UUID) (*domain.Job, error) {
+	args := m.Called(ctx, jobType, payload, userID, projectID)
+	if job, ok := args.Get(0).(*domain.Job); ok {
+		return job, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *JobQueueMock) Requeue(ctx context.Context, job *domain.Job) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type ProjectVariantRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *ProjectVariantRepositoryMock) Create(ctx context.Context, variant *domain.ProjectVariant) error {
+	args := m.Called(ctx, variant)
+	return args.Error(0)
+}
+
+func (m *ProjectVariantRepositoryMock) GetByID(ctx context.Context, id string) (*domain.ProjectVariant, error) {
+	args := m.Called(ctx, id)
+	if variant, ok := args.Get(0).(*domain.ProjectVariant); ok {
+		return variant, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProjectVariantRepositoryMock) ListByProject(ctx context.Context, projectID uuid.UUID) ([]*domain.ProjectVariant, error) {
+	args := m.Called(ctx, projectID)
+	if variants, ok := args.Get(0).([]*domain.ProjectVariant); ok {
+		return variants, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProjectVariantRepositoryMock) Update(ctx context.Context, variant *domain.ProjectVariant) error {
+	args := m.Called(ctx, variant)
+	return args.Error(0)
+}
+
+func (m *ProjectVariantRepositoryMock) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *ProjectVariantRepositoryMock) DeleteByProject(ctx context.Context, projectID uuid.UUID) error {
+	args := m.Called(ctx, projectID)
+	return args.Error(0)
+}
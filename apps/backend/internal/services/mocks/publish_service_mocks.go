@@ -0,0 +1,154 @@
+package mocks
+
+import (
+	"context"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type PublishTargetRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *PublishTargetRepositoryMock) Create(ctx context.Context, target *domain.PublishTarget) error {
+	args := m.Called(ctx, target)
+	return args.Error(0)
+}
+
+func (m *PublishTargetRepositoryMock) GetByID(ctx context.Context, id string) (*domain.PublishTarget, error) {
+	args := m.Called(ctx, id)
+	if target, ok := args.Get(0).(*domain.PublishTarget); ok {
+		return target, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PublishTargetRepositoryMock) GetByProjectID(ctx context.Context, projectID string) (*domain.PublishTarget, error) {
+	args := m.Called(ctx, projectID)
+	if target, ok := args.Get(0).(*domain.PublishTarget); ok {
+		return target, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PublishTargetRepositoryMock) GetBySubdomain(ctx context.Context, subdomain string) (*domain.PublishTarget, error) {
+	args := m.Called(ctx, subdomain)
+	if target, ok := args.Get(0).(*domain.PublishTarget); ok {
+		return target, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PublishTargetRepositoryMock) GetByCustomDomain(ctx context.Context, host string) (*domain.PublishTarget, error) {
+	args := m.Called(ctx, host)
+	if target, ok := args.Get(0).(*domain.PublishTarget); ok {
+		return target, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *PublishTargetRepositoryMock) Update(ctx context.Context, target *domain.PublishTarget) error {
+	args := m.Called(ctx, target)
+	return args.Error(0)
+}
+
+func (m *PublishTargetRepositoryMock) UpdateDomain(ctx context.Context, target *domain.PublishTarget) error {
+	args := m.Called(ctx, target)
+	return args.Error(0)
+}
+
+func (m *PublishTargetRepositoryMock) ClaimVerifiedDomain(ctx context.Context, target *domain.PublishTarget) error {
+	args := m.Called(ctx, target)
+	return args.Error(0)
+}
+
+func (m *PublishTargetRepositoryMock) ListVerifiedDomains(ctx context.Context) ([]*domain.PublishTarget, error) {
+	args := m.Called(ctx)
+	targets, _ := args.Get(0).([]*domain.PublishTarget)
+	return targets, args.Error(1)
+}
+
+func (m *PublishTargetRepositoryMock) SetSSLStatus(ctx context.Context, host, status string) error {
+	args := m.Called(ctx, host, status)
+	return args.Error(0)
+}
+
+func (m *PublishTargetRepositoryMock) SetActiveDeployment(ctx context.Context, targetID, deploymentID uuid.UUID) error {
+	args := m.Called(ctx, targetID, deploymentID)
+	return args.Error(0)
+}
+
+func (m *PublishTargetRepositoryMock) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type DeploymentRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *DeploymentRepositoryMock) Create(ctx context.Context, deployment *domain.Deployment) error {
+	args := m.Called(ctx, deployment)
+	return args.Error(0)
+}
+
+func (m *DeploymentRepositoryMock) GetByID(ctx context.Context, id string) (*domain.Deployment, error) {
+	args := m.Called(ctx, id)
+	if deployment, ok := args.Get(0).(*domain.Deployment); ok {
+		return deployment, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *DeploymentRepositoryMock) ListByProject(ctx context.Context, projectID string, limit, offset int) ([]*domain.Deployment, error) {
+	args := m.Called(ctx, projectID, limit, offset)
+	if deployments, ok := args.Get(0).([]*domain.Deployment); ok {
+		return deployments, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *DeploymentRepositoryMock) Update(ctx context.Context, deployment *domain.Deployment) error {
+	args := m.Called(ctx, deployment)
+	return args.Error(0)
+}
+
+type RendererMock struct {
+	mock.Mock
+}
+
+func (m *RendererMock) RenderStatic(ctx context.Context, projectID uuid.UUID, schemaJSON, siteURL string) (string, error) {
+	args := m.Called(ctx, projectID, schemaJSON, siteURL)
+	return args.String(0), args.Error(1)
+}
+
+func (m *RendererMock) RenderVariant(ctx context.Context, projectID uuid.UUID, variant, schemaJSON, siteURL string) (string, error) {
+	args := m.Called(ctx, projectID, variant, schemaJSON, siteURL)
+	return args.String(0), args.Error(1)
+}
+
+type PublisherMock struct {
+	mock.Mock
+}
+
+func (m *PublisherMock) Upload(ctx context.Context, localPath, remotePath string) error {
+	args := m.Called(ctx, localPath, remotePath)
+	return args.Error(0)
+}
+
+func (m *PublisherMock) GetPublicURL(remotePath string) string {
+	args := m.Called(remotePath)
+	return args.String(0)
+}
+
+func (m *PublisherMock) GetObject(ctx context.Context, remotePath string) (io.ReadCloser, string, error) {
+	args := m.Called(ctx, remotePath)
+	if reader, ok := args.Get(0).(io.ReadCloser); ok {
+		return reader, args.String(1), args.Error(2)
+	}
+	return nil, args.String(1), args.Error(2)
+}
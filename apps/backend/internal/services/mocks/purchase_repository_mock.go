@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type PurchaseRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *PurchaseRepositoryMock) Create(ctx context.Context, purchase *domain.Purchase) error {
+	args := m.Called(ctx, purchase)
+	return args.Error(0)
+}
+
+func (m *PurchaseRepositoryMock) ListByProject(ctx context.Context, projectID uuid.UUID, limit int) ([]*domain.Purchase, error) {
+	args := m.Called(ctx, projectID, limit)
+	if purchases, ok := args.Get(0).([]*domain.Purchase); ok {
+		return purchases, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type SchemaRevisionRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *SchemaRevisionRepositoryMock) Create(ctx context.Context, revision *domain.SchemaRevision) error {
+	args := m.Called(ctx, revision)
+	return args.Error(0)
+}
+
+func (m *SchemaRevisionRepositoryMock) GetByID(ctx context.Context, id string) (*domain.SchemaRevision, error) {
+	args := m.Called(ctx, id)
+	if revision, ok := args.Get(0).(*domain.SchemaRevision); ok {
+		return revision, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *SchemaRevisionRepositoryMock) GetPrevious(ctx context.Context, projectID string, version int) (*domain.SchemaRevision, error) {
+	args := m.Called(ctx, projectID, version)
+	if revision, ok := args.Get(0).(*domain.SchemaRevision); ok {
+		return revision, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *SchemaRevisionRepositoryMock) ListByProject(ctx context.Context, projectID string, limit, offset int) ([]*domain.SchemaRevision, error) {
+	args := m.Called(ctx, projectID, limit, offset)
+	if revisions, ok := args.Get(0).([]*domain.SchemaRevision); ok {
+		return revisions, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,81 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	domain "github.com/landly/backend/internal/models"
+)
+
+type WebhookRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *WebhookRepositoryMock) Create(ctx context.Context, webhook *domain.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (m *WebhookRepositoryMock) GetByID(ctx context.Context, id string) (*domain.Webhook, error) {
+	args := m.Called(ctx, id)
+	if webhook, ok := args.Get(0).(*domain.Webhook); ok {
+		return webhook, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookRepositoryMock) ListByProject(ctx context.Context, projectID uuid.UUID) ([]*domain.Webhook, error) {
+	args := m.Called(ctx, projectID)
+	if webhooks, ok := args.Get(0).([]*domain.Webhook); ok {
+		return webhooks, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookRepositoryMock) Update(ctx context.Context, webhook *domain.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (m *WebhookRepositoryMock) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type WebhookDeliveryRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *WebhookDeliveryRepositoryMock) Create(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *WebhookDeliveryRepositoryMock) ListByWebhook(ctx context.Context, webhookID uuid.UUID, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookID, limit, offset)
+	if deliveries, ok := args.Get(0).([]*domain.WebhookDelivery); ok {
+		return deliveries, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *WebhookDeliveryRepositoryMock) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type EventEmitterMock struct {
+	mock.Mock
+}
+
+func (m *EventEmitterMock) Emit(ctx context.Context, projectID uuid.UUID, eventType string, data interface{}) {
+	m.Called(ctx, projectID, eventType, data)
+}
+
+func (m *EventEmitterMock) Subscribed(ctx context.Context, projectID uuid.UUID, eventType string) bool {
+	args := m.Called(ctx, projectID, eventType)
+	return args.Bool(0)
+}
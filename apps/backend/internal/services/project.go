@@ -126,3 +126,17 @@ func (s *ProjectService) DeleteProject(ctx context.Context, userID, projectID st
 
 	return nil
 }
+
+// ensureProjectOwnership загружает проект и проверяет, что он принадлежит пользователю
+func ensureProjectOwnership(ctx context.Context, projectRepo domain.ProjectRepository, userID, projectID string) (*domain.Project, error) {
+	project, err := projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, domain.ErrNotFound.WithMessage("project not found")
+	}
+
+	if project.UserID.String() != userID {
+		return nil, domain.ErrForbidden.WithMessage("access denied")
+	}
+
+	return project, nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// projectCacheTTL через сколько изменения, сделанные через другой инстанс API, попадают в кэш
+	projectCacheTTL = time.Minute
+	// maxProjectCacheEntries предел записей: публичный трекинг принимает любые ID проектов
+	maxProjectCacheEntries = 10000
+)
+
+// projectCache данные проекта, которые нужны на каждом событии публичного трекинга.
+// Изменения через этот инстанс сбрасывают запись сразу, через другие — по истечении projectCacheTTL.
+type projectCache[V any] struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID]projectCacheEntry[V]
+}
+
+type projectCacheEntry[V any] struct {
+	value    V
+	loadedAt time.Time
+}
+
+func (c *projectCache[V]) get(projectID uuid.UUID, now time.Time) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[projectID]
+	if !ok || now.Sub(entry.loadedAt) >= projectCacheTTL {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func (c *projectCache[V]) set(projectID uuid.UUID, value V, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[uuid.UUID]projectCacheEntry[V])
+	}
+	if len(c.entries) >= maxProjectCacheEntries {
+		for id, entry := range c.entries {
+			if now.Sub(entry.loadedAt) >= projectCacheTTL {
+				delete(c.entries, id)
+			}
+		}
+		if len(c.entries) >= maxProjectCacheEntries {
+			c.entries = make(map[uuid.UUID]projectCacheEntry[V])
+		}
+	}
+	c.entries[projectID] = projectCacheEntry[V]{value: value, loadedAt: now}
+}
+
+func (c *projectCache[V]) forget(projectID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, projectID)
+}
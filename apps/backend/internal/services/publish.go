@@ -2,9 +2,12 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
+	"net/url"
 	"path/filepath"
 	"strings"
 	"time"
@@ -17,7 +20,8 @@ import (
 
 // Renderer интерфейс для рендеринга статических сайтов
 type Renderer interface {
-	RenderStatic(ctx context.Context, projectID uuid.UUID, schemaJSON string) (string, error)
+	RenderStatic(ctx context.Context, projectID uuid.UUID, schemaJSON, siteURL string) (string, error)
+	RenderVariant(ctx context.Context, projectID uuid.UUID, variant, schemaJSON, siteURL string) (string, error)
 }
 
 // Publisher интерфейс для публикации в S3/CDN
@@ -32,27 +36,41 @@ type PublishUserRepository interface {
 	GetByID(ctx context.Context, userID string) (*domain.User, error)
 }
 
+const (
+	defaultDeploymentsLimit = 50
+	maxDeploymentsLimit     = 200
+)
+
 // PublishService сервис для публикации проектов
 type PublishService struct {
 	projectRepo       domain.ProjectRepository
 	publishTargetRepo domain.PublishTargetRepository
+	deploymentRepo    domain.DeploymentRepository
 	userRepo          PublishUserRepository
 	renderer          Renderer
 	publisher         Publisher
 	publicBase        string
+	jobQueue          JobQueue
+	variantRepo       domain.ProjectVariantRepository
+	events            EventEmitter
+	// randIntN выбор варианта для нового посетителя, [0, n)
+	randIntN func(n int) int
 }
 
 // PublishResult результат публикации
 type PublishResult struct {
-	Subdomain   string `json:"subdomain"`
-	PublicURL   string `json:"public_url"`
-	PublishedAt string `json:"published_at"`
+	Subdomain    string    `json:"subdomain"`
+	PublicURL    string    `json:"public_url"`
+	PublishedAt  string    `json:"published_at"`
+	DeploymentID uuid.UUID `json:"deployment_id"`
+	Version      int       `json:"version"`
 }
 
 // NewPublishService создаёт новый publish service
 func NewPublishService(
 	projectRepo domain.ProjectRepository,
 	publishTargetRepo domain.PublishTargetRepository,
+	deploymentRepo domain.DeploymentRepository,
 	userRepo PublishUserRepository,
 	renderer Renderer,
 	publisher Publisher,
@@ -61,13 +79,35 @@ func NewPublishService(
 	return &PublishService{
 		projectRepo:       projectRepo,
 		publishTargetRepo: publishTargetRepo,
+		deploymentRepo:    deploymentRepo,
 		userRepo:          userRepo,
 		renderer:          renderer,
 		publisher:         publisher,
 		publicBase:        strings.TrimRight(publicBase, "/"),
+		randIntN:          rand.IntN,
 	}
 }
 
+// SetJobQueue переносит публикацию из горутины API в фоновую задачу воркера
+func (s *PublishService) SetJobQueue(queue JobQueue) {
+	s.jobQueue = queue
+}
+
+// SetVariantRepository включает A/B-тесты: варианты проекта публикуются вместе с основной схемой
+func (s *PublishService) SetVariantRepository(repo domain.ProjectVariantRepository) {
+	s.variantRepo = repo
+}
+
+// SetEventEmitter отправляет событие site.published подписчикам вебхуков проекта
+func (s *PublishService) SetEventEmitter(events EventEmitter) {
+	s.events = events
+}
+
+// publishJobPayload данные задачи публикации
+type publishJobPayload struct {
+	TargetID uuid.UUID `json:"target_id"`
+}
+
 func generateSubdomain(projectName string, projectID uuid.UUID) string {
 	prefix := strings.ToLower(strings.TrimSpace(projectName))
 	if prefix == "" {
@@ -104,6 +144,16 @@ func (s *PublishService) publicBaseURL() string {
 	return "http://localhost:8080"
 }
 
+// customDomainURL адрес сайта на подтверждённом собственном домене — канонический URL страниц.
+// Схема берётся из адреса платформы, чтобы локально сайт открывался по http.
+func (s *PublishService) customDomainURL(host string) string {
+	scheme := "https"
+	if base, err := url.Parse(s.publicBaseURL()); err == nil && base.Scheme == "http" {
+		scheme = "http"
+	}
+	return scheme + "://" + host
+}
+
 // PublishSite публикует сайт (новый интерфейс)
 func (s *PublishService) PublishSite(ctx context.Context, userID, projectID string, req *domain.PublishRequest) (*domain.PublishTarget, error) {
 	userUUID, err := uuid.Parse(userID)
@@ -147,7 +197,16 @@ func (s *PublishService) PublishSite(ctx context.Context, userID, projectID stri
 		}
 	}
 
-	// Публикуем в фоне
+	if s.jobQueue != nil {
+		job, err := s.jobQueue.Enqueue(ctx, domain.JobTypePublish, publishJobPayload{TargetID: target.ID}, userUUID, projectUUID)
+		if err != nil {
+			return nil, err
+		}
+		target.JobID = &job.ID
+		return target, nil
+	}
+
+	// Без очереди задач публикуем в фоне
 	go func() {
 		ctxWithLogger := logger.WithContext(context.Background()).With(
 			zap.String("project_id", projectUUID.String()),
@@ -220,6 +279,7 @@ func (s *PublishService) PublishProject(ctx context.Context, userID, projectID u
 	if err == nil && existingTarget != nil {
 		// Обновляем существующую цель
 		target.ID = existingTarget.ID
+		target.ActiveDeploymentID = existingTarget.ActiveDeploymentID
 		target.CreatedAt = existingTarget.CreatedAt
 		if err := s.publishTargetRepo.Update(ctx, target); err != nil {
 			return nil, domain.ErrInternal.WithError(err)
@@ -231,34 +291,210 @@ func (s *PublishService) PublishProject(ctx context.Context, userID, projectID u
 		}
 	}
 
-	// Рендерим статический сайт
-	buildDir, err := s.renderer.RenderStatic(ctx, projectID, project.SchemaJSON)
+	publicURL := fmt.Sprintf("%s/sites/%s", s.publicBaseURL(), subdomain)
+	if existingTarget != nil && existingTarget.DomainVerified() {
+		publicURL = s.customDomainURL(existingTarget.CustomDomain)
+	}
+
+	variants, err := s.experimentVariants(ctx, projectID)
 	if err != nil {
-		return nil, domain.ErrInternal.WithMessage("failed to render static site")
+		return nil, err
+	}
+
+	// Каждая публикация получает свою версию; посетители видят предыдущую, пока новая не загружена целиком
+	deployment := domain.NewDeployment(projectID, subdomain, &userID)
+	deployment.Variants = deploymentVariants(variants)
+	deployment.SchemaJSON = project.SchemaJSON
+	if err := s.deploymentRepo.Create(ctx, deployment); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
 	}
 
-	// Загружаем файлы в S3/CDN
-	remotePath := fmt.Sprintf("sites/%s", subdomain)
-	if err := s.publisher.Upload(ctx, buildDir, remotePath); err != nil {
-		return nil, domain.ErrInternal.WithMessage("failed to upload to storage")
+	if err := s.uploadBuilds(ctx, deployment, project, variants, publicURL); err != nil {
+		s.finishDeployment(ctx, deployment, domain.DeploymentStatusFailed)
+		return nil, err
+	}
+
+	if err := s.finishDeployment(ctx, deployment, domain.DeploymentStatusSucceeded); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+	if err := s.publishTargetRepo.SetActiveDeployment(ctx, target.ID, deployment.ID); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
 	}
 
 	now := time.Now()
-	project.Status = domain.ProjectStatusPublished
-	project.UpdatedAt = now
-	if err := s.projectRepo.Update(ctx, project); err != nil {
+	// Только статус: схема могла измениться, пока шла публикация
+	if err := s.projectRepo.UpdateStatus(ctx, projectID.String(), domain.ProjectStatusPublished); err != nil {
 		return nil, domain.ErrInternal.WithError(err)
 	}
 
-	publicURL := fmt.Sprintf("%s/sites/%s", s.publicBaseURL(), subdomain)
+	if s.events != nil {
+		s.events.Emit(ctx, projectID, domain.WebhookEventSitePublished, sitePublishedData{
+			DeploymentID: deployment.ID,
+			Version:      deployment.Version,
+			URL:          publicURL,
+			Subdomain:    subdomain,
+		})
+	}
 
 	return &PublishResult{
-		Subdomain:   subdomain,
-		PublicURL:   publicURL,
-		PublishedAt: now.Format(time.RFC3339),
+		Subdomain:    subdomain,
+		PublicURL:    publicURL,
+		PublishedAt:  now.Format(time.RFC3339),
+		DeploymentID: deployment.ID,
+		Version:      deployment.Version,
 	}, nil
 }
 
+// experimentVariants варианты проекта, которым назначен трафик
+func (s *PublishService) experimentVariants(ctx context.Context, projectID uuid.UUID) ([]*domain.ProjectVariant, error) {
+	if s.variantRepo == nil {
+		return nil, nil
+	}
+
+	all, err := s.variantRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var variants []*domain.ProjectVariant
+	for _, variant := range all {
+		if variant.Weight > 0 {
+			variants = append(variants, variant)
+		}
+	}
+	return variants, nil
+}
+
+// deploymentVariants снимок эксперимента для деплоя: контроль получает трафик, не распределённый по вариантам
+func deploymentVariants(variants []*domain.ProjectVariant) []domain.DeploymentVariant {
+	if len(variants) == 0 {
+		return nil
+	}
+
+	controlWeight := 100
+	snapshot := make([]domain.DeploymentVariant, 0, len(variants)+1)
+	for _, variant := range variants {
+		controlWeight -= variant.Weight
+		snapshot = append(snapshot, domain.DeploymentVariant{Key: variant.Key(), Weight: variant.Weight, SchemaJSON: variant.SchemaJSON})
+	}
+	if controlWeight > 0 {
+		snapshot = append([]domain.DeploymentVariant{{Key: domain.VariantControl, Weight: controlWeight}}, snapshot...)
+	}
+	return snapshot
+}
+
+// uploadBuilds рендерит сайт и загружает его в префикс деплоя.
+// При эксперименте каждый вариант, включая контроль, собирается в свой подкаталог variants/<key>.
+func (s *PublishService) uploadBuilds(ctx context.Context, deployment *domain.Deployment, project *domain.Project, variants []*domain.ProjectVariant, publicURL string) error {
+	if len(deployment.Variants) == 0 {
+		buildDir, err := s.renderer.RenderStatic(ctx, project.ID, project.SchemaJSON, publicURL)
+		if err != nil {
+			return domain.ErrInternal.WithMessage("failed to render static site")
+		}
+		// Загружаем файлы в неизменяемый префикс версии
+		if err := s.publisher.Upload(ctx, buildDir, deployment.StoragePrefix()); err != nil {
+			return domain.ErrInternal.WithMessage("failed to upload to storage")
+		}
+		return nil
+	}
+
+	schemas := map[string]string{domain.VariantControl: project.SchemaJSON}
+	for _, variant := range variants {
+		schemas[variant.Key()] = variant.SchemaJSON
+	}
+
+	for _, variant := range deployment.Variants {
+		buildDir, err := s.renderer.RenderVariant(ctx, project.ID, variant.Key, schemas[variant.Key], publicURL)
+		if err != nil {
+			return domain.ErrInternal.WithMessage("failed to render static site")
+		}
+		if err := s.publisher.Upload(ctx, buildDir, deployment.VariantStoragePrefix(variant.Key)); err != nil {
+			return domain.ErrInternal.WithMessage("failed to upload to storage")
+		}
+	}
+	return nil
+}
+
+// finishDeployment фиксирует итоговый статус деплоя
+func (s *PublishService) finishDeployment(ctx context.Context, deployment *domain.Deployment, status string) error {
+	now := time.Now()
+	deployment.Status = status
+	deployment.FinishedAt = &now
+
+	if err := s.deploymentRepo.Update(ctx, deployment); err != nil {
+		logger.WithContext(ctx).Error("failed to update deployment",
+			zap.String("deployment_id", deployment.ID.String()),
+			zap.String("status", status),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// ListDeployments возвращает деплои проекта, начиная с последнего, и ID активного деплоя
+func (s *PublishService) ListDeployments(ctx context.Context, userID, projectID string, limit, offset int) ([]*domain.Deployment, *uuid.UUID, error) {
+	if _, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID); err != nil {
+		return nil, nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultDeploymentsLimit
+	}
+	if limit > maxDeploymentsLimit {
+		limit = maxDeploymentsLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	deployments, err := s.deploymentRepo.ListByProject(ctx, projectID, limit, offset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var activeID *uuid.UUID
+	target, err := s.publishTargetRepo.GetByProjectID(ctx, projectID)
+	switch {
+	case err == nil:
+		activeID = target.ActiveDeploymentID
+	case !errors.Is(err, domain.ErrNotFound):
+		return nil, nil, err
+	}
+
+	return deployments, activeID, nil
+}
+
+// RollbackDeployment переключает сайт на ранее загруженный деплой
+func (s *PublishService) RollbackDeployment(ctx context.Context, userID, projectID, deploymentID string) (*domain.Deployment, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := s.deploymentRepo.GetByID(ctx, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	if deployment.ProjectID != project.ID {
+		return nil, domain.ErrNotFound.WithMessage("deployment not found")
+	}
+	if deployment.Status != domain.DeploymentStatusSucceeded {
+		return nil, domain.ErrBadRequest.WithMessage("only successful deployments can be activated")
+	}
+
+	target, err := s.publishTargetRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, domain.ErrNotFound.WithMessage("publish target not found")
+	}
+
+	if err := s.publishTargetRepo.SetActiveDeployment(ctx, target.ID, deployment.ID); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return deployment, nil
+}
+
 // UnpublishProject снимает проект с публикации
 func (s *PublishService) UnpublishProject(ctx context.Context, userID, projectID uuid.UUID) error {
 	project, err := s.projectRepo.GetByID(ctx, projectID.String())
@@ -283,31 +519,95 @@ func (s *PublishService) UnpublishProject(ctx context.Context, userID, projectID
 		return domain.ErrInternal.WithError(err)
 	}
 
-	project.Status = domain.ProjectStatusGenerated
-	project.UpdatedAt = now
-	if err := s.projectRepo.Update(ctx, project); err != nil {
+	if err := s.projectRepo.UpdateStatus(ctx, projectID.String(), domain.ProjectStatusGenerated); err != nil {
 		return domain.ErrInternal.WithError(err)
 	}
 
 	return nil
 }
 
-// ServePublished возвращает содержимое опубликованного проекта для указанного ресурса
-func (s *PublishService) ServePublished(ctx context.Context, subdomain, assetPath string) (io.ReadCloser, string, error) {
+// ServePublished возвращает содержимое опубликованного проекта для указанного ресурса.
+// variant — вариант A/B-теста из cookie посетителя: он сохраняется, пока есть в активном деплое,
+// иначе посетителю выбирается новый вариант по весам.
+func (s *PublishService) ServePublished(ctx context.Context, subdomain, assetPath, variant string) (*domain.PublishedAsset, error) {
 	cleanPath := strings.TrimPrefix(assetPath, "/")
 	if cleanPath == "" {
 		cleanPath = "index.html"
 	}
 	cleanPath = filepath.Clean(cleanPath)
 	if strings.Contains(cleanPath, "..") {
-		return nil, "", domain.ErrForbidden
+		return nil, domain.ErrForbidden
+	}
+	// Страницы многостраничного сайта лежат в <path>/index.html
+	if filepath.Ext(cleanPath) == "" {
+		cleanPath = filepath.Join(cleanPath, "index.html")
 	}
 
 	target, targetErr := s.publishTargetRepo.GetBySubdomain(ctx, subdomain)
 	if targetErr != nil && !errors.Is(targetErr, domain.ErrNotFound) {
-		return nil, "", targetErr
+		return nil, targetErr
 	}
 
+	var searchBases []string
+	servedVariant := ""
+	if targetErr == nil && target != nil && target.ActiveDeploymentID != nil {
+		// Активный деплой отдаётся целиком из своего префикса, без подмешивания файлов других версий
+		deployment, err := s.deploymentRepo.GetByID(ctx, target.ActiveDeploymentID.String())
+		if err != nil {
+			return nil, err
+		}
+		if len(deployment.Variants) > 0 {
+			servedVariant = s.assignVariant(deployment.Variants, variant)
+			searchBases = append(searchBases, deployment.VariantStoragePrefix(servedVariant))
+		} else {
+			searchBases = append(searchBases, deployment.StoragePrefix())
+		}
+	} else {
+		searchBases = legacySearchBases(subdomain, target)
+	}
+
+	for _, basePath := range searchBases {
+		remotePath := filepath.Join(basePath, cleanPath)
+
+		reader, contentType, err := s.publisher.GetObject(ctx, remotePath)
+		if err == nil {
+			return &domain.PublishedAsset{Body: reader, ContentType: contentType, Variant: servedVariant}, nil
+		}
+
+		if !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	return nil, domain.ErrNotFound
+}
+
+// assignVariant оставляет посетителю вариант из cookie, если он есть в эксперименте,
+// иначе выбирает вариант случайно пропорционально весам
+func (s *PublishService) assignVariant(variants []domain.DeploymentVariant, current string) string {
+	total := 0
+	for _, variant := range variants {
+		if variant.Key == current {
+			return current
+		}
+		total += variant.Weight
+	}
+	if total <= 0 {
+		return variants[0].Key
+	}
+
+	n := s.randIntN(total)
+	for _, variant := range variants {
+		if n < variant.Weight {
+			return variant.Key
+		}
+		n -= variant.Weight
+	}
+	return variants[len(variants)-1].Key
+}
+
+// legacySearchBases пути сайтов, опубликованных до появления деплоев
+func legacySearchBases(subdomain string, target *domain.PublishTarget) []string {
 	seen := make(map[string]struct{})
 	var searchBases []string
 	addBase := func(base string) {
@@ -320,30 +620,45 @@ func (s *PublishService) ServePublished(ctx context.Context, subdomain, assetPat
 
 	addBase(fmt.Sprintf("sites/%s", subdomain))
 
-	if targetErr == nil && target != nil {
+	if target != nil {
 		if !strings.EqualFold(target.Subdomain, subdomain) {
 			addBase(fmt.Sprintf("sites/%s", target.Subdomain))
 		}
 		addBase(fmt.Sprintf("sites/%s", target.ProjectID.String()))
 	}
 
-	for _, basePath := range searchBases {
-		remotePath := filepath.Join(basePath, cleanPath)
+	return searchBases
+}
 
-		reader, contentType, err := s.publisher.GetObject(ctx, remotePath)
-		if err == nil {
-			return reader, contentType, nil
-		}
+// HandlePublishJob выполняет задачу публикации в воркере
+func (s *PublishService) HandlePublishJob(ctx context.Context, job *domain.Job) error {
+	var payload publishJobPayload
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		return domain.ErrBadRequest.WithMessage("invalid publish job payload")
+	}
+	if job.UserID == nil || job.ProjectID == nil {
+		return domain.ErrBadRequest.WithMessage("publish job without user or project")
+	}
 
-		if !errors.Is(err, domain.ErrNotFound) {
-			return nil, "", err
-		}
+	target, err := s.publishTargetRepo.GetByID(ctx, payload.TargetID.String())
+	if err != nil {
+		return err
 	}
 
-	return nil, "", domain.ErrNotFound
+	log := logger.WithContext(ctx).With(
+		zap.String("project_id", job.ProjectID.String()),
+		zap.String("user_id", job.UserID.String()),
+		zap.String("job_id", job.ID.String()),
+	)
+	return s.runPublish(ctx, target, *job.UserID, *job.ProjectID, log)
 }
 
 func (s *PublishService) publishInBackground(ctx context.Context, target *domain.PublishTarget, userID, projectID uuid.UUID, log logger.Logger) {
+	_ = s.runPublish(ctx, target, userID, projectID, log)
+}
+
+// runPublish публикует проект и записывает итог в цель публикации
+func (s *PublishService) runPublish(ctx context.Context, target *domain.PublishTarget, userID, projectID uuid.UUID, log logger.Logger) error {
 	log.Info("starting background publication")
 
 	result, err := s.PublishProject(ctx, userID, projectID)
@@ -364,4 +679,6 @@ func (s *PublishService) publishInBackground(ctx context.Context, target *domain
 	if err := s.publishTargetRepo.Update(context.Background(), target); err != nil {
 		log.Error("failed to update publish target", zap.Error(err))
 	}
+
+	return err
 }
@@ -0,0 +1,48 @@
+//go:build integration
+// +build integration
+
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/repositories"
+	testhelpers "github.com/landly/backend/internal/testing"
+)
+
+func TestDeploymentRepository_Integration_ConcurrentPublishes(t *testing.T) {
+	qb := testhelpers.SetupTestDB(t)
+	deploymentRepo := repositories.NewDeploymentRepository(qb)
+
+	user, _ := testhelpers.CreateTestUser(t, qb, "", "")
+	project := testhelpers.CreateTestProject(t, qb, user.ID, "Publish Project", "SaaS")
+
+	const publishes = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, publishes)
+	for i := 0; i < publishes; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- deploymentRepo.Create(context.Background(), domain.NewDeployment(project.ID, "publish-project", &user.ID))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	deployments, err := deploymentRepo.ListByProject(context.Background(), project.ID.String(), publishes, 0)
+	require.NoError(t, err)
+	require.Len(t, deployments, publishes)
+	for i, deployment := range deployments {
+		assert.Equal(t, publishes-i, deployment.Version)
+	}
+}
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/services/mocks"
+)
+
+func TestPublishService_PublishProject_UploadsVersionAndActivatesIt(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	deploymentRepo := new(mocks.DeploymentRepositoryMock)
+	renderer := new(mocks.RendererMock)
+	publisher := new(mocks.PublisherMock)
+	svc := NewPublishService(projectRepo, targetRepo, deploymentRepo, nil, renderer, publisher, "https://landly.test")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop", SchemaJSON: `{"pages":[]}`}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	subdomain := generateSubdomain(project.Name, project.ID)
+
+	previous := uuid.New()
+	target := &domain.PublishTarget{ID: uuid.New(), ProjectID: project.ID, Subdomain: subdomain, ActiveDeploymentID: &previous}
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(target, nil).Once()
+	targetRepo.On("Update", ctx, mock.MatchedBy(func(updated *domain.PublishTarget) bool {
+		return updated.ID == target.ID && updated.ActiveDeploymentID == &previous
+	})).Return(nil).Once()
+	deploymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Deployment")).
+		Run(func(args mock.Arguments) { args.Get(1).(*domain.Deployment).Version = 3 }).
+		Return(nil).Once()
+	renderer.On("RenderStatic", ctx, project.ID, project.SchemaJSON, "https://landly.test/sites/"+subdomain).Return("/tmp/build", nil).Once()
+	publisher.On("Upload", ctx, "/tmp/build", "sites/"+subdomain+"/v3").Return(nil).Once()
+	deploymentRepo.On("Update", ctx, mock.MatchedBy(func(deployment *domain.Deployment) bool {
+		return deployment.Status == domain.DeploymentStatusSucceeded && deployment.FinishedAt != nil
+	})).Return(nil).Once()
+	targetRepo.On("SetActiveDeployment", ctx, target.ID, mock.AnythingOfType("uuid.UUID")).Return(nil).Once()
+	projectRepo.On("UpdateStatus", ctx, project.ID.String(), domain.ProjectStatusPublished).Return(nil).Once()
+
+	result, err := svc.PublishProject(ctx, project.UserID, project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Version)
+	assert.NotEqual(t, uuid.Nil, result.DeploymentID)
+
+	targetRepo.AssertCalled(t, "SetActiveDeployment", ctx, target.ID, result.DeploymentID)
+	projectRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	projectRepo.AssertExpectations(t)
+	targetRepo.AssertExpectations(t)
+	deploymentRepo.AssertExpectations(t)
+	publisher.AssertExpectations(t)
+}
+
+func TestPublishService_PublishProject_UsesVerifiedCustomDomainAsSiteURL(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	deploymentRepo := new(mocks.DeploymentRepositoryMock)
+	renderer := new(mocks.RendererMock)
+	publisher := new(mocks.PublisherMock)
+	svc := NewPublishService(projectRepo, targetRepo, deploymentRepo, nil, renderer, publisher, "https://landly.test")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop", SchemaJSON: `{"pages":[]}`}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	subdomain := generateSubdomain(project.Name, project.ID)
+
+	verifiedAt := time.Now()
+	target := &domain.PublishTarget{ID: uuid.New(), ProjectID: project.ID, Subdomain: subdomain, CustomDomain: "shop.example.com", DomainVerifiedAt: &verifiedAt}
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(target, nil).Once()
+	targetRepo.On("Update", ctx, mock.Anything).Return(nil).Once()
+	deploymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Deployment")).Return(nil).Once()
+	renderer.On("RenderStatic", ctx, project.ID, project.SchemaJSON, "https://shop.example.com").Return("/tmp/build", nil).Once()
+	publisher.On("Upload", ctx, "/tmp/build", mock.Anything).Return(nil).Once()
+	deploymentRepo.On("Update", ctx, mock.Anything).Return(nil).Once()
+	targetRepo.On("SetActiveDeployment", ctx, target.ID, mock.AnythingOfType("uuid.UUID")).Return(nil).Once()
+	projectRepo.On("UpdateStatus", ctx, project.ID.String(), domain.ProjectStatusPublished).Return(nil).Once()
+
+	result, err := svc.PublishProject(ctx, project.UserID, project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://shop.example.com", result.PublicURL)
+	renderer.AssertExpectations(t)
+}
+
+func TestPublishService_PublishProject_FailedUploadKeepsActiveDeployment(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	deploymentRepo := new(mocks.DeploymentRepositoryMock)
+	renderer := new(mocks.RendererMock)
+	publisher := new(mocks.PublisherMock)
+	svc := NewPublishService(projectRepo, targetRepo, deploymentRepo, nil, renderer, publisher, "https://landly.test")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop", SchemaJSON: `{"pages":[]}`}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(nil, domain.ErrNotFound).Once()
+	targetRepo.On("Create", ctx, mock.AnythingOfType("*domain.PublishTarget")).Return(nil).Once()
+	deploymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Deployment")).Return(nil).Once()
+	renderer.On("RenderStatic", ctx, project.ID, project.SchemaJSON, mock.Anything).Return("/tmp/build", nil).Once()
+	publisher.On("Upload", ctx, "/tmp/build", mock.Anything).Return(errors.New("connection reset")).Once()
+	deploymentRepo.On("Update", ctx, mock.MatchedBy(func(deployment *domain.Deployment) bool {
+		return deployment.Status == domain.DeploymentStatusFailed
+	})).Return(nil).Once()
+
+	_, err := svc.PublishProject(ctx, project.UserID, project.ID)
+	require.Error(t, err)
+
+	targetRepo.AssertNotCalled(t, "SetActiveDeployment", mock.Anything, mock.Anything, mock.Anything)
+	deploymentRepo.AssertExpectations(t)
+}
+
+func TestPublishService_ServePublished_ResolvesActiveDeployment(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	deploymentRepo := new(mocks.DeploymentRepositoryMock)
+	publisher := new(mocks.PublisherMock)
+	svc := NewPublishService(projectRepo, targetRepo, deploymentRepo, nil, new(mocks.RendererMock), publisher, "https://landly.test")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop", SchemaJSON: `{"pages":[]}`}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	deployment := &domain.Deployment{ID: uuid.New(), ProjectID: project.ID, Version: 2, Subdomain: "old-name"}
+	target := &domain.PublishTarget{ID: uuid.New(), ProjectID: project.ID, Subdomain: "shop", ActiveDeploymentID: &deployment.ID}
+	targetRepo.On("GetBySubdomain", ctx, "shop").Return(target, nil).Once()
+	deploymentRepo.On("GetByID", ctx, deployment.ID.String()).Return(deployment, nil).Once()
+	publisher.On("GetObject", ctx, "sites/old-name/v2/about/index.html").
+		Return(io.NopCloser(strings.NewReader("<html>")), "text/html", nil).Once()
+
+	published, err := svc.ServePublished(ctx, "shop", "/about", "")
+	require.NoError(t, err)
+	defer published.Body.Close()
+	assert.Equal(t, "text/html", published.ContentType)
+	assert.Empty(t, published.Variant)
+
+	publisher.AssertExpectations(t)
+}
+
+func TestPublishService_ServePublished_LegacyWithoutDeployment(t *testing.T) {
+	ctx := context.Background()
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	deploymentRepo := new(mocks.DeploymentRepositoryMock)
+	publisher := new(mocks.PublisherMock)
+	svc := NewPublishService(new(mocks.ProjectRepositoryMock), targetRepo, deploymentRepo, nil, new(mocks.RendererMock), publisher, "https://landly.test")
+
+	targetRepo.On("GetBySubdomain", ctx, "shop").Return(nil, domain.ErrNotFound).Once()
+	publisher.On("GetObject", ctx, "sites/shop/index.html").
+		Return(io.NopCloser(strings.NewReader("<html>")), "text/html", nil).Once()
+
+	published, err := svc.ServePublished(ctx, "shop", "", "")
+	require.NoError(t, err)
+	defer published.Body.Close()
+
+	deploymentRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestPublishService_PublishProject_RendersExperimentVariants(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	deploymentRepo := new(mocks.DeploymentRepositoryMock)
+	renderer := new(mocks.RendererMock)
+	publisher := new(mocks.PublisherMock)
+	svc := NewPublishService(projectRepo, targetRepo, deploymentRepo, nil, renderer, publisher, "https://landly.test")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop", SchemaJSON: `{"pages":[]}`}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	variantRepo := new(mocks.ProjectVariantRepositoryMock)
+	svc.SetVariantRepository(variantRepo)
+
+	variant := domain.NewProjectVariant(project.ID, "Short hero", `{"pages":[{"path":"/"}]}`, 30)
+	paused := domain.NewProjectVariant(project.ID, "Paused", `{"pages":[]}`, 0)
+	variantRepo.On("ListByProject", ctx, project.ID).Return([]*domain.ProjectVariant{variant, paused}, nil).Once()
+
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(nil, domain.ErrNotFound).Once()
+	targetRepo.On("Create", ctx, mock.AnythingOfType("*domain.PublishTarget")).Return(nil).Once()
+	deploymentRepo.On("Create", ctx, mock.MatchedBy(func(deployment *domain.Deployment) bool {
+		deployment.Version = 4
+		return assert.ObjectsAreEqual([]domain.DeploymentVariant{
+			{Key: domain.VariantControl, Weight: 70},
+			{Key: variant.Key(), Weight: 30, SchemaJSON: variant.SchemaJSON},
+		}, deployment.Variants) && deployment.SchemaJSON == project.SchemaJSON
+	})).Return(nil).Once()
+	renderer.On("RenderVariant", ctx, project.ID, domain.VariantControl, project.SchemaJSON, mock.Anything).Return("/tmp/control", nil).Once()
+	renderer.On("RenderVariant", ctx, project.ID, variant.Key(), variant.SchemaJSON, mock.Anything).Return("/tmp/variant", nil).Once()
+
+	prefix := "sites/" + generateSubdomain(project.Name, project.ID) + "/v4/variants/"
+	publisher.On("Upload", ctx, "/tmp/control", prefix+domain.VariantControl).Return(nil).Once()
+	publisher.On("Upload", ctx, "/tmp/variant", prefix+variant.Key()).Return(nil).Once()
+	deploymentRepo.On("Update", ctx, mock.AnythingOfType("*domain.Deployment")).Return(nil).Once()
+	targetRepo.On("SetActiveDeployment", ctx, mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("uuid.UUID")).Return(nil).Once()
+	projectRepo.On("UpdateStatus", ctx, project.ID.String(), domain.ProjectStatusPublished).Return(nil).Once()
+
+	_, err := svc.PublishProject(ctx, project.UserID, project.ID)
+	require.NoError(t, err)
+
+	renderer.AssertNotCalled(t, "RenderStatic", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	renderer.AssertExpectations(t)
+	publisher.AssertExpectations(t)
+}
+
+func TestPublishService_ServePublished_AssignsStickyVariant(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	deploymentRepo := new(mocks.DeploymentRepositoryMock)
+	publisher := new(mocks.PublisherMock)
+	svc := NewPublishService(projectRepo, targetRepo, deploymentRepo, nil, new(mocks.RendererMock), publisher, "https://landly.test")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop", SchemaJSON: `{"pages":[]}`}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	svc.randIntN = func(n int) int {
+		assert.Equal(t, 100, n)
+		return 75
+	}
+
+	deployment := &domain.Deployment{
+		ID: uuid.New(), ProjectID: project.ID, Version: 2, Subdomain: "shop",
+		Variants: []domain.DeploymentVariant{{Key: domain.VariantControl, Weight: 70}, {Key: "b", Weight: 30}},
+	}
+	target := &domain.PublishTarget{ID: uuid.New(), ProjectID: project.ID, Subdomain: "shop", ActiveDeploymentID: &deployment.ID}
+	targetRepo.On("GetBySubdomain", ctx, "shop").Return(target, nil)
+	deploymentRepo.On("GetByID", ctx, deployment.ID.String()).Return(deployment, nil)
+	publisher.On("GetObject", ctx, "sites/shop/v2/variants/b/index.html").
+		Return(io.NopCloser(strings.NewReader("<html>")), "text/html", nil).Once()
+	publisher.On("GetObject", ctx, "sites/shop/v2/variants/control/styles.css").
+		Return(io.NopCloser(strings.NewReader("body{}")), "text/css", nil).Once()
+
+	// Новый посетитель попадает в вариант по весам
+	published, err := svc.ServePublished(ctx, "shop", "", "")
+	require.NoError(t, err)
+	published.Body.Close()
+	assert.Equal(t, "b", published.Variant)
+
+	// Вариант из cookie сохраняется без розыгрыша
+	svc.randIntN = func(int) int {
+		t.Fatal("sticky visitor must not be reassigned")
+		return 0
+	}
+	published, err = svc.ServePublished(ctx, "shop", "styles.css", domain.VariantControl)
+	require.NoError(t, err)
+	published.Body.Close()
+	assert.Equal(t, domain.VariantControl, published.Variant)
+
+	publisher.AssertExpectations(t)
+}
+
+func TestPublishService_AssignVariant_ReassignsUnknownCookie(t *testing.T) {
+	svc := NewPublishService(new(mocks.ProjectRepositoryMock), new(mocks.PublishTargetRepositoryMock), new(mocks.DeploymentRepositoryMock), nil, new(mocks.RendererMock), new(mocks.PublisherMock), "https://landly.test")
+
+	svc.randIntN = func(int) int { return 10 }
+	variants := []domain.DeploymentVariant{{Key: domain.VariantControl, Weight: 50}, {Key: "b", Weight: 50}}
+
+	assert.Equal(t, domain.VariantControl, svc.assignVariant(variants, "removed-variant"))
+	assert.Equal(t, "b", svc.assignVariant(variants, "b"))
+}
+
+func TestPublishService_RollbackDeployment(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	deploymentRepo := new(mocks.DeploymentRepositoryMock)
+	svc := NewPublishService(projectRepo, targetRepo, deploymentRepo, nil, new(mocks.RendererMock), new(mocks.PublisherMock), "https://landly.test")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop", SchemaJSON: `{"pages":[]}`}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	deployment := &domain.Deployment{ID: uuid.New(), ProjectID: project.ID, Version: 1, Status: domain.DeploymentStatusSucceeded}
+	target := &domain.PublishTarget{ID: uuid.New(), ProjectID: project.ID}
+	deploymentRepo.On("GetByID", ctx, deployment.ID.String()).Return(deployment, nil).Once()
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(target, nil).Once()
+	targetRepo.On("SetActiveDeployment", ctx, target.ID, deployment.ID).Return(nil).Once()
+
+	activated, err := svc.RollbackDeployment(ctx, project.UserID.String(), project.ID.String(), deployment.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, deployment.ID, activated.ID)
+
+	targetRepo.AssertExpectations(t)
+}
+
+func TestPublishService_RollbackDeployment_Rejected(t *testing.T) {
+	tests := []struct {
+		name       string
+		deployment func(project *domain.Project) *domain.Deployment
+		code       string
+	}{
+		{
+			name: "other project",
+			deployment: func(*domain.Project) *domain.Deployment {
+				return &domain.Deployment{ID: uuid.New(), ProjectID: uuid.New(), Status: domain.DeploymentStatusSucceeded}
+			},
+			code: domain.ErrNotFound.Code,
+		},
+		{
+			name: "failed deployment",
+			deployment: func(project *domain.Project) *domain.Deployment {
+				return &domain.Deployment{ID: uuid.New(), ProjectID: project.ID, Status: domain.DeploymentStatusFailed}
+			},
+			code: domain.ErrBadRequest.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			projectRepo := new(mocks.ProjectRepositoryMock)
+			targetRepo := new(mocks.PublishTargetRepositoryMock)
+			deploymentRepo := new(mocks.DeploymentRepositoryMock)
+			svc := NewPublishService(projectRepo, targetRepo, deploymentRepo, nil, new(mocks.RendererMock), new(mocks.PublisherMock), "https://landly.test")
+
+			project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop", SchemaJSON: `{"pages":[]}`}
+			projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+			deployment := tt.deployment(project)
+			deploymentRepo.On("GetByID", ctx, deployment.ID.String()).Return(deployment, nil).Once()
+
+			_, err := svc.RollbackDeployment(ctx, project.UserID.String(), project.ID.String(), deployment.ID.String())
+
+			var domainErr *domain.Error
+			require.ErrorAs(t, err, &domainErr)
+			assert.Equal(t, tt.code, domainErr.Code)
+			targetRepo.AssertNotCalled(t, "SetActiveDeployment", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestPublishService_PublishSite_EnqueuesJob(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	targetRepo := new(mocks.PublishTargetRepositoryMock)
+	renderer := new(mocks.RendererMock)
+	svc := NewPublishService(projectRepo, targetRepo, new(mocks.DeploymentRepositoryMock), nil, renderer, new(mocks.PublisherMock), "https://landly.test")
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop", SchemaJSON: `{"pages":[]}`}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	queue := new(mocks.JobQueueMock)
+	svc.SetJobQueue(queue)
+
+	targetRepo.On("GetByProjectID", ctx, project.ID.String()).Return(nil, domain.ErrNotFound).Once()
+	targetRepo.On("Create", ctx, mock.AnythingOfType("*domain.PublishTarget")).Return(nil).Once()
+	job := &domain.Job{ID: uuid.New(), Type: domain.JobTypePublish}
+	queue.On("Enqueue", ctx, domain.JobTypePublish, mock.AnythingOfType("services.publishJobPayload"), project.UserID, project.ID).
+		Return(job, nil).Once()
+
+	target, err := svc.PublishSite(ctx, project.UserID.String(), project.ID.String(), &domain.PublishRequest{})
+	require.NoError(t, err)
+	require.NotNil(t, target.JobID)
+	assert.Equal(t, job.ID, *target.JobID)
+
+	renderer.AssertNotCalled(t, "RenderStatic", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	queue.AssertExpectations(t)
+}
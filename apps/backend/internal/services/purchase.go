@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/landly/backend/internal/logger"
+	domain "github.com/landly/backend/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// stripeSignatureTolerance допустимое расхождение времени подписи, как в SDK Stripe:
+	// перехваченный вебхук нельзя переотправить позже
+	stripeSignatureTolerance = 5 * time.Minute
+
+	stripeEventCheckoutCompleted     = "checkout.session.completed"
+	stripeEventAsyncPaymentSucceeded = "checkout.session.async_payment_succeeded"
+	stripePaymentStatusUnpaid        = "unpaid"
+
+	maxPurchaseVariantLength = 64
+	purchasesListLimit       = 100
+)
+
+// stripeEvent поля события Stripe, нужные для учёта оплат
+type stripeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object stripeCheckoutSession `json:"object"`
+	} `json:"data"`
+}
+
+type stripeCheckoutSession struct {
+	ID                string `json:"id"`
+	AmountTotal       int64  `json:"amount_total"`
+	Currency          string `json:"currency"`
+	ClientReferenceID string `json:"client_reference_id"`
+	PaymentStatus     string `json:"payment_status"`
+}
+
+// PurchaseService учёт оплат из вебхуков платёжных систем.
+// Оплата относится к варианту лендинга по client_reference_id, который рендерер
+// добавляет к ссылке Stripe Payment Link.
+type PurchaseService struct {
+	projectRepo     domain.ProjectRepository
+	integrationRepo domain.IntegrationRepository
+	purchaseRepo    domain.PurchaseRepository
+	events          EventEmitter
+}
+
+// NewPurchaseService создаёт сервис оплат
+func NewPurchaseService(projectRepo domain.ProjectRepository, integrationRepo domain.IntegrationRepository, purchaseRepo domain.PurchaseRepository) *PurchaseService {
+	return &PurchaseService{
+		projectRepo:     projectRepo,
+		integrationRepo: integrationRepo,
+		purchaseRepo:    purchaseRepo,
+	}
+}
+
+// SetEventEmitter отправляет событие purchase.completed подписчикам вебхуков проекта
+func (s *PurchaseService) SetEventEmitter(events EventEmitter) {
+	s.events = events
+}
+
+// HandleStripeWebhook проверяет подпись Stripe-Signature секретом интеграции проекта
+// и записывает оплаченную сессию Checkout. Прочие события и повторные доставки
+// принимаются без изменений, чтобы Stripe не повторял их.
+func (s *PurchaseService) HandleStripeWebhook(ctx context.Context, projectID string, payload []byte, signature string) error {
+	integration, err := s.integrationRepo.GetByProjectIDAndType(ctx, projectID, domain.IntegrationTypeStripe)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrNotFound.WithMessage("stripe webhook is not configured")
+		}
+		return err
+	}
+	config, err := integration.ParseConfig()
+	if err != nil {
+		return domain.ErrInternal.WithError(err)
+	}
+	if config.WebhookSecret == "" {
+		return domain.ErrNotFound.WithMessage("stripe webhook is not configured")
+	}
+
+	if err := verifyStripeSignature(payload, signature, config.WebhookSecret, time.Now()); err != nil {
+		return err
+	}
+
+	var event stripeEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return domain.ErrBadRequest.WithMessage("invalid event payload")
+	}
+
+	switch event.Type {
+	case stripeEventCheckoutCompleted:
+		// При отложенных способах оплаты деньги ещё не пришли: запись будет
+		// по checkout.session.async_payment_succeeded той же сессии
+		if event.Data.Object.PaymentStatus == stripePaymentStatusUnpaid {
+			return nil
+		}
+	case stripeEventAsyncPaymentSucceeded:
+	default:
+		return nil
+	}
+
+	session := event.Data.Object
+	if session.ID == "" || session.Currency == "" {
+		return domain.ErrBadRequest.WithMessage("checkout session without id or currency")
+	}
+
+	purchase := domain.NewPurchase(integration.ProjectID, domain.PurchaseProviderStripe, session.ID,
+		session.AmountTotal, strings.ToUpper(session.Currency), purchaseVariant(integration.ProjectID, session.ClientReferenceID))
+	err = s.purchaseRepo.Create(ctx, purchase)
+	if errors.Is(err, domain.ErrAlreadyExists) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.WithContext(ctx).Info("purchase recorded",
+		zap.String("project_id", purchase.ProjectID.String()),
+		zap.String("external_id", purchase.ExternalID),
+		zap.String("variant", purchase.Variant))
+
+	// Повторная доставка Stripe сюда не доходит, поэтому событие отправляется один раз
+	if s.events != nil {
+		s.events.Emit(ctx, purchase.ProjectID, domain.WebhookEventPurchaseCompleted, purchaseCompletedData{
+			PurchaseID: purchase.ID,
+			Provider:   purchase.Provider,
+			ExternalID: purchase.ExternalID,
+			Amount:     purchase.Amount,
+			Currency:   purchase.Currency,
+			Variant:    purchase.Variant,
+		})
+	}
+	return nil
+}
+
+// ListPurchases возвращает последние оплаты проекта
+func (s *PurchaseService) ListPurchases(ctx context.Context, userID, projectID string) ([]*domain.Purchase, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return s.purchaseRepo.ListByProject(ctx, project.ID, purchasesListLimit)
+}
+
+// purchaseVariant вариант лендинга из client_reference_id; метка чужого проекта
+// или без варианта не атрибутируется
+func purchaseVariant(projectID uuid.UUID, clientReferenceID string) string {
+	refProjectID, variant, ok := domain.ParseClientReferenceID(clientReferenceID)
+	if !ok || refProjectID != projectID || len(variant) > maxPurchaseVariantLength {
+		return ""
+	}
+	return variant
+}
+
+// verifyStripeSignature проверяет заголовок Stripe-Signature вида t=...,v1=...:
+// v1 — HMAC-SHA256 строки "t.payload" на секрете эндпоинта. Подписей v1 может быть
+// несколько, пока в Stripe действуют старый и новый секреты.
+func verifyStripeSignature(payload []byte, header, secret string, now time.Time) error {
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			if sig, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, sig)
+			}
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return domain.ErrBadRequest.WithMessage("invalid signature header")
+	}
+	signedAt := time.Unix(seconds, 0)
+	if now.Sub(signedAt) > stripeSignatureTolerance || signedAt.Sub(now) > stripeSignatureTolerance {
+		return domain.ErrBadRequest.WithMessage("signature timestamp is outside the tolerance")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+	for _, sig := range signatures {
+		if hmac.Equal(sig, expected) {
+			return nil
+		}
+	}
+	return domain.ErrBadRequest.WithMessage("signature mismatch")
+}
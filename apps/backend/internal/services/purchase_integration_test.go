@@ -0,0 +1,47 @@
+//go:build integration
+// +build integration
+
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/repositories"
+	testhelpers "github.com/landly/backend/internal/testing"
+)
+
+func TestPurchaseService_Integration_StripeWebhook(t *testing.T) {
+	ctx := context.Background()
+	qb := testhelpers.SetupTestDB(t)
+	projectRepo := repositories.NewProjectRepository(qb)
+	integrationRepo := repositories.NewIntegrationRepository(qb)
+	integrations := NewIntegrationService(projectRepo, integrationRepo)
+	purchases := NewPurchaseService(projectRepo, integrationRepo, repositories.NewPurchaseRepository(qb))
+
+	user, _ := testhelpers.CreateTestUser(t, qb, "", "")
+	project := testhelpers.CreateTestProject(t, qb, user.ID, "Checkout Project", "SaaS")
+	userID, projectID := user.ID.String(), project.ID.String()
+
+	_, err := integrations.CreateIntegration(ctx, userID, projectID, &domain.IntegrationRequest{
+		Type: domain.IntegrationTypeStripe, PaymentURL: "https://buy.stripe.com/shop", WebhookSecret: testWebhookSecret,
+	})
+	require.NoError(t, err)
+
+	payload := checkoutEvent("checkout.session.completed", "cs_live_1", "paid", domain.ClientReferenceID(project.ID, domain.VariantControl))
+	signature := signStripePayload(payload, testWebhookSecret, time.Now())
+	require.NoError(t, purchases.HandleStripeWebhook(ctx, projectID, payload, signature))
+	require.NoError(t, purchases.HandleStripeWebhook(ctx, projectID, payload, signature), "redelivery is accepted")
+
+	list, err := purchases.ListPurchases(ctx, userID, projectID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, int64(4900), list[0].Amount)
+	assert.Equal(t, "USD", list[0].Currency)
+	assert.Equal(t, domain.VariantControl, list[0].Variant)
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/services/mocks"
+)
+
+const testWebhookSecret = "whsec_test_secret"
+
+func signStripePayload(payload []byte, secret string, at time.Time) string {
+	timestamp := fmt.Sprintf("%d", at.Unix())
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + string(payload)))
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func checkoutEvent(eventType, sessionID, paymentStatus, clientReferenceID string) []byte {
+	return []byte(fmt.Sprintf(`{"id":"evt_1","type":%q,"data":{"object":{"id":%q,"amount_total":4900,"currency":"usd","payment_status":%q,"client_reference_id":%q}}}`,
+		eventType, sessionID, paymentStatus, clientReferenceID))
+}
+
+func TestPurchaseService_HandleStripeWebhook_RecordsAttributedPurchase(t *testing.T) {
+	ctx := context.Background()
+	integrationRepo := new(mocks.IntegrationRepositoryMock)
+	purchaseRepo := new(mocks.PurchaseRepositoryMock)
+	svc := NewPurchaseService(new(mocks.ProjectRepositoryMock), integrationRepo, purchaseRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	integration := domain.NewIntegration(project.ID, domain.IntegrationTypeStripe, `{"payment_url":"https://buy.stripe.com/shop","webhook_secret":"`+testWebhookSecret+`"}`)
+	integrationRepo.On("GetByProjectIDAndType", ctx, project.ID.String(), domain.IntegrationType(domain.IntegrationTypeStripe)).Return(integration, nil)
+
+	variant := uuid.NewString()
+	purchaseRepo.On("Create", ctx, mock.MatchedBy(func(p *domain.Purchase) bool {
+		return p.ProjectID == project.ID && p.Provider == domain.PurchaseProviderStripe && p.ExternalID == "cs_test_1" &&
+			p.Amount == 4900 && p.Currency == "USD" && p.Variant == variant
+	})).Return(nil).Once()
+
+	payload := checkoutEvent("checkout.session.completed", "cs_test_1", "paid", domain.ClientReferenceID(project.ID, variant))
+	err := svc.HandleStripeWebhook(ctx, project.ID.String(), payload, signStripePayload(payload, testWebhookSecret, time.Now()))
+	require.NoError(t, err)
+	purchaseRepo.AssertExpectations(t)
+}
+
+func TestPurchaseService_HandleStripeWebhook_Attribution(t *testing.T) {
+	ctx := context.Background()
+	integrationRepo := new(mocks.IntegrationRepositoryMock)
+	purchaseRepo := new(mocks.PurchaseRepositoryMock)
+	svc := NewPurchaseService(new(mocks.ProjectRepositoryMock), integrationRepo, purchaseRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	integration := domain.NewIntegration(project.ID, domain.IntegrationTypeStripe, `{"webhook_secret":"`+testWebhookSecret+`"}`)
+	integrationRepo.On("GetByProjectIDAndType", ctx, project.ID.String(), domain.IntegrationType(domain.IntegrationTypeStripe)).Return(integration, nil)
+
+	cases := map[string]struct {
+		ref     string
+		variant string
+	}{
+		"without variant": {project.ID.String(), ""},
+		"control":         {domain.ClientReferenceID(project.ID, domain.VariantControl), domain.VariantControl},
+		"foreign project": {domain.ClientReferenceID(uuid.New(), domain.VariantControl), ""},
+		"no reference":    {"", ""},
+		"garbage":         {"not-a-reference", ""},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			sessionID := "cs_" + name
+			purchaseRepo.On("Create", ctx, mock.MatchedBy(func(p *domain.Purchase) bool {
+				return p.ExternalID == sessionID
+			})).Run(func(args mock.Arguments) {
+				assert.Equal(t, tc.variant, args.Get(1).(*domain.Purchase).Variant)
+			}).Return(nil).Once()
+
+			payload := checkoutEvent("checkout.session.completed", sessionID, "paid", tc.ref)
+			err := svc.HandleStripeWebhook(ctx, project.ID.String(), payload, signStripePayload(payload, testWebhookSecret, time.Now()))
+			require.NoError(t, err)
+		})
+	}
+	purchaseRepo.AssertExpectations(t)
+}
+
+func TestPurchaseService_HandleStripeWebhook_InvalidSignature(t *testing.T) {
+	ctx := context.Background()
+	integrationRepo := new(mocks.IntegrationRepositoryMock)
+	purchaseRepo := new(mocks.PurchaseRepositoryMock)
+	svc := NewPurchaseService(new(mocks.ProjectRepositoryMock), integrationRepo, purchaseRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	integration := domain.NewIntegration(project.ID, domain.IntegrationTypeStripe, `{"webhook_secret":"`+testWebhookSecret+`"}`)
+	integrationRepo.On("GetByProjectIDAndType", ctx, project.ID.String(), domain.IntegrationType(domain.IntegrationTypeStripe)).Return(integration, nil)
+	payload := checkoutEvent("checkout.session.completed", "cs_test_1", "paid", "")
+
+	cases := map[string]string{
+		"missing header":   "",
+		"wrong secret":     signStripePayload(payload, "whsec_other", time.Now()),
+		"stale timestamp":  signStripePayload(payload, testWebhookSecret, time.Now().Add(-10*time.Minute)),
+		"no v1 signature":  fmt.Sprintf("t=%d,v0=abc", time.Now().Unix()),
+		"tampered payload": signStripePayload(checkoutEvent("checkout.session.completed", "cs_test_1", "paid", "x"), testWebhookSecret, time.Now()),
+	}
+	for name, signature := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := svc.HandleStripeWebhook(ctx, project.ID.String(), payload, signature)
+			assert.ErrorIs(t, err, domain.ErrBadRequest)
+		})
+	}
+	purchaseRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPurchaseService_HandleStripeWebhook_SkipsAndDuplicates(t *testing.T) {
+	ctx := context.Background()
+	integrationRepo := new(mocks.IntegrationRepositoryMock)
+	purchaseRepo := new(mocks.PurchaseRepositoryMock)
+	svc := NewPurchaseService(new(mocks.ProjectRepositoryMock), integrationRepo, purchaseRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	integration := domain.NewIntegration(project.ID, domain.IntegrationTypeStripe, `{"webhook_secret":"`+testWebhookSecret+`"}`)
+	integrationRepo.On("GetByProjectIDAndType", ctx, project.ID.String(), domain.IntegrationType(domain.IntegrationTypeStripe)).Return(integration, nil)
+	send := func(payload []byte) error {
+		return svc.HandleStripeWebhook(ctx, project.ID.String(), payload, signStripePayload(payload, testWebhookSecret, time.Now()))
+	}
+
+	// Отложенная оплата и посторонние события не записываются
+	require.NoError(t, send(checkoutEvent("checkout.session.completed", "cs_async", "unpaid", "")))
+	require.NoError(t, send(checkoutEvent("customer.created", "cus_1", "", "")))
+	purchaseRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	// Повторная доставка уже записанной оплаты принимается без ошибки
+	purchaseRepo.On("Create", ctx, mock.AnythingOfType("*domain.Purchase")).Return(domain.ErrAlreadyExists).Once()
+	require.NoError(t, send(checkoutEvent("checkout.session.async_payment_succeeded", "cs_async", "paid", "")))
+	purchaseRepo.AssertExpectations(t)
+}
+
+func TestPurchaseService_HandleStripeWebhook_NotConfigured(t *testing.T) {
+	ctx := context.Background()
+	integrationRepo := new(mocks.IntegrationRepositoryMock)
+	svc := NewPurchaseService(new(mocks.ProjectRepositoryMock), integrationRepo, new(mocks.PurchaseRepositoryMock))
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	integration := domain.NewIntegration(project.ID, domain.IntegrationTypeStripe, `{"payment_url":"https://buy.stripe.com/shop"}`)
+	integrationRepo.On("GetByProjectIDAndType", ctx, project.ID.String(), domain.IntegrationType(domain.IntegrationTypeStripe)).Return(integration, nil)
+	payload := checkoutEvent("checkout.session.completed", "cs_test_1", "paid", "")
+
+	err := svc.HandleStripeWebhook(ctx, project.ID.String(), payload, signStripePayload(payload, "", time.Now()))
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestPurchaseService_HandleStripeWebhook_EmitsPurchaseCompletedOnce(t *testing.T) {
+	ctx := context.Background()
+	integrationRepo := new(mocks.IntegrationRepositoryMock)
+	purchaseRepo := new(mocks.PurchaseRepositoryMock)
+	svc := NewPurchaseService(new(mocks.ProjectRepositoryMock), integrationRepo, purchaseRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New(), Name: "Shop"}
+	integration := domain.NewIntegration(project.ID, domain.IntegrationTypeStripe, `{"webhook_secret":"`+testWebhookSecret+`"}`)
+	integrationRepo.On("GetByProjectIDAndType", ctx, project.ID.String(), domain.IntegrationType(domain.IntegrationTypeStripe)).Return(integration, nil)
+	events := new(mocks.EventEmitterMock)
+	svc.SetEventEmitter(events)
+	send := func(payload []byte) error {
+		return svc.HandleStripeWebhook(ctx, project.ID.String(), payload, signStripePayload(payload, testWebhookSecret, time.Now()))
+	}
+
+	purchaseRepo.On("Create", ctx, mock.AnythingOfType("*domain.Purchase")).Return(nil).Once()
+	purchaseRepo.On("Create", ctx, mock.AnythingOfType("*domain.Purchase")).Return(domain.ErrAlreadyExists).Once()
+	events.On("Emit", ctx, project.ID, domain.WebhookEventPurchaseCompleted, mock.MatchedBy(func(data purchaseCompletedData) bool {
+		return data.ExternalID == "cs_test_1" && data.Amount == 4900 && data.Currency == "USD"
+	})).Once()
+
+	payload := checkoutEvent("checkout.session.completed", "cs_test_1", "paid", "")
+	require.NoError(t, send(payload))
+	require.NoError(t, send(payload))
+	events.AssertExpectations(t)
+	events.AssertNumberOfCalls(t, "Emit", 1)
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/landly/backend/internal/logger"
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/schema"
+	"go.uber.org/zap"
+)
+
+// defaultSchemaRepairAttempts сколько раз модели разрешено исправить невалидную схему
+const defaultSchemaRepairAttempts = 2
+
+// schemaGenerator запрашивает у AI схему и добивается её соответствия page_schema.json
+type schemaGenerator struct {
+	aiClient       AIClient
+	validator      *schema.Validator
+	repairAttempts int
+}
+
+func newSchemaGenerator(aiClient AIClient) *schemaGenerator {
+	return &schemaGenerator{
+		aiClient:       aiClient,
+		validator:      schema.MustNewValidator(),
+		repairAttempts: defaultSchemaRepairAttempts,
+	}
+}
+
+// generate возвращает валидную схему либо domain.ErrSchemaInvalid со списком нарушений.
+// Если onChunk задан и клиент поддерживает потоковую генерацию, фрагменты ответа
+// каждой попытки (0 — исходная, далее — исправления) передаются в onChunk.
+func (g *schemaGenerator) generate(ctx context.Context, prompt, paymentURL string, onChunk func(attempt int, chunk string)) (string, error) {
+	log := logger.WithContext(ctx)
+
+	schemaJSON, err := g.request(ctx, prompt, paymentURL, 0, onChunk)
+	if err != nil {
+		return "", err
+	}
+
+	for attempt := 0; ; attempt++ {
+		validationErrs := g.validator.Validate(schemaJSON)
+		if len(validationErrs) == 0 {
+			return schemaJSON, nil
+		}
+
+		if attempt >= g.repairAttempts {
+			log.Warn("schema repair attempts exhausted",
+				zap.Int("attempts", attempt),
+				zap.Int("errors", len(validationErrs)),
+			)
+			return "", domain.ErrSchemaInvalid.WithError(validationErrs)
+		}
+
+		log.Info("AI returned invalid schema, requesting repair",
+			zap.Int("attempt", attempt+1),
+			zap.Int("errors", len(validationErrs)),
+		)
+
+		schemaJSON, err = g.request(ctx, buildRepairPrompt(prompt, schemaJSON, validationErrs), paymentURL, attempt+1, onChunk)
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// generateBlock запрашивает у AI новые props блока, подставляет их в схему и добивается
+// валидности результата так же, как generate; остальные байты схемы не меняются
+func (g *schemaGenerator) generateBlock(ctx context.Context, schemaJSON string, edit *blockEdit, instruction string) (string, error) {
+	log := logger.WithContext(ctx)
+
+	request := instruction
+	for attempt := 0; ; attempt++ {
+		props, err := g.aiClient.GenerateBlockProps(ctx, edit.ref.BlockType, string(edit.props), request)
+		if err != nil {
+			return "", err
+		}
+
+		updated, err := schema.ReplaceValue(schemaJSON, json.RawMessage(props), edit.path()...)
+		if err != nil {
+			return "", fmt.Errorf("failed to merge block props: %w", err)
+		}
+
+		validationErrs := g.validator.Validate(updated)
+		if len(validationErrs) == 0 {
+			return updated, nil
+		}
+
+		if attempt >= g.repairAttempts {
+			log.Warn("block repair attempts exhausted",
+				zap.String("block_type", edit.ref.BlockType),
+				zap.Int("attempts", attempt),
+				zap.Int("errors", len(validationErrs)),
+			)
+			return "", domain.ErrSchemaInvalid.WithError(validationErrs)
+		}
+
+		log.Info("AI returned invalid block props, requesting repair",
+			zap.String("block_type", edit.ref.BlockType),
+			zap.Int("attempt", attempt+1),
+			zap.Int("errors", len(validationErrs)),
+		)
+		request = buildBlockRepairPrompt(instruction, props, validationErrs)
+	}
+}
+
+func (g *schemaGenerator) request(ctx context.Context, prompt, paymentURL string, attempt int, onChunk func(attempt int, chunk string)) (string, error) {
+	streamingClient, ok := g.aiClient.(StreamingAIClient)
+	if onChunk == nil || !ok {
+		return g.aiClient.GenerateLandingSchema(ctx, prompt, paymentURL)
+	}
+
+	return streamingClient.StreamLandingSchema(ctx, prompt, paymentURL, func(chunk string) {
+		onChunk(attempt, chunk)
+	})
+}
+
+// buildRepairPrompt формирует запрос на исправление схемы с перечнем ошибок валидации
+func buildRepairPrompt(originalPrompt, invalidSchema string, validationErrs schema.ValidationErrors) string {
+	var builder strings.Builder
+
+	builder.WriteString("Предыдущий ответ не прошёл валидацию JSON-схемы лендинга.\n\nОшибки:\n")
+	for _, ve := range validationErrs {
+		builder.WriteString("- ")
+		builder.WriteString(ve.Path)
+		builder.WriteString(": ")
+		builder.WriteString(ve.Message)
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString("\nИсходный запрос:\n")
+	builder.WriteString(originalPrompt)
+	builder.WriteString("\n\nПредыдущий ответ:\n")
+	builder.WriteString(invalidSchema)
+	builder.WriteString("\n\nИсправь все ошибки и верни полную корректную JSON-схему без дополнительного текста.")
+
+	return builder.String()
+}
+
+// buildBlockRepairPrompt формирует запрос на исправление props блока с перечнем ошибок валидации
+func buildBlockRepairPrompt(instruction, invalidProps string, validationErrs schema.ValidationErrors) string {
+	var builder strings.Builder
+
+	builder.WriteString(instruction)
+	builder.WriteString("\n\nПредыдущий ответ не прошёл валидацию схемы лендинга:\n")
+	builder.WriteString(invalidProps)
+	builder.WriteString("\n\nОшибки:\n")
+	for _, ve := range validationErrs {
+		builder.WriteString("- ")
+		builder.WriteString(ve.Path)
+		builder.WriteString(": ")
+		builder.WriteString(ve.Message)
+		builder.WriteString("\n")
+	}
+	builder.WriteString("\nИсправь все ошибки и верни только корректный объект props.")
+
+	return builder.String()
+}
+
+// schemaValidationErrors извлекает список нарушений схемы из цепочки ошибок
+func schemaValidationErrors(err error) (schema.ValidationErrors, bool) {
+	var validationErrs schema.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return validationErrs, true
+	}
+	return nil, false
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/landly/backend/internal/logger"
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/schema"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRevisionsLimit = 50
+	maxRevisionsLimit     = 200
+)
+
+// SchemaRevisionService история изменений схемы: просмотр, сравнение, откат и ручная правка
+type SchemaRevisionService struct {
+	projectRepo  domain.ProjectRepository
+	revisionRepo domain.SchemaRevisionRepository
+	validator    *schema.Validator
+}
+
+// NewSchemaRevisionService создаёт сервис ревизий схемы
+func NewSchemaRevisionService(projectRepo domain.ProjectRepository, revisionRepo domain.SchemaRevisionRepository) *SchemaRevisionService {
+	return &SchemaRevisionService{
+		projectRepo:  projectRepo,
+		revisionRepo: revisionRepo,
+		validator:    schema.MustNewValidator(),
+	}
+}
+
+// ListRevisions возвращает ревизии проекта, начиная с последней
+func (s *SchemaRevisionService) ListRevisions(ctx context.Context, userID, projectID string, limit, offset int) ([]*domain.SchemaRevision, error) {
+	if _, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultRevisionsLimit
+	}
+	if limit > maxRevisionsLimit {
+		limit = maxRevisionsLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	revisions, err := s.revisionRepo.ListByProject(ctx, projectID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// GetRevision возвращает ревизию проекта
+func (s *SchemaRevisionService) GetRevision(ctx context.Context, userID, projectID, revisionID string) (*domain.SchemaRevision, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getProjectRevision(ctx, project, revisionID)
+}
+
+// DiffRevisions возвращает структурные различия схемы ревизии to относительно from
+func (s *SchemaRevisionService) DiffRevisions(ctx context.Context, userID, projectID, fromID, toID string) ([]schema.Change, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := s.getProjectRevision(ctx, project, fromID)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := s.getProjectRevision(ctx, project, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := schema.Diff(from.SchemaJSON, to.SchemaJSON)
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return changes, nil
+}
+
+// RestoreRevision делает схему ревизии текущей; восстановление записывается новой ревизией
+func (s *SchemaRevisionService) RestoreRevision(ctx context.Context, userID, projectID, revisionID string) (*domain.SchemaRevision, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.getProjectRevision(ctx, project, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.restore(ctx, project, revision, domain.RevisionSourceRestore)
+}
+
+// UndoSchema возвращает схему, действовавшую до последнего изменения.
+// Откат записывается ревизией undo со ссылкой на восстановленную ревизию, поэтому
+// повторный откат продолжает путь назад от неё, а не возвращает отменённое изменение.
+func (s *SchemaRevisionService) UndoSchema(ctx context.Context, userID, projectID string) (*domain.SchemaRevision, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.revisionRepo.ListByProject(ctx, projectID, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, domain.ErrBadRequest.WithMessage("nothing to undo")
+	}
+
+	current := revisions[0]
+	if current.Source == domain.RevisionSourceUndo && current.RestoredFrom != nil {
+		current, err = s.revisionRepo.GetByID(ctx, current.RestoredFrom.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	previous, err := s.revisionRepo.GetPrevious(ctx, projectID, current.Version)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrBadRequest.WithMessage("nothing to undo")
+		}
+		return nil, err
+	}
+
+	return s.restore(ctx, project, previous, domain.RevisionSourceUndo)
+}
+
+// UpdateSchema сохраняет схему, отредактированную пользователем вручную
+func (s *SchemaRevisionService) UpdateSchema(ctx context.Context, userID, projectID, schemaJSON string) (*domain.SchemaRevision, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(schemaJSON) == "" {
+		return nil, domain.ErrBadRequest.WithMessage("schema is required")
+	}
+	if validationErrs := s.validator.Validate(schemaJSON); len(validationErrs) > 0 {
+		return nil, domain.ErrSchemaInvalid.WithError(validationErrs)
+	}
+
+	return s.save(ctx, project, domain.NewSchemaRevision(project.ID, schemaJSON, domain.RevisionSourceManual, &project.UserID, nil))
+}
+
+func (s *SchemaRevisionService) restore(ctx context.Context, project *domain.Project, revision *domain.SchemaRevision, source string) (*domain.SchemaRevision, error) {
+	restored := domain.NewSchemaRevision(project.ID, revision.SchemaJSON, source, &project.UserID, nil)
+	restored.RestoredFrom = &revision.ID
+
+	return s.save(ctx, project, restored)
+}
+
+func (s *SchemaRevisionService) save(ctx context.Context, project *domain.Project, revision *domain.SchemaRevision) (*domain.SchemaRevision, error) {
+	if err := s.projectRepo.UpdateSchema(ctx, project.ID.String(), revision.SchemaJSON); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	if err := s.revisionRepo.Create(ctx, revision); err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return revision, nil
+}
+
+func (s *SchemaRevisionService) getProjectRevision(ctx context.Context, project *domain.Project, revisionID string) (*domain.SchemaRevision, error) {
+	revision, err := s.revisionRepo.GetByID(ctx, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if revision.ProjectID != project.ID {
+		return nil, domain.ErrNotFound.WithMessage("revision not found")
+	}
+
+	return revision, nil
+}
+
+// recordSchemaRevision записывает снимок схемы после генерации.
+// Ошибка записи не отменяет уже сохранённую схему и только логируется.
+func recordSchemaRevision(ctx context.Context, repo domain.SchemaRevisionRepository, projectID uuid.UUID, schemaJSON, source string, authorID, messageID *uuid.UUID) {
+	if repo == nil {
+		return
+	}
+
+	revision := domain.NewSchemaRevision(projectID, schemaJSON, source, authorID, messageID)
+	if err := repo.Create(ctx, revision); err != nil {
+		logger.WithContext(ctx).Error("failed to record schema revision",
+			zap.String("project_id", projectID.String()),
+			zap.String("source", source),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,96 @@
+//go:build integration
+// +build integration
+
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/repositories"
+	"github.com/landly/backend/internal/storage/ai"
+	testhelpers "github.com/landly/backend/internal/testing"
+)
+
+func TestSchemaRevisionService_Integration_GenerateAndRestore(t *testing.T) {
+	qb := testhelpers.SetupTestDB(t)
+	projectRepo := repositories.NewProjectRepository(qb)
+	sessionRepo := repositories.NewGenerationSessionRepository(qb)
+	integrationRepo := repositories.NewIntegrationRepository(qb)
+	messageRepo := repositories.NewGenerationMessageRepository(qb)
+	revisionRepo := repositories.NewSchemaRevisionRepository(qb)
+
+	user, _ := testhelpers.CreateTestUser(t, qb, "", "")
+	project := testhelpers.CreateTestProject(t, qb, user.ID, "Revisions Project", "SaaS")
+
+	generateService := NewGenerateService(projectRepo, integrationRepo, sessionRepo, messageRepo, ai.NewMockClient())
+	generateService.SetRevisionRepository(revisionRepo)
+	revisionService := NewSchemaRevisionService(projectRepo, revisionRepo)
+
+	ctx := context.Background()
+	first, err := generateService.GenerateSite(ctx, user.ID.String(), project.ID.String(), &domain.GenerateRequest{Prompt: "Первый вариант"})
+	require.NoError(t, err)
+
+	_, _, err = generateService.SendChatMessage(ctx, user.ID.String(), project.ID.String(), "Второй вариант", nil)
+	require.NoError(t, err)
+
+	revisions, err := revisionService.ListRevisions(ctx, user.ID.String(), project.ID.String(), 0, 0)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, 2, revisions[0].Version)
+	assert.Equal(t, domain.RevisionSourceChat, revisions[0].Source)
+	assert.NotNil(t, revisions[0].MessageID)
+	assert.Equal(t, 1, revisions[1].Version)
+	assert.Equal(t, domain.RevisionSourceGenerate, revisions[1].Source)
+
+	restored, err := revisionService.UndoSchema(ctx, user.ID.String(), project.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, 3, restored.Version)
+	assert.Equal(t, revisions[1].ID, *restored.RestoredFrom)
+
+	updatedProject, err := projectRepo.GetByID(ctx, project.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, first.SchemaJSON, updatedProject.SchemaJSON)
+
+	// Откат продолжает путь назад от восстановленной ревизии, а не возвращает отменённую
+	_, err = revisionService.UndoSchema(ctx, user.ID.String(), project.ID.String())
+	var domainErr *domain.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domain.ErrBadRequest.Code, domainErr.Code)
+}
+
+func TestSchemaRevisionRepository_Integration_ConcurrentVersions(t *testing.T) {
+	qb := testhelpers.SetupTestDB(t)
+	revisionRepo := repositories.NewSchemaRevisionRepository(qb)
+
+	user, _ := testhelpers.CreateTestUser(t, qb, "", "")
+	project := testhelpers.CreateTestProject(t, qb, user.ID, "Concurrent Project", "SaaS")
+
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- revisionRepo.Create(context.Background(), domain.NewSchemaRevision(project.ID, "{}", domain.RevisionSourceManual, &user.ID, nil))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	revisions, err := revisionRepo.ListByProject(context.Background(), project.ID.String(), writers, 0)
+	require.NoError(t, err)
+	require.Len(t, revisions, writers)
+	for i, revision := range revisions {
+		assert.Equal(t, writers-i, revision.Version)
+	}
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/schema"
+	"github.com/landly/backend/internal/services/mocks"
+)
+
+const revisionTestSchema = `{"version":"1.0","pages":[{"path":"/","title":"Home","blocks":[{"type":"hero","order":0,"props":{"headline":"A"}}]}]}`
+
+func TestSchemaRevisionService_RestoreRevision(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	revisionRepo := new(mocks.SchemaRevisionRepositoryMock)
+	svc := NewSchemaRevisionService(projectRepo, revisionRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New()}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	revision := &domain.SchemaRevision{ID: uuid.New(), ProjectID: project.ID, Version: 3, SchemaJSON: revisionTestSchema}
+	revisionRepo.On("GetByID", ctx, revision.ID.String()).Return(revision, nil).Once()
+	projectRepo.On("UpdateSchema", ctx, project.ID.String(), revisionTestSchema).Return(nil).Once()
+	revisionRepo.On("Create", ctx, mock.MatchedBy(func(created *domain.SchemaRevision) bool {
+		return created.Source == domain.RevisionSourceRestore &&
+			created.SchemaJSON == revisionTestSchema &&
+			created.RestoredFrom != nil && *created.RestoredFrom == revision.ID &&
+			created.AuthorID != nil && *created.AuthorID == project.UserID
+	})).Return(nil).Once()
+
+	restored, err := svc.RestoreRevision(ctx, project.UserID.String(), project.ID.String(), revision.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, revisionTestSchema, restored.SchemaJSON)
+
+	projectRepo.AssertExpectations(t)
+	revisionRepo.AssertExpectations(t)
+}
+
+func TestSchemaRevisionService_RestoreRevision_OtherProject(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	revisionRepo := new(mocks.SchemaRevisionRepositoryMock)
+	svc := NewSchemaRevisionService(projectRepo, revisionRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New()}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	revision := &domain.SchemaRevision{ID: uuid.New(), ProjectID: uuid.New(), SchemaJSON: revisionTestSchema}
+	revisionRepo.On("GetByID", ctx, revision.ID.String()).Return(revision, nil).Once()
+
+	_, err := svc.RestoreRevision(ctx, project.UserID.String(), project.ID.String(), revision.ID.String())
+
+	var domainErr *domain.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domain.ErrNotFound.Code, domainErr.Code)
+	projectRepo.AssertNotCalled(t, "UpdateSchema", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSchemaRevisionService_UndoSchema(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	revisionRepo := new(mocks.SchemaRevisionRepositoryMock)
+	svc := NewSchemaRevisionService(projectRepo, revisionRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New()}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	previous := &domain.SchemaRevision{ID: uuid.New(), ProjectID: project.ID, Version: 1, SchemaJSON: revisionTestSchema}
+	current := &domain.SchemaRevision{ID: uuid.New(), ProjectID: project.ID, Version: 2, SchemaJSON: "{}", Source: domain.RevisionSourceChat}
+	revisionRepo.On("ListByProject", ctx, project.ID.String(), 1, 0).Return([]*domain.SchemaRevision{current}, nil).Once()
+	revisionRepo.On("GetPrevious", ctx, project.ID.String(), 2).Return(previous, nil).Once()
+	projectRepo.On("UpdateSchema", ctx, project.ID.String(), revisionTestSchema).Return(nil).Once()
+	revisionRepo.On("Create", ctx, mock.MatchedBy(func(created *domain.SchemaRevision) bool {
+		return created.Source == domain.RevisionSourceUndo && created.RestoredFrom != nil && *created.RestoredFrom == previous.ID
+	})).Return(nil).Once()
+
+	_, err := svc.UndoSchema(ctx, project.UserID.String(), project.ID.String())
+	require.NoError(t, err)
+
+	projectRepo.AssertExpectations(t)
+	revisionRepo.AssertExpectations(t)
+}
+
+func TestSchemaRevisionService_UndoSchema_StepsBackPastUndo(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	revisionRepo := new(mocks.SchemaRevisionRepositoryMock)
+	svc := NewSchemaRevisionService(projectRepo, revisionRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New()}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	// v1 → v2 → v3, затем откат записал v4 = v2; следующий откат должен вернуть v1, а не v3
+	first := &domain.SchemaRevision{ID: uuid.New(), ProjectID: project.ID, Version: 1, SchemaJSON: revisionTestSchema}
+	second := &domain.SchemaRevision{ID: uuid.New(), ProjectID: project.ID, Version: 2, SchemaJSON: "{}"}
+	undo := &domain.SchemaRevision{ID: uuid.New(), ProjectID: project.ID, Version: 4, SchemaJSON: "{}", Source: domain.RevisionSourceUndo, RestoredFrom: &second.ID}
+	revisionRepo.On("ListByProject", ctx, project.ID.String(), 1, 0).Return([]*domain.SchemaRevision{undo}, nil).Once()
+	revisionRepo.On("GetByID", ctx, second.ID.String()).Return(second, nil).Once()
+	revisionRepo.On("GetPrevious", ctx, project.ID.String(), 2).Return(first, nil).Once()
+	projectRepo.On("UpdateSchema", ctx, project.ID.String(), revisionTestSchema).Return(nil).Once()
+	revisionRepo.On("Create", ctx, mock.MatchedBy(func(created *domain.SchemaRevision) bool {
+		return created.RestoredFrom != nil && *created.RestoredFrom == first.ID
+	})).Return(nil).Once()
+
+	_, err := svc.UndoSchema(ctx, project.UserID.String(), project.ID.String())
+	require.NoError(t, err)
+
+	projectRepo.AssertExpectations(t)
+	revisionRepo.AssertExpectations(t)
+}
+
+func TestSchemaRevisionService_UndoSchema_NothingToUndo(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	revisionRepo := new(mocks.SchemaRevisionRepositoryMock)
+	svc := NewSchemaRevisionService(projectRepo, revisionRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New()}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	only := &domain.SchemaRevision{ID: uuid.New(), ProjectID: project.ID, Version: 1}
+	revisionRepo.On("ListByProject", ctx, project.ID.String(), 1, 0).Return([]*domain.SchemaRevision{only}, nil).Once()
+	revisionRepo.On("GetPrevious", ctx, project.ID.String(), 1).Return(nil, domain.ErrNotFound.WithMessage("revision not found")).Once()
+
+	_, err := svc.UndoSchema(ctx, project.UserID.String(), project.ID.String())
+
+	var domainErr *domain.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domain.ErrBadRequest.Code, domainErr.Code)
+}
+
+func TestSchemaRevisionService_UpdateSchema_Invalid(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	revisionRepo := new(mocks.SchemaRevisionRepositoryMock)
+	svc := NewSchemaRevisionService(projectRepo, revisionRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New()}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	_, err := svc.UpdateSchema(ctx, project.UserID.String(), project.ID.String(), `{"pages":[]}`)
+
+	var domainErr *domain.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domain.ErrSchemaInvalid.Code, domainErr.Code)
+	_, ok := schemaValidationErrors(err)
+	assert.True(t, ok)
+	projectRepo.AssertNotCalled(t, "UpdateSchema", mock.Anything, mock.Anything, mock.Anything)
+	revisionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestSchemaRevisionService_DiffRevisions(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	revisionRepo := new(mocks.SchemaRevisionRepositoryMock)
+	svc := NewSchemaRevisionService(projectRepo, revisionRepo)
+
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New()}
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil)
+
+	from := &domain.SchemaRevision{ID: uuid.New(), ProjectID: project.ID, SchemaJSON: revisionTestSchema}
+	to := &domain.SchemaRevision{ID: uuid.New(), ProjectID: project.ID, SchemaJSON: `{"version":"1.0","pages":[{"path":"/","title":"Home","blocks":[{"type":"hero","order":0,"props":{"headline":"B"}}]}]}`}
+	revisionRepo.On("GetByID", ctx, from.ID.String()).Return(from, nil).Once()
+	revisionRepo.On("GetByID", ctx, to.ID.String()).Return(to, nil).Once()
+
+	changes, err := svc.DiffRevisions(ctx, project.UserID.String(), project.ID.String(), from.ID.String(), to.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, []schema.Change{
+		{Op: schema.DiffOpReplace, Path: "$.pages[0].blocks[0].props.headline", OldValue: "A", NewValue: "B"},
+	}, changes)
+}
+
+func TestSimpleGenerateService_RecordsRevision(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := new(mocks.ProjectRepositoryMock)
+	revisionRepo := new(mocks.SchemaRevisionRepositoryMock)
+	aiClient := new(mocks.AIClientMock)
+	project := &domain.Project{ID: uuid.New(), UserID: uuid.New()}
+
+	svc := NewSimpleGenerateService(projectRepo, aiClient)
+	svc.SetRevisionRepository(revisionRepo)
+
+	projectRepo.On("GetByID", ctx, project.ID.String()).Return(project, nil).Once()
+	aiClient.On("GenerateLandingSchema", ctx, "Prompt", "").Return(revisionTestSchema, nil).Once()
+	projectRepo.On("UpdateSchema", ctx, project.ID.String(), revisionTestSchema).Return(nil).Once()
+	revisionRepo.On("Create", ctx, mock.MatchedBy(func(created *domain.SchemaRevision) bool {
+		return created.Source == domain.RevisionSourceGenerateSimple &&
+			created.ProjectID == project.ID &&
+			created.AuthorID != nil && *created.AuthorID == project.UserID &&
+			created.MessageID == nil
+	})).Return(nil).Once()
+
+	_, err := svc.GenerateSimple(ctx, project.UserID.String(), project.ID.String(), "Prompt", "")
+	require.NoError(t, err)
+
+	revisionRepo.AssertExpectations(t)
+}
@@ -13,18 +13,31 @@ import (
 
 // SimpleGenerateService простой сервис генерации
 type SimpleGenerateService struct {
-	projectRepo domain.ProjectRepository
-	aiClient    AIClient
+	projectRepo     domain.ProjectRepository
+	aiClient        AIClient
+	schemaGenerator *schemaGenerator
+	revisionRepo    domain.SchemaRevisionRepository
 }
 
 // NewSimpleGenerateService создает новый простой сервис генерации
 func NewSimpleGenerateService(projectRepo domain.ProjectRepository, aiClient AIClient) *SimpleGenerateService {
 	return &SimpleGenerateService{
-		projectRepo: projectRepo,
-		aiClient:    aiClient,
+		projectRepo:     projectRepo,
+		aiClient:        aiClient,
+		schemaGenerator: newSchemaGenerator(aiClient),
 	}
 }
 
+// SetSchemaRepairAttempts задаёт число попыток исправления схемы, не прошедшей валидацию
+func (s *SimpleGenerateService) SetSchemaRepairAttempts(attempts int) {
+	s.schemaGenerator.repairAttempts = attempts
+}
+
+// SetRevisionRepository включает запись ревизий схемы после каждой генерации
+func (s *SimpleGenerateService) SetRevisionRepository(revisionRepo domain.SchemaRevisionRepository) {
+	s.revisionRepo = revisionRepo
+}
+
 // GenerateSimple простая генерация лендинга
 func (s *SimpleGenerateService) GenerateSimple(ctx context.Context, userID, projectID string, prompt, paymentURL string) (map[string]interface{}, error) {
 	log := logger.WithContext(ctx).With(
@@ -56,12 +69,9 @@ func (s *SimpleGenerateService) GenerateSimple(ctx context.Context, userID, proj
 		return nil, fmt.Errorf("доступ запрещен")
 	}
 
-	// Проверяем, что проект принадлежит пользователю
-	_ = projectUUID // Используем переменную для избежания ошибки компиляции
-
 	// Генерируем схему с помощью AI
 	log.Info("generating schema with AI")
-	schemaJSON, err := s.aiClient.GenerateLandingSchema(ctx, prompt, paymentURL)
+	schemaJSON, err := s.schemaGenerator.generate(ctx, prompt, paymentURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка AI генерации: %w", err)
 	}
@@ -83,6 +93,7 @@ func (s *SimpleGenerateService) GenerateSimple(ctx context.Context, userID, proj
 	}
 
 	log.Info("schema saved to project successfully")
+	recordSchemaRevision(ctx, s.revisionRepo, projectUUID, schemaJSON, domain.RevisionSourceGenerateSimple, &userUUID, nil)
 
 	return schema, nil
 }
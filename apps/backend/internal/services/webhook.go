@@ -0,0 +1,641 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/landly/backend/internal/logger"
+	domain "github.com/landly/backend/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	maxWebhooksPerProject = 10
+	maxWebhookURLLength   = 2048
+
+	defaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 200
+	// WebhookDeliveryRetention срок хранения журнала доставок
+	WebhookDeliveryRetention = 30 * 24 * time.Hour
+
+	webhookSecretPrefix     = "whsec_"
+	webhookTimeout          = 10 * time.Second
+	webhookDialTimeout      = 5 * time.Second
+	maxWebhookResponseBytes = 512
+	maxWebhookErrorLength   = 1000
+
+	// Заголовки запроса к подписчику; подпись в формате Stripe-Signature: t=...,v1=...
+	WebhookSignatureHeader = "X-Landly-Signature"
+	WebhookEventHeader     = "X-Landly-Event"
+	WebhookDeliveryHeader  = "X-Landly-Delivery"
+	webhookUserAgent       = "Landly-Webhooks/1.0"
+)
+
+// errWebhookPrivateAddress вебхук не должен открывать владельцу проекта доступ во внутреннюю сеть
+var errWebhookPrivateAddress = errors.New("webhook URL resolves to a non-public address")
+
+// EventEmitter отправляет события проекта подписчикам вебхуков.
+// Ошибки отправки логируются и не влияют на действие, породившее событие.
+// Subscribed позволяет не собирать данные события, которое никому не отправится.
+type EventEmitter interface {
+	Emit(ctx context.Context, projectID uuid.UUID, eventType string, data interface{})
+	Subscribed(ctx context.Context, projectID uuid.UUID, eventType string) bool
+}
+
+// WebhookService исходящие вебхуки: подписки проекта на события, доставка через очередь задач
+// с повторами по backoff воркера и журнал попыток доставки.
+type WebhookService struct {
+	projectRepo  domain.ProjectRepository
+	webhookRepo  domain.WebhookRepository
+	deliveryRepo domain.WebhookDeliveryRepository
+	jobQueue     JobQueue
+	httpClient   *http.Client
+	// subscriptions подписки проектов для Emit и Subscribed, которые вызываются и из публичного трекинга
+	subscriptions projectCache[[]*domain.Webhook]
+}
+
+// NewWebhookService создаёт сервис вебхуков
+func NewWebhookService(
+	projectRepo domain.ProjectRepository,
+	webhookRepo domain.WebhookRepository,
+	deliveryRepo domain.WebhookDeliveryRepository,
+	jobQueue JobQueue,
+) *WebhookService {
+	return &WebhookService{
+		projectRepo:  projectRepo,
+		webhookRepo:  webhookRepo,
+		deliveryRepo: deliveryRepo,
+		jobQueue:     jobQueue,
+		httpClient:   newWebhookHTTPClient(),
+	}
+}
+
+// SetHTTPClient заменяет HTTP-клиент доставки. Клиент по умолчанию не обращается
+// к адресам внутренней сети и не следует редиректам.
+func (s *WebhookService) SetHTTPClient(client *http.Client) {
+	s.httpClient = client
+}
+
+// webhookJobPayload данные задачи доставки; Body подписывается заново при каждой попытке
+type webhookJobPayload struct {
+	WebhookID uuid.UUID `json:"webhook_id"`
+	EventID   uuid.UUID `json:"event_id"`
+	Event     string    `json:"event"`
+	Body      string    `json:"body"`
+}
+
+// Данные событий (поле data в теле запроса к подписчику)
+type (
+	sitePublishedData struct {
+		DeploymentID uuid.UUID `json:"deployment_id"`
+		Version      int       `json:"version"`
+		URL          string    `json:"url"`
+		Subdomain    string    `json:"subdomain"`
+	}
+
+	leadSubmittedData struct {
+		SubmissionID uuid.UUID         `json:"submission_id"`
+		Form         string            `json:"form"`
+		Fields       map[string]string `json:"fields"`
+		Path         string            `json:"path"`
+		Variant      string            `json:"variant,omitempty"`
+	}
+
+	purchaseCompletedData struct {
+		PurchaseID uuid.UUID `json:"purchase_id"`
+		Provider   string    `json:"provider"`
+		ExternalID string    `json:"external_id"`
+		Amount     int64     `json:"amount"`
+		Currency   string    `json:"currency"`
+		Variant    string    `json:"variant,omitempty"`
+	}
+
+	goalReachedData struct {
+		GoalID    uuid.UUID `json:"goal_id"`
+		Goal      string    `json:"goal"`
+		EventType string    `json:"event_type"`
+		Path      string    `json:"path"`
+		Variant   string    `json:"variant,omitempty"`
+	}
+)
+
+// ListWebhooks возвращает подписки проекта
+func (s *WebhookService) ListWebhooks(ctx context.Context, userID, projectID string) ([]*domain.Webhook, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return s.webhookRepo.ListByProject(ctx, project.ID)
+}
+
+// GetWebhook возвращает подписку проекта
+func (s *WebhookService) GetWebhook(ctx context.Context, userID, projectID, webhookID string) (*domain.Webhook, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return s.projectWebhook(ctx, project, webhookID)
+}
+
+// CreateWebhook подписывает URL на события проекта; секрет подписи генерируется сервисом
+func (s *WebhookService) CreateWebhook(ctx context.Context, userID, projectID string, req *domain.WebhookRequest) (*domain.Webhook, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookURL, err := normalizeWebhookURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	events, err := normalizeWebhookEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.webhookRepo.ListByProject(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxWebhooksPerProject {
+		return nil, domain.ErrBadRequest.WithMessage(fmt.Sprintf("a project can have at most %d webhooks", maxWebhooksPerProject))
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	webhook := domain.NewWebhook(project.ID, webhookURL, secret, events)
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+	if err := s.webhookRepo.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+	s.subscriptions.forget(project.ID)
+
+	return webhook, nil
+}
+
+// UpdateWebhook меняет URL, события или активность подписки и может выдать новый секрет.
+// Незаданные в запросе поля сохраняются.
+func (s *WebhookService) UpdateWebhook(ctx context.Context, userID, projectID, webhookID string, req *domain.WebhookRequest) (*domain.Webhook, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.projectWebhook(ctx, project, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(req.URL) == "" && req.Events == nil && req.Active == nil && !req.RotateSecret {
+		return nil, domain.ErrInvalidInput.WithMessage("url, events, active or rotate_secret is required")
+	}
+
+	if strings.TrimSpace(req.URL) != "" {
+		if webhook.URL, err = normalizeWebhookURL(req.URL); err != nil {
+			return nil, err
+		}
+	}
+	if req.Events != nil {
+		if webhook.Events, err = normalizeWebhookEvents(req.Events); err != nil {
+			return nil, err
+		}
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+	if req.RotateSecret {
+		if webhook.Secret, err = newWebhookSecret(); err != nil {
+			return nil, domain.ErrInternal.WithError(err)
+		}
+	}
+
+	webhook.UpdatedAt = time.Now()
+	if err := s.webhookRepo.Update(ctx, webhook); err != nil {
+		return nil, err
+	}
+	s.subscriptions.forget(project.ID)
+
+	return webhook, nil
+}
+
+// DeleteWebhook удаляет подписку; недоставленные события ей больше не отправляются
+func (s *WebhookService) DeleteWebhook(ctx context.Context, userID, projectID, webhookID string) error {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return err
+	}
+
+	webhook, err := s.projectWebhook(ctx, project, webhookID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.webhookRepo.Delete(ctx, webhook.ID.String()); err != nil {
+		return err
+	}
+	s.subscriptions.forget(project.ID)
+	return nil
+}
+
+// ListDeliveries возвращает журнал доставок подписки, новые попытки первыми
+func (s *WebhookService) ListDeliveries(ctx context.Context, userID, projectID, webhookID string, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.projectWebhook(ctx, project, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultWebhookDeliveriesLimit
+	}
+	if limit > maxWebhookDeliveriesLimit {
+		limit = maxWebhookDeliveriesLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.deliveryRepo.ListByWebhook(ctx, webhook.ID, limit, offset)
+}
+
+// SendTestEvent сразу отправляет подписке событие webhook.test, без повторов.
+// Неудачная доставка не ошибка: её результат возвращается и пишется в журнал.
+func (s *WebhookService) SendTestEvent(ctx context.Context, userID, projectID, webhookID string) (*domain.WebhookDelivery, error) {
+	project, err := ensureProjectOwnership(ctx, s.projectRepo, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.projectWebhook(ctx, project, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := domain.NewWebhookEvent(project.ID, domain.WebhookEventTest, map[string]string{
+		"message": "Test event from Landly",
+	})
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, domain.ErrInternal.WithError(err)
+	}
+
+	return s.deliver(ctx, webhook, event.ID, event.Type, body, 1)
+}
+
+// Emit ставит в очередь доставку события всем активным подпискам проекта на этот тип
+func (s *WebhookService) Emit(ctx context.Context, projectID uuid.UUID, eventType string, data interface{}) {
+	log := logger.WithContext(ctx).With(
+		zap.String("project_id", projectID.String()),
+		zap.String("event", eventType),
+	)
+
+	subscribers, err := s.subscribers(ctx, projectID, eventType)
+	if err != nil {
+		log.Error("failed to list webhooks", zap.Error(err))
+		return
+	}
+	if len(subscribers) == 0 {
+		return
+	}
+
+	// Задача привязывается к владельцу проекта, как задачи генерации и публикации
+	project, err := s.projectRepo.GetByID(ctx, projectID.String())
+	if err != nil {
+		log.Error("failed to get project for webhook event", zap.Error(err))
+		return
+	}
+
+	event := domain.NewWebhookEvent(projectID, eventType, data)
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Error("failed to encode webhook event", zap.Error(err))
+		return
+	}
+
+	for _, webhook := range subscribers {
+		_, err := s.jobQueue.Enqueue(ctx, domain.JobTypeWebhook, webhookJobPayload{
+			WebhookID: webhook.ID,
+			EventID:   event.ID,
+			Event:     eventType,
+			Body:      string(body),
+		}, project.UserID, projectID)
+		if err != nil {
+			log.Error("failed to enqueue webhook delivery", zap.String("webhook_id", webhook.ID.String()), zap.Error(err))
+		}
+	}
+}
+
+// Subscribed есть ли у проекта подписка на событие
+func (s *WebhookService) Subscribed(ctx context.Context, projectID uuid.UUID, eventType string) bool {
+	subscribers, err := s.subscribers(ctx, projectID, eventType)
+	if err != nil {
+		logger.WithContext(ctx).Error("failed to list webhooks",
+			zap.String("project_id", projectID.String()),
+			zap.Error(err))
+		return false
+	}
+	return len(subscribers) > 0
+}
+
+// subscribers подписки проекта на событие. Подписки читаются из кэша: Emit вызывается
+// на каждое подходящее событие трекинга
+func (s *WebhookService) subscribers(ctx context.Context, projectID uuid.UUID, eventType string) ([]*domain.Webhook, error) {
+	now := time.Now()
+	webhooks, ok := s.subscriptions.get(projectID, now)
+	if !ok {
+		var err error
+		webhooks, err = s.webhookRepo.ListByProject(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		s.subscriptions.set(projectID, webhooks, now)
+	}
+
+	var subscribers []*domain.Webhook
+	for _, webhook := range webhooks {
+		if webhook.Subscribed(eventType) {
+			subscribers = append(subscribers, webhook)
+		}
+	}
+	return subscribers, nil
+}
+
+// HandleDeliveryJob доставляет событие в воркере. Неудачная попытка возвращает ошибку,
+// и воркер повторяет задачу с экспоненциальной задержкой до исчерпания попыток.
+func (s *WebhookService) HandleDeliveryJob(ctx context.Context, job *domain.Job) error {
+	var payload webhookJobPayload
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		return domain.ErrBadRequest.WithMessage("invalid webhook job payload")
+	}
+
+	webhook, err := s.webhookRepo.GetByID(ctx, payload.WebhookID.String())
+	if errors.Is(err, domain.ErrNotFound) {
+		// Подписку удалили, пока событие ждало в очереди
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !webhook.Active {
+		return nil
+	}
+
+	delivery, err := s.deliver(ctx, webhook, payload.EventID, payload.Event, []byte(payload.Body), job.Attempts)
+	if err != nil {
+		return err
+	}
+	if !delivery.Success {
+		// Обычная ошибка, а не domain.Error: 4xx-коды domain.Error воркер не повторяет
+		return fmt.Errorf("webhook delivery failed: %s", delivery.Error)
+	}
+	return nil
+}
+
+// PurgeDeliveries удаляет записи журнала доставок старше WebhookDeliveryRetention
+func (s *WebhookService) PurgeDeliveries(ctx context.Context, now time.Time) error {
+	deleted, err := s.deliveryRepo.DeleteBefore(ctx, now.Add(-WebhookDeliveryRetention))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		logger.WithContext(ctx).Info("purged old webhook deliveries", zap.Int64("deleted", deleted))
+	}
+	return nil
+}
+
+// deliver отправляет событие подписчику и записывает попытку в журнал
+func (s *WebhookService) deliver(ctx context.Context, webhook *domain.Webhook, eventID uuid.UUID, eventType string, body []byte, attempt int) (*domain.WebhookDelivery, error) {
+	delivery := &domain.WebhookDelivery{
+		ID:        uuid.New(),
+		WebhookID: webhook.ID,
+		ProjectID: webhook.ProjectID,
+		EventID:   eventID,
+		Event:     eventType,
+		Payload:   string(body),
+		Attempt:   attempt,
+		CreatedAt: time.Now(),
+	}
+
+	statusCode, err := s.post(ctx, webhook, eventID, eventType, body, delivery.CreatedAt)
+	delivery.DurationMs = time.Since(delivery.CreatedAt).Milliseconds()
+	delivery.StatusCode = statusCode
+	if err != nil {
+		delivery.Error = truncateRunes(err.Error(), maxWebhookErrorLength)
+	} else {
+		delivery.Success = true
+	}
+
+	// Попытка записывается и при остановке воркера посреди запроса
+	if err := s.deliveryRepo.Create(context.WithoutCancel(ctx), delivery); err != nil {
+		return nil, err
+	}
+
+	logger.WithContext(ctx).Info("webhook delivered",
+		zap.String("webhook_id", webhook.ID.String()),
+		zap.String("event", eventType),
+		zap.Int("attempt", attempt),
+		zap.Int("status_code", statusCode),
+		zap.Bool("success", delivery.Success))
+	return delivery, nil
+}
+
+// post выполняет запрос к подписчику; успех — ответ 2xx
+func (s *WebhookService) post(ctx context.Context, webhook *domain.Webhook, eventID uuid.UUID, eventType string, body []byte, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", webhookUserAgent)
+	req.Header.Set(WebhookEventHeader, eventType)
+	req.Header.Set(WebhookDeliveryHeader, eventID.String())
+	req.Header.Set(WebhookSignatureHeader, signWebhookPayload(body, webhook.Secret, now))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Начало ответа попадает в журнал и помогает понять причину отказа
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseBytes))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message := fmt.Sprintf("unexpected status %d", resp.StatusCode)
+		if text := strings.TrimSpace(string(snippet)); text != "" {
+			message += ": " + text
+		}
+		return resp.StatusCode, errors.New(message)
+	}
+	return resp.StatusCode, nil
+}
+
+func (s *WebhookService) projectWebhook(ctx context.Context, project *domain.Project, webhookID string) (*domain.Webhook, error) {
+	webhook, err := s.webhookRepo.GetByID(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook.ProjectID != project.ID {
+		return nil, domain.ErrNotFound.WithMessage("webhook not found")
+	}
+	return webhook, nil
+}
+
+// signWebhookPayload подпись тела в формате Stripe-Signature: v1 — HMAC-SHA256 строки
+// "t.payload" на секрете подписки. Подписчики проверяют её теми же способами, что и вебхуки Stripe.
+func signWebhookPayload(payload []byte, secret string, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + hex.EncodeToString(buf), nil
+}
+
+// normalizeWebhookURL принимает абсолютные http(s)-адреса публичных хостов
+func normalizeWebhookURL(raw string) (string, error) {
+	link := strings.TrimSpace(raw)
+	if link == "" {
+		return "", domain.ErrInvalidInput.WithMessage("url is required")
+	}
+	if len(link) > maxWebhookURLLength {
+		return "", domain.ErrInvalidInput.WithMessage("url is too long")
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" || parsed.User != nil {
+		return "", domain.ErrInvalidInput.WithMessage("url must be an absolute http or https URL")
+	}
+
+	// Имена хостов проверяются при подключении (см. newWebhookHTTPClient), здесь — очевидные случаи
+	host := strings.ToLower(parsed.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return "", domain.ErrInvalidInput.WithMessage("url must point to a public host")
+	}
+	if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+		return "", domain.ErrInvalidInput.WithMessage("url must point to a public host")
+	}
+
+	return parsed.String(), nil
+}
+
+// normalizeWebhookEvents проверяет типы событий и возвращает их без повторов в порядке domain.WebhookEventTypes
+func normalizeWebhookEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return nil, domain.ErrInvalidInput.WithMessage("at least one event is required")
+	}
+
+	requested := make(map[string]bool, len(events))
+	for _, event := range events {
+		event = strings.TrimSpace(event)
+		if !domain.IsValidWebhookEvent(event) {
+			return nil, domain.ErrInvalidInput.WithMessage(fmt.Sprintf("unknown event %q", event))
+		}
+		requested[event] = true
+	}
+
+	normalized := make([]string, 0, len(requested))
+	for _, event := range domain.WebhookEventTypes {
+		if requested[event] {
+			normalized = append(normalized, event)
+		}
+	}
+	return normalized, nil
+}
+
+// newWebhookHTTPClient клиент доставки вебхуков. Адрес проверяется после разрешения DNS,
+// поэтому запись, сменённая после создания подписки, не откроет доступ во внутреннюю сеть.
+func newWebhookHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookDialTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return errWebhookPrivateAddress
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: webhookTimeout,
+		// Прокси из окружения не используется: через него проверка адреса не работает
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookDialTimeout,
+			MaxIdleConnsPerHost: 2,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		// Редирект увёл бы запрос на другой, непроверенный адрес
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// nonPublicNetworks диапазоны, которые не покрывают проверки net.IP, но ведут во внутреннюю сеть
+var nonPublicNetworks = func() []*net.IPNet {
+	cidrs := []string{
+		"100.64.0.0/10", // CGNAT, RFC 6598
+		"198.18.0.0/15", // тестирование производительности, RFC 2544
+		"64:ff9b::/96",  // NAT64, RFC 6052: транслируется в IPv4 шлюзом
+	}
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}()
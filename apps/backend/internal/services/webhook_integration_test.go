@@ -0,0 +1,71 @@
+//go:build integration
+// +build integration
+
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/landly/backend/internal/models"
+	"github.com/landly/backend/internal/repositories"
+	testhelpers "github.com/landly/backend/internal/testing"
+)
+
+func TestWebhookService_Integration_SubscriptionAndDeliveryLog(t *testing.T) {
+	ctx := context.Background()
+	qb := testhelpers.SetupTestDB(t)
+	deliveryRepo := repositories.NewWebhookDeliveryRepository(qb)
+	webhooks := NewWebhookService(repositories.NewProjectRepository(qb), repositories.NewWebhookRepository(qb), deliveryRepo, nil)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+	webhooks.SetHTTPClient(receiver.Client())
+
+	user, _ := testhelpers.CreateTestUser(t, qb, "", "")
+	project := testhelpers.CreateTestProject(t, qb, user.ID, "Webhooks Project", "SaaS")
+	userID, projectID := user.ID.String(), project.ID.String()
+
+	// Адрес httptest локальный, поэтому подписка создаётся на публичный URL и затем переключается напрямую в БД
+	webhook, err := webhooks.CreateWebhook(ctx, userID, projectID, &domain.WebhookRequest{
+		URL:    "https://crm.example.com/hook",
+		Events: []string{domain.WebhookEventSitePublished, domain.WebhookEventLeadSubmitted},
+	})
+	require.NoError(t, err)
+	webhook.URL = receiver.URL
+	require.NoError(t, repositories.NewWebhookRepository(qb).Update(ctx, webhook))
+
+	list, err := webhooks.ListWebhooks(ctx, userID, projectID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, []string{domain.WebhookEventSitePublished, domain.WebhookEventLeadSubmitted}, list[0].Events)
+	assert.Equal(t, webhook.Secret, list[0].Secret)
+
+	delivery, err := webhooks.SendTestEvent(ctx, userID, projectID, webhook.ID.String())
+	require.NoError(t, err)
+	assert.True(t, delivery.Success)
+
+	deliveries, err := webhooks.ListDeliveries(ctx, userID, projectID, webhook.ID.String(), 0, 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, domain.WebhookEventTest, deliveries[0].Event)
+	assert.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+
+	// Журнал очищается по сроку хранения
+	require.NoError(t, webhooks.PurgeDeliveries(ctx, time.Now().Add(WebhookDeliveryRetention+time.Hour)))
+	deliveries, err = webhooks.ListDeliveries(ctx, userID, projectID, webhook.ID.String(), 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+
+	require.NoError(t, webhooks.DeleteWebhook(ctx, userID, projectID, webhook.ID.String()))
+	_, err = webhooks.GetWebhook(ctx, userID, projectID, webhook.ID.String())
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
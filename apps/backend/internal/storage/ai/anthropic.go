@@ -0,0 +1,454 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/landly/backend/internal/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAnthropicBaseURL = "https://api.anthropic.com/v1"
+	anthropicAPIVersion     = "2023-06-01"
+	landingToolName         = "emit_landing_schema"
+	blockToolName           = "emit_block_props"
+
+	// statusOverloaded код ответа Anthropic API при перегрузке
+	statusOverloaded = 529
+)
+
+// AnthropicConfig конфигурация клиента Anthropic Messages API
+type AnthropicConfig struct {
+	APIKey    string
+	Model     string
+	BaseURL   string
+	MaxTokens int
+}
+
+// AnthropicClient клиент Anthropic Messages API
+type AnthropicClient struct {
+	cfg            AnthropicConfig
+	httpClient     *http.Client
+	maxRetries     int
+	retryBaseDelay time.Duration
+}
+
+// NewAnthropicClient создаёт новый клиент Anthropic
+func NewAnthropicClient(cfg AnthropicConfig, opts ...Option) *AnthropicClient {
+	o := applyOptions(opts)
+
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultAnthropicBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = 4000
+	}
+
+	return &AnthropicClient{
+		cfg:            cfg,
+		httpClient:     o.httpClient,
+		maxRetries:     o.maxRetries,
+		retryBaseDelay: o.retryBaseDelay,
+	}
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type anthropicRequest struct {
+	Model      string              `json:"model"`
+	MaxTokens  int                 `json:"max_tokens"`
+	System     string              `json:"system"`
+	Messages   []anthropicMessage  `json:"messages"`
+	Tools      []anthropicTool     `json:"tools"`
+	ToolChoice anthropicToolChoice `json:"tool_choice"`
+	Stream     bool                `json:"stream,omitempty"`
+}
+
+type anthropicContentBlock struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+type anthropicResponse struct {
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+}
+
+// anthropicStreamEvent событие потокового ответа Messages API
+type anthropicStreamEvent struct {
+	Type         string                `json:"type"`
+	Index        int                   `json:"index"`
+	ContentBlock anthropicContentBlock `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type anthropicErrorResponse struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// GenerateLandingSchema запрашивает схему лендинга через принудительный вызов инструмента
+func (c *AnthropicClient) GenerateLandingSchema(ctx context.Context, prompt, paymentURL string) (string, error) {
+	log := logger.WithContext(ctx).With(zap.String("model", c.cfg.Model))
+
+	body, err := c.buildRequest(prompt, paymentURL, false)
+	if err != nil {
+		return "", err
+	}
+
+	respBody, err := c.doWithRetry(ctx, body)
+	if err != nil {
+		log.Error("anthropic request failed", zap.Error(err))
+		return "", err
+	}
+
+	var msgResp anthropicResponse
+	if err := json.Unmarshal(respBody, &msgResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if msgResp.StopReason == "max_tokens" {
+		return "", fmt.Errorf("anthropic response truncated: increase max_tokens")
+	}
+
+	for _, block := range msgResp.Content {
+		if block.Type == "tool_use" && block.Name == landingToolName {
+			schemaJSON, err := normalizeSchema(string(block.Input), paymentURL)
+			if err != nil {
+				log.Error("anthropic returned invalid schema", zap.Error(err))
+				return "", err
+			}
+			log.Info("schema generated successfully", zap.Int("schema_length", len(schemaJSON)))
+			return schemaJSON, nil
+		}
+	}
+
+	return "", fmt.Errorf("anthropic response has no %s tool call", landingToolName)
+}
+
+// GenerateBlockProps переписывает props одного блока через принудительный вызов инструмента
+func (c *AnthropicClient) GenerateBlockProps(ctx context.Context, blockType, propsJSON, instruction string) (string, error) {
+	log := logger.WithContext(ctx).With(zap.String("model", c.cfg.Model), zap.String("block_type", blockType))
+
+	body, err := json.Marshal(anthropicRequest{
+		Model:     c.cfg.Model,
+		MaxTokens: c.cfg.MaxTokens,
+		System:    buildBlockSystemPrompt(blockType),
+		Messages: []anthropicMessage{
+			{Role: "user", Content: buildBlockUserPrompt(propsJSON, instruction)},
+		},
+		Tools: []anthropicTool{
+			{
+				Name:        blockToolName,
+				Description: "Сохраняет новые props блока лендинга",
+				InputSchema: map[string]interface{}{"type": "object"},
+			},
+		},
+		ToolChoice: anthropicToolChoice{Type: "tool", Name: blockToolName},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	respBody, err := c.doWithRetry(ctx, body)
+	if err != nil {
+		log.Error("anthropic request failed", zap.Error(err))
+		return "", err
+	}
+
+	var msgResp anthropicResponse
+	if err := json.Unmarshal(respBody, &msgResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	for _, block := range msgResp.Content {
+		if block.Type == "tool_use" && block.Name == blockToolName {
+			props, err := normalizeBlockProps(string(block.Input))
+			if err != nil {
+				log.Error("anthropic returned invalid block props", zap.Error(err))
+				return "", err
+			}
+			return props, nil
+		}
+	}
+
+	return "", fmt.Errorf("anthropic response has no %s tool call", blockToolName)
+}
+
+// StreamLandingSchema запрашивает схему в потоковом режиме, передавая фрагменты аргументов инструмента в onChunk
+func (c *AnthropicClient) StreamLandingSchema(ctx context.Context, prompt, paymentURL string, onChunk func(chunk string)) (string, error) {
+	log := logger.WithContext(ctx).With(zap.String("model", c.cfg.Model))
+
+	body, err := c.buildRequest(prompt, paymentURL, true)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.sendWithRetry(ctx, body)
+	if err != nil {
+		log.Error("anthropic stream request failed", zap.Error(err))
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	toolBlockIndex := -1
+	var input strings.Builder
+	var stopReason string
+
+	err = readServerSentEvents(resp.Body, func(_, data string) error {
+		var event anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("failed to decode stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "content_block_start":
+			if event.ContentBlock.Type == "tool_use" && event.ContentBlock.Name == landingToolName {
+				toolBlockIndex = event.Index
+			}
+		case "content_block_delta":
+			if event.Index == toolBlockIndex && event.Delta.Type == "input_json_delta" && event.Delta.PartialJSON != "" {
+				input.WriteString(event.Delta.PartialJSON)
+				onChunk(event.Delta.PartialJSON)
+			}
+		case "message_delta":
+			stopReason = event.Delta.StopReason
+		case "error":
+			return fmt.Errorf("anthropic stream error: %s", event.Error.Message)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("anthropic stream failed", zap.Error(err))
+		return "", err
+	}
+
+	if stopReason == "max_tokens" {
+		return "", fmt.Errorf("anthropic response truncated: increase max_tokens")
+	}
+	if toolBlockIndex < 0 {
+		return "", fmt.Errorf("anthropic response has no %s tool call", landingToolName)
+	}
+
+	schemaJSON, err := normalizeSchema(input.String(), paymentURL)
+	if err != nil {
+		log.Error("anthropic returned invalid schema", zap.Error(err))
+		return "", err
+	}
+
+	log.Info("schema streamed successfully", zap.Int("schema_length", len(schemaJSON)))
+	return schemaJSON, nil
+}
+
+func (c *AnthropicClient) buildRequest(prompt, paymentURL string, stream bool) ([]byte, error) {
+	body, err := json.Marshal(anthropicRequest{
+		Model:     c.cfg.Model,
+		MaxTokens: c.cfg.MaxTokens,
+		System:    buildSystemPrompt(),
+		Messages: []anthropicMessage{
+			{Role: "user", Content: buildUserPrompt(prompt, paymentURL)},
+		},
+		Tools: []anthropicTool{
+			{
+				Name:        landingToolName,
+				Description: "Сохраняет сгенерированную JSON-схему лендинга",
+				InputSchema: landingToolInputSchema(),
+			},
+		},
+		ToolChoice: anthropicToolChoice{Type: "tool", Name: landingToolName},
+		Stream:     stream,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return body, nil
+}
+
+// doWithRetry отправляет запрос и возвращает тело ответа
+func (c *AnthropicClient) doWithRetry(ctx context.Context, body []byte) ([]byte, error) {
+	resp, err := c.sendWithRetry(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return respBody, nil
+}
+
+// sendWithRetry отправляет запрос и повторяет его при 429/529 с экспоненциальной задержкой
+func (c *AnthropicClient) sendWithRetry(ctx context.Context, body []byte) (*http.Response, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, c.backoff(attempt, lastErr)); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.send(ctx, body)
+		if err == nil {
+			return resp, nil
+		}
+
+		lastErr = err
+		if !isRetryable(err) {
+			return nil, err
+		}
+
+		logger.WithContext(ctx).Warn("anthropic request throttled, retrying",
+			zap.Int("attempt", attempt+1),
+			zap.Error(err),
+		)
+	}
+
+	return nil, fmt.Errorf("anthropic request failed after %d retries: %w", c.maxRetries, lastErr)
+}
+
+// send выполняет запрос и возвращает ответ со статусом 200 либо *anthropicAPIError
+func (c *AnthropicClient) send(ctx context.Context, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.BaseURL+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", c.cfg.APIKey)
+	req.Header.Set("anthropic-version", anthropicAPIVersion)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		apiErr := &anthropicAPIError{StatusCode: resp.StatusCode}
+		var errResp anthropicErrorResponse
+		if json.Unmarshal(respBody, &errResp) == nil {
+			apiErr.Message = errResp.Error.Message
+		}
+		if seconds, err := strconv.Atoi(resp.Header.Get("retry-after")); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return nil, apiErr
+	}
+
+	return resp, nil
+}
+
+func (c *AnthropicClient) backoff(attempt int, lastErr error) time.Duration {
+	if apiErr, ok := lastErr.(*anthropicAPIError); ok && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter
+	}
+	return c.retryBaseDelay * time.Duration(1<<(attempt-1))
+}
+
+// anthropicAPIError ошибка, возвращённая Anthropic API
+type anthropicAPIError struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *anthropicAPIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("anthropic API error (status %d): %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("anthropic API error (status %d)", e.StatusCode)
+}
+
+func isRetryable(err error) bool {
+	apiErr, ok := err.(*anthropicAPIError)
+	if !ok {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode == statusOverloaded
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// landingToolInputSchema JSON Schema аргументов инструмента emit_landing_schema
+func landingToolInputSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"version", "pages"},
+		"properties": map[string]interface{}{
+			"version": map[string]interface{}{"type": "string"},
+			"pages": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"path", "title", "blocks"},
+					"properties": map[string]interface{}{
+						"path":        map[string]interface{}{"type": "string"},
+						"title":       map[string]interface{}{"type": "string"},
+						"description": map[string]interface{}{"type": "string"},
+						"blocks": map[string]interface{}{
+							"type": "array",
+							"items": map[string]interface{}{
+								"type":     "object",
+								"required": []string{"type", "order", "props"},
+								"properties": map[string]interface{}{
+									"type":  map[string]interface{}{"type": "string", "enum": supportedBlockTypes()},
+									"order": map[string]interface{}{"type": "integer"},
+									"props": map[string]interface{}{"type": "object"},
+								},
+							},
+						},
+					},
+				},
+			},
+			"theme": map[string]interface{}{"type": "object"},
+		},
+	}
+}
@@ -0,0 +1,176 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedResponse struct {
+	status  int
+	fixture string
+	headers map[string]string
+}
+
+// replayServer отдаёт записанные ответы Anthropic API по порядку
+type replayServer struct {
+	t         *testing.T
+	mu        sync.Mutex
+	responses []recordedResponse
+	requests  []anthropicRequest
+}
+
+func newReplayServer(t *testing.T, responses ...recordedResponse) (*replayServer, *httptest.Server) {
+	rs := &replayServer{t: t, responses: responses}
+	server := httptest.NewServer(http.HandlerFunc(rs.handle))
+	t.Cleanup(server.Close)
+	return rs, server
+}
+
+func (rs *replayServer) handle(w http.ResponseWriter, r *http.Request) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	assert.Equal(rs.t, "/v1/messages", r.URL.Path)
+	assert.Equal(rs.t, "test-key", r.Header.Get("x-api-key"))
+	assert.Equal(rs.t, anthropicAPIVersion, r.Header.Get("anthropic-version"))
+
+	var req anthropicRequest
+	require.NoError(rs.t, json.NewDecoder(r.Body).Decode(&req))
+	rs.requests = append(rs.requests, req)
+
+	require.NotEmpty(rs.t, rs.responses, "unexpected request to replay server")
+	resp := rs.responses[0]
+	rs.responses = rs.responses[1:]
+
+	body, err := os.ReadFile(filepath.Join("testdata", "anthropic", resp.fixture))
+	require.NoError(rs.t, err)
+
+	for k, v := range resp.headers {
+		w.Header().Set(k, v)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.status)
+	_, _ = w.Write(body)
+}
+
+func newTestAnthropicClient(baseURL string) *AnthropicClient {
+	return NewAnthropicClient(AnthropicConfig{
+		APIKey:    "test-key",
+		Model:     "claude-test",
+		BaseURL:   baseURL + "/v1",
+		MaxTokens: 2000,
+	}, WithRetryPolicy(3, time.Millisecond))
+}
+
+func TestAnthropicClient_GenerateLandingSchema_ToolUse(t *testing.T) {
+	rs, server := newReplayServer(t, recordedResponse{status: http.StatusOK, fixture: "tool_use_success.json"})
+	client := newTestAnthropicClient(server.URL)
+
+	schemaJSON, err := client.GenerateLandingSchema(context.Background(), "Лендинг школы йоги", "https://pay.example.com")
+	require.NoError(t, err)
+
+	require.Len(t, rs.requests, 1)
+	req := rs.requests[0]
+	assert.Equal(t, "claude-test", req.Model)
+	assert.Equal(t, 2000, req.MaxTokens)
+	assert.Equal(t, "tool", req.ToolChoice.Type)
+	assert.Equal(t, landingToolName, req.ToolChoice.Name)
+	require.Len(t, req.Tools, 1)
+	assert.Equal(t, landingToolName, req.Tools[0].Name)
+
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(schemaJSON), &schema))
+	pages := schema["pages"].([]interface{})
+	require.Len(t, pages, 1)
+	assert.Equal(t, "Школа йоги", pages[0].(map[string]interface{})["title"])
+	assert.Equal(t, "https://pay.example.com", schema["payment"].(map[string]interface{})["url"])
+}
+
+func TestAnthropicClient_GenerateLandingSchema_RetriesOnRateLimitAndOverload(t *testing.T) {
+	rs, server := newReplayServer(t,
+		recordedResponse{status: http.StatusTooManyRequests, fixture: "rate_limited.json"},
+		recordedResponse{status: statusOverloaded, fixture: "overloaded.json"},
+		recordedResponse{status: http.StatusOK, fixture: "tool_use_success.json"},
+	)
+	client := newTestAnthropicClient(server.URL)
+
+	schemaJSON, err := client.GenerateLandingSchema(context.Background(), "prompt", "")
+	require.NoError(t, err)
+	assert.Contains(t, schemaJSON, "Школа йоги")
+	assert.Len(t, rs.requests, 3)
+}
+
+func TestAnthropicClient_GenerateLandingSchema_GivesUpAfterMaxRetries(t *testing.T) {
+	rs, server := newReplayServer(t,
+		recordedResponse{status: statusOverloaded, fixture: "overloaded.json"},
+		recordedResponse{status: statusOverloaded, fixture: "overloaded.json"},
+		recordedResponse{status: statusOverloaded, fixture: "overloaded.json"},
+		recordedResponse{status: statusOverloaded, fixture: "overloaded.json"},
+	)
+	client := newTestAnthropicClient(server.URL)
+
+	_, err := client.GenerateLandingSchema(context.Background(), "prompt", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "after 3 retries")
+	assert.Len(t, rs.requests, 4)
+}
+
+func TestAnthropicClient_GenerateLandingSchema_DoesNotRetryClientErrors(t *testing.T) {
+	rs, server := newReplayServer(t, recordedResponse{status: http.StatusBadRequest, fixture: "invalid_request.json"})
+	client := newTestAnthropicClient(server.URL)
+
+	_, err := client.GenerateLandingSchema(context.Background(), "prompt", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max_tokens: Field required")
+	assert.Len(t, rs.requests, 1)
+}
+
+func TestAnthropicClient_GenerateLandingSchema_HonoursRetryAfter(t *testing.T) {
+	_, server := newReplayServer(t,
+		recordedResponse{status: http.StatusTooManyRequests, fixture: "rate_limited.json", headers: map[string]string{"retry-after": "30"}},
+	)
+	client := newTestAnthropicClient(server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GenerateLandingSchema(ctx, "prompt", "")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestAnthropicClient_GenerateLandingSchema_NoToolCall(t *testing.T) {
+	_, server := newReplayServer(t, recordedResponse{status: http.StatusOK, fixture: "text_only.json"})
+	client := newTestAnthropicClient(server.URL)
+
+	_, err := client.GenerateLandingSchema(context.Background(), "prompt", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no emit_landing_schema tool call")
+}
+
+func TestAnthropicClient_GenerateBlockProps(t *testing.T) {
+	rs, server := newReplayServer(t, recordedResponse{status: http.StatusOK, fixture: "block_tool_use.json"})
+	client := newTestAnthropicClient(server.URL)
+
+	props, err := client.GenerateBlockProps(context.Background(), "pricing", `{"title":"Тарифы"}`, "Сделай тарифы абонементами")
+	require.NoError(t, err)
+
+	require.Len(t, rs.requests, 1)
+	assert.Equal(t, blockToolName, rs.requests[0].ToolChoice.Name)
+	assert.Contains(t, rs.requests[0].System, "pricing")
+	assert.Contains(t, rs.requests[0].Messages[0].Content, `{"title":"Тарифы"}`)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(props), &decoded))
+	assert.Equal(t, "Абонементы", decoded["title"])
+	assert.Len(t, decoded["plans"], 2)
+}
@@ -13,6 +13,8 @@ import (
 // Client интерфейс для AI клиента
 type Client interface {
 	GenerateLandingSchema(ctx context.Context, prompt, paymentURL string) (string, error)
+	// GenerateBlockProps переписывает props одного блока по инструкции и возвращает JSON-объект props
+	GenerateBlockProps(ctx context.Context, blockType, propsJSON, instruction string) (string, error)
 }
 
 // MockClient мок-реализация AI клиента для разработки и тестирования
@@ -208,6 +210,33 @@ func (c *MockClient) GenerateLandingSchema(ctx context.Context, prompt, paymentU
 	return string(schemaJSON), nil
 }
 
+// GenerateBlockProps возвращает исходные props с заголовком блока, взятым из инструкции
+func (c *MockClient) GenerateBlockProps(ctx context.Context, blockType, propsJSON, instruction string) (string, error) {
+	logger.WithContext(ctx).Info("generating block props",
+		zap.String("block_type", blockType),
+		zap.String("instruction", instruction),
+	)
+
+	props := map[string]interface{}{}
+	if strings.TrimSpace(propsJSON) != "" {
+		if err := json.Unmarshal([]byte(propsJSON), &props); err != nil {
+			return "", fmt.Errorf("invalid block props: %w", err)
+		}
+	}
+
+	titleKey := "title"
+	if blockType == "hero" {
+		titleKey = "headline"
+	}
+	props[titleKey] = extractTitle(instruction)
+
+	result, err := json.Marshal(props)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal props: %w", err)
+	}
+	return string(result), nil
+}
+
 // extractTitle извлекает заголовок из промпта (упрощённая логика)
 func extractTitle(prompt string) string {
 	if prompt == "" {
@@ -0,0 +1,260 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/landly/backend/internal/logger"
+	"go.uber.org/zap"
+)
+
+const defaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+// OpenAIConfig конфигурация OpenAI-совместимого клиента
+type OpenAIConfig struct {
+	APIKey      string
+	Model       string
+	BaseURL     string
+	MaxTokens   int
+	Temperature float64
+}
+
+// OpenAIClient клиент для любого OpenAI-совместимого chat-completions API
+type OpenAIClient struct {
+	cfg        OpenAIConfig
+	httpClient *http.Client
+}
+
+// NewOpenAIClient создаёт новый OpenAI-совместимый клиент
+func NewOpenAIClient(cfg OpenAIConfig, opts ...Option) *OpenAIClient {
+	o := applyOptions(opts)
+
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultOpenAIBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+
+	return &OpenAIClient{
+		cfg:        cfg,
+		httpClient: o.httpClient,
+	}
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIResponseFormat struct {
+	Type string `json:"type"`
+}
+
+type openAIChatRequest struct {
+	Model          string               `json:"model"`
+	Messages       []openAIMessage      `json:"messages"`
+	MaxTokens      int                  `json:"max_tokens,omitempty"`
+	Temperature    float64              `json:"temperature"`
+	ResponseFormat openAIResponseFormat `json:"response_format"`
+	Stream         bool                 `json:"stream,omitempty"`
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message      openAIMessage `json:"message"`
+		FinishReason string        `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+type openAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+type openAIErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
+
+// GenerateLandingSchema запрашивает у модели JSON-схему лендинга в JSON-режиме
+func (c *OpenAIClient) GenerateLandingSchema(ctx context.Context, prompt, paymentURL string) (string, error) {
+	log := logger.WithContext(ctx).With(zap.String("model", c.cfg.Model))
+
+	content, err := c.complete(ctx, []openAIMessage{
+		{Role: "system", Content: buildSystemPrompt()},
+		{Role: "user", Content: buildUserPrompt(prompt, paymentURL)},
+	})
+	if err != nil {
+		log.Error("openai request failed", zap.Error(err))
+		return "", err
+	}
+
+	schemaJSON, err := normalizeSchema(content, paymentURL)
+	if err != nil {
+		log.Error("openai returned invalid schema", zap.Error(err))
+		return "", err
+	}
+
+	log.Info("schema generated successfully", zap.Int("schema_length", len(schemaJSON)))
+	return schemaJSON, nil
+}
+
+// GenerateBlockProps переписывает props одного блока в JSON-режиме
+func (c *OpenAIClient) GenerateBlockProps(ctx context.Context, blockType, propsJSON, instruction string) (string, error) {
+	log := logger.WithContext(ctx).With(zap.String("model", c.cfg.Model), zap.String("block_type", blockType))
+
+	content, err := c.complete(ctx, []openAIMessage{
+		{Role: "system", Content: buildBlockSystemPrompt(blockType)},
+		{Role: "user", Content: buildBlockUserPrompt(propsJSON, instruction)},
+	})
+	if err != nil {
+		log.Error("openai request failed", zap.Error(err))
+		return "", err
+	}
+
+	props, err := normalizeBlockProps(content)
+	if err != nil {
+		log.Error("openai returned invalid block props", zap.Error(err))
+		return "", err
+	}
+
+	return props, nil
+}
+
+// StreamLandingSchema запрашивает схему в потоковом режиме, передавая фрагменты в onChunk
+func (c *OpenAIClient) StreamLandingSchema(ctx context.Context, prompt, paymentURL string, onChunk func(chunk string)) (string, error) {
+	log := logger.WithContext(ctx).With(zap.String("model", c.cfg.Model))
+
+	resp, err := c.send(ctx, []openAIMessage{
+		{Role: "system", Content: buildSystemPrompt()},
+		{Role: "user", Content: buildUserPrompt(prompt, paymentURL)},
+	}, true)
+	if err != nil {
+		log.Error("openai stream request failed", zap.Error(err))
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var content strings.Builder
+	var finishReason string
+	err = readServerSentEvents(resp.Body, func(_, data string) error {
+		if data == "[DONE]" {
+			return nil
+		}
+
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to decode stream chunk: %w", err)
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				content.WriteString(choice.Delta.Content)
+				onChunk(choice.Delta.Content)
+			}
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("openai stream failed", zap.Error(err))
+		return "", err
+	}
+
+	if finishReason == "length" {
+		return "", fmt.Errorf("openai response truncated: increase max_tokens")
+	}
+
+	schemaJSON, err := normalizeSchema(content.String(), paymentURL)
+	if err != nil {
+		log.Error("openai returned invalid schema", zap.Error(err))
+		return "", err
+	}
+
+	log.Info("schema streamed successfully", zap.Int("schema_length", len(schemaJSON)))
+	return schemaJSON, nil
+}
+
+func (c *OpenAIClient) complete(ctx context.Context, messages []openAIMessage) (string, error) {
+	resp, err := c.send(ctx, messages, false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var chatResp openAIChatResponse
+	if err := json.Unmarshal(respBody, &chatResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(chatResp.Choices) == 0 {
+		return "", fmt.Errorf("openai returned no choices")
+	}
+
+	choice := chatResp.Choices[0]
+	if choice.FinishReason == "length" {
+		return "", fmt.Errorf("openai response truncated: increase max_tokens")
+	}
+
+	return choice.Message.Content, nil
+}
+
+// send выполняет запрос к chat/completions и возвращает ответ со статусом 200
+func (c *OpenAIClient) send(ctx context.Context, messages []openAIMessage, stream bool) (*http.Response, error) {
+	body, err := json.Marshal(openAIChatRequest{
+		Model:          c.cfg.Model,
+		Messages:       messages,
+		MaxTokens:      c.cfg.MaxTokens,
+		Temperature:    c.cfg.Temperature,
+		ResponseFormat: openAIResponseFormat{Type: "json_object"},
+		Stream:         stream,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+	if c.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("openai request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		var apiErr openAIErrorResponse
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error.Message != "" {
+			return nil, fmt.Errorf("openai API error (status %d): %s", resp.StatusCode, apiErr.Error.Message)
+		}
+		return nil, fmt.Errorf("openai API error (status %d)", resp.StatusCode)
+	}
+
+	return resp, nil
+}
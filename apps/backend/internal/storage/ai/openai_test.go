@@ -0,0 +1,113 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchemaContent = `{"version":"1.0","pages":[{"path":"/","title":"Кофейня","blocks":[{"type":"hero","order":0,"props":{"headline":"Кофе"}}]}]}`
+
+func TestOpenAIClient_GenerateLandingSchema_Success(t *testing.T) {
+	var captured openAIChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&captured))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{
+					"message":       map[string]string{"role": "assistant", "content": testSchemaContent},
+					"finish_reason": "stop",
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(OpenAIConfig{
+		APIKey:      "test-key",
+		Model:       "gpt-test",
+		BaseURL:     server.URL + "/v1/",
+		MaxTokens:   1000,
+		Temperature: 0.5,
+	})
+
+	schemaJSON, err := client.GenerateLandingSchema(context.Background(), "Лендинг кофейни", "https://pay.example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, "gpt-test", captured.Model)
+	assert.Equal(t, "json_object", captured.ResponseFormat.Type)
+	assert.Equal(t, 1000, captured.MaxTokens)
+	require.Len(t, captured.Messages, 2)
+	assert.Equal(t, "system", captured.Messages[0].Role)
+	assert.Contains(t, captured.Messages[1].Content, "Лендинг кофейни")
+	assert.Contains(t, captured.Messages[1].Content, "https://pay.example.com")
+
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(schemaJSON), &schema))
+	assert.Equal(t, "1.0", schema["version"])
+	payment, ok := schema["payment"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "https://pay.example.com", payment["url"])
+}
+
+func TestOpenAIClient_GenerateLandingSchema_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":{"message":"Incorrect API key","type":"invalid_request_error"}}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(OpenAIConfig{Model: "gpt-test", BaseURL: server.URL})
+
+	_, err := client.GenerateLandingSchema(context.Background(), "prompt", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Incorrect API key")
+}
+
+func TestOpenAIClient_GenerateLandingSchema_InvalidContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"not json"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(OpenAIConfig{Model: "gpt-test", BaseURL: server.URL})
+
+	_, err := client.GenerateLandingSchema(context.Background(), "prompt", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid JSON")
+}
+
+func TestNormalizeSchema_StripsCodeFence(t *testing.T) {
+	schemaJSON, err := normalizeSchema("```json\n"+testSchemaContent+"\n```", "")
+	require.NoError(t, err)
+	assert.Contains(t, schemaJSON, "Кофейня")
+	assert.NotContains(t, schemaJSON, "payment")
+}
+
+func TestOpenAIClient_GenerateBlockProps_UnwrapsBlock(t *testing.T) {
+	var captured openAIChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&captured))
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"type\":\"cta\",\"props\":{\"title\":\"Запишитесь сегодня\"}}"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(OpenAIConfig{Model: "gpt-test", BaseURL: server.URL})
+
+	props, err := client.GenerateBlockProps(context.Background(), "cta", `{"title":"Готовы?"}`, "Сделай призыв настойчивее")
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"title":"Запишитесь сегодня"}`, props)
+	require.Len(t, captured.Messages, 2)
+	assert.Contains(t, captured.Messages[0].Content, "Тип блока: cta")
+	assert.Contains(t, captured.Messages[1].Content, `{"title":"Готовы?"}`)
+}
@@ -0,0 +1,50 @@
+package ai
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	defaultRequestTimeout = 120 * time.Second
+	defaultMaxRetries     = 3
+	defaultRetryBaseDelay = time.Second
+)
+
+type options struct {
+	httpClient     *http.Client
+	maxRetries     int
+	retryBaseDelay time.Duration
+}
+
+// Option конфигурирует HTTP-клиенты AI провайдеров.
+type Option func(*options)
+
+// WithHTTPClient позволяет подменить HTTP клиент (используется в тестах).
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithRetryPolicy задаёт число повторов и базовую задержку экспоненциального backoff.
+func WithRetryPolicy(maxRetries int, baseDelay time.Duration) Option {
+	return func(o *options) {
+		o.maxRetries = maxRetries
+		o.retryBaseDelay = baseDelay
+	}
+}
+
+func applyOptions(opts []Option) options {
+	o := options{
+		maxRetries:     defaultMaxRetries,
+		retryBaseDelay: defaultRetryBaseDelay,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.httpClient == nil {
+		o.httpClient = &http.Client{Timeout: defaultRequestTimeout}
+	}
+	return o
+}
@@ -0,0 +1,135 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/landly/backend/internal/blocks"
+)
+
+// supportedBlockTypes типы блоков из реестра, которые умеет рендерить StaticRenderer
+func supportedBlockTypes() []string {
+	return blocks.Default.Types()
+}
+
+// buildSystemPrompt формирует системную инструкцию с описанием контракта схемы
+func buildSystemPrompt() string {
+	var sb strings.Builder
+	sb.WriteString("Ты — генератор лендингов. Отвечай строго одним JSON-объектом без markdown и пояснений.\n")
+	sb.WriteString("Формат ответа:\n")
+	sb.WriteString(`{"version":"1.0","pages":[{"path":"/","title":"...","description":"...","blocks":[{"type":"hero","order":0,"props":{...}}]}],`)
+	sb.WriteString(`"theme":{"palette":{"primary":"#RRGGBB","secondary":"#RRGGBB","accent":"#RRGGBB","background":"#RRGGBB","text":"#RRGGBB"},"font":"inter","borderRadius":"lg"}}`)
+	sb.WriteString("\n\nДопустимые типы блоков: ")
+	sb.WriteString(strings.Join(supportedBlockTypes(), ", "))
+	sb.WriteString(".\n")
+	sb.WriteString("Свойства блоков:\n")
+	for _, def := range blocks.Default.Definitions() {
+		if def.Hint != "" {
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", def.Type, def.Hint))
+		}
+	}
+	sb.WriteString("Поле order — целое число, начиная с 0. Тексты пиши на языке запроса пользователя.")
+	return sb.String()
+}
+
+// buildUserPrompt формирует пользовательское сообщение для модели
+func buildUserPrompt(prompt, paymentURL string) string {
+	if paymentURL == "" {
+		return prompt
+	}
+	return fmt.Sprintf("%s\n\nСсылка на оплату: %s", prompt, paymentURL)
+}
+
+// buildBlockSystemPrompt формирует системную инструкцию для переписывания одного блока
+func buildBlockSystemPrompt(blockType string) string {
+	var sb strings.Builder
+	sb.WriteString("Ты — редактор одного блока лендинга. Отвечай строго одним JSON-объектом без markdown и пояснений.\n")
+	sb.WriteString(fmt.Sprintf("Тип блока: %s.\n", blockType))
+	if def, ok := blocks.Default.Get(blockType); ok {
+		if def.Hint != "" {
+			sb.WriteString(fmt.Sprintf("Свойства блока: %s.\n", def.Hint))
+		}
+		sb.WriteString(fmt.Sprintf("Пример props: %s\n", def.Example))
+	}
+	sb.WriteString("Верни только новый объект props этого блока: без type, order и других блоков. ")
+	sb.WriteString("Сохраняй свойства, которые пользователь не просил менять. Тексты пиши на языке запроса пользователя.")
+	return sb.String()
+}
+
+// buildBlockUserPrompt формирует запрос на изменение свойств блока
+func buildBlockUserPrompt(propsJSON, instruction string) string {
+	return fmt.Sprintf("Текущие props блока (JSON):\n%s\n\nЗапрос пользователя:\n%s", propsJSON, instruction)
+}
+
+// normalizeBlockProps проверяет, что ответ модели — JSON-объект props, и возвращает его в компактном виде
+func normalizeBlockProps(raw string) (string, error) {
+	content := stripCodeFence(raw)
+
+	var props map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &props); err != nil {
+		return "", fmt.Errorf("model returned invalid JSON: %w", err)
+	}
+
+	// Некоторые модели возвращают блок целиком вместо props
+	if nested, ok := props["props"].(map[string]interface{}); ok {
+		props = nested
+	}
+
+	propsJSON, err := json.Marshal(props)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal props: %w", err)
+	}
+
+	return string(propsJSON), nil
+}
+
+// normalizeSchema проверяет, что ответ модели — JSON-объект, и дополняет его платёжными данными
+func normalizeSchema(raw, paymentURL string) (string, error) {
+	content := stripCodeFence(raw)
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &schema); err != nil {
+		return "", fmt.Errorf("model returned invalid JSON: %w", err)
+	}
+
+	if _, ok := schema["pages"].([]interface{}); !ok {
+		return "", fmt.Errorf("model returned schema without pages")
+	}
+
+	if _, ok := schema["version"]; !ok {
+		schema["version"] = "1.0"
+	}
+
+	if paymentURL != "" {
+		payment, _ := schema["payment"].(map[string]interface{})
+		if payment == nil {
+			payment = map[string]interface{}{"buttonText": "Оплатить"}
+		}
+		payment["url"] = paymentURL
+		schema["payment"] = payment
+	}
+
+	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	return string(schemaJSON), nil
+}
+
+// stripCodeFence убирает markdown-обёртку ```json ... ```, которую иногда добавляют модели
+func stripCodeFence(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "```") {
+		return trimmed
+	}
+
+	trimmed = strings.TrimPrefix(trimmed, "```")
+	if idx := strings.Index(trimmed, "\n"); idx >= 0 {
+		trimmed = trimmed[idx+1:]
+	}
+	trimmed = strings.TrimSuffix(strings.TrimSpace(trimmed), "```")
+
+	return strings.TrimSpace(trimmed)
+}
@@ -0,0 +1,35 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/landly/backend/internal/blocks"
+)
+
+func TestBuildSystemPrompt_AdvertisesOnlyRegisteredBlocks(t *testing.T) {
+	const marker = "Допустимые типы блоков: "
+	prompt := buildSystemPrompt()
+	start := strings.Index(prompt, marker)
+	require.GreaterOrEqual(t, start, 0)
+	line := prompt[start+len(marker):]
+	line = line[:strings.Index(line, ".\n")]
+
+	advertised := strings.Split(line, ", ")
+	assert.ElementsMatch(t, blocks.Default.Types(), advertised)
+	for _, blockType := range advertised {
+		_, ok := blocks.Default.Get(blockType)
+		assert.True(t, ok, "advertised block %q has no renderer", blockType)
+	}
+}
+
+func TestLandingToolInputSchema_BlockTypesFromRegistry(t *testing.T) {
+	pages := landingToolInputSchema()["properties"].(map[string]interface{})["pages"].(map[string]interface{})
+	page := pages["items"].(map[string]interface{})["properties"].(map[string]interface{})
+	block := page["blocks"].(map[string]interface{})["items"].(map[string]interface{})["properties"].(map[string]interface{})
+
+	assert.ElementsMatch(t, blocks.Default.Types(), block["type"].(map[string]interface{})["enum"])
+}
@@ -0,0 +1,92 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+)
+
+// mockChunkSize размер фрагмента (в рунах), которым мок-клиент отдаёт схему
+const mockChunkSize = 48
+
+// StreamingClient AI клиент, умеющий отдавать схему по мере генерации:
+// onChunk получает очередной фрагмент ответа модели.
+// Реализация опциональна: вызывающий код проверяет её через type assertion.
+type StreamingClient interface {
+	Client
+	StreamLandingSchema(ctx context.Context, prompt, paymentURL string, onChunk func(chunk string)) (string, error)
+}
+
+var (
+	_ StreamingClient = (*MockClient)(nil)
+	_ StreamingClient = (*OpenAIClient)(nil)
+	_ StreamingClient = (*AnthropicClient)(nil)
+)
+
+// StreamLandingSchema отдаёт предсказуемую схему фрагментами, имитируя потоковую генерацию
+func (c *MockClient) StreamLandingSchema(ctx context.Context, prompt, paymentURL string, onChunk func(chunk string)) (string, error) {
+	schemaJSON, err := c.GenerateLandingSchema(ctx, prompt, paymentURL)
+	if err != nil {
+		return "", err
+	}
+
+	runes := []rune(schemaJSON)
+	for start := 0; start < len(runes); start += mockChunkSize {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		end := start + mockChunkSize
+		if end > len(runes) {
+			end = len(runes)
+		}
+		onChunk(string(runes[start:end]))
+	}
+
+	return schemaJSON, nil
+}
+
+// readServerSentEvents разбирает поток text/event-stream и вызывает fn для каждого события
+func readServerSentEvents(r io.Reader, fn func(event, data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var event string
+	var data strings.Builder
+
+	dispatch := func() error {
+		if data.Len() == 0 {
+			event = ""
+			return nil
+		}
+		err := fn(event, data.String())
+		event = ""
+		data.Reset()
+		return err
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// комментарий / keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return dispatch()
+}
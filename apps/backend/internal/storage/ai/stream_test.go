@@ -0,0 +1,96 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockClient_StreamLandingSchema_EmitsChunks(t *testing.T) {
+	client := NewMockClient()
+
+	var chunks []string
+	schemaJSON, err := client.StreamLandingSchema(context.Background(), "Кофейня", "", func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	require.NoError(t, err)
+
+	assert.Greater(t, len(chunks), 1)
+	assert.Equal(t, schemaJSON, strings.Join(chunks, ""))
+}
+
+func TestOpenAIClient_StreamLandingSchema(t *testing.T) {
+	var captured openAIChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&captured))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		parts := []string{testSchemaContent[:30], testSchemaContent[30:80], testSchemaContent[80:]}
+		for _, part := range parts {
+			chunk, _ := json.Marshal(map[string]interface{}{
+				"choices": []map[string]interface{}{{"delta": map[string]string{"content": part}}},
+			})
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(OpenAIConfig{Model: "gpt-test", BaseURL: server.URL})
+
+	var chunks []string
+	schemaJSON, err := client.StreamLandingSchema(context.Background(), "prompt", "", func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	require.NoError(t, err)
+
+	assert.True(t, captured.Stream)
+	assert.Len(t, chunks, 3)
+	assert.Equal(t, testSchemaContent, strings.Join(chunks, ""))
+	assert.Contains(t, schemaJSON, "Кофейня")
+}
+
+func TestOpenAIClient_StreamLandingSchema_Truncated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"{\\\"pages\\\"\"},\"finish_reason\":\"length\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(OpenAIConfig{Model: "gpt-test", BaseURL: server.URL})
+
+	_, err := client.StreamLandingSchema(context.Background(), "prompt", "", func(string) {})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "truncated")
+}
+
+func TestAnthropicClient_StreamLandingSchema(t *testing.T) {
+	rs, server := newReplayServer(t,
+		recordedResponse{status: http.StatusTooManyRequests, fixture: "rate_limited.json"},
+		recordedResponse{status: http.StatusOK, fixture: "stream_tool_use.sse"},
+	)
+	client := newTestAnthropicClient(server.URL)
+
+	var chunks []string
+	schemaJSON, err := client.StreamLandingSchema(context.Background(), "prompt", "https://pay.example.com", func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	require.NoError(t, err)
+
+	require.Len(t, rs.requests, 2)
+	assert.True(t, rs.requests[1].Stream)
+	assert.Len(t, chunks, 3)
+
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(schemaJSON), &schema))
+	assert.Equal(t, "Школа йоги", schema["pages"].([]interface{})[0].(map[string]interface{})["title"])
+	assert.Equal(t, "https://pay.example.com", schema["payment"].(map[string]interface{})["url"])
+}
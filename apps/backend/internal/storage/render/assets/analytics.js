@@ -0,0 +1,78 @@
+// Analytics tracking
+(function () {
+    var endpoint = __LANDLY_ANALYTICS_ENDPOINT__;
+    // Вариант A/B-теста, из сборки которого отдана страница; пусто — эксперимента нет
+    var variant = __LANDLY_ANALYTICS_VARIANT__;
+    var maxBatch = 50;
+    var queue = [];
+    var timer = null;
+
+    // Путь страницы относительно корня сайта: analytics.js лежит в корне
+    var script = document.currentScript;
+    var rootPath = script ? new URL('.', script.src).pathname : '/';
+
+    function pagePath() {
+        var path = window.location.pathname;
+        if (rootPath !== '/' && path.indexOf(rootPath) === 0) {
+            path = '/' + path.slice(rootPath.length);
+        }
+        return path;
+    }
+
+    function send(body) {
+        // text/plain не требует CORS preflight, поэтому beacon работает и с других доменов
+        if (navigator.sendBeacon && navigator.sendBeacon(endpoint + '/events', new Blob([body], { type: 'text/plain' }))) {
+            return;
+        }
+        fetch(endpoint + '/events', {
+            method: 'POST',
+            headers: { 'Content-Type': 'text/plain' },
+            body: body,
+            keepalive: true
+        }).catch(function () {});
+    }
+
+    function flush() {
+        if (timer) {
+            clearTimeout(timer);
+            timer = null;
+        }
+        while (queue.length) {
+            send(JSON.stringify({ events: queue.splice(0, maxBatch) }));
+        }
+    }
+
+    function track(eventType) {
+        queue.push({
+            event_type: eventType,
+            path: pagePath(),
+            url: window.location.href,
+            referrer: document.referrer,
+            variant: variant
+        });
+        if (queue.length >= 10) {
+            flush();
+        } else if (!timer) {
+            timer = setTimeout(flush, 2000);
+        }
+    }
+
+    // Track pageview
+    track('pageview');
+
+    // Track button clicks
+    document.addEventListener('click', function (e) {
+        var el = e.target.closest ? e.target.closest('[data-track]') : e.target;
+        if (el && el.dataset && el.dataset.track) {
+            track(el.dataset.track);
+        }
+    });
+
+    // Уходя со страницы, отправляем накопленное
+    document.addEventListener('visibilitychange', function () {
+        if (document.visibilityState === 'hidden') {
+            flush();
+        }
+    });
+    window.addEventListener('pagehide', flush);
+})();
@@ -2,6 +2,7 @@ package render
 
 import (
 	"context"
+	"flag"
 	"os"
 	"path/filepath"
 	"testing"
@@ -9,6 +10,10 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/landly/backend/internal/blocks"
+	_ "github.com/landly/backend/internal/blocks/form"
+	domain "github.com/landly/backend/internal/models"
 )
 
 func TestStaticRenderer_RenderStatic_Success(t *testing.T) {
@@ -35,7 +40,7 @@ func TestStaticRenderer_RenderStatic_Success(t *testing.T) {
 		]
 	}`
 
-	buildDir, err := renderer.RenderStatic(context.Background(), projectID, schemaJSON)
+	buildDir, err := renderer.RenderStatic(context.Background(), projectID, schemaJSON, "")
 	require.NoError(t, err)
 	assert.Contains(t, buildDir, projectID.String())
 
@@ -84,7 +89,7 @@ func TestStaticRenderer_RenderStatic_MultiplePages(t *testing.T) {
 		]
 	}`
 
-	buildDir, err := renderer.RenderStatic(context.Background(), projectID, schemaJSON)
+	buildDir, err := renderer.RenderStatic(context.Background(), projectID, schemaJSON, "")
 	require.NoError(t, err)
 
 	// Verify both pages
@@ -103,7 +108,7 @@ func TestStaticRenderer_RenderStatic_InvalidJSON(t *testing.T) {
 	projectID := uuid.New()
 	schemaJSON := `{invalid json`
 
-	_, err := renderer.RenderStatic(context.Background(), projectID, schemaJSON)
+	_, err := renderer.RenderStatic(context.Background(), projectID, schemaJSON, "")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to parse schema")
 }
@@ -115,11 +120,23 @@ func TestStaticRenderer_RenderStatic_InvalidPagesStructure(t *testing.T) {
 	projectID := uuid.New()
 	schemaJSON := `{"pages": "not an array"}`
 
-	_, err := renderer.RenderStatic(context.Background(), projectID, schemaJSON)
+	_, err := renderer.RenderStatic(context.Background(), projectID, schemaJSON, "")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid pages structure")
 }
 
+func TestStaticRenderer_RenderStatic_MalformedPage(t *testing.T) {
+	tmpDir := t.TempDir()
+	renderer := NewStaticRenderer(tmpDir)
+
+	projectID := uuid.New()
+	schemaJSON := `{"pages": [{"path": "/", "title": 42, "blocks": []}]}`
+
+	_, err := renderer.RenderStatic(context.Background(), projectID, schemaJSON, "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "title must be a string")
+}
+
 func TestStaticRenderer_RenderBlock_Hero(t *testing.T) {
 	renderer := NewStaticRenderer("/tmp")
 
@@ -177,6 +194,83 @@ func TestStaticRenderer_RenderBlock_Pricing(t *testing.T) {
 	assert.Contains(t, html, "https://payment.example.com")
 }
 
+// staticPaymentLinks отдаёт одну ссылку для любого проекта
+type staticPaymentLinks string
+
+func (l staticPaymentLinks) PaymentURL(context.Context, uuid.UUID) (string, error) {
+	return string(l), nil
+}
+
+func TestStaticRenderer_RenderStatic_UsesIntegrationPaymentLink(t *testing.T) {
+	renderer := NewStaticRenderer(t.TempDir())
+	renderer.SetPaymentLinks(staticPaymentLinks("https://buy.stripe.com/shop"))
+
+	schemaJSON := `{
+		"payment": {"url": "https://old.example.com/pay"},
+		"pages": [{"path": "/", "title": "Home", "blocks": [{"type": "pricing", "props": {"plans": [
+			{"name": "Basic", "price": "49", "currency": "USD"},
+			{"name": "Pro", "price": "99", "currency": "USD", "url": "https://example.com/pro"}
+		]}}]}]
+	}`
+	projectID := uuid.New()
+	buildDir, err := renderer.RenderStatic(context.Background(), projectID, schemaJSON, "https://landly.test/sites/shop")
+	require.NoError(t, err)
+
+	indexHTML, err := os.ReadFile(filepath.Join(buildDir, "index.html"))
+	require.NoError(t, err)
+	page := string(indexHTML)
+	assert.Contains(t, page, `href="https://buy.stripe.com/shop?client_reference_id=`+projectID.String()+`"`)
+	assert.Contains(t, page, `href="https://example.com/pro"`, "plan URL wins over the integration")
+	assert.NotContains(t, page, "old.example.com")
+	assert.Contains(t, page, `"url":"https://buy.stripe.com/shop"`, "offer markup uses the same link")
+}
+
+func TestStaticRenderer_RenderVariant_StripeClientReference(t *testing.T) {
+	renderer := NewStaticRenderer(t.TempDir())
+	renderer.SetPaymentLinks(staticPaymentLinks("https://buy.stripe.com/shop?locale=ru"))
+
+	schemaJSON := `{
+		"pages": [{"path": "/", "title": "Home", "blocks": [{"type": "pricing", "props": {"plans": [
+			{"name": "Basic", "price": "49"},
+			{"name": "Team", "price": "99", "url": "https://buy.stripe.com/team?client_reference_id=manual"},
+			{"name": "Pro", "price": "199", "url": "https://paypal.me/shop"}
+		]}}]}]
+	}`
+	projectID := uuid.New()
+	buildDir, err := renderer.RenderVariant(context.Background(), projectID, domain.VariantControl, schemaJSON, "")
+	require.NoError(t, err)
+
+	indexHTML, err := os.ReadFile(filepath.Join(buildDir, "index.html"))
+	require.NoError(t, err)
+	page := string(indexHTML)
+	ref := domain.ClientReferenceID(projectID, domain.VariantControl)
+	assert.Contains(t, page, `href="https://buy.stripe.com/shop?client_reference_id=`+ref+`&amp;locale=ru"`)
+	assert.Contains(t, page, `href="https://buy.stripe.com/team?client_reference_id=manual"`, "an explicit reference is kept")
+	assert.Contains(t, page, `href="https://paypal.me/shop"`, "only Stripe links are tagged")
+
+	projectIDRef, variant, ok := domain.ParseClientReferenceID(ref)
+	require.True(t, ok)
+	assert.Equal(t, projectID, projectIDRef)
+	assert.Equal(t, domain.VariantControl, variant)
+}
+
+func TestStaticRenderer_RenderVariant_FormEndpoint(t *testing.T) {
+	renderer := NewStaticRenderer(t.TempDir())
+	renderer.SetAPIBaseURL("https://api.landly.test/")
+
+	schemaJSON := `{
+		"pages": [{"path": "/contacts", "title": "Contacts", "blocks": [{"type": "form", "props": {"fields": [{"name": "email", "type": "email"}]}}]}]
+	}`
+	projectID := uuid.New()
+	buildDir, err := renderer.RenderVariant(context.Background(), projectID, domain.VariantControl, schemaJSON, "")
+	require.NoError(t, err)
+
+	indexHTML, err := os.ReadFile(filepath.Join(buildDir, "contacts", "index.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(indexHTML), `data-endpoint="/v1/forms/`+projectID.String()+`/submissions"`)
+	assert.Contains(t, string(indexHTML), `data-path="/contacts" data-variant="control"`)
+}
+
 func TestStaticRenderer_RenderBlock_CTA(t *testing.T) {
 	renderer := NewStaticRenderer("/tmp")
 
@@ -241,6 +335,133 @@ func TestStaticRenderer_RenderBlock_Unknown(t *testing.T) {
 	assert.Contains(t, html, "Блок unknown пока не поддерживается")
 }
 
+var updateGolden = flag.Bool("update", false, "перезаписать golden-файлы в testdata/golden")
+
+// assertGolden сравнивает HTML блока с testdata/golden/<name>.html
+func assertGolden(t *testing.T, name, actual string) {
+	t.Helper()
+
+	goldenPath := filepath.Join("testdata", "golden", name+".html")
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll(filepath.Dir(goldenPath), 0755))
+		require.NoError(t, os.WriteFile(goldenPath, []byte(actual+"\n"), 0644))
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	require.NoError(t, err, "golden file missing, run go test -update")
+	assert.Equal(t, string(expected), actual+"\n")
+}
+
+func TestStaticRenderer_RenderBlock_Golden(t *testing.T) {
+	renderer := NewStaticRenderer("/tmp")
+
+	cases := []struct {
+		name      string
+		blockType string
+		props     map[string]interface{}
+	}{
+		{
+			name:      "gallery",
+			blockType: "gallery",
+			props: map[string]interface{}{
+				"title":    "Наши работы",
+				"subtitle": "Проекты за последний год",
+				"columns":  float64(2),
+				"items": []interface{}{
+					map[string]interface{}{"image": "https://cdn.example.com/1.jpg", "caption": "Кухня в стиле лофт"},
+					map[string]interface{}{"image": "https://cdn.example.com/2.jpg", "alt": "Гостиная"},
+					map[string]interface{}{"caption": "Без картинки пропускается"},
+				},
+			},
+		},
+		{
+			name:      "gallery_empty",
+			blockType: "gallery",
+			props:     map[string]interface{}{"columns": float64(7)},
+		},
+		{
+			name:      "about",
+			blockType: "about",
+			props: map[string]interface{}{
+				"title": "О студии",
+				"text":  "Мы проектируем интерьеры с 2015 года.\nРаботаем по всей России.",
+				"image": "https://cdn.example.com/studio.jpg",
+				"stats": []interface{}{
+					map[string]interface{}{"value": "250+", "label": "проектов"},
+				},
+				"team": []interface{}{
+					map[string]interface{}{"name": "Анна Смирнова", "role": "Основатель", "bio": "Архитектор <и> дизайнер", "photo": "https://cdn.example.com/anna.jpg"},
+					map[string]interface{}{"name": "Иван Петров", "role": "Дизайнер"},
+				},
+			},
+		},
+		{
+			name:      "about_empty",
+			blockType: "about",
+			props:     map[string]interface{}{},
+		},
+		{
+			name:      "contact",
+			blockType: "contact",
+			props: map[string]interface{}{
+				"title":       "Свяжитесь с нами",
+				"description": "Ответим в течение часа",
+				"email":       "hello@example.com",
+				"phone":       "+7 (999) 123-45-67",
+				"address":     "Москва, ул. Тверская, 1",
+				"hours":       "Пн–Пт 10:00–19:00",
+				"showMap":     true,
+				"socials": []interface{}{
+					map[string]interface{}{"label": "Telegram", "url": "https://t.me/example"},
+					map[string]interface{}{"label": "", "url": "https://vk.com/example"},
+				},
+			},
+		},
+		{
+			name:      "contact_insecure_map",
+			blockType: "contact",
+			props: map[string]interface{}{
+				"email":       "hello@example.com",
+				"mapEmbedUrl": "http://maps.example.com/embed",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assertGolden(t, tc.name, renderer.renderBlock(tc.blockType, tc.props, nil))
+		})
+	}
+}
+
+func TestStaticRenderer_CustomRegistry(t *testing.T) {
+	registry := blocks.NewRegistry()
+	require.NoError(t, registry.Register(blocks.Definition{
+		Type: "banner",
+		CSS:  ".landing-banner{color:red}",
+		Renderer: blocks.RendererFunc(func(props map[string]interface{}, rc blocks.RenderContext) string {
+			return `<section class="landing-banner">` + blocks.StringProp(props, "text", "") + `</section>`
+		}),
+	}))
+
+	renderer := NewStaticRenderer(t.TempDir())
+	renderer.SetRegistry(registry)
+
+	assert.Equal(t, `<section class="landing-banner">Скидки</section>`, renderer.renderBlock("banner", map[string]interface{}{"text": "Скидки"}, nil))
+	assert.Contains(t, renderer.renderBlock("hero", map[string]interface{}{}, nil), "Блок hero пока не поддерживается")
+
+	buildDir, err := renderer.RenderStatic(context.Background(), uuid.New(), `{"pages":[{"path":"/","title":"T","blocks":[{"type":"banner","props":{"text":"Скидки"}}]}]}`, "")
+	require.NoError(t, err)
+
+	indexHTML, err := os.ReadFile(filepath.Join(buildDir, "index.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(indexHTML), ".landing-banner{color:red}")
+
+	stylesCSS, err := os.ReadFile(filepath.Join(buildDir, "styles.css"))
+	require.NoError(t, err)
+	assert.Contains(t, string(stylesCSS), ".landing-banner{color:red}")
+}
+
 func TestGetStringProp(t *testing.T) {
 	props := map[string]interface{}{
 		"exists":    "value",
@@ -264,9 +485,45 @@ func TestStaticRenderer_GenerateHTML_WithBlocks(t *testing.T) {
 		},
 	}
 
-	html := renderer.generateHTML("Test Title", blocks, nil)
+	html := renderer.generateHTML("/", "Test Title", blocks, nil, buildInfo{}, "")
 	assert.Contains(t, html, "<!DOCTYPE html>")
 	assert.Contains(t, html, "<title>Test Title</title>")
 	assert.Contains(t, html, "landing-section--hero")
 	assert.Contains(t, html, "Test")
 }
+
+func TestStaticRenderer_RenderStatic_AnalyticsScriptTargetsProject(t *testing.T) {
+	renderer := NewStaticRenderer(t.TempDir())
+	renderer.SetAPIBaseURL("https://api.landly.test/")
+
+	projectID := uuid.New()
+	buildDir, err := renderer.RenderStatic(context.Background(), projectID, `{"pages":[{"path":"/","title":"Home","blocks":[]}]}`, "")
+	require.NoError(t, err)
+
+	script, err := os.ReadFile(filepath.Join(buildDir, "analytics.js"))
+	require.NoError(t, err)
+	assert.Contains(t, string(script), `"https://api.landly.test/v1/analytics/`+projectID.String()+`"`)
+	assert.NotContains(t, string(script), analyticsEndpointPlaceholder)
+	assert.NotContains(t, string(script), "/api/track")
+}
+
+func TestStaticRenderer_RenderVariant_SeparateBuildWithVariant(t *testing.T) {
+	renderer := NewStaticRenderer(t.TempDir())
+
+	projectID := uuid.New()
+	schemaJSON := `{"pages":[{"path":"/","title":"Home","blocks":[]}]}`
+	mainDir, err := renderer.RenderStatic(context.Background(), projectID, schemaJSON, "")
+	require.NoError(t, err)
+	variantDir, err := renderer.RenderVariant(context.Background(), projectID, "b", schemaJSON, "")
+	require.NoError(t, err)
+	assert.NotEqual(t, mainDir, variantDir)
+
+	mainScript, err := os.ReadFile(filepath.Join(mainDir, "analytics.js"))
+	require.NoError(t, err)
+	assert.Contains(t, string(mainScript), `var variant = "";`)
+
+	variantScript, err := os.ReadFile(filepath.Join(variantDir, "analytics.js"))
+	require.NoError(t, err)
+	assert.Contains(t, string(variantScript), `var variant = "b";`)
+	assert.NotContains(t, string(variantScript), analyticsVariantPlaceholder)
+}
@@ -0,0 +1,322 @@
+package render
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"html/template"
+	"regexp"
+	"strings"
+
+	"github.com/landly/backend/internal/blocks"
+)
+
+// seoHead метатеги страницы: description, canonical, Open Graph, Twitter Card и JSON-LD
+type seoHead struct {
+	Title        string
+	Description  string
+	CanonicalURL string
+	Image        string
+	SiteName     string
+	TwitterCard  string
+	JSONLD       []template.JS
+}
+
+var seoHeadTemplate = template.Must(template.New("seo").Parse(`
+    {{- with .Description}}
+    <meta name="description" content="{{.}}">{{end}}
+    {{- with .CanonicalURL}}
+    <link rel="canonical" href="{{.}}">{{end}}
+    <meta property="og:type" content="website">
+    <meta property="og:title" content="{{.Title}}">
+    {{- with .Description}}
+    <meta property="og:description" content="{{.}}">{{end}}
+    {{- with .CanonicalURL}}
+    <meta property="og:url" content="{{.}}">{{end}}
+    {{- with .SiteName}}
+    <meta property="og:site_name" content="{{.}}">{{end}}
+    {{- with .Image}}
+    <meta property="og:image" content="{{.}}">{{end}}
+    <meta property="og:locale" content="ru_RU">
+    <meta name="twitter:card" content="{{.TwitterCard}}">
+    <meta name="twitter:title" content="{{.Title}}">
+    {{- with .Description}}
+    <meta name="twitter:description" content="{{.}}">{{end}}
+    {{- with .Image}}
+    <meta name="twitter:image" content="{{.}}">{{end}}
+    {{- range .JSONLD}}
+    <script type="application/ld+json">{{.}}</script>{{end}}`))
+
+// pageURL абсолютный адрес страницы опубликованного сайта
+func pageURL(siteURL, pagePath string) string {
+	if siteURL == "" {
+		return ""
+	}
+	url := strings.TrimSuffix(siteURL, "/") + "/"
+	if trimmed := strings.Trim(pagePath, "/"); trimmed != "" {
+		url += trimmed + "/"
+	}
+	return url
+}
+
+// buildSEOHead собирает метатеги страницы; ошибки сериализации JSON-LD пропускают разметку
+func (r *StaticRenderer) buildSEOHead(page map[string]interface{}, schema map[string]interface{}, siteURL, paymentURL string) template.HTML {
+	pagePath := getStringProp(page, "path", "/")
+	pageBlocks, _ := page["blocks"].([]interface{})
+
+	head := seoHead{
+		Title:        getStringProp(page, "title", ""),
+		Description:  getStringProp(page, "description", ""),
+		CanonicalURL: pageURL(siteURL, pagePath),
+		Image:        getStringProp(page, "image", firstBlockProp(pageBlocks, "hero", "image")),
+		SiteName:     siteName(schema),
+		TwitterCard:  "summary",
+	}
+	if head.Image != "" {
+		head.TwitterCard = "summary_large_image"
+	}
+
+	var entities []map[string]interface{}
+	if strings.Trim(pagePath, "/") == "" {
+		entities = append(entities, organizationLD(schema, siteURL))
+	}
+	entities = append(entities, productsLD(pageBlocks, schema, paymentURL, head.CanonicalURL)...)
+	if faq := faqPageLD(pageBlocks); faq != nil {
+		entities = append(entities, faq)
+	}
+	for _, entity := range entities {
+		data, err := json.Marshal(entity)
+		if err != nil {
+			continue
+		}
+		// json.Marshal экранирует <, > и &, поэтому содержимое безопасно внутри <script>
+		head.JSONLD = append(head.JSONLD, template.JS(data))
+	}
+
+	var buf strings.Builder
+	if err := seoHeadTemplate.Execute(&buf, head); err != nil {
+		return ""
+	}
+	return template.HTML(buf.String())
+}
+
+// organizationLD разметка Organization: название сайта и контакты из блока contact
+func organizationLD(schema map[string]interface{}, siteURL string) map[string]interface{} {
+	org := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "Organization",
+		"name":     siteName(schema),
+	}
+	if siteURL != "" {
+		org["url"] = pageURL(siteURL, "/")
+	}
+
+	pages, _ := schema["pages"].([]interface{})
+	for _, rawPage := range pages {
+		page, _ := rawPage.(map[string]interface{})
+		pageBlocks, _ := page["blocks"].([]interface{})
+		contact := findBlockProps(pageBlocks, "contact")
+		if contact == nil {
+			continue
+		}
+		if email := blocks.StringProp(contact, "email", ""); email != "" {
+			org["email"] = email
+		}
+		if phone := blocks.StringProp(contact, "phone", ""); phone != "" {
+			org["telephone"] = phone
+		}
+		if address := blocks.StringProp(contact, "address", ""); address != "" {
+			org["address"] = address
+		}
+		var sameAs []string
+		for _, social := range blocks.Slice(contact["socials"]) {
+			if link := blocks.StringProp(social, "url", ""); strings.HasPrefix(link, "http") {
+				sameAs = append(sameAs, link)
+			}
+		}
+		if len(sameAs) > 0 {
+			org["sameAs"] = sameAs
+		}
+		break
+	}
+
+	return org
+}
+
+// productsLD разметка Product/Offer для тарифов из блоков pricing
+func productsLD(pageBlocks []interface{}, schema map[string]interface{}, paymentURL, pageURL string) []map[string]interface{} {
+	defaultURL := paymentURL
+	if defaultURL == "" {
+		payment, _ := schema["payment"].(map[string]interface{})
+		defaultURL = getStringProp(payment, "url", pageURL)
+	}
+
+	var products []map[string]interface{}
+	for _, rawBlock := range pageBlocks {
+		block, _ := rawBlock.(map[string]interface{})
+		if getStringProp(block, "type", "") != "pricing" {
+			continue
+		}
+		props, _ := block["props"].(map[string]interface{})
+		for _, plan := range blocks.Slice(props["plans"]) {
+			name := blocks.StringProp(plan, "name", "")
+			if name == "" {
+				continue
+			}
+			product := map[string]interface{}{
+				"@context": "https://schema.org",
+				"@type":    "Product",
+				"name":     name,
+			}
+			if features := blocks.StringSlice(plan["features"]); len(features) > 0 {
+				product["description"] = strings.Join(features, ", ")
+			}
+
+			price := normalizePrice(plan["price"])
+			currency := currencyCode(blocks.StringProp(plan, "currency", ""))
+			if price != "" && currency != "" {
+				offer := map[string]interface{}{
+					"@type":         "Offer",
+					"price":         price,
+					"priceCurrency": currency,
+					"availability":  "https://schema.org/InStock",
+				}
+				if url := blocks.StringProp(plan, "url", defaultURL); url != "" {
+					offer["url"] = url
+				}
+				product["offers"] = offer
+			}
+			products = append(products, product)
+		}
+	}
+	return products
+}
+
+// faqPageLD разметка FAQPage по всем блокам faq страницы
+func faqPageLD(pageBlocks []interface{}) map[string]interface{} {
+	var questions []map[string]interface{}
+	for _, rawBlock := range pageBlocks {
+		block, _ := rawBlock.(map[string]interface{})
+		if getStringProp(block, "type", "") != "faq" {
+			continue
+		}
+		props, _ := block["props"].(map[string]interface{})
+		for _, item := range blocks.Slice(props["items"]) {
+			question := blocks.StringProp(item, "question", "")
+			answer := blocks.StringProp(item, "answer", "")
+			if question == "" || answer == "" {
+				continue
+			}
+			questions = append(questions, map[string]interface{}{
+				"@type": "Question",
+				"name":  question,
+				"acceptedAnswer": map[string]interface{}{
+					"@type": "Answer",
+					"text":  answer,
+				},
+			})
+		}
+	}
+	if len(questions) == 0 {
+		return nil
+	}
+	return map[string]interface{}{
+		"@context":   "https://schema.org",
+		"@type":      "FAQPage",
+		"mainEntity": questions,
+	}
+}
+
+var nonPriceChars = regexp.MustCompile(`[^0-9.,]`)
+
+// normalizePrice приводит цену ("29 990", 4990, "1 490,50") к виду schema.org: "29990", "4990", "1490.50"
+func normalizePrice(value interface{}) string {
+	var raw string
+	switch price := value.(type) {
+	case string:
+		raw = price
+	case float64:
+		data, _ := json.Marshal(price)
+		raw = string(data)
+	default:
+		return ""
+	}
+	cleaned := strings.ReplaceAll(nonPriceChars.ReplaceAllString(raw, ""), ",", ".")
+	cleaned = strings.Trim(cleaned, ".")
+	if cleaned == "" || strings.Count(cleaned, ".") > 1 {
+		return ""
+	}
+	return cleaned
+}
+
+// currencyCode переводит символ валюты в код ISO 4217
+func currencyCode(currency string) string {
+	switch strings.ToLower(strings.TrimSpace(currency)) {
+	case "₽", "руб", "руб.", "р.", "rub":
+		return "RUB"
+	case "$", "usd":
+		return "USD"
+	case "€", "eur":
+		return "EUR"
+	case "₸", "kzt":
+		return "KZT"
+	}
+	upper := strings.ToUpper(strings.TrimSpace(currency))
+	if len(upper) == 3 && strings.Trim(upper, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == "" {
+		return upper
+	}
+	return ""
+}
+
+// findBlockProps возвращает props первого блока указанного типа
+func findBlockProps(pageBlocks []interface{}, blockType string) map[string]interface{} {
+	for _, rawBlock := range pageBlocks {
+		block, _ := rawBlock.(map[string]interface{})
+		if getStringProp(block, "type", "") == blockType {
+			props, _ := block["props"].(map[string]interface{})
+			if props == nil {
+				props = map[string]interface{}{}
+			}
+			return props
+		}
+	}
+	return nil
+}
+
+func firstBlockProp(pageBlocks []interface{}, blockType, key string) string {
+	return getStringProp(findBlockProps(pageBlocks, blockType), key, "")
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc string `xml:"loc"`
+}
+
+// buildSitemap формирует sitemap.xml со всеми страницами сайта
+func buildSitemap(siteURL string, pagePaths []string) ([]byte, error) {
+	set := sitemapURLSet{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, pagePath := range pagePaths {
+		set.URLs = append(set.URLs, sitemapURL{Loc: pageURL(siteURL, pagePath)})
+	}
+	data, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// buildRobots формирует robots.txt; ссылка на sitemap добавляется, если известен адрес сайта
+func buildRobots(siteURL string) []byte {
+	var sb strings.Builder
+	sb.WriteString("User-agent: *\nAllow: /\n")
+	if siteURL != "" {
+		sb.WriteString("\nSitemap: ")
+		sb.WriteString(pageURL(siteURL, "/"))
+		sb.WriteString("sitemap.xml\n")
+	}
+	return []byte(sb.String())
+}
@@ -0,0 +1,175 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const seoSchemaJSON = `{
+	"pages": [
+		{
+			"path": "/",
+			"title": "Курс «Go с нуля»",
+			"description": "Научитесь программировать за 3 месяца",
+			"blocks": [
+				{"type": "hero", "props": {"headline": "Go с нуля", "image": "https://cdn.example.com/hero.jpg"}},
+				{"type": "pricing", "props": {"plans": [
+					{"name": "Базовый", "price": "29 990", "currency": "₽", "features": ["Видеоуроки", "Чат"]},
+					{"name": "Премиум", "price": 49990, "currency": "USD", "url": "https://pay.example.com/premium"},
+					{"name": "Корпоративный", "price": "по запросу", "currency": "₽"}
+				]}},
+				{"type": "faq", "props": {"items": [
+					{"question": "Нужен ли опыт?", "answer": "Нет"},
+					{"question": "Без ответа"}
+				]}}
+			]
+		},
+		{"path": "/contacts", "title": "Контакты", "blocks": [
+			{"type": "contact", "props": {"email": "hi@example.com", "phone": "+7 999 000-00-00", "socials": [{"label": "TG", "url": "https://t.me/example"}]}}
+		]}
+	],
+	"payment": {"url": "https://pay.example.com"}
+}`
+
+// jsonLD извлекает JSON-LD объекты из HTML страницы
+func jsonLD(t *testing.T, page string) []map[string]interface{} {
+	t.Helper()
+
+	var entities []map[string]interface{}
+	for _, match := range regexp.MustCompile(`<script type="application/ld\+json">(.*?)</script>`).FindAllStringSubmatch(page, -1) {
+		var entity map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(match[1]), &entity))
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+func TestStaticRenderer_RenderStatic_SEO(t *testing.T) {
+	renderer := NewStaticRenderer(t.TempDir())
+
+	buildDir, err := renderer.RenderStatic(context.Background(), uuid.New(), seoSchemaJSON, "https://landly.example.com/sites/go-course")
+	require.NoError(t, err)
+
+	homeBytes, err := os.ReadFile(filepath.Join(buildDir, "index.html"))
+	require.NoError(t, err)
+	home := string(homeBytes)
+
+	assert.Contains(t, home, `<meta name="description" content="Научитесь программировать за 3 месяца">`)
+	assert.Contains(t, home, `<link rel="canonical" href="https://landly.example.com/sites/go-course/">`)
+	assert.Contains(t, home, `<meta property="og:title" content="Курс «Go с нуля»">`)
+	assert.Contains(t, home, `<meta property="og:url" content="https://landly.example.com/sites/go-course/">`)
+	assert.Contains(t, home, `<meta property="og:image" content="https://cdn.example.com/hero.jpg">`)
+	assert.Contains(t, home, `<meta name="twitter:card" content="summary_large_image">`)
+
+	entities := jsonLD(t, home)
+	require.Len(t, entities, 5)
+
+	org := entities[0]
+	assert.Equal(t, "Organization", org["@type"])
+	assert.Equal(t, "Курс «Go с нуля»", org["name"])
+	assert.Equal(t, "https://landly.example.com/sites/go-course/", org["url"])
+	assert.Equal(t, "hi@example.com", org["email"])
+	assert.Equal(t, []interface{}{"https://t.me/example"}, org["sameAs"])
+
+	basic := entities[1]
+	assert.Equal(t, "Product", basic["@type"])
+	assert.Equal(t, "Видеоуроки, Чат", basic["description"])
+	assert.Equal(t, map[string]interface{}{
+		"@type":         "Offer",
+		"price":         "29990",
+		"priceCurrency": "RUB",
+		"availability":  "https://schema.org/InStock",
+		"url":           "https://pay.example.com",
+	}, basic["offers"])
+
+	premium := entities[2]["offers"].(map[string]interface{})
+	assert.Equal(t, "49990", premium["price"])
+	assert.Equal(t, "USD", premium["priceCurrency"])
+	assert.Equal(t, "https://pay.example.com/premium", premium["url"])
+
+	assert.NotContains(t, entities[3], "offers", "price without digits produces no offer")
+
+	faq := entities[4]
+	assert.Equal(t, "FAQPage", faq["@type"])
+	assert.Len(t, faq["mainEntity"], 1)
+
+	contactsBytes, err := os.ReadFile(filepath.Join(buildDir, "contacts", "index.html"))
+	require.NoError(t, err)
+	contacts := string(contactsBytes)
+	assert.Contains(t, contacts, `<link rel="canonical" href="https://landly.example.com/sites/go-course/contacts/">`)
+	assert.Contains(t, contacts, `<meta name="twitter:card" content="summary">`)
+	assert.NotContains(t, contacts, `name="description"`)
+	assert.Empty(t, jsonLD(t, contacts), "organization markup is emitted on the home page only")
+
+	sitemap, err := os.ReadFile(filepath.Join(buildDir, "sitemap.xml"))
+	require.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://landly.example.com/sites/go-course/</loc>
+  </url>
+  <url>
+    <loc>https://landly.example.com/sites/go-course/contacts/</loc>
+  </url>
+</urlset>
+`, string(sitemap))
+
+	robots, err := os.ReadFile(filepath.Join(buildDir, "robots.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "User-agent: *\nAllow: /\n\nSitemap: https://landly.example.com/sites/go-course/sitemap.xml\n", string(robots))
+}
+
+func TestStaticRenderer_RenderStatic_SEOWithoutSiteURL(t *testing.T) {
+	renderer := NewStaticRenderer(t.TempDir())
+
+	buildDir, err := renderer.RenderStatic(context.Background(), uuid.New(), seoSchemaJSON, "")
+	require.NoError(t, err)
+
+	home, err := os.ReadFile(filepath.Join(buildDir, "index.html"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(home), `rel="canonical"`)
+	assert.NotContains(t, string(home), `og:url`)
+	assert.Contains(t, string(home), `<meta name="description"`)
+
+	assert.NoFileExists(t, filepath.Join(buildDir, "sitemap.xml"))
+	robots, err := os.ReadFile(filepath.Join(buildDir, "robots.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "User-agent: *\nAllow: /\n", string(robots))
+}
+
+func TestJSONLD_EscapesScriptContent(t *testing.T) {
+	renderer := NewStaticRenderer(t.TempDir())
+	page := map[string]interface{}{
+		"path":  "/",
+		"title": "T",
+		"blocks": []interface{}{
+			map[string]interface{}{"type": "faq", "props": map[string]interface{}{"items": []interface{}{
+				map[string]interface{}{"question": "</script><script>alert(1)</script>", "answer": "a"},
+			}}},
+		},
+	}
+
+	head := string(renderer.buildSEOHead(page, map[string]interface{}{"pages": []interface{}{page}}, "", ""))
+	assert.NotContains(t, head, "<script>alert(1)")
+	assert.Contains(t, head, `</script>`)
+}
+
+func TestNormalizePriceAndCurrency(t *testing.T) {
+	assert.Equal(t, "1490.50", normalizePrice("1 490,50 ₽"))
+	assert.Equal(t, "990", normalizePrice(float64(990)))
+	assert.Equal(t, "", normalizePrice("бесплатно"))
+	assert.Equal(t, "", normalizePrice(true))
+
+	assert.Equal(t, "RUB", currencyCode("руб."))
+	assert.Equal(t, "EUR", currencyCode("€"))
+	assert.Equal(t, "GBP", currencyCode("gbp"))
+	assert.Equal(t, "", currencyCode("у.е."))
+}
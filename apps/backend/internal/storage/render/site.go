@@ -0,0 +1,147 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/landly/backend/internal/blocks"
+)
+
+// relativeRoot возвращает относительный путь от страницы к корню сайта:
+// "" для /, "../" для /about, "../../" для /docs/api.
+// Страницы лежат в <path>/index.html и открываются как <path>/.
+func relativeRoot(pagePath string) string {
+	trimmed := strings.Trim(pagePath, "/")
+	if trimmed == "" {
+		return ""
+	}
+	return strings.Repeat("../", strings.Count(trimmed, "/")+1)
+}
+
+// pageHref возвращает относительную ссылку со страницы from на страницу to
+func pageHref(from, to string) string {
+	href := relativeRoot(from)
+	if target := strings.Trim(to, "/"); target != "" {
+		href += target + "/"
+	}
+	if href == "" {
+		return "./"
+	}
+	return href
+}
+
+// siteLinks строит меню сайта из списка страниц схемы
+func siteLinks(schema map[string]interface{}, currentPath string) []blocks.Link {
+	pages, _ := schema["pages"].([]interface{})
+	links := make([]blocks.Link, 0, len(pages))
+	for _, rawPage := range pages {
+		page, ok := rawPage.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path, _ := page["path"].(string)
+		title, _ := page["title"].(string)
+		if !strings.HasPrefix(path, "/") || title == "" {
+			continue
+		}
+		links = append(links, blocks.Link{
+			Title:  getStringProp(page, "navTitle", title),
+			Href:   pageHref(currentPath, path),
+			Active: strings.Trim(path, "/") == strings.Trim(currentPath, "/"),
+		})
+	}
+	return links
+}
+
+// siteName название сайта для шапки: заголовок главной страницы
+func siteName(schema map[string]interface{}) string {
+	pages, _ := schema["pages"].([]interface{})
+	for _, rawPage := range pages {
+		page, _ := rawPage.(map[string]interface{})
+		if getStringProp(page, "path", "") == "/" {
+			return getStringProp(page, "title", "")
+		}
+	}
+	return ""
+}
+
+// sectionAnchors назначает секциям страницы уникальные id (props.anchor или тип блока)
+// и собирает подписи, по которым на них можно сослаться из меню
+func (r *StaticRenderer) sectionAnchors(pageBlocks []interface{}) ([]string, map[string]string) {
+	ids := make([]string, len(pageBlocks))
+	aliases := make(map[string]string)
+	used := make(map[string]int)
+
+	addAlias := func(alias, id string) {
+		alias = strings.ToLower(strings.TrimSpace(alias))
+		if alias == "" {
+			return
+		}
+		if _, exists := aliases[alias]; !exists {
+			aliases[alias] = id
+		}
+	}
+
+	for i, rawBlock := range pageBlocks {
+		block, ok := rawBlock.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		blockType, _ := block["type"].(string)
+		props, _ := block["props"].(map[string]interface{})
+
+		base := anchorID(getStringProp(props, "anchor", ""))
+		if base == "" {
+			base = anchorID(blockType)
+		}
+		if base == "" {
+			continue
+		}
+
+		id := base
+		used[base]++
+		if used[base] > 1 {
+			id = fmt.Sprintf("%s-%d", base, used[base])
+		}
+		ids[i] = id
+
+		addAlias(id, id)
+		addAlias(blockType, id)
+		addAlias(getStringProp(props, "title", ""), id)
+		if def, ok := r.registry.Get(blockType); ok {
+			addAlias(def.Label, id)
+		}
+	}
+
+	return ids, aliases
+}
+
+// anchorID приводит строку к допустимому id: буквы, цифры, - и _
+func anchorID(value string) string {
+	var sb strings.Builder
+	for _, ch := range strings.ToLower(strings.TrimSpace(value)) {
+		switch {
+		case unicode.IsLetter(ch), unicode.IsDigit(ch), ch == '-', ch == '_':
+			sb.WriteRune(ch)
+		case unicode.IsSpace(ch):
+			sb.WriteRune('-')
+		}
+	}
+	return strings.Trim(sb.String(), "-")
+}
+
+var sectionTagPattern = regexp.MustCompile(`^\s*<section\b([^>]*)>`)
+
+// withAnchor добавляет id в открывающий тег секции, если его там ещё нет
+func withAnchor(sectionHTML, id string) string {
+	if id == "" {
+		return sectionHTML
+	}
+	match := sectionTagPattern.FindStringSubmatchIndex(sectionHTML)
+	if match == nil || strings.Contains(sectionHTML[match[2]:match[3]], " id=") {
+		return sectionHTML
+	}
+	return sectionHTML[:match[2]] + fmt.Sprintf(` id="%s"`, id) + sectionHTML[match[2]:]
+}
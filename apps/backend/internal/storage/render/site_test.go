@@ -0,0 +1,107 @@
+package render
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelativeRootAndPageHref(t *testing.T) {
+	assert.Equal(t, "", relativeRoot("/"))
+	assert.Equal(t, "../", relativeRoot("/about"))
+	assert.Equal(t, "../", relativeRoot("/about/"))
+	assert.Equal(t, "../../", relativeRoot("/docs/api"))
+
+	assert.Equal(t, "./", pageHref("/", "/"))
+	assert.Equal(t, "about/", pageHref("/", "/about"))
+	assert.Equal(t, "../", pageHref("/about", "/"))
+	assert.Equal(t, "../pricing/", pageHref("/about", "/pricing"))
+	assert.Equal(t, "../../about/", pageHref("/docs/api", "/about"))
+}
+
+func TestAnchorID(t *testing.T) {
+	assert.Equal(t, "pricing", anchorID("pricing"))
+	assert.Equal(t, "наши-тарифы", anchorID(" Наши тарифы! "))
+	assert.Equal(t, "script", anchorID(`"><script>`))
+	assert.Equal(t, "", anchorID(" !? "))
+}
+
+func TestWithAnchor(t *testing.T) {
+	assert.Equal(t, `<section id="faq" class="landing-section">x</section>`, withAnchor(`<section class="landing-section">x</section>`, "faq"))
+	assert.Equal(t, `<section id="own">x</section>`, withAnchor(`<section id="own">x</section>`, "faq"))
+	assert.Equal(t, `<div>x</div>`, withAnchor(`<div>x</div>`, "faq"))
+	assert.Equal(t, `<section>x</section>`, withAnchor(`<section>x</section>`, ""))
+}
+
+func TestStaticRenderer_RenderStatic_SharedNavigation(t *testing.T) {
+	renderer := NewStaticRenderer(t.TempDir())
+
+	buildDir, err := renderer.RenderStatic(context.Background(), uuid.New(), `{
+		"pages": [
+			{"path": "/", "title": "Студия", "blocks": [
+				{"type": "hero", "props": {"headline": "Привет", "navItems": ["Цены", "О компании", {"label": "Блог", "href": "https://blog.example.com"}]}},
+				{"type": "pricing", "props": {"title": "Тарифы"}},
+				{"type": "faq", "props": {"anchor": "questions"}},
+				{"type": "faq", "props": {}}
+			]},
+			{"path": "/about", "title": "О компании", "blocks": [{"type": "about", "props": {}}]},
+			{"path": "/docs/api", "title": "API", "blocks": []}
+		]
+	}`, "")
+	require.NoError(t, err)
+
+	read := func(parts ...string) string {
+		data, err := os.ReadFile(filepath.Join(append([]string{buildDir}, parts...)...))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	home := read("index.html")
+	assert.Contains(t, home, `<link rel="stylesheet" href="styles.css">`)
+	assert.Contains(t, home, `<header class="landing-site-header">`)
+	assert.Contains(t, home, `<footer class="landing-site-footer">`)
+	assert.Contains(t, home, `<a href="./" class="is-active" aria-current="page">Студия</a>`)
+	assert.Contains(t, home, `<a href="about/">О компании</a>`)
+	assert.Contains(t, home, `<a href="docs/api/">API</a>`)
+	// Меню hero: якорь секции, страница сайта и явная ссылка
+	assert.Contains(t, home, `<a href="#pricing">Цены</a><a href="about/">О компании</a><a href="https://blog.example.com">Блог</a>`)
+	assert.Contains(t, home, `<section id="pricing" class="landing-section landing-section--pricing"`)
+	assert.Contains(t, home, `<section id="questions" class="landing-section landing-section--faq"`)
+	assert.Contains(t, home, `<section id="faq" class="landing-section landing-section--faq"`)
+
+	about := read("about", "index.html")
+	assert.Contains(t, about, `<link rel="stylesheet" href="../styles.css">`)
+	assert.Contains(t, about, `<script src="../analytics.js" defer></script>`)
+	assert.Contains(t, about, `<a class="landing-brand" href="../">Студия</a>`)
+	assert.Contains(t, about, `<a href="../about/" class="is-active" aria-current="page">О компании</a>`)
+
+	api := read("docs", "api", "index.html")
+	assert.Contains(t, api, `<link rel="stylesheet" href="../../styles.css">`)
+	assert.Contains(t, api, `<a href="../../">Студия</a>`)
+}
+
+func TestStaticRenderer_RenderStatic_SinglePageHasNoSiteHeader(t *testing.T) {
+	renderer := NewStaticRenderer(t.TempDir())
+
+	buildDir, err := renderer.RenderStatic(context.Background(), uuid.New(), `{
+		"pages": [{"path": "/", "title": "Лендинг", "blocks": [
+			{"type": "hero", "props": {}},
+			{"type": "features", "props": {}},
+			{"type": "contact", "props": {}}
+		]}]
+	}`, "")
+	require.NoError(t, err)
+
+	home, err := os.ReadFile(filepath.Join(buildDir, "index.html"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(home), `<header class="landing-site-header">`)
+	assert.NotContains(t, string(home), `<footer class="landing-site-footer">`)
+	assert.Contains(t, string(home), "<body class=\"landing-body\">\n    <main class=\"landing\"")
+	// Подписи меню по умолчанию ведут к секциям, которые есть на странице
+	assert.Contains(t, string(home), `<a href="#features">Возможности</a><a href="#">Цены</a><a href="#">Отзывы</a><a href="#contact">Контакты</a>`)
+}
@@ -0,0 +1,148 @@
+package render
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/landly/backend/internal/blocks"
+)
+
+// DefaultThemeName тема, которой рендерятся проекты без theme.name в схеме
+const DefaultThemeName = "default"
+
+// Встроенные темы. Структура каталога темы:
+//
+//	<name>/page.html        — каркас страницы (если нет, берётся из default)
+//	<name>/theme.css        — стили поверх landing.css
+//	<name>/blocks/<type>.html — шаблоны блоков; блоки без шаблона рендерятся из реестра
+//
+//go:embed themes
+var builtinThemesFS embed.FS
+
+// Theme набор html/template-шаблонов и CSS, выбираемый через theme.name в схеме
+type Theme struct {
+	Name   string
+	CSS    string
+	page   *template.Template
+	blocks map[string]*template.Template
+}
+
+// pageData данные шаблона page.html
+type pageData struct {
+	Title string
+	// Head метатеги SEO (description, canonical, Open Graph, JSON-LD) для вставки в <head>
+	Head     template.HTML
+	Path     string
+	SiteName string
+	HomeHref string
+	// AssetBase относительный путь к корню сайта ("", "../", ...) для styles.css, analytics.js и ссылок
+	AssetBase string
+	// Pages меню сайта; шапка и подвал выводятся, если страниц больше одной
+	Pages      []blocks.Link
+	Theme      string
+	ThemeStyle string
+	InlineCSS  template.CSS
+	Sections   []template.HTML
+}
+
+// blockData данные шаблона блока; .Schema, .Pages, .Anchors и .Href — из RenderContext
+type blockData struct {
+	Type  string
+	Props map[string]interface{}
+	blocks.RenderContext
+}
+
+// themeFuncs функции, доступные в шаблонах тем
+var themeFuncs = template.FuncMap{
+	"prop": func(props map[string]interface{}, key, defaultValue string) string {
+		return blocks.StringProp(props, key, defaultValue)
+	},
+	"items":   blocks.Slice,
+	"strings": blocks.StringSlice,
+	"flag":    blocks.BoolProp,
+	"number":  blocks.IntProp,
+}
+
+// LoadThemes загружает темы из подкаталогов fsys
+func LoadThemes(fsys fs.FS) (map[string]*Theme, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read themes: %w", err)
+	}
+
+	themes := make(map[string]*Theme, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		theme, err := loadTheme(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		themes[theme.Name] = theme
+	}
+	return themes, nil
+}
+
+func loadTheme(fsys fs.FS, name string) (*Theme, error) {
+	theme := &Theme{Name: name, blocks: make(map[string]*template.Template)}
+
+	pageFile := path.Join(name, "page.html")
+	if _, err := fs.Stat(fsys, pageFile); err == nil {
+		page, err := template.New("page.html").Funcs(themeFuncs).ParseFS(fsys, pageFile)
+		if err != nil {
+			return nil, fmt.Errorf("theme %s: failed to parse page.html: %w", name, err)
+		}
+		theme.page = page
+	}
+
+	css, err := fs.ReadFile(fsys, path.Join(name, "theme.css"))
+	switch {
+	case err == nil:
+		theme.CSS = string(css)
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("theme %s: failed to read theme.css: %w", name, err)
+	}
+
+	blockFiles, err := fs.Glob(fsys, path.Join(name, "blocks", "*.html"))
+	if err != nil {
+		return nil, fmt.Errorf("theme %s: %w", name, err)
+	}
+	for _, file := range blockFiles {
+		base := path.Base(file)
+		tmpl, err := template.New(base).Funcs(themeFuncs).ParseFS(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("theme %s: failed to parse %s: %w", name, base, err)
+		}
+		theme.blocks[strings.TrimSuffix(base, ".html")] = tmpl
+	}
+
+	return theme, nil
+}
+
+// mustLoadBuiltinThemes загружает встроенные темы; ошибка в них — ошибка сборки
+func mustLoadBuiltinThemes() map[string]*Theme {
+	sub, err := fs.Sub(builtinThemesFS, "themes")
+	if err != nil {
+		panic(err)
+	}
+	themes, err := LoadThemes(sub)
+	if err != nil {
+		panic(err)
+	}
+	if themes[DefaultThemeName] == nil || themes[DefaultThemeName].page == nil {
+		panic("render: default theme must provide page.html")
+	}
+	return themes
+}
+
+// themeName возвращает theme.name из схемы
+func themeName(schema map[string]interface{}) string {
+	theme, _ := schema["theme"].(map[string]interface{})
+	return getStringProp(theme, "name", DefaultThemeName)
+}
@@ -0,0 +1,120 @@
+package render
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinThemes(t *testing.T) {
+	renderer := NewStaticRenderer(t.TempDir())
+
+	require.Contains(t, renderer.themes, DefaultThemeName)
+	require.Contains(t, renderer.themes, "minimal")
+	assert.Empty(t, renderer.themes[DefaultThemeName].blocks, "default theme renders blocks from the registry")
+	assert.NotEmpty(t, renderer.themes["minimal"].CSS)
+}
+
+func TestStaticRenderer_Theme_DefaultWhenMissingOrUnknown(t *testing.T) {
+	renderer := NewStaticRenderer(t.TempDir())
+	props := map[string]interface{}{"headline": "Привет"}
+
+	expected := renderer.renderBlock("hero", props, nil)
+	assert.Contains(t, expected, "landing-hero-overlay")
+
+	assert.Equal(t, expected, renderer.renderBlock("hero", props, map[string]interface{}{"theme": map[string]interface{}{"font": "inter"}}))
+	assert.Equal(t, expected, renderer.renderBlock("hero", props, map[string]interface{}{"theme": map[string]interface{}{"name": "neon"}}))
+}
+
+func TestStaticRenderer_Theme_Minimal(t *testing.T) {
+	renderer := NewStaticRenderer(t.TempDir())
+	schema := map[string]interface{}{"theme": map[string]interface{}{"name": "minimal"}}
+
+	hero := renderer.renderBlock("hero", map[string]interface{}{
+		"headline": "<Привет>",
+		"ctaText":  "Начать",
+		"ctaUrl":   "https://example.com/start",
+	}, schema)
+	assert.Contains(t, hero, "minimal-hero__headline")
+	assert.Contains(t, hero, "&lt;Привет&gt;")
+	assert.Contains(t, hero, `href="https://example.com/start"`)
+	assert.NotContains(t, hero, "landing-hero-overlay")
+
+	faq := renderer.renderBlock("faq", nil, schema)
+	assert.Contains(t, faq, "Добавьте вопросы и ответы")
+
+	// Блоки без шаблона в теме рендерятся из реестра
+	pricing := renderer.renderBlock("pricing", map[string]interface{}{"title": "Тарифы"}, schema)
+	assert.Contains(t, pricing, "landing-pricing__grid")
+
+	page := renderer.generateHTML("/", "Minimal", []interface{}{
+		map[string]interface{}{"type": "features", "props": map[string]interface{}{
+			"items": []interface{}{map[string]interface{}{"title": "Быстро", "description": "За минуту"}},
+		}},
+	}, schema, buildInfo{}, "")
+	assert.Contains(t, page, `class="landing landing--minimal" data-theme="minimal"`)
+	assert.Contains(t, page, ".minimal-hero")
+	assert.Contains(t, page, "<strong>Быстро</strong>")
+}
+
+func TestLoadThemes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"dark/theme.css":         {Data: []byte(".landing{background:#000}")},
+		"dark/blocks/cta.html":   {Data: []byte(`<section class="dark-cta">{{prop .Props "title" "CTA"}}</section>`)},
+		"dark/blocks/readme.txt": {Data: []byte("ignored")},
+		"notes.txt":              {Data: []byte("not a theme")},
+	}
+
+	themes, err := LoadThemes(fsys)
+	require.NoError(t, err)
+	require.Len(t, themes, 1)
+
+	dark := themes["dark"]
+	require.NotNil(t, dark)
+	assert.Nil(t, dark.page)
+	assert.Equal(t, ".landing{background:#000}", dark.CSS)
+	assert.Len(t, dark.blocks, 1)
+	assert.Contains(t, dark.blocks, "cta")
+}
+
+func TestLoadThemes_InvalidTemplate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"broken/blocks/hero.html": {Data: []byte(`{{prop .Props "headline"`)},
+	}
+
+	_, err := LoadThemes(fsys)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "theme broken")
+}
+
+func TestStaticRenderer_LoadThemes(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "dark", "blocks"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dark", "theme.css"), []byte(".dark{color:#fff}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dark", "blocks", "cta.html"), []byte(`<section class="dark-cta">{{prop .Props "title" "CTA"}}</section>`), 0644))
+
+	renderer := NewStaticRenderer(t.TempDir())
+	require.NoError(t, renderer.LoadThemes(dir))
+
+	buildDir, err := renderer.RenderStatic(context.Background(), uuid.New(), `{
+		"pages": [{"path": "/", "title": "Dark", "blocks": [{"type": "cta", "props": {"title": "Купить"}}]}],
+		"theme": {"name": "dark"}
+	}`, "")
+	require.NoError(t, err)
+
+	indexHTML, err := os.ReadFile(filepath.Join(buildDir, "index.html"))
+	require.NoError(t, err)
+	// page.html темы нет — каркас берётся из default
+	assert.Contains(t, string(indexHTML), `<main class="landing"`)
+	assert.Contains(t, string(indexHTML), `<section id="cta" class="dark-cta">Купить</section>`)
+
+	stylesCSS, err := os.ReadFile(filepath.Join(buildDir, "styles.css"))
+	require.NoError(t, err)
+	assert.Contains(t, string(stylesCSS), ".dark{color:#fff}")
+}
@@ -187,6 +187,7 @@ func createTestSchema(db *sql.DB) error {
 		model VARCHAR(50) NOT NULL,
 		status VARCHAR(50) NOT NULL DEFAULT 'pending',
 		schema_json TEXT,
+		error_json TEXT NOT NULL DEFAULT '',
 		completed_at TIMESTAMPTZ,
 		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
@@ -205,6 +206,19 @@ func createTestSchema(db *sql.DB) error {
 	CREATE INDEX IF NOT EXISTS idx_generation_messages_session_created_at
 		ON generation_messages(session_id, created_at);
 
+	CREATE TABLE IF NOT EXISTS schema_revisions (
+		id UUID PRIMARY KEY,
+		project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+		version INTEGER NOT NULL,
+		schema_json TEXT NOT NULL,
+		source VARCHAR(50) NOT NULL,
+		author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+		message_id UUID REFERENCES generation_messages(id) ON DELETE SET NULL,
+		restored_from UUID REFERENCES schema_revisions(id) ON DELETE SET NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		UNIQUE(project_id, version)
+	);
+
 	CREATE TABLE IF NOT EXISTS integrations (
 		id UUID PRIMARY KEY,
 		project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
@@ -215,16 +229,53 @@ func createTestSchema(db *sql.DB) error {
 		UNIQUE(project_id, type)
 	);
 
+	CREATE TABLE IF NOT EXISTS deployments (
+		id UUID PRIMARY KEY,
+		project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+		version INTEGER NOT NULL,
+		subdomain VARCHAR(255) NOT NULL,
+		status VARCHAR(50) NOT NULL DEFAULT 'pending',
+		author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		finished_at TIMESTAMPTZ,
+		variants TEXT NOT NULL DEFAULT '',
+		schema_json TEXT NOT NULL DEFAULT '',
+		UNIQUE(project_id, version)
+	);
+
 	CREATE TABLE IF NOT EXISTS publish_targets (
 		id UUID PRIMARY KEY,
 		project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
 		subdomain VARCHAR(255) UNIQUE NOT NULL,
+		custom_domain VARCHAR(255),
+		ssl_status VARCHAR(50) NOT NULL DEFAULT 'none',
+		domain_verification_token VARCHAR(64),
+		domain_verified_at TIMESTAMPTZ,
 		status VARCHAR(50) NOT NULL DEFAULT 'draft',
+		active_deployment_id UUID REFERENCES deployments(id) ON DELETE SET NULL,
 		last_published_at TIMESTAMPTZ,
 		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 	);
 
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_publish_targets_custom_domain ON publish_targets(custom_domain) WHERE domain_verified_at IS NOT NULL;
+
+	CREATE TABLE IF NOT EXISTS jobs (
+		id UUID PRIMARY KEY,
+		type VARCHAR(50) NOT NULL,
+		payload TEXT NOT NULL,
+		status VARCHAR(50) NOT NULL DEFAULT 'queued',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		max_attempts INTEGER NOT NULL DEFAULT 5,
+		last_error TEXT NOT NULL DEFAULT '',
+		user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+		project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+		run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		finished_at TIMESTAMPTZ
+	);
+
 	CREATE TABLE IF NOT EXISTS analytics_events (
 		id UUID PRIMARY KEY,
 		project_id UUID NOT NULL,
@@ -233,8 +284,155 @@ func createTestSchema(db *sql.DB) error {
 		referrer VARCHAR(500),
 		user_agent VARCHAR(500),
 		ip_address VARCHAR(50),
+		visitor_hash VARCHAR(64),
+		utm_source VARCHAR(255),
+		utm_medium VARCHAR(255),
+		utm_campaign VARCHAR(255),
+		utm_term VARCHAR(255),
+		utm_content VARCHAR(255),
+		referrer_domain VARCHAR(255),
+		browser VARCHAR(50),
+		os VARCHAR(50),
+		device_type VARCHAR(20),
+		variant VARCHAR(64),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS analytics_salts (
+		day DATE PRIMARY KEY,
+		salt BYTEA NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS analytics_goals (
+		id UUID PRIMARY KEY,
+		project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+		name VARCHAR(100) NOT NULL,
+		event_type VARCHAR(50) NOT NULL,
+		path VARCHAR(255),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS analytics_goal_reached (
+		goal_id UUID NOT NULL REFERENCES analytics_goals(id) ON DELETE CASCADE,
+		visitor_hash VARCHAR(64) NOT NULL,
+		day DATE NOT NULL,
+		PRIMARY KEY (goal_id, visitor_hash, day)
+	);
+
+	CREATE TABLE IF NOT EXISTS project_variants (
+		id UUID PRIMARY KEY,
+		project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+		name VARCHAR(100) NOT NULL,
+		schema_json TEXT NOT NULL,
+		weight INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS tls_certificates (
+		domain VARCHAR(255) PRIMARY KEY,
+		cert_pem TEXT NOT NULL,
+		key_pem TEXT NOT NULL,
+		not_after TIMESTAMPTZ NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS acme_accounts (
+		directory_url VARCHAR(512) PRIMARY KEY,
+		key_pem TEXT NOT NULL,
 		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 	);
+
+	CREATE TABLE IF NOT EXISTS acme_challenges (
+		token VARCHAR(255) PRIMARY KEY,
+		response TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS certificate_issue_leases (
+		domain VARCHAR(255) PRIMARY KEY,
+		holder VARCHAR(64) NOT NULL,
+		expires_at TIMESTAMP NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS analytics_rollups_hourly (
+		project_id UUID NOT NULL,
+		bucket TIMESTAMPTZ NOT NULL,
+		pageviews INTEGER NOT NULL DEFAULT 0,
+		unique_visitors INTEGER NOT NULL DEFAULT 0,
+		cta_clicks INTEGER NOT NULL DEFAULT 0,
+		pay_clicks INTEGER NOT NULL DEFAULT 0,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (project_id, bucket)
+	);
+
+	CREATE TABLE IF NOT EXISTS analytics_rollups_daily (
+		project_id UUID NOT NULL,
+		bucket TIMESTAMPTZ NOT NULL,
+		pageviews INTEGER NOT NULL DEFAULT 0,
+		unique_visitors INTEGER NOT NULL DEFAULT 0,
+		cta_clicks INTEGER NOT NULL DEFAULT 0,
+		pay_clicks INTEGER NOT NULL DEFAULT 0,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (project_id, bucket)
+	);
+
+	CREATE TABLE IF NOT EXISTS analytics_rollup_watermarks (
+		granularity VARCHAR(10) PRIMARY KEY,
+		rolled_up_to TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS purchases (
+		id UUID PRIMARY KEY,
+		project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+		provider VARCHAR(20) NOT NULL,
+		external_id VARCHAR(255) NOT NULL,
+		amount BIGINT NOT NULL,
+		currency VARCHAR(3) NOT NULL,
+		variant VARCHAR(64) NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		UNIQUE(provider, external_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS form_submissions (
+		id UUID PRIMARY KEY,
+		project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+		form_name VARCHAR(64) NOT NULL,
+		data TEXT NOT NULL,
+		path VARCHAR(512) NOT NULL DEFAULT '',
+		variant VARCHAR(64) NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS webhooks (
+		id UUID PRIMARY KEY,
+		project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+		url VARCHAR(2048) NOT NULL,
+		secret VARCHAR(255) NOT NULL,
+		events TEXT NOT NULL DEFAULT '[]',
+		active BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id UUID PRIMARY KEY,
+		webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+		project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+		event_id UUID NOT NULL,
+		event VARCHAR(64) NOT NULL,
+		payload TEXT NOT NULL,
+		attempt INTEGER NOT NULL DEFAULT 1,
+		status_code INTEGER NOT NULL DEFAULT 0,
+		error TEXT NOT NULL DEFAULT '',
+		duration_ms BIGINT NOT NULL DEFAULT 0,
+		success BOOLEAN NOT NULL DEFAULT FALSE,
+		created_at TIMESTAMP NOT NULL DEFAULT NOW()
+	);
 	`
 
 	_, err := db.Exec(schema)
@@ -245,9 +443,27 @@ func cleanupTestDB(t *testing.T, db *sql.DB) {
 	t.Helper()
 
 	tables := []string{
+		"jobs",
+		"schema_revisions",
 		"generation_messages",
+		"analytics_rollups_hourly",
+		"analytics_rollups_daily",
+		"analytics_rollup_watermarks",
 		"analytics_events",
+		"analytics_salts",
+		"analytics_goal_reached",
+		"analytics_goals",
+		"project_variants",
+		"tls_certificates",
+		"acme_accounts",
+		"acme_challenges",
+		"certificate_issue_leases",
 		"publish_targets",
+		"deployments",
+		"purchases",
+		"form_submissions",
+		"webhook_deliveries",
+		"webhooks",
 		"integrations",
 		"generation_sessions",
 		"projects",
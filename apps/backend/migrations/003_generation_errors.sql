@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE generation_sessions
+    ADD COLUMN IF NOT EXISTS error_json TEXT NOT NULL DEFAULT '';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE generation_sessions
+    DROP COLUMN IF EXISTS error_json;
+
+-- +goose StatementEnd
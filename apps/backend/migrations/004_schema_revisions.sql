@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS schema_revisions (
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    schema_json TEXT NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    message_id UUID REFERENCES generation_messages(id) ON DELETE SET NULL,
+    restored_from UUID REFERENCES schema_revisions(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (project_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_schema_revisions_project_version
+    ON schema_revisions(project_id, version DESC);
+
+-- Текущие схемы становятся первой ревизией, чтобы к ним можно было вернуться
+INSERT INTO schema_revisions (id, project_id, version, schema_json, source, author_id, created_at)
+SELECT gen_random_uuid(), id, 1, schema_json, 'initial', user_id, updated_at
+FROM projects
+WHERE schema_json IS NOT NULL AND schema_json <> '';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_schema_revisions_project_version;
+DROP TABLE IF EXISTS schema_revisions;
+
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Каждая публикация загружается в неизменяемый префикс sites/<subdomain>/v<version>
+CREATE TABLE IF NOT EXISTS deployments (
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    subdomain VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP,
+    UNIQUE (project_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_deployments_project_version
+    ON deployments(project_id, version DESC);
+
+-- Колонка status используется репозиторием, но в 001 её не было
+ALTER TABLE publish_targets
+    ADD COLUMN IF NOT EXISTS status VARCHAR(50) NOT NULL DEFAULT 'draft';
+
+-- Указатель на деплой, который отдаётся посетителям; переключается только после успешной загрузки
+ALTER TABLE publish_targets
+    ADD COLUMN IF NOT EXISTS active_deployment_id UUID REFERENCES deployments(id) ON DELETE SET NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE publish_targets DROP COLUMN IF EXISTS active_deployment_id;
+DROP INDEX IF EXISTS idx_deployments_project_version;
+DROP TABLE IF EXISTS deployments;
+
+-- +goose StatementEnd
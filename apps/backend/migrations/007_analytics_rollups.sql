@@ -0,0 +1,61 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Почасовые и посуточные агрегаты analytics_events. Пересчитываются агрегатором в cmd/worker;
+-- unique_visitors считаются внутри интервала, поэтому суточные не выводятся из почасовых
+CREATE TABLE IF NOT EXISTS analytics_rollups_hourly (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    bucket TIMESTAMP NOT NULL,
+    pageviews INTEGER NOT NULL DEFAULT 0,
+    unique_visitors INTEGER NOT NULL DEFAULT 0,
+    cta_clicks INTEGER NOT NULL DEFAULT 0,
+    pay_clicks INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, bucket)
+);
+
+CREATE TABLE IF NOT EXISTS analytics_rollups_daily (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    bucket TIMESTAMP NOT NULL,
+    pageviews INTEGER NOT NULL DEFAULT 0,
+    unique_visitors INTEGER NOT NULL DEFAULT 0,
+    cta_clicks INTEGER NOT NULL DEFAULT 0,
+    pay_clicks INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, bucket)
+);
+
+-- Агрегаты по уже накопленным событиям
+INSERT INTO analytics_rollups_hourly (project_id, bucket, pageviews, unique_visitors, cta_clicks, pay_clicks)
+SELECT
+    project_id,
+    date_trunc('hour', created_at),
+    COUNT(*) FILTER (WHERE event_type = 'pageview'),
+    COUNT(DISTINCT ip_address) FILTER (WHERE event_type = 'pageview'),
+    COUNT(*) FILTER (WHERE event_type = 'cta_click'),
+    COUNT(*) FILTER (WHERE event_type = 'pay_click')
+FROM analytics_events
+GROUP BY project_id, date_trunc('hour', created_at)
+ON CONFLICT (project_id, bucket) DO NOTHING;
+
+INSERT INTO analytics_rollups_daily (project_id, bucket, pageviews, unique_visitors, cta_clicks, pay_clicks)
+SELECT
+    project_id,
+    date_trunc('day', created_at),
+    COUNT(*) FILTER (WHERE event_type = 'pageview'),
+    COUNT(DISTINCT ip_address) FILTER (WHERE event_type = 'pageview'),
+    COUNT(*) FILTER (WHERE event_type = 'cta_click'),
+    COUNT(*) FILTER (WHERE event_type = 'pay_click')
+FROM analytics_events
+GROUP BY project_id, date_trunc('day', created_at)
+ON CONFLICT (project_id, bucket) DO NOTHING;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS analytics_rollups_daily;
+DROP TABLE IF EXISTS analytics_rollups_hourly;
+
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Уникальные посетители считаются по хэшу IP + User-Agent + проект с солью, меняющейся раз в сутки.
+-- Сырой IP больше не нужен для подсчёта и по умолчанию не хранится.
+ALTER TABLE analytics_events ADD COLUMN IF NOT EXISTS visitor_hash VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_analytics_events_project_created ON analytics_events(project_id, created_at);
+
+-- Соль текущих суток общая для всех инстансов API; соли прошлых дней удаляет задача хранения,
+-- после чего хэш нельзя сопоставить с IP перебором
+CREATE TABLE IF NOT EXISTS analytics_salts (
+    day DATE PRIMARY KEY,
+    salt BYTEA NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Накопленные события: хэш с одноразовой солью на каждые сутки, сырые IP удаляются.
+-- Соли не сохраняются, поэтому подсчёт уникальных за прошлые дни сохраняется, а IP восстановить нельзя.
+WITH days AS (
+    SELECT day, md5(random()::text || clock_timestamp()::text) AS salt
+    FROM (SELECT DISTINCT date_trunc('day', created_at) AS day FROM analytics_events WHERE visitor_hash IS NULL) d
+)
+UPDATE analytics_events e
+SET visitor_hash = encode(sha256(convert_to(days.salt || e.project_id::text || COALESCE(e.ip_address, '') || COALESCE(e.user_agent, ''), 'UTF8')), 'hex'),
+    ip_address = NULL
+FROM days
+WHERE days.day = date_trunc('day', e.created_at) AND e.visitor_hash IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS analytics_salts;
+DROP INDEX IF EXISTS idx_analytics_events_project_created;
+ALTER TABLE analytics_events DROP COLUMN IF EXISTS visitor_hash;
+
+-- +goose StatementEnd
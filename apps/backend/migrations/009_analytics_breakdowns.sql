@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Разрезы аналитики: UTM-метки страницы, домен источника и устройство посетителя.
+-- Заполняются при приёме события, чтобы отчёты группировали по готовым колонкам.
+ALTER TABLE analytics_events
+    ADD COLUMN IF NOT EXISTS utm_source VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS utm_medium VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS utm_campaign VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS utm_term VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS utm_content VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS referrer_domain VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS browser VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS os VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS device_type VARCHAR(20);
+
+-- Накопленные события: домен источника без www. Браузер и устройство для них остаются пустыми.
+UPDATE analytics_events
+SET referrer_domain = regexp_replace(lower(substring(referrer from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^/:?#]+)')), '^www\.', '')
+WHERE referrer IS NOT NULL AND referrer <> '' AND referrer_domain IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE analytics_events
+    DROP COLUMN IF EXISTS device_type,
+    DROP COLUMN IF EXISTS os,
+    DROP COLUMN IF EXISTS browser,
+    DROP COLUMN IF EXISTS referrer_domain,
+    DROP COLUMN IF EXISTS utm_content,
+    DROP COLUMN IF EXISTS utm_term,
+    DROP COLUMN IF EXISTS utm_campaign,
+    DROP COLUMN IF EXISTS utm_medium,
+    DROP COLUMN IF EXISTS utm_source;
+
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Варианты схемы для A/B-теста. Основная схема проекта — контрольный вариант,
+-- ей достаётся трафик, не распределённый по вариантам (100 - сумма weight).
+CREATE TABLE IF NOT EXISTS project_variants (
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    schema_json TEXT NOT NULL,
+    weight INTEGER NOT NULL DEFAULT 0 CHECK (weight >= 0 AND weight <= 100),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_variants_project_id ON project_variants(project_id);
+
+-- Снимок вариантов и весов на момент публикации (JSON); пусто — деплой без эксперимента
+ALTER TABLE deployments ADD COLUMN IF NOT EXISTS variants TEXT NOT NULL DEFAULT '';
+
+-- Вариант, который видел посетитель
+ALTER TABLE analytics_events ADD COLUMN IF NOT EXISTS variant VARCHAR(64);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE analytics_events DROP COLUMN IF EXISTS variant;
+ALTER TABLE deployments DROP COLUMN IF EXISTS variants;
+DROP TABLE IF EXISTS project_variants;
+
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Подтверждение владения доменом: TXT-запись _landly-verification.<domain> с токеном
+ALTER TABLE publish_targets ADD COLUMN IF NOT EXISTS domain_verification_token VARCHAR(64);
+ALTER TABLE publish_targets ADD COLUMN IF NOT EXISTS domain_verified_at TIMESTAMP;
+
+-- Домен хранится в нижнем регистре и принадлежит одному проекту
+CREATE UNIQUE INDEX IF NOT EXISTS idx_publish_targets_custom_domain ON publish_targets(custom_domain) WHERE custom_domain IS NOT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_publish_targets_custom_domain;
+ALTER TABLE publish_targets DROP COLUMN IF EXISTS domain_verified_at;
+ALTER TABLE publish_targets DROP COLUMN IF EXISTS domain_verification_token;
+
+-- +goose StatementEnd
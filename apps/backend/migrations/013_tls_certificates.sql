@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Сертификаты собственных доменов, выпущенные по ACME: PEM-цепочка и ключ
+CREATE TABLE IF NOT EXISTS tls_certificates (
+    domain VARCHAR(255) PRIMARY KEY,
+    cert_pem TEXT NOT NULL,
+    key_pem TEXT NOT NULL,
+    not_after TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Ключ ACME-аккаунта для каждого удостоверяющего центра
+CREATE TABLE IF NOT EXISTS acme_accounts (
+    directory_url VARCHAR(512) PRIMARY KEY,
+    key_pem TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS acme_accounts;
+DROP TABLE IF EXISTS tls_certificates;
+
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Настройки интеграции хранятся одним JSON в config, как их пишет IntegrationRepository.
+-- value была платёжной ссылкой, meta_json никто не заполнял.
+ALTER TABLE integrations ADD COLUMN IF NOT EXISTS config TEXT;
+UPDATE integrations SET config = json_build_object('payment_url', value)::text WHERE config IS NULL;
+ALTER TABLE integrations ALTER COLUMN config SET NOT NULL;
+
+ALTER TABLE integrations DROP COLUMN IF EXISTS value;
+ALTER TABLE integrations DROP COLUMN IF EXISTS meta_json;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE integrations ADD COLUMN IF NOT EXISTS value TEXT;
+ALTER TABLE integrations ADD COLUMN IF NOT EXISTS meta_json TEXT;
+UPDATE integrations SET value = COALESCE(config::json->>'payment_url', '');
+ALTER TABLE integrations ALTER COLUMN value SET NOT NULL;
+ALTER TABLE integrations DROP COLUMN IF EXISTS config;
+
+-- +goose StatementEnd
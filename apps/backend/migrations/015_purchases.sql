@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Оплаты из вебхуков платёжных систем. Сумма в минимальных единицах валюты,
+-- variant — вариант лендинга из client_reference_id ссылки на оплату.
+-- Повторная доставка вебхука не создаёт дубль благодаря UNIQUE(provider, external_id).
+CREATE TABLE IF NOT EXISTS purchases (
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    amount BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    variant VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(provider, external_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchases_project_created ON purchases(project_id, created_at DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS purchases;
+
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Заявки из форм лендингов. data — JSON значений полей, проверенных по блоку form схемы.
+CREATE TABLE IF NOT EXISTS form_submissions (
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    form_name VARCHAR(64) NOT NULL,
+    data TEXT NOT NULL,
+    path VARCHAR(512) NOT NULL DEFAULT '',
+    variant VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_form_submissions_project_created ON form_submissions(project_id, created_at DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS form_submissions;
+
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- До какого момента агрегаты уже пересчитаны. После простоя воркера пересчёт продолжается отсюда,
+-- а очистка сырых событий не удаляет то, что ещё не попало в агрегаты
+CREATE TABLE IF NOT EXISTS analytics_rollup_watermarks (
+    granularity VARCHAR(10) PRIMARY KEY,
+    rolled_up_to TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS analytics_rollup_watermarks;
+
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Уникален только подтверждённый домен: неподтверждённая заявка не должна мешать настоящему
+-- владельцу, который подтвердит домен через DNS
+DROP INDEX IF EXISTS idx_publish_targets_custom_domain;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_publish_targets_custom_domain ON publish_targets(custom_domain) WHERE domain_verified_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_publish_targets_custom_domain_claims ON publish_targets(custom_domain) WHERE custom_domain IS NOT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_publish_targets_custom_domain_claims;
+DROP INDEX IF EXISTS idx_publish_targets_custom_domain;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_publish_targets_custom_domain ON publish_targets(custom_domain) WHERE custom_domain IS NOT NULL;
+
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Ответы на challenge http-01: удостоверяющий центр может прийти на любой экземпляр API,
+-- а не только на тот, что заказал сертификат
+CREATE TABLE IF NOT EXISTS acme_challenges (
+    token VARCHAR(255) PRIMARY KEY,
+    response TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Аренда выпуска сертификата: домен заказывает только один экземпляр, пока аренда не истекла
+CREATE TABLE IF NOT EXISTS certificate_issue_leases (
+    domain VARCHAR(255) PRIMARY KEY,
+    holder VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS certificate_issue_leases;
+DROP TABLE IF EXISTS acme_challenges;
+
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Схема проекта на момент публикации: заявки с сайта проверяются по опубликованным формам,
+-- а не по черновику. Схемы вариантов хранятся в снимке variants
+ALTER TABLE deployments ADD COLUMN IF NOT EXISTS schema_json TEXT NOT NULL DEFAULT '';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE deployments DROP COLUMN IF EXISTS schema_json;
+
+-- +goose StatementEnd
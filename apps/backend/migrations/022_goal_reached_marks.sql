@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Отметки goal.reached: событие уходит подписчикам не чаще раза в сутки на посетителя и цель.
+-- Хэш посетителя меняется вместе с солью суток, поэтому отметки прошлых дней удаляются
+CREATE TABLE IF NOT EXISTS analytics_goal_reached (
+    goal_id UUID NOT NULL REFERENCES analytics_goals(id) ON DELETE CASCADE,
+    visitor_hash VARCHAR(64) NOT NULL,
+    day DATE NOT NULL,
+    PRIMARY KEY (goal_id, visitor_hash, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_analytics_goal_reached_day ON analytics_goal_reached(day);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS analytics_goal_reached;
+
+-- +goose StatementEnd